@@ -9,6 +9,7 @@ import (
 	"gohotel/internal/database"
 	"gohotel/internal/handler"
 	"gohotel/internal/middleware"
+	"gohotel/internal/models"
 	"gohotel/internal/repository"
 	"gohotel/internal/service"
 	"gohotel/pkg/logger"
@@ -82,6 +83,26 @@ func main() {
 		log.Fatal("测试数据插入失败:", err)
 	}
 
+	// 5.1 迁移房间设施数据到设施目录
+	if err := database.MigrateRoomAmenities(); err != nil {
+		log.Fatal("房间设施迁移失败:", err)
+	}
+
+	// 5.2 迁移房间图片数据到图库
+	if err := database.MigrateRoomPhotos(); err != nil {
+		log.Fatal("房间图片迁移失败:", err)
+	}
+
+	// 5.3 迁移单酒店数据到默认酒店
+	if err := database.MigrateHotels(); err != nil {
+		log.Fatal("酒店数据迁移失败:", err)
+	}
+
+	// 5.4 写入内置角色并为现有管理员分配超级管理员
+	if err := database.MigrateRoles(); err != nil {
+		log.Fatal("角色数据迁移失败:", err)
+	}
+
 	// 6. 初始化雪花算法节点
 	fmt.Println("❄️  正在初始化雪花算法节点...")
 	// 节点ID可以从配置文件读取，这里暂时使用固定值 1
@@ -124,15 +145,54 @@ func main() {
 	facilityRepo := repository.NewFacilityRepository(database.DB)
 	bannerRepo := repository.NewBannerRepository(database.DB)
 	noticeRepo := repository.NewNoticeRepository(database.DB)
+	housekeepingRepo := repository.NewHousekeepingRepository(database.DB)
+	workOrderRepo := repository.NewWorkOrderRepository(database.DB)
+	amenityRepo := repository.NewAmenityRepository(database.DB)
+	roomPhotoRepo := repository.NewRoomPhotoRepository(database.DB)
+	reviewRepo := repository.NewReviewRepository(database.DB)
+	floorLayoutRepo := repository.NewFloorLayoutRepository(database.DB)
+	hotelRepo := repository.NewHotelRepository(database.DB)
+	pricingRepo := repository.NewPricingRepository(database.DB)
+	installmentRepo := repository.NewInstallmentRepository(database.DB)
+	roleRepo := repository.NewRoleRepository(database.DB)
+	authSessionRepo := repository.NewAuthSessionRepository(database.DB)
+	verificationCodeRepo := repository.NewVerificationCodeRepository(database.DB)
+	passwordResetRepo := repository.NewPasswordResetRepository(database.DB)
+	loginLockoutRepo := repository.NewLoginLockoutRepository(database.DB)
+	wechatAccountRepo := repository.NewWechatAccountRepository(database.DB)
+	mfaRepo := repository.NewMFARepository(database.DB)
 
 	// Service 层
-	userService := service.NewUserService(userRepo)
-	roomService := service.NewRoomService(roomRepo)
-	bookingService := service.NewBookingService(bookingRepo, roomRepo, userRepo)
+	tokenService := service.NewTokenService(authSessionRepo, userRepo, timeWheel, &config.AppConfig.JWT)
+	captchaService := service.NewCaptchaService(service.NewMemoryCaptchaStore(config.AppConfig.Captcha.MaxStored), &config.AppConfig.Captcha)
+	messageSender := service.NewStubSender(&config.AppConfig.Verify)
+	verificationService := service.NewVerificationService(verificationCodeRepo, userRepo, messageSender, messageSender, timeWheel, &config.AppConfig.Verify)
+	passwordResetService := service.NewPasswordResetService(passwordResetRepo, userRepo, tokenService, messageSender, messageSender, timeWheel, &config.AppConfig.Password)
+	loginThrottleService := service.NewLoginThrottleService(loginLockoutRepo, userRepo, roleRepo, messageSender, &config.AppConfig.Login)
+	userRecycleService := service.NewUserRecycleService(userRepo, timeWheel, &config.AppConfig.User)
+	mfaService := service.NewMFAService(mfaRepo, userRepo, roleRepo, tokenService, timeWheel, &config.AppConfig.MFA)
+	userService := service.NewUserService(userRepo, tokenService, captchaService, verificationService, passwordResetService, loginThrottleService, mfaService, bookingRepo, roleRepo)
+	wechatAuthService := service.NewWechatAuthService(wechatAccountRepo, userRepo, mfaService, service.NewWechatClient(&config.AppConfig.Wechat), &config.AppConfig.Wechat)
+	floorLayoutService := service.NewFloorLayoutService(floorLayoutRepo, roomRepo, facilityRepo)
+	roomService := service.NewRoomService(roomRepo, floorLayoutService, cosService)
+	housekeepingService := service.NewHousekeepingService(housekeepingRepo, roomRepo, userRepo, hotelRepo)
+	pricingService := service.NewPricingService(pricingRepo, hotelRepo, timeWheel, &config.AppConfig.Pricing)
+	billingService := service.NewBillingService(installmentRepo, bookingRepo, timeWheel, &config.AppConfig.Booking)
+	bookingService := service.NewBookingService(bookingRepo, roomRepo, userRepo, workOrderRepo, housekeepingService, pricingService, billingService, &config.AppConfig.Booking)
 	logService := service.NewLogService(logRepo)
-	facilityService := service.NewFacilityService(facilityRepo)
+	facilityService := service.NewFacilityService(facilityRepo, roomRepo, floorLayoutService)
 	bannerService := service.NewBannerService(bannerRepo, cosService, timeWheel)
 	noticeService := service.NewNoticeService(noticeRepo, cosService, timeWheel)
+	roomCalendarService := service.NewRoomCalendarService(roomRepo, bookingRepo, workOrderRepo)
+	workOrderService := service.NewWorkOrderService(workOrderRepo, roomRepo, bookingRepo, userRepo, hotelRepo, cosService)
+	amenityService := service.NewAmenityService(amenityRepo, roomRepo)
+	roomMediaService := service.NewRoomMediaService(roomRepo, roomPhotoRepo, cosService)
+	reviewService := service.NewReviewService(reviewRepo, bookingRepo, userRepo, cosService)
+	floorPlanService := service.NewFloorPlanService(roomRepo, facilityRepo, workOrderRepo)
+	wayfindingService := service.NewWayfindingService(roomRepo, facilityRepo)
+	evacuationService := service.NewEvacuationService(roomRepo, facilityRepo, &config.AppConfig.Evacuation)
+	hotelService := service.NewHotelService(hotelRepo, userRepo)
+	roleService := service.NewRoleService(roleRepo, userRepo)
 
 	// 加载持久化的时间轮任务
 	fmt.Println("📂 正在加载时间轮任务...")
@@ -174,8 +234,31 @@ func main() {
 		fmt.Println("✅ COS临时文件清理任务已添加，每30分钟执行一次")
 	}
 
+	// 启动动态定价定时重算
+	pricingService.StartScheduler()
+	fmt.Printf("✅ 动态定价任务已启动，每%v重算一次\n", config.AppConfig.Pricing.Interval)
+
+	// 启动过期登录会话定时清理
+	tokenService.StartCleanup()
+
+	// 启动验证码发送记录定时清理
+	verificationService.StartCleanup()
+
+	// 启动过期设置密码令牌定时清理
+	passwordResetService.StartCleanup()
+
+	// 启动过期两步验证记录定时清理
+	mfaService.StartCleanup()
+
+	// 启动回收站到期用户定时匿名化
+	userRecycleService.StartAnonymizer()
+
+	// 启动长住分期账单定时出账
+	billingService.StartScheduler()
+	fmt.Printf("✅ 长住账单出账任务已启动，每%v检查一次\n", config.AppConfig.Booking.BillingInterval)
+
 	// Handler 层
-	userHandler := handler.NewUserHandler(userService)
+	userHandler := handler.NewUserHandler(userService, tokenService)
 	roomHandler := handler.NewRoomHandler(roomService)
 	bookingHandler := handler.NewBookingHandler(bookingService)
 	logHandler := handler.NewLogHandler(logService)
@@ -183,12 +266,37 @@ func main() {
 	bannerHandler := handler.NewBannerHandler(bannerService, cosService)
 	noticeHandler := handler.NewNoticeHandler(noticeService)
 	cosHandler := handler.NewCosHandler(cosService)
+	roomCalendarHandler := handler.NewRoomCalendarHandler(roomCalendarService)
+	housekeepingHandler := handler.NewHousekeepingHandler(housekeepingService)
+	workOrderHandler := handler.NewWorkOrderHandler(workOrderService)
+	amenityHandler := handler.NewAmenityHandler(amenityService)
+	roomMediaHandler := handler.NewRoomMediaHandler(roomMediaService)
+	reviewHandler := handler.NewReviewHandler(reviewService)
+	floorPlanHandler := handler.NewFloorPlanHandler(floorPlanService)
+	floorLayoutHandler := handler.NewFloorLayoutHandler(floorLayoutService)
+	wayfindingHandler := handler.NewWayfindingHandler(wayfindingService)
+	evacuationHandler := handler.NewEvacuationHandler(evacuationService)
+	hotelHandler := handler.NewHotelHandler(hotelService)
+	pricingHandler := handler.NewPricingHandler(pricingService)
+	roleHandler := handler.NewRoleHandler(roleService)
+	captchaHandler := handler.NewCaptchaHandler(captchaService)
+	verificationHandler := handler.NewVerificationHandler(verificationService)
+	passwordHandler := handler.NewPasswordHandler(passwordResetService)
+	loginThrottleHandler := handler.NewLoginThrottleHandler(loginThrottleService)
+	wechatHandler := handler.NewWechatHandler(wechatAuthService)
+	mfaHandler := handler.NewMFAHandler(mfaService)
+	userRecycleHandler := handler.NewUserRecycleHandler(userRecycleService)
+	sessionHandler := handler.NewSessionHandler(tokenService)
 
 	// 8. 设置 Gin 模式
 	gin.SetMode(config.AppConfig.Server.Mode)
 
 	// 9. 创建 Gin 引擎
 	r := gin.New()
+	// 只信任配置的反向代理，避免客户端伪造 X-Forwarded-For 绕过按 IP 的限流
+	if err := r.SetTrustedProxies(config.AppConfig.Server.TrustedProxies); err != nil {
+		log.Fatal("信任代理配置错误:", err)
+	}
 
 	// 10. 使用中间件
 	r.Use(gin.Recovery())                // 恢复中间件（处理 panic）
@@ -196,7 +304,7 @@ func main() {
 	r.Use(middleware.LoggerMiddleware()) // 日志中间件
 
 	// 设置路由
-	setupRoutes(r, userHandler, roomHandler, bookingHandler, logHandler, facilityHandler, bannerHandler, noticeHandler, cosHandler)
+	setupRoutes(r, userHandler, roomHandler, bookingHandler, logHandler, facilityHandler, bannerHandler, noticeHandler, cosHandler, roomCalendarHandler, housekeepingHandler, workOrderHandler, amenityHandler, roomMediaHandler, reviewHandler, floorPlanHandler, floorLayoutHandler, wayfindingHandler, evacuationHandler, hotelHandler, hotelService, pricingHandler, roleHandler, roleService, tokenService, captchaHandler, verificationHandler, passwordHandler, loginThrottleHandler, wechatHandler, mfaHandler, userRecycleHandler, sessionHandler)
 
 	// 12. 启动服务器
 	fmt.Println("═══════════════════════════════════════════════")
@@ -213,7 +321,7 @@ func main() {
 }
 
 // setupRoutes 设置所有路由
-func setupRoutes(r *gin.Engine, userHandler *handler.UserHandler, roomHandler *handler.RoomHandler, bookingHandler *handler.BookingHandler, logHandler *handler.LogHandler, facilityHandler *handler.FacilityHandler, bannerHandler *handler.BannerHandler, noticeHandler *handler.NoticeHandler, cosHandler *handler.CosHandler) {
+func setupRoutes(r *gin.Engine, userHandler *handler.UserHandler, roomHandler *handler.RoomHandler, bookingHandler *handler.BookingHandler, logHandler *handler.LogHandler, facilityHandler *handler.FacilityHandler, bannerHandler *handler.BannerHandler, noticeHandler *handler.NoticeHandler, cosHandler *handler.CosHandler, roomCalendarHandler *handler.RoomCalendarHandler, housekeepingHandler *handler.HousekeepingHandler, workOrderHandler *handler.WorkOrderHandler, amenityHandler *handler.AmenityHandler, roomMediaHandler *handler.RoomMediaHandler, reviewHandler *handler.ReviewHandler, floorPlanHandler *handler.FloorPlanHandler, floorLayoutHandler *handler.FloorLayoutHandler, wayfindingHandler *handler.WayfindingHandler, evacuationHandler *handler.EvacuationHandler, hotelHandler *handler.HotelHandler, hotelService *service.HotelService, pricingHandler *handler.PricingHandler, roleHandler *handler.RoleHandler, roleService *service.RoleService, tokenService *service.TokenService, captchaHandler *handler.CaptchaHandler, verificationHandler *handler.VerificationHandler, passwordHandler *handler.PasswordHandler, loginThrottleHandler *handler.LoginThrottleHandler, wechatHandler *handler.WechatHandler, mfaHandler *handler.MFAHandler, userRecycleHandler *handler.UserRecycleHandler, sessionHandler *handler.SessionHandler) {
 	// Swagger 文档路由
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -232,42 +340,98 @@ func setupRoutes(r *gin.Engine, userHandler *handler.UserHandler, roomHandler *h
 		})
 	})
 
+	// 认证与会话撤销检查
+	authRequired := middleware.AuthMiddleware(tokenService)
+	// 首次登录尚未修改密码的用户只能访问使用该中间件的接口
+	passwordChangeAuth := middleware.PasswordChangeAuthMiddleware(tokenService)
+	// 管理接口的权限检查
+	perm := func(permissions ...string) gin.HandlerFunc {
+		return middleware.RequirePermission(roleService, permissions...)
+	}
+
 	// API 路由组
 	api := r.Group("/api")
 	{
+		// 酒店选择由 hotel_id 参数或 X-Hotel-ID 请求头决定，公开接口未指定时使用默认酒店
+		hotelSelector := middleware.HotelSelectorMiddleware(hotelService)
+		hotelScope := middleware.HotelScopeMiddleware(hotelService)
+
+		// 酒店路由（公开查询）
+		api.GET("/hotels", hotelHandler.ListHotels)
+
 		// 认证路由（公开）
 		auth := api.Group("/auth")
 		{
+			auth.GET("/captcha", captchaHandler.GetCaptcha) // 获取图形验证码
 			auth.POST("/register", userHandler.Register)
+			auth.POST("/verification-codes", verificationHandler.SendCode) // 发送短信/邮件验证码
 			auth.POST("/login", userHandler.Login)
+			auth.POST("/login/code", userHandler.LoginWithCode)           // 验证码登录
+			auth.POST("/wechat/login", wechatHandler.Login)               // 微信小程序登录
+			auth.POST("/mfa/verify", mfaHandler.VerifyLogin)              // 登录第二步验证
+			auth.POST("/mfa/setup", mfaHandler.SetupForLogin)             // 登录时绑定身份验证器
+			auth.POST("/refresh", userHandler.Refresh)                    // 刷新令牌
+			auth.POST("/logout", passwordChangeAuth, userHandler.Logout)  // 注销当前会话
+			auth.POST("/password/forgot", passwordHandler.ForgotPassword) // 申请找回密码
+			auth.POST("/password/reset", passwordHandler.ResetPassword)   // 通过链接设置新密码
 		}
 
 		// 房间路由（公开查询）
 		rooms := api.Group("/rooms")
+		rooms.Use(hotelSelector)
 		{
-			rooms.GET("", roomHandler.ListRooms)                     // 获取所有房间
-			rooms.GET("/available", roomHandler.ListAvailableRooms)  // 获取可用房间
-			rooms.GET("/floor/:floor", roomHandler.GetRoomByFloor)   // 根据楼层获取房间
-			rooms.GET("/search/type", roomHandler.SearchRoomsByType) // 按房型搜索
-			rooms.GET("/:id", roomHandler.GetRoomByID)               // 获取房间详情
-
-			// 需要认证的房间管理路由（管理员）
-			roomsAuth := rooms.Group("")
-			roomsAuth.Use(middleware.AuthMiddleware())
+			rooms.GET("", roomHandler.ListRooms)                                // 获取所有房间
+			rooms.GET("/available", roomHandler.ListAvailableRooms)             // 获取可用房间
+			rooms.GET("/floor/:floor", roomHandler.GetRoomByFloor)              // 根据楼层获取房间
+			rooms.GET("/search/type", roomHandler.SearchRoomsByType)            // 按房型搜索
+			rooms.GET("/:id", roomHandler.GetRoomByID)                          // 获取房间详情
+			rooms.GET("/:id/calendar", roomCalendarHandler.GetRoomAvailability) // 获取房间空闲日历
+			rooms.GET("/:id/photos", roomMediaHandler.ListPhotos)               // 获取房间图库
+
+			// 需要认证的房间管理路由（管理员），按员工被分配的酒店隔离，不经过公开接口的酒店选择
+			roomsAuth := api.Group("/rooms")
+			roomsAuth.Use(authRequired, hotelScope)
 			{
-				roomsAuth.POST("", roomHandler.CreateRoom)             // 创建房间
-				roomsAuth.POST("/batch", roomHandler.BatchCreateRooms) // 批量创建房间
-				roomsAuth.POST("/:id", roomHandler.UpdateRoom)         // 更新房间
-				roomsAuth.POST("/:id/delete", roomHandler.DeleteRoom)  // 删除房间
+				roomsAuth.POST("", perm(models.PermRoomCreate), roomHandler.CreateRoom)                       // 创建房间
+				roomsAuth.POST("/batch", perm(models.PermRoomCreate), roomHandler.BatchCreateRooms)           // 批量创建房间
+				roomsAuth.POST("/import", perm(models.PermRoomCreate), roomHandler.ImportRooms)               // 从 CSV/XLSX 导入房间
+				roomsAuth.GET("/import/template", perm(models.PermRoomCreate), roomHandler.GetImportTemplate) // 下载导入模板
+				roomsAuth.POST("/:id", perm(models.PermRoomUpdate), roomHandler.UpdateRoom)                   // 更新房间
+				roomsAuth.POST("/:id/delete", perm(models.PermRoomDelete), roomHandler.DeleteRoom)            // 删除房间
 			}
 		}
+		// 楼层平面图路由（公开查询，便于打印或嵌入自助机）
+		floors := api.Group("/floors")
+		floors.Use(hotelSelector)
+		{
+			floors.GET("/:floor/plan", floorPlanHandler.GetFloorPlan)             // 楼层平面图（JSON/SVG）
+			floors.GET("/:floor/evacuation", evacuationHandler.GetEvacuationPlan) // 楼层疏散图（JSON/SVG）
+			floors.GET("/route", wayfindingHandler.FindRoute)                     // 到房间的步行路线
+		}
+		// 评价路由（公开查询，发表评价需要登录）
+		reviews := api.Group("/reviews")
+		{
+			reviews.GET("", hotelSelector, reviewHandler.ListReviews)              // 公开评价列表
+			reviews.GET("/summary", hotelSelector, reviewHandler.GetRatingSummary) // 房型评分汇总
+
+			reviewsAuth := reviews.Group("")
+			reviewsAuth.Use(authRequired)
+			{
+				reviewsAuth.POST("", reviewHandler.CreateReview)   // 评价已退房的预订
+				reviewsAuth.GET("/my", reviewHandler.GetMyReviews) // 我的评价
+			}
+		}
+		// 设施目录路由（公开查询）
+		api.GET("/amenities", amenityHandler.ListAmenities)
 		// 活动横幅路由（公开查询）
 		banners := api.Group("/banners")
+		banners.Use(hotelSelector)
 		{
 			banners.GET("/active", bannerHandler.GetActiveBanners) // 获取激活的活动横幅（前端展示用）
 		}
 		// 公告路由（公开查询）
 		notices := api.Group("/notices")
+		notices.Use(hotelSelector)
 		{
 			notices.GET("/active", noticeHandler.GetActiveNotices) // 获取激活的公告（前端展示用）
 		}
@@ -275,26 +439,42 @@ func setupRoutes(r *gin.Engine, userHandler *handler.UserHandler, roomHandler *h
 		logs := api.Group("/logs")
 		{
 			logs.POST("/report", logHandler.Report) // 上报日志
-			logs.GET("", logHandler.GetLogs)        // 获取日志列表
 		}
 
 		// 文件上传路由（需要认证，但不需要管理员权限）
 		upload := api.Group("/upload")
-		upload.Use(middleware.AuthMiddleware())
+		upload.Use(authRequired)
 		{
 			upload.POST("/image", cosHandler.UploadImage) // 通用图片上传接口
 		}
 
+		// 首次登录修改密码所需的路由（需要认证，尚未修改密码也可访问）
+		account := api.Group("/users")
+		account.Use(passwordChangeAuth)
+		{
+			account.GET("/profile", userHandler.GetProfile)       // 获取个人信息
+			account.POST("/password", userHandler.ChangePassword) // 修改密码
+		}
+
 		// 需要认证的路由
 		authorized := api.Group("")
-		authorized.Use(middleware.AuthMiddleware())
+		authorized.Use(authRequired)
 		{
 			// 用户路由
 			users := authorized.Group("/users")
 			{
-				users.GET("/profile", userHandler.GetProfile)       // 获取个人信息
-				users.POST("/profile", userHandler.UpdateProfile)   // 更新个人信息
-				users.POST("/password", userHandler.ChangePassword) // 修改密码
+				users.POST("/profile", userHandler.UpdateProfile)                     // 更新个人信息
+				users.POST("/wechat/bind", wechatHandler.BindAccount)                 // 绑定微信
+				users.POST("/wechat/phone", wechatHandler.BindPhone)                  // 绑定微信手机号
+				users.GET("/permissions", roleHandler.GetMyPermissions)               // 我的权限
+				users.GET("/mfa", mfaHandler.GetStatus)                               // 两步验证状态
+				users.POST("/mfa/setup", mfaHandler.Setup)                            // 获取绑定二维码
+				users.POST("/mfa/enable", mfaHandler.Enable)                          // 启用两步验证
+				users.POST("/mfa/disable", mfaHandler.Disable)                        // 关闭两步验证
+				users.POST("/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes) // 重新生成恢复码
+				users.GET("/sessions", sessionHandler.ListSessions)                   // 登录设备列表
+				users.POST("/sessions/:id/revoke", sessionHandler.RevokeSession)      // 撤销登录会话
+				users.POST("/sessions/revoke-all", sessionHandler.RevokeAllSessions)  // 撤销全部登录会话
 			}
 
 			// 预订路由
@@ -306,46 +486,140 @@ func setupRoutes(r *gin.Engine, userHandler *handler.UserHandler, roomHandler *h
 				bookings.POST("/:id/cancel", bookingHandler.CancelBooking) // 取消预订
 			}
 
-			// 管理员路由
+			// 客房清洁路由（保洁员移动端）
+			housekeeping := authorized.Group("/housekeeping")
+			{
+				housekeeping.GET("/tasks/my", housekeepingHandler.GetMyTasks)              // 我的清洁任务
+				housekeeping.POST("/tasks/:id/start", housekeepingHandler.StartTask)       // 开始清扫
+				housekeeping.POST("/tasks/:id/complete", housekeepingHandler.CompleteTask) // 完成清扫
+			}
+
+			// 管理路由，每个接口按所需权限检查，权限由用户的角色决定
 			admin := authorized.Group("/admin")
-			admin.Use(middleware.AdminMiddleware())
 			{
 				// 用户管理
-				admin.GET("/users", userHandler.ListUsers)
-				admin.GET("/users/:id", userHandler.GetUserByID)
-				admin.POST("/users/user", userHandler.AddUser)
-				admin.POST("/users/batch", userHandler.DeleteUsers)
+				admin.GET("/users", perm(models.PermUserRead), userHandler.ListUsers)
+				admin.GET("/users/:id", perm(models.PermUserRead), userHandler.GetUserByID)
+				admin.POST("/users/user", perm(models.PermUserCreate), userHandler.AddUser)
+				admin.POST("/users/batch", perm(models.PermUserDelete), userHandler.DeleteUsers)
+				admin.POST("/users/:id/status", perm(models.PermUserUpdate), userHandler.UpdateUserStatus)
+				admin.GET("/users/locked", perm(models.PermUserRead), loginThrottleHandler.ListLockedUsers)   // 被锁定的账号
+				admin.POST("/users/:id/unlock", perm(models.PermUserUpdate), loginThrottleHandler.UnlockUser) // 解除登录锁定
+				admin.POST("/users/:id/mfa/reset", perm(models.PermUserUpdate), mfaHandler.ResetUserMFA)      // 重置两步验证
+				admin.GET("/users/deleted", perm(models.PermUserDelete), userRecycleHandler.ListDeletedUsers) // 回收站
+				admin.POST("/users/:id/restore", perm(models.PermUserDelete), userRecycleHandler.RestoreUser) // 从回收站恢复
+				admin.POST("/users/:id/logout", perm(models.PermUserUpdate), sessionHandler.ForceLogout)      // 强制下线
+				// 酒店管理
+				admin.GET("/hotels", perm(models.PermHotelRead), hotelHandler.ListMyHotels)                            // 我管理的酒店
+				admin.POST("/hotels", perm(models.PermHotelManage), hotelHandler.CreateHotel)                          // 创建酒店
+				admin.GET("/hotels/:id", perm(models.PermHotelRead), hotelHandler.GetHotel)                            // 酒店详情
+				admin.POST("/hotels/:id", perm(models.PermHotelManage), hotelHandler.UpdateHotel)                      // 更新酒店
+				admin.GET("/hotels/:id/staff", perm(models.PermHotelRead), hotelHandler.ListStaff)                     // 酒店员工列表
+				admin.POST("/hotels/:id/staff", perm(models.PermHotelStaff), hotelHandler.AssignStaff)                 // 分配员工
+				admin.POST("/hotels/:id/staff/:user_id/delete", perm(models.PermHotelStaff), hotelHandler.RemoveStaff) // 取消员工分配
+				admin.GET("/amenities", amenityHandler.ListAmenities)                                                  // 设施目录
+				admin.POST("/amenities", perm(models.PermAmenityManage), amenityHandler.CreateAmenity)                 // 创建设施
+				admin.POST("/amenities/:id", perm(models.PermAmenityManage), amenityHandler.UpdateAmenity)             // 更新设施
+				admin.POST("/amenities/:id/delete", perm(models.PermAmenityManage), amenityHandler.DeleteAmenity)      // 删除设施
+				admin.GET("/logs", perm(models.PermLogRead), logHandler.GetLogs)                                       // 获取日志列表
+				// 角色管理
+				admin.GET("/permissions", perm(models.PermRoleManage), roleHandler.ListPermissions)
+				admin.GET("/roles", perm(models.PermRoleManage), roleHandler.ListRoles)
+				admin.POST("/roles", perm(models.PermRoleManage), roleHandler.CreateRole)
+				admin.POST("/roles/:id", perm(models.PermRoleManage), roleHandler.UpdateRole)
+				admin.POST("/roles/:id/delete", perm(models.PermRoleManage), roleHandler.DeleteRole)
+				admin.GET("/users/:id/roles", perm(models.PermRoleManage), roleHandler.GetUserRoles)
+				admin.POST("/users/:id/roles", perm(models.PermRoleManage), roleHandler.SetUserRoles)
+			}
+
+			// 按酒店隔离的管理路由，通过 X-Hotel-ID 请求头选择酒店
+			scoped := admin.Group("")
+			scoped.Use(hotelScope)
+			{
 				// 预订管理
-				admin.GET("/bookings", bookingHandler.ListAllBookings)
-				admin.GET("/bookings/search", bookingHandler.SearchBookingsByGuestInfo) // 通过客人信息搜索预订
-				admin.POST("/bookings/:id/confirm", bookingHandler.ConfirmBooking)
-				admin.POST("/bookings/:id/checkin", bookingHandler.CheckIn)
-				admin.POST("/bookings/:id/checkout", bookingHandler.CheckOut)
-				admin.GET("/bookings/room", bookingHandler.GetBookingsByRoomNumberAndStatus) // 根据房间号和状态获取预订列表
-				// 日志管理
-				admin.GET("/logs", logHandler.GetLogs) // 获取日志列表
+				scoped.GET("/bookings", perm(models.PermBookingRead), bookingHandler.ListAllBookings)
+				scoped.GET("/bookings/search", perm(models.PermBookingRead), bookingHandler.SearchBookingsByGuestInfo) // 通过客人信息搜索预订
+				scoped.POST("/bookings/:id/confirm", perm(models.PermBookingConfirm), bookingHandler.ConfirmBooking)
+				scoped.POST("/bookings/:id/checkin", perm(models.PermBookingCheckIn), bookingHandler.CheckIn)
+				scoped.POST("/bookings/:id/checkout", perm(models.PermBookingCheckOut), bookingHandler.CheckOut)
+				scoped.POST("/bookings/:id/installments/:installment_id/pay", perm(models.PermBookingPayment), bookingHandler.PayInstallment)
+				scoped.GET("/bookings/room", perm(models.PermBookingRead), bookingHandler.GetBookingsByRoomNumberAndStatus) // 根据房间号和状态获取预订列表
+				// 房态管理
+				scoped.GET("/rooms/calendar", perm(models.PermRoomRead), roomCalendarHandler.GetRoomCalendar)                                 // 前台房态图
+				scoped.POST("/rooms/:id/housekeeping", perm(models.PermHousekeepingManage), housekeepingHandler.UpdateRoomHousekeepingStatus) // 设置房间清洁状态
+				// 客房清洁管理
+				scoped.GET("/housekeeping/tasks", perm(models.PermHousekeepingManage), housekeepingHandler.ListTasks)                // 清洁任务列表
+				scoped.POST("/housekeeping/tasks", perm(models.PermHousekeepingManage), housekeepingHandler.CreateTask)              // 创建清洁任务
+				scoped.POST("/housekeeping/tasks/:id/assign", perm(models.PermHousekeepingManage), housekeepingHandler.AssignTask)   // 分配清洁任务
+				scoped.POST("/housekeeping/tasks/:id/inspect", perm(models.PermHousekeepingManage), housekeepingHandler.InspectTask) // 查房
+				// 维修工单管理
+				scoped.GET("/work-orders", perm(models.PermWorkOrderManage), workOrderHandler.ListWorkOrders)       // 工单列表
+				scoped.POST("/work-orders", perm(models.PermWorkOrderManage), workOrderHandler.CreateWorkOrder)     // 创建工单
+				scoped.GET("/work-orders/:id", perm(models.PermWorkOrderManage), workOrderHandler.GetWorkOrder)     // 工单详情（含冲突预订）
+				scoped.POST("/work-orders/:id", perm(models.PermWorkOrderManage), workOrderHandler.UpdateWorkOrder) // 更新工单
+				scoped.POST("/rooms/:id/amenities", perm(models.PermRoomUpdate), amenityHandler.SetRoomAmenities)   // 设置房间设施
+
+				scoped.POST("/rooms/:id/photos", perm(models.PermRoomUpdate), roomMediaHandler.AddPhotos)                    // 添加房间图片
+				scoped.POST("/rooms/:id/photos/reorder", perm(models.PermRoomUpdate), roomMediaHandler.ReorderPhotos)        // 调整图片顺序
+				scoped.POST("/rooms/:id/photos/:photo_id", perm(models.PermRoomUpdate), roomMediaHandler.UpdatePhoto)        // 更新图片说明
+				scoped.POST("/rooms/:id/photos/:photo_id/cover", perm(models.PermRoomUpdate), roomMediaHandler.SetCover)     // 设置封面
+				scoped.POST("/rooms/:id/photos/:photo_id/delete", perm(models.PermRoomUpdate), roomMediaHandler.DeletePhoto) // 删除图片
+
+				scoped.POST("/floors/:floor/layout/validate", perm(models.PermFloorManage), floorLayoutHandler.ValidateLayout)           // 校验布局
+				scoped.POST("/floors/:floor/layout/draft", perm(models.PermFloorManage), floorLayoutHandler.SaveDraft)                   // 保存布局草稿
+				scoped.GET("/floors/:floor/layout/versions", perm(models.PermFloorManage), floorLayoutHandler.ListVersions)              // 布局版本列表
+				scoped.GET("/floors/:floor/layout/versions/:id", perm(models.PermFloorManage), floorLayoutHandler.GetVersion)            // 布局版本详情
+				scoped.POST("/floors/:floor/layout/versions/:id/publish", perm(models.PermFloorManage), floorLayoutHandler.PublishDraft) // 发布草稿
+				scoped.POST("/floors/:floor/layout/versions/:id/rollback", perm(models.PermFloorManage), floorLayoutHandler.Rollback)    // 回滚到历史版本
+
+				scoped.GET("/reviews", perm(models.PermReviewModerate), reviewHandler.ListAllReviews)         // 评价列表（含已隐藏）
+				scoped.POST("/reviews/:id/hide", perm(models.PermReviewModerate), reviewHandler.HideReview)   // 隐藏评价
+				scoped.POST("/reviews/:id/show", perm(models.PermReviewModerate), reviewHandler.ShowReview)   // 恢复展示评价
+				scoped.POST("/reviews/:id/reply", perm(models.PermReviewModerate), reviewHandler.ReplyReview) // 回复评价
+
 				// 设施管理
-				admin.GET("/facilities", facilityHandler.FindAllFacilities)                  // 查询所有设施
-				admin.POST("/facilities", facilityHandler.CreateFacility)                    // 创建设施
-				admin.POST("/facilities/batch", facilityHandler.BatchUpdateFacilities)       // 批量更新设施位置
-				admin.GET("/facilities/floor/:floor", facilityHandler.FindFacilitiesByFloor) // 按楼层查询设施
-				admin.GET("/facilities/:id", facilityHandler.FindFacilityByID)               // 根据ID查找设施
-				admin.POST("/facilities/:id", facilityHandler.UpdateFacility)                // 更新设施
-				admin.POST("/facilities/:id/delete", facilityHandler.DeleteFacility)         // 删除设施
+				scoped.GET("/facilities", perm(models.PermFloorManage), facilityHandler.FindAllFacilities)                  // 查询所有设施
+				scoped.POST("/facilities", perm(models.PermFloorManage), facilityHandler.CreateFacility)                    // 创建设施
+				scoped.POST("/facilities/batch", perm(models.PermFloorManage), facilityHandler.BatchUpdateFacilities)       // 批量调整设施位置（保存到布局草稿）
+				scoped.GET("/facilities/floor/:floor", perm(models.PermFloorManage), facilityHandler.FindFacilitiesByFloor) // 按楼层查询设施
+				scoped.GET("/facilities/:id", perm(models.PermFloorManage), facilityHandler.FindFacilityByID)               // 根据ID查找设施
+				scoped.POST("/facilities/:id", perm(models.PermFloorManage), facilityHandler.UpdateFacility)                // 更新设施
+				scoped.POST("/facilities/:id/delete", perm(models.PermFloorManage), facilityHandler.DeleteFacility)         // 删除设施
 
 				// 活动横幅管理
-				admin.GET("/banners", bannerHandler.GetAllBanners)            // 获取所有活动横幅
-				admin.POST("/banners", bannerHandler.CreateBanner)            // 创建活动横幅
-				admin.GET("/banners/:id", bannerHandler.GetBannerByID)        // 获取活动横幅详情
-				admin.POST("/banners/:id", bannerHandler.UpdateBanner)        // 更新活动横幅
-				admin.POST("/banners/:id/delete", bannerHandler.DeleteBanner) // 删除活动横幅
+				scoped.GET("/banners", perm(models.PermContentManage), bannerHandler.GetAllBanners)            // 获取所有活动横幅
+				scoped.POST("/banners", perm(models.PermContentManage), bannerHandler.CreateBanner)            // 创建活动横幅
+				scoped.GET("/banners/:id", perm(models.PermContentManage), bannerHandler.GetBannerByID)        // 获取活动横幅详情
+				scoped.POST("/banners/:id", perm(models.PermContentManage), bannerHandler.UpdateBanner)        // 更新活动横幅
+				scoped.POST("/banners/:id/delete", perm(models.PermContentManage), bannerHandler.DeleteBanner) // 删除活动横幅
 
 				// 公告管理
-				admin.GET("/notices", noticeHandler.GetAllNotices)            // 获取所有公告
-				admin.POST("/notices", noticeHandler.CreateNotice)            // 创建公告
-				admin.GET("/notices/:id", noticeHandler.GetNoticeByID)        // 获取公告详情
-				admin.POST("/notices/:id", noticeHandler.UpdateNotice)        // 更新公告
-				admin.POST("/notices/:id/delete", noticeHandler.DeleteNotice) // 删除公告
+				scoped.GET("/notices", perm(models.PermContentManage), noticeHandler.GetAllNotices)            // 获取所有公告
+				scoped.POST("/notices", perm(models.PermContentManage), noticeHandler.CreateNotice)            // 创建公告
+				scoped.GET("/notices/:id", perm(models.PermContentManage), noticeHandler.GetNoticeByID)        // 获取公告详情
+				scoped.POST("/notices/:id", perm(models.PermContentManage), noticeHandler.UpdateNotice)        // 更新公告
+				scoped.POST("/notices/:id/delete", perm(models.PermContentManage), noticeHandler.DeleteNotice) // 删除公告
+
+				// 动态定价
+				scoped.GET("/pricing/rules", perm(models.PermPricingManage), pricingHandler.ListRules)              // 定价规则列表
+				scoped.POST("/pricing/rules", perm(models.PermPricingManage), pricingHandler.CreateRule)            // 创建定价规则
+				scoped.POST("/pricing/rules/:id", perm(models.PermPricingManage), pricingHandler.UpdateRule)        // 更新定价规则
+				scoped.POST("/pricing/rules/:id/delete", perm(models.PermPricingManage), pricingHandler.DeleteRule) // 删除定价规则
+				scoped.GET("/pricing/calendar", perm(models.PermPricingRead), pricingHandler.GetCalendar)           // 房价日历
+				scoped.GET("/pricing/preview", perm(models.PermPricingRead), pricingHandler.Preview)                // 定价预览
+				scoped.POST("/pricing/evaluate", perm(models.PermPricingManage), pricingHandler.Evaluate)           // 立即重算
+				scoped.GET("/pricing/logs", perm(models.PermPricingRead), pricingHandler.ListChangeLogs)            // 调价记录
+
+				// 连住折扣
+				scoped.GET("/pricing/stay-discounts", perm(models.PermPricingManage), pricingHandler.ListStayDiscounts)              // 连住折扣档位列表
+				scoped.POST("/pricing/stay-discounts", perm(models.PermPricingManage), pricingHandler.CreateStayDiscount)            // 创建连住折扣档位
+				scoped.POST("/pricing/stay-discounts/:id", perm(models.PermPricingManage), pricingHandler.UpdateStayDiscount)        // 更新连住折扣档位
+				scoped.POST("/pricing/stay-discounts/:id/delete", perm(models.PermPricingManage), pricingHandler.DeleteStayDiscount) // 删除连住折扣档位
+
+				// 长住政策
+				scoped.GET("/pricing/long-stay-policies", perm(models.PermPricingManage), pricingHandler.ListLongStayPolicies)             // 长住政策列表
+				scoped.POST("/pricing/long-stay-policies", perm(models.PermPricingManage), pricingHandler.SaveLongStayPolicy)              // 设置长住政策（押金）
+				scoped.POST("/pricing/long-stay-policies/:id/delete", perm(models.PermPricingManage), pricingHandler.DeleteLongStayPolicy) // 删除长住政策
 			}
 		}
 	}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -12,12 +13,22 @@ import (
 // Config 应用配置结构体
 // 这个结构体包含了应用运行所需的所有配置
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	Redis    RedisConfig
-	COS      COSConfig
-	Log      LogConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	JWT        JWTConfig
+	Redis      RedisConfig
+	COS        COSConfig
+	Log        LogConfig
+	Evacuation EvacuationConfig
+	Pricing    PricingConfig
+	Booking    BookingConfig
+	Captcha    CaptchaConfig
+	Verify     VerificationConfig
+	Password   PasswordConfig
+	Login      LoginConfig
+	Wechat     WechatConfig
+	MFA        MFAConfig
+	User       UserConfig
 }
 
 // COSConfig 腾讯云对象存储配置
@@ -30,10 +41,11 @@ type COSConfig struct {
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Port         string        // 服务器端口，如 ":8080"
-	Mode         string        // 运行模式：debug, release, test
-	ReadTimeout  time.Duration // 读取超时时间
-	WriteTimeout time.Duration // 写入超时时间
+	Port           string        // 服务器端口，如 ":8080"
+	Mode           string        // 运行模式：debug, release, test
+	ReadTimeout    time.Duration // 读取超时时间
+	WriteTimeout   time.Duration // 写入超时时间
+	TrustedProxies []string      // 信任的反向代理 IP 或网段，只有来自这些地址的请求才按 X-Forwarded-For 取客户端 IP，为空表示不信任任何代理
 }
 
 // DatabaseConfig 数据库配置
@@ -50,8 +62,9 @@ type DatabaseConfig struct {
 
 // JWTConfig JWT 配置
 type JWTConfig struct {
-	Secret     string        // JWT 签名密钥
-	ExpireTime time.Duration // Token 过期时间
+	Secret            string        // JWT 签名密钥
+	ExpireTime        time.Duration // 访问令牌过期时间
+	RefreshExpireTime time.Duration // 刷新令牌过期时间，超过该时间未刷新需要重新登录
 }
 
 // LogConfig 日志配置
@@ -65,6 +78,83 @@ type LogConfig struct {
 	Console    bool   // 是否输出到控制台
 }
 
+// EvacuationConfig 疏散图配置
+type EvacuationConfig struct {
+	MaxDistance int // 房间到最近安全出口的最大允许步行距离（平面图单位），超出的房间会被标记
+}
+
+// PricingConfig 动态定价配置
+type PricingConfig struct {
+	Interval    time.Duration // 定时重算房价日历的间隔
+	HorizonDays int           // 每次重算从今天起的天数（不超过 92 天）
+}
+
+// BookingConfig 预订配置
+type BookingConfig struct {
+	CheckInHour     int           // 按晚预订的入住时间（小时），用于与钟点房的时段比较
+	CheckOutHour    int           // 按晚预订的退房时间（小时）
+	CleaningBuffer  time.Duration // 钟点房与前后住客之间预留的清洁时间
+	BillingInterval time.Duration // 长住分期账单的出账检查间隔
+}
+
+// CaptchaConfig 图形验证码配置
+type CaptchaConfig struct {
+	Length         int           // 验证码位数
+	TTL            time.Duration // 验证码有效期
+	LoginThreshold int           // 同一账号在登录失败统计窗口（LoginConfig.FailWindow）内失败多少次后需要验证码
+	MaxStored      int           // 进程内最多保存的未过期验证码数量，超过后拒绝生成新验证码
+}
+
+// VerificationConfig 短信/邮件验证码配置
+type VerificationConfig struct {
+	TTL            time.Duration // 验证码有效期
+	ResendInterval time.Duration // 同一目标同一用途两次发送的最小间隔
+	TargetDaily    int           // 同一手机号或邮箱 24 小时内最多发送次数
+	IPHourly       int           // 同一 IP 1 小时内最多请求发送次数
+	MaxAttempts    int           // 单个验证码最多校验失败次数，超过后作废
+	Sender         string        // 发送通道：console（输出到日志）, file（写入发件箱文件）
+	OutboxFile     string        // file 通道的发件箱文件路径
+}
+
+// PasswordConfig 密码重置与邀请配置
+type PasswordConfig struct {
+	ResetTTL       time.Duration // 找回密码链接的有效期
+	InviteTTL      time.Duration // 管理员邀请链接的有效期
+	ResendInterval time.Duration // 同一账号两次申请找回密码的最小间隔
+	ResetURL       string        // 前端设置新密码页面地址，令牌以 token 参数附加在后面
+}
+
+// LoginConfig 登录防暴力破解配置
+type LoginConfig struct {
+	MaxFailures    int           // 同一账号在统计窗口内连续失败多少次后锁定
+	FailWindow     time.Duration // 失败次数的统计窗口，最后一次失败后超过该时间清零
+	LockBase       time.Duration // 首次锁定时长，之后每次连续锁定翻倍
+	LockMax        time.Duration // 锁定时长上限
+	IPMaxFailures  int           // 同一 IP 在统计窗口内失败多少次后暂停其登录
+	IPLockDuration time.Duration // IP 暂停登录的时长
+}
+
+// WechatConfig 微信小程序登录配置
+type WechatConfig struct {
+	AppID     string        // 小程序 AppID
+	AppSecret string        // 小程序 AppSecret
+	Client    string        // 登录凭证校验方式：api（调用微信接口）, stub（本地模拟，用于开发和测试）
+	Timeout   time.Duration // 调用微信接口的超时时间
+}
+
+// MFAConfig 两步验证配置
+type MFAConfig struct {
+	Issuer        string        // 身份验证器 App 中显示的签发方名称
+	ChallengeTTL  time.Duration // 密码验证通过后完成第二步验证的时限
+	MaxAttempts   int           // 每次登录第二步验证最多失败次数
+	RecoveryCodes int           // 每次生成的恢复码数量
+}
+
+// UserConfig 用户账号配置
+type UserConfig struct {
+	DeletedRetention time.Duration // 已删除用户在回收站中的保留时间，期满后匿名化且不可恢复
+}
+
 // RedisConfig Redis 配置
 type RedisConfig struct {
 	Host     string // Redis 主机地址
@@ -85,10 +175,11 @@ func Load() error {
 
 	AppConfig = &Config{
 		Server: ServerConfig{
-			Port:         getEnv("SERVER_PORT", ":8080"),
-			Mode:         getEnv("SERVER_MODE", "debug"),
-			ReadTimeout:  getDurationEnv("SERVER_READ_TIMEOUT", 10*time.Second),
-			WriteTimeout: getDurationEnv("SERVER_WRITE_TIMEOUT", 10*time.Second),
+			Port:           getEnv("SERVER_PORT", ":8080"),
+			Mode:           getEnv("SERVER_MODE", "debug"),
+			ReadTimeout:    getDurationEnv("SERVER_READ_TIMEOUT", 10*time.Second),
+			WriteTimeout:   getDurationEnv("SERVER_WRITE_TIMEOUT", 10*time.Second),
+			TrustedProxies: getListEnv("SERVER_TRUSTED_PROXIES", nil),
 		},
 		Database: DatabaseConfig{
 			Host:            getEnv("DB_HOST", "localhost"),
@@ -101,8 +192,9 @@ func Load() error {
 			ConnMaxLifetime: getDurationEnv("DB_CONN_MAX_LIFETIME", time.Hour),
 		},
 		JWT: JWTConfig{
-			Secret:     getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
-			ExpireTime: getDurationEnv("JWT_EXPIRE_TIME", 24*time.Hour),
+			Secret:            getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
+			ExpireTime:        getDurationEnv("JWT_EXPIRE_TIME", 15*time.Minute),
+			RefreshExpireTime: getDurationEnv("JWT_REFRESH_EXPIRE_TIME", 7*24*time.Hour),
 		},
 		Redis: RedisConfig{
 			Host:     getEnv("REDIS_HOST", "localhost"),
@@ -125,6 +217,63 @@ func Load() error {
 			Compress:   getEnv("LOG_COMPRESS", "true") == "true",
 			Console:    getEnv("LOG_CONSOLE", "true") == "true",
 		},
+		Evacuation: EvacuationConfig{
+			MaxDistance: getIntEnv("EVACUATION_MAX_DISTANCE", 400),
+		},
+		Pricing: PricingConfig{
+			Interval:    getDurationEnv("PRICING_INTERVAL", 30*time.Minute),
+			HorizonDays: getIntEnv("PRICING_HORIZON_DAYS", 90),
+		},
+		Booking: BookingConfig{
+			CheckInHour:     getIntEnv("BOOKING_CHECKIN_HOUR", 14),
+			CheckOutHour:    getIntEnv("BOOKING_CHECKOUT_HOUR", 12),
+			CleaningBuffer:  getDurationEnv("BOOKING_CLEANING_BUFFER", 30*time.Minute),
+			BillingInterval: getDurationEnv("BOOKING_BILLING_INTERVAL", time.Hour),
+		},
+		Captcha: CaptchaConfig{
+			Length:         getIntEnv("CAPTCHA_LENGTH", 4),
+			TTL:            getDurationEnv("CAPTCHA_TTL", 5*time.Minute),
+			LoginThreshold: getIntEnv("CAPTCHA_LOGIN_THRESHOLD", 3),
+			MaxStored:      getIntEnv("CAPTCHA_MAX_STORED", 10000),
+		},
+		Verify: VerificationConfig{
+			TTL:            getDurationEnv("VERIFY_CODE_TTL", 10*time.Minute),
+			ResendInterval: getDurationEnv("VERIFY_RESEND_INTERVAL", time.Minute),
+			TargetDaily:    getIntEnv("VERIFY_TARGET_DAILY_LIMIT", 10),
+			IPHourly:       getIntEnv("VERIFY_IP_HOURLY_LIMIT", 20),
+			MaxAttempts:    getIntEnv("VERIFY_MAX_ATTEMPTS", 5),
+			Sender:         getEnv("VERIFY_SENDER", "console"),
+			OutboxFile:     getEnv("VERIFY_OUTBOX_FILE", "logs/outbox.jsonl"),
+		},
+		Password: PasswordConfig{
+			ResetTTL:       getDurationEnv("PASSWORD_RESET_TTL", 30*time.Minute),
+			InviteTTL:      getDurationEnv("PASSWORD_INVITE_TTL", 72*time.Hour),
+			ResendInterval: getDurationEnv("PASSWORD_RESET_INTERVAL", time.Minute),
+			ResetURL:       getEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+		},
+		Login: LoginConfig{
+			MaxFailures:    getIntEnv("LOGIN_MAX_FAILURES", 5),
+			FailWindow:     getDurationEnv("LOGIN_FAIL_WINDOW", 15*time.Minute),
+			LockBase:       getDurationEnv("LOGIN_LOCK_BASE", 5*time.Minute),
+			LockMax:        getDurationEnv("LOGIN_LOCK_MAX", 24*time.Hour),
+			IPMaxFailures:  getIntEnv("LOGIN_IP_MAX_FAILURES", 20),
+			IPLockDuration: getDurationEnv("LOGIN_IP_LOCK_DURATION", 15*time.Minute),
+		},
+		Wechat: WechatConfig{
+			AppID:     getEnv("WECHAT_APPID", ""),
+			AppSecret: getEnv("WECHAT_SECRET", ""),
+			Client:    getEnv("WECHAT_CLIENT", "stub"),
+			Timeout:   getDurationEnv("WECHAT_TIMEOUT", 5*time.Second),
+		},
+		MFA: MFAConfig{
+			Issuer:        getEnv("MFA_ISSUER", "GoHotel"),
+			ChallengeTTL:  getDurationEnv("MFA_CHALLENGE_TTL", 5*time.Minute),
+			MaxAttempts:   getIntEnv("MFA_MAX_ATTEMPTS", 5),
+			RecoveryCodes: getIntEnv("MFA_RECOVERY_CODES", 10),
+		},
+		User: UserConfig{
+			DeletedRetention: getDurationEnv("USER_DELETED_RETENTION", 30*24*time.Hour),
+		},
 	}
 
 	return nil
@@ -155,6 +304,21 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
+// getListEnv 获取逗号分隔的列表类型的环境变量，忽略空白项
+func getListEnv(key string, defaultValue []string) []string {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	var values []string
+	for _, item := range strings.Split(valueStr, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
 // getIntEnv 获取整数类型的环境变量
 func getIntEnv(key string, defaultValue int) int {
 	valueStr := os.Getenv(key)
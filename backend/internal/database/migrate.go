@@ -1,10 +1,16 @@
 package database
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"gohotel/internal/models"
+	"gohotel/pkg/utils"
+
+	"gorm.io/gorm"
 )
 
 // AutoMigrate 自动迁移数据库
@@ -25,6 +31,33 @@ func AutoMigrate() error {
 		&models.Log{},
 		&models.Banner{},
 		&models.Notice{},
+		&models.HousekeepingTask{},
+		&models.WorkOrder{},
+		&models.Amenity{},
+		&models.RoomPhoto{},
+		&models.DataMigration{},
+		&models.Review{},
+		&models.FloorLayoutVersion{},
+		&models.Hotel{},
+		&models.HotelStaff{},
+		&models.PricingRule{},
+		&models.RoomRate{},
+		&models.PriceChangeLog{},
+		&models.StayDiscount{},
+		&models.LongStayPolicy{},
+		&models.BookingInstallment{},
+		&models.Role{},
+		&models.RolePermission{},
+		&models.UserRole{},
+		&models.AuthSession{},
+		&models.RefreshToken{},
+		&models.VerificationCode{},
+		&models.PasswordResetToken{},
+		&models.LoginLockout{},
+		&models.WechatAccount{},
+		&models.UserTOTP{},
+		&models.MFARecoveryCode{},
+		&models.MFAChallenge{},
 	)
 
 	if err != nil {
@@ -98,3 +131,280 @@ func SeedData() error {
 	log.Printf("✅ 成功插入 %d 条房间数据", len(rooms))
 	return nil
 }
+
+// runOnce 执行一次性数据迁移，迁移和执行记录在同一事务中提交，已执行过的迁移直接跳过
+func runOnce(name string, migrate func(tx *gorm.DB) error) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.DataMigration{}).Where("name = ?", name).Count(&count).Error; err != nil {
+			return fmt.Errorf("查询数据迁移记录失败: %w", err)
+		}
+		if count > 0 {
+			return nil
+		}
+
+		if err := migrate(tx); err != nil {
+			return err
+		}
+		if err := tx.Create(&models.DataMigration{Name: name, AppliedAt: time.Now()}).Error; err != nil {
+			return fmt.Errorf("记录数据迁移 %s 失败: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// MigrateRoomAmenities 将房间 Facilities 字段中的 JSON 设施列表迁移到设施目录
+// 只执行一次，之后房间设施以设施目录为准，管理员清空的设施不会被旧字段恢复
+func MigrateRoomAmenities() error {
+	migrated := 0
+	err := runOnce("room_amenities", func(tx *gorm.DB) error {
+		var rooms []models.Room
+		err := tx.Where("facilities <> ''").
+			Where("id NOT IN (?)", tx.Table("room_amenities").Select("room_id")).
+			Find(&rooms).Error
+		if err != nil {
+			return fmt.Errorf("查询待迁移房间失败: %w", err)
+		}
+
+		cache := make(map[string]models.Amenity)
+		for i := range rooms {
+			names := parseFacilityNames(rooms[i].Facilities)
+			if len(names) == 0 {
+				continue
+			}
+
+			amenities := make([]models.Amenity, 0, len(names))
+			for _, name := range names {
+				amenity, ok := cache[name]
+				if !ok {
+					if err := tx.Where(models.Amenity{Name: name}).FirstOrCreate(&amenity).Error; err != nil {
+						return fmt.Errorf("创建设施 %s 失败: %w", name, err)
+					}
+					cache[name] = amenity
+				}
+				amenities = append(amenities, amenity)
+			}
+
+			if err := tx.Model(&rooms[i]).Association("Amenities").Append(amenities); err != nil {
+				return fmt.Errorf("关联房间 %s 设施失败: %w", rooms[i].RoomNumber, err)
+			}
+			migrated++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if migrated > 0 {
+		log.Printf("✅ 成功迁移 %d 个房间的设施数据", migrated)
+	}
+	return nil
+}
+
+// parseFacilityNames 解析设施字段
+// 优先按 JSON 字符串数组解析，失败时按逗号分隔解析，结果去重
+func parseFacilityNames(raw string) []string {
+	var items []string
+	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+		items = strings.FieldsFunc(raw, func(r rune) bool {
+			return r == ',' || r == '，'
+		})
+	}
+
+	seen := make(map[string]bool, len(items))
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		name := strings.TrimSpace(item)
+		if name == "" || len([]rune(name)) > 50 || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// MigrateRoomPhotos 将房间 Images 字段中的 JSON 图片列表迁移到房间图库
+// 只执行一次，第一张图片作为封面；之后房间图片以图库为准，删光图片的房间不会被旧字段恢复
+func MigrateRoomPhotos() error {
+	migrated := 0
+	err := runOnce("room_photos", func(tx *gorm.DB) error {
+		var rooms []models.Room
+		err := tx.Where("images <> '' AND images <> '[]'").
+			Where("id NOT IN (?)", tx.Model(&models.RoomPhoto{}).Select("room_id")).
+			Find(&rooms).Error
+		if err != nil {
+			return fmt.Errorf("查询待迁移房间失败: %w", err)
+		}
+
+		var photos []models.RoomPhoto
+		for _, room := range rooms {
+			var urls []string
+			if err := json.Unmarshal([]byte(room.Images), &urls); err != nil {
+				log.Printf("⚠️  房间 %s 的图片数据格式错误，跳过迁移", room.RoomNumber)
+				continue
+			}
+			for i, u := range urls {
+				u = strings.TrimSpace(u)
+				if u == "" {
+					continue
+				}
+				photos = append(photos, models.RoomPhoto{
+					RoomID:  room.ID,
+					URL:     u,
+					Sort:    i,
+					IsCover: i == 0,
+				})
+			}
+		}
+		if len(photos) == 0 {
+			return nil
+		}
+
+		if err := tx.Create(&photos).Error; err != nil {
+			return fmt.Errorf("迁移房间图片失败: %w", err)
+		}
+		migrated = len(photos)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if migrated > 0 {
+		log.Printf("✅ 成功迁移 %d 张房间图片", migrated)
+	}
+	return nil
+}
+
+// hotelScopedTables 按酒店隔离数据的表
+var hotelScopedTables = []string{
+	"rooms", "bookings", "facilities", "banners", "notices", "reviews", "floor_layout_versions",
+}
+
+// MigrateHotels 将单酒店时期的数据迁移到默认酒店
+// 首次执行时创建默认酒店并把现有管理员分配到该酒店；
+// 每次执行都会把未归属酒店（hotel_id 为 0）的数据归入默认酒店，并删除房间号的全局唯一索引，可重复执行
+func MigrateHotels() error {
+	var hotel models.Hotel
+	err := DB.Order("id").First(&hotel).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return fmt.Errorf("查询酒店失败: %w", err)
+	}
+
+	if err == gorm.ErrRecordNotFound {
+		hotel = models.Hotel{Code: "default", Name: "默认酒店", Status: "active"}
+		err = DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&hotel).Error; err != nil {
+				return fmt.Errorf("创建默认酒店失败: %w", err)
+			}
+
+			var adminIDs []int64
+			if err := tx.Model(&models.User{}).Where("role = ?", "admin").Pluck("id", &adminIDs).Error; err != nil {
+				return fmt.Errorf("查询管理员失败: %w", err)
+			}
+			if len(adminIDs) == 0 {
+				return nil
+			}
+			staff := make([]models.HotelStaff, len(adminIDs))
+			for i, id := range adminIDs {
+				staff[i] = models.HotelStaff{HotelID: hotel.ID, UserID: utils.JSONInt64(id)}
+			}
+			if err := tx.Create(&staff).Error; err != nil {
+				return fmt.Errorf("分配管理员到默认酒店失败: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		log.Printf("✅ 已创建默认酒店 #%d", hotel.ID)
+	}
+
+	for _, table := range hotelScopedTables {
+		result := DB.Table(table).Where("hotel_id = ?", 0).Update("hotel_id", hotel.ID)
+		if result.Error != nil {
+			return fmt.Errorf("迁移 %s 的酒店归属失败: %w", table, result.Error)
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("✅ 已将 %d 条 %s 数据归入默认酒店", result.RowsAffected, table)
+		}
+	}
+
+	// 房间号改为酒店内唯一，删除旧版本创建的全局唯一索引
+	for _, name := range []string{"uni_rooms_room_number", "idx_rooms_room_number", "room_number"} {
+		if DB.Migrator().HasIndex(&models.Room{}, name) {
+			if err := DB.Migrator().DropIndex(&models.Room{}, name); err != nil {
+				return fmt.Errorf("删除房间号唯一索引失败: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// MigrateRoles 写入内置角色，并为旧版本的管理员分配超级管理员角色
+// 已存在的内置角色保留管理员调整后的权限，可重复执行；
+// 分配超级管理员只在首次执行时进行一次，之后 role 字段不再授予任何权限
+func MigrateRoles() error {
+	for _, builtIn := range models.BuiltInRoles {
+		var count int64
+		if err := DB.Model(&models.Role{}).Where("code = ?", builtIn.Code).Count(&count).Error; err != nil {
+			return fmt.Errorf("查询角色失败: %w", err)
+		}
+		if count > 0 {
+			continue
+		}
+
+		role := models.Role{
+			Code:        builtIn.Code,
+			Name:        builtIn.Name,
+			Description: builtIn.Description,
+			BuiltIn:     true,
+			Permissions: append([]models.RolePermission(nil), builtIn.Permissions...),
+		}
+		if err := DB.Create(&role).Error; err != nil {
+			return fmt.Errorf("创建内置角色 %s 失败: %w", builtIn.Code, err)
+		}
+		log.Printf("✅ 已创建内置角色 %s", role.Name)
+	}
+
+	assigned := 0
+	err := runOnce("admin_roles", func(tx *gorm.DB) error {
+		var superAdmin models.Role
+		if err := tx.Where("code = ?", models.RoleSuperAdmin).First(&superAdmin).Error; err != nil {
+			return fmt.Errorf("查询超级管理员角色失败: %w", err)
+		}
+
+		var adminIDs []int64
+		err := tx.Model(&models.User{}).
+			Where("role = ?", "admin").
+			Where("id NOT IN (?)", tx.Model(&models.UserRole{}).Select("user_id")).
+			Pluck("id", &adminIDs).Error
+		if err != nil {
+			return fmt.Errorf("查询管理员失败: %w", err)
+		}
+		if len(adminIDs) == 0 {
+			return nil
+		}
+
+		assignments := make([]models.UserRole, len(adminIDs))
+		for i, id := range adminIDs {
+			assignments[i] = models.UserRole{UserID: utils.JSONInt64(id), RoleID: superAdmin.ID}
+		}
+		if err := tx.Create(&assignments).Error; err != nil {
+			return fmt.Errorf("分配超级管理员角色失败: %w", err)
+		}
+		assigned = len(adminIDs)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if assigned > 0 {
+		log.Printf("✅ 已为 %d 名管理员分配超级管理员角色", assigned)
+	}
+	return nil
+}
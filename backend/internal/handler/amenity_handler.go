@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"gohotel/internal/service"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AmenityHandler 房间设施目录控制器
+type AmenityHandler struct {
+	amenityService *service.AmenityService
+}
+
+// NewAmenityHandler 创建设施目录控制器实例
+func NewAmenityHandler(amenityService *service.AmenityService) *AmenityHandler {
+	return &AmenityHandler{amenityService: amenityService}
+}
+
+// ListAmenities 获取设施目录
+// @Summary 获取设施目录
+// @Description 获取所有设施（名称、图标、分类），可按分类筛选
+// @Tags 设施目录
+// @Accept json
+// @Produce json
+// @Param category query string false "分类"
+// @Success 200 {array} models.Amenity
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /api/amenities [get]
+func (h *AmenityHandler) ListAmenities(c *gin.Context) {
+	amenities, err := h.amenityService.ListAmenities(c.Query("category"))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, amenities)
+}
+
+// CreateAmenity 创建设施（管理员）
+// @Summary 创建设施（管理员）
+// @Description 在设施目录中新增设施
+// @Tags 设施目录
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.CreateAmenityRequest true "设施信息"
+// @Success 200 {object} models.Amenity
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse
+// @Router /api/admin/amenities [post]
+func (h *AmenityHandler) CreateAmenity(c *gin.Context) {
+	var req service.CreateAmenityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	amenity, err := h.amenityService.CreateAmenity(&req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "设施创建成功", amenity)
+}
+
+// UpdateAmenity 更新设施（管理员）
+// @Summary 更新设施（管理员）
+// @Description 更新设施名称、图标、分类或排序
+// @Tags 设施目录
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "设施 ID"
+// @Param request body service.UpdateAmenityRequest true "设施信息"
+// @Success 200 {object} models.Amenity
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/amenities/{id} [post]
+func (h *AmenityHandler) UpdateAmenity(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的设施ID"))
+		return
+	}
+
+	var req service.UpdateAmenityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	amenity, err := h.amenityService.UpdateAmenity(uint(id), &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "设施更新成功", amenity)
+}
+
+// DeleteAmenity 删除设施（管理员）
+// @Summary 删除设施（管理员）
+// @Description 删除设施，并解除所有房间与该设施的关联
+// @Tags 设施目录
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "设施 ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/amenities/{id}/delete [post]
+func (h *AmenityHandler) DeleteAmenity(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的设施ID"))
+		return
+	}
+
+	if err := h.amenityService.DeleteAmenity(uint(id)); err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "设施删除成功", nil)
+}
+
+// SetRoomAmenities 设置房间设施（管理员）
+// @Summary 设置房间设施（管理员）
+// @Description 用给定的设施 ID 列表替换房间现有设施
+// @Tags 设施目录
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "房间 ID"
+// @Param request body service.SetRoomAmenitiesRequest true "设施 ID 列表"
+// @Success 200 {object} models.Room
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/rooms/{id}/amenities [post]
+func (h *AmenityHandler) SetRoomAmenities(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的房间ID"))
+		return
+	}
+
+	var req service.SetRoomAmenitiesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	room, err := h.amenityService.SetRoomAmenities(c.GetUint("hotel_id"), uint(id), &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "房间设施更新成功", room)
+}
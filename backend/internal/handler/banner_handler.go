@@ -88,7 +88,7 @@ func (h *BannerHandler) CreateBanner(c *gin.Context) {
 	}
 
 	// 调用服务层创建Banner
-	banner, err := h.bannerService.CreateBanner(req)
+	banner, err := h.bannerService.CreateBanner(c.GetUint("hotel_id"), req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建失败: " + err.Error()})
 		return
@@ -118,7 +118,7 @@ func (h *BannerHandler) GetBannerByID(c *gin.Context) {
 		return
 	}
 
-	banner, err := h.bannerService.GetBannerByID(int64(id))
+	banner, err := h.bannerService.GetBannerByID(c.GetUint("hotel_id"), int64(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "活动横幅不存在"})
 		return
@@ -143,7 +143,7 @@ func (h *BannerHandler) GetAllBanners(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
 
-	banners, total, err := h.bannerService.GetAllBanners(page, pageSize)
+	banners, total, err := h.bannerService.GetAllBanners(c.GetUint("hotel_id"), page, pageSize)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取失败: " + err.Error()})
 		return
@@ -163,11 +163,12 @@ func (h *BannerHandler) GetAllBanners(c *gin.Context) {
 // @Tags 活动管理
 // @Accept json
 // @Produce json
+// @Param hotel_id query int false "酒店 ID，不传时使用默认酒店"
 // @Success 200 {object} utils.Response{data=[]models.Banner}
 // @Failure 500 {object} utils.Response
 // @Router /api/banners/active [get]
 func (h *BannerHandler) GetActiveBanners(c *gin.Context) {
-	banners, err := h.bannerService.GetActiveBanners()
+	banners, err := h.bannerService.GetActiveBanners(c.GetUint("hotel_id"))
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
@@ -206,7 +207,7 @@ func (h *BannerHandler) UpdateBanner(c *gin.Context) {
 	}
 
 	// 检查Banner是否存在
-	_, err = h.bannerService.GetBannerByID(id)
+	_, err = h.bannerService.GetBannerByID(c.GetUint("hotel_id"), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Banner不存在"})
 		return
@@ -270,7 +271,7 @@ func (h *BannerHandler) UpdateBanner(c *gin.Context) {
 	}
 
 	// 调用服务层更新Banner
-	banner, err := h.bannerService.UpdateBanner(id, req)
+	banner, err := h.bannerService.UpdateBanner(c.GetUint("hotel_id"), id, req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新失败: " + err.Error()})
 		return
@@ -299,7 +300,7 @@ func (h *BannerHandler) DeleteBanner(c *gin.Context) {
 		return
 	}
 
-	if err := h.bannerService.DeleteBanner(int64(id)); err != nil {
+	if err := h.bannerService.DeleteBanner(c.GetUint("hotel_id"), int64(id)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除失败: " + err.Error()})
 		return
 	}
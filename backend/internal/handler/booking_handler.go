@@ -22,7 +22,7 @@ func NewBookingHandler(bookingService *service.BookingService) *BookingHandler {
 
 // CreateBooking 创建预订
 // @Summary 创建预订
-// @Description 创建新的房间预订，需要登录
+// @Description 创建新的房间预订，需要登录。booking_type 为 hourly 时按钟点房预订，需传 start_time/end_time；连住达到折扣档位时自动打折，满 30 晚可选择按月付款
 // @Tags 预订
 // @Accept json
 // @Produce json
@@ -175,7 +175,7 @@ func (h *BookingHandler) ConfirmBooking(c *gin.Context) {
 		return
 	}
 
-	err = h.bookingService.ConfirmBooking(id)
+	err = h.bookingService.ConfirmBooking(c.GetUint("hotel_id"), id)
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
@@ -207,7 +207,7 @@ func (h *BookingHandler) CheckIn(c *gin.Context) {
 		return
 	}
 
-	err = h.bookingService.CheckIn(id)
+	err = h.bookingService.CheckIn(c.GetUint("hotel_id"), id)
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
@@ -239,7 +239,7 @@ func (h *BookingHandler) CheckOut(c *gin.Context) {
 		return
 	}
 
-	err = h.bookingService.CheckOut(id)
+	err = h.bookingService.CheckOut(c.GetUint("hotel_id"), id)
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
@@ -248,6 +248,42 @@ func (h *BookingHandler) CheckOut(c *gin.Context) {
 	utils.SuccessWithMessage(c, "退房办理成功", nil)
 }
 
+// PayInstallment 登记分期账单已支付（管理员）
+// @Summary 登记分期账单已支付（管理员）
+// @Description 按月付款的预订逐期登记已出账的押金或房费，全部付清后预订标记为已支付
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "预订 ID"
+// @Param installment_id path int true "账单 ID"
+// @Success 200 {object} models.BookingInstallment
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/bookings/{id}/installments/{installment_id}/pay [post]
+func (h *BookingHandler) PayInstallment(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的预订ID，请确保传入有效的数字字符串"))
+		return
+	}
+	installmentID, err := strconv.ParseUint(c.Param("installment_id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的账单ID"))
+		return
+	}
+
+	installment, err := h.bookingService.PayInstallment(c.GetUint("hotel_id"), id, uint(installmentID))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "账单支付登记成功", installment)
+}
+
 // ListAllBookings 获取所有预订（管理员）
 // @Summary 获取所有预订（管理员）
 // @Description 管理员获取所有预订列表，支持分页
@@ -266,7 +302,7 @@ func (h *BookingHandler) ListAllBookings(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
 
-	bookings, total, err := h.bookingService.ListAllBookings(page, pageSize)
+	bookings, total, err := h.bookingService.ListAllBookings(c.GetUint("hotel_id"), page, pageSize)
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
@@ -292,7 +328,7 @@ func (h *BookingHandler) SearchBookingsByGuestInfo(c *gin.Context) {
 	guestPhone := c.Query("guest_phone")
 	status := c.Query("status")
 
-	bookings, err := h.bookingService.GetByGuestInfo(guestName, guestPhone, status)
+	bookings, err := h.bookingService.GetByGuestInfo(c.GetUint("hotel_id"), guestName, guestPhone, status)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -326,7 +362,7 @@ func (h *BookingHandler) GetBookingsByRoomNumberAndStatus(c *gin.Context) {
 	}
 	status := c.Query("status")
 
-	bookings, err := h.bookingService.GetBookingsByRoomNumberAndStatus(roomNumber, status)
+	bookings, err := h.bookingService.GetBookingsByRoomNumberAndStatus(c.GetUint("hotel_id"), roomNumber, status)
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"gohotel/internal/service"
+	"gohotel/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CaptchaHandler 图形验证码控制器
+type CaptchaHandler struct {
+	captchaService *service.CaptchaService
+}
+
+// NewCaptchaHandler 创建图形验证码控制器实例
+func NewCaptchaHandler(captchaService *service.CaptchaService) *CaptchaHandler {
+	return &CaptchaHandler{captchaService: captchaService}
+}
+
+// GetCaptcha 获取图形验证码
+// @Summary 获取图形验证码
+// @Description 生成数字图形验证码，注册时必填；同一账号登录失败次数过多（错误码 CAPTCHA_REQUIRED）后登录也需要填写。每个验证码只能校验一次
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Success 200 {object} service.CaptchaResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /api/auth/captcha [get]
+func (h *CaptchaHandler) GetCaptcha(c *gin.Context) {
+	captcha, err := h.captchaService.Generate()
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, captcha)
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"gohotel/internal/service"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EvacuationHandler 疏散图控制器
+type EvacuationHandler struct {
+	evacuationService *service.EvacuationService
+}
+
+// NewEvacuationHandler 创建疏散图控制器实例
+func NewEvacuationHandler(evacuationService *service.EvacuationService) *EvacuationHandler {
+	return &EvacuationHandler{evacuationService: evacuationService}
+}
+
+// GetEvacuationPlan 获取楼层疏散图
+// @Summary 获取楼层疏散图
+// @Description 以走廊为可通行区域计算每个房间到最近安全出口（exit 类型设施）的路线，并标记超出最大疏散距离的房间；format=svg 时返回可打印的疏散图，可用 room_number 突出显示张贴位置
+// @Tags 楼层平面图
+// @Accept json
+// @Produce json,image/svg+xml
+// @Param floor path int true "楼层"
+// @Param format query string false "输出格式：json（默认）, svg"
+// @Param room_number query string false "突出显示的房间号（仅 svg）"
+// @Param hotel_id query int false "酒店 ID，不传时使用默认酒店"
+// @Success 200 {object} service.EvacuationPlan
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/floors/{floor}/evacuation [get]
+func (h *EvacuationHandler) GetEvacuationPlan(c *gin.Context) {
+	floor, ok := parseFloor(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "svg" {
+		utils.ErrorResponse(c, errors.NewBadRequestError("不支持的格式，可选: json, svg"))
+		return
+	}
+
+	plan, err := h.evacuationService.GetEvacuationPlan(c.GetUint("hotel_id"), floor)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	if format == "svg" {
+		c.Data(http.StatusOK, "image/svg+xml; charset=utf-8", service.RenderEvacuationPlanSVG(plan, c.Query("room_number")))
+		return
+	}
+
+	utils.SuccessResponse(c, plan)
+}
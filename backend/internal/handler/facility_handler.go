@@ -39,7 +39,7 @@ func (h *FacilityHandler) CreateFacility(c *gin.Context) {
 		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
 		return
 	}
-	facility, err := h.facilityService.CreateFacility(&req)
+	facility, err := h.facilityService.CreateFacility(c.GetUint("hotel_id"), &req)
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
@@ -49,7 +49,7 @@ func (h *FacilityHandler) CreateFacility(c *gin.Context) {
 
 // UpdateFacility 更新设施
 // @Summary 更新设施（管理员）
-// @Description 管理员更新设施
+// @Description 管理员更新设施类型、楼层和标签，坐标通过楼层布局草稿修改；更换楼层后设施需要在新楼层重新摆放
 // @Tags 管理员
 // @Accept json
 // @Produce json
@@ -75,7 +75,7 @@ func (h *FacilityHandler) UpdateFacility(c *gin.Context) {
 		return
 	}
 
-	facility, err := h.facilityService.UpdateFacility(uint(id), &req)
+	facility, err := h.facilityService.UpdateFacility(c.GetUint("hotel_id"), uint(id), &req)
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
@@ -104,7 +104,7 @@ func (h *FacilityHandler) DeleteFacility(c *gin.Context) {
 		utils.ErrorResponse(c, errors.NewBadRequestError("无效的设施ID"))
 		return
 	}
-	err = h.facilityService.DeleteFacility(uint(id))
+	err = h.facilityService.DeleteFacility(c.GetUint("hotel_id"), uint(id))
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
@@ -130,7 +130,7 @@ func (h *FacilityHandler) FindFacilityByID(c *gin.Context) {
 		utils.ErrorResponse(c, errors.NewBadRequestError("无效的设施ID"))
 		return
 	}
-	facility, err := h.facilityService.FindFacilityByID(uint(id))
+	facility, err := h.facilityService.FindFacilityByID(c.GetUint("hotel_id"), uint(id))
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
@@ -153,7 +153,7 @@ func (h *FacilityHandler) FindFacilityByID(c *gin.Context) {
 func (h *FacilityHandler) FindAllFacilities(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-	facilities, total, err := h.facilityService.FindAllFacilities(page, pageSize)
+	facilities, total, err := h.facilityService.FindAllFacilities(c.GetUint("hotel_id"), page, pageSize)
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
@@ -178,7 +178,7 @@ func (h *FacilityHandler) FindFacilitiesByFloor(c *gin.Context) {
 		utils.ErrorResponse(c, errors.NewBadRequestError("无效的楼层"))
 		return
 	}
-	facilities, err := h.facilityService.FindFacilitiesByFloor(floor)
+	facilities, err := h.facilityService.FindFacilitiesByFloor(c.GetUint("hotel_id"), floor)
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
@@ -186,28 +186,31 @@ func (h *FacilityHandler) FindFacilitiesByFloor(c *gin.Context) {
 	utils.SuccessResponse(c, facilities)
 }
 
-// BatchUpdateFacilities 批量更新设施位置
-// @Summary 批量更新设施位置（管理员）
-// @Description 管理员批量更新设施的位置和尺寸信息
+// BatchUpdateFacilities 批量调整设施位置
+// @Summary 批量调整设施位置（管理员）
+// @Description 管理员批量调整设施的位置和尺寸，改动按楼层保存到布局草稿（保留草稿中已有的其他改动），发布草稿后才生效
 // @Tags 管理员
 // @Accept json
 // @Produce json
 // @Security Bearer
 // @Param request body service.BatchUpdateFacilitiesRequest true "设施位置信息"
-// @Success 200 {object} map[string]interface{}
+// @Success 200 {array} service.FloorLayoutVersionDetail
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 401 {object} errors.ErrorResponse
 // @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
 // @Router /api/admin/facilities/batch [post]
 func (h *FacilityHandler) BatchUpdateFacilities(c *gin.Context) {
+	userID, _ := c.Get("user_id")
 	var req service.BatchUpdateFacilitiesRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
 		return
 	}
-	if err := h.facilityService.BatchUpdateFacilities(&req); err != nil {
+	drafts, err := h.facilityService.BatchUpdateFacilities(userID.(int64), c.GetUint("hotel_id"), &req)
+	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
 	}
-	utils.SuccessWithMessage(c, "设施位置已批量更新", nil)
+	utils.SuccessWithMessage(c, "设施位置已保存到布局草稿，发布后生效", drafts)
 }
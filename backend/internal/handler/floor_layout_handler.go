@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"gohotel/internal/service"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FloorLayoutHandler 楼层布局版本控制器
+type FloorLayoutHandler struct {
+	layoutService *service.FloorLayoutService
+}
+
+// NewFloorLayoutHandler 创建楼层布局版本控制器实例
+func NewFloorLayoutHandler(layoutService *service.FloorLayoutService) *FloorLayoutHandler {
+	return &FloorLayoutHandler{layoutService: layoutService}
+}
+
+// ValidateLayout 校验楼层布局（管理员）
+// @Summary 校验楼层布局（管理员）
+// @Description 将提交的坐标改动合并到楼层当前布局，检查尺寸、越界和重叠（考虑设施旋转），不保存
+// @Tags 楼层布局
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param floor path int true "楼层"
+// @Param request body service.SaveLayoutDraftRequest true "布局改动"
+// @Success 200 {object} service.LayoutValidationResult
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/floors/{floor}/layout/validate [post]
+func (h *FloorLayoutHandler) ValidateLayout(c *gin.Context) {
+	floor, ok := parseFloor(c)
+	if !ok {
+		return
+	}
+
+	var req service.SaveLayoutDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	result, err := h.layoutService.ValidateLayout(c.GetUint("hotel_id"), floor, &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, result)
+}
+
+// SaveDraft 保存楼层布局草稿（管理员）
+// @Summary 保存楼层布局草稿（管理员）
+// @Description 校验通过后保存为楼层草稿（每个楼层一个草稿，再次保存会覆盖），发布前不影响线上布局
+// @Tags 楼层布局
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param floor path int true "楼层"
+// @Param request body service.SaveLayoutDraftRequest true "布局改动"
+// @Success 200 {object} service.FloorLayoutVersionDetail
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/floors/{floor}/layout/draft [post]
+func (h *FloorLayoutHandler) SaveDraft(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	floor, ok := parseFloor(c)
+	if !ok {
+		return
+	}
+
+	var req service.SaveLayoutDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	detail, err := h.layoutService.SaveDraft(userID.(int64), c.GetUint("hotel_id"), floor, &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "草稿保存成功", detail)
+}
+
+// ListVersions 获取楼层布局版本列表（管理员）
+// @Summary 获取楼层布局版本列表（管理员）
+// @Description 返回楼层的草稿、当前发布版本和历史版本，新版本在前
+// @Tags 楼层布局
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param floor path int true "楼层"
+// @Success 200 {array} models.FloorLayoutVersion
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/admin/floors/{floor}/layout/versions [get]
+func (h *FloorLayoutHandler) ListVersions(c *gin.Context) {
+	floor, ok := parseFloor(c)
+	if !ok {
+		return
+	}
+
+	versions, err := h.layoutService.ListVersions(c.GetUint("hotel_id"), floor)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, versions)
+}
+
+// GetVersion 获取楼层布局版本详情（管理员）
+// @Summary 获取楼层布局版本详情（管理员）
+// @Description 返回版本快照以及基于楼层当前状态的校验结果
+// @Tags 楼层布局
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param floor path int true "楼层"
+// @Param id path int true "版本 ID"
+// @Success 200 {object} service.FloorLayoutVersionDetail
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/floors/{floor}/layout/versions/{id} [get]
+func (h *FloorLayoutHandler) GetVersion(c *gin.Context) {
+	floor, id, ok := parseLayoutVersionID(c)
+	if !ok {
+		return
+	}
+
+	detail, err := h.layoutService.GetVersion(c.GetUint("hotel_id"), floor, id)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, detail)
+}
+
+// PublishDraft 发布楼层布局草稿（管理员）
+// @Summary 发布楼层布局草稿（管理员）
+// @Description 重新校验草稿后写入房间和设施坐标，原发布版本转为历史版本
+// @Tags 楼层布局
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param floor path int true "楼层"
+// @Param id path int true "草稿版本 ID"
+// @Success 200 {object} service.FloorLayoutVersionDetail
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/floors/{floor}/layout/versions/{id}/publish [post]
+func (h *FloorLayoutHandler) PublishDraft(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	floor, id, ok := parseLayoutVersionID(c)
+	if !ok {
+		return
+	}
+
+	detail, err := h.layoutService.PublishDraft(userID.(int64), c.GetUint("hotel_id"), floor, id)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "布局发布成功", detail)
+}
+
+// Rollback 回滚楼层布局（管理员）
+// @Summary 回滚楼层布局（管理员）
+// @Description 以历史版本的快照创建新版本并立即发布
+// @Tags 楼层布局
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param floor path int true "楼层"
+// @Param id path int true "历史版本 ID"
+// @Param request body service.RollbackLayoutRequest false "备注"
+// @Success 200 {object} service.FloorLayoutVersionDetail
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/floors/{floor}/layout/versions/{id}/rollback [post]
+func (h *FloorLayoutHandler) Rollback(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	floor, id, ok := parseLayoutVersionID(c)
+	if !ok {
+		return
+	}
+
+	var req service.RollbackLayoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+			return
+		}
+	}
+
+	detail, err := h.layoutService.Rollback(userID.(int64), c.GetUint("hotel_id"), floor, id, &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "布局回滚成功", detail)
+}
+
+// parseFloor 解析路径中的楼层，失败时直接写入错误响应
+func parseFloor(c *gin.Context) (int, bool) {
+	floor, err := strconv.Atoi(c.Param("floor"))
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的楼层"))
+		return 0, false
+	}
+	return floor, true
+}
+
+// parseLayoutVersionID 解析路径中的楼层和布局版本 ID，失败时直接写入错误响应
+func parseLayoutVersionID(c *gin.Context) (int, uint, bool) {
+	floor, ok := parseFloor(c)
+	if !ok {
+		return 0, 0, false
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的版本ID"))
+		return 0, 0, false
+	}
+	return floor, uint(id), true
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"gohotel/internal/service"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FloorPlanHandler 楼层平面图控制器
+type FloorPlanHandler struct {
+	floorPlanService *service.FloorPlanService
+}
+
+// NewFloorPlanHandler 创建楼层平面图控制器实例
+func NewFloorPlanHandler(floorPlanService *service.FloorPlanService) *FloorPlanHandler {
+	return &FloorPlanHandler{floorPlanService: floorPlanService}
+}
+
+// GetFloorPlan 获取楼层平面图
+// @Summary 获取楼层平面图
+// @Description 将楼层的房间和设施组合成平面图，房间按实时状态着色；format=svg 时返回可直接打印或嵌入的 SVG
+// @Tags 楼层平面图
+// @Accept json
+// @Produce json,image/svg+xml
+// @Param floor path int true "楼层"
+// @Param format query string false "输出格式：json（默认）, svg"
+// @Param hotel_id query int false "酒店 ID，不传时使用默认酒店"
+// @Success 200 {object} service.FloorPlan
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/floors/{floor}/plan [get]
+func (h *FloorPlanHandler) GetFloorPlan(c *gin.Context) {
+	floor, ok := parseFloor(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "svg" {
+		utils.ErrorResponse(c, errors.NewBadRequestError("不支持的格式，可选: json, svg"))
+		return
+	}
+
+	plan, err := h.floorPlanService.GetFloorPlan(c.GetUint("hotel_id"), floor)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	if format == "svg" {
+		c.Data(http.StatusOK, "image/svg+xml; charset=utf-8", service.RenderFloorPlanSVG(plan))
+		return
+	}
+
+	utils.SuccessResponse(c, plan)
+}
@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"gohotel/internal/service"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HotelHandler 酒店控制器
+type HotelHandler struct {
+	hotelService *service.HotelService
+}
+
+// NewHotelHandler 创建酒店控制器实例
+func NewHotelHandler(hotelService *service.HotelService) *HotelHandler {
+	return &HotelHandler{hotelService: hotelService}
+}
+
+// ListHotels 获取营业中的酒店列表
+// @Summary 获取酒店列表
+// @Description 获取营业中的酒店，用于客户端选择酒店；其他公开接口通过 hotel_id 参数或 X-Hotel-ID 请求头指定酒店
+// @Tags 酒店
+// @Accept json
+// @Produce json
+// @Param city query string false "城市"
+// @Success 200 {array} models.Hotel
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /api/hotels [get]
+func (h *HotelHandler) ListHotels(c *gin.Context) {
+	hotels, err := h.hotelService.ListActiveHotels(c.Query("city"))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, hotels)
+}
+
+// ListMyHotels 获取当前员工被分配的酒店（管理员）
+// @Summary 获取我管理的酒店（管理员）
+// @Description 获取当前员工被分配的酒店列表，管理接口通过 X-Hotel-ID 请求头选择其中一家
+// @Tags 酒店管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} models.Hotel
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/admin/hotels [get]
+func (h *HotelHandler) ListMyHotels(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	hotels, err := h.hotelService.ListMyHotels(userID.(int64))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, hotels)
+}
+
+// CreateHotel 创建酒店（管理员）
+// @Summary 创建酒店（管理员）
+// @Description 新增酒店，创建人自动分配到该酒店
+// @Tags 酒店管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.CreateHotelRequest true "酒店信息"
+// @Success 200 {object} models.Hotel
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse
+// @Router /api/admin/hotels [post]
+func (h *HotelHandler) CreateHotel(c *gin.Context) {
+	var req service.CreateHotelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	hotel, err := h.hotelService.CreateHotel(userID.(int64), &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "酒店创建成功", hotel)
+}
+
+// GetHotel 获取酒店详情（管理员）
+// @Summary 获取酒店详情（管理员）
+// @Description 获取被分配酒店的详细信息
+// @Tags 酒店管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "酒店 ID"
+// @Success 200 {object} models.Hotel
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/hotels/{id} [get]
+func (h *HotelHandler) GetHotel(c *gin.Context) {
+	id, ok := parseHotelID(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	hotel, err := h.hotelService.GetHotel(userID.(int64), id)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, hotel)
+}
+
+// UpdateHotel 更新酒店（管理员）
+// @Summary 更新酒店（管理员）
+// @Description 更新被分配酒店的信息，停业的酒店不再出现在公开接口中
+// @Tags 酒店管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "酒店 ID"
+// @Param request body service.UpdateHotelRequest true "酒店信息"
+// @Success 200 {object} models.Hotel
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/hotels/{id} [post]
+func (h *HotelHandler) UpdateHotel(c *gin.Context) {
+	id, ok := parseHotelID(c)
+	if !ok {
+		return
+	}
+
+	var req service.UpdateHotelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	hotel, err := h.hotelService.UpdateHotel(userID.(int64), id, &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "酒店更新成功", hotel)
+}
+
+// ListStaff 获取酒店员工列表（管理员）
+// @Summary 获取酒店员工列表（管理员）
+// @Description 获取分配到酒店的员工
+// @Tags 酒店管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "酒店 ID"
+// @Success 200 {array} models.HotelStaff
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/admin/hotels/{id}/staff [get]
+func (h *HotelHandler) ListStaff(c *gin.Context) {
+	id, ok := parseHotelID(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	staff, err := h.hotelService.ListStaff(userID.(int64), id)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, staff)
+}
+
+// AssignStaff 分配员工到酒店（管理员）
+// @Summary 分配员工到酒店（管理员）
+// @Description 将员工分配到酒店，已分配的员工会被忽略
+// @Tags 酒店管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "酒店 ID"
+// @Param request body service.AssignHotelStaffRequest true "员工 ID 列表"
+// @Success 200 {array} models.HotelStaff
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/hotels/{id}/staff [post]
+func (h *HotelHandler) AssignStaff(c *gin.Context) {
+	id, ok := parseHotelID(c)
+	if !ok {
+		return
+	}
+
+	var req service.AssignHotelStaffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	staff, err := h.hotelService.AssignStaff(userID.(int64), id, &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "员工分配成功", staff)
+}
+
+// RemoveStaff 取消员工的酒店分配（管理员）
+// @Summary 取消员工的酒店分配（管理员）
+// @Description 将员工从酒店移除，酒店至少保留一名员工
+// @Tags 酒店管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "酒店 ID"
+// @Param user_id path string true "员工 ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/hotels/{id}/staff/{user_id}/delete [post]
+func (h *HotelHandler) RemoveStaff(c *gin.Context) {
+	id, ok := parseHotelID(c)
+	if !ok {
+		return
+	}
+	staffUserID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的员工ID"))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.hotelService.RemoveStaff(userID.(int64), id, staffUserID); err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "员工已移出酒店", nil)
+}
+
+// parseHotelID 解析路径中的酒店 ID
+func parseHotelID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的酒店ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,304 @@
+package handler
+
+import (
+	"gohotel/internal/service"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"io"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HousekeepingHandler 客房清洁控制器
+type HousekeepingHandler struct {
+	housekeepingService *service.HousekeepingService
+}
+
+// NewHousekeepingHandler 创建客房清洁控制器实例
+func NewHousekeepingHandler(housekeepingService *service.HousekeepingService) *HousekeepingHandler {
+	return &HousekeepingHandler{housekeepingService: housekeepingService}
+}
+
+// ListTasks 获取清洁任务列表（管理员）
+// @Summary 获取清洁任务列表（管理员）
+// @Description 管理员按状态、保洁员、楼层筛选清洁任务，支持分页
+// @Tags 客房清洁
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Param status query string false "任务状态：pending, in_progress, cleaned, inspected"
+// @Param assignee_id query string false "保洁员 ID"
+// @Param floor query int false "楼层"
+// @Success 200 {array} models.HousekeepingTask
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/admin/housekeeping/tasks [get]
+func (h *HousekeepingHandler) ListTasks(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	status := c.Query("status")
+	floor, _ := strconv.Atoi(c.DefaultQuery("floor", "0"))
+
+	var assigneeID int64
+	if assigneeStr := c.Query("assignee_id"); assigneeStr != "" {
+		id, err := strconv.ParseInt(assigneeStr, 10, 64)
+		if err != nil {
+			utils.ErrorResponse(c, errors.NewBadRequestError("无效的员工ID"))
+			return
+		}
+		assigneeID = id
+	}
+
+	tasks, total, err := h.housekeepingService.ListTasks(c.GetUint("hotel_id"), page, pageSize, status, assigneeID, floor)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithPage(c, tasks, page, pageSize, total)
+}
+
+// CreateTask 手动创建清洁任务（管理员）
+// @Summary 创建清洁任务（管理员）
+// @Description 管理员为房间手动创建清洁任务，房间会被标记为脏房。房间已有未完成的任务时返回 409
+// @Tags 客房清洁
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.CreateHousekeepingTaskRequest true "任务信息"
+// @Success 200 {object} models.HousekeepingTask
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse
+// @Router /api/admin/housekeeping/tasks [post]
+func (h *HousekeepingHandler) CreateTask(c *gin.Context) {
+	var req service.CreateHousekeepingTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	task, err := h.housekeepingService.CreateTask(c.GetUint("hotel_id"), &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "清洁任务创建成功", task)
+}
+
+// AssignTask 分配清洁任务（管理员）
+// @Summary 分配清洁任务（管理员）
+// @Description 管理员将清洁任务分配给保洁员
+// @Tags 客房清洁
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "任务 ID"
+// @Param request body service.AssignHousekeepingTaskRequest true "保洁员信息"
+// @Success 200 {object} models.HousekeepingTask
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/housekeeping/tasks/{id}/assign [post]
+func (h *HousekeepingHandler) AssignTask(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的任务ID"))
+		return
+	}
+
+	var req service.AssignHousekeepingTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	task, err := h.housekeepingService.AssignTask(c.GetUint("hotel_id"), uint(id), &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "任务分配成功", task)
+}
+
+// InspectTask 查房（管理员）
+// @Summary 查房（管理员）
+// @Description 对已清扫的房间进行查房，通过后房间可重新售卖，不通过则退回待清扫
+// @Tags 客房清洁
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "任务 ID"
+// @Param request body service.InspectHousekeepingTaskRequest true "查房结果"
+// @Success 200 {object} models.HousekeepingTask
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/housekeeping/tasks/{id}/inspect [post]
+func (h *HousekeepingHandler) InspectTask(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的任务ID"))
+		return
+	}
+
+	var req service.InspectHousekeepingTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	task, err := h.housekeepingService.InspectTask(c.GetUint("hotel_id"), uint(id), userID.(int64), &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "查房完成", task)
+}
+
+// UpdateRoomHousekeepingStatus 手动设置房间清洁状态（管理员）
+// @Summary 设置房间清洁状态（管理员）
+// @Description 管理员手动设置房间清洁状态：dirty, cleaning, clean, inspected
+// @Tags 客房清洁
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "房间 ID"
+// @Param request body object true "清洁状态" example({"status":"inspected"})
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/rooms/{id}/housekeeping [post]
+func (h *HousekeepingHandler) UpdateRoomHousekeepingStatus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的房间ID"))
+		return
+	}
+
+	var req struct {
+		Status string `json:"status" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := h.housekeepingService.UpdateRoomHousekeepingStatus(c.GetUint("hotel_id"), uint(id), req.Status); err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "清洁状态更新成功", nil)
+}
+
+// GetMyTasks 获取我的清洁任务（移动端）
+// @Summary 获取我的清洁任务
+// @Description 保洁员获取分配给自己的待清扫和清扫中任务
+// @Tags 客房清洁
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} models.HousekeepingTask
+// @Failure 401 {object} errors.ErrorResponse
+// @Router /api/housekeeping/tasks/my [get]
+func (h *HousekeepingHandler) GetMyTasks(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	tasks, err := h.housekeepingService.GetMyTasks(userID.(int64))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, tasks)
+}
+
+// StartTask 开始清扫（移动端）
+// @Summary 开始清扫
+// @Description 保洁员开始清扫分配给自己的任务，房间变为清扫中
+// @Tags 客房清洁
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "任务 ID"
+// @Success 200 {object} models.HousekeepingTask
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/housekeeping/tasks/{id}/start [post]
+func (h *HousekeepingHandler) StartTask(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的任务ID"))
+		return
+	}
+
+	task, err := h.housekeepingService.StartTask(uint(id), userID.(int64))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "已开始清扫", task)
+}
+
+// CompleteTask 完成清扫（移动端）
+// @Summary 完成清扫
+// @Description 保洁员完成清扫，房间变为已清洁，等待查房
+// @Tags 客房清洁
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "任务 ID"
+// @Param request body object false "备注" example({"note":"已更换床品"})
+// @Success 200 {object} models.HousekeepingTask
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/housekeeping/tasks/{id}/complete [post]
+func (h *HousekeepingHandler) CompleteTask(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的任务ID"))
+		return
+	}
+
+	var req struct {
+		Note string `json:"note"`
+	}
+	// 备注可选，允许不带请求体
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	task, err := h.housekeepingService.CompleteTask(uint(id), userID.(int64), req.Note)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "清扫完成，等待查房", task)
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"gohotel/internal/service"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoginThrottleHandler 登录锁定管理控制器
+type LoginThrottleHandler struct {
+	loginThrottle *service.LoginThrottleService
+}
+
+// NewLoginThrottleHandler 创建登录锁定管理控制器实例
+func NewLoginThrottleHandler(loginThrottle *service.LoginThrottleService) *LoginThrottleHandler {
+	return &LoginThrottleHandler{loginThrottle: loginThrottle}
+}
+
+// ListLockedUsers 查询被锁定的账号（管理员）
+// @Summary 查询被锁定的账号（管理员）
+// @Description 列出因连续登录失败而被临时锁定、且尚未到期的账号
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} service.LockedAccount
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/admin/users/locked [get]
+func (h *LoginThrottleHandler) ListLockedUsers(c *gin.Context) {
+	accounts, err := h.loginThrottle.ListLocked()
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, accounts)
+}
+
+// UnlockUser 解除账号锁定（管理员）
+// @Summary 解除账号锁定（管理员）
+// @Description 立即解除账号的登录锁定，并清零失败次数和连续锁定次数
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "用户 ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/users/{id}/unlock [post]
+func (h *LoginThrottleHandler) UnlockUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的用户ID"))
+		return
+	}
+
+	if err := h.loginThrottle.Unlock(id); err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "账号已解锁", nil)
+}
@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"gohotel/internal/service"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MFAHandler 两步验证控制器
+type MFAHandler struct {
+	mfaService *service.MFAService
+}
+
+// NewMFAHandler 创建两步验证控制器实例
+func NewMFAHandler(mfaService *service.MFAService) *MFAHandler {
+	return &MFAHandler{mfaService: mfaService}
+}
+
+// VerifyLogin 登录第二步验证
+// @Summary 登录第二步验证
+// @Description 登录返回 mfa 时，使用其中的 mfa_token 和身份验证器的动态码（或恢复码）完成登录，返回内容与密码登录相同。登录时完成绑定的（setup_required 为 true）只能使用动态码，并额外返回恢复码。失败次数过多或令牌过期后需要重新登录
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body service.MFAVerifyRequest true "第二步验证"
+// @Success 200 {object} service.MFAVerifyResponse
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/auth/mfa/verify [post]
+func (h *MFAHandler) VerifyLogin(c *gin.Context) {
+	var req service.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	resp, err := h.mfaService.VerifyLogin(&req, clientInfo(c))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "登录成功", resp)
+}
+
+// SetupForLogin 登录时绑定身份验证器
+// @Summary 登录时绑定身份验证器
+// @Description 角色要求两步验证但尚未绑定的用户登录时（mfa.setup_required 为 true），凭 mfa_token 获取绑定二维码，扫码后调用第二步验证接口完成绑定和登录
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body service.MFATokenRequest true "第二步验证令牌"
+// @Success 200 {object} service.MFASetupResponse
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Router /api/auth/mfa/setup [post]
+func (h *MFAHandler) SetupForLogin(c *gin.Context) {
+	var req service.MFATokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	resp, err := h.mfaService.SetupForLogin(&req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, resp)
+}
+
+// GetStatus 查询两步验证状态
+// @Summary 查询两步验证状态
+// @Description 返回当前用户是否已启用两步验证、角色是否要求启用以及剩余恢复码数量
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} service.MFAStatusResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Router /api/users/mfa [get]
+func (h *MFAHandler) GetStatus(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	status, err := h.mfaService.GetStatus(userID.(int64))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, status)
+}
+
+// Setup 获取绑定二维码
+// @Summary 获取绑定二维码
+// @Description 生成新的 TOTP 密钥，返回 otpauth 链接和二维码图片，使用身份验证器扫码后调用启用接口。重复调用会替换之前未完成绑定的密钥
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} service.MFASetupResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse
+// @Router /api/users/mfa/setup [post]
+func (h *MFAHandler) Setup(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	resp, err := h.mfaService.Setup(userID.(int64))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, resp)
+}
+
+// Enable 启用两步验证
+// @Summary 启用两步验证
+// @Description 提交身份验证器显示的动态码完成绑定，返回恢复码（只显示一次，请妥善保存）
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.MFACodeRequest true "动态码"
+// @Success 200 {object} service.MFARecoveryCodesResponse
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse
+// @Router /api/users/mfa/enable [post]
+func (h *MFAHandler) Enable(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req service.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	resp, err := h.mfaService.Enable(userID.(int64), &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "两步验证已启用", resp)
+}
+
+// Disable 关闭两步验证
+// @Summary 关闭两步验证
+// @Description 使用当前密码和动态码（或恢复码）关闭两步验证，角色要求两步验证时不能关闭
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.MFADisableRequest true "密码和动态码"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/users/mfa/disable [post]
+func (h *MFAHandler) Disable(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req service.MFADisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := h.mfaService.Disable(userID.(int64), &req); err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "两步验证已关闭", nil)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+// @Summary 重新生成恢复码
+// @Description 使用动态码确认后重新生成恢复码，旧恢复码全部作废
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.MFACodeRequest true "动态码"
+// @Success 200 {object} service.MFARecoveryCodesResponse
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Router /api/users/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req service.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	resp, err := h.mfaService.RegenerateRecoveryCodes(userID.(int64), &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, resp)
+}
+
+// ResetUserMFA 重置用户的两步验证（管理员）
+// @Summary 重置用户的两步验证（管理员）
+// @Description 删除用户的身份验证器绑定和恢复码，用于用户丢失身份验证器且没有恢复码的情况；角色要求两步验证的用户下次登录时需要重新绑定
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "用户 ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/users/{id}/mfa/reset [post]
+func (h *MFAHandler) ResetUserMFA(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的用户ID"))
+		return
+	}
+
+	if err := h.mfaService.Reset(id); err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "两步验证已重置", nil)
+}
@@ -81,7 +81,7 @@ func (h *NoticeHandler) CreateNotice(c *gin.Context) {
 	}
 
 	// 调用服务层创建公告
-	notice, err := h.noticeService.CreateNotice(req)
+	notice, err := h.noticeService.CreateNotice(c.GetUint("hotel_id"), req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建失败: " + err.Error()})
 		return
@@ -111,7 +111,7 @@ func (h *NoticeHandler) GetNoticeByID(c *gin.Context) {
 		return
 	}
 
-	notice, err := h.noticeService.GetNoticeByID(int64(id))
+	notice, err := h.noticeService.GetNoticeByID(c.GetUint("hotel_id"), int64(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "公告不存在"})
 		return
@@ -136,7 +136,7 @@ func (h *NoticeHandler) GetAllNotices(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
 
-	notices, total, err := h.noticeService.GetAllNotices(page, pageSize)
+	notices, total, err := h.noticeService.GetAllNotices(c.GetUint("hotel_id"), page, pageSize)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取失败: " + err.Error()})
 		return
@@ -156,11 +156,12 @@ func (h *NoticeHandler) GetAllNotices(c *gin.Context) {
 // @Tags 公告管理
 // @Accept json
 // @Produce json
+// @Param hotel_id query int false "酒店 ID，不传时使用默认酒店"
 // @Success 200 {array} models.Notice
 // @Failure 500 {object} map[string]string
 // @Router /api/notices/active [get]
 func (h *NoticeHandler) GetActiveNotices(c *gin.Context) {
-	notices, err := h.noticeService.GetActiveNotices()
+	notices, err := h.noticeService.GetActiveNotices(c.GetUint("hotel_id"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取失败: " + err.Error()})
 		return
@@ -197,7 +198,7 @@ func (h *NoticeHandler) UpdateNotice(c *gin.Context) {
 	}
 
 	// 检查公告是否存在
-	_, err = h.noticeService.GetNoticeByID(id)
+	_, err = h.noticeService.GetNoticeByID(c.GetUint("hotel_id"), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "公告不存在"})
 		return
@@ -243,7 +244,7 @@ func (h *NoticeHandler) UpdateNotice(c *gin.Context) {
 	}
 
 	// 调用服务层更新公告
-	notice, err := h.noticeService.UpdateNotice(id, req)
+	notice, err := h.noticeService.UpdateNotice(c.GetUint("hotel_id"), id, req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新失败: " + err.Error()})
 		return
@@ -272,7 +273,7 @@ func (h *NoticeHandler) DeleteNotice(c *gin.Context) {
 		return
 	}
 
-	if err := h.noticeService.DeleteNotice(int64(id)); err != nil {
+	if err := h.noticeService.DeleteNotice(c.GetUint("hotel_id"), int64(id)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除失败: " + err.Error()})
 		return
 	}
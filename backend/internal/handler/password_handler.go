@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"gohotel/internal/service"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PasswordHandler 找回密码控制器
+type PasswordHandler struct {
+	passwordService *service.PasswordResetService
+}
+
+// NewPasswordHandler 创建找回密码控制器实例
+func NewPasswordHandler(passwordService *service.PasswordResetService) *PasswordHandler {
+	return &PasswordHandler{passwordService: passwordService}
+}
+
+// ForgotPassword 申请找回密码
+// @Summary 申请找回密码
+// @Description 向账号绑定的邮箱（填写手机号时为短信）发送设置新密码的链接，链接一次有效且有时限。无论账号是否存在都返回成功
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body service.ForgotPasswordRequest true "账号"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} errors.ErrorResponse
+// @Router /api/auth/password/forgot [post]
+func (h *PasswordHandler) ForgotPassword(c *gin.Context) {
+	var req service.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := h.passwordService.RequestReset(&req); err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "如果账号存在，设置密码的链接已发送", nil)
+}
+
+// ResetPassword 设置新密码
+// @Summary 设置新密码
+// @Description 使用找回密码或邀请链接中的令牌设置新密码，成功后链接作废，账号的全部登录会话失效
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body service.ResetPasswordRequest true "令牌和新密码"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} errors.ErrorResponse
+// @Router /api/auth/password/reset [post]
+func (h *PasswordHandler) ResetPassword(c *gin.Context) {
+	var req service.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := h.passwordService.ResetPassword(&req); err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "密码已重置，请使用新密码登录", nil)
+}
@@ -0,0 +1,429 @@
+package handler
+
+import (
+	"gohotel/internal/service"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PricingHandler 动态定价控制器
+type PricingHandler struct {
+	pricingService *service.PricingService
+}
+
+// NewPricingHandler 创建动态定价控制器实例
+func NewPricingHandler(pricingService *service.PricingService) *PricingHandler {
+	return &PricingHandler{pricingService: pricingService}
+}
+
+// ListRules 获取定价规则列表（管理员）
+// @Summary 获取定价规则列表（管理员）
+// @Description 获取当前酒店的动态定价规则
+// @Tags 动态定价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} models.PricingRule
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/admin/pricing/rules [get]
+func (h *PricingHandler) ListRules(c *gin.Context) {
+	rules, err := h.pricingService.ListRules(c.GetUint("hotel_id"))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, rules)
+}
+
+// CreateRule 创建定价规则（管理员）
+// @Summary 创建定价规则（管理员）
+// @Description 房型某日入住率超过阈值时按比例或金额上浮房价，可设置价格上限；规则在下次定时重算时生效
+// @Tags 动态定价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.PricingRuleRequest true "定价规则"
+// @Success 200 {object} models.PricingRule
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/admin/pricing/rules [post]
+func (h *PricingHandler) CreateRule(c *gin.Context) {
+	var req service.PricingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	rule, err := h.pricingService.CreateRule(c.GetUint("hotel_id"), &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "定价规则创建成功", rule)
+}
+
+// UpdateRule 更新定价规则（管理员）
+// @Summary 更新定价规则（管理员）
+// @Description 更新定价规则，在下次定时重算时生效
+// @Tags 动态定价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "规则 ID"
+// @Param request body service.PricingRuleRequest true "定价规则"
+// @Success 200 {object} models.PricingRule
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/pricing/rules/{id} [post]
+func (h *PricingHandler) UpdateRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的规则ID"))
+		return
+	}
+
+	var req service.PricingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	rule, err := h.pricingService.UpdateRule(c.GetUint("hotel_id"), uint(id), &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "定价规则更新成功", rule)
+}
+
+// DeleteRule 删除定价规则（管理员）
+// @Summary 删除定价规则（管理员）
+// @Description 删除定价规则，下次定时重算时相关日期恢复原价
+// @Tags 动态定价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "规则 ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/pricing/rules/{id}/delete [post]
+func (h *PricingHandler) DeleteRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的规则ID"))
+		return
+	}
+
+	if err := h.pricingService.DeleteRule(c.GetUint("hotel_id"), uint(id)); err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "定价规则删除成功", nil)
+}
+
+// GetCalendar 获取房价日历（管理员）
+// @Summary 获取房价日历（管理员）
+// @Description 获取房型每日的入住率和自动调整后的参考价，没有记录的日期按原价售卖；结束日期不包含在内，最多 92 天
+// @Tags 动态定价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param start_date query string true "开始日期（YYYY-MM-DD）"
+// @Param end_date query string true "结束日期（YYYY-MM-DD，不包含）"
+// @Param room_type query string false "房型"
+// @Success 200 {array} models.RoomRate
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/admin/pricing/calendar [get]
+func (h *PricingHandler) GetCalendar(c *gin.Context) {
+	rates, err := h.pricingService.GetCalendar(c.GetUint("hotel_id"), c.Query("start_date"), c.Query("end_date"), c.Query("room_type"))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, rates)
+}
+
+// Preview 预览定价（管理员）
+// @Summary 预览定价（管理员）
+// @Description 按当前入住率和启用的规则预览房型每日的价格，不写入房价日历；结束日期不包含在内，最多 92 天
+// @Tags 动态定价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param start_date query string true "开始日期（YYYY-MM-DD）"
+// @Param end_date query string true "结束日期（YYYY-MM-DD，不包含）"
+// @Param room_type query string false "房型"
+// @Success 200 {array} service.RatePreview
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/admin/pricing/preview [get]
+func (h *PricingHandler) Preview(c *gin.Context) {
+	previews, err := h.pricingService.Preview(c.GetUint("hotel_id"), c.Query("start_date"), c.Query("end_date"), c.Query("room_type"))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, previews)
+}
+
+// Evaluate 立即重算定价（管理员）
+// @Summary 立即重算定价（管理员）
+// @Description 不等待定时任务，立即按规则重算从今天起的房价日历并记录调价
+// @Tags 动态定价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} service.PricingEvaluation
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/admin/pricing/evaluate [post]
+func (h *PricingHandler) Evaluate(c *gin.Context) {
+	result, err := h.pricingService.EvaluateHotel(c.GetUint("hotel_id"))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "定价重算完成", result)
+}
+
+// ListChangeLogs 获取调价记录（管理员）
+// @Summary 获取调价记录（管理员）
+// @Description 获取每一次自动调价的记录，最新的在前
+// @Tags 动态定价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param room_type query string false "房型"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {array} models.PriceChangeLog
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/admin/pricing/logs [get]
+func (h *PricingHandler) ListChangeLogs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	logs, total, err := h.pricingService.ListChangeLogs(c.GetUint("hotel_id"), c.Query("room_type"), page, pageSize)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithPage(c, logs, page, pageSize, total)
+}
+
+// ListStayDiscounts 获取连住折扣档位列表（管理员）
+// @Summary 获取连住折扣档位列表（管理员）
+// @Description 获取当前酒店的连住折扣档位
+// @Tags 动态定价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} models.StayDiscount
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/admin/pricing/stay-discounts [get]
+func (h *PricingHandler) ListStayDiscounts(c *gin.Context) {
+	discounts, err := h.pricingService.ListStayDiscounts(c.GetUint("hotel_id"))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, discounts)
+}
+
+// CreateStayDiscount 创建连住折扣档位（管理员）
+// @Summary 创建连住折扣档位（管理员）
+// @Description 连住晚数达到档位时整单打折（如 7、14、30 晚），可按房型设置
+// @Tags 动态定价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.StayDiscountRequest true "连住折扣档位"
+// @Success 200 {object} models.StayDiscount
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/admin/pricing/stay-discounts [post]
+func (h *PricingHandler) CreateStayDiscount(c *gin.Context) {
+	var req service.StayDiscountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	discount, err := h.pricingService.CreateStayDiscount(c.GetUint("hotel_id"), &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "连住折扣档位创建成功", discount)
+}
+
+// UpdateStayDiscount 更新连住折扣档位（管理员）
+// @Summary 更新连住折扣档位（管理员）
+// @Description 更新连住折扣档位，已创建的预订不受影响
+// @Tags 动态定价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "档位 ID"
+// @Param request body service.StayDiscountRequest true "连住折扣档位"
+// @Success 200 {object} models.StayDiscount
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/pricing/stay-discounts/{id} [post]
+func (h *PricingHandler) UpdateStayDiscount(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的档位ID"))
+		return
+	}
+
+	var req service.StayDiscountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	discount, err := h.pricingService.UpdateStayDiscount(c.GetUint("hotel_id"), uint(id), &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "连住折扣档位更新成功", discount)
+}
+
+// DeleteStayDiscount 删除连住折扣档位（管理员）
+// @Summary 删除连住折扣档位（管理员）
+// @Description 删除连住折扣档位，已创建的预订不受影响
+// @Tags 动态定价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "档位 ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/pricing/stay-discounts/{id}/delete [post]
+func (h *PricingHandler) DeleteStayDiscount(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的档位ID"))
+		return
+	}
+
+	if err := h.pricingService.DeleteStayDiscount(c.GetUint("hotel_id"), uint(id)); err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "连住折扣档位删除成功", nil)
+}
+
+// ListLongStayPolicies 获取长住政策列表（管理员）
+// @Summary 获取长住政策列表（管理员）
+// @Description 获取当前酒店各房型按月付款的押金设置
+// @Tags 动态定价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} models.LongStayPolicy
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/admin/pricing/long-stay-policies [get]
+func (h *PricingHandler) ListLongStayPolicies(c *gin.Context) {
+	policies, err := h.pricingService.ListLongStayPolicies(c.GetUint("hotel_id"))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, policies)
+}
+
+// SaveLongStayPolicy 设置长住政策（管理员）
+// @Summary 设置长住政策（管理员）
+// @Description 设置房型按月付款时收取的押金，房型为空时适用所有房型；房型已有政策时更新
+// @Tags 动态定价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.LongStayPolicyRequest true "长住政策"
+// @Success 200 {object} models.LongStayPolicy
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/admin/pricing/long-stay-policies [post]
+func (h *PricingHandler) SaveLongStayPolicy(c *gin.Context) {
+	var req service.LongStayPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	policy, err := h.pricingService.SaveLongStayPolicy(c.GetUint("hotel_id"), &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "长住政策设置成功", policy)
+}
+
+// DeleteLongStayPolicy 删除长住政策（管理员）
+// @Summary 删除长住政策（管理员）
+// @Description 删除长住政策，已创建的预订不受影响
+// @Tags 动态定价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "政策 ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/pricing/long-stay-policies/{id}/delete [post]
+func (h *PricingHandler) DeleteLongStayPolicy(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的政策ID"))
+		return
+	}
+
+	if err := h.pricingService.DeleteLongStayPolicy(c.GetUint("hotel_id"), uint(id)); err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "长住政策删除成功", nil)
+}
@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"gohotel/internal/service"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReviewHandler 住客评价控制器
+type ReviewHandler struct {
+	reviewService *service.ReviewService
+}
+
+// NewReviewHandler 创建评价控制器实例
+func NewReviewHandler(reviewService *service.ReviewService) *ReviewHandler {
+	return &ReviewHandler{reviewService: reviewService}
+}
+
+// CreateReview 评价预订
+// @Summary 评价预订
+// @Description 对已退房的预订进行多维度评分和文字评价，每个预订只能评价一次
+// @Tags 评价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.CreateReviewRequest true "评价信息"
+// @Success 200 {object} models.Review
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse
+// @Router /api/reviews [post]
+func (h *ReviewHandler) CreateReview(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req service.CreateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	review, err := h.reviewService.CreateReview(userID.(int64), &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "评价成功", review)
+}
+
+// ListReviews 获取公开评价列表
+// @Summary 获取公开评价列表
+// @Description 按房型或房间查询公开展示的评价，支持分页
+// @Tags 评价
+// @Accept json
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Param room_type query string false "房型"
+// @Param room_id query int false "房间 ID"
+// @Param hotel_id query int false "酒店 ID，不传时使用默认酒店"
+// @Success 200 {array} models.Review
+// @Failure 400 {object} errors.ErrorResponse
+// @Router /api/reviews [get]
+func (h *ReviewHandler) ListReviews(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	roomID, _ := strconv.ParseInt(c.DefaultQuery("room_id", "0"), 10, 64)
+
+	reviews, total, err := h.reviewService.ListPublicReviews(c.GetUint("hotel_id"), page, pageSize, c.Query("room_type"), roomID)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithPage(c, reviews, page, pageSize, total)
+}
+
+// GetRatingSummary 获取房型评分汇总
+// @Summary 获取房型评分汇总
+// @Description 按房型汇总公开评价的各维度平均分和评价数
+// @Tags 评价
+// @Accept json
+// @Produce json
+// @Param room_type query string false "房型，不传返回所有房型"
+// @Param hotel_id query int false "酒店 ID，不传时使用默认酒店"
+// @Success 200 {array} models.RoomTypeRating
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /api/reviews/summary [get]
+func (h *ReviewHandler) GetRatingSummary(c *gin.Context) {
+	ratings, err := h.reviewService.GetRatingSummary(c.GetUint("hotel_id"), c.Query("room_type"))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, ratings)
+}
+
+// GetMyReviews 获取我的评价
+// @Summary 获取我的评价
+// @Description 获取当前用户的评价列表（包含已隐藏的评价）
+// @Tags 评价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {array} models.Review
+// @Failure 401 {object} errors.ErrorResponse
+// @Router /api/reviews/my [get]
+func (h *ReviewHandler) GetMyReviews(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	reviews, total, err := h.reviewService.GetMyReviews(userID.(int64), page, pageSize)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithPage(c, reviews, page, pageSize, total)
+}
+
+// ListAllReviews 获取评价列表（管理员）
+// @Summary 获取评价列表（管理员）
+// @Description 按状态、房型、房间筛选评价，包含已隐藏的评价
+// @Tags 评价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Param status query string false "状态：visible, hidden"
+// @Param room_type query string false "房型"
+// @Param room_id query int false "房间 ID"
+// @Success 200 {array} models.Review
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/admin/reviews [get]
+func (h *ReviewHandler) ListAllReviews(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	roomID, _ := strconv.ParseInt(c.DefaultQuery("room_id", "0"), 10, 64)
+
+	reviews, total, err := h.reviewService.ListAllReviews(c.GetUint("hotel_id"), page, pageSize, c.Query("status"), c.Query("room_type"), roomID)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithPage(c, reviews, page, pageSize, total)
+}
+
+// HideReview 隐藏评价（管理员）
+// @Summary 隐藏评价（管理员）
+// @Description 隐藏不当评价，隐藏后不在公开列表展示，也不计入评分汇总
+// @Tags 评价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "评价 ID"
+// @Param request body service.HideReviewRequest false "隐藏原因"
+// @Success 200 {object} models.Review
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/reviews/{id}/hide [post]
+func (h *ReviewHandler) HideReview(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的评价ID"))
+		return
+	}
+
+	var req service.HideReviewRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+			return
+		}
+	}
+
+	review, err := h.reviewService.HideReview(c.GetUint("hotel_id"), uint(id), &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "评价已隐藏", review)
+}
+
+// ShowReview 恢复展示评价（管理员）
+// @Summary 恢复展示评价（管理员）
+// @Description 将已隐藏的评价恢复公开展示
+// @Tags 评价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "评价 ID"
+// @Success 200 {object} models.Review
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/reviews/{id}/show [post]
+func (h *ReviewHandler) ShowReview(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的评价ID"))
+		return
+	}
+
+	review, err := h.reviewService.ShowReview(c.GetUint("hotel_id"), uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "评价已恢复展示", review)
+}
+
+// ReplyReview 回复评价（管理员）
+// @Summary 回复评价（管理员）
+// @Description 以酒店身份回复评价，再次回复会覆盖原回复
+// @Tags 评价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "评价 ID"
+// @Param request body service.ReplyReviewRequest true "回复内容"
+// @Success 200 {object} models.Review
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/reviews/{id}/reply [post]
+func (h *ReviewHandler) ReplyReview(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的评价ID"))
+		return
+	}
+
+	var req service.ReplyReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	review, err := h.reviewService.ReplyReview(c.GetUint("hotel_id"), uint(id), &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "回复成功", review)
+}
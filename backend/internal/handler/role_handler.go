@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"gohotel/internal/service"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleHandler 角色与权限控制器
+type RoleHandler struct {
+	roleService *service.RoleService
+}
+
+// NewRoleHandler 创建角色控制器实例
+func NewRoleHandler(roleService *service.RoleService) *RoleHandler {
+	return &RoleHandler{roleService: roleService}
+}
+
+// ListPermissions 获取系统支持的全部权限（管理员）
+// @Summary 获取权限列表（管理员）
+// @Description 获取可分配给角色的全部权限码及说明
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} models.PermissionInfo
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/admin/permissions [get]
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	utils.SuccessResponse(c, h.roleService.ListPermissions())
+}
+
+// ListRoles 获取全部角色（管理员）
+// @Summary 获取角色列表（管理员）
+// @Description 获取内置角色和自定义角色及其权限
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} models.Role
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/admin/roles [get]
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleService.ListRoles()
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, roles)
+}
+
+// CreateRole 创建自定义角色（管理员）
+// @Summary 创建角色（管理员）
+// @Description 创建自定义角色并设置权限，不能授予通配权限
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.CreateRoleRequest true "角色信息"
+// @Success 200 {object} models.Role
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse
+// @Router /api/admin/roles [post]
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req service.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	role, err := h.roleService.CreateRole(&req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "角色创建成功", role)
+}
+
+// UpdateRole 更新角色（管理员）
+// @Summary 更新角色（管理员）
+// @Description 更新角色名称、说明和权限（整体替换），超级管理员的权限不可修改
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "角色 ID"
+// @Param request body service.UpdateRoleRequest true "角色信息"
+// @Success 200 {object} models.Role
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/roles/{id} [post]
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	id, ok := parseRoleID(c)
+	if !ok {
+		return
+	}
+
+	var req service.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	role, err := h.roleService.UpdateRole(id, &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "角色更新成功", role)
+}
+
+// DeleteRole 删除自定义角色（管理员）
+// @Summary 删除角色（管理员）
+// @Description 删除自定义角色并取消其用户分配，内置角色不可删除
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "角色 ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/roles/{id}/delete [post]
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	id, ok := parseRoleID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.roleService.DeleteRole(id); err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "角色删除成功", nil)
+}
+
+// GetUserRoles 获取用户的角色（管理员）
+// @Summary 获取用户角色（管理员）
+// @Description 获取用户被分配的角色及权限
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "用户 ID"
+// @Success 200 {array} models.Role
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/users/{id}/roles [get]
+func (h *RoleHandler) GetUserRoles(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的用户ID"))
+		return
+	}
+
+	roles, err := h.roleService.GetUserRoles(userID)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, roles)
+}
+
+// SetUserRoles 设置用户的角色（管理员）
+// @Summary 设置用户角色（管理员）
+// @Description 整体替换用户的角色，系统至少保留一名超级管理员
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "用户 ID"
+// @Param request body service.SetUserRolesRequest true "角色 ID 列表"
+// @Success 200 {array} models.Role
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/users/{id}/roles [post]
+func (h *RoleHandler) SetUserRoles(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的用户ID"))
+		return
+	}
+
+	var req service.SetUserRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	roles, err := h.roleService.SetUserRoles(userID, &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "用户角色设置成功", roles)
+}
+
+// GetMyPermissions 获取当前用户的权限
+// @Summary 获取我的权限
+// @Description 获取当前用户通过角色获得的权限码，前端据此显示菜单，"*" 表示拥有全部权限
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} string
+// @Failure 401 {object} errors.ErrorResponse
+// @Router /api/users/permissions [get]
+func (h *RoleHandler) GetMyPermissions(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	permissions, err := h.roleService.GetUserPermissions(userID.(int64))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, permissions)
+}
+
+// parseRoleID 解析路径中的角色 ID
+func parseRoleID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的角色ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"gohotel/internal/service"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoomCalendarHandler 房态日历控制器
+type RoomCalendarHandler struct {
+	calendarService *service.RoomCalendarService
+}
+
+// NewRoomCalendarHandler 创建房态日历控制器实例
+func NewRoomCalendarHandler(calendarService *service.RoomCalendarService) *RoomCalendarHandler {
+	return &RoomCalendarHandler{calendarService: calendarService}
+}
+
+// GetRoomCalendar 获取前台房态图（管理员）
+// @Summary 获取前台房态图（管理员）
+// @Description 返回房间 × 日期的房态网格，每个格子包含预订ID、入住人姓名、预订状态或停用标记
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param start_date query string true "开始日期（包含），格式: 2024-01-01"
+// @Param end_date query string true "结束日期（不包含），格式: 2024-01-31"
+// @Param floor query int false "楼层"
+// @Param room_type query string false "房型"
+// @Success 200 {object} service.RoomCalendar
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/admin/rooms/calendar [get]
+func (h *RoomCalendarHandler) GetRoomCalendar(c *gin.Context) {
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+	if startDate == "" || endDate == "" {
+		utils.ErrorResponse(c, errors.NewBadRequestError("请提供开始日期和结束日期"))
+		return
+	}
+
+	floor := 0
+	if floorStr := c.Query("floor"); floorStr != "" {
+		f, err := strconv.Atoi(floorStr)
+		if err != nil {
+			utils.ErrorResponse(c, errors.NewBadRequestError("无效的楼层"))
+			return
+		}
+		floor = f
+	}
+	roomType := c.Query("room_type")
+
+	calendar, err := h.calendarService.GetRoomCalendar(c.GetUint("hotel_id"), startDate, endDate, floor, roomType)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, calendar)
+}
+
+// GetRoomAvailability 获取房间空闲日历
+// @Summary 获取房间空闲日历
+// @Description 返回指定房间在日期范围内每一晚是否可订，不包含预订详情
+// @Tags 房间
+// @Accept json
+// @Produce json
+// @Param id path int true "房间 ID"
+// @Param start_date query string true "开始日期（包含），格式: 2024-01-01"
+// @Param end_date query string true "结束日期（不包含），格式: 2024-01-31"
+// @Param hotel_id query int false "酒店 ID，不传时使用默认酒店"
+// @Success 200 {array} service.RoomDayAvailability
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/rooms/{id}/calendar [get]
+func (h *RoomCalendarHandler) GetRoomAvailability(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的房间ID"))
+		return
+	}
+
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+	if startDate == "" || endDate == "" {
+		utils.ErrorResponse(c, errors.NewBadRequestError("请提供开始日期和结束日期"))
+		return
+	}
+
+	days, err := h.calendarService.GetRoomAvailability(c.GetUint("hotel_id"), uint(id), startDate, endDate)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, days)
+}
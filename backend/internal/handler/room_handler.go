@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"fmt"
 	"gohotel/internal/service"
 	"gohotel/pkg/errors"
 	"gohotel/pkg/utils"
+	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -41,7 +44,7 @@ func (h *RoomHandler) CreateRoom(c *gin.Context) {
 		return
 	}
 
-	room, err := h.roomService.CreateRoom(&req)
+	room, err := h.roomService.CreateRoom(c.GetUint("hotel_id"), &req)
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
@@ -71,7 +74,7 @@ func (h *RoomHandler) BatchCreateRooms(c *gin.Context) {
 		return
 	}
 
-	result, err := h.roomService.BatchCreateRooms(&req)
+	result, err := h.roomService.BatchCreateRooms(c.GetUint("hotel_id"), &req)
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
@@ -80,6 +83,89 @@ func (h *RoomHandler) BatchCreateRooms(c *gin.Context) {
 	utils.SuccessWithMessage(c, "批量创建完成", result)
 }
 
+// ImportRooms 从 CSV/XLSX 文件批量导入房间（管理员）
+// @Summary 导入房间（管理员）
+// @Description 上传 CSV/XLSX 文件批量导入房间（含平面图坐标），单次最多 1000 行。所有行校验通过才会在一个事务中导入，否则返回 400 和行级错误；dry_run=true 时只校验不导入。坐标保存到所在楼层的布局草稿，发布后生效
+// @Tags 管理员
+// @Accept multipart/form-data
+// @Produce json
+// @Security Bearer
+// @Param file formData file true "房间表格（.csv 或 .xlsx，表头见导入模板）"
+// @Param dry_run query bool false "是否只校验不导入"
+// @Success 200 {object} service.RoomImportResult
+// @Failure 400 {object} service.RoomImportResult
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/rooms/import [post]
+func (h *RoomHandler) ImportRooms(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("请上传导入文件"))
+		return
+	}
+	if fileHeader.Size > service.RoomImportMaxFileSize {
+		utils.ErrorResponse(c, errors.NewBadRequestError("导入文件不能超过 5MB"))
+		return
+	}
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无法读取导入文件"))
+		return
+	}
+	defer file.Close()
+
+	result, err := h.roomService.ImportRooms(userID.(int64), c.GetUint("hotel_id"), fileHeader.Filename, file, dryRun)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	switch {
+	case len(result.Errors) > 0 && !dryRun:
+		c.JSON(http.StatusBadRequest, utils.Response{
+			Success: false,
+			Data:    result,
+			Error: &utils.ErrorInfo{
+				Code:    "BAD_REQUEST",
+				Message: fmt.Sprintf("存在 %d 处错误，未导入任何房间", len(result.Errors)),
+			},
+		})
+	case dryRun:
+		utils.SuccessWithMessage(c, fmt.Sprintf("校验完成，%d 行可导入，%d 处错误", result.ValidRows, len(result.Errors)), result)
+	default:
+		utils.SuccessWithMessage(c, fmt.Sprintf("成功导入 %d 个房间", result.ImportedCount), result)
+	}
+}
+
+// GetImportTemplate 下载房间导入模板（管理员）
+// @Summary 下载房间导入模板（管理员）
+// @Description 下载房间导入模板，包含表头和一行示例数据；表头也可以使用中文列名
+// @Tags 管理员
+// @Produce application/octet-stream
+// @Security Bearer
+// @Param format query string false "模板格式：xlsx（默认）或 csv"
+// @Success 200 {file} file
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Router /api/rooms/import/template [get]
+func (h *RoomHandler) GetImportTemplate(c *gin.Context) {
+	filename, data, err := service.RoomImportTemplate(c.Query("format"))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if strings.HasSuffix(filename, ".xlsx") {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, contentType, data)
+}
+
 // GetRoomByID 根据 ID 获取房间
 // @Summary 获取房间详情
 // @Description 根据房间ID获取房间详细信息
@@ -87,6 +173,7 @@ func (h *RoomHandler) BatchCreateRooms(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "房间 ID"
+// @Param hotel_id query int false "酒店 ID，不传时使用默认酒店"
 // @Success 200 {object} models.Room
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 404 {object} errors.ErrorResponse
@@ -98,7 +185,7 @@ func (h *RoomHandler) GetRoomByID(c *gin.Context) {
 		return
 	}
 
-	room, err := h.roomService.GetRoomByID(uint(id))
+	room, err := h.roomService.GetRoomByID(c.GetUint("hotel_id"), uint(id))
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
@@ -135,7 +222,7 @@ func (h *RoomHandler) UpdateRoom(c *gin.Context) {
 		return
 	}
 
-	room, err := h.roomService.UpdateRoom(uint(id), &req)
+	room, err := h.roomService.UpdateRoom(c.GetUint("hotel_id"), uint(id), &req)
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
@@ -165,7 +252,7 @@ func (h *RoomHandler) DeleteRoom(c *gin.Context) {
 		return
 	}
 
-	err = h.roomService.DeleteRoom(uint(id))
+	err = h.roomService.DeleteRoom(c.GetUint("hotel_id"), uint(id))
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
@@ -176,50 +263,97 @@ func (h *RoomHandler) DeleteRoom(c *gin.Context) {
 
 // ListRooms 获取房间列表
 // @Summary 获取房间列表
-// @Description 获取所有房间列表，支持分页
+// @Description 获取所有房间列表，支持分页和按设施筛选，返回设施分面统计
 // @Tags 房间
 // @Accept json
 // @Produce json
 // @Param page query int false "页码" default(1)
 // @Param page_size query int false "每页数量" default(10)
+// @Param amenity_ids query string false "设施 ID，多个用逗号分隔（需同时具备）"
+// @Param hotel_id query int false "酒店 ID，不传时使用默认酒店"
 // @Success 200 {array} models.Room
 // @Failure 400 {object} errors.ErrorResponse
 // @Router /api/rooms [get]
 func (h *RoomHandler) ListRooms(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	amenityIDs, err := parseAmenityIDs(c.Query("amenity_ids"))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
 
-	rooms, total, err := h.roomService.ListRooms(page, pageSize)
+	rooms, total, err := h.roomService.ListRooms(c.GetUint("hotel_id"), page, pageSize, amenityIDs)
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
 	}
 
-	utils.SuccessWithPage(c, rooms, page, pageSize, total)
+	facets, err := h.roomService.GetAmenityFacets(c.GetUint("hotel_id"), false, amenityIDs)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithPageAndFacets(c, rooms, page, pageSize, total, facets)
 }
 
 // ListAvailableRooms 获取可用房间列表
 // @Summary 获取可用房间列表
-// @Description 获取所有可用状态的房间列表，支持分页
+// @Description 获取所有可用状态的房间列表，支持分页和按设施筛选，返回设施分面统计
 // @Tags 房间
 // @Accept json
 // @Produce json
 // @Param page query int false "页码" default(1)
 // @Param page_size query int false "每页数量" default(10)
+// @Param amenity_ids query string false "设施 ID，多个用逗号分隔（需同时具备）"
+// @Param hotel_id query int false "酒店 ID，不传时使用默认酒店"
 // @Success 200 {array} models.Room
 // @Failure 400 {object} errors.ErrorResponse
 // @Router /api/rooms/available [get]
 func (h *RoomHandler) ListAvailableRooms(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	amenityIDs, err := parseAmenityIDs(c.Query("amenity_ids"))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
 
-	rooms, total, err := h.roomService.ListAvailableRooms(page, pageSize)
+	rooms, total, err := h.roomService.ListAvailableRooms(c.GetUint("hotel_id"), page, pageSize, amenityIDs)
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
 	}
 
-	utils.SuccessWithPage(c, rooms, page, pageSize, total)
+	facets, err := h.roomService.GetAmenityFacets(c.GetUint("hotel_id"), true, amenityIDs)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithPageAndFacets(c, rooms, page, pageSize, total, facets)
+}
+
+// parseAmenityIDs 解析逗号分隔的设施 ID 列表
+func parseAmenityIDs(raw string) ([]uint, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	parts := strings.Split(raw, ",")
+	ids := make([]uint, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, errors.NewBadRequestError("无效的设施ID: " + part)
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
 }
 
 // GetRoomByFloor 根据楼层获取房间
@@ -231,6 +365,7 @@ func (h *RoomHandler) ListAvailableRooms(c *gin.Context) {
 // @Param floor path int true "楼层号"
 // @Param page query int false "页码" default(1)
 // @Param page_size query int false "每页数量" default(10)
+// @Param hotel_id query int false "酒店 ID，不传时使用默认酒店"
 // @Success 200 {array} models.Room
 // @Failure 400 {object} errors.ErrorResponse
 // @Router /api/rooms/floor/{floor} [get]
@@ -242,7 +377,7 @@ func (h *RoomHandler) GetRoomByFloor(c *gin.Context) {
 	}
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-	rooms, total, err := h.roomService.ListRoomsByFloor(floor, page, pageSize)
+	rooms, total, err := h.roomService.ListRoomsByFloor(c.GetUint("hotel_id"), floor, page, pageSize)
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
@@ -259,6 +394,7 @@ func (h *RoomHandler) GetRoomByFloor(c *gin.Context) {
 // @Param type query string true "房型"
 // @Param page query int false "页码" default(1)
 // @Param page_size query int false "每页数量" default(10)
+// @Param hotel_id query int false "酒店 ID，不传时使用默认酒店"
 // @Success 200 {array} models.Room
 // @Failure 400 {object} errors.ErrorResponse
 // @Router /api/rooms/search/type [get]
@@ -272,7 +408,7 @@ func (h *RoomHandler) SearchRoomsByType(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
 
-	rooms, total, err := h.roomService.SearchRoomsByType(roomType, page, pageSize)
+	rooms, total, err := h.roomService.SearchRoomsByType(c.GetUint("hotel_id"), roomType, page, pageSize)
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
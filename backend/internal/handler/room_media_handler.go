@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"gohotel/internal/service"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoomMediaHandler 房间图库控制器
+type RoomMediaHandler struct {
+	roomMediaService *service.RoomMediaService
+}
+
+// NewRoomMediaHandler 创建房间图库控制器实例
+func NewRoomMediaHandler(roomMediaService *service.RoomMediaService) *RoomMediaHandler {
+	return &RoomMediaHandler{roomMediaService: roomMediaService}
+}
+
+// ListPhotos 获取房间图库
+// @Summary 获取房间图库
+// @Description 获取房间的全部图片，按排序返回
+// @Tags 房间图库
+// @Accept json
+// @Produce json
+// @Param id path int true "房间 ID"
+// @Param hotel_id query int false "酒店 ID，不传时使用默认酒店"
+// @Success 200 {array} models.RoomPhoto
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/rooms/{id}/photos [get]
+func (h *RoomMediaHandler) ListPhotos(c *gin.Context) {
+	roomID, ok := parseRoomID(c)
+	if !ok {
+		return
+	}
+
+	photos, err := h.roomMediaService.ListPhotos(c.GetUint("hotel_id"), roomID)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, photos)
+}
+
+// AddPhotos 添加房间图片（管理员）
+// @Summary 添加房间图片（管理员）
+// @Description 确认通过 /api/upload/image（type=room）上传的临时图片，并追加到房间图库
+// @Tags 房间图库
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "房间 ID"
+// @Param request body service.AddRoomPhotosRequest true "临时图片 URL 和说明"
+// @Success 200 {array} models.RoomPhoto
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/rooms/{id}/photos [post]
+func (h *RoomMediaHandler) AddPhotos(c *gin.Context) {
+	roomID, ok := parseRoomID(c)
+	if !ok {
+		return
+	}
+
+	var req service.AddRoomPhotosRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	photos, err := h.roomMediaService.AddPhotos(c.GetUint("hotel_id"), roomID, &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "图片添加成功", photos)
+}
+
+// ReorderPhotos 调整房间图片顺序（管理员）
+// @Summary 调整房间图片顺序（管理员）
+// @Description 按给定的图片 ID 顺序重排，需包含房间全部图片
+// @Tags 房间图库
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "房间 ID"
+// @Param request body service.ReorderRoomPhotosRequest true "图片 ID 列表"
+// @Success 200 {array} models.RoomPhoto
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/rooms/{id}/photos/reorder [post]
+func (h *RoomMediaHandler) ReorderPhotos(c *gin.Context) {
+	roomID, ok := parseRoomID(c)
+	if !ok {
+		return
+	}
+
+	var req service.ReorderRoomPhotosRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	photos, err := h.roomMediaService.ReorderPhotos(c.GetUint("hotel_id"), roomID, &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "图片排序已更新", photos)
+}
+
+// UpdatePhoto 更新图片说明（管理员）
+// @Summary 更新图片说明（管理员）
+// @Description 修改房间图片的说明文字
+// @Tags 房间图库
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "房间 ID"
+// @Param photo_id path int true "图片 ID"
+// @Param request body service.UpdateRoomPhotoRequest true "图片说明"
+// @Success 200 {object} models.RoomPhoto
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/rooms/{id}/photos/{photo_id} [post]
+func (h *RoomMediaHandler) UpdatePhoto(c *gin.Context) {
+	roomID, photoID, ok := parsePhotoID(c)
+	if !ok {
+		return
+	}
+
+	var req service.UpdateRoomPhotoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	photo, err := h.roomMediaService.UpdatePhoto(c.GetUint("hotel_id"), roomID, photoID, &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "图片更新成功", photo)
+}
+
+// SetCover 设置房间封面（管理员）
+// @Summary 设置房间封面（管理员）
+// @Description 将指定图片设为房间封面
+// @Tags 房间图库
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "房间 ID"
+// @Param photo_id path int true "图片 ID"
+// @Success 200 {array} models.RoomPhoto
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/rooms/{id}/photos/{photo_id}/cover [post]
+func (h *RoomMediaHandler) SetCover(c *gin.Context) {
+	roomID, photoID, ok := parsePhotoID(c)
+	if !ok {
+		return
+	}
+
+	photos, err := h.roomMediaService.SetCover(c.GetUint("hotel_id"), roomID, photoID)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "封面设置成功", photos)
+}
+
+// DeletePhoto 删除房间图片（管理员）
+// @Summary 删除房间图片（管理员）
+// @Description 删除图片记录并删除 COS 中的文件
+// @Tags 房间图库
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "房间 ID"
+// @Param photo_id path int true "图片 ID"
+// @Success 200 {array} models.RoomPhoto
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/rooms/{id}/photos/{photo_id}/delete [post]
+func (h *RoomMediaHandler) DeletePhoto(c *gin.Context) {
+	roomID, photoID, ok := parsePhotoID(c)
+	if !ok {
+		return
+	}
+
+	photos, err := h.roomMediaService.DeletePhoto(c.GetUint("hotel_id"), roomID, photoID)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "图片删除成功", photos)
+}
+
+// parseRoomID 解析路径中的房间 ID，失败时直接写入错误响应
+func parseRoomID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的房间ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// parsePhotoID 解析路径中的房间 ID 和图片 ID，失败时直接写入错误响应
+func parsePhotoID(c *gin.Context) (uint, uint, bool) {
+	roomID, ok := parseRoomID(c)
+	if !ok {
+		return 0, 0, false
+	}
+	photoID, err := strconv.ParseUint(c.Param("photo_id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的图片ID"))
+		return 0, 0, false
+	}
+	return roomID, uint(photoID), true
+}
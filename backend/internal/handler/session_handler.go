@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"gohotel/internal/service"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionHandler 登录会话控制器
+type SessionHandler struct {
+	tokenService *service.TokenService
+}
+
+// NewSessionHandler 创建登录会话控制器实例
+func NewSessionHandler(tokenService *service.TokenService) *SessionHandler {
+	return &SessionHandler{tokenService: tokenService}
+}
+
+// ListSessions 查询登录设备
+// @Summary 查询登录设备
+// @Description 返回当前用户所有有效的登录会话，包括设备、IP、User-Agent、登录时间和最近活跃时间，current 为 true 的是当前请求所用的会话
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} service.SessionInfo
+// @Failure 401 {object} errors.ErrorResponse
+// @Router /api/users/sessions [get]
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	sessionID, _ := c.Get("session_id")
+
+	sessions, err := h.tokenService.ListSessions(userID.(int64), sessionID.(int64))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, sessions)
+}
+
+// RevokeSession 撤销登录会话
+// @Summary 撤销登录会话
+// @Description 撤销当前用户的某个登录会话，该设备的访问令牌和刷新令牌立即失效；撤销当前会话等同于注销登录
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "会话 ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/users/sessions/{id}/revoke [post]
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的会话ID"))
+		return
+	}
+
+	if err := h.tokenService.RevokeSession(userID.(int64), id); err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "会话已撤销", nil)
+}
+
+// RevokeAllSessions 撤销全部登录会话
+// @Summary 撤销全部登录会话
+// @Description 撤销当前用户在所有设备上的登录；keep_current 为 true 时保留当前会话，即退出其他设备
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param keep_current query bool false "是否保留当前会话"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} errors.ErrorResponse
+// @Router /api/users/sessions/revoke-all [post]
+func (h *SessionHandler) RevokeAllSessions(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	sessionID, _ := c.Get("session_id")
+
+	keepCurrent, _ := strconv.ParseBool(c.Query("keep_current"))
+	if keepCurrent {
+		if err := h.tokenService.RevokeOtherSessions(userID.(int64), sessionID.(int64)); err != nil {
+			utils.ErrorResponse(c, err)
+			return
+		}
+		utils.SuccessWithMessage(c, "已退出其他设备", nil)
+		return
+	}
+
+	if err := h.tokenService.RevokeUserSessions(userID.(int64)); err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "已退出所有设备", nil)
+}
+
+// ForceLogout 强制用户下线（管理员）
+// @Summary 强制用户下线（管理员）
+// @Description 撤销用户在所有设备上的登录会话，用户需要重新登录
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "用户 ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/users/{id}/logout [post]
+func (h *SessionHandler) ForceLogout(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的用户ID"))
+		return
+	}
+
+	if err := h.tokenService.ForceLogout(id); err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "用户已强制下线", nil)
+}
+
+// clientInfo 读取请求的客户端信息，登录和刷新令牌时记录到会话
+func clientInfo(c *gin.Context) service.ClientInfo {
+	return service.ClientInfo{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
@@ -11,17 +11,18 @@ import (
 
 // UserHandler 用户控制器
 type UserHandler struct {
-	userService *service.UserService
+	userService  *service.UserService
+	tokenService *service.TokenService
 }
 
 // NewUserHandler 创建用户控制器实例
-func NewUserHandler(userService *service.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService *service.UserService, tokenService *service.TokenService) *UserHandler {
+	return &UserHandler{userService: userService, tokenService: tokenService}
 }
 
 // Register 用户注册
 // @Summary 用户注册
-// @Description 新用户注册接口
+// @Description 新用户注册接口，需要先获取图形验证码，并填写邮箱验证码（填写手机号时还需手机验证码，purpose=register）
 // @Tags 认证
 // @Accept json
 // @Produce json
@@ -54,7 +55,7 @@ func (h *UserHandler) Register(c *gin.Context) {
 
 // Login 用户登录
 // @Summary 用户登录
-// @Description 用户登录接口，返回用户信息和 JWT token
+// @Description 用户登录接口，返回用户信息、短期访问令牌和刷新令牌；同一账号失败次数过多后需要图形验证码，继续失败将临时锁定账号（423，锁定时长逐次翻倍），同一 IP 失败次数过多时暂停登录（429）。启用两步验证或角色要求两步验证时不返回令牌，只返回 mfa，需调用第二步验证接口完成登录
 // @Tags 认证
 // @Accept json
 // @Produce json
@@ -62,6 +63,9 @@ func (h *UserHandler) Register(c *gin.Context) {
 // @Success 200 {object} service.LoginResponse
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 423 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
 // @Router /api/auth/login [post]
 func (h *UserHandler) Login(c *gin.Context) {
 	var req service.LoginRequest
@@ -73,7 +77,7 @@ func (h *UserHandler) Login(c *gin.Context) {
 	}
 
 	// 2. 调用 Service 层
-	resp, err := h.userService.Login(&req)
+	resp, err := h.userService.Login(&req, clientInfo(c))
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
@@ -83,6 +87,84 @@ func (h *UserHandler) Login(c *gin.Context) {
 	utils.SuccessWithMessage(c, "登录成功", resp)
 }
 
+// LoginWithCode 验证码登录
+// @Summary 验证码登录
+// @Description 使用已绑定的手机号或邮箱和验证码（purpose=login）登录，无需密码，返回内容与密码登录相同
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body service.CodeLoginRequest true "登录信息"
+// @Success 200 {object} service.LoginResponse
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/auth/login/code [post]
+func (h *UserHandler) LoginWithCode(c *gin.Context) {
+	var req service.CodeLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	resp, err := h.userService.LoginWithCode(&req, clientInfo(c))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "登录成功", resp)
+}
+
+// Refresh 刷新令牌
+// @Summary 刷新令牌
+// @Description 使用刷新令牌换取新的访问令牌和刷新令牌，旧刷新令牌随即作废；重复使用已作废的刷新令牌会撤销整个登录会话
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body service.RefreshRequest true "刷新令牌"
+// @Success 200 {object} service.TokenPair
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/auth/refresh [post]
+func (h *UserHandler) Refresh(c *gin.Context) {
+	var req service.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	tokens, err := h.tokenService.Refresh(&req, clientInfo(c))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, tokens)
+}
+
+// Logout 注销登录
+// @Summary 注销登录
+// @Description 注销当前登录会话，该会话的访问令牌和刷新令牌立即失效
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} errors.ErrorResponse
+// @Router /api/auth/logout [post]
+func (h *UserHandler) Logout(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	sessionID, _ := c.Get("session_id")
+
+	if err := h.tokenService.Logout(userID.(int64), sessionID.(int64)); err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "已退出登录", nil)
+}
+
 // GetProfile 获取个人信息
 // @Summary 获取个人信息
 // @Description 获取当前登录用户的个人信息
@@ -118,7 +200,7 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security Bearer
-// @Param request body object true "更新信息" example({"phone":"13800138000","real_name":"张三","avatar":"https://example.com/avatar.jpg"})
+// @Param request body service.UpdateProfileRequest true "更新信息，修改手机号时需要填写新手机号的验证码（purpose=change_phone）"
 // @Success 200 {object} models.User
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 401 {object} errors.ErrorResponse
@@ -128,18 +210,13 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	// 获取当前用户 ID
 	userID, _ := c.Get("user_id")
 
-	var req struct {
-		Phone    string `json:"phone"`
-		RealName string `json:"real_name"`
-		Avatar   string `json:"avatar"`
-	}
-
+	var req service.UpdateProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ErrorResponse(c, err)
 		return
 	}
 
-	user, err := h.userService.UpdateProfile(userID.(int64), req.Phone, req.RealName, req.Avatar)
+	user, err := h.userService.UpdateProfile(userID.(int64), &req)
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
@@ -150,7 +227,7 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 
 // ChangePassword 修改密码
 // @Summary 修改密码
-// @Description 修改当前登录用户的密码
+// @Description 修改当前登录用户的密码，成功后所有登录会话失效，需要重新登录；首次登录的用户必须先调用此接口
 // @Tags 用户
 // @Accept json
 // @Produce json
@@ -179,7 +256,7 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	utils.SuccessWithMessage(c, "密码修改成功", nil)
+	utils.SuccessWithMessage(c, "密码修改成功，请重新登录", nil)
 }
 
 // GetUserByID 根据 ID 获取用户（管理员）
@@ -253,13 +330,13 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 
 // AddUser 添加用户
 // @Summary 添加用户
-// @Description 管理员添加新的用户账户，默认密码为yumi123456
+// @Description 管理员添加新的用户账户。默认生成随机初始密码并在响应中返回一次；invite 为 true 时改为向用户邮箱发送设置密码的邀请链接。用户首次登录后必须修改密码（错误码 PASSWORD_CHANGE_REQUIRED）
 // @Tags 管理员
 // @Accept json
 // @Produce json
 // @Security Bearer
 // @Param request body service.AddUserRequest true "管理员信息"
-// @Success 200 {object} models.User
+// @Success 200 {object} service.AddUserResponse
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 401 {object} errors.ErrorResponse
 // @Failure 403 {object} errors.ErrorResponse
@@ -274,21 +351,19 @@ func (h *UserHandler) AddUser(c *gin.Context) {
 	}
 
 	// 2. 调用 Service 层
-	user, err := h.userService.AddUser(&req)
+	resp, err := h.userService.AddUser(&req)
 	if err != nil {
 		utils.ErrorResponse(c, err)
 		return
 	}
 
 	// 3. 返回成功响应
-	utils.SuccessWithMessage(c, "用户添加成功", gin.H{
-		"user": user,
-	})
+	utils.SuccessWithMessage(c, "用户添加成功", resp)
 }
 
 // DeleteUsers 批量删除用户
 // @Summary 批量删除用户
-// @Description 管理员批量删除用户账户
+// @Description 管理员批量删除用户账户。删除为软删除，用户进入回收站，保留期内可以恢复，历史预订仍关联原用户；有未完成预订（待确认、已确认、已入住）的用户不能删除（409）
 // @Tags 管理员
 // @Accept json
 // @Produce json
@@ -298,6 +373,7 @@ func (h *UserHandler) AddUser(c *gin.Context) {
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 401 {object} errors.ErrorResponse
 // @Failure 403 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse
 // @Router /api/admin/users/batch [post]
 func (h *UserHandler) DeleteUsers(c *gin.Context) {
 	// 1. 绑定并验证请求参数
@@ -317,3 +393,40 @@ func (h *UserHandler) DeleteUsers(c *gin.Context) {
 	// 3. 返回成功响应
 	utils.SuccessWithMessage(c, "用户删除成功", nil)
 }
+
+// UpdateUserStatus 修改用户状态（管理员）
+// @Summary 修改用户状态（管理员）
+// @Description 封禁或解封用户，封禁后用户的所有登录会话立即失效
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "用户 ID"
+// @Param request body service.UpdateUserStatusRequest true "用户状态"
+// @Success 200 {object} models.User
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/users/{id}/status [post]
+func (h *UserHandler) UpdateUserStatus(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的用户ID"))
+		return
+	}
+
+	var req service.UpdateUserStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	user, err := h.userService.UpdateUserStatus(id, &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "用户状态修改成功", user)
+}
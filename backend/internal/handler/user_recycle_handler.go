@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"gohotel/internal/service"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserRecycleHandler 用户回收站控制器
+type UserRecycleHandler struct {
+	recycleService *service.UserRecycleService
+}
+
+// NewUserRecycleHandler 创建用户回收站控制器实例
+func NewUserRecycleHandler(recycleService *service.UserRecycleService) *UserRecycleHandler {
+	return &UserRecycleHandler{recycleService: recycleService}
+}
+
+// ListDeletedUsers 查询回收站中的用户（管理员）
+// @Summary 查询回收站中的用户（管理员）
+// @Description 分页列出已删除且尚未匿名化的用户，purge_at 为到期匿名化的时间，此前可以恢复
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {array} service.DeletedUser
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/admin/users/deleted [get]
+func (h *UserRecycleHandler) ListDeletedUsers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	users, total, err := h.recycleService.ListDeleted(page, pageSize)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithPage(c, users, page, pageSize, total)
+}
+
+// RestoreUser 从回收站恢复用户（管理员）
+// @Summary 从回收站恢复用户（管理员）
+// @Description 恢复已删除的用户，恢复后用户需要重新登录；超过保留期已匿名化的用户不能恢复
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "用户 ID"
+// @Success 200 {object} models.User
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/users/{id}/restore [post]
+func (h *UserRecycleHandler) RestoreUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的用户ID"))
+		return
+	}
+
+	user, err := h.recycleService.Restore(id)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "用户已恢复", user)
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"gohotel/internal/service"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VerificationHandler 短信/邮件验证码控制器
+type VerificationHandler struct {
+	verificationService *service.VerificationService
+}
+
+// NewVerificationHandler 创建验证码控制器实例
+func NewVerificationHandler(verificationService *service.VerificationService) *VerificationHandler {
+	return &VerificationHandler{verificationService: verificationService}
+}
+
+// SendCode 发送短信/邮件验证码
+// @Summary 发送验证码
+// @Description 向手机号或邮箱发送 6 位验证码，用于注册（register）、免密码登录（login）和修改手机号（change_phone）。同一目标同一用途有重发间隔，同一目标每天、同一 IP 每小时有发送上限，超出返回 429
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body service.SendCodeRequest true "发送目标和用途"
+// @Success 200 {object} service.SendCodeResponse
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse
+// @Failure 429 {object} errors.ErrorResponse
+// @Router /api/auth/verification-codes [post]
+func (h *VerificationHandler) SendCode(c *gin.Context) {
+	var req service.SendCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	resp, err := h.verificationService.SendCode(&req, c.ClientIP())
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "验证码已发送", resp)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"gohotel/internal/service"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WayfindingHandler 室内导航控制器
+type WayfindingHandler struct {
+	wayfindingService *service.WayfindingService
+}
+
+// NewWayfindingHandler 创建室内导航控制器实例
+func NewWayfindingHandler(wayfindingService *service.WayfindingService) *WayfindingHandler {
+	return &WayfindingHandler{wayfindingService: wayfindingService}
+}
+
+// FindRoute 获取到房间的步行路线
+// @Summary 获取到房间的步行路线
+// @Description 以走廊为可通行区域，计算从电梯或楼梯到房间的步行路线，返回可直接绘制在平面图上的折线；未指定起点时自动选择最近的电梯或楼梯
+// @Tags 楼层平面图
+// @Accept json
+// @Produce json
+// @Param room_number query string true "房间号"
+// @Param start_facility_id query int false "起点设施 ID"
+// @Param hotel_id query int false "酒店 ID，不传时使用默认酒店"
+// @Success 200 {object} service.WayfindingRoute
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/floors/route [get]
+func (h *WayfindingHandler) FindRoute(c *gin.Context) {
+	roomNumber := c.Query("room_number")
+	if roomNumber == "" {
+		utils.ErrorResponse(c, errors.NewBadRequestError("房间号不能为空"))
+		return
+	}
+
+	var startFacilityID uint64
+	if v := c.Query("start_facility_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			utils.ErrorResponse(c, errors.NewBadRequestError("无效的起点设施ID"))
+			return
+		}
+		startFacilityID = id
+	}
+
+	route, err := h.wayfindingService.FindRoute(c.GetUint("hotel_id"), roomNumber, uint(startFacilityID))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, route)
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"gohotel/internal/service"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WechatHandler 微信小程序登录控制器
+type WechatHandler struct {
+	wechatService *service.WechatAuthService
+}
+
+// NewWechatHandler 创建微信登录控制器实例
+func NewWechatHandler(wechatService *service.WechatAuthService) *WechatHandler {
+	return &WechatHandler{wechatService: wechatService}
+}
+
+// Login 微信登录
+// @Summary 微信小程序登录
+// @Description 使用 wx.login 获取的 js_code 登录。微信未绑定账号时自动创建用户（is_new_user 为 true），返回内容与密码登录相同
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body service.WechatCodeRequest true "登录凭证"
+// @Success 200 {object} service.WechatLoginResponse
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /api/auth/wechat/login [post]
+func (h *WechatHandler) Login(c *gin.Context) {
+	var req service.WechatCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	resp, err := h.wechatService.Login(&req, clientInfo(c))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "登录成功", resp)
+}
+
+// BindAccount 绑定微信
+// @Summary 绑定微信
+// @Description 将微信绑定到当前登录的账号，之后可直接使用微信登录该账号
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.WechatCodeRequest true "登录凭证"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse
+// @Router /api/users/wechat/bind [post]
+func (h *WechatHandler) BindAccount(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req service.WechatCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := h.wechatService.BindAccount(userID.(int64), &req); err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "绑定成功", nil)
+}
+
+// BindPhone 绑定微信手机号
+// @Summary 绑定微信手机号
+// @Description 使用 getPhoneNumber 返回的 encryptedData 和 iv 解密手机号并绑定到当前账号，需要先通过微信登录或绑定微信
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.WechatPhoneRequest true "加密数据"
+// @Success 200 {object} models.User
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse
+// @Router /api/users/wechat/phone [post]
+func (h *WechatHandler) BindPhone(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req service.WechatPhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	user, err := h.wechatService.BindPhone(userID.(int64), &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "手机号绑定成功", user)
+}
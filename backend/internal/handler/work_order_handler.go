@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"gohotel/internal/service"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WorkOrderHandler 维修工单控制器
+type WorkOrderHandler struct {
+	workOrderService *service.WorkOrderService
+}
+
+// NewWorkOrderHandler 创建维修工单控制器实例
+func NewWorkOrderHandler(workOrderService *service.WorkOrderService) *WorkOrderHandler {
+	return &WorkOrderHandler{workOrderService: workOrderService}
+}
+
+// CreateWorkOrder 创建维修工单（管理员）
+// @Summary 创建维修工单（管理员）
+// @Description 创建维修工单，可设置停用时段（停用期间房间不可预订），返回与停用时段重叠的已有预订
+// @Tags 维修工单
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.CreateWorkOrderRequest true "工单信息"
+// @Success 200 {object} service.WorkOrderResult
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/work-orders [post]
+func (h *WorkOrderHandler) CreateWorkOrder(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req service.CreateWorkOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	result, err := h.workOrderService.CreateWorkOrder(userID.(int64), c.GetUint("hotel_id"), &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "工单创建成功", result)
+}
+
+// UpdateWorkOrder 更新维修工单（管理员）
+// @Summary 更新维修工单（管理员）
+// @Description 更新工单信息、状态、维修人员、停用时段和照片
+// @Tags 维修工单
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "工单 ID"
+// @Param request body service.UpdateWorkOrderRequest true "工单信息"
+// @Success 200 {object} service.WorkOrderResult
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/work-orders/{id} [post]
+func (h *WorkOrderHandler) UpdateWorkOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的工单ID"))
+		return
+	}
+
+	var req service.UpdateWorkOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	result, err := h.workOrderService.UpdateWorkOrder(c.GetUint("hotel_id"), uint(id), &req)
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "工单更新成功", result)
+}
+
+// GetWorkOrder 获取维修工单详情（管理员）
+// @Summary 获取维修工单详情（管理员）
+// @Description 获取工单详情，并列出与停用时段重叠的预订
+// @Tags 维修工单
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "工单 ID"
+// @Success 200 {object} service.WorkOrderResult
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 404 {object} errors.ErrorResponse
+// @Router /api/admin/work-orders/{id} [get]
+func (h *WorkOrderHandler) GetWorkOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的工单ID"))
+		return
+	}
+
+	result, err := h.workOrderService.GetWorkOrder(c.GetUint("hotel_id"), uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, result)
+}
+
+// ListWorkOrders 获取维修工单列表（管理员）
+// @Summary 获取维修工单列表（管理员）
+// @Description 按状态、优先级、类别、房间筛选工单，支持分页
+// @Tags 维修工单
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Param status query string false "状态：open, in_progress, resolved, cancelled"
+// @Param priority query string false "优先级：low, medium, high, urgent"
+// @Param category query string false "类别"
+// @Param room_id query int false "房间 ID"
+// @Success 200 {array} models.WorkOrder
+// @Failure 401 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Router /api/admin/work-orders [get]
+func (h *WorkOrderHandler) ListWorkOrders(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	status := c.Query("status")
+	priority := c.Query("priority")
+	category := c.Query("category")
+	roomID, _ := strconv.ParseUint(c.DefaultQuery("room_id", "0"), 10, 32)
+
+	workOrders, total, err := h.workOrderService.ListWorkOrders(c.GetUint("hotel_id"), page, pageSize, status, priority, category, uint(roomID))
+	if err != nil {
+		utils.ErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithPage(c, workOrders, page, pageSize, total)
+}
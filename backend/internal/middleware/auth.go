@@ -2,6 +2,7 @@ package middleware
 
 import (
 	stderrors "errors"
+	"gohotel/internal/service"
 	"gohotel/pkg/errors"
 	"gohotel/pkg/utils"
 	"strings"
@@ -11,7 +12,20 @@ import (
 )
 
 // AuthMiddleware JWT 认证中间件
-func AuthMiddleware() gin.HandlerFunc {
+// 除校验令牌签名和有效期外，还会检查令牌所属的登录会话是否已被撤销；
+// 首次登录尚未修改密码的用户会被拒绝（PASSWORD_CHANGE_REQUIRED）
+func AuthMiddleware(tokenService *service.TokenService) gin.HandlerFunc {
+	return authenticate(tokenService, false)
+}
+
+// PasswordChangeAuthMiddleware 允许尚未修改密码的用户通过的认证中间件
+// 只用于查看个人资料、修改密码和注销等完成首次改密所需的接口
+func PasswordChangeAuthMiddleware(tokenService *service.TokenService) gin.HandlerFunc {
+	return authenticate(tokenService, true)
+}
+
+// authenticate 校验访问令牌，allowPasswordChange 为 true 时不拦截需要修改密码的用户
+func authenticate(tokenService *service.TokenService, allowPasswordChange bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 1. 从请求头获取 Authorization
 		authHeader := c.GetHeader("Authorization")
@@ -47,36 +61,50 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// 4. 将用户信息存入上下文
+		// 4. 检查会话是否已撤销（注销、修改密码、封禁用户）
+		if err := tokenService.ValidateSession(claims.UserID, claims.SessionID); err != nil {
+			utils.ErrorResponse(c, err)
+			c.Abort()
+			return
+		}
+
+		// 5. 首次登录必须先修改密码
+		if claims.PasswordChangeRequired && !allowPasswordChange {
+			utils.ErrorResponse(c, errors.NewPasswordChangeRequiredError("首次登录请先修改密码"))
+			c.Abort()
+			return
+		}
+
+		// 6. 将用户信息存入上下文
 		c.Set("user_id", claims.UserID)
+		c.Set("session_id", claims.SessionID)
 		c.Set("username", claims.Username)
 		c.Set("role", claims.Role)
 
-		// 5. 继续处理请求
+		// 7. 继续处理请求
 		c.Next()
 	}
 }
 
-// AdminMiddleware 管理员权限中间件
+// RequirePermission 权限检查中间件，要求当前用户通过角色拥有全部指定权限
 // 注意：必须在 AuthMiddleware 之后使用
-func AdminMiddleware() gin.HandlerFunc {
+func RequirePermission(roleService *service.RoleService, permissions ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		roleAny, exists := c.Get("role")
+		userID, exists := c.Get("user_id")
 		if !exists {
 			utils.ErrorResponse(c, errors.NewUnauthorizedError("未登录"))
 			c.Abort()
 			return
 		}
 
-		role, ok := roleAny.(string)
-		if !ok || role == "" {
-			utils.ErrorResponse(c, errors.NewUnauthorizedError("无效的登录信息"))
+		allowed, err := roleService.HasPermissions(userID.(int64), permissions...)
+		if err != nil {
+			utils.ErrorResponse(c, err)
 			c.Abort()
 			return
 		}
-
-		if role != "admin" {
-			utils.ErrorResponse(c, errors.NewForbiddenError("需要管理员权限"))
+		if !allowed {
+			utils.ErrorResponse(c, errors.NewForbiddenError("权限不足"))
 			c.Abort()
 			return
 		}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"gohotel/internal/service"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HotelHeader 指定酒店的请求头
+const HotelHeader = "X-Hotel-ID"
+
+// HotelSelectorMiddleware 公开接口的酒店选择中间件
+// 从查询参数 hotel_id 或请求头 X-Hotel-ID 读取酒店，未指定时使用默认酒店，结果存入上下文 hotel_id
+func HotelSelectorMiddleware(hotelService *service.HotelService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		selected, ok := parseHotelSelector(c)
+		if !ok {
+			return
+		}
+
+		hotelID, err := hotelService.ResolvePublicHotel(selected)
+		if err != nil {
+			utils.ErrorResponse(c, err)
+			c.Abort()
+			return
+		}
+
+		c.Set("hotel_id", hotelID)
+		c.Next()
+	}
+}
+
+// HotelScopeMiddleware 员工接口的酒店范围中间件
+// 员工只能访问被分配的酒店，结果存入上下文 hotel_id
+// 注意：必须在 AuthMiddleware 之后使用
+func HotelScopeMiddleware(hotelService *service.HotelService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			utils.ErrorResponse(c, errors.NewUnauthorizedError("未登录"))
+			c.Abort()
+			return
+		}
+
+		selected, ok := parseHotelSelector(c)
+		if !ok {
+			return
+		}
+
+		hotelID, err := hotelService.ResolveStaffHotel(userID.(int64), selected)
+		if err != nil {
+			utils.ErrorResponse(c, err)
+			c.Abort()
+			return
+		}
+
+		c.Set("hotel_id", hotelID)
+		c.Next()
+	}
+}
+
+// parseHotelSelector 读取请求中指定的酒店 ID，未指定时返回 0
+func parseHotelSelector(c *gin.Context) (uint, bool) {
+	raw := c.Query("hotel_id")
+	if raw == "" {
+		raw = c.GetHeader(HotelHeader)
+	}
+	if raw == "" {
+		return 0, true
+	}
+
+	id, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil || id == 0 {
+		utils.ErrorResponse(c, errors.NewBadRequestError("无效的酒店ID"))
+		c.Abort()
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+// Amenity 房间设施目录模型（如 WiFi、浴缸、阳台）
+// 对应数据库中的 amenities 表，与房间通过 room_amenities 表多对多关联
+type Amenity struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`                            // 主键
+	Name      string    `gorm:"unique;not null;size:50" json:"name"`             // 名称（唯一）
+	Icon      string    `gorm:"size:255" json:"icon"`                            // 图标（图标名或图片 URL）
+	Category  string    `gorm:"default:'general';size:50;index" json:"category"` // 分类：general, bathroom, bedroom, entertainment, view 等
+	Sort      int       `gorm:"default:0" json:"sort"`                           // 排序，数字越小越靠前
+	CreatedAt time.Time `json:"created_at"`                                      // 创建时间
+	UpdatedAt time.Time `json:"updated_at"`                                      // 更新时间
+}
+
+// TableName 指定表名
+func (Amenity) TableName() string {
+	return "amenities"
+}
+
+// AmenityFacet 设施分面统计
+// 表示在当前筛选条件下，具备该设施的房间数量
+type AmenityFacet struct {
+	AmenityID uint   `json:"amenity_id"`
+	Name      string `json:"name"`
+	Icon      string `json:"icon"`
+	Category  string `json:"category"`
+	Count     int64  `json:"count"`
+}
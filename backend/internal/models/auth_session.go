@@ -0,0 +1,51 @@
+package models
+
+import (
+	"gohotel/pkg/utils"
+	"time"
+)
+
+// AuthSession 登录会话
+// 对应数据库中的 auth_sessions 表，每次登录创建一个会话，访问令牌通过 sid 关联会话，
+// 注销、修改密码或封禁用户时撤销会话，该会话签发的访问令牌和刷新令牌随即失效；
+// 同时记录登录设备和最近活跃时间，用户可以查看并撤销自己在各设备上的登录
+type AuthSession struct {
+	ID         utils.JSONInt64 `gorm:"primaryKey;autoIncrement:false" json:"id"` // 会话 ID（雪花算法生成），写入访问令牌的 sid
+	UserID     utils.JSONInt64 `gorm:"not null;index" json:"user_id"`            // 用户 ID
+	Device     string          `gorm:"size:100" json:"device"`                   // 设备描述，根据 User-Agent 识别
+	IP         string          `gorm:"size:45" json:"ip"`                        // 最近一次登录或刷新令牌的 IP
+	UserAgent  string          `gorm:"size:255" json:"user_agent"`               // 最近一次登录或刷新令牌的 User-Agent
+	LastSeenAt time.Time       `json:"last_seen_at"`                             // 最近活跃时间
+	ExpiresAt  time.Time       `gorm:"not null;index" json:"expires_at"`         // 过期时间，每次刷新令牌时顺延
+	RevokedAt  *time.Time      `json:"revoked_at"`                               // 撤销时间，为空表示有效
+	CreatedAt  time.Time       `json:"created_at"`                               // 登录时间
+	UpdatedAt  time.Time       `json:"updated_at"`                               // 更新时间
+}
+
+// TableName 指定表名
+func (AuthSession) TableName() string {
+	return "auth_sessions"
+}
+
+// IsActive 判断会话是否有效
+func (s *AuthSession) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// RefreshToken 刷新令牌
+// 对应数据库中的 refresh_tokens 表，只保存令牌的 SHA-256 摘要；
+// 每个刷新令牌只能使用一次，使用后轮换出新令牌，已使用的令牌再次出现时视为泄露并撤销整个会话
+type RefreshToken struct {
+	ID        uint            `gorm:"primaryKey" json:"id"`             // 主键
+	SessionID utils.JSONInt64 `gorm:"not null;index" json:"session_id"` // 所属会话 ID
+	UserID    utils.JSONInt64 `gorm:"not null;index" json:"user_id"`    // 用户 ID
+	TokenHash string          `gorm:"unique;not null;size:64" json:"-"` // 令牌摘要
+	ExpiresAt time.Time       `gorm:"not null;index" json:"expires_at"` // 过期时间
+	UsedAt    *time.Time      `json:"used_at"`                          // 轮换时间，为空表示未使用
+	CreatedAt time.Time       `json:"created_at"`                       // 签发时间
+}
+
+// TableName 指定表名
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
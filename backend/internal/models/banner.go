@@ -8,6 +8,7 @@ import (
 // 对应数据库中的 banners 表
 type Banner struct {
 	ID        int64      `gorm:"primaryKey;autoIncrement" json:"id"`     // 主键（自增ID）
+	HotelID   uint       `gorm:"not null;index" json:"hotel_id"`         // 所属酒店 ID
 	Title     string     `gorm:"not null;size:100" json:"title"`         // 标题
 	Subtitle  *string    `gorm:"size:255" json:"subtitle"`               // 副标题（可为空）
 	ImageURL  string     `gorm:"not null;size:500" json:"image_url"`     // 图片URL
@@ -10,12 +10,19 @@ import (
 type Booking struct {
 	ID             utils.JSONInt64 `gorm:"primaryKey" json:"id"`                                 // 主键（JSON序列化为字符串）
 	BookingNumber  utils.JSONInt64 `gorm:"unique;not null" json:"booking_number"`                // 预订单号（唯一，JSON序列化为字符串）
+	HotelID        uint            `gorm:"not null;index" json:"hotel_id"`                       // 所属酒店 ID（与房间一致）
 	UserID         utils.JSONInt64 `gorm:"not null;index" json:"user_id"`                        // 用户 ID（有索引，JSON序列化为字符串）
 	RoomID         int64           `gorm:"not null;index" json:"room_id"`                        // 房间 ID（有索引）
-	CheckIn        time.Time       `gorm:"not null;index" json:"check_in"`                       // 入住日期（有索引）
-	CheckOut       time.Time       `gorm:"not null;index" json:"check_out"`                      // 退房日期（有索引）
-	TotalDays      int             `gorm:"not null" json:"total_days"`                           // 总天数
-	TotalPrice     float64         `gorm:"not null;type:decimal(10,2)" json:"total_price"`       // 总价
+	BookingType    string          `gorm:"default:'nightly';size:20;index" json:"booking_type"`  // 预订类型：nightly（按晚）, hourly（钟点房）
+	CheckIn        time.Time       `gorm:"not null;index" json:"check_in"`                       // 入住日期（有索引，钟点房为开始时间所在日期）
+	CheckOut       time.Time       `gorm:"not null;index" json:"check_out"`                      // 退房日期（有索引，钟点房与入住日期相同）
+	StartTime      *time.Time      `json:"start_time,omitempty"`                                 // 钟点房开始时间
+	EndTime        *time.Time      `json:"end_time,omitempty"`                                   // 钟点房结束时间
+	TotalDays      int             `gorm:"not null" json:"total_days"`                           // 总天数（钟点房为 0）
+	TotalHours     int             `gorm:"not null;default:0" json:"total_hours"`                // 总小时数（钟点房）
+	TotalPrice     float64         `gorm:"not null;type:decimal(10,2)" json:"total_price"`       // 总价（已扣除连住折扣）
+	DiscountAmount float64         `gorm:"type:decimal(10,2)" json:"discount_amount"`            // 连住折扣减免金额
+	BillingCycle   string          `gorm:"default:'full';size:20" json:"billing_cycle"`          // 付款方式：full（一次付清）, monthly（按月付款）
 	GuestName      string          `gorm:"not null;size:50" json:"guest_name"`                   // 入住人姓名
 	GuestPhone     string          `gorm:"not null;size:20" json:"guest_phone"`                  // 入住人电话
 	GuestIDCard    string          `gorm:"size:50" json:"guest_id_card"`                         // 入住人身份证号
@@ -31,6 +38,9 @@ type Booking struct {
 	// 当查询 Booking 时，可以同时加载 User 和 Room 的信息
 	User User `gorm:"foreignKey:UserID" json:"user,omitempty"` // 关联的用户
 	Room Room `gorm:"foreignKey:RoomID" json:"room,omitempty"` // 关联的房间
+
+	// 按月付款的分期账单（仅预订详情加载）
+	Installments []BookingInstallment `gorm:"foreignKey:BookingID" json:"installments,omitempty"`
 }
 
 // TableName 指定表名
@@ -38,6 +48,26 @@ func (Booking) TableName() string {
 	return "bookings"
 }
 
+// 预订类型
+const (
+	BookingTypeNightly = "nightly" // 按晚预订
+	BookingTypeHourly  = "hourly"  // 钟点房
+)
+
+// IsHourly 判断是否为钟点房预订
+func (b *Booking) IsHourly() bool {
+	return b.BookingType == BookingTypeHourly
+}
+
+// OccupiesDate 判断预订是否占用房间在指定日期（当晚）
+// 按晚预订占用入住日到退房前一天；钟点房的入住、退房日期相同，按实际开始、结束时间占用所跨的日期
+func (b *Booking) OccupiesDate(date time.Time) bool {
+	if b.IsHourly() && b.StartTime != nil && b.EndTime != nil {
+		return b.StartTime.Before(date.AddDate(0, 0, 1)) && date.Before(*b.EndTime)
+	}
+	return !date.Before(b.CheckIn) && date.Before(b.CheckOut)
+}
+
 // IsPending 判断是否待确认
 func (b *Booking) IsPending() bool {
 	return b.Status == "pending"
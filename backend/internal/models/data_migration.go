@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// DataMigration 一次性数据迁移的执行记录
+// 对应数据库中的 data_migrations 表，已记录的迁移在启动时不再执行
+type DataMigration struct {
+	Name      string    `gorm:"primaryKey;size:100" json:"name"` // 迁移名称
+	AppliedAt time.Time `json:"applied_at"`                      // 执行时间
+}
+
+// TableName 指定表名
+func (DataMigration) TableName() string {
+	return "data_migrations"
+}
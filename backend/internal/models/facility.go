@@ -8,7 +8,8 @@ import (
 // 对应数据库中的 facilities 表
 type Facility struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
-	Type      string    `gorm:"not null;size:50;index" json:"type"` // 设施类型：elevator, corridor, storage 等
+	HotelID   uint      `gorm:"not null;index" json:"hotel_id"`     // 所属酒店 ID
+	Type      string    `gorm:"not null;size:50;index" json:"type"` // 设施类型：elevator, stairs, corridor, exit（安全出口）, storage 等
 	Floor     int       `gorm:"not null;index" json:"floor"`        // 楼层
 	Left      int       `gorm:"not null" json:"left"`               // X 坐标（左边距）
 	Top       int       `gorm:"not null" json:"top"`                // Y 坐标（上边距）
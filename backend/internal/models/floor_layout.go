@@ -0,0 +1,40 @@
+package models
+
+import (
+	"gohotel/pkg/utils"
+	"time"
+)
+
+// FloorLayoutVersion 楼层布局版本模型
+// 对应数据库中的 floor_layout_versions 表
+// 每个酒店的每个楼层同一时间最多一个草稿（draft）和一个已发布（published）版本，其余为历史版本（archived）
+type FloorLayoutVersion struct {
+	ID          uint             `gorm:"primaryKey" json:"id"`                                   // 主键
+	HotelID     uint             `gorm:"not null;uniqueIndex:idx_floor_version" json:"hotel_id"` // 所属酒店 ID
+	Floor       int              `gorm:"not null;uniqueIndex:idx_floor_version" json:"floor"`    // 楼层
+	Version     int              `gorm:"not null;uniqueIndex:idx_floor_version" json:"version"`  // 版本号（楼层内递增）
+	Status      string           `gorm:"default:'draft';size:20;index" json:"status"`            // 状态：draft, published, archived
+	Snapshot    string           `gorm:"type:text" json:"-"`                                     // 布局快照（JSON）
+	Note        string           `gorm:"size:255" json:"note"`                                   // 备注
+	BasedOnID   *uint            `json:"based_on_id"`                                            // 回滚来源版本 ID
+	CreatedBy   utils.JSONInt64  `gorm:"not null" json:"created_by"`                             // 创建人 ID
+	PublishedBy *utils.JSONInt64 `json:"published_by"`                                           // 发布人 ID
+	PublishedAt *time.Time       `json:"published_at"`                                           // 发布时间
+	CreatedAt   time.Time        `json:"created_at"`                                             // 创建时间
+	UpdatedAt   time.Time        `json:"updated_at"`                                             // 更新时间
+}
+
+// TableName 指定表名
+func (FloorLayoutVersion) TableName() string {
+	return "floor_layout_versions"
+}
+
+// IsDraft 判断是否为草稿
+func (v *FloorLayoutVersion) IsDraft() bool {
+	return v.Status == "draft"
+}
+
+// IsPublished 判断是否为当前发布版本
+func (v *FloorLayoutVersion) IsPublished() bool {
+	return v.Status == "published"
+}
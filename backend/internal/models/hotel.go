@@ -0,0 +1,48 @@
+package models
+
+import (
+	"gohotel/pkg/utils"
+	"time"
+)
+
+// Hotel 酒店（门店）模型
+// 对应数据库中的 hotels 表，房间、设施、预订、横幅和公告都归属于某个酒店
+type Hotel struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`                   // 主键
+	Code      string    `gorm:"unique;not null;size:50" json:"code"`    // 酒店编码（唯一）
+	Name      string    `gorm:"not null;size:100" json:"name"`          // 酒店名称
+	City      string    `gorm:"size:50;index" json:"city"`              // 所在城市
+	Address   string    `gorm:"size:255" json:"address"`                // 地址
+	Phone     string    `gorm:"size:20" json:"phone"`                   // 联系电话
+	Status    string    `gorm:"default:'active';size:20" json:"status"` // 状态：active, inactive
+	Sort      int       `gorm:"default:0" json:"sort"`                  // 排序，数字越小越靠前
+	CreatedAt time.Time `json:"created_at"`                             // 创建时间
+	UpdatedAt time.Time `json:"updated_at"`                             // 更新时间
+}
+
+// TableName 指定表名
+func (Hotel) TableName() string {
+	return "hotels"
+}
+
+// IsActive 判断酒店是否营业中
+func (h *Hotel) IsActive() bool {
+	return h.Status == "active"
+}
+
+// HotelStaff 酒店员工分配模型
+// 对应数据库中的 hotel_staff 表，员工只能管理被分配的酒店
+type HotelStaff struct {
+	ID        uint            `gorm:"primaryKey" json:"id"`                                      // 主键
+	HotelID   uint            `gorm:"not null;uniqueIndex:idx_hotel_staff" json:"hotel_id"`      // 酒店 ID
+	UserID    utils.JSONInt64 `gorm:"not null;uniqueIndex:idx_hotel_staff;index" json:"user_id"` // 员工用户 ID
+	CreatedAt time.Time       `json:"created_at"`                                                // 分配时间
+
+	// 关联查询（可选）
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"` // 员工信息
+}
+
+// TableName 指定表名
+func (HotelStaff) TableName() string {
+	return "hotel_staff"
+}
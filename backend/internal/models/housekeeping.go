@@ -0,0 +1,43 @@
+package models
+
+import (
+	"gohotel/pkg/utils"
+	"time"
+)
+
+// HousekeepingTask 客房清洁任务模型
+// 对应数据库中的 housekeeping_tasks 表
+type HousekeepingTask struct {
+	ID          uint             `gorm:"primaryKey" json:"id"`                          // 主键
+	RoomID      uint             `gorm:"not null;index" json:"room_id"`                 // 房间 ID
+	BookingID   *utils.JSONInt64 `gorm:"index" json:"booking_id"`                       // 关联的预订 ID（退房自动生成时有值）
+	TaskType    string           `gorm:"not null;size:20" json:"task_type"`             // 任务类型：checkout（退房清扫）, stayover（续住清扫）, deep（深度清洁）
+	Status      string           `gorm:"default:'pending';size:20;index" json:"status"` // 状态：pending, in_progress, cleaned, inspected
+	AssigneeID  *utils.JSONInt64 `gorm:"index" json:"assignee_id"`                      // 负责的保洁员 ID
+	InspectorID *utils.JSONInt64 `json:"inspector_id"`                                  // 查房人 ID
+	Note        string           `gorm:"type:text" json:"note"`                         // 备注（查房不合格原因等）
+	StartedAt   *time.Time       `json:"started_at"`                                    // 开始清扫时间
+	CleanedAt   *time.Time       `json:"cleaned_at"`                                    // 清扫完成时间
+	InspectedAt *time.Time       `json:"inspected_at"`                                  // 查房通过时间
+	CreatedAt   time.Time        `json:"created_at"`                                    // 创建时间
+	UpdatedAt   time.Time        `json:"updated_at"`                                    // 更新时间
+
+	// 关联查询（可选）
+	Room     Room  `gorm:"foreignKey:RoomID" json:"room,omitempty"`         // 关联的房间
+	Assignee *User `gorm:"foreignKey:AssigneeID" json:"assignee,omitempty"` // 负责的保洁员
+}
+
+// TableName 指定表名
+func (HousekeepingTask) TableName() string {
+	return "housekeeping_tasks"
+}
+
+// IsOpen 判断任务是否仍未完成（未通过查房）
+func (t *HousekeepingTask) IsOpen() bool {
+	return t.Status != "inspected"
+}
+
+// IsAssignedTo 判断任务是否分配给指定员工
+func (t *HousekeepingTask) IsAssignedTo(userID int64) bool {
+	return t.AssigneeID != nil && t.AssigneeID.Int64() == userID
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"gohotel/pkg/utils"
+	"time"
+)
+
+// LoginLockout 账号登录失败与锁定记录
+// 对应数据库中的 login_lockouts 表，每个账号一条；连续失败达到阈值后临时锁定，
+// 锁定时长随连续锁定次数指数增长，登录成功或管理员解锁后清零
+type LoginLockout struct {
+	UserID       utils.JSONInt64 `gorm:"primaryKey;autoIncrement:false" json:"user_id"` // 用户 ID
+	FailedCount  int             `gorm:"not null;default:0" json:"failed_count"`        // 统计窗口内的连续失败次数
+	LockCount    int             `gorm:"not null;default:0" json:"lock_count"`          // 连续锁定次数，决定下次锁定时长
+	LastFailedAt *time.Time      `json:"last_failed_at"`                                // 最近一次失败时间
+	LockedUntil  *time.Time      `gorm:"index" json:"locked_until"`                     // 锁定截止时间，为空表示未锁定
+	UpdatedAt    time.Time       `json:"updated_at"`                                    // 更新时间
+}
+
+// TableName 指定表名
+func (LoginLockout) TableName() string {
+	return "login_lockouts"
+}
+
+// IsLocked 判断账号当前是否处于锁定状态
+func (l *LoginLockout) IsLocked(now time.Time) bool {
+	return l.LockedUntil != nil && now.Before(*l.LockedUntil)
+}
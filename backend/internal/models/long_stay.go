@@ -0,0 +1,91 @@
+package models
+
+import (
+	"gohotel/pkg/utils"
+	"time"
+)
+
+// StayDiscount 连住折扣档位
+// 按晚预订的连住晚数达到档位时整单打折，例如"连住 7 晚 95 折、30 晚以上 8 折"
+type StayDiscount struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	HotelID         uint      `gorm:"not null;index" json:"hotel_id"`                     // 所属酒店 ID
+	RoomType        string    `gorm:"size:50" json:"room_type"`                           // 适用房型，为空时适用所有房型
+	MinNights       int       `gorm:"not null" json:"min_nights"`                         // 最少连住晚数，达到时生效
+	DiscountPercent float64   `gorm:"not null;type:decimal(5,2)" json:"discount_percent"` // 折扣比例（百分比），如 20 表示减免 20%
+	Enabled         bool      `gorm:"not null;index" json:"enabled"`                      // 是否启用
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (StayDiscount) TableName() string {
+	return "stay_discounts"
+}
+
+// Matches 判断档位是否适用于房型和连住晚数
+func (d *StayDiscount) Matches(roomType string, nights int) bool {
+	return d.Enabled && nights >= d.MinNights && (d.RoomType == "" || d.RoomType == roomType)
+}
+
+// LongStayPolicy 长住政策
+// 按月付款的预订在下单时收取押金，与是否命中连住折扣档位无关；
+// 每个房型最多一条，指定房型的政策优先于适用所有房型的政策
+type LongStayPolicy struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	HotelID   uint      `gorm:"not null;uniqueIndex:idx_long_stay_policy" json:"hotel_id"` // 所属酒店 ID
+	RoomType  string    `gorm:"size:50;uniqueIndex:idx_long_stay_policy" json:"room_type"` // 适用房型，为空时适用所有房型
+	Deposit   float64   `gorm:"not null;type:decimal(10,2)" json:"deposit"`                // 按月付款时收取的押金，0 表示不收
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (LongStayPolicy) TableName() string {
+	return "long_stay_policies"
+}
+
+// 付款方式
+const (
+	BillingCycleFull    = "full"    // 一次付清
+	BillingCycleMonthly = "monthly" // 按月付款
+)
+
+// 账单类型
+const (
+	InstallmentTypeDeposit = "deposit" // 押金
+	InstallmentTypeRent    = "rent"    // 房费
+)
+
+// 账单状态
+const (
+	InstallmentScheduled = "scheduled" // 未到期
+	InstallmentBilled    = "billed"    // 已出账，待支付
+	InstallmentPaid      = "paid"      // 已支付
+	InstallmentCancelled = "cancelled" // 预订取消后作废
+)
+
+// BookingInstallment 按月付款预订的分期账单
+// 押金在下单时出账，房费按每 30 晚一期，到期日由定时任务出账
+type BookingInstallment struct {
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	BookingID   utils.JSONInt64 `gorm:"not null;index" json:"booking_id"`                // 预订 ID
+	HotelID     uint            `gorm:"not null;index" json:"hotel_id"`                  // 所属酒店 ID
+	Seq         int             `gorm:"not null" json:"seq"`                             // 期数，押金为 0
+	Type        string          `gorm:"not null;size:20" json:"type"`                    // 类型：deposit, rent
+	PeriodStart time.Time       `gorm:"not null;type:date" json:"period_start"`          // 计费开始日期
+	PeriodEnd   time.Time       `gorm:"not null;type:date" json:"period_end"`            // 计费结束日期（不包含），押金与开始日期相同
+	Nights      int             `gorm:"not null;default:0" json:"nights"`                // 本期晚数
+	Amount      float64         `gorm:"not null;type:decimal(10,2)" json:"amount"`       // 金额
+	DueDate     time.Time       `gorm:"not null;type:date;index" json:"due_date"`        // 到期日，当天出账
+	Status      string          `gorm:"default:'scheduled';size:20;index" json:"status"` // 状态：scheduled, billed, paid, cancelled
+	BilledAt    *time.Time      `json:"billed_at"`                                       // 出账时间
+	PaidAt      *time.Time      `json:"paid_at"`                                         // 支付时间
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (BookingInstallment) TableName() string {
+	return "booking_installments"
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"gohotel/pkg/utils"
+	"time"
+)
+
+// UserTOTP 用户的 TOTP 两步验证设置
+// 对应数据库中的 user_totps 表，绑定时先生成密钥，用户输入一次正确的动态码后才启用；
+// LastStep 记录最近一次通过验证的时间步，同一动态码不能重复使用
+type UserTOTP struct {
+	UserID    utils.JSONInt64 `gorm:"primaryKey;autoIncrement:false" json:"user_id"` // 用户 ID
+	Secret    string          `gorm:"not null;size:64" json:"-"`                     // Base32 编码的密钥
+	EnabledAt *time.Time      `json:"enabled_at"`                                    // 启用时间，为空表示尚未完成绑定
+	LastStep  int64           `gorm:"not null;default:0" json:"-"`                   // 最近一次通过验证的时间步
+	CreatedAt time.Time       `json:"created_at"`                                    // 创建时间
+	UpdatedAt time.Time       `json:"updated_at"`                                    // 更新时间
+}
+
+// TableName 指定表名
+func (UserTOTP) TableName() string {
+	return "user_totps"
+}
+
+// IsEnabled 判断两步验证是否已启用
+func (t *UserTOTP) IsEnabled() bool {
+	return t.EnabledAt != nil
+}
+
+// MFARecoveryCode 两步验证恢复码
+// 对应数据库中的 mfa_recovery_codes 表，只保存摘要；丢失身份验证器时用于代替动态码，每个只能使用一次
+type MFARecoveryCode struct {
+	ID        uint            `gorm:"primaryKey" json:"id"`             // 主键
+	UserID    utils.JSONInt64 `gorm:"not null;index" json:"user_id"`    // 用户 ID
+	CodeHash  string          `gorm:"unique;not null;size:64" json:"-"` // 恢复码摘要
+	UsedAt    *time.Time      `json:"used_at"`                          // 使用时间，为空表示可用
+	CreatedAt time.Time       `json:"created_at"`                       // 生成时间
+}
+
+// TableName 指定表名
+func (MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+// MFAChallenge 登录的第二步验证
+// 对应数据库中的 mfa_challenges 表，密码等第一步验证通过后签发，只保存令牌摘要；
+// 凭令牌提交动态码或恢复码后才创建登录会话，失败次数过多或过期后需要重新登录
+type MFAChallenge struct {
+	ID            uint            `gorm:"primaryKey" json:"id"`                         // 主键
+	UserID        utils.JSONInt64 `gorm:"not null;index" json:"user_id"`                // 用户 ID
+	TokenHash     string          `gorm:"unique;not null;size:64" json:"-"`             // 令牌摘要
+	SetupRequired bool            `gorm:"not null;default:false" json:"setup_required"` // 角色要求两步验证但用户尚未绑定，需先完成绑定
+	Attempts      int             `gorm:"not null;default:0" json:"attempts"`           // 已失败次数
+	ExpiresAt     time.Time       `gorm:"not null;index" json:"expires_at"`             // 过期时间
+	UsedAt        *time.Time      `json:"used_at"`                                      // 完成时间，为空表示未完成
+	CreatedAt     time.Time       `json:"created_at"`                                   // 签发时间
+}
+
+// TableName 指定表名
+func (MFAChallenge) TableName() string {
+	return "mfa_challenges"
+}
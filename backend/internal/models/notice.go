@@ -8,6 +8,7 @@ import (
 // 对应数据库中的 notices表
 type Notice struct {
 	ID        int64      `gorm:"primaryKey;autoIncrement" json:"id"`     // 自增主键
+	HotelID   uint       `gorm:"not null;index" json:"hotel_id"`         // 所属酒店 ID
 	Title     string     `gorm:"not null;size:100" json:"title"`         // 标题
 	LinkURL   *string    `gorm:"size:500" json:"link_url"`               // 跳转链接（可为空）
 	Sort      int        `gorm:"default:0" json:"sort"`                  // 排序，数字越小越靠前
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"gohotel/pkg/utils"
+	"time"
+)
+
+// 密码令牌用途
+const (
+	PasswordTokenReset  = "reset"  // 找回密码
+	PasswordTokenInvite = "invite" // 管理员邀请新用户设置密码
+)
+
+// PasswordResetToken 设置密码令牌
+// 对应数据库中的 password_reset_tokens 表，只保存令牌的 SHA-256 摘要；
+// 令牌随链接发送到用户的邮箱或手机，只能使用一次，签发新令牌时同一用户未使用的旧令牌作废
+type PasswordResetToken struct {
+	ID        uint            `gorm:"primaryKey" json:"id"`             // 主键
+	UserID    utils.JSONInt64 `gorm:"not null;index" json:"user_id"`    // 用户 ID
+	Purpose   string          `gorm:"not null;size:10" json:"purpose"`  // 用途：reset, invite
+	TokenHash string          `gorm:"unique;not null;size:64" json:"-"` // 令牌摘要
+	ExpiresAt time.Time       `gorm:"not null;index" json:"expires_at"` // 过期时间
+	UsedAt    *time.Time      `json:"used_at"`                          // 使用或作废时间，为空表示有效
+	CreatedAt time.Time       `json:"created_at"`                       // 签发时间
+}
+
+// TableName 指定表名
+func (PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// 定价规则调整方式
+const (
+	PricingAdjustPercent = "percent" // 按百分比上浮
+	PricingAdjustAmount  = "amount"  // 按固定金额上浮
+)
+
+// PricingRule 动态定价规则
+// 房型在某日的入住率超过阈值时上浮房价，例如"入住率超过 80% 时上浮 15%，最高不超过 899"
+type PricingRule struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	HotelID      uint      `gorm:"not null;index" json:"hotel_id"`                  // 所属酒店 ID
+	Name         string    `gorm:"not null;size:100" json:"name"`                   // 规则名称
+	RoomType     string    `gorm:"size:50" json:"room_type"`                        // 适用房型，为空时适用所有房型
+	MinOccupancy float64   `gorm:"not null;type:decimal(5,2)" json:"min_occupancy"` // 入住率阈值（百分比），超过时生效
+	AdjustType   string    `gorm:"not null;size:20" json:"adjust_type"`             // 调整方式：percent, amount
+	AdjustValue  float64   `gorm:"not null;type:decimal(10,2)" json:"adjust_value"` // 上浮比例（百分比）或金额
+	MaxPrice     float64   `gorm:"type:decimal(10,2)" json:"max_price"`             // 调整后的价格上限，0 表示不限
+	Enabled      bool      `gorm:"not null;index" json:"enabled"`                   // 是否启用
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (PricingRule) TableName() string {
+	return "pricing_rules"
+}
+
+// RoomRate 房价日历
+// 记录房型每一天的入住率和生效的定价规则，由定时任务自动维护
+type RoomRate struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	HotelID     uint      `gorm:"not null;uniqueIndex:idx_room_rate" json:"hotel_id"`          // 所属酒店 ID
+	RoomType    string    `gorm:"not null;size:50;uniqueIndex:idx_room_rate" json:"room_type"` // 房型
+	Date        time.Time `gorm:"not null;type:date;uniqueIndex:idx_room_rate" json:"date"`    // 日期
+	Occupancy   float64   `gorm:"type:decimal(5,2)" json:"occupancy"`                          // 入住率（百分比）
+	RuleID      *uint     `json:"rule_id"`                                                     // 生效的定价规则，为空表示原价
+	RuleName    string    `gorm:"size:100" json:"rule_name"`
+	AdjustType  string    `gorm:"size:20" json:"adjust_type"`             // 生效时规则的调整方式（快照）
+	AdjustValue float64   `gorm:"type:decimal(10,2)" json:"adjust_value"` // 生效时规则的调整值（快照）
+	MaxPrice    float64   `gorm:"type:decimal(10,2)" json:"max_price"`    // 生效时规则的价格上限（快照）
+	BasePrice   float64   `gorm:"type:decimal(10,2)" json:"base_price"`   // 房型参考价（房型内最低房价）
+	Price       float64   `gorm:"type:decimal(10,2)" json:"price"`        // 参考价调整后的价格
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (RoomRate) TableName() string {
+	return "room_rates"
+}
+
+// Apply 按日历中生效的规则计算房间当日价格
+func (r *RoomRate) Apply(base float64) float64 {
+	if r.RuleID == nil {
+		return base
+	}
+	return AdjustPrice(base, r.AdjustType, r.AdjustValue, r.MaxPrice)
+}
+
+// PriceChangeLog 自动调价记录
+type PriceChangeLog struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	HotelID   uint      `gorm:"not null;index" json:"hotel_id"`
+	RoomType  string    `gorm:"not null;size:50" json:"room_type"`
+	Date      time.Time `gorm:"not null;type:date" json:"date"`      // 调价的日期
+	OldPrice  float64   `gorm:"type:decimal(10,2)" json:"old_price"` // 调价前的参考价
+	NewPrice  float64   `gorm:"type:decimal(10,2)" json:"new_price"` // 调价后的参考价
+	Occupancy float64   `gorm:"type:decimal(5,2)" json:"occupancy"`  // 调价时的入住率
+	RuleID    *uint     `json:"rule_id"`                             // 生效的规则，为空表示恢复原价
+	RuleName  string    `gorm:"size:100" json:"rule_name"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// TableName 指定表名
+func (PriceChangeLog) TableName() string {
+	return "price_change_logs"
+}
+
+// AdjustPrice 按调整方式上浮价格，并限制在上限以内（上限低于原价时保持原价），结果保留两位小数
+func AdjustPrice(base float64, adjustType string, value float64, maxPrice float64) float64 {
+	price := base
+	switch adjustType {
+	case PricingAdjustPercent:
+		price = base * (1 + value/100)
+	case PricingAdjustAmount:
+		price = base + value
+	}
+	if maxPrice > 0 && price > maxPrice {
+		price = math.Max(maxPrice, base)
+	}
+	return math.Round(price*100) / 100
+}
@@ -0,0 +1,201 @@
+package models
+
+import (
+	"gohotel/pkg/utils"
+	"time"
+)
+
+// PermissionAll 通配权限，拥有时视为拥有全部权限（超级管理员）
+const PermissionAll = "*"
+
+// 权限码，格式为 资源:操作
+const (
+	PermUserRead   = "user:read"   // 查看用户
+	PermUserCreate = "user:create" // 新增用户
+	PermUserUpdate = "user:update" // 封禁、解封用户
+	PermUserDelete = "user:delete" // 删除用户
+	PermRoleManage = "role:manage" // 管理角色与用户角色分配
+
+	PermHotelRead   = "hotel:read"   // 查看酒店
+	PermHotelManage = "hotel:manage" // 创建、更新酒店
+	PermHotelStaff  = "hotel:staff"  // 分配酒店员工
+
+	PermLogRead = "log:read" // 查看日志
+
+	PermBookingRead     = "booking:read"     // 查看、搜索预订
+	PermBookingConfirm  = "booking:confirm"  // 确认预订
+	PermBookingCheckIn  = "booking:checkin"  // 办理入住
+	PermBookingCheckOut = "booking:checkout" // 办理退房
+	PermBookingPayment  = "booking:payment"  // 登记收款
+
+	PermRoomRead   = "room:read"   // 查看房态图
+	PermRoomCreate = "room:create" // 创建、批量创建、导入房间
+	PermRoomUpdate = "room:update" // 更新房间信息、设施和图库
+	PermRoomDelete = "room:delete" // 删除房间
+
+	PermHousekeepingManage = "housekeeping:manage" // 清洁任务派单、查房、设置清洁状态
+	PermWorkOrderManage    = "workorder:manage"    // 管理维修工单
+	PermFloorManage        = "floor:manage"        // 编辑、发布楼层布局和设施位置
+	PermAmenityManage      = "amenity:manage"      // 管理设施目录
+	PermReviewModerate     = "review:moderate"     // 查看、隐藏、回复评价
+	PermContentManage      = "content:manage"      // 管理活动横幅和公告
+	PermPricingRead        = "pricing:read"        // 查看房价日历、定价预览和调价记录
+	PermPricingManage      = "pricing:manage"      // 管理定价规则和连住折扣
+)
+
+// PermissionInfo 权限说明
+type PermissionInfo struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+// Permissions 系统支持的全部权限
+var Permissions = []PermissionInfo{
+	{PermUserRead, "查看用户"},
+	{PermUserCreate, "新增用户"},
+	{PermUserUpdate, "封禁、解封用户"},
+	{PermUserDelete, "删除用户"},
+	{PermRoleManage, "管理角色与用户角色分配"},
+	{PermHotelRead, "查看酒店"},
+	{PermHotelManage, "创建、更新酒店"},
+	{PermHotelStaff, "分配酒店员工"},
+	{PermLogRead, "查看日志"},
+	{PermBookingRead, "查看、搜索预订"},
+	{PermBookingConfirm, "确认预订"},
+	{PermBookingCheckIn, "办理入住"},
+	{PermBookingCheckOut, "办理退房"},
+	{PermBookingPayment, "登记收款"},
+	{PermRoomRead, "查看房态图"},
+	{PermRoomCreate, "创建、导入房间"},
+	{PermRoomUpdate, "更新房间信息、设施和图库"},
+	{PermRoomDelete, "删除房间"},
+	{PermHousekeepingManage, "清洁任务派单、查房、设置清洁状态"},
+	{PermWorkOrderManage, "管理维修工单"},
+	{PermFloorManage, "编辑、发布楼层布局和设施位置"},
+	{PermAmenityManage, "管理设施目录"},
+	{PermReviewModerate, "查看、隐藏、回复评价"},
+	{PermContentManage, "管理活动横幅和公告"},
+	{PermPricingRead, "查看房价日历、定价预览和调价记录"},
+	{PermPricingManage, "管理定价规则和连住折扣"},
+}
+
+// IsValidPermission 判断权限码是否为系统支持的权限
+func IsValidPermission(code string) bool {
+	if code == PermissionAll {
+		return true
+	}
+	for _, p := range Permissions {
+		if p.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// 内置角色编码
+const (
+	RoleSuperAdmin   = "super_admin"  // 超级管理员
+	RoleManager      = "manager"      // 店长
+	RoleFrontDesk    = "front_desk"   // 前台
+	RoleHousekeeping = "housekeeping" // 客房保洁
+	RoleFinance      = "finance"      // 财务
+)
+
+// Role 员工角色
+// 对应数据库中的 roles 表，内置角色不可删除，但可以调整权限
+type Role struct {
+	ID          uint             `gorm:"primaryKey" json:"id"`
+	Code        string           `gorm:"unique;not null;size:50" json:"code"` // 角色编码（唯一）
+	Name        string           `gorm:"not null;size:50" json:"name"`        // 角色名称
+	Description string           `gorm:"size:255" json:"description"`         // 说明
+	BuiltIn     bool             `gorm:"not null;default:false" json:"built_in"`
+	RequireMFA  bool             `gorm:"not null;default:false" json:"require_mfa"` // 拥有该角色的用户必须启用两步验证
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	Permissions []RolePermission `gorm:"foreignKey:RoleID" json:"permissions"`
+}
+
+// TableName 指定表名
+func (Role) TableName() string {
+	return "roles"
+}
+
+// PermissionCodes 返回角色的权限码列表
+func (r *Role) PermissionCodes() []string {
+	codes := make([]string, len(r.Permissions))
+	for i, p := range r.Permissions {
+		codes[i] = p.Permission
+	}
+	return codes
+}
+
+// RolePermission 角色拥有的权限
+type RolePermission struct {
+	ID         uint   `gorm:"primaryKey" json:"-"`
+	RoleID     uint   `gorm:"not null;uniqueIndex:idx_role_permission" json:"-"`
+	Permission string `gorm:"not null;size:50;uniqueIndex:idx_role_permission" json:"permission"` // 权限码
+}
+
+// TableName 指定表名
+func (RolePermission) TableName() string {
+	return "role_permissions"
+}
+
+// UserRole 用户的角色分配
+type UserRole struct {
+	ID        uint            `gorm:"primaryKey" json:"id"`
+	UserID    utils.JSONInt64 `gorm:"not null;uniqueIndex:idx_user_role;index" json:"user_id"`
+	RoleID    uint            `gorm:"not null;uniqueIndex:idx_user_role" json:"role_id"`
+	CreatedAt time.Time       `json:"created_at"`
+
+	Role *Role `gorm:"foreignKey:RoleID" json:"role,omitempty"`
+}
+
+// TableName 指定表名
+func (UserRole) TableName() string {
+	return "user_roles"
+}
+
+// BuiltInRoles 内置角色及默认权限，首次启动时写入数据库
+var BuiltInRoles = []Role{
+	{
+		Code: RoleSuperAdmin, Name: "超级管理员", Description: "拥有全部权限",
+		Permissions: rolePermissions(PermissionAll),
+	},
+	{
+		Code: RoleManager, Name: "店长", Description: "管理酒店的日常运营",
+		Permissions: rolePermissions(
+			PermUserRead, PermUserCreate, PermHotelRead, PermHotelStaff, PermLogRead,
+			PermBookingRead, PermBookingConfirm, PermBookingCheckIn, PermBookingCheckOut, PermBookingPayment,
+			PermRoomRead, PermRoomCreate, PermRoomUpdate, PermRoomDelete,
+			PermHousekeepingManage, PermWorkOrderManage, PermFloorManage, PermAmenityManage,
+			PermReviewModerate, PermContentManage, PermPricingRead, PermPricingManage,
+		),
+	},
+	{
+		Code: RoleFrontDesk, Name: "前台", Description: "办理预订、入住和退房",
+		Permissions: rolePermissions(
+			PermHotelRead, PermBookingRead, PermBookingConfirm, PermBookingCheckIn, PermBookingCheckOut,
+			PermBookingPayment, PermRoomRead, PermWorkOrderManage,
+		),
+	},
+	{
+		Code: RoleHousekeeping, Name: "客房保洁", Description: "客房清洁派单与查房",
+		Permissions: rolePermissions(PermHotelRead, PermRoomRead, PermHousekeepingManage, PermWorkOrderManage),
+	},
+	{
+		Code: RoleFinance, Name: "财务", Description: "收款、定价与对账",
+		Permissions: rolePermissions(
+			PermHotelRead, PermLogRead, PermBookingRead, PermBookingPayment, PermPricingRead, PermPricingManage,
+		),
+	},
+}
+
+// rolePermissions 将权限码转换为角色权限记录
+func rolePermissions(codes ...string) []RolePermission {
+	perms := make([]RolePermission, len(codes))
+	for i, code := range codes {
+		perms[i] = RolePermission{Permission: code}
+	}
+	return perms
+}
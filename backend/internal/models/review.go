@@ -0,0 +1,51 @@
+package models
+
+import (
+	"gohotel/pkg/utils"
+	"time"
+)
+
+// Review 住客评价模型
+// 对应数据库中的 reviews 表，每个已退房的预订只能评价一次
+type Review struct {
+	ID             uint            `gorm:"primaryKey" json:"id"`                          // 主键
+	BookingID      utils.JSONInt64 `gorm:"unique;not null" json:"booking_id"`             // 预订 ID（唯一）
+	HotelID        uint            `gorm:"not null;index" json:"hotel_id"`                // 所属酒店 ID
+	UserID         utils.JSONInt64 `gorm:"not null;index" json:"user_id"`                 // 评价用户 ID
+	RoomID         int64           `gorm:"not null;index" json:"room_id"`                 // 房间 ID
+	RoomType       string          `gorm:"not null;size:50;index" json:"room_type"`       // 房型（评价时的快照，用于按房型汇总）
+	ReviewerName   string          `gorm:"size:50" json:"reviewer_name"`                  // 评价人昵称（脱敏）
+	ReviewerAvatar string          `gorm:"size:255" json:"reviewer_avatar"`               // 评价人头像
+	Cleanliness    int             `gorm:"not null" json:"cleanliness"`                   // 卫生评分（1-5）
+	Service        int             `gorm:"not null" json:"service"`                       // 服务评分（1-5）
+	Location       int             `gorm:"not null" json:"location"`                      // 位置评分（1-5）
+	Overall        float64         `gorm:"not null;type:decimal(3,2)" json:"overall"`     // 综合评分（三项平均）
+	Content        string          `gorm:"type:text" json:"content"`                      // 评价内容
+	Photos         string          `gorm:"type:text" json:"photos"`                       // 评价图片 URL（JSON 数组）
+	Status         string          `gorm:"default:'visible';size:20;index" json:"status"` // 状态：visible, hidden
+	HiddenReason   string          `gorm:"size:255" json:"hidden_reason,omitempty"`       // 隐藏原因
+	Reply          string          `gorm:"type:text" json:"reply"`                        // 酒店回复
+	RepliedAt      *time.Time      `json:"replied_at"`                                    // 回复时间
+	CreatedAt      time.Time       `json:"created_at"`                                    // 创建时间
+	UpdatedAt      time.Time       `json:"updated_at"`                                    // 更新时间
+}
+
+// TableName 指定表名
+func (Review) TableName() string {
+	return "reviews"
+}
+
+// IsVisible 判断评价是否公开展示
+func (r *Review) IsVisible() bool {
+	return r.Status == "visible"
+}
+
+// RoomTypeRating 房型评分汇总
+type RoomTypeRating struct {
+	RoomType       string  `json:"room_type"`
+	ReviewCount    int64   `json:"review_count"`
+	AvgCleanliness float64 `json:"avg_cleanliness"`
+	AvgService     float64 `json:"avg_service"`
+	AvgLocation    float64 `json:"avg_location"`
+	AvgOverall     float64 `json:"avg_overall"`
+}
@@ -7,25 +7,34 @@ import (
 // Room 房间模型
 // 对应数据库中的 rooms 表
 type Room struct {
-	ID            uint      `gorm:"primaryKey" json:"id"`                             // 主键
-	RoomNumber    string    `gorm:"unique;not null;size:20;index" json:"room_number"` // 房间号（唯一，有索引）
-	RoomType      string    `gorm:"not null;size:50;index" json:"room_type"`          // 房间类型（有索引）
-	Floor         int       `gorm:"not null" json:"floor"`                            // 楼层
-	Price         float64   `gorm:"not null;type:decimal(10,2)" json:"price"`         // 价格（每晚）
-	OriginalPrice float64   `gorm:"type:decimal(10,2)" json:"original_price"`         // 原价
-	Capacity      int       `gorm:"not null" json:"capacity"`                         // 可住人数
-	Area          float64   `gorm:"type:decimal(10,2)" json:"area"`                   // 面积（平方米）
-	BedType       string    `gorm:"size:50" json:"bed_type"`                          // 床型：单人床、双人床、大床
-	Description   string    `gorm:"type:text" json:"description"`                     // 房间描述
-	Facilities    string    `gorm:"type:text" json:"facilities"`                      // 设施（JSON 字符串）
-	Images        string    `gorm:"type:text" json:"images"`                          // 图片 URL（JSON 数组）
-	Left          int       `gorm:"not null" json:"left"`                            // 左边界
-	Top           int       `gorm:"not null" json:"top"`                             // 上边界
-	Width         int       `gorm:"not null" json:"width"`                           // 宽度
-	Height        int       `gorm:"not null" json:"height"`                          // 高度
-	Status        string    `gorm:"default:'available';size:20;index" json:"status"`  // 状态：available, occupied, maintenance
-	CreatedAt     time.Time `json:"created_at"`                                       // 创建时间
-	UpdatedAt     time.Time `json:"updated_at"`                                       // 更新时间
+	ID                 uint      `gorm:"primaryKey" json:"id"`                                         // 主键
+	HotelID            uint      `gorm:"not null;uniqueIndex:idx_room_no" json:"hotel_id"`             // 所属酒店 ID
+	RoomNumber         string    `gorm:"not null;size:20;uniqueIndex:idx_room_no" json:"room_number"`  // 房间号（同一酒店内唯一）
+	RoomType           string    `gorm:"not null;size:50;index" json:"room_type"`                      // 房间类型（有索引）
+	Floor              int       `gorm:"not null" json:"floor"`                                        // 楼层
+	Price              float64   `gorm:"not null;type:decimal(10,2)" json:"price"`                     // 价格（每晚）
+	OriginalPrice      float64   `gorm:"type:decimal(10,2)" json:"original_price"`                     // 原价
+	Capacity           int       `gorm:"not null" json:"capacity"`                                     // 可住人数
+	Area               float64   `gorm:"type:decimal(10,2)" json:"area"`                               // 面积（平方米）
+	BedType            string    `gorm:"size:50" json:"bed_type"`                                      // 床型：单人床、双人床、大床
+	Description        string    `gorm:"type:text" json:"description"`                                 // 房间描述
+	Facilities         string    `gorm:"type:text" json:"facilities"`                                  // 设施（JSON 字符串，已废弃，请使用 Amenities）
+	Images             string    `gorm:"type:text" json:"images"`                                      // 图片 URL（JSON 数组，由图库自动同步，请使用 Photos）
+	Left               int       `gorm:"not null" json:"left"`                                         // 左边界
+	Top                int       `gorm:"not null" json:"top"`                                          // 上边界
+	Width              int       `gorm:"not null" json:"width"`                                        // 宽度
+	Height             int       `gorm:"not null" json:"height"`                                       // 高度
+	HourlyEnabled      bool      `gorm:"not null;default:false;index" json:"hourly_enabled"`           // 是否开放钟点房
+	HourlyPrice        float64   `gorm:"type:decimal(10,2)" json:"hourly_price"`                       // 钟点房价格（每小时）
+	HourlyMinHours     int       `gorm:"not null;default:0" json:"hourly_min_hours"`                   // 钟点房最少预订小时数
+	Status             string    `gorm:"default:'available';size:20;index" json:"status"`              // 状态：available, occupied, maintenance
+	HousekeepingStatus string    `gorm:"default:'inspected';size:20;index" json:"housekeeping_status"` // 清洁状态：dirty, cleaning, clean, inspected
+	CreatedAt          time.Time `json:"created_at"`                                                   // 创建时间
+	UpdatedAt          time.Time `json:"updated_at"`                                                   // 更新时间
+
+	// 关联查询（可选）
+	Amenities []Amenity   `gorm:"many2many:room_amenities" json:"amenities,omitempty"` // 房间设施
+	Photos    []RoomPhoto `gorm:"foreignKey:RoomID" json:"photos,omitempty"`           // 房间图库
 }
 
 // TableName 指定表名
@@ -34,10 +43,16 @@ func (Room) TableName() string {
 }
 
 // IsAvailable 判断房间是否可用
+// 清洁状态只影响当天入住（见 IsInspected），不影响之后日期的预订
 func (r *Room) IsAvailable() bool {
 	return r.Status == "available"
 }
 
+// IsInspected 判断房间是否已清洁并通过查房，当天入住和办理入住时要求已查房
+func (r *Room) IsInspected() bool {
+	return r.HousekeepingStatus == "inspected"
+}
+
 // GetDiscountRate 获取折扣率
 func (r *Room) GetDiscountRate() float64 {
 	if r.OriginalPrice == 0 {
@@ -46,3 +61,7 @@ func (r *Room) GetDiscountRate() float64 {
 	return (r.OriginalPrice - r.Price) / r.OriginalPrice * 100
 }
 
+// IsHourlyAvailable 判断房间是否可以按钟点房预订
+func (r *Room) IsHourlyAvailable() bool {
+	return r.HourlyEnabled && r.HourlyPrice > 0 && r.IsAvailable()
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// RoomPhoto 房间图片模型
+// 对应数据库中的 room_photos 表
+type RoomPhoto struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`          // 主键
+	RoomID    uint      `gorm:"not null;index" json:"room_id"` // 房间 ID
+	URL       string    `gorm:"not null;size:500" json:"url"`  // 图片 URL（已确认的正式文件）
+	Caption   string    `gorm:"size:255" json:"caption"`       // 图片说明
+	Sort      int       `gorm:"default:0" json:"sort"`         // 排序，数字越小越靠前
+	IsCover   bool      `gorm:"default:false" json:"is_cover"` // 是否为封面
+	CreatedAt time.Time `json:"created_at"`                    // 创建时间
+	UpdatedAt time.Time `json:"updated_at"`                    // 更新时间
+}
+
+// TableName 指定表名
+func (RoomPhoto) TableName() string {
+	return "room_photos"
+}
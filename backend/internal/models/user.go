@@ -3,10 +3,13 @@ package models
 import (
 	"gohotel/pkg/utils"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // User 用户模型
-// 对应数据库中的 users 表
+// 对应数据库中的 users 表；删除为软删除，预订等历史记录仍可关联到用户，
+// 回收站保留期满后清除个人信息（匿名化），此后不可恢复
 type User struct {
 	ID         utils.JSONInt64 `gorm:"primaryKey;autoIncrement:false" json:"id"` // 主键（使用雪花算法生成，JSON序列化为字符串）
 	Username   string    `gorm:"unique;not null;size:50" json:"username"`  // 用户名（唯一）
@@ -20,6 +23,8 @@ type User struct {
 	FirstLogin bool      `gorm:"default:false" json:"first_login"` // 是否首次登录
 	CreatedAt  time.Time `json:"created_at"`                       // 创建时间
 	UpdatedAt  time.Time `json:"updated_at"`                       // 更新时间
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"` // 删除时间，不为空表示已删除（在回收站中）
+	AnonymizedAt *time.Time     `json:"anonymized_at,omitempty"`          // 匿名化时间，不为空表示个人信息已清除
 }
 
 // TableName 指定表名
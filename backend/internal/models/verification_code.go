@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// 验证码发送渠道
+const (
+	VerifyChannelSMS   = "sms"   // 短信
+	VerifyChannelEmail = "email" // 邮件
+)
+
+// 验证码用途
+const (
+	VerifyPurposeRegister    = "register"     // 注册时验证手机号或邮箱
+	VerifyPurposeLogin       = "login"        // 免密码登录
+	VerifyPurposeChangePhone = "change_phone" // 修改手机号时验证新号码
+)
+
+// VerificationCode 短信/邮件验证码
+// 对应数据库中的 verification_codes 表，只保存验证码的 SHA-256 摘要；
+// 发送记录同时用于按目标和按 IP 的频率限制
+type VerificationCode struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`                                    // 主键
+	Channel   string     `gorm:"not null;size:10" json:"channel"`                         // 渠道：sms, email
+	Target    string     `gorm:"not null;size:100;index:idx_verify_target" json:"target"` // 手机号或邮箱
+	Purpose   string     `gorm:"not null;size:20;index:idx_verify_target" json:"purpose"` // 用途：register, login, change_phone
+	CodeHash  string     `gorm:"not null;size:64" json:"-"`                               // 验证码摘要
+	IP        string     `gorm:"size:45;index" json:"ip"`                                 // 请求发送的客户端 IP
+	Attempts  int        `gorm:"not null;default:0" json:"attempts"`                      // 已校验失败次数
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`                              // 过期时间
+	UsedAt    *time.Time `json:"used_at"`                                                 // 校验通过时间，为空表示未使用
+	CreatedAt time.Time  `gorm:"index" json:"created_at"`                                 // 发送时间
+}
+
+// TableName 指定表名
+func (VerificationCode) TableName() string {
+	return "verification_codes"
+}
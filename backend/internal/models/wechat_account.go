@@ -0,0 +1,26 @@
+package models
+
+import (
+	"gohotel/pkg/utils"
+	"time"
+)
+
+// WechatAccount 微信账号绑定
+// 对应数据库中的 wechat_accounts 表，一个用户在每个小程序下最多绑定一个 openid，
+// 同一开放平台下的多个小程序通过 unionid 关联到同一用户；
+// session_key 每次微信登录时更新，用于解密手机号等加密数据，不返回给前端
+type WechatAccount struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`                                           // 主键
+	UserID     utils.JSONInt64 `gorm:"not null;uniqueIndex:idx_wechat_user_app" json:"user_id"`        // 用户 ID
+	AppID      string          `gorm:"not null;size:64;uniqueIndex:idx_wechat_user_app" json:"app_id"` // 小程序 AppID
+	OpenID     string          `gorm:"not null;size:64;uniqueIndex" json:"open_id"`                    // 用户在小程序下的唯一标识
+	UnionID    string          `gorm:"size:64;index" json:"union_id"`                                  // 用户在开放平台下的唯一标识，可为空
+	SessionKey string          `gorm:"size:64" json:"-"`                                               // 会话密钥
+	CreatedAt  time.Time       `json:"created_at"`                                                     // 绑定时间
+	UpdatedAt  time.Time       `json:"updated_at"`                                                     // 最近登录时间
+}
+
+// TableName 指定表名
+func (WechatAccount) TableName() string {
+	return "wechat_accounts"
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"gohotel/pkg/utils"
+	"time"
+)
+
+// WorkOrder 维修工单模型
+// 对应数据库中的 work_orders 表
+type WorkOrder struct {
+	ID              uint             `gorm:"primaryKey" json:"id"`                       // 主键
+	RoomID          uint             `gorm:"not null;index" json:"room_id"`              // 房间 ID
+	Title           string           `gorm:"not null;size:100" json:"title"`             // 标题
+	Description     string           `gorm:"type:text" json:"description"`               // 问题描述
+	Category        string           `gorm:"not null;size:20;index" json:"category"`     // 类别：plumbing, electrical, hvac, furniture, appliance, other
+	Priority        string           `gorm:"default:'medium';size:20" json:"priority"`   // 优先级：low, medium, high, urgent
+	Status          string           `gorm:"default:'open';size:20;index" json:"status"` // 状态：open, in_progress, resolved, cancelled
+	ReporterID      utils.JSONInt64  `gorm:"not null" json:"reporter_id"`                // 报修人 ID
+	AssigneeID      *utils.JSONInt64 `gorm:"index" json:"assignee_id"`                   // 维修人员 ID
+	Photos          string           `gorm:"type:text" json:"photos"`                    // 现场照片 URL（JSON 数组）
+	OutOfOrderStart *time.Time       `gorm:"index" json:"out_of_order_start"`            // 停用开始日期（包含）
+	OutOfOrderEnd   *time.Time       `gorm:"index" json:"out_of_order_end"`              // 停用结束日期（不包含）
+	ResolvedAt      *time.Time       `json:"resolved_at"`                                // 完成时间
+	CreatedAt       time.Time        `json:"created_at"`                                 // 创建时间
+	UpdatedAt       time.Time        `json:"updated_at"`                                 // 更新时间
+
+	// 关联查询（可选）
+	Room     Room  `gorm:"foreignKey:RoomID" json:"room,omitempty"`         // 关联的房间
+	Assignee *User `gorm:"foreignKey:AssigneeID" json:"assignee,omitempty"` // 维修人员
+}
+
+// TableName 指定表名
+func (WorkOrder) TableName() string {
+	return "work_orders"
+}
+
+// IsActive 判断工单是否仍在处理中
+// 只有处理中的工单会占用停用时段
+func (w *WorkOrder) IsActive() bool {
+	return w.Status == "open" || w.Status == "in_progress"
+}
+
+// HasOutOfOrder 判断工单是否设置了停用时段
+func (w *WorkOrder) HasOutOfOrder() bool {
+	return w.OutOfOrderStart != nil && w.OutOfOrderEnd != nil
+}
+
+// BlocksDate 判断工单是否使房间在指定日期（当晚）不可售
+func (w *WorkOrder) BlocksDate(date time.Time) bool {
+	if !w.IsActive() || !w.HasOutOfOrder() {
+		return false
+	}
+	return !date.Before(*w.OutOfOrderStart) && date.Before(*w.OutOfOrderEnd)
+}
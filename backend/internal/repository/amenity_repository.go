@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"gohotel/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// AmenityRepository 房间设施目录数据访问层
+type AmenityRepository struct {
+	db *gorm.DB
+}
+
+// NewAmenityRepository 创建设施目录仓库实例
+func NewAmenityRepository(db *gorm.DB) *AmenityRepository {
+	return &AmenityRepository{db: db}
+}
+
+// Create 创建设施
+func (r *AmenityRepository) Create(amenity *models.Amenity) error {
+	return r.db.Create(amenity).Error
+}
+
+// FindByID 根据 ID 查找设施
+func (r *AmenityRepository) FindByID(id uint) (*models.Amenity, error) {
+	var amenity models.Amenity
+	err := r.db.First(&amenity, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &amenity, nil
+}
+
+// FindByIDs 根据 ID 列表查找设施
+func (r *AmenityRepository) FindByIDs(ids []uint) ([]models.Amenity, error) {
+	var amenities []models.Amenity
+	err := r.db.Where("id IN ?", ids).Find(&amenities).Error
+	return amenities, err
+}
+
+// FindByName 根据名称查找设施
+func (r *AmenityRepository) FindByName(name string) (*models.Amenity, error) {
+	var amenity models.Amenity
+	err := r.db.Where("name = ?", name).First(&amenity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &amenity, nil
+}
+
+// ExistsByName 检查设施名称是否已存在
+func (r *AmenityRepository) ExistsByName(name string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Amenity{}).Where("name = ?", name).Count(&count).Error
+	return count > 0, err
+}
+
+// FindAll 查询所有设施，category 为空时不过滤
+func (r *AmenityRepository) FindAll(category string) ([]models.Amenity, error) {
+	var amenities []models.Amenity
+	query := r.db.Model(&models.Amenity{})
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+	err := query.Order("sort, id").Find(&amenities).Error
+	return amenities, err
+}
+
+// Update 更新设施
+func (r *AmenityRepository) Update(amenity *models.Amenity) error {
+	return r.db.Save(amenity).Error
+}
+
+// Delete 删除设施，同时删除与房间的关联
+func (r *AmenityRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM room_amenities WHERE amenity_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Amenity{}, id).Error
+	})
+}
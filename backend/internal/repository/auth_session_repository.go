@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"gohotel/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuthSessionRepository 登录会话与刷新令牌数据访问层
+type AuthSessionRepository struct {
+	db *gorm.DB
+}
+
+// NewAuthSessionRepository 创建登录会话仓库实例
+func NewAuthSessionRepository(db *gorm.DB) *AuthSessionRepository {
+	return &AuthSessionRepository{db: db}
+}
+
+// CreateWithToken 在一个事务中创建会话及其第一个刷新令牌
+func (r *AuthSessionRepository) CreateWithToken(session *models.AuthSession, token *models.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// FindByID 根据 ID 查找会话
+func (r *AuthSessionRepository) FindByID(id int64) (*models.AuthSession, error) {
+	var session models.AuthSession
+	err := r.db.First(&session, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// FindActiveByUserID 查询用户未撤销且未过期的会话，按最近活跃时间倒序
+func (r *AuthSessionRepository) FindActiveByUserID(userID int64, now time.Time) ([]models.AuthSession, error) {
+	var sessions []models.AuthSession
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_seen_at DESC, id DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// FindTokenByHash 根据摘要查找刷新令牌
+func (r *AuthSessionRepository) FindTokenByHash(hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RotateToken 在一个事务中将旧刷新令牌标记为已使用、签发新令牌并顺延会话有效期
+// 旧令牌已被并发使用时返回 gorm.ErrRecordNotFound
+func (r *AuthSessionRepository) RotateToken(old *models.RefreshToken, next *models.RefreshToken, now time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", old.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.AuthSession{}).
+			Where("id = ?", next.SessionID).
+			Update("expires_at", next.ExpiresAt).Error
+	})
+}
+
+// UpdateClient 更新会话的客户端信息和最近活跃时间，用于刷新令牌
+func (r *AuthSessionRepository) UpdateClient(id int64, ip, userAgent, device string, now time.Time) error {
+	return r.db.Model(&models.AuthSession{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"ip": ip, "user_agent": userAgent, "device": device, "last_seen_at": now}).Error
+}
+
+// Touch 更新会话的最近活跃时间
+func (r *AuthSessionRepository) Touch(id int64, now time.Time) error {
+	return r.db.Model(&models.AuthSession{}).
+		Where("id = ?", id).
+		UpdateColumn("last_seen_at", now).Error
+}
+
+// Revoke 撤销会话
+func (r *AuthSessionRepository) Revoke(id int64, now time.Time) error {
+	return r.db.Model(&models.AuthSession{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", now).Error
+}
+
+// RevokeByUserID 撤销用户的全部会话
+func (r *AuthSessionRepository) RevokeByUserID(userID int64, now time.Time) error {
+	return r.db.Model(&models.AuthSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", now).Error
+}
+
+// RevokeOthers 撤销用户除 keepID 以外的全部会话
+func (r *AuthSessionRepository) RevokeOthers(userID, keepID int64, now time.Time) error {
+	return r.db.Model(&models.AuthSession{}).
+		Where("user_id = ? AND id != ? AND revoked_at IS NULL", userID, keepID).
+		Update("revoked_at", now).Error
+}
+
+// DeleteExpired 删除在 before 之前过期或撤销的会话及其刷新令牌，返回删除的会话数量
+func (r *AuthSessionRepository) DeleteExpired(before time.Time) (int64, error) {
+	var deleted int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		stale := tx.Model(&models.AuthSession{}).
+			Select("id").
+			Where("expires_at < ? OR revoked_at < ?", before, before)
+		if err := tx.Where("session_id IN (?)", stale).Delete(&models.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("expires_at < ? OR revoked_at < ?", before, before).Delete(&models.AuthSession{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
@@ -32,11 +32,11 @@ func (r *BannerRepository) FindByID(id int64) (*models.Banner, error) {
 }
 
 // FindAll 查找所有活动横幅（带分页）
-func (r *BannerRepository) FindAll(page, pageSize int) ([]models.Banner, int64, error) {
+func (r *BannerRepository) FindAll(hotelID uint, page, pageSize int) ([]models.Banner, int64, error) {
 	var banners []models.Banner
 	var total int64
 
-	query := r.db.Model(&models.Banner{})
+	query := r.db.Model(&models.Banner{}).Where("hotel_id = ?", hotelID)
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -49,9 +49,9 @@ func (r *BannerRepository) FindAll(page, pageSize int) ([]models.Banner, int64,
 }
 
 // FindActive 查找激活的活动横幅（前端展示用）
-func (r *BannerRepository) FindActive() ([]models.Banner, error) {
+func (r *BannerRepository) FindActive(hotelID uint) ([]models.Banner, error) {
 	var banners []models.Banner
-	err := r.db.Where("status = ?", "active").
+	err := r.db.Where("hotel_id = ? AND status = ?", hotelID, "active").
 		Order("sort ASC, created_at DESC").Find(&banners).Error
 	return banners, err
 }
@@ -22,10 +22,12 @@ func (r *BookingRepository) Create(booking *models.Booking) error {
 	return r.db.Create(booking).Error
 }
 
-// FindByID 根据 ID 查找预订（包含关联的用户和房间信息）
+// FindByID 根据 ID 查找预订（包含关联的用户、房间信息和分期账单）
 func (r *BookingRepository) FindByID(id int64) (*models.Booking, error) {
 	var booking models.Booking
-	err := r.db.Preload("User").Preload("Room").First(&booking, id).Error
+	err := r.db.Preload("User", withDeletedUser).Preload("Room").
+		Preload("Installments", func(db *gorm.DB) *gorm.DB { return db.Order("seq") }).
+		First(&booking, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -35,7 +37,7 @@ func (r *BookingRepository) FindByID(id int64) (*models.Booking, error) {
 // FindByBookingNumber 根据订单号查找预订
 func (r *BookingRepository) FindByBookingNumber(bookingNumber string) (*models.Booking, error) {
 	var booking models.Booking
-	err := r.db.Preload("User").Preload("Room").
+	err := r.db.Preload("User", withDeletedUser).Preload("Room").
 		Where("booking_number = ?", bookingNumber).First(&booking).Error
 	if err != nil {
 		return nil, err
@@ -69,35 +71,35 @@ func (r *BookingRepository) FindByRoomID(roomID int64) ([]models.Booking, error)
 	return bookings, err
 }
 
-// FindByRoomNumberAndStatus 根据房间号和状态查找预订列表
-func (r *BookingRepository) FindByRoomNumberAndStatus(roomNumber string, status string) ([]models.Booking, error) {
+// FindByRoomNumberAndStatus 根据房间号和状态查找酒店的预订列表
+func (r *BookingRepository) FindByRoomNumberAndStatus(hotelID uint, roomNumber string, status string) ([]models.Booking, error) {
 	var bookings []models.Booking
 	query := r.db.Model(&models.Booking{}).
 		Joins("JOIN rooms ON rooms.id = bookings.room_id").
-		Where("rooms.room_number = ?", roomNumber)
+		Where("bookings.hotel_id = ? AND rooms.room_number = ?", hotelID, roomNumber)
 
 	// 根据状态参数过滤
 	if status != "" {
 		query = query.Where("bookings.status = ?", status)
 	}
 
-	err := query.Preload("User").Preload("Room").
+	err := query.Preload("User", withDeletedUser).Preload("Room").
 		Order("bookings.created_at DESC").Find(&bookings).Error
 
 	return bookings, err
 }
 
-// FindAll 查询所有预订（分页）
-func (r *BookingRepository) FindAll(page, pageSize int) ([]models.Booking, int64, error) {
+// FindAll 查询酒店的所有预订（分页）
+func (r *BookingRepository) FindAll(hotelID uint, page, pageSize int) ([]models.Booking, int64, error) {
 	var bookings []models.Booking
 	var total int64
 
-	if err := r.db.Model(&models.Booking{}).Count(&total).Error; err != nil {
+	if err := r.db.Model(&models.Booking{}).Where("hotel_id = ?", hotelID).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	offset := (page - 1) * pageSize
-	err := r.db.Preload("User").Preload("Room").
+	err := r.db.Where("hotel_id = ?", hotelID).Preload("User", withDeletedUser).Preload("Room").
 		Offset(offset).Limit(pageSize).
 		Order("created_at DESC").Find(&bookings).Error
 	return bookings, total, err
@@ -123,35 +125,12 @@ func (r *BookingRepository) Delete(id int64) error {
 	return r.db.Delete(&models.Booking{}, id).Error
 }
 
-// CheckRoomAvailability 检查房间在指定日期范围内是否可用
-// 如果有冲突的预订，返回 false
-func (r *BookingRepository) CheckRoomAvailability(roomID int64, checkIn, checkOut time.Time) (bool, error) {
-	var count int64
-
-	// 查询是否有冲突的预订
-	// 冲突条件：
-	// 1. 同一房间
-	// 2. 预订未取消
-	// 3. 日期有重叠
-	err := r.db.Model(&models.Booking{}).
-		Where("room_id = ?", roomID).
-		Where("status IN ?", []string{"pending", "confirmed", "checkin"}).
-		Where("(check_in < ? AND check_out > ?)", checkOut, checkIn).
-		Count(&count).Error
-
-	if err != nil {
-		return false, err
-	}
-
-	return count == 0, nil
-}
-
-// FindByDateRange 根据日期范围查询预订（分页）
-func (r *BookingRepository) FindByDateRange(startDate, endDate time.Time, page, pageSize int) ([]models.Booking, int64, error) {
+// FindByDateRange 根据日期范围查询酒店的预订（分页）
+func (r *BookingRepository) FindByDateRange(hotelID uint, startDate, endDate time.Time, page, pageSize int) ([]models.Booking, int64, error) {
 	var bookings []models.Booking
 	var total int64
 
-	query := r.db.Model(&models.Booking{}).
+	query := r.db.Model(&models.Booking{}).Where("hotel_id = ?", hotelID).
 		Where("check_in >= ? AND check_out <= ?", startDate, endDate)
 
 	if err := query.Count(&total).Error; err != nil {
@@ -159,34 +138,34 @@ func (r *BookingRepository) FindByDateRange(startDate, endDate time.Time, page,
 	}
 
 	offset := (page - 1) * pageSize
-	err := query.Preload("User").Preload("Room").
+	err := query.Preload("User", withDeletedUser).Preload("Room").
 		Offset(offset).Limit(pageSize).
 		Order("check_in").Find(&bookings).Error
 	return bookings, total, err
 }
 
-// FindByStatus 根据状态查询预订（分页）
-func (r *BookingRepository) FindByStatus(status string, page, pageSize int) ([]models.Booking, int64, error) {
+// FindByStatus 根据状态查询酒店的预订（分页）
+func (r *BookingRepository) FindByStatus(hotelID uint, status string, page, pageSize int) ([]models.Booking, int64, error) {
 	var bookings []models.Booking
 	var total int64
 
-	query := r.db.Model(&models.Booking{}).Where("status = ?", status)
+	query := r.db.Model(&models.Booking{}).Where("hotel_id = ? AND status = ?", hotelID, status)
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	offset := (page - 1) * pageSize
-	err := query.Preload("User").Preload("Room").
+	err := query.Preload("User", withDeletedUser).Preload("Room").
 		Offset(offset).Limit(pageSize).
 		Order("created_at DESC").Find(&bookings).Error
 	return bookings, total, err
 }
 
-// FindByGuestInfo 通过客人姓名、手机号和状态查询预订
-func (r *BookingRepository) FindByGuestInfo(guestName, guestPhone, status string) ([]models.Booking, error) {
+// FindByGuestInfo 通过客人姓名、手机号和状态查询酒店的预订
+func (r *BookingRepository) FindByGuestInfo(hotelID uint, guestName, guestPhone, status string) ([]models.Booking, error) {
 	var bookings []models.Booking
-	query := r.db.Model(&models.Booking{})
+	query := r.db.Model(&models.Booking{}).Where("hotel_id = ?", hotelID)
 
 	if guestName != "" {
 		query = query.Where("guest_name LIKE ?", "%"+guestName+"%")
@@ -201,7 +180,7 @@ func (r *BookingRepository) FindByGuestInfo(guestName, guestPhone, status string
 		query = query.Where("status = ?", status)
 	}
 
-	err := query.Preload("User").Preload("Room").
+	err := query.Preload("User", withDeletedUser).Preload("Room").
 		Order("created_at DESC").Find(&bookings).Error
 	if err != nil {
 		return nil, err
@@ -209,3 +188,38 @@ func (r *BookingRepository) FindByGuestInfo(guestName, guestPhone, status string
 
 	return bookings, nil
 }
+
+// FindUserIDsWithActiveBookings 查询有未完成预订（待确认、已确认、已入住）的用户 ID
+func (r *BookingRepository) FindUserIDsWithActiveBookings(userIDs []int64) ([]int64, error) {
+	var ids []int64
+	if len(userIDs) == 0 {
+		return ids, nil
+	}
+	err := r.db.Model(&models.Booking{}).
+		Distinct("user_id").
+		Where("user_id IN ? AND status IN ?", userIDs, []string{"pending", "confirmed", "checkin"}).
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+// FindActiveByRoomsAndDateRange 查询指定房间在日期范围内的有效预订（用于房态日历）
+// 钟点房的入住、退房日期相同，按实际开始、结束时间判断是否重叠；roomIDs 为空时查询所有房间
+func (r *BookingRepository) FindActiveByRoomsAndDateRange(roomIDs []int64, startDate, endDate time.Time) ([]models.Booking, error) {
+	var bookings []models.Booking
+	query := r.db.Model(&models.Booking{}).
+		Where("status IN ?", []string{"pending", "confirmed", "checkin"}).
+		Where("((booking_type = ? AND start_time < ? AND end_time > ?) OR (booking_type <> ? AND check_in < ? AND check_out > ?))",
+			models.BookingTypeHourly, endDate, startDate, models.BookingTypeHourly, endDate, startDate)
+
+	if len(roomIDs) > 0 {
+		query = query.Where("room_id IN ?", roomIDs)
+	}
+
+	err := query.Order("check_in").Find(&bookings).Error
+	return bookings, err
+}
+
+// withDeletedUser 预加载预订的用户时包含已删除的用户，保证历史预订仍能显示下单人
+func withDeletedUser(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
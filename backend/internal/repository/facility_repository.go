@@ -41,41 +41,30 @@ func (r *FacilityRepository) Delete(id uint) error {
 	return r.db.Delete(&models.Facility{}, id).Error
 }
 
-// FindAll 查询所有设施（分页）
-func (r *FacilityRepository) FindAll(page, pageSize int) ([]models.Facility, int64, error) {
+// FindAll 查询酒店的所有设施（分页）
+func (r *FacilityRepository) FindAll(hotelID uint, page, pageSize int) ([]models.Facility, int64, error) {
 	var facilities []models.Facility
 	var total int64
 
-	if err := r.db.Model(&models.Facility{}).Count(&total).Error; err != nil {
+	if err := r.db.Model(&models.Facility{}).Where("hotel_id = ?", hotelID).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	offset := (page - 1) * pageSize
-	err := r.db.Offset(offset).Limit(pageSize).Order("floor, type").Find(&facilities).Error
+	err := r.db.Where("hotel_id = ?", hotelID).Offset(offset).Limit(pageSize).Order("floor, type").Find(&facilities).Error
 	return facilities, total, err
 }
 
-// FindByFloor 按楼层查询设施
-func (r *FacilityRepository) FindByFloor(floor int) ([]models.Facility, error) {
+// FindByIDs 根据 ID 列表查找酒店内的设施
+func (r *FacilityRepository) FindByIDs(hotelID uint, ids []uint) ([]models.Facility, error) {
 	var facilities []models.Facility
-	err := r.db.Where("floor = ?", floor).Order("type").Find(&facilities).Error
+	err := r.db.Where("hotel_id = ? AND id IN ?", hotelID, ids).Find(&facilities).Error
 	return facilities, err
 }
 
-// BatchUpdate 批量更新设施位置
-func (r *FacilityRepository) BatchUpdate(facilities []models.Facility) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
-		for _, f := range facilities {
-			if err := tx.Model(&models.Facility{}).Where("id = ?", f.ID).Updates(map[string]interface{}{
-				"left":     f.Left,
-				"top":      f.Top,
-				"width":    f.Width,
-				"height":   f.Height,
-				"rotation": f.Rotation,
-			}).Error; err != nil {
-				return err
-			}
-		}
-		return nil
-	})
+// FindByFloor 按楼层查询酒店内的设施
+func (r *FacilityRepository) FindByFloor(hotelID uint, floor int) ([]models.Facility, error) {
+	var facilities []models.Facility
+	err := r.db.Where("hotel_id = ? AND floor = ?", hotelID, floor).Order("type").Find(&facilities).Error
+	return facilities, err
 }
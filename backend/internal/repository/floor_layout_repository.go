@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"gohotel/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// FloorLayoutRepository 楼层布局版本数据访问层
+type FloorLayoutRepository struct {
+	db *gorm.DB
+}
+
+// NewFloorLayoutRepository 创建楼层布局版本仓库实例
+func NewFloorLayoutRepository(db *gorm.DB) *FloorLayoutRepository {
+	return &FloorLayoutRepository{db: db}
+}
+
+// Create 创建布局版本
+func (r *FloorLayoutRepository) Create(version *models.FloorLayoutVersion) error {
+	return r.db.Create(version).Error
+}
+
+// Update 更新布局版本
+func (r *FloorLayoutRepository) Update(version *models.FloorLayoutVersion) error {
+	return r.db.Save(version).Error
+}
+
+// FindByID 根据 ID 查找布局版本
+func (r *FloorLayoutRepository) FindByID(id uint) (*models.FloorLayoutVersion, error) {
+	var version models.FloorLayoutVersion
+	err := r.db.First(&version, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// FindDraft 查找楼层的草稿版本
+func (r *FloorLayoutRepository) FindDraft(hotelID uint, floor int) (*models.FloorLayoutVersion, error) {
+	var version models.FloorLayoutVersion
+	err := r.db.Where("hotel_id = ? AND floor = ? AND status = ?", hotelID, floor, "draft").First(&version).Error
+	if err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// ExistsPublishedOrBaseline 检查楼层是否已有发布版本或基线版本（版本号 0）
+func (r *FloorLayoutRepository) ExistsPublishedOrBaseline(hotelID uint, floor int) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.FloorLayoutVersion{}).
+		Where("hotel_id = ? AND floor = ? AND (status = ? OR version = ?)", hotelID, floor, "published", 0).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// FindByFloor 查询楼层的所有布局版本（新版本在前）
+func (r *FloorLayoutRepository) FindByFloor(hotelID uint, floor int) ([]models.FloorLayoutVersion, error) {
+	var versions []models.FloorLayoutVersion
+	err := r.db.Where("hotel_id = ? AND floor = ?", hotelID, floor).Order("version DESC").Find(&versions).Error
+	return versions, err
+}
+
+// NextVersion 获取楼层的下一个版本号
+func (r *FloorLayoutRepository) NextVersion(hotelID uint, floor int) (int, error) {
+	var maxVersion *int
+	err := r.db.Model(&models.FloorLayoutVersion{}).
+		Where("hotel_id = ? AND floor = ?", hotelID, floor).
+		Select("MAX(version)").
+		Scan(&maxVersion).Error
+	if err != nil {
+		return 0, err
+	}
+	if maxVersion == nil {
+		return 1, nil
+	}
+	return *maxVersion + 1, nil
+}
+
+// SaveDrafts 在一个事务中保存多个楼层的草稿（新草稿会被创建）
+func (r *FloorLayoutRepository) SaveDrafts(drafts []*models.FloorLayoutVersion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, draft := range drafts {
+			if err := tx.Save(draft).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CreateRoomsWithDrafts 在一个事务中创建房间并保存楼层草稿
+// buildDrafts 在房间创建（已分配 ID）后调用，返回需要保存的草稿
+func (r *FloorLayoutRepository) CreateRoomsWithDrafts(rooms []*models.Room, buildDrafts func() ([]*models.FloorLayoutVersion, error)) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(rooms, 100).Error; err != nil {
+			return err
+		}
+		drafts, err := buildDrafts()
+		if err != nil {
+			return err
+		}
+		for _, draft := range drafts {
+			if err := tx.Save(draft).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Publish 发布布局版本
+// 在一个事务中写入房间和设施坐标，将楼层原发布版本归档，并保存当前版本（新版本会被创建）
+func (r *FloorLayoutRepository) Publish(version *models.FloorLayoutVersion, rooms []models.Room, facilities []models.Facility) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, room := range rooms {
+			if err := tx.Model(&models.Room{}).Where("id = ?", room.ID).Updates(map[string]interface{}{
+				"left":   room.Left,
+				"top":    room.Top,
+				"width":  room.Width,
+				"height": room.Height,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		for _, f := range facilities {
+			if err := tx.Model(&models.Facility{}).Where("id = ?", f.ID).Updates(map[string]interface{}{
+				"left":     f.Left,
+				"top":      f.Top,
+				"width":    f.Width,
+				"height":   f.Height,
+				"rotation": f.Rotation,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		err := tx.Model(&models.FloorLayoutVersion{}).
+			Where("hotel_id = ? AND floor = ? AND status = ? AND id <> ?", version.HotelID, version.Floor, "published", version.ID).
+			Update("status", "archived").Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Save(version).Error
+	})
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"gohotel/internal/models"
+	"gohotel/pkg/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// HotelRepository 酒店数据访问层
+type HotelRepository struct {
+	db *gorm.DB
+}
+
+// NewHotelRepository 创建酒店仓库实例
+func NewHotelRepository(db *gorm.DB) *HotelRepository {
+	return &HotelRepository{db: db}
+}
+
+// CreateWithStaff 创建酒店，并在同一事务中将创建人分配为该酒店员工
+func (r *HotelRepository) CreateWithStaff(hotel *models.Hotel, userID int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(hotel).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.HotelStaff{HotelID: hotel.ID, UserID: utils.JSONInt64(userID)}).Error
+	})
+}
+
+// FindByID 根据 ID 查找酒店
+func (r *HotelRepository) FindByID(id uint) (*models.Hotel, error) {
+	var hotel models.Hotel
+	err := r.db.First(&hotel, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &hotel, nil
+}
+
+// FindDefault 查找默认酒店（排序最靠前的营业中酒店）
+func (r *HotelRepository) FindDefault() (*models.Hotel, error) {
+	var hotel models.Hotel
+	err := r.db.Where("status = ?", "active").Order("sort, id").First(&hotel).Error
+	if err != nil {
+		return nil, err
+	}
+	return &hotel, nil
+}
+
+// FindActive 查询营业中的酒店
+// city 为空时不过滤
+func (r *HotelRepository) FindActive(city string) ([]models.Hotel, error) {
+	var hotels []models.Hotel
+	query := r.db.Where("status = ?", "active")
+	if city != "" {
+		query = query.Where("city = ?", city)
+	}
+	err := query.Order("sort, id").Find(&hotels).Error
+	return hotels, err
+}
+
+// FindByStaff 查询员工被分配的酒店
+func (r *HotelRepository) FindByStaff(userID int64) ([]models.Hotel, error) {
+	var hotels []models.Hotel
+	err := r.db.Joins("JOIN hotel_staff ON hotel_staff.hotel_id = hotels.id").
+		Where("hotel_staff.user_id = ?", userID).
+		Order("hotels.sort, hotels.id").
+		Find(&hotels).Error
+	return hotels, err
+}
+
+// FindStaffHotelIDs 查询员工被分配的酒店 ID 列表
+func (r *HotelRepository) FindStaffHotelIDs(userID int64) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.HotelStaff{}).
+		Where("user_id = ?", userID).
+		Order("hotel_id").
+		Pluck("hotel_id", &ids).Error
+	return ids, err
+}
+
+// ExistsByCode 检查酒店编码是否已存在
+func (r *HotelRepository) ExistsByCode(code string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Hotel{}).Where("code = ?", code).Count(&count).Error
+	return count > 0, err
+}
+
+// Update 更新酒店信息
+func (r *HotelRepository) Update(hotel *models.Hotel) error {
+	return r.db.Save(hotel).Error
+}
+
+// FindStaff 查询酒店的员工列表
+func (r *HotelRepository) FindStaff(hotelID uint) ([]models.HotelStaff, error) {
+	var staff []models.HotelStaff
+	err := r.db.Preload("User").
+		Where("hotel_id = ?", hotelID).
+		Order("created_at").
+		Find(&staff).Error
+	return staff, err
+}
+
+// CountStaff 统计酒店的员工数量
+func (r *HotelRepository) CountStaff(hotelID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.HotelStaff{}).Where("hotel_id = ?", hotelID).Count(&count).Error
+	return count, err
+}
+
+// AddStaff 将员工分配到酒店，已分配的员工会被忽略
+func (r *HotelRepository) AddStaff(hotelID uint, userIDs []int64) error {
+	staff := make([]models.HotelStaff, len(userIDs))
+	for i, id := range userIDs {
+		staff[i] = models.HotelStaff{HotelID: hotelID, UserID: utils.JSONInt64(id)}
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&staff).Error
+}
+
+// RemoveStaff 取消员工的酒店分配，返回是否有记录被删除
+func (r *HotelRepository) RemoveStaff(hotelID uint, userID int64) (bool, error) {
+	result := r.db.Where("hotel_id = ? AND user_id = ?", hotelID, userID).Delete(&models.HotelStaff{})
+	return result.RowsAffected > 0, result.Error
+}
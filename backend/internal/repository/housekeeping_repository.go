@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"gohotel/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// HousekeepingRepository 客房清洁任务数据访问层
+type HousekeepingRepository struct {
+	db *gorm.DB
+}
+
+// NewHousekeepingRepository 创建清洁任务仓库实例
+func NewHousekeepingRepository(db *gorm.DB) *HousekeepingRepository {
+	return &HousekeepingRepository{db: db}
+}
+
+// Create 创建清洁任务
+func (r *HousekeepingRepository) Create(task *models.HousekeepingTask) error {
+	return r.db.Create(task).Error
+}
+
+// FindByID 根据 ID 查找清洁任务（包含房间和保洁员信息）
+func (r *HousekeepingRepository) FindByID(id uint) (*models.HousekeepingTask, error) {
+	var task models.HousekeepingTask
+	err := r.db.Preload("Room").Preload("Assignee").First(&task, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// Update 更新清洁任务
+func (r *HousekeepingRepository) Update(task *models.HousekeepingTask) error {
+	return r.db.Omit("Room", "Assignee").Save(task).Error
+}
+
+// FindOpenByRoomID 查找房间尚未完成的清洁任务
+func (r *HousekeepingRepository) FindOpenByRoomID(roomID uint) (*models.HousekeepingTask, error) {
+	var task models.HousekeepingTask
+	err := r.db.Where("room_id = ? AND status <> ?", roomID, "inspected").
+		Order("created_at DESC").First(&task).Error
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// FindAll 根据条件查询酒店的清洁任务（分页）
+// status 为空、assigneeID 为 0、floor 为 0 时不过滤
+func (r *HousekeepingRepository) FindAll(hotelID uint, page, pageSize int, status string, assigneeID int64, floor int) ([]models.HousekeepingTask, int64, error) {
+	var tasks []models.HousekeepingTask
+	var total int64
+
+	query := r.db.Model(&models.HousekeepingTask{}).
+		Joins("JOIN rooms ON rooms.id = housekeeping_tasks.room_id").
+		Where("rooms.hotel_id = ?", hotelID)
+	if status != "" {
+		query = query.Where("housekeeping_tasks.status = ?", status)
+	}
+	if assigneeID != 0 {
+		query = query.Where("housekeeping_tasks.assignee_id = ?", assigneeID)
+	}
+	if floor != 0 {
+		query = query.Where("rooms.floor = ?", floor)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Preload("Room").Preload("Assignee").
+		Offset(offset).Limit(pageSize).
+		Order("housekeeping_tasks.created_at DESC").Find(&tasks).Error
+	return tasks, total, err
+}
+
+// FindOpenByAssignee 查询分配给指定员工且未完成的清洁任务
+func (r *HousekeepingRepository) FindOpenByAssignee(assigneeID int64) ([]models.HousekeepingTask, error) {
+	var tasks []models.HousekeepingTask
+	err := r.db.Preload("Room").
+		Where("assignee_id = ?", assigneeID).
+		Where("status IN ?", []string{"pending", "in_progress"}).
+		Order("created_at").Find(&tasks).Error
+	return tasks, err
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"gohotel/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InstallmentRepository 分期账单数据访问层
+type InstallmentRepository struct {
+	db *gorm.DB
+}
+
+// NewInstallmentRepository 创建分期账单仓库实例
+func NewInstallmentRepository(db *gorm.DB) *InstallmentRepository {
+	return &InstallmentRepository{db: db}
+}
+
+// FindByID 根据 ID 查找账单
+func (r *InstallmentRepository) FindByID(id uint) (*models.BookingInstallment, error) {
+	var installment models.BookingInstallment
+	err := r.db.First(&installment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &installment, nil
+}
+
+// FindByBookingID 查询预订的全部账单，按期数排序
+func (r *InstallmentRepository) FindByBookingID(bookingID int64) ([]models.BookingInstallment, error) {
+	var installments []models.BookingInstallment
+	err := r.db.Where("booking_id = ?", bookingID).Order("seq").Find(&installments).Error
+	return installments, err
+}
+
+// FindDue 查询到期日不晚于 date 且仍未出账的账单（预订未取消）
+func (r *InstallmentRepository) FindDue(date time.Time) ([]models.BookingInstallment, error) {
+	var installments []models.BookingInstallment
+	err := r.db.Model(&models.BookingInstallment{}).
+		Joins("JOIN bookings ON bookings.id = booking_installments.booking_id").
+		Where("booking_installments.status = ?", models.InstallmentScheduled).
+		Where("booking_installments.due_date <= ?", date).
+		Where("bookings.status IN ?", []string{"pending", "confirmed", "checkin"}).
+		Order("booking_installments.due_date, booking_installments.id").
+		Find(&installments).Error
+	return installments, err
+}
+
+// MarkBilled 将账单标记为已出账，只更新仍未出账的账单
+func (r *InstallmentRepository) MarkBilled(ids []uint, billedAt time.Time) (int64, error) {
+	result := r.db.Model(&models.BookingInstallment{}).
+		Where("id IN ? AND status = ?", ids, models.InstallmentScheduled).
+		Updates(map[string]interface{}{"status": models.InstallmentBilled, "billed_at": billedAt})
+	return result.RowsAffected, result.Error
+}
+
+// Update 更新账单
+func (r *InstallmentRepository) Update(installment *models.BookingInstallment) error {
+	return r.db.Save(installment).Error
+}
+
+// CancelUnpaid 作废预订中尚未支付的账单
+func (r *InstallmentRepository) CancelUnpaid(bookingID int64) error {
+	return r.db.Model(&models.BookingInstallment{}).
+		Where("booking_id = ?", bookingID).
+		Where("status IN ?", []string{models.InstallmentScheduled, models.InstallmentBilled}).
+		Update("status", models.InstallmentCancelled).Error
+}
+
+// CountUnpaid 统计预订中尚未支付的账单数量
+func (r *InstallmentRepository) CountUnpaid(bookingID int64) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.BookingInstallment{}).
+		Where("booking_id = ?", bookingID).
+		Where("status IN ?", []string{models.InstallmentScheduled, models.InstallmentBilled}).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"gohotel/internal/models"
+	"gohotel/pkg/utils"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginLockoutRepository 登录锁定记录数据访问层
+type LoginLockoutRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginLockoutRepository 创建登录锁定记录仓库实例
+func NewLoginLockoutRepository(db *gorm.DB) *LoginLockoutRepository {
+	return &LoginLockoutRepository{db: db}
+}
+
+// FindByUserID 查找账号的锁定记录
+func (r *LoginLockoutRepository) FindByUserID(userID int64) (*models.LoginLockout, error) {
+	var lockout models.LoginLockout
+	if err := r.db.Where("user_id = ?", userID).First(&lockout).Error; err != nil {
+		return nil, err
+	}
+	return &lockout, nil
+}
+
+// IncrementFailure 原子地累加账号的连续失败次数（记录不存在时创建），返回累加后的记录
+// 最近一次失败早于 windowStart 时从 1 重新计数；并发的失败请求在数据库中累加，不会互相覆盖
+func (r *LoginLockoutRepository) IncrementFailure(userID int64, now, windowStart time.Time) (*models.LoginLockout, error) {
+	err := r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.LoginLockout{UserID: utils.JSONInt64(userID)}).Error
+	if err != nil {
+		return nil, err
+	}
+	// MySQL 按书写顺序赋值，failed_count 须在 last_failed_at 之前更新才能读到旧的失败时间（map 的键按字母序生成 SET）
+	err = r.db.Model(&models.LoginLockout{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"failed_count":   gorm.Expr("CASE WHEN last_failed_at IS NULL OR last_failed_at < ? THEN 1 ELSE failed_count + 1 END", windowStart),
+			"last_failed_at": now,
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.FindByUserID(userID)
+}
+
+// Lock 连续失败次数达到 maxFailures 时锁定账号、累加锁定次数并清零失败次数
+// 返回是否由本次调用完成锁定，并发的失败请求只有一个会锁定成功
+func (r *LoginLockoutRepository) Lock(userID int64, maxFailures int, until time.Time) (bool, error) {
+	result := r.db.Model(&models.LoginLockout{}).
+		Where("user_id = ? AND failed_count >= ?", userID, maxFailures).
+		Updates(map[string]interface{}{
+			"failed_count": 0,
+			"lock_count":   gorm.Expr("lock_count + 1"),
+			"locked_until": until,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Delete 删除账号的锁定记录
+func (r *LoginLockoutRepository) Delete(userID int64) (int64, error) {
+	result := r.db.Where("user_id = ?", userID).Delete(&models.LoginLockout{})
+	return result.RowsAffected, result.Error
+}
+
+// FindLocked 查询当前处于锁定状态的记录，按锁定截止时间倒序
+func (r *LoginLockoutRepository) FindLocked(now time.Time) ([]models.LoginLockout, error) {
+	var lockouts []models.LoginLockout
+	err := r.db.Where("locked_until > ?", now).
+		Order("locked_until DESC").
+		Find(&lockouts).Error
+	return lockouts, err
+}
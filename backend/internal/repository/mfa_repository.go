@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"gohotel/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MFARepository 两步验证数据访问层
+type MFARepository struct {
+	db *gorm.DB
+}
+
+// NewMFARepository 创建两步验证仓库实例
+func NewMFARepository(db *gorm.DB) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+// FindTOTP 查找用户的 TOTP 设置
+func (r *MFARepository) FindTOTP(userID int64) (*models.UserTOTP, error) {
+	var totp models.UserTOTP
+	if err := r.db.Where("user_id = ?", userID).First(&totp).Error; err != nil {
+		return nil, err
+	}
+	return &totp, nil
+}
+
+// SaveTOTP 保存尚未启用的 TOTP 密钥，覆盖之前未完成的绑定
+// 用户已启用两步验证时返回 gorm.ErrDuplicatedKey
+func (r *MFARepository) SaveTOTP(totp *models.UserTOTP) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.UserTOTP
+		err := tx.Where("user_id = ?", totp.UserID).First(&existing).Error
+		if err == gorm.ErrRecordNotFound {
+			return tx.Create(totp).Error
+		}
+		if err != nil {
+			return err
+		}
+		if existing.IsEnabled() {
+			return gorm.ErrDuplicatedKey
+		}
+		return tx.Model(&existing).Updates(map[string]interface{}{"secret": totp.Secret, "last_step": 0}).Error
+	})
+}
+
+// Enable 在事务中启用 TOTP 并替换恢复码
+// step 为本次通过验证的时间步；绑定已启用或已被删除时返回 gorm.ErrRecordNotFound
+func (r *MFARepository) Enable(userID, step int64, now time.Time, codes []models.MFARecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.UserTOTP{}).
+			Where("user_id = ? AND enabled_at IS NULL", userID).
+			Updates(map[string]interface{}{"enabled_at": now, "last_step": step})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+// Disable 删除用户的 TOTP 设置和恢复码
+func (r *MFARepository) Disable(userID int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.UserTOTP{}).Error
+	})
+}
+
+// UseStep 记录通过验证的时间步，只有比上次更新的时间步才能写入，防止动态码被重复使用
+func (r *MFARepository) UseStep(userID, step int64) (bool, error) {
+	result := r.db.Model(&models.UserTOTP{}).
+		Where("user_id = ? AND last_step < ?", userID, step).
+		Update("last_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReplaceRecoveryCodes 删除用户的旧恢复码并保存新恢复码
+func (r *MFARepository) ReplaceRecoveryCodes(userID int64, codes []models.MFARecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+// UseRecoveryCode 将未使用的恢复码标记为已使用，恢复码不存在或已使用时返回 false
+func (r *MFARepository) UseRecoveryCode(userID int64, hash string, now time.Time) (bool, error) {
+	result := r.db.Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", now)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CountRecoveryCodes 统计用户未使用的恢复码数量
+func (r *MFARepository) CountRecoveryCodes(userID int64) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// CreateChallenge 保存登录的第二步验证
+func (r *MFARepository) CreateChallenge(challenge *models.MFAChallenge) error {
+	return r.db.Create(challenge).Error
+}
+
+// FindChallengeByHash 根据令牌摘要查找第二步验证
+func (r *MFARepository) FindChallengeByHash(hash string) (*models.MFAChallenge, error) {
+	var challenge models.MFAChallenge
+	if err := r.db.Where("token_hash = ?", hash).First(&challenge).Error; err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// IncrementChallengeAttempts 失败次数加一
+func (r *MFARepository) IncrementChallengeAttempts(id uint) error {
+	return r.db.Model(&models.MFAChallenge{}).
+		Where("id = ?", id).
+		UpdateColumn("attempts", gorm.Expr("attempts + 1")).Error
+}
+
+// CompleteChallenge 将第二步验证标记为已完成，已完成时返回 false，用于防止并发重复使用
+func (r *MFARepository) CompleteChallenge(id uint, now time.Time) (bool, error) {
+	result := r.db.Model(&models.MFAChallenge{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", now)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteExpiredChallenges 删除已过期的第二步验证，返回删除数量
+func (r *MFARepository) DeleteExpiredChallenges(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&models.MFAChallenge{})
+	return result.RowsAffected, result.Error
+}
+
+// replaceRecoveryCodes 在事务中替换用户的恢复码
+func replaceRecoveryCodes(tx *gorm.DB, userID int64, codes []models.MFARecoveryCode) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return tx.Create(&codes).Error
+}
@@ -28,11 +28,11 @@ func (r *NoticeRepository) FindByID(id int64) (*models.Notice, error) {
 }
 
 // FindAll 查找所有活动横幅 (带分页)
-func (r *NoticeRepository) FindAll(page, pageSize int) ([]models.Notice, int64, error) {
+func (r *NoticeRepository) FindAll(hotelID uint, page, pageSize int) ([]models.Notice, int64, error) {
 	var notices []models.Notice
 	var total int64
 
-	query := r.db.Model(&models.Notice{})
+	query := r.db.Model(&models.Notice{}).Where("hotel_id = ?", hotelID)
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -45,9 +45,9 @@ func (r *NoticeRepository) FindAll(page, pageSize int) ([]models.Notice, int64,
 }
 
 // FindActive 查找激活的活动横幅（前端展示用）
-func (r *NoticeRepository) FindActive() ([]models.Notice, error) {
+func (r *NoticeRepository) FindActive(hotelID uint) ([]models.Notice, error) {
 	var notices []models.Notice
-	err := r.db.Where("status = ?", "active").
+	err := r.db.Where("hotel_id = ? AND status = ?", hotelID, "active").
 		Order("sort ASC, created_at DESC").Find(&notices).Error
 	return notices, err
 }
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"gohotel/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PasswordResetRepository 设置密码令牌数据访问层
+type PasswordResetRepository struct {
+	db *gorm.DB
+}
+
+// NewPasswordResetRepository 创建设置密码令牌仓库实例
+func NewPasswordResetRepository(db *gorm.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+// Replace 作废用户未使用的令牌并保存新令牌
+func (r *PasswordResetRepository) Replace(token *models.PasswordResetToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", token.UserID).
+			Update("used_at", token.CreatedAt).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// FindByHash 根据令牌摘要查找
+func (r *PasswordResetRepository) FindByHash(hash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	if err := r.db.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// FindLatest 查找用户某用途最近签发的令牌
+func (r *PasswordResetRepository) FindLatest(userID int64, purpose string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	err := r.db.Where("user_id = ? AND purpose = ?", userID, purpose).
+		Order("created_at DESC, id DESC").
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// UseAndSetPassword 在事务中标记令牌已使用并更新用户密码，同时清除首次登录标记
+// 令牌已被使用时返回 gorm.ErrRecordNotFound
+func (r *PasswordResetRepository) UseAndSetPassword(token *models.PasswordResetToken, hashedPassword string, now time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(&models.User{}).
+			Where("id = ?", token.UserID).
+			Updates(map[string]interface{}{"password": hashedPassword, "first_login": false}).Error
+	})
+}
+
+// DeleteExpired 删除已过期的令牌，返回删除数量
+func (r *PasswordResetRepository) DeleteExpired(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&models.PasswordResetToken{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"gohotel/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PricingRepository 动态定价数据访问层（定价规则、房价日历、调价记录）
+type PricingRepository struct {
+	db *gorm.DB
+}
+
+// NewPricingRepository 创建定价仓库实例
+func NewPricingRepository(db *gorm.DB) *PricingRepository {
+	return &PricingRepository{db: db}
+}
+
+// RoomTypeStat 房型的房间数和参考价
+type RoomTypeStat struct {
+	RoomType   string
+	TotalRooms int
+	BasePrice  float64 // 房型内最低房价
+}
+
+// RoomTypeStay 房型的在住或已预订记录
+type RoomTypeStay struct {
+	RoomType string
+	CheckIn  time.Time
+	CheckOut time.Time
+}
+
+// CreateRule 创建定价规则
+func (r *PricingRepository) CreateRule(rule *models.PricingRule) error {
+	return r.db.Create(rule).Error
+}
+
+// FindRuleByID 根据 ID 查找定价规则
+func (r *PricingRepository) FindRuleByID(id uint) (*models.PricingRule, error) {
+	var rule models.PricingRule
+	err := r.db.First(&rule, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// FindRules 查询酒店的定价规则，enabledOnly 为 true 时只返回启用的规则
+func (r *PricingRepository) FindRules(hotelID uint, enabledOnly bool) ([]models.PricingRule, error) {
+	var rules []models.PricingRule
+	query := r.db.Where("hotel_id = ?", hotelID)
+	if enabledOnly {
+		query = query.Where("enabled = ?", true)
+	}
+	err := query.Order("min_occupancy DESC, id").Find(&rules).Error
+	return rules, err
+}
+
+// UpdateRule 更新定价规则
+func (r *PricingRepository) UpdateRule(rule *models.PricingRule) error {
+	return r.db.Save(rule).Error
+}
+
+// DeleteRule 删除定价规则
+func (r *PricingRepository) DeleteRule(id uint) error {
+	return r.db.Delete(&models.PricingRule{}, id).Error
+}
+
+// FindRates 查询酒店在 [startDate, endDate) 内的房价日历，roomType 为空时不过滤
+func (r *PricingRepository) FindRates(hotelID uint, roomType string, startDate, endDate time.Time) ([]models.RoomRate, error) {
+	var rates []models.RoomRate
+	query := r.db.Where("hotel_id = ? AND date >= ? AND date < ?", hotelID, startDate, endDate)
+	if roomType != "" {
+		query = query.Where("room_type = ?", roomType)
+	}
+	err := query.Order("date, room_type").Find(&rates).Error
+	return rates, err
+}
+
+// SaveRates 在一个事务中保存房价日历的变更和调价记录
+func (r *PricingRepository) SaveRates(rates []*models.RoomRate, logs []models.PriceChangeLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, rate := range rates {
+			if err := tx.Save(rate).Error; err != nil {
+				return err
+			}
+		}
+		if len(logs) > 0 {
+			if err := tx.CreateInBatches(logs, 100).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindChangeLogs 查询酒店的调价记录（分页，最新的在前），roomType 为空时不过滤
+func (r *PricingRepository) FindChangeLogs(hotelID uint, roomType string, page, pageSize int) ([]models.PriceChangeLog, int64, error) {
+	var logs []models.PriceChangeLog
+	var total int64
+
+	query := r.db.Model(&models.PriceChangeLog{}).Where("hotel_id = ?", hotelID)
+	if roomType != "" {
+		query = query.Where("room_type = ?", roomType)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&logs).Error
+	return logs, total, err
+}
+
+// FindRoomTypeStats 按房型统计酒店的房间数和最低房价
+func (r *PricingRepository) FindRoomTypeStats(hotelID uint) ([]RoomTypeStat, error) {
+	var stats []RoomTypeStat
+	err := r.db.Model(&models.Room{}).
+		Select("room_type, COUNT(*) AS total_rooms, MIN(price) AS base_price").
+		Where("hotel_id = ?", hotelID).
+		Group("room_type").
+		Order("room_type").
+		Scan(&stats).Error
+	return stats, err
+}
+
+// FindActiveStays 查询酒店在 [startDate, endDate) 内占用房间的预订（待确认、已确认、已入住）
+func (r *PricingRepository) FindActiveStays(hotelID uint, startDate, endDate time.Time) ([]RoomTypeStay, error) {
+	var stays []RoomTypeStay
+	err := r.db.Model(&models.Booking{}).
+		Select("rooms.room_type, bookings.check_in, bookings.check_out").
+		Joins("JOIN rooms ON rooms.id = bookings.room_id").
+		Where("bookings.hotel_id = ?", hotelID).
+		Where("bookings.status IN ?", []string{"pending", "confirmed", "checkin"}).
+		Where("bookings.check_in < ? AND bookings.check_out > ?", endDate, startDate).
+		Scan(&stays).Error
+	return stays, err
+}
+
+// CreateStayDiscount 创建连住折扣档位
+func (r *PricingRepository) CreateStayDiscount(discount *models.StayDiscount) error {
+	return r.db.Create(discount).Error
+}
+
+// FindStayDiscountByID 根据 ID 查找连住折扣档位
+func (r *PricingRepository) FindStayDiscountByID(id uint) (*models.StayDiscount, error) {
+	var discount models.StayDiscount
+	err := r.db.First(&discount, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &discount, nil
+}
+
+// FindStayDiscounts 查询酒店的连住折扣档位，enabledOnly 为 true 时只返回启用的档位
+func (r *PricingRepository) FindStayDiscounts(hotelID uint, enabledOnly bool) ([]models.StayDiscount, error) {
+	var discounts []models.StayDiscount
+	query := r.db.Where("hotel_id = ?", hotelID)
+	if enabledOnly {
+		query = query.Where("enabled = ?", true)
+	}
+	err := query.Order("min_nights DESC, id").Find(&discounts).Error
+	return discounts, err
+}
+
+// UpdateStayDiscount 更新连住折扣档位
+func (r *PricingRepository) UpdateStayDiscount(discount *models.StayDiscount) error {
+	return r.db.Save(discount).Error
+}
+
+// DeleteStayDiscount 删除连住折扣档位
+func (r *PricingRepository) DeleteStayDiscount(id uint) error {
+	return r.db.Delete(&models.StayDiscount{}, id).Error
+}
+
+// FindLongStayPolicies 查询酒店的长住政策
+func (r *PricingRepository) FindLongStayPolicies(hotelID uint) ([]models.LongStayPolicy, error) {
+	var policies []models.LongStayPolicy
+	err := r.db.Where("hotel_id = ?", hotelID).Order("room_type, id").Find(&policies).Error
+	return policies, err
+}
+
+// FindLongStayPolicy 查找酒店某房型的长住政策，roomType 为空时查找适用所有房型的政策
+func (r *PricingRepository) FindLongStayPolicy(hotelID uint, roomType string) (*models.LongStayPolicy, error) {
+	var policy models.LongStayPolicy
+	err := r.db.Where("hotel_id = ? AND room_type = ?", hotelID, roomType).First(&policy).Error
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// FindLongStayPolicyByID 根据 ID 查找长住政策
+func (r *PricingRepository) FindLongStayPolicyByID(id uint) (*models.LongStayPolicy, error) {
+	var policy models.LongStayPolicy
+	err := r.db.First(&policy, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// SaveLongStayPolicy 创建或更新长住政策
+func (r *PricingRepository) SaveLongStayPolicy(policy *models.LongStayPolicy) error {
+	return r.db.Save(policy).Error
+}
+
+// DeleteLongStayPolicy 删除长住政策
+func (r *PricingRepository) DeleteLongStayPolicy(id uint) error {
+	return r.db.Delete(&models.LongStayPolicy{}, id).Error
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"gohotel/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ReviewRepository 住客评价数据访问层
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+// NewReviewRepository 创建评价仓库实例
+func NewReviewRepository(db *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// Create 创建评价
+func (r *ReviewRepository) Create(review *models.Review) error {
+	return r.db.Create(review).Error
+}
+
+// FindByID 根据 ID 查找评价
+func (r *ReviewRepository) FindByID(id uint) (*models.Review, error) {
+	var review models.Review
+	err := r.db.First(&review, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// ExistsByBookingID 检查预订是否已评价
+func (r *ReviewRepository) ExistsByBookingID(bookingID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Review{}).Where("booking_id = ?", bookingID).Count(&count).Error
+	return count > 0, err
+}
+
+// Update 更新评价
+func (r *ReviewRepository) Update(review *models.Review) error {
+	return r.db.Save(review).Error
+}
+
+// FindAll 查询酒店的评价（分页）
+// status、roomType 为空、roomID 为 0 时不过滤
+func (r *ReviewRepository) FindAll(hotelID uint, page, pageSize int, status, roomType string, roomID int64) ([]models.Review, int64, error) {
+	var reviews []models.Review
+	var total int64
+
+	query := r.db.Model(&models.Review{}).Where("hotel_id = ?", hotelID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if roomType != "" {
+		query = query.Where("room_type = ?", roomType)
+	}
+	if roomID > 0 {
+		query = query.Where("room_id = ?", roomID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Offset(offset).Limit(pageSize).Order("created_at DESC").Find(&reviews).Error
+	return reviews, total, err
+}
+
+// FindByUserID 查询用户的评价（分页）
+func (r *ReviewRepository) FindByUserID(userID int64, page, pageSize int) ([]models.Review, int64, error) {
+	var reviews []models.Review
+	var total int64
+
+	query := r.db.Model(&models.Review{}).Where("user_id = ?", userID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Offset(offset).Limit(pageSize).Order("created_at DESC").Find(&reviews).Error
+	return reviews, total, err
+}
+
+// AggregateByRoomType 按房型汇总酒店公开评价的评分，roomType 为空时返回所有房型
+func (r *ReviewRepository) AggregateByRoomType(hotelID uint, roomType string) ([]models.RoomTypeRating, error) {
+	var ratings []models.RoomTypeRating
+	query := r.db.Model(&models.Review{}).
+		Select("room_type, COUNT(*) AS review_count, "+
+			"AVG(cleanliness) AS avg_cleanliness, AVG(service) AS avg_service, "+
+			"AVG(location) AS avg_location, AVG(overall) AS avg_overall").
+		Where("hotel_id = ? AND status = ?", hotelID, "visible")
+	if roomType != "" {
+		query = query.Where("room_type = ?", roomType)
+	}
+	err := query.Group("room_type").Order("room_type").Scan(&ratings).Error
+	return ratings, err
+}
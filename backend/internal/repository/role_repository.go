@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"gohotel/internal/models"
+	"gohotel/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+// RoleRepository 角色与权限数据访问层
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository 创建角色仓库实例
+func NewRoleRepository(db *gorm.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+// Create 创建角色（连同权限）
+func (r *RoleRepository) Create(role *models.Role) error {
+	return r.db.Create(role).Error
+}
+
+// FindByID 根据 ID 查找角色（包含权限）
+func (r *RoleRepository) FindByID(id uint) (*models.Role, error) {
+	var role models.Role
+	err := r.db.Preload("Permissions").First(&role, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// FindByCode 根据编码查找角色
+func (r *RoleRepository) FindByCode(code string) (*models.Role, error) {
+	var role models.Role
+	err := r.db.Where("code = ?", code).First(&role).Error
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// FindByIDs 根据 ID 列表查找角色
+func (r *RoleRepository) FindByIDs(ids []uint) ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.Where("id IN ?", ids).Find(&roles).Error
+	return roles, err
+}
+
+// FindAll 查询全部角色（包含权限）
+func (r *RoleRepository) FindAll() ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.Preload("Permissions").Order("id").Find(&roles).Error
+	return roles, err
+}
+
+// ExistsByCode 检查角色编码是否已存在
+func (r *RoleRepository) ExistsByCode(code string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Role{}).Where("code = ?", code).Count(&count).Error
+	return count > 0, err
+}
+
+// UpdateWithPermissions 在一个事务中更新角色信息并替换其权限
+func (r *RoleRepository) UpdateWithPermissions(role *models.Role, permissions []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(role).Select("name", "description", "require_mfa").Updates(role).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", role.ID).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		role.Permissions = make([]models.RolePermission, len(permissions))
+		for i, p := range permissions {
+			role.Permissions[i] = models.RolePermission{RoleID: role.ID, Permission: p}
+		}
+		if len(role.Permissions) > 0 {
+			return tx.Create(&role.Permissions).Error
+		}
+		return nil
+	})
+}
+
+// Delete 删除角色及其权限和用户分配
+func (r *RoleRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", id).Delete(&models.UserRole{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", id).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Role{}, id).Error
+	})
+}
+
+// FindUserRoles 查询用户的角色（包含权限）
+func (r *RoleRepository) FindUserRoles(userID int64) ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.Preload("Permissions").
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Order("roles.id").
+		Find(&roles).Error
+	return roles, err
+}
+
+// FindUserPermissions 查询用户通过角色获得的全部权限码
+func (r *RoleRepository) FindUserPermissions(userID int64) ([]string, error) {
+	var permissions []string
+	err := r.db.Model(&models.RolePermission{}).
+		Distinct("role_permissions.permission").
+		Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
+		Where("user_roles.user_id = ?", userID).
+		Pluck("role_permissions.permission", &permissions).Error
+	return permissions, err
+}
+
+// FindUserIDsWithPermission 查询通过角色拥有某权限的全部用户 ID，拥有全部权限（*）的用户也包含在内
+func (r *RoleRepository) FindUserIDsWithPermission(permission string) ([]int64, error) {
+	var userIDs []int64
+	err := r.db.Model(&models.UserRole{}).
+		Distinct("user_roles.user_id").
+		Joins("JOIN role_permissions ON role_permissions.role_id = user_roles.role_id").
+		Where("role_permissions.permission IN ?", []string{permission, models.PermissionAll}).
+		Pluck("user_roles.user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// UserRequiresMFA 判断用户是否拥有要求两步验证的角色
+func (r *RoleRepository) UserRequiresMFA(userID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.UserRole{}).
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("user_roles.user_id = ? AND roles.require_mfa = ?", userID, true).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// SetUserRoles 在一个事务中替换用户的角色
+func (r *RoleRepository) SetUserRoles(userID int64, roleIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserRole{}).Error; err != nil {
+			return err
+		}
+		if len(roleIDs) == 0 {
+			return nil
+		}
+		assignments := make([]models.UserRole, len(roleIDs))
+		for i, id := range roleIDs {
+			assignments[i] = models.UserRole{UserID: utils.JSONInt64(userID), RoleID: id}
+		}
+		return tx.Create(&assignments).Error
+	})
+}
+
+// CountUsersWithRole 统计拥有某角色且未被删除的用户数，不含 excludeUserIDs 中的用户
+func (r *RoleRepository) CountUsersWithRole(roleID uint, excludeUserIDs []int64) (int64, error) {
+	var count int64
+	query := r.db.Model(&models.UserRole{}).
+		Joins("JOIN users ON users.id = user_roles.user_id AND users.deleted_at IS NULL").
+		Where("user_roles.role_id = ?", roleID)
+	if len(excludeUserIDs) > 0 {
+		query = query.Where("user_roles.user_id NOT IN ?", excludeUserIDs)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"encoding/json"
+	"gohotel/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// RoomPhotoRepository 房间图片数据访问层
+type RoomPhotoRepository struct {
+	db *gorm.DB
+}
+
+// NewRoomPhotoRepository 创建房间图片仓库实例
+func NewRoomPhotoRepository(db *gorm.DB) *RoomPhotoRepository {
+	return &RoomPhotoRepository{db: db}
+}
+
+// CreateBatch 在事务中批量创建同一房间的图片，并同步房间的 images 字段
+func (r *RoomPhotoRepository) CreateBatch(photos []*models.RoomPhoto) error {
+	if len(photos) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&photos).Error; err != nil {
+			return err
+		}
+		return syncRoomImages(tx, photos[0].RoomID)
+	})
+}
+
+// FindByID 根据 ID 查找图片
+func (r *RoomPhotoRepository) FindByID(id uint) (*models.RoomPhoto, error) {
+	var photo models.RoomPhoto
+	err := r.db.First(&photo, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &photo, nil
+}
+
+// FindByRoomID 查询房间的所有图片（按排序）
+func (r *RoomPhotoRepository) FindByRoomID(roomID uint) ([]models.RoomPhoto, error) {
+	var photos []models.RoomPhoto
+	err := r.db.Where("room_id = ?", roomID).Order("sort, id").Find(&photos).Error
+	return photos, err
+}
+
+// MaxSort 获取房间图片的最大排序值，没有图片时返回 -1
+func (r *RoomPhotoRepository) MaxSort(roomID uint) (int, error) {
+	var maxSort *int
+	err := r.db.Model(&models.RoomPhoto{}).
+		Where("room_id = ?", roomID).
+		Select("MAX(sort)").
+		Scan(&maxSort).Error
+	if err != nil || maxSort == nil {
+		return -1, err
+	}
+	return *maxSort, nil
+}
+
+// Update 更新图片
+func (r *RoomPhotoRepository) Update(photo *models.RoomPhoto) error {
+	return r.db.Save(photo).Error
+}
+
+// DeleteWithCover 在事务中删除图片，删除的是封面时把排在最前的图片设为新封面，并同步房间的 images 字段
+func (r *RoomPhotoRepository) DeleteWithCover(photo *models.RoomPhoto) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.RoomPhoto{}, photo.ID).Error; err != nil {
+			return err
+		}
+		if photo.IsCover {
+			var next models.RoomPhoto
+			err := tx.Where("room_id = ?", photo.RoomID).Order("sort, id").First(&next).Error
+			if err != nil && err != gorm.ErrRecordNotFound {
+				return err
+			}
+			if err == nil {
+				if err := tx.Model(&next).Update("is_cover", true).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return syncRoomImages(tx, photo.RoomID)
+	})
+}
+
+// UpdateSorts 按给定顺序重排房间图片，并同步房间的 images 字段
+func (r *RoomPhotoRepository) UpdateSorts(roomID uint, photoIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i, id := range photoIDs {
+			err := tx.Model(&models.RoomPhoto{}).
+				Where("id = ? AND room_id = ?", id, roomID).
+				Update("sort", i).Error
+			if err != nil {
+				return err
+			}
+		}
+		return syncRoomImages(tx, roomID)
+	})
+}
+
+// SetCover 设置房间封面（同一房间只有一张封面），并同步房间的 images 字段
+func (r *RoomPhotoRepository) SetCover(roomID uint, photoID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.RoomPhoto{}).
+			Where("room_id = ? AND is_cover = ?", roomID, true).
+			Update("is_cover", false).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.RoomPhoto{}).
+			Where("id = ? AND room_id = ?", photoID, roomID).
+			Update("is_cover", true).Error
+		if err != nil {
+			return err
+		}
+		return syncRoomImages(tx, roomID)
+	})
+}
+
+// syncRoomImages 按图库同步房间的 images 字段（JSON 数组，封面在前），与图库改动在同一事务中写入
+// images 字段保留给仍读取 JSON 字符串的旧客户端
+func syncRoomImages(tx *gorm.DB, roomID uint) error {
+	var photos []models.RoomPhoto
+	if err := tx.Where("room_id = ?", roomID).Order("sort, id").Find(&photos).Error; err != nil {
+		return err
+	}
+
+	urls := make([]string, 0, len(photos))
+	for _, p := range photos {
+		if p.IsCover {
+			urls = append([]string{p.URL}, urls...)
+			continue
+		}
+		urls = append(urls, p.URL)
+	}
+
+	images := "[]"
+	if len(urls) > 0 {
+		data, err := json.Marshal(urls)
+		if err != nil {
+			return err
+		}
+		images = string(data)
+	}
+	return tx.Model(&models.Room{}).Where("id = ?", roomID).Update("images", images).Error
+}
@@ -21,20 +21,24 @@ func (r *RoomRepository) Create(room *models.Room) error {
 	return r.db.Create(room).Error
 }
 
-// FindByID 根据 ID 查找房间
+// FindByID 根据 ID 查找房间（包含设施和图片信息）
 func (r *RoomRepository) FindByID(id uint) (*models.Room, error) {
 	var room models.Room
-	err := r.db.First(&room, id).Error
+	err := r.db.Preload("Amenities").
+		Preload("Photos", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort, id")
+		}).
+		First(&room, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &room, nil
 }
 
-// FindByRoomNumber 根据房间号查找房间
-func (r *RoomRepository) FindByRoomNumber(roomNumber string) (*models.Room, error) {
+// FindByRoomNumber 根据房间号查找酒店内的房间
+func (r *RoomRepository) FindByRoomNumber(hotelID uint, roomNumber string) (*models.Room, error) {
 	var room models.Room
-	err := r.db.Where("room_number = ?", roomNumber).First(&room).Error
+	err := r.db.Where("hotel_id = ? AND room_number = ?", hotelID, roomNumber).First(&room).Error
 	if err != nil {
 		return nil, err
 	}
@@ -42,51 +46,104 @@ func (r *RoomRepository) FindByRoomNumber(roomNumber string) (*models.Room, erro
 }
 
 // Update 更新房间信息
+// 设施和图片通过各自的接口维护，这里不保存关联
 func (r *RoomRepository) Update(room *models.Room) error {
-	return r.db.Save(room).Error
+	return r.db.Omit("Amenities", "Photos").Save(room).Error
 }
 
-// Delete 删除房间
+// Delete 删除房间（同时删除与设施的关联和图片记录）
 func (r *RoomRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Room{}, id).Error
+	return r.db.Select("Amenities", "Photos").Delete(&models.Room{ID: id}).Error
 }
 
 // FindAll 查询所有房间（分页）
-func (r *RoomRepository) FindAll(page, pageSize int) ([]models.Room, int64, error) {
+// amenityIDs 不为空时只返回同时具备这些设施的房间
+func (r *RoomRepository) FindAll(hotelID uint, page, pageSize int, amenityIDs []uint) ([]models.Room, int64, error) {
 	var rooms []models.Room
 	var total int64
 
-	if err := r.db.Model(&models.Room{}).Count(&total).Error; err != nil {
+	query := r.applyAmenityFilter(r.hotelQuery(hotelID), amenityIDs)
+
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	offset := (page - 1) * pageSize
-	err := r.db.Offset(offset).Limit(pageSize).Order("room_number").Find(&rooms).Error
+	err := query.Preload("Amenities").Offset(offset).Limit(pageSize).Order("room_number").Find(&rooms).Error
 	return rooms, total, err
 }
 
 // FindAvailable 查询可用房间（分页）
-func (r *RoomRepository) FindAvailable(page, pageSize int) ([]models.Room, int64, error) {
+// amenityIDs 不为空时只返回同时具备这些设施的房间
+func (r *RoomRepository) FindAvailable(hotelID uint, page, pageSize int, amenityIDs []uint) ([]models.Room, int64, error) {
 	var rooms []models.Room
 	var total int64
 
-	query := r.db.Model(&models.Room{}).Where("status = ?", "available")
+	query := r.applyAmenityFilter(r.availableQuery(hotelID), amenityIDs)
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	offset := (page - 1) * pageSize
-	err := query.Offset(offset).Limit(pageSize).Order("price").Find(&rooms).Error
+	err := query.Preload("Amenities").Offset(offset).Limit(pageSize).Order("price").Find(&rooms).Error
 	return rooms, total, err
 }
 
+// CountAmenityFacets 统计当前筛选条件下每种设施对应的房间数量
+// onlyAvailable 为 true 时只统计可售房间
+func (r *RoomRepository) CountAmenityFacets(hotelID uint, onlyAvailable bool, amenityIDs []uint) ([]models.AmenityFacet, error) {
+	roomQuery := r.hotelQuery(hotelID)
+	if onlyAvailable {
+		roomQuery = r.availableQuery(hotelID)
+	}
+	roomQuery = r.applyAmenityFilter(roomQuery, amenityIDs).Select("rooms.id")
+
+	var facets []models.AmenityFacet
+	err := r.db.Table("room_amenities").
+		Select("amenities.id AS amenity_id, amenities.name, amenities.icon, amenities.category, COUNT(DISTINCT room_amenities.room_id) AS count").
+		Joins("JOIN amenities ON amenities.id = room_amenities.amenity_id").
+		Where("room_amenities.room_id IN (?)", roomQuery).
+		Group("amenities.id, amenities.name, amenities.icon, amenities.category, amenities.sort").
+		Order("amenities.sort, amenities.id").
+		Scan(&facets).Error
+	return facets, err
+}
+
+// hotelQuery 酒店内的房间查询
+func (r *RoomRepository) hotelQuery(hotelID uint) *gorm.DB {
+	return r.db.Model(&models.Room{}).Where("rooms.hotel_id = ?", hotelID)
+}
+
+// availableQuery 可售房间查询：空闲（清洁状态只影响当天入住，不影响房间是否可售）
+func (r *RoomRepository) availableQuery(hotelID uint) *gorm.DB {
+	return r.hotelQuery(hotelID).Where("rooms.status = ?", "available")
+}
+
+// applyAmenityFilter 筛选同时具备所有指定设施的房间
+func (r *RoomRepository) applyAmenityFilter(query *gorm.DB, amenityIDs []uint) *gorm.DB {
+	if len(amenityIDs) == 0 {
+		return query
+	}
+	subQuery := r.db.Table("room_amenities").
+		Select("room_id").
+		Where("amenity_id IN ?", amenityIDs).
+		Group("room_id").
+		Having("COUNT(DISTINCT amenity_id) = ?", len(amenityIDs))
+	return query.Where("rooms.id IN (?)", subQuery)
+}
+
+// ReplaceAmenities 替换房间的设施列表
+func (r *RoomRepository) ReplaceAmenities(room *models.Room, amenities []models.Amenity) error {
+	return r.db.Model(room).Association("Amenities").Replace(amenities)
+}
+
 // FindByRoomType 根据房型查询房间（分页）
-func (r *RoomRepository) FindByRoomType(roomType string, page, pageSize int) ([]models.Room, int64, error) {
+func (r *RoomRepository) FindByRoomType(hotelID uint, roomType string, page, pageSize int) ([]models.Room, int64, error) {
 	var rooms []models.Room
 	var total int64
 
-	query := r.db.Model(&models.Room{}).Where("room_type = ?", roomType)
+	query := r.hotelQuery(hotelID).Where("room_type = ?", roomType)
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -98,11 +155,11 @@ func (r *RoomRepository) FindByRoomType(roomType string, page, pageSize int) ([]
 }
 
 // FindByPriceRange 根据价格范围查询房间（分页）
-func (r *RoomRepository) FindByPriceRange(minPrice, maxPrice float64, page, pageSize int) ([]models.Room, int64, error) {
+func (r *RoomRepository) FindByPriceRange(hotelID uint, minPrice, maxPrice float64, page, pageSize int) ([]models.Room, int64, error) {
 	var rooms []models.Room
 	var total int64
 
-	query := r.db.Model(&models.Room{}).Where("price BETWEEN ? AND ?", minPrice, maxPrice)
+	query := r.hotelQuery(hotelID).Where("price BETWEEN ? AND ?", minPrice, maxPrice)
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -114,11 +171,11 @@ func (r *RoomRepository) FindByPriceRange(minPrice, maxPrice float64, page, page
 }
 
 // FindByFloor 根据楼层查询房间
-func (r *RoomRepository) FindRoomByFloor(floor, page, pageSize int) ([]models.Room, int64, error) {
+func (r *RoomRepository) FindRoomByFloor(hotelID uint, floor, page, pageSize int) ([]models.Room, int64, error) {
 	var rooms []models.Room
 	var total int64
 
-	query := r.db.Model(&models.Room{}).Where("floor = ?", floor)
+	query := r.hotelQuery(hotelID).Where("floor = ?", floor)
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -134,10 +191,15 @@ func (r *RoomRepository) UpdateStatus(id uint, status string) error {
 	return r.db.Model(&models.Room{}).Where("id = ?", id).Update("status", status).Error
 }
 
-// ExistsByRoomNumber 检查房间号是否已存在
-func (r *RoomRepository) ExistsByRoomNumber(roomNumber string) (bool, error) {
+// UpdateHousekeepingStatus 更新房间清洁状态
+func (r *RoomRepository) UpdateHousekeepingStatus(id uint, status string) error {
+	return r.db.Model(&models.Room{}).Where("id = ?", id).Update("housekeeping_status", status).Error
+}
+
+// ExistsByRoomNumber 检查酒店内房间号是否已存在
+func (r *RoomRepository) ExistsByRoomNumber(hotelID uint, roomNumber string) (bool, error) {
 	var count int64
-	err := r.db.Model(&models.Room{}).Where("room_number = ?", roomNumber).Count(&count).Error
+	err := r.hotelQuery(hotelID).Where("room_number = ?", roomNumber).Count(&count).Error
 	return count > 0, err
 }
 
@@ -146,10 +208,10 @@ func (r *RoomRepository) CreateBatch(rooms []*models.Room) error {
 	return r.db.Create(rooms).Error
 }
 
-// ExistsByRoomNumbers 批量检查房间号是否已存在，返回已存在的房间号列表
-func (r *RoomRepository) ExistsByRoomNumbers(roomNumbers []string) ([]string, error) {
+// ExistsByRoomNumbers 批量检查酒店内房间号是否已存在，返回已存在的房间号列表
+func (r *RoomRepository) ExistsByRoomNumbers(hotelID uint, roomNumbers []string) ([]string, error) {
 	var existingRooms []models.Room
-	err := r.db.Model(&models.Room{}).Where("room_number IN ?", roomNumbers).Select("room_number").Find(&existingRooms).Error
+	err := r.hotelQuery(hotelID).Where("room_number IN ?", roomNumbers).Select("room_number").Find(&existingRooms).Error
 	if err != nil {
 		return nil, err
 	}
@@ -159,3 +221,20 @@ func (r *RoomRepository) ExistsByRoomNumbers(roomNumbers []string) ([]string, er
 	}
 	return existingNumbers, nil
 }
+
+// FindAllByFilter 按楼层和房型查询酒店内的房间（不分页，用于房态日历）
+// floor 为 0、roomType 为空时不过滤
+func (r *RoomRepository) FindAllByFilter(hotelID uint, floor int, roomType string) ([]models.Room, error) {
+	var rooms []models.Room
+	query := r.hotelQuery(hotelID)
+
+	if floor != 0 {
+		query = query.Where("floor = ?", floor)
+	}
+	if roomType != "" {
+		query = query.Where("room_type = ?", roomType)
+	}
+
+	err := query.Order("floor, room_number").Find(&rooms).Error
+	return rooms, err
+}
@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"fmt"
 	"gohotel/internal/models"
 	"strconv"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -22,17 +24,19 @@ func (r *UserRepository) Create(user *models.User) error {
 	return r.db.Create(user).Error
 }
 
+// 唯一性检查包含回收站中的用户，用户名、邮箱和手机号在匿名化之前保留给原用户，保证可以恢复
+
 // ExistsByUsername 检查用户名是否已存在
 func (r *UserRepository) ExistsByUsername(username string) (bool, error) {
 	var count int64
-	err := r.db.Model(&models.User{}).Where("username = ?", username).Count(&count).Error
+	err := r.db.Unscoped().Model(&models.User{}).Where("username = ?", username).Count(&count).Error
 	return count > 0, err
 }
 
 // ExistsByEmail 检查邮箱是否已存在
 func (r *UserRepository) ExistsByEmail(email string) (bool, error) {
 	var count int64
-	err := r.db.Model(&models.User{}).Where("email = ?", email).Count(&count).Error
+	err := r.db.Unscoped().Model(&models.User{}).Where("email = ?", email).Count(&count).Error
 	return count > 0, err
 }
 
@@ -42,7 +46,7 @@ func (r *UserRepository) ExistsByPhone(phone string) (bool, error) {
 		return false, nil
 	}
 	var count int64
-	err := r.db.Model(&models.User{}).Where("phone = ?", phone).Count(&count).Error
+	err := r.db.Unscoped().Model(&models.User{}).Where("phone = ?", phone).Count(&count).Error
 	return count > 0, err
 }
 
@@ -52,7 +56,7 @@ func (r *UserRepository) ExistsByPhoneExcludingUser(phone string, excludeUserID
 		return false, nil
 	}
 	var count int64
-	err := r.db.Model(&models.User{}).Where("phone = ? AND id != ?", phone, excludeUserID).Count(&count).Error
+	err := r.db.Unscoped().Model(&models.User{}).Where("phone = ? AND id != ?", phone, excludeUserID).Count(&count).Error
 	return count > 0, err
 }
 
@@ -61,12 +65,12 @@ func (r *UserRepository) Update(user *models.User) error {
 	return r.db.Save(user).Error
 }
 
-// Delete 删除用户
+// Delete 删除用户（软删除）
 func (r *UserRepository) Delete(id int64) error {
 	return r.db.Delete(&models.User{}, id).Error
 }
 
-// BatchDelete 批量删除用户
+// BatchDelete 批量删除用户（软删除）
 func (r *UserRepository) BatchDelete(userIDs []string) error {
 	// 将字符串ID转换为int64
 	var ids []int64
@@ -90,6 +94,16 @@ func (r *UserRepository) FindByID(id int64) (*models.User, error) {
 	return &user, nil
 }
 
+// FindByIDs 根据 ID 列表查找用户
+func (r *UserRepository) FindByIDs(ids []int64) ([]models.User, error) {
+	var users []models.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
 // FindByUsername 根据用户名查找用户
 func (r *UserRepository) FindByUsername(username string) (*models.User, error) {
 	var user models.User
@@ -157,3 +171,119 @@ func (r *UserRepository) FindAll(page, pageSize int, username, email, phone, rea
 	err := query.Offset(offset).Limit(pageSize).Find(&users).Error
 	return users, total, err
 }
+
+// FindDeleted 分页查询回收站中的用户（已删除且尚未匿名化），按删除时间倒序
+func (r *UserRepository) FindDeleted(page, pageSize int) ([]models.User, int64, error) {
+	var users []models.User
+	var total int64
+
+	query := r.db.Unscoped().Model(&models.User{}).
+		Where("deleted_at IS NOT NULL AND anonymized_at IS NULL")
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("deleted_at DESC").Offset(offset).Limit(pageSize).Find(&users).Error
+	return users, total, err
+}
+
+// FindDeletedByID 根据 ID 查找已删除的用户
+func (r *UserRepository) FindDeletedByID(id int64) (*models.User, error) {
+	var user models.User
+	err := r.db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Restore 恢复回收站中的用户，用户不在回收站中时返回 gorm.ErrRecordNotFound
+func (r *UserRepository) Restore(id int64) error {
+	result := r.db.Unscoped().Model(&models.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL AND anonymized_at IS NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FindDeletedBefore 查询删除时间早于 before 且尚未匿名化的用户 ID
+func (r *UserRepository) FindDeletedBefore(before time.Time, limit int) ([]int64, error) {
+	var ids []int64
+	err := r.db.Unscoped().Model(&models.User{}).
+		Where("deleted_at < ? AND anonymized_at IS NULL", before).
+		Order("deleted_at").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Anonymize 在事务中清除已删除用户的个人信息，并删除其第三方账号绑定、两步验证和角色分配
+// 用户名和邮箱改为不含个人信息的占位值，原用户名、邮箱和手机号随即可以被重新注册；
+// 同时清除其预订中的入住人信息、登录会话的 IP 和 User-Agent，以及发送到其手机号和邮箱的验证码
+func (r *UserRepository) Anonymize(id int64, now time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL AND anonymized_at IS NULL").First(&user, id).Error; err != nil {
+			return err
+		}
+
+		placeholder := fmt.Sprintf("deleted_%d", id)
+		result := tx.Unscoped().Model(&models.User{}).
+			Where("id = ? AND deleted_at IS NOT NULL AND anonymized_at IS NULL", id).
+			Updates(map[string]interface{}{
+				"username":      placeholder,
+				"email":         placeholder + "@deleted.invalid",
+				"phone":         nil,
+				"real_name":     "",
+				"avatar":        "",
+				"password":      "",
+				"status":        "blocked",
+				"anonymized_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		for _, model := range []interface{}{
+			&models.WechatAccount{}, &models.UserTOTP{}, &models.MFARecoveryCode{},
+			&models.UserRole{}, &models.PasswordResetToken{}, &models.LoginLockout{},
+		} {
+			if err := tx.Where("user_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		// 历史预订保留，只清除入住人信息
+		err := tx.Model(&models.Booking{}).
+			Where("user_id = ?", id).
+			Updates(map[string]interface{}{
+				"guest_name":    "已注销用户",
+				"guest_phone":   "",
+				"guest_id_card": "",
+			}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.AuthSession{}).
+			Where("user_id = ?", id).
+			Updates(map[string]interface{}{"ip": "", "user_agent": ""}).Error
+		if err != nil {
+			return err
+		}
+
+		targets := []string{user.Email}
+		if user.Phone != nil {
+			targets = append(targets, *user.Phone)
+		}
+		return tx.Where("target IN ?", targets).Delete(&models.VerificationCode{}).Error
+	})
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"gohotel/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// VerificationCodeRepository 验证码数据访问层
+type VerificationCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewVerificationCodeRepository 创建验证码仓库实例
+func NewVerificationCodeRepository(db *gorm.DB) *VerificationCodeRepository {
+	return &VerificationCodeRepository{db: db}
+}
+
+// Create 保存验证码
+func (r *VerificationCodeRepository) Create(code *models.VerificationCode) error {
+	return r.db.Create(code).Error
+}
+
+// Delete 删除验证码（发送失败时撤回）
+func (r *VerificationCodeRepository) Delete(id uint) error {
+	return r.db.Delete(&models.VerificationCode{}, id).Error
+}
+
+// FindLatest 查找目标某用途最近发送的验证码
+func (r *VerificationCodeRepository) FindLatest(target, purpose string) (*models.VerificationCode, error) {
+	var code models.VerificationCode
+	err := r.db.Where("target = ? AND purpose = ?", target, purpose).
+		Order("created_at DESC, id DESC").
+		First(&code).Error
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+// CountByTargetSince 统计某时间之后发送到目标的验证码数量（所有用途）
+func (r *VerificationCodeRepository) CountByTargetSince(target string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.VerificationCode{}).
+		Where("target = ? AND created_at >= ?", target, since).
+		Count(&count).Error
+	return count, err
+}
+
+// CountByIPSince 统计某时间之后同一 IP 请求发送的验证码数量
+func (r *VerificationCodeRepository) CountByIPSince(ip string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.VerificationCode{}).
+		Where("ip = ? AND created_at >= ?", ip, since).
+		Count(&count).Error
+	return count, err
+}
+
+// IncrementAttempts 校验失败次数加一
+func (r *VerificationCodeRepository) IncrementAttempts(id uint) error {
+	return r.db.Model(&models.VerificationCode{}).
+		Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1")).Error
+}
+
+// MarkUsed 将验证码标记为已使用，已被使用时返回 gorm.ErrRecordNotFound
+func (r *VerificationCodeRepository) MarkUsed(id uint, now time.Time) error {
+	result := r.db.Model(&models.VerificationCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteBefore 删除某时间之前发送的验证码，返回删除数量
+func (r *VerificationCodeRepository) DeleteBefore(before time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", before).Delete(&models.VerificationCode{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"gohotel/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// WechatAccountRepository 微信账号绑定数据访问层
+type WechatAccountRepository struct {
+	db *gorm.DB
+}
+
+// NewWechatAccountRepository 创建微信账号绑定仓库实例
+func NewWechatAccountRepository(db *gorm.DB) *WechatAccountRepository {
+	return &WechatAccountRepository{db: db}
+}
+
+// Create 保存绑定
+func (r *WechatAccountRepository) Create(account *models.WechatAccount) error {
+	return r.db.Create(account).Error
+}
+
+// CreateWithUser 在一个事务中创建用户和微信绑定
+func (r *WechatAccountRepository) CreateWithUser(user *models.User, account *models.WechatAccount) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		account.UserID = user.ID
+		return tx.Create(account).Error
+	})
+}
+
+// FindByOpenID 根据 openid 查找绑定
+func (r *WechatAccountRepository) FindByOpenID(openID string) (*models.WechatAccount, error) {
+	var account models.WechatAccount
+	if err := r.db.Where("open_id = ?", openID).First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// FindByUnionID 根据 unionid 查找绑定（同一开放平台下的其他小程序）
+func (r *WechatAccountRepository) FindByUnionID(unionID string) (*models.WechatAccount, error) {
+	var account models.WechatAccount
+	if err := r.db.Where("union_id = ?", unionID).First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// FindByUserAndApp 查找用户在某个小程序下的绑定
+func (r *WechatAccountRepository) FindByUserAndApp(userID int64, appID string) (*models.WechatAccount, error) {
+	var account models.WechatAccount
+	if err := r.db.Where("user_id = ? AND app_id = ?", userID, appID).First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// UpdateSession 更新会话密钥和 unionid
+func (r *WechatAccountRepository) UpdateSession(id uint, sessionKey, unionID string) error {
+	updates := map[string]interface{}{"session_key": sessionKey}
+	if unionID != "" {
+		updates["union_id"] = unionID
+	}
+	return r.db.Model(&models.WechatAccount{}).Where("id = ?", id).Updates(updates).Error
+}
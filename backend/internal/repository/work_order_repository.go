@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"gohotel/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// activeWorkOrderStatuses 处理中的工单状态
+var activeWorkOrderStatuses = []string{"open", "in_progress"}
+
+// WorkOrderRepository 维修工单数据访问层
+type WorkOrderRepository struct {
+	db *gorm.DB
+}
+
+// NewWorkOrderRepository 创建维修工单仓库实例
+func NewWorkOrderRepository(db *gorm.DB) *WorkOrderRepository {
+	return &WorkOrderRepository{db: db}
+}
+
+// Create 创建工单
+func (r *WorkOrderRepository) Create(workOrder *models.WorkOrder) error {
+	return r.db.Create(workOrder).Error
+}
+
+// FindByID 根据 ID 查找工单（包含房间和维修人员信息）
+func (r *WorkOrderRepository) FindByID(id uint) (*models.WorkOrder, error) {
+	var workOrder models.WorkOrder
+	err := r.db.Preload("Room").Preload("Assignee").First(&workOrder, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &workOrder, nil
+}
+
+// Update 更新工单
+func (r *WorkOrderRepository) Update(workOrder *models.WorkOrder) error {
+	return r.db.Omit("Room", "Assignee").Save(workOrder).Error
+}
+
+// FindAll 根据条件查询酒店的工单（分页）
+// 参数为空值时不过滤
+func (r *WorkOrderRepository) FindAll(hotelID uint, page, pageSize int, status, priority, category string, roomID uint) ([]models.WorkOrder, int64, error) {
+	var workOrders []models.WorkOrder
+	var total int64
+
+	query := r.db.Model(&models.WorkOrder{}).
+		Joins("JOIN rooms ON rooms.id = work_orders.room_id").
+		Where("rooms.hotel_id = ?", hotelID)
+	if status != "" {
+		query = query.Where("work_orders.status = ?", status)
+	}
+	if priority != "" {
+		query = query.Where("work_orders.priority = ?", priority)
+	}
+	if category != "" {
+		query = query.Where("work_orders.category = ?", category)
+	}
+	if roomID != 0 {
+		query = query.Where("work_orders.room_id = ?", roomID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Preload("Room").Preload("Assignee").
+		Offset(offset).Limit(pageSize).
+		Order("work_orders.created_at DESC").Find(&workOrders).Error
+	return workOrders, total, err
+}
+
+// FindOutOfOrderByRoomsAndDateRange 查询指定房间在日期范围内生效的停用工单
+// roomIDs 为空时查询所有房间
+func (r *WorkOrderRepository) FindOutOfOrderByRoomsAndDateRange(roomIDs []uint, startDate, endDate time.Time) ([]models.WorkOrder, error) {
+	var workOrders []models.WorkOrder
+	query := r.db.Model(&models.WorkOrder{}).
+		Where("status IN ?", activeWorkOrderStatuses).
+		Where("out_of_order_start IS NOT NULL AND out_of_order_end IS NOT NULL").
+		Where("out_of_order_start < ? AND out_of_order_end > ?", endDate, startDate)
+
+	if len(roomIDs) > 0 {
+		query = query.Where("room_id IN ?", roomIDs)
+	}
+
+	err := query.Order("out_of_order_start").Find(&workOrders).Error
+	return workOrders, err
+}
+
+// ExistsOutOfOrder 检查房间在指定日期范围内是否有生效的停用工单
+func (r *WorkOrderRepository) ExistsOutOfOrder(roomID uint, startDate, endDate time.Time) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.WorkOrder{}).
+		Where("room_id = ?", roomID).
+		Where("status IN ?", activeWorkOrderStatuses).
+		Where("out_of_order_start IS NOT NULL AND out_of_order_end IS NOT NULL").
+		Where("out_of_order_start < ? AND out_of_order_end > ?", endDate, startDate).
+		Count(&count).Error
+	return count > 0, err
+}
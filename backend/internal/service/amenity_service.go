@@ -0,0 +1,165 @@
+package service
+
+import (
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+// AmenityService 房间设施目录业务逻辑层
+type AmenityService struct {
+	amenityRepo *repository.AmenityRepository
+	roomRepo    *repository.RoomRepository
+}
+
+// NewAmenityService 创建设施目录服务实例
+func NewAmenityService(amenityRepo *repository.AmenityRepository, roomRepo *repository.RoomRepository) *AmenityService {
+	return &AmenityService{
+		amenityRepo: amenityRepo,
+		roomRepo:    roomRepo,
+	}
+}
+
+// CreateAmenityRequest 创建设施请求
+type CreateAmenityRequest struct {
+	Name     string `json:"name" binding:"required,max=50"`
+	Icon     string `json:"icon"`
+	Category string `json:"category"`
+	Sort     int    `json:"sort"`
+}
+
+// UpdateAmenityRequest 更新设施请求
+type UpdateAmenityRequest struct {
+	Name     string `json:"name" binding:"max=50"`
+	Icon     string `json:"icon"`
+	Category string `json:"category"`
+	Sort     *int   `json:"sort"`
+}
+
+// SetRoomAmenitiesRequest 设置房间设施请求
+type SetRoomAmenitiesRequest struct {
+	AmenityIDs []uint `json:"amenity_ids"` // 为空时清空房间设施
+}
+
+// CreateAmenity 创建设施
+func (s *AmenityService) CreateAmenity(req *CreateAmenityRequest) (*models.Amenity, error) {
+	exists, err := s.amenityRepo.ExistsByName(req.Name)
+	if err != nil {
+		return nil, errors.NewDatabaseError("check amenity name", err)
+	}
+	if exists {
+		return nil, errors.NewConflictError("设施名称已存在")
+	}
+
+	amenity := &models.Amenity{
+		Name:     req.Name,
+		Icon:     req.Icon,
+		Category: req.Category,
+		Sort:     req.Sort,
+	}
+	if amenity.Category == "" {
+		amenity.Category = "general"
+	}
+
+	if err := s.amenityRepo.Create(amenity); err != nil {
+		return nil, errors.NewDatabaseError("create amenity", err)
+	}
+
+	return amenity, nil
+}
+
+// UpdateAmenity 更新设施
+func (s *AmenityService) UpdateAmenity(id uint, req *UpdateAmenityRequest) (*models.Amenity, error) {
+	amenity, err := s.findAmenity(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" && req.Name != amenity.Name {
+		exists, err := s.amenityRepo.ExistsByName(req.Name)
+		if err != nil {
+			return nil, errors.NewDatabaseError("check amenity name", err)
+		}
+		if exists {
+			return nil, errors.NewConflictError("设施名称已存在")
+		}
+		amenity.Name = req.Name
+	}
+	if req.Icon != "" {
+		amenity.Icon = req.Icon
+	}
+	if req.Category != "" {
+		amenity.Category = req.Category
+	}
+	if req.Sort != nil {
+		amenity.Sort = *req.Sort
+	}
+
+	if err := s.amenityRepo.Update(amenity); err != nil {
+		return nil, errors.NewDatabaseError("update amenity", err)
+	}
+
+	return amenity, nil
+}
+
+// DeleteAmenity 删除设施，同时解除与房间的关联
+func (s *AmenityService) DeleteAmenity(id uint) error {
+	if _, err := s.findAmenity(id); err != nil {
+		return err
+	}
+
+	if err := s.amenityRepo.Delete(id); err != nil {
+		return errors.NewDatabaseError("delete amenity", err)
+	}
+
+	return nil
+}
+
+// ListAmenities 获取设施目录，category 为空时返回全部
+func (s *AmenityService) ListAmenities(category string) ([]models.Amenity, error) {
+	amenities, err := s.amenityRepo.FindAll(category)
+	if err != nil {
+		return nil, errors.NewDatabaseError("list amenities", err)
+	}
+	return amenities, nil
+}
+
+// SetRoomAmenities 替换房间的设施列表
+func (s *AmenityService) SetRoomAmenities(hotelID, roomID uint, req *SetRoomAmenitiesRequest) (*models.Room, error) {
+	room, err := findHotelRoom(s.roomRepo, hotelID, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := uniqueAmenityIDs(req.AmenityIDs)
+	amenities := make([]models.Amenity, 0)
+	if len(ids) > 0 {
+		amenities, err = s.amenityRepo.FindByIDs(ids)
+		if err != nil {
+			return nil, errors.NewDatabaseError("find amenities", err)
+		}
+		if len(amenities) != len(ids) {
+			return nil, errors.NewBadRequestError("存在无效的设施ID")
+		}
+	}
+
+	if err := s.roomRepo.ReplaceAmenities(room, amenities); err != nil {
+		return nil, errors.NewDatabaseError("replace room amenities", err)
+	}
+
+	return s.roomRepo.FindByID(roomID)
+}
+
+// findAmenity 查找设施，不存在时返回 404
+func (s *AmenityService) findAmenity(id uint) (*models.Amenity, error) {
+	amenity, err := s.amenityRepo.FindByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("设施不存在")
+		}
+		return nil, errors.NewDatabaseError("find amenity", err)
+	}
+	return amenity, nil
+}
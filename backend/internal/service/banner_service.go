@@ -6,6 +6,8 @@ import (
 	"gohotel/pkg/utils"
 	"sync"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // BannerService 活动横幅业务逻辑层
@@ -134,7 +136,7 @@ func parseTimeString(timeStr *string) *time.Time {
 }
 
 // CreateBanner 创建活动横幅
-func (s *BannerService) CreateBanner(req *CreateBannerRequest) (*models.Banner, error) {
+func (s *BannerService) CreateBanner(hotelID uint, req *CreateBannerRequest) (*models.Banner, error) {
 	// 解析时间
 	startTime := parseTimeString(req.StartTime)
 	endTime := parseTimeString(req.EndTime)
@@ -150,6 +152,7 @@ func (s *BannerService) CreateBanner(req *CreateBannerRequest) (*models.Banner,
 
 	// 创建Banner对象
 	banner := &models.Banner{
+		HotelID:   hotelID,
 		Title:     req.Title,
 		Subtitle:  req.Subtitle,
 		ImageURL:  req.ImageURL,
@@ -172,24 +175,32 @@ func (s *BannerService) CreateBanner(req *CreateBannerRequest) (*models.Banner,
 }
 
 // GetBannerByID 根据ID获取活动横幅
-func (s *BannerService) GetBannerByID(id int64) (*models.Banner, error) {
-	return s.bannerRepo.FindByID(id)
+// 属于其他酒店时视为不存在
+func (s *BannerService) GetBannerByID(hotelID uint, id int64) (*models.Banner, error) {
+	banner, err := s.GetBannerByID(hotelID, id)
+	if err != nil {
+		return nil, err
+	}
+	if banner.HotelID != hotelID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return banner, nil
 }
 
 // GetAllBanners 获取所有活动横幅（带分页）
-func (s *BannerService) GetAllBanners(page, pageSize int) ([]models.Banner, int64, error) {
-	return s.bannerRepo.FindAll(page, pageSize)
+func (s *BannerService) GetAllBanners(hotelID uint, page, pageSize int) ([]models.Banner, int64, error) {
+	return s.bannerRepo.FindAll(hotelID, page, pageSize)
 }
 
 // GetActiveBanners 获取激活的活动横幅（前端展示用）
-func (s *BannerService) GetActiveBanners() ([]models.Banner, error) {
-	return s.bannerRepo.FindActive()
+func (s *BannerService) GetActiveBanners(hotelID uint) ([]models.Banner, error) {
+	return s.bannerRepo.FindActive(hotelID)
 }
 
 // UpdateBanner 更新活动横幅信息
-func (s *BannerService) UpdateBanner(id int64, req *UpdateBannerRequest) (*models.Banner, error) {
+func (s *BannerService) UpdateBanner(hotelID uint, id int64, req *UpdateBannerRequest) (*models.Banner, error) {
 	// 获取现有Banner
-	banner, err := s.bannerRepo.FindByID(id)
+	banner, err := s.GetBannerByID(hotelID, id)
 	if err != nil {
 		return nil, err
 	}
@@ -351,8 +362,8 @@ func (s *BannerService) addBannerTasks(banner *models.Banner) {
 }
 
 // DeleteBanner 删除活动横幅
-func (s *BannerService) DeleteBanner(id int64) error {
-	banner, err := s.bannerRepo.FindByID(id)
+func (s *BannerService) DeleteBanner(hotelID uint, id int64) error {
+	banner, err := s.GetBannerByID(hotelID, id)
 	if err != nil {
 		return err
 	}
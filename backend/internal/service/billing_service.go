@@ -0,0 +1,180 @@
+package service
+
+import (
+	"gohotel/internal/config"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/logger"
+	"gohotel/pkg/utils"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 按月付款
+const (
+	monthlyBillingMinNights = 30 // 连住满 30 晚才可按月付款
+	billingPeriodNights     = 30 // 每期房费覆盖的晚数
+)
+
+// BillingService 长住账单业务逻辑层
+// 按月付款的预订在下单时生成押金和分期房费账单，定时任务在到期日将账单出账
+type BillingService struct {
+	installmentRepo *repository.InstallmentRepository
+	bookingRepo     *repository.BookingRepository
+	timeWheel       *utils.MultiTimeWheel
+	interval        time.Duration
+}
+
+// NewBillingService 创建长住账单服务实例
+func NewBillingService(installmentRepo *repository.InstallmentRepository, bookingRepo *repository.BookingRepository, timeWheel *utils.MultiTimeWheel, cfg *config.BookingConfig) *BillingService {
+	return &BillingService{
+		installmentRepo: installmentRepo,
+		bookingRepo:     bookingRepo,
+		timeWheel:       timeWheel,
+		interval:        cfg.BillingInterval,
+	}
+}
+
+// BuildSchedule 为按月付款的预订生成分期账单
+// 押金（长住政策设置时）下单即出账，与是否命中连住折扣档位无关；房费每 30 晚一期，在该期开始日出账，最后一期承担折扣的舍入差额，保证合计等于总价
+func (s *BillingService) BuildSchedule(booking *models.Booking, quote *StayQuote) []models.BookingInstallment {
+	var installments []models.BookingInstallment
+	now := time.Now()
+
+	if quote.Deposit > 0 {
+		installments = append(installments, models.BookingInstallment{
+			BookingID:   booking.ID,
+			HotelID:     booking.HotelID,
+			Seq:         0,
+			Type:        models.InstallmentTypeDeposit,
+			PeriodStart: booking.CheckIn,
+			PeriodEnd:   booking.CheckIn,
+			Amount:      quote.Deposit,
+			DueDate:     pricingToday(),
+			Status:      models.InstallmentBilled,
+			BilledAt:    &now,
+		})
+	}
+
+	rate := 1.0
+	if quote.Discount != nil {
+		rate = 1 - quote.Discount.DiscountPercent/100
+	}
+	billed := 0.0
+	nights := len(quote.NightlyPrices)
+	for start, seq := 0, 1; start < nights; start, seq = start+billingPeriodNights, seq+1 {
+		end := start + billingPeriodNights
+		if end > nights {
+			end = nights
+		}
+
+		amount := 0.0
+		if end == nights {
+			amount = math.Round((booking.TotalPrice-billed)*100) / 100
+		} else {
+			for _, price := range quote.NightlyPrices[start:end] {
+				amount += price
+			}
+			amount = math.Round(amount*rate*100) / 100
+		}
+		billed += amount
+
+		periodStart := booking.CheckIn.AddDate(0, 0, start)
+		installments = append(installments, models.BookingInstallment{
+			BookingID:   booking.ID,
+			HotelID:     booking.HotelID,
+			Seq:         seq,
+			Type:        models.InstallmentTypeRent,
+			PeriodStart: periodStart,
+			PeriodEnd:   booking.CheckIn.AddDate(0, 0, end),
+			Nights:      end - start,
+			Amount:      amount,
+			DueDate:     periodStart,
+			Status:      models.InstallmentScheduled,
+		})
+	}
+	return installments
+}
+
+// GenerateDueBills 将到期日不晚于今天的账单出账，返回出账数量
+func (s *BillingService) GenerateDueBills() (int, error) {
+	installments, err := s.installmentRepo.FindDue(pricingToday())
+	if err != nil {
+		return 0, errors.NewDatabaseError("find due installments", err)
+	}
+	if len(installments) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]uint, len(installments))
+	for i := range installments {
+		ids[i] = installments[i].ID
+	}
+	count, err := s.installmentRepo.MarkBilled(ids, time.Now())
+	if err != nil {
+		return 0, errors.NewDatabaseError("bill installments", err)
+	}
+	return int(count), nil
+}
+
+// StartScheduler 启动定时出账任务，立即执行一次，之后按配置的间隔重复执行
+func (s *BillingService) StartScheduler() {
+	var task func()
+	task = func() {
+		count, err := s.GenerateDueBills()
+		if err != nil {
+			logger.Error("长住账单出账失败", zap.Error(err))
+		} else if count > 0 {
+			logger.Info("长住账单已出账", zap.Int("count", count))
+		}
+		s.timeWheel.AddTask(time.Now().Add(s.interval), task, nil, true) // 不持久化任务
+	}
+	go task()
+}
+
+// PayInstallment 登记账单已支付，全部账单付清后预订标记为已支付
+func (s *BillingService) PayInstallment(booking *models.Booking, installmentID uint) (*models.BookingInstallment, error) {
+	installment, err := s.installmentRepo.FindByID(installmentID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("账单不存在")
+		}
+		return nil, errors.NewDatabaseError("find installment", err)
+	}
+	if installment.BookingID != booking.ID {
+		return nil, errors.NewNotFoundError("账单不存在")
+	}
+	if installment.Status != models.InstallmentBilled {
+		return nil, errors.NewBadRequestError("只能登记已出账的账单")
+	}
+
+	now := time.Now()
+	installment.Status = models.InstallmentPaid
+	installment.PaidAt = &now
+	if err := s.installmentRepo.Update(installment); err != nil {
+		return nil, errors.NewDatabaseError("pay installment", err)
+	}
+
+	unpaid, err := s.installmentRepo.CountUnpaid(booking.ID.Int64())
+	if err != nil {
+		return nil, errors.NewDatabaseError("count unpaid installments", err)
+	}
+	if unpaid == 0 {
+		if err := s.bookingRepo.UpdatePaymentStatus(booking.ID.Int64(), "paid"); err != nil {
+			return nil, errors.NewDatabaseError("update payment status", err)
+		}
+	}
+	return installment, nil
+}
+
+// CancelBills 预订取消时作废尚未支付的账单
+func (s *BillingService) CancelBills(bookingID int64) error {
+	if err := s.installmentRepo.CancelUnpaid(bookingID); err != nil {
+		return errors.NewDatabaseError("cancel installments", err)
+	}
+	return nil
+}
@@ -1,10 +1,13 @@
 package service
 
 import (
+	"fmt"
+	"gohotel/internal/config"
 	"gohotel/internal/models"
 	"gohotel/internal/repository"
 	"gohotel/pkg/errors"
 	"gohotel/pkg/utils"
+	"math"
 	"time"
 
 	"gorm.io/gorm"
@@ -12,9 +15,14 @@ import (
 
 // BookingService 预订业务逻辑层
 type BookingService struct {
-	bookingRepo *repository.BookingRepository
-	roomRepo    *repository.RoomRepository
-	userRepo    *repository.UserRepository
+	bookingRepo         *repository.BookingRepository
+	roomRepo            *repository.RoomRepository
+	userRepo            *repository.UserRepository
+	workOrderRepo       *repository.WorkOrderRepository
+	housekeepingService *HousekeepingService
+	pricingService      *PricingService
+	billingService      *BillingService
+	bookingConfig       *config.BookingConfig
 }
 
 // NewBookingService 创建预订服务实例
@@ -22,19 +30,39 @@ func NewBookingService(
 	bookingRepo *repository.BookingRepository,
 	roomRepo *repository.RoomRepository,
 	userRepo *repository.UserRepository,
+	workOrderRepo *repository.WorkOrderRepository,
+	housekeepingService *HousekeepingService,
+	pricingService *PricingService,
+	billingService *BillingService,
+	bookingConfig *config.BookingConfig,
 ) *BookingService {
 	return &BookingService{
-		bookingRepo: bookingRepo,
-		roomRepo:    roomRepo,
-		userRepo:    userRepo,
+		bookingRepo:         bookingRepo,
+		roomRepo:            roomRepo,
+		userRepo:            userRepo,
+		workOrderRepo:       workOrderRepo,
+		housekeepingService: housekeepingService,
+		pricingService:      pricingService,
+		billingService:      billingService,
+		bookingConfig:       bookingConfig,
 	}
 }
 
+// 钟点房限制
+const (
+	hourlyTimeLayout      = "2006-01-02 15:04"
+	maxHourlyBookingHours = 24 // 钟点房单次最多预订小时数，更长请按晚预订
+)
+
 // CreateBookingRequest 创建预订请求
 type CreateBookingRequest struct {
 	RoomID         int64  `json:"room_id" binding:"required"`
-	CheckIn        string `json:"check_in" binding:"required"`  // 格式: "2024-01-01"
-	CheckOut       string `json:"check_out" binding:"required"` // 格式: "2024-01-05"
+	BookingType    string `json:"booking_type" binding:"omitempty,oneof=nightly hourly"` // 预订类型，默认 nightly
+	CheckIn        string `json:"check_in"`                                              // 按晚预订必填，格式: "2024-01-01"
+	CheckOut       string `json:"check_out"`                                             // 按晚预订必填，格式: "2024-01-05"
+	StartTime      string `json:"start_time"`                                            // 钟点房必填，格式: "2024-01-01 13:00"
+	EndTime        string `json:"end_time"`                                              // 钟点房必填，格式: "2024-01-01 17:00"
+	BillingCycle   string `json:"billing_cycle" binding:"omitempty,oneof=full monthly"`  // 付款方式，默认 full；连住 30 晚以上可按月付款
 	GuestName      string `json:"guest_name" binding:"required"`
 	GuestPhone     string `json:"guest_phone" binding:"required"`
 	GuestIDCard    string `json:"guest_id_card"`   // 入住人身份证号，可选
@@ -42,85 +70,227 @@ type CreateBookingRequest struct {
 }
 
 // CreateBooking 创建预订
+// 支持按晚预订和钟点房预订，两种预订在同一房间上按实际占用时段检查冲突
 func (s *BookingService) CreateBooking(userID int64, req *CreateBookingRequest) (*models.Booking, error) {
-	// 1. 验证日期格式
+	// 1. 查询房间是否存在
+	room, err := s.roomRepo.FindByID(uint(req.RoomID))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("房间不存在")
+		}
+		return nil, errors.NewDatabaseError("find room", err)
+	}
+
+	// 2. 检查房间状态
+	if !room.IsAvailable() {
+		return nil, errors.NewBadRequestError("房间不可用")
+	}
+
+	// 3. 按预订类型校验时间并计算总价
+	var booking *models.Booking
+	var quote *StayQuote
+	if req.BookingType == models.BookingTypeHourly {
+		booking, err = s.newHourlyBooking(room, req)
+	} else {
+		booking, quote, err = s.newNightlyBooking(room, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	booking.RoomID = req.RoomID
+
+	// 当天入住要求房间已清洁并通过查房，之后日期的预订只受已有预订和维修停用时段限制
+	today := time.Now().Truncate(24 * time.Hour)
+	if !booking.CheckIn.After(today) && !room.IsInspected() {
+		return nil, errors.NewBadRequestError("房间尚未完成清洁查房，暂不能预订今日入住")
+	}
+
+	// 4. 检查房间在所选时段是否已被预订
+	if err := s.checkRoomSchedule(booking); err != nil {
+		return nil, err
+	}
+
+	// 检查房间在所选时段是否处于维修停用时段
+	outOfOrderStart, outOfOrderEnd := booking.CheckIn, booking.CheckOut
+	if booking.IsHourly() {
+		outOfOrderStart, outOfOrderEnd = *booking.StartTime, *booking.EndTime
+	}
+	outOfOrder, err := s.workOrderRepo.ExistsOutOfOrder(room.ID, outOfOrderStart, outOfOrderEnd)
+	if err != nil {
+		return nil, errors.NewDatabaseError("check out of order", err)
+	}
+	if outOfOrder {
+		return nil, errors.NewConflictError("该房间在所选时段维修停用")
+	}
+
+	// 5. 生成订单号和预订ID
+	booking.ID = utils.JSONInt64(utils.GenID())
+	booking.BookingNumber = utils.JSONInt64(utils.GenID())
+
+	// 6. 填写预订信息
+	booking.HotelID = room.HotelID
+	booking.UserID = utils.JSONInt64(userID)
+	booking.GuestName = req.GuestName
+	booking.GuestPhone = req.GuestPhone
+	booking.GuestIDCard = req.GuestIDCard
+	booking.SpecialRequest = req.SpecialRequest
+	booking.Status = "pending"
+	booking.PaymentStatus = "unpaid"
+	if booking.BillingCycle == models.BillingCycleMonthly {
+		booking.Installments = s.billingService.BuildSchedule(booking, quote)
+	}
+
+	// 7. 保存到数据库（分期账单随预订一起创建）
+	if err := s.bookingRepo.Create(booking); err != nil {
+		return nil, errors.NewDatabaseError("create booking", err)
+	}
+
+	// 8. 加载关联的房间信息
+	booking.Room = *room
+
+	return booking, nil
+}
+
+// newNightlyBooking 校验按晚预订的日期，按房价日历逐晚计价并扣除连住折扣
+func (s *BookingService) newNightlyBooking(room *models.Room, req *CreateBookingRequest) (*models.Booking, *StayQuote, error) {
 	checkIn, err := time.Parse("2006-01-02", req.CheckIn)
 	if err != nil {
-		return nil, errors.NewBadRequestError("入住日期格式错误，应为: YYYY-MM-DD")
+		return nil, nil, errors.NewBadRequestError("入住日期格式错误，应为: YYYY-MM-DD")
 	}
 
 	checkOut, err := time.Parse("2006-01-02", req.CheckOut)
 	if err != nil {
-		return nil, errors.NewBadRequestError("退房日期格式错误，应为: YYYY-MM-DD")
+		return nil, nil, errors.NewBadRequestError("退房日期格式错误，应为: YYYY-MM-DD")
 	}
 
-	// 2. 验证日期逻辑
 	now := time.Now().Truncate(24 * time.Hour) // 去掉时间部分，只保留日期
 	if checkIn.Before(now) {
-		return nil, errors.NewBadRequestError("入住日期不能早于今天")
+		return nil, nil, errors.NewBadRequestError("入住日期不能早于今天")
 	}
 	if checkOut.Before(checkIn) || checkOut.Equal(checkIn) {
-		return nil, errors.NewBadRequestError("退房日期必须晚于入住日期")
+		return nil, nil, errors.NewBadRequestError("退房日期必须晚于入住日期")
 	}
 
-	// 3. 查询房间是否存在
-	room, err := s.roomRepo.FindByID(uint(req.RoomID))
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, errors.NewNotFoundError("房间不存在")
+	totalDays := int(checkOut.Sub(checkIn).Hours() / 24)
+	billingCycle := models.BillingCycleFull
+	if req.BillingCycle == models.BillingCycleMonthly {
+		if totalDays < monthlyBillingMinNights {
+			return nil, nil, errors.NewBadRequestError(fmt.Sprintf("连住满%d晚才可按月付款", monthlyBillingMinNights))
 		}
-		return nil, errors.NewDatabaseError("find room", err)
+		billingCycle = models.BillingCycleMonthly
 	}
 
-	// 4. 检查房间状态
-	if !room.IsAvailable() {
-		return nil, errors.NewBadRequestError("房间不可用")
-	}
-
-	// 5. 检查房间在指定日期是否已被预订
-	available, err := s.bookingRepo.CheckRoomAvailability(req.RoomID, checkIn, checkOut)
+	quote, err := s.pricingService.QuoteStayDetail(room, checkIn, checkOut)
 	if err != nil {
-		return nil, errors.NewDatabaseError("check availability", err)
-	}
-	if !available {
-		return nil, errors.NewConflictError("该房间在所选日期已被预订")
+		return nil, nil, err
 	}
 
-	// 6. 计算总天数和总价
-	totalDays := int(checkOut.Sub(checkIn).Hours() / 24)
-	totalPrice := float64(totalDays) * room.Price
-
-	// 7. 生成订单号和预订ID
-	bookingNumber := utils.GenID()
-	bookingID := utils.GenID()
-
-	// 8. 创建预订对象
-	booking := &models.Booking{
-		ID:             utils.JSONInt64(bookingID),
-		BookingNumber:  utils.JSONInt64(bookingNumber),
-		UserID:         utils.JSONInt64(userID),
-		RoomID:         req.RoomID,
+	return &models.Booking{
+		BookingType:    models.BookingTypeNightly,
 		CheckIn:        checkIn,
 		CheckOut:       checkOut,
 		TotalDays:      totalDays,
-		TotalPrice:     totalPrice,
-		GuestName:      req.GuestName,
-		GuestPhone:     req.GuestPhone,
-		GuestIDCard:    req.GuestIDCard,
-		SpecialRequest: req.SpecialRequest,
-		Status:         "pending",
-		PaymentStatus:  "unpaid",
+		TotalPrice:     quote.Total,
+		DiscountAmount: quote.DiscountAmount,
+		BillingCycle:   billingCycle,
+	}, quote, nil
+}
+
+// newHourlyBooking 校验钟点房的起止时间，按小时计价
+// 起止时间为酒店当地时间，与按晚预订的日期一样按 UTC 存储；入住和退房日期都记为开始时间所在日期，不占用整晚
+func (s *BookingService) newHourlyBooking(room *models.Room, req *CreateBookingRequest) (*models.Booking, error) {
+	if !room.IsHourlyAvailable() {
+		return nil, errors.NewBadRequestError("该房间未开放钟点房")
+	}
+	if req.BillingCycle == models.BillingCycleMonthly {
+		return nil, errors.NewBadRequestError("钟点房不支持按月付款")
 	}
 
-	// 9. 保存到数据库
-	if err := s.bookingRepo.Create(booking); err != nil {
-		return nil, errors.NewDatabaseError("create booking", err)
+	startTime, err := time.Parse(hourlyTimeLayout, req.StartTime)
+	if err != nil {
+		return nil, errors.NewBadRequestError("开始时间格式错误，应为: YYYY-MM-DD HH:MM")
+	}
+	endTime, err := time.Parse(hourlyTimeLayout, req.EndTime)
+	if err != nil {
+		return nil, errors.NewBadRequestError("结束时间格式错误，应为: YYYY-MM-DD HH:MM")
 	}
 
-	// 10. 加载关联的房间信息
-	booking.Room = *room
+	now := time.Now()
+	localNow := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), 0, 0, time.UTC)
+	if startTime.Before(localNow) {
+		return nil, errors.NewBadRequestError("开始时间不能早于当前时间")
+	}
+	if !endTime.After(startTime) {
+		return nil, errors.NewBadRequestError("结束时间必须晚于开始时间")
+	}
 
-	return booking, nil
+	duration := endTime.Sub(startTime)
+	if duration%time.Hour != 0 {
+		return nil, errors.NewBadRequestError("钟点房按整小时预订")
+	}
+	hours := int(duration / time.Hour)
+	minHours := room.HourlyMinHours
+	if minHours < 1 {
+		minHours = 1
+	}
+	if hours < minHours {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("该房间钟点房最少预订%d小时", minHours))
+	}
+	if hours > maxHourlyBookingHours {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("钟点房最多预订%d小时，更长请按晚预订", maxHourlyBookingHours))
+	}
+
+	date := time.Date(startTime.Year(), startTime.Month(), startTime.Day(), 0, 0, 0, 0, time.UTC)
+	return &models.Booking{
+		BookingType:  models.BookingTypeHourly,
+		CheckIn:      date,
+		CheckOut:     date,
+		StartTime:    &startTime,
+		EndTime:      &endTime,
+		TotalHours:   hours,
+		TotalPrice:   math.Round(float64(hours)*room.HourlyPrice*100) / 100,
+		BillingCycle: models.BillingCycleFull,
+	}, nil
+}
+
+// checkRoomSchedule 检查预订与房间其他有效预订的占用时段是否冲突
+// 按晚预订占用入住日入住时间到退房日退房时间；钟点房与前后住客之间需要预留清洁时间
+func (s *BookingService) checkRoomSchedule(booking *models.Booking) error {
+	start, end := s.stayWindow(booking)
+
+	// 钟点房最长 24 小时，前后各多查两天即可覆盖所有可能冲突的预订
+	from := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -2)
+	to := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 2)
+	existing, err := s.bookingRepo.FindActiveByRoomsAndDateRange([]int64{booking.RoomID}, from, to)
+	if err != nil {
+		return errors.NewDatabaseError("check availability", err)
+	}
+
+	for i := range existing {
+		other := &existing[i]
+		otherStart, otherEnd := s.stayWindow(other)
+
+		buffer := time.Duration(0)
+		if booking.IsHourly() || other.IsHourly() {
+			buffer = s.bookingConfig.CleaningBuffer
+		}
+		if start.Before(otherEnd.Add(buffer)) && otherStart.Before(end.Add(buffer)) {
+			if buffer > 0 {
+				return errors.NewConflictError(fmt.Sprintf("该房间在所选时段已被预订（前后需预留%v清洁时间）", buffer))
+			}
+			return errors.NewConflictError("该房间在所选日期已被预订")
+		}
+	}
+	return nil
+}
+
+// stayWindow 返回预订实际占用房间的时间段
+func (s *BookingService) stayWindow(booking *models.Booking) (time.Time, time.Time) {
+	if booking.IsHourly() && booking.StartTime != nil && booking.EndTime != nil {
+		return *booking.StartTime, *booking.EndTime
+	}
+	return booking.CheckIn.Add(time.Duration(s.bookingConfig.CheckInHour) * time.Hour),
+		booking.CheckOut.Add(time.Duration(s.bookingConfig.CheckOutHour) * time.Hour)
 }
 
 // GetBookingByID 根据 ID 获取预订详情
@@ -143,13 +313,13 @@ func (s *BookingService) GetBookingByID(id int64, userID int64) (*models.Booking
 }
 
 // GetByGuestInfo 通过客人姓名、手机号和状态查询预订
-func (s *BookingService) GetByGuestInfo(guestName, guestPhone, status string) ([]models.Booking, error) {
+func (s *BookingService) GetByGuestInfo(hotelID uint, guestName, guestPhone, status string) ([]models.Booking, error) {
 	// 参数验证
 	if guestName == "" && guestPhone == "" {
 		return nil, errors.NewBadRequestError("客人姓名和手机号不能同时为空")
 	}
 
-	bookings, err := s.bookingRepo.FindByGuestInfo(guestName, guestPhone, status)
+	bookings, err := s.bookingRepo.FindByGuestInfo(hotelID, guestName, guestPhone, status)
 	if err != nil {
 		return nil, errors.NewDatabaseError("find by guest info", err)
 	}
@@ -202,16 +372,20 @@ func (s *BookingService) CancelBooking(id int64, userID int64, reason string) er
 		return errors.NewDatabaseError("cancel booking", err)
 	}
 
+	// 5. 作废尚未支付的分期账单
+	if booking.BillingCycle == models.BillingCycleMonthly {
+		if err := s.billingService.CancelBills(id); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-func (s *BookingService) ConfirmBooking(id int64) error {
-	booking, err := s.bookingRepo.FindByID(id)
+func (s *BookingService) ConfirmBooking(hotelID uint, id int64) error {
+	booking, err := s.findHotelBooking(hotelID, id)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.NewNotFoundError("预订不存在")
-		}
-		return errors.NewDatabaseError("find booking", err)
+		return err
 	}
 
 	if !booking.IsPending() {
@@ -226,18 +400,18 @@ func (s *BookingService) ConfirmBooking(id int64) error {
 }
 
 // CheckIn 办理入住（管理员）
-func (s *BookingService) CheckIn(id int64) error {
-	booking, err := s.bookingRepo.FindByID(id)
+func (s *BookingService) CheckIn(hotelID uint, id int64) error {
+	booking, err := s.findHotelBooking(hotelID, id)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.NewNotFoundError("预订不存在")
-		}
-		return errors.NewDatabaseError("find booking", err)
+		return err
 	}
 
 	if !booking.CanCheckIn() {
 		return errors.NewBadRequestError("该预订无法办理入住")
 	}
+	if !booking.Room.IsInspected() {
+		return errors.NewBadRequestError("房间尚未完成清洁查房，暂不能办理入住")
+	}
 
 	// 更新预订状态为入住中
 	if err := s.bookingRepo.UpdateStatus(id, "checkin"); err != nil {
@@ -253,13 +427,10 @@ func (s *BookingService) CheckIn(id int64) error {
 }
 
 // CheckOut 办理退房（管理员）
-func (s *BookingService) CheckOut(id int64) error {
-	booking, err := s.bookingRepo.FindByID(id)
+func (s *BookingService) CheckOut(hotelID uint, id int64) error {
+	booking, err := s.findHotelBooking(hotelID, id)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.NewNotFoundError("预订不存在")
-		}
-		return errors.NewDatabaseError("find booking", err)
+		return err
 	}
 
 	if booking.Status != "checkin" {
@@ -271,16 +442,21 @@ func (s *BookingService) CheckOut(id int64) error {
 		return errors.NewDatabaseError("check out", err)
 	}
 
-	// 更新房间状态为可用
+	// 更新房间状态为空闲（清洁状态为脏房，查房通过后才可售卖）
 	if err := s.roomRepo.UpdateStatus(uint(booking.RoomID), "available"); err != nil {
 		return errors.NewDatabaseError("update room status", err)
 	}
 
+	// 自动生成退房清洁任务
+	if err := s.housekeepingService.CreateCheckoutTask(uint(booking.RoomID), id); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // ListAllBookings 获取所有预订列表（管理员）
-func (s *BookingService) ListAllBookings(page, pageSize int) ([]models.Booking, int64, error) {
+func (s *BookingService) ListAllBookings(hotelID uint, page, pageSize int) ([]models.Booking, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -288,7 +464,7 @@ func (s *BookingService) ListAllBookings(page, pageSize int) ([]models.Booking,
 		pageSize = 10
 	}
 
-	bookings, total, err := s.bookingRepo.FindAll(page, pageSize)
+	bookings, total, err := s.bookingRepo.FindAll(hotelID, page, pageSize)
 	if err != nil {
 		return nil, 0, errors.NewDatabaseError("list bookings", err)
 	}
@@ -297,11 +473,38 @@ func (s *BookingService) ListAllBookings(page, pageSize int) ([]models.Booking,
 }
 
 // GetBookingsByRoomNumberAndStatus 根据房间号和状态获取预订列表（管理员）
-func (s *BookingService) GetBookingsByRoomNumberAndStatus(roomNumber string, status string) ([]models.Booking, error) {
-	bookings, err := s.bookingRepo.FindByRoomNumberAndStatus(roomNumber, status)
+func (s *BookingService) GetBookingsByRoomNumberAndStatus(hotelID uint, roomNumber string, status string) ([]models.Booking, error) {
+	bookings, err := s.bookingRepo.FindByRoomNumberAndStatus(hotelID, roomNumber, status)
 	if err != nil {
 		return nil, errors.NewDatabaseError("find bookings by room number and status", err)
 	}
 
 	return bookings, nil
 }
+
+// PayInstallment 登记按月付款预订的账单已支付（管理员）
+func (s *BookingService) PayInstallment(hotelID uint, id int64, installmentID uint) (*models.BookingInstallment, error) {
+	booking, err := s.findHotelBooking(hotelID, id)
+	if err != nil {
+		return nil, err
+	}
+	if booking.IsCancelled() {
+		return nil, errors.NewBadRequestError("预订已取消")
+	}
+	return s.billingService.PayInstallment(booking, installmentID)
+}
+
+// findHotelBooking 查找酒店内的预订，预订属于其他酒店时视为不存在
+func (s *BookingService) findHotelBooking(hotelID uint, id int64) (*models.Booking, error) {
+	booking, err := s.bookingRepo.FindByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("预订不存在")
+		}
+		return nil, errors.NewDatabaseError("find booking", err)
+	}
+	if booking.HotelID != hotelID {
+		return nil, errors.NewNotFoundError("预订不存在")
+	}
+	return booking, nil
+}
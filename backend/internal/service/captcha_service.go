@@ -0,0 +1,169 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"gohotel/internal/config"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 验证码图片尺寸
+const (
+	captchaWidth  = 120
+	captchaHeight = 40
+)
+
+// CaptchaStore 验证码答案存储
+// 默认使用进程内存储，多实例部署时可替换为 Redis 等共享存储
+type CaptchaStore interface {
+	// Set 保存答案，超过 ttl 后失效；存储已满时返回 false
+	Set(id, answer string, ttl time.Duration) bool
+	// Take 取出并删除答案，不存在或已过期时返回 false
+	Take(id string) (string, bool)
+}
+
+// MemoryCaptchaStore 进程内的验证码存储，最多保存 maxItems 个未过期的答案
+type MemoryCaptchaStore struct {
+	items     map[string]memoryCaptchaItem
+	maxItems  int
+	lastPrune time.Time
+	mutex     sync.Mutex
+}
+
+// memoryCaptchaItem 验证码答案及过期时间
+type memoryCaptchaItem struct {
+	answer    string
+	expiresAt time.Time
+}
+
+// NewMemoryCaptchaStore 创建进程内验证码存储，maxItems 为 0 时不限制数量
+func NewMemoryCaptchaStore(maxItems int) *MemoryCaptchaStore {
+	return &MemoryCaptchaStore{items: make(map[string]memoryCaptchaItem), maxItems: maxItems}
+}
+
+// Set 保存答案，每分钟最多顺带清理一次过期答案；达到数量上限时立即清理，仍然已满则拒绝保存
+func (s *MemoryCaptchaStore) Set(id, answer string, ttl time.Duration) bool {
+	now := time.Now()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	full := s.maxItems > 0 && len(s.items) >= s.maxItems
+	if full || now.Sub(s.lastPrune) > time.Minute {
+		for key, item := range s.items {
+			if !now.Before(item.expiresAt) {
+				delete(s.items, key)
+			}
+		}
+		s.lastPrune = now
+	}
+	if s.maxItems > 0 && len(s.items) >= s.maxItems {
+		return false
+	}
+	s.items[id] = memoryCaptchaItem{answer: answer, expiresAt: now.Add(ttl)}
+	return true
+}
+
+// Take 取出并删除答案
+func (s *MemoryCaptchaStore) Take(id string) (string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	item, ok := s.items[id]
+	if !ok {
+		return "", false
+	}
+	delete(s.items, id)
+	if !time.Now().Before(item.expiresAt) {
+		return "", false
+	}
+	return item.answer, true
+}
+
+// CaptchaService 图形验证码业务逻辑层
+// 注册必须通过验证码；同一账号登录失败达到阈值后，登录也需要验证码
+type CaptchaService struct {
+	store          CaptchaStore
+	length         int
+	ttl            time.Duration
+	loginThreshold int
+}
+
+// NewCaptchaService 创建图形验证码服务实例
+func NewCaptchaService(store CaptchaStore, cfg *config.CaptchaConfig) *CaptchaService {
+	return &CaptchaService{
+		store:          store,
+		length:         cfg.Length,
+		ttl:            cfg.TTL,
+		loginThreshold: cfg.LoginThreshold,
+	}
+}
+
+// CaptchaResponse 图形验证码
+type CaptchaResponse struct {
+	CaptchaID string `json:"captcha_id"` // 验证码 ID，提交时与答案一起回传
+	Image     string `json:"image"`      // PNG 图片（data URL）
+	ExpiresIn int64  `json:"expires_in"` // 有效期（秒）
+}
+
+// Generate 生成图形验证码
+func (s *CaptchaService) Generate() (*CaptchaResponse, error) {
+	digits, err := randomDigits(s.length)
+	if err != nil {
+		return nil, errors.NewInternalServerError("生成验证码失败")
+	}
+	img, err := utils.RenderCaptcha(digits, captchaWidth, captchaHeight)
+	if err != nil {
+		return nil, errors.NewInternalServerError("生成验证码失败")
+	}
+
+	id, err := newRandomToken()
+	if err != nil {
+		return nil, errors.NewInternalServerError("生成验证码失败")
+	}
+	if !s.store.Set(id, digits, s.ttl) {
+		return nil, errors.NewTooManyRequestsError("验证码请求过多，请稍后再试")
+	}
+	return &CaptchaResponse{
+		CaptchaID: id,
+		Image:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(img),
+		ExpiresIn: int64(s.ttl / time.Second),
+	}, nil
+}
+
+// Verify 校验验证码，每个验证码只能校验一次，无论是否正确
+func (s *CaptchaService) Verify(id, answer string) error {
+	if id == "" || answer == "" {
+		return errors.NewCaptchaRequiredError("请输入验证码")
+	}
+	expected, ok := s.store.Take(id)
+	if !ok {
+		return errors.NewCaptchaInvalidError("验证码已过期，请刷新")
+	}
+	if strings.TrimSpace(answer) != expected {
+		return errors.NewCaptchaInvalidError("验证码错误")
+	}
+	return nil
+}
+
+// LoginCaptchaRequired 判断账号登录是否需要验证码，failures 为账号在统计窗口内的连续失败次数
+func (s *CaptchaService) LoginCaptchaRequired(failures int) bool {
+	return failures >= s.loginThreshold
+}
+
+// randomDigits 使用加密随机数生成数字串，保证答案不可预测
+func randomDigits(n int) (string, error) {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		d, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(byte('0' + d.Int64()))
+	}
+	return b.String(), nil
+}
@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"gohotel/internal/config"
+	"gohotel/pkg/errors"
 	"gohotel/pkg/logger"
 	"io"
 	"mime/multipart"
@@ -410,3 +412,61 @@ func (s *CosService) FileExists(objectKey string) (bool, error) {
 
 	return true, nil
 }
+
+// confirmTempUploads 将一组临时文件确认为正式文件
+// 任意一个确认失败时，删除本次已确认的文件，避免产生无人引用的文件
+func confirmTempUploads(cosService *CosService, tempURLs []string) ([]string, error) {
+	if len(tempURLs) == 0 {
+		return nil, nil
+	}
+	if cosService == nil {
+		return nil, errors.NewInternalServerError("图片服务不可用")
+	}
+
+	urls := make([]string, 0, len(tempURLs))
+	for _, tempURL := range tempURLs {
+		u, err := cosService.ConfirmUpload(tempURL)
+		if err != nil {
+			deleteCosFiles(cosService, urls)
+			return nil, errors.NewBadRequestError("确认图片上传失败: " + err.Error())
+		}
+		urls = append(urls, u)
+	}
+	return urls, nil
+}
+
+// deleteCosFiles 尽力删除 COS 中的文件，失败只记录日志
+func deleteCosFiles(cosService *CosService, urls []string) {
+	if cosService == nil {
+		return
+	}
+	for _, u := range urls {
+		if err := cosService.DeleteFile(u); err != nil {
+			logger.Error("删除COS文件失败", zap.String("url", u), zap.Error(err))
+		}
+	}
+}
+
+// encodePhotos 将照片列表编码为 JSON 字符串
+func encodePhotos(photos []string) (string, error) {
+	if len(photos) == 0 {
+		return "[]", nil
+	}
+	data, err := json.Marshal(photos)
+	if err != nil {
+		return "", errors.NewInternalServerError("照片编码失败")
+	}
+	return string(data), nil
+}
+
+// decodePhotos 解析 JSON 字符串中的照片列表，格式错误时返回空列表
+func decodePhotos(data string) []string {
+	var photos []string
+	if data == "" {
+		return photos
+	}
+	if err := json.Unmarshal([]byte(data), &photos); err != nil {
+		return nil
+	}
+	return photos
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"fmt"
+	"gohotel/internal/config"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/pkg/errors"
+	"math"
+	"time"
+)
+
+// evacuationExitType 安全出口的设施类型
+const evacuationExitType = "exit"
+
+// EvacuationService 疏散图业务逻辑层
+// 以走廊为可通行区域，计算楼层每个房间到最近安全出口的路线，并标记超出最大疏散距离的房间
+type EvacuationService struct {
+	roomRepo     *repository.RoomRepository
+	facilityRepo *repository.FacilityRepository
+	maxDistance  float64
+}
+
+// NewEvacuationService 创建疏散图服务实例
+func NewEvacuationService(roomRepo *repository.RoomRepository, facilityRepo *repository.FacilityRepository, cfg *config.EvacuationConfig) *EvacuationService {
+	return &EvacuationService{
+		roomRepo:     roomRepo,
+		facilityRepo: facilityRepo,
+		maxDistance:  float64(cfg.MaxDistance),
+	}
+}
+
+// EvacuationRoom 房间的疏散路线
+type EvacuationRoom struct {
+	RoomID       uint           `json:"room_id"`
+	RoomNumber   string         `json:"room_number"`
+	Left         int            `json:"left"`
+	Top          int            `json:"top"`
+	Width        int            `json:"width"`
+	Height       int            `json:"height"`
+	Exit         *RouteFacility `json:"exit"`          // 最近的安全出口，无法到达时为 null
+	Points       []RoutePoint   `json:"points"`        // 折线顶点，从房间中心到出口中心
+	Distance     float64        `json:"distance"`      // 疏散距离（平面图单位）
+	Unreachable  bool           `json:"unreachable"`   // 无法沿走廊到达任何出口
+	ExceedsLimit bool           `json:"exceeds_limit"` // 疏散距离超过最大允许值
+}
+
+// EvacuationPlan 楼层疏散图
+type EvacuationPlan struct {
+	Floor            int                 `json:"floor"`
+	Width            int                 `json:"width"`  // 画布宽度（包含留白）
+	Height           int                 `json:"height"` // 画布高度（包含留白）
+	MaxDistance      float64             `json:"max_distance"`
+	GeneratedAt      time.Time           `json:"generated_at"`
+	Exits            []RouteFacility     `json:"exits"`
+	Facilities       []FloorPlanFacility `json:"facilities"`
+	Rooms            []EvacuationRoom    `json:"rooms"`
+	ExceedingCount   int                 `json:"exceeding_count"`   // 超出最大疏散距离的房间数
+	UnreachableCount int                 `json:"unreachable_count"` // 无法到达出口的房间数
+}
+
+// GetEvacuationPlan 生成楼层疏散图
+// 尚未摆放到平面图上的房间不参与计算
+func (s *EvacuationService) GetEvacuationPlan(hotelID uint, floor int) (*EvacuationPlan, error) {
+	rooms, err := s.roomRepo.FindAllByFilter(hotelID, floor, "")
+	if err != nil {
+		return nil, errors.NewDatabaseError("find rooms", err)
+	}
+	facilities, err := s.facilityRepo.FindByFloor(hotelID, floor)
+	if err != nil {
+		return nil, errors.NewDatabaseError("find facilities", err)
+	}
+	if len(rooms) == 0 && len(facilities) == 0 {
+		return nil, errors.NewNotFoundError("该楼层没有房间和设施")
+	}
+
+	plan := &EvacuationPlan{
+		Floor:       floor,
+		MaxDistance: s.maxDistance,
+		GeneratedAt: time.Now(),
+		Exits:       make([]RouteFacility, 0),
+		Facilities:  make([]FloorPlanFacility, 0, len(facilities)),
+		Rooms:       make([]EvacuationRoom, 0, len(rooms)),
+	}
+
+	var corridors, exitShapes []layoutShape
+	maxX, maxY := 0.0, 0.0
+	for _, f := range facilities {
+		color, ok := planFacilityColors[f.Type]
+		if !ok {
+			color = planFacilityDefaultColor
+		}
+		plan.Facilities = append(plan.Facilities, FloorPlanFacility{
+			ID:       f.ID,
+			Type:     f.Type,
+			Label:    f.Label,
+			Left:     f.Left,
+			Top:      f.Top,
+			Width:    f.Width,
+			Height:   f.Height,
+			Rotation: f.Rotation,
+			Color:    color,
+		})
+		_, _, right, bottom := rotatedBounds(f.Left, f.Top, f.Width, f.Height, f.Rotation)
+		maxX = math.Max(maxX, right)
+		maxY = math.Max(maxY, bottom)
+
+		if f.Width <= 0 || f.Height <= 0 {
+			continue
+		}
+		switch f.Type {
+		case wayfindingCorridorType:
+			corridors = append(corridors, facilityShape(f))
+		case evacuationExitType:
+			exitShapes = append(exitShapes, facilityShape(f))
+			plan.Exits = append(plan.Exits, RouteFacility{ID: f.ID, Type: f.Type, Label: f.Label})
+		}
+	}
+	if len(plan.Exits) == 0 {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("%d 楼尚未标记安全出口", floor))
+	}
+
+	placed := make([]models.Room, 0, len(rooms))
+	roomShapes := make([]layoutShape, 0, len(rooms))
+	for _, room := range rooms {
+		if room.Width <= 0 || room.Height <= 0 {
+			continue
+		}
+		placed = append(placed, room)
+		roomShapes = append(roomShapes, roomShape(room))
+		maxX = math.Max(maxX, float64(room.Left+room.Width))
+		maxY = math.Max(maxY, float64(room.Top+room.Height))
+	}
+
+	// 整个楼层只建一次网格，从所有出口同时搜索
+	routes := findRoutesToGoals(corridors, exitShapes, roomShapes)
+	for i, room := range placed {
+		plan.Rooms = append(plan.Rooms, s.evacuateRoom(room, routes[i], plan.Exits))
+	}
+
+	for _, r := range plan.Rooms {
+		if r.Unreachable {
+			plan.UnreachableCount++
+		} else if r.ExceedsLimit {
+			plan.ExceedingCount++
+		}
+	}
+
+	plan.Width = int(math.Ceil(maxX)) + planPadding
+	plan.Height = int(math.Ceil(maxY)) + planPadding
+	return plan, nil
+}
+
+// evacuateRoom 根据出口到房间的路线生成房间的疏散路线
+func (s *EvacuationService) evacuateRoom(room models.Room, route layoutRoute, exits []RouteFacility) EvacuationRoom {
+	result := EvacuationRoom{
+		RoomID:     room.ID,
+		RoomNumber: room.RoomNumber,
+		Left:       room.Left,
+		Top:        room.Top,
+		Width:      room.Width,
+		Height:     room.Height,
+		Points:     make([]RoutePoint, 0),
+	}
+
+	if !route.OK {
+		result.Unreachable = true
+		return result
+	}
+
+	// 路线是出口到房间的顺序，疏散路线需要反过来
+	points := route.Points
+	for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+		points[i], points[j] = points[j], points[i]
+	}
+	exit := exits[route.StartIndex]
+	result.Exit = &exit
+	result.Points = newRoutePoints(points)
+	result.Distance = roundRouteValue(polylineLength(points))
+	result.ExceedsLimit = s.maxDistance > 0 && result.Distance > s.maxDistance
+	return result
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// 疏散图配色
+const (
+	evacuationRouteColor    = "#389e0d" // 疏散路线
+	evacuationExceedColor   = "#cf1322" // 超出最大疏散距离
+	evacuationRoomFill      = "#ffffff"
+	evacuationExceedFill    = "#fff1f0"
+	evacuationHighlightFill = "#fffbe6"
+)
+
+// RenderEvacuationPlanSVG 将楼层疏散图渲染为可打印的 SVG 文档
+// highlightRoom 不为空时突出显示该房间的位置和疏散路线，便于张贴在房门背后
+func RenderEvacuationPlanSVG(plan *EvacuationPlan, highlightRoom string) []byte {
+	const headerHeight = 40
+	const legendHeight = 50
+	const minWidth = 420 // 保证图例完整显示
+
+	var b strings.Builder
+	width, height := plan.Width, plan.Height+headerHeight+legendHeight
+	if width < minWidth {
+		width = minWidth
+	}
+
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(&b, `<title>%d F evacuation plan</title>`+"\n", plan.Floor)
+	b.WriteString(`<defs>` +
+		`<marker id="arrow" viewBox="0 0 10 10" refX="8" refY="5" markerWidth="6" markerHeight="6" orient="auto-start-reverse"><path d="M0,0 L10,5 L0,10 z" fill="` + evacuationRouteColor + `"/></marker>` +
+		`<marker id="arrow-exceed" viewBox="0 0 10 10" refX="8" refY="5" markerWidth="6" markerHeight="6" orient="auto-start-reverse"><path d="M0,0 L10,5 L0,10 z" fill="` + evacuationExceedColor + `"/></marker>` +
+		`</defs>` + "\n")
+	fmt.Fprintf(&b, `<rect x="0" y="0" width="%d" height="%d" fill="#ffffff"/>`+"\n", width, height)
+	fmt.Fprintf(&b, `<text x="%d" y="26" font-size="18" font-weight="bold" fill="#262626">%d 楼紧急疏散图</text>`+"\n", planPadding, plan.Floor)
+
+	fmt.Fprintf(&b, `<g id="plan" transform="translate(0 %d)">`+"\n", headerHeight)
+
+	// 设施
+	b.WriteString(`<g id="facilities">` + "\n")
+	for _, f := range plan.Facilities {
+		cx := float64(f.Left) + float64(f.Width)/2
+		cy := float64(f.Top) + float64(f.Height)/2
+		transform := ""
+		if f.Rotation != 0 {
+			transform = fmt.Sprintf(` transform="rotate(%d %.1f %.1f)"`, f.Rotation, cx, cy)
+		}
+		label := f.Label
+		if label == "" {
+			label = f.Type
+		}
+		textColor := "#262626"
+		if f.Type == evacuationExitType {
+			textColor = "#ffffff"
+			if f.Label == "" {
+				label = "安全出口"
+			}
+		}
+		fmt.Fprintf(&b, `<g data-facility-id="%d" data-type="%s"%s>`, f.ID, html.EscapeString(f.Type), transform)
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s" stroke="#595959" stroke-width="1"/>`,
+			f.Left, f.Top, f.Width, f.Height, f.Color)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" font-size="10" text-anchor="middle" dominant-baseline="middle" fill="%s">%s</text>`,
+			cx, cy, textColor, html.EscapeString(label))
+		b.WriteString("</g>\n")
+	}
+	b.WriteString("</g>\n")
+
+	// 房间
+	b.WriteString(`<g id="rooms">` + "\n")
+	for _, r := range plan.Rooms {
+		fill, stroke := evacuationRoomFill, "#262626"
+		if r.RoomNumber == highlightRoom {
+			fill = evacuationHighlightFill
+		}
+		if r.ExceedsLimit || r.Unreachable {
+			fill, stroke = evacuationExceedFill, evacuationExceedColor
+		}
+		cx := float64(r.Left) + float64(r.Width)/2
+		fmt.Fprintf(&b, `<g data-room-id="%d">`, r.RoomID)
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s" stroke="%s" stroke-width="1"/>`,
+			r.Left, r.Top, r.Width, r.Height, fill, stroke)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" font-size="12" text-anchor="middle" fill="#262626">%s</text>`,
+			cx, float64(r.Top)+14, html.EscapeString(r.RoomNumber))
+		b.WriteString("</g>\n")
+	}
+	b.WriteString("</g>\n")
+
+	// 疏散路线，突出显示的房间最后绘制，位于最上层
+	b.WriteString(`<g id="routes" fill="none" stroke-linecap="round" stroke-linejoin="round">` + "\n")
+	var highlighted *EvacuationRoom
+	for i := range plan.Rooms {
+		r := &plan.Rooms[i]
+		if r.RoomNumber == highlightRoom {
+			highlighted = r
+			continue
+		}
+		writeEvacuationRoute(&b, r, 2, "0.6")
+	}
+	if highlighted != nil {
+		writeEvacuationRoute(&b, highlighted, 4, "1")
+		cx := float64(highlighted.Left) + float64(highlighted.Width)/2
+		cy := float64(highlighted.Top) + float64(highlighted.Height)/2
+		fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="6" fill="%s"/>`, cx, cy, evacuationExceedColor)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" font-size="12" font-weight="bold" text-anchor="middle" fill="%s">您在此处</text>`+"\n",
+			cx, cy+20, evacuationExceedColor)
+	}
+	b.WriteString("</g>\n")
+	b.WriteString("</g>\n")
+
+	// 图例
+	y := plan.Height + headerHeight + 8
+	b.WriteString(`<g id="legend">` + "\n")
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s" stroke-width="3"/>`,
+		planPadding, y+7, planPadding+24, y+7, evacuationRouteColor)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="12" fill="#262626">疏散路线</text>`, planPadding+30, y+12)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s" stroke-width="3" stroke-dasharray="6 4"/>`,
+		planPadding+110, y+7, planPadding+134, y+7, evacuationExceedColor)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="12" fill="#262626">超出最大疏散距离</text>`, planPadding+140, y+12)
+	fmt.Fprintf(&b, `<rect x="%d" y="%d" width="14" height="14" fill="%s"/>`, planPadding+260, y, planFacilityColors[evacuationExitType])
+	fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="12" fill="#262626">安全出口</text>`+"\n", planPadding+280, y+12)
+
+	note := fmt.Sprintf("最大疏散距离 %.0f", plan.MaxDistance)
+	if flagged := flaggedEvacuationRooms(plan); len(flagged) > 0 {
+		note += "，需关注房间：" + strings.Join(flagged, "、")
+	}
+	fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="12" fill="#595959">%s</text>`+"\n", planPadding, y+34, html.EscapeString(note))
+	b.WriteString("</g>\n")
+
+	b.WriteString("</svg>\n")
+	return []byte(b.String())
+}
+
+// writeEvacuationRoute 绘制单个房间的疏散路线，超出最大距离的路线使用红色虚线
+func writeEvacuationRoute(b *strings.Builder, r *EvacuationRoom, strokeWidth int, opacity string) {
+	if len(r.Points) < 2 {
+		return
+	}
+	points := make([]string, len(r.Points))
+	for i, p := range r.Points {
+		points[i] = fmt.Sprintf("%.1f,%.1f", p.X, p.Y)
+	}
+	color, marker, dash := evacuationRouteColor, "arrow", ""
+	if r.ExceedsLimit {
+		color, marker, dash = evacuationExceedColor, "arrow-exceed", ` stroke-dasharray="6 4"`
+	}
+	fmt.Fprintf(b, `<polyline data-room-id="%d" points="%s" stroke="%s" stroke-width="%d" stroke-opacity="%s"%s marker-end="url(#%s)"/>`+"\n",
+		r.RoomID, strings.Join(points, " "), color, strokeWidth, opacity, dash, marker)
+}
+
+// flaggedEvacuationRooms 返回超出最大疏散距离或无法到达出口的房间号
+func flaggedEvacuationRooms(plan *EvacuationPlan) []string {
+	var rooms []string
+	for _, r := range plan.Rooms {
+		if r.ExceedsLimit || r.Unreachable {
+			rooms = append(rooms, r.RoomNumber)
+		}
+	}
+	return rooms
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"fmt"
 	"gohotel/internal/models"
 	"gohotel/internal/repository"
 	"gohotel/pkg/errors"
@@ -8,12 +9,18 @@ import (
 
 // FacilityService 设施服务层
 type FacilityService struct {
-	facilityRepo *repository.FacilityRepository
+	facilityRepo  *repository.FacilityRepository
+	roomRepo      *repository.RoomRepository
+	layoutService *FloorLayoutService
 }
 
 // NewFacilityService 创建设施服务实例
-func NewFacilityService(facilityRepo *repository.FacilityRepository) *FacilityService {
-	return &FacilityService{facilityRepo: facilityRepo}
+func NewFacilityService(facilityRepo *repository.FacilityRepository, roomRepo *repository.RoomRepository, layoutService *FloorLayoutService) *FacilityService {
+	return &FacilityService{
+		facilityRepo:  facilityRepo,
+		roomRepo:      roomRepo,
+		layoutService: layoutService,
+	}
 }
 
 // CreateFacilityRequest 创建设施请求
@@ -29,15 +36,11 @@ type CreateFacilityRequest struct {
 }
 
 // UpdateFacilityRequest 更新设施请求
+// 坐标通过楼层布局草稿修改，发布后生效
 type UpdateFacilityRequest struct {
-	Type     string `json:"type"`
-	Floor    int    `json:"floor"`
-	Left     int    `json:"left"`
-	Top      int    `json:"top"`
-	Width    int    `json:"width"`
-	Height   int    `json:"height"`
-	Rotation int    `json:"rotation"`
-	Label    string `json:"label"`
+	Type  string `json:"type"`
+	Floor int    `json:"floor"`
+	Label string `json:"label"`
 }
 
 // BatchUpdateFacilityItem 批量更新设施项
@@ -55,9 +58,10 @@ type BatchUpdateFacilitiesRequest struct {
 	Items []BatchUpdateFacilityItem `json:"items" binding:"required"`
 }
 
-// CreateFacility 创建设施
-func (s *FacilityService) CreateFacility(req *CreateFacilityRequest) (*models.Facility, error) {
+// CreateFacility 在酒店内创建设施
+func (s *FacilityService) CreateFacility(hotelID uint, req *CreateFacilityRequest) (*models.Facility, error) {
 	facility := &models.Facility{
+		HotelID:  hotelID,
 		Type:     req.Type,
 		Floor:    req.Floor,
 		Left:     req.Left,
@@ -67,6 +71,9 @@ func (s *FacilityService) CreateFacility(req *CreateFacilityRequest) (*models.Fa
 		Rotation: req.Rotation,
 		Label:    req.Label,
 	}
+	if err := s.validateFacilities(hotelID, []models.Facility{*facility}); err != nil {
+		return nil, err
+	}
 	if err := s.facilityRepo.Create(facility); err != nil {
 		return nil, errors.NewDatabaseError("create facility", err)
 	}
@@ -74,32 +81,23 @@ func (s *FacilityService) CreateFacility(req *CreateFacilityRequest) (*models.Fa
 }
 
 // UpdateFacility 更新设施
-func (s *FacilityService) UpdateFacility(id uint, req *UpdateFacilityRequest) (*models.Facility, error) {
-	facility, err := s.facilityRepo.FindByID(id)
+// 更换楼层后设施变为未摆放状态，需要在新楼层的布局草稿中重新摆放
+func (s *FacilityService) UpdateFacility(hotelID, id uint, req *UpdateFacilityRequest) (*models.Facility, error) {
+	facility, err := s.FindFacilityByID(hotelID, id)
 	if err != nil {
-		return nil, errors.NewNotFoundError("设施不存在")
+		return nil, err
 	}
 
 	// 更新所有字段
 	if req.Type != "" {
 		facility.Type = req.Type
 	}
-	if req.Floor != 0 {
+	if req.Floor != 0 && req.Floor != facility.Floor {
 		facility.Floor = req.Floor
+		facility.Left, facility.Top = 0, 0
+		facility.Width, facility.Height = 0, 0
+		facility.Rotation = 0
 	}
-	if req.Left != 0 {
-		facility.Left = req.Left
-	}
-	if req.Top != 0 {
-		facility.Top = req.Top
-	}
-	if req.Width != 0 {
-		facility.Width = req.Width
-	}
-	if req.Height != 0 {
-		facility.Height = req.Height
-	}
-	facility.Rotation = req.Rotation
 	facility.Label = req.Label
 
 	if err := s.facilityRepo.Update(facility); err != nil {
@@ -109,11 +107,10 @@ func (s *FacilityService) UpdateFacility(id uint, req *UpdateFacilityRequest) (*
 }
 
 // DeleteFacility 删除设施
-func (s *FacilityService) DeleteFacility(id uint) error {
+func (s *FacilityService) DeleteFacility(hotelID, id uint) error {
 	// 先检查设施是否存在
-	_, err := s.facilityRepo.FindByID(id)
-	if err != nil {
-		return errors.NewNotFoundError("设施不存在")
+	if _, err := s.FindFacilityByID(hotelID, id); err != nil {
+		return err
 	}
 	if err := s.facilityRepo.Delete(id); err != nil {
 		return errors.NewDatabaseError("delete facility", err)
@@ -121,18 +118,18 @@ func (s *FacilityService) DeleteFacility(id uint) error {
 	return nil
 }
 
-// FindFacilityByID 根据 ID 查找设施
-func (s *FacilityService) FindFacilityByID(id uint) (*models.Facility, error) {
+// FindFacilityByID 根据 ID 查找酒店内的设施
+func (s *FacilityService) FindFacilityByID(hotelID, id uint) (*models.Facility, error) {
 	facility, err := s.facilityRepo.FindByID(id)
-	if err != nil {
+	if err != nil || facility.HotelID != hotelID {
 		return nil, errors.NewNotFoundError("设施不存在")
 	}
 	return facility, nil
 }
 
 // FindAllFacilities 查询所有设施（分页）
-func (s *FacilityService) FindAllFacilities(page, pageSize int) ([]models.Facility, int64, error) {
-	facilities, total, err := s.facilityRepo.FindAll(page, pageSize)
+func (s *FacilityService) FindAllFacilities(hotelID uint, page, pageSize int) ([]models.Facility, int64, error) {
+	facilities, total, err := s.facilityRepo.FindAll(hotelID, page, pageSize)
 	if err != nil {
 		return nil, 0, errors.NewDatabaseError("find all facilities", err)
 	}
@@ -140,29 +137,88 @@ func (s *FacilityService) FindAllFacilities(page, pageSize int) ([]models.Facili
 }
 
 // FindFacilitiesByFloor 按楼层查询设施
-func (s *FacilityService) FindFacilitiesByFloor(floor int) ([]models.Facility, error) {
-	facilities, err := s.facilityRepo.FindByFloor(floor)
+func (s *FacilityService) FindFacilitiesByFloor(hotelID uint, floor int) ([]models.Facility, error) {
+	facilities, err := s.facilityRepo.FindByFloor(hotelID, floor)
 	if err != nil {
 		return nil, errors.NewDatabaseError("find facilities by floor", err)
 	}
 	return facilities, nil
 }
 
-// BatchUpdateFacilities 批量更新设施位置
-func (s *FacilityService) BatchUpdateFacilities(req *BatchUpdateFacilitiesRequest) error {
-	facilities := make([]models.Facility, len(req.Items))
+// BatchUpdateFacilities 批量调整设施位置，改动按楼层保存到布局草稿，发布后才生效
+// 保存前校验所在楼层的布局，存在重叠或越界时拒绝保存
+func (s *FacilityService) BatchUpdateFacilities(userID int64, hotelID uint, req *BatchUpdateFacilitiesRequest) ([]*FloorLayoutVersionDetail, error) {
+	if len(req.Items) == 0 {
+		return []*FloorLayoutVersionDetail{}, nil
+	}
+
+	ids := make([]uint, len(req.Items))
 	for i, item := range req.Items {
-		facilities[i] = models.Facility{
+		ids[i] = item.ID
+	}
+	existing, err := s.facilityRepo.FindByIDs(hotelID, ids)
+	if err != nil {
+		return nil, errors.NewDatabaseError("find facilities", err)
+	}
+	floorOf := make(map[uint]int, len(existing))
+	for _, f := range existing {
+		floorOf[f.ID] = f.Floor
+	}
+
+	floors := make(map[int]*SaveLayoutDraftRequest)
+	for _, item := range req.Items {
+		floor, ok := floorOf[item.ID]
+		if !ok {
+			return nil, errors.NewNotFoundError(fmt.Sprintf("设施 #%d 不存在", item.ID))
+		}
+		if floors[floor] == nil {
+			floors[floor] = &SaveLayoutDraftRequest{}
+		}
+		floors[floor].Facilities = append(floors[floor].Facilities, LayoutFacilityItem{
 			ID:       item.ID,
 			Left:     item.Left,
 			Top:      item.Top,
 			Width:    item.Width,
 			Height:   item.Height,
 			Rotation: item.Rotation,
-		}
+		})
+	}
+
+	return s.layoutService.StageDrafts(userID, hotelID, floors)
+}
+
+// validateFacilities 校验改动的设施与所在楼层其他房间、设施是否冲突
+// changed 中 ID 为 0 的视为新建设施
+func (s *FacilityService) validateFacilities(hotelID uint, changed []models.Facility) error {
+	byFloor := make(map[int][]models.Facility)
+	changes := newLayoutChanges()
+	for _, f := range changed {
+		byFloor[f.Floor] = append(byFloor[f.Floor], f)
+		changes.Facilities[f.ID] = true
 	}
-	if err := s.facilityRepo.BatchUpdate(facilities); err != nil {
-		return errors.NewDatabaseError("batch update facilities", err)
+
+	for floor, items := range byFloor {
+		rooms, err := s.roomRepo.FindAllByFilter(hotelID, floor, "")
+		if err != nil {
+			return errors.NewDatabaseError("find rooms", err)
+		}
+		current, err := s.facilityRepo.FindByFloor(hotelID, floor)
+		if err != nil {
+			return errors.NewDatabaseError("find facilities", err)
+		}
+
+		// 用改动后的设施替换当前楼层中的同一设施（包括从其他楼层移入的设施）
+		facilities := make([]models.Facility, 0, len(current)+len(items))
+		for _, f := range current {
+			if !changes.Facilities[f.ID] {
+				facilities = append(facilities, f)
+			}
+		}
+		facilities = append(facilities, items...)
+
+		if err := layoutValidationError(validateFloorLayout(rooms, facilities, changes)); err != nil {
+			return err
+		}
 	}
 	return nil
 }
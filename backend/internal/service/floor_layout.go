@@ -0,0 +1,248 @@
+package service
+
+import (
+	"fmt"
+	"gohotel/internal/models"
+	"gohotel/pkg/errors"
+	"math"
+)
+
+// 布局元素类型
+const (
+	LayoutKindRoom     = "room"
+	LayoutKindFacility = "facility"
+)
+
+// 布局校验问题类型
+const (
+	LayoutIssueInvalidSize = "invalid_size"  // 宽高不合法
+	LayoutIssueOutOfBounds = "out_of_bounds" // 超出画布（出现负坐标）
+	LayoutIssueCollision   = "collision"     // 与其他元素重叠
+)
+
+// layoutEpsilon 重叠判定容差，边与边恰好相接不算重叠
+const layoutEpsilon = 0.5
+
+// LayoutItemRef 布局元素引用
+type LayoutItemRef struct {
+	Kind  string `json:"kind"` // room 或 facility
+	ID    uint   `json:"id"`
+	Label string `json:"label"` // 房间号或设施标签，便于前端提示
+}
+
+// LayoutIssue 布局校验发现的问题
+type LayoutIssue struct {
+	Type    string          `json:"type"` // invalid_size, out_of_bounds, collision
+	Message string          `json:"message"`
+	Items   []LayoutItemRef `json:"items"`
+}
+
+// LayoutValidationResult 布局校验结果
+type LayoutValidationResult struct {
+	Valid  bool          `json:"valid"`
+	Issues []LayoutIssue `json:"issues"`
+}
+
+// layoutShape 参与校验的矩形（房间或设施），按 Rotation 绕中心旋转
+type layoutShape struct {
+	Ref      LayoutItemRef
+	Left     int
+	Top      int
+	Width    int
+	Height   int
+	Rotation int
+	Changed  bool // 是否为本次改动的元素
+}
+
+// layoutChanges 本次改动的房间和设施 ID，新建设施的 ID 为 0
+type layoutChanges struct {
+	Rooms      map[uint]bool
+	Facilities map[uint]bool
+}
+
+// newLayoutChanges 创建空的改动集合
+func newLayoutChanges() layoutChanges {
+	return layoutChanges{Rooms: make(map[uint]bool), Facilities: make(map[uint]bool)}
+}
+
+// layoutPoint 平面坐标点
+type layoutPoint struct {
+	X, Y float64
+}
+
+// corners 返回旋转后矩形的四个顶点（顺时针）
+func (s layoutShape) corners() [4]layoutPoint {
+	cx := float64(s.Left) + float64(s.Width)/2
+	cy := float64(s.Top) + float64(s.Height)/2
+	hw, hh := float64(s.Width)/2, float64(s.Height)/2
+	rad := float64(s.Rotation) * math.Pi / 180
+	cosA, sinA := math.Cos(rad), math.Sin(rad)
+
+	offsets := [4]layoutPoint{{-hw, -hh}, {hw, -hh}, {hw, hh}, {-hw, hh}}
+	var pts [4]layoutPoint
+	for i, o := range offsets {
+		pts[i] = layoutPoint{
+			X: cx + o.X*cosA - o.Y*sinA,
+			Y: cy + o.X*sinA + o.Y*cosA,
+		}
+	}
+	return pts
+}
+
+// validateLayoutShapes 校验布局：尺寸、边界（旋转后不得出现负坐标）以及是否重叠
+// 只校验有改动的元素，以及改动元素与其他元素之间是否重叠，未改动元素之间已有的问题不影响本次改动；
+// 宽高都为 0 的元素视为尚未摆放到平面图上，不参与校验
+func validateLayoutShapes(shapes []layoutShape) *LayoutValidationResult {
+	result := &LayoutValidationResult{Issues: make([]LayoutIssue, 0)}
+
+	valid := make([]layoutShape, 0, len(shapes))
+	for _, s := range shapes {
+		if s.Width == 0 && s.Height == 0 {
+			continue
+		}
+		if s.Width <= 0 || s.Height <= 0 {
+			if !s.Changed {
+				continue
+			}
+			result.Issues = append(result.Issues, LayoutIssue{
+				Type:    LayoutIssueInvalidSize,
+				Message: fmt.Sprintf("%s 的宽高必须大于0", describeLayoutItem(s.Ref)),
+				Items:   []LayoutItemRef{s.Ref},
+			})
+			continue
+		}
+		left, top, _, _ := rotatedBounds(s.Left, s.Top, s.Width, s.Height, s.Rotation)
+		if s.Changed && (left < -layoutEpsilon || top < -layoutEpsilon) {
+			result.Issues = append(result.Issues, LayoutIssue{
+				Type:    LayoutIssueOutOfBounds,
+				Message: fmt.Sprintf("%s 超出画布边界", describeLayoutItem(s.Ref)),
+				Items:   []LayoutItemRef{s.Ref},
+			})
+		}
+		valid = append(valid, s)
+	}
+
+	for i := 0; i < len(valid); i++ {
+		for j := i + 1; j < len(valid); j++ {
+			if !valid[i].Changed && !valid[j].Changed {
+				continue
+			}
+			if shapesOverlap(valid[i], valid[j]) {
+				result.Issues = append(result.Issues, LayoutIssue{
+					Type: LayoutIssueCollision,
+					Message: fmt.Sprintf("%s 与 %s 重叠",
+						describeLayoutItem(valid[i].Ref), describeLayoutItem(valid[j].Ref)),
+					Items: []LayoutItemRef{valid[i].Ref, valid[j].Ref},
+				})
+			}
+		}
+	}
+
+	result.Valid = len(result.Issues) == 0
+	return result
+}
+
+// shapesOverlap 使用分离轴定理判断两个旋转矩形是否重叠
+func shapesOverlap(a, b layoutShape) bool {
+	// 外接矩形不相交时直接返回，减少计算
+	al, at, ar, ab := rotatedBounds(a.Left, a.Top, a.Width, a.Height, a.Rotation)
+	bl, bt, br, bb := rotatedBounds(b.Left, b.Top, b.Width, b.Height, b.Rotation)
+	if ar <= bl+layoutEpsilon || br <= al+layoutEpsilon || ab <= bt+layoutEpsilon || bb <= at+layoutEpsilon {
+		return false
+	}
+
+	pa, pb := a.corners(), b.corners()
+	for _, pts := range [][4]layoutPoint{pa, pb} {
+		for i := 0; i < 2; i++ {
+			// 矩形只需检查两条相邻边的法线
+			edge := layoutPoint{pts[i+1].X - pts[i].X, pts[i+1].Y - pts[i].Y}
+			axis := layoutPoint{-edge.Y, edge.X}
+			length := math.Hypot(axis.X, axis.Y)
+			axis = layoutPoint{axis.X / length, axis.Y / length}
+
+			minA, maxA := projectCorners(pa, axis)
+			minB, maxB := projectCorners(pb, axis)
+			if maxA <= minB+layoutEpsilon || maxB <= minA+layoutEpsilon {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// projectCorners 计算顶点在轴上投影的最小值和最大值
+func projectCorners(pts [4]layoutPoint, axis layoutPoint) (float64, float64) {
+	minV, maxV := math.Inf(1), math.Inf(-1)
+	for _, p := range pts {
+		v := p.X*axis.X + p.Y*axis.Y
+		minV = math.Min(minV, v)
+		maxV = math.Max(maxV, v)
+	}
+	return minV, maxV
+}
+
+// describeLayoutItem 生成布局元素的可读描述
+func describeLayoutItem(ref LayoutItemRef) string {
+	if ref.Kind == LayoutKindRoom {
+		return fmt.Sprintf("房间 %s", ref.Label)
+	}
+	if ref.Label != "" {
+		return fmt.Sprintf("设施 %s(#%d)", ref.Label, ref.ID)
+	}
+	return fmt.Sprintf("设施 #%d", ref.ID)
+}
+
+// roomShape 将房间转换为布局矩形
+func roomShape(room models.Room) layoutShape {
+	return layoutShape{
+		Ref:    LayoutItemRef{Kind: LayoutKindRoom, ID: room.ID, Label: room.RoomNumber},
+		Left:   room.Left,
+		Top:    room.Top,
+		Width:  room.Width,
+		Height: room.Height,
+	}
+}
+
+// facilityShape 将设施转换为布局矩形
+func facilityShape(f models.Facility) layoutShape {
+	label := f.Label
+	if label == "" {
+		label = f.Type
+	}
+	return layoutShape{
+		Ref:      LayoutItemRef{Kind: LayoutKindFacility, ID: f.ID, Label: label},
+		Left:     f.Left,
+		Top:      f.Top,
+		Width:    f.Width,
+		Height:   f.Height,
+		Rotation: f.Rotation,
+	}
+}
+
+// validateFloorLayout 校验一个楼层的房间和设施布局，只校验 changes 中的元素
+func validateFloorLayout(rooms []models.Room, facilities []models.Facility, changes layoutChanges) *LayoutValidationResult {
+	shapes := make([]layoutShape, 0, len(rooms)+len(facilities))
+	for _, r := range rooms {
+		shape := roomShape(r)
+		shape.Changed = changes.Rooms[r.ID]
+		shapes = append(shapes, shape)
+	}
+	for _, f := range facilities {
+		shape := facilityShape(f)
+		shape.Changed = changes.Facilities[f.ID]
+		shapes = append(shapes, shape)
+	}
+	return validateLayoutShapes(shapes)
+}
+
+// layoutValidationError 将校验结果转换为错误响应，校验通过时返回 nil
+func layoutValidationError(result *LayoutValidationResult) error {
+	if result.Valid {
+		return nil
+	}
+	msg := "布局校验未通过：" + result.Issues[0].Message
+	if len(result.Issues) > 1 {
+		msg += fmt.Sprintf("（共 %d 个问题）", len(result.Issues))
+	}
+	return errors.NewBadRequestError(msg)
+}
@@ -0,0 +1,624 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FloorLayoutService 楼层布局版本业务逻辑层
+// 布局编辑先保存为楼层草稿，管理员发布后才写入房间和设施坐标；
+// 每次发布都会保留版本快照，可回滚到任意历史版本
+type FloorLayoutService struct {
+	layoutRepo   *repository.FloorLayoutRepository
+	roomRepo     *repository.RoomRepository
+	facilityRepo *repository.FacilityRepository
+}
+
+// NewFloorLayoutService 创建楼层布局服务实例
+func NewFloorLayoutService(
+	layoutRepo *repository.FloorLayoutRepository,
+	roomRepo *repository.RoomRepository,
+	facilityRepo *repository.FacilityRepository,
+) *FloorLayoutService {
+	return &FloorLayoutService{
+		layoutRepo:   layoutRepo,
+		roomRepo:     roomRepo,
+		facilityRepo: facilityRepo,
+	}
+}
+
+// LayoutRoomItem 布局中的房间坐标
+type LayoutRoomItem struct {
+	ID         uint   `json:"id" binding:"required"`
+	RoomNumber string `json:"room_number"`
+	Left       int    `json:"left"`
+	Top        int    `json:"top"`
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+}
+
+// LayoutFacilityItem 布局中的设施坐标
+type LayoutFacilityItem struct {
+	ID       uint   `json:"id" binding:"required"`
+	Type     string `json:"type"`
+	Label    string `json:"label"`
+	Left     int    `json:"left"`
+	Top      int    `json:"top"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Rotation int    `json:"rotation"`
+}
+
+// LayoutSnapshot 楼层布局快照
+type LayoutSnapshot struct {
+	Rooms      []LayoutRoomItem     `json:"rooms"`
+	Facilities []LayoutFacilityItem `json:"facilities"`
+}
+
+// SaveLayoutDraftRequest 保存布局草稿请求
+// 只需提交有改动的房间和设施，未提交的保持当前坐标
+type SaveLayoutDraftRequest struct {
+	Rooms      []LayoutRoomItem     `json:"rooms" binding:"dive"`
+	Facilities []LayoutFacilityItem `json:"facilities" binding:"dive"`
+	Note       string               `json:"note" binding:"max=255"`
+}
+
+// RollbackLayoutRequest 回滚布局请求
+type RollbackLayoutRequest struct {
+	Note string `json:"note" binding:"max=255"`
+}
+
+// FloorLayoutVersionDetail 布局版本详情（包含快照和校验结果）
+type FloorLayoutVersionDetail struct {
+	models.FloorLayoutVersion
+	Layout     *LayoutSnapshot         `json:"layout"`
+	Validation *LayoutValidationResult `json:"validation"`
+}
+
+// ValidateLayout 校验布局改动（不保存）
+func (s *FloorLayoutService) ValidateLayout(hotelID uint, floor int, req *SaveLayoutDraftRequest) (*LayoutValidationResult, error) {
+	rooms, facilities, changes, err := s.mergeWithCurrent(hotelID, floor, req.Rooms, req.Facilities, true)
+	if err != nil {
+		return nil, err
+	}
+	return validateFloorLayout(rooms, facilities, changes), nil
+}
+
+// SaveDraft 保存楼层布局草稿，每个楼层只保留一个草稿，再次保存会覆盖
+// 校验未通过的布局不能保存
+func (s *FloorLayoutService) SaveDraft(userID int64, hotelID uint, floor int, req *SaveLayoutDraftRequest) (*FloorLayoutVersionDetail, error) {
+	rooms, facilities, changes, err := s.mergeWithCurrent(hotelID, floor, req.Rooms, req.Facilities, true)
+	if err != nil {
+		return nil, err
+	}
+	validation := validateFloorLayout(rooms, facilities, changes)
+	if err := layoutValidationError(validation); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := encodeLayoutSnapshot(rooms, facilities)
+	if err != nil {
+		return nil, err
+	}
+
+	draft, err := s.findDraft(hotelID, floor)
+	if err != nil {
+		return nil, err
+	}
+	if draft == nil {
+		if draft, err = s.newDraft(userID, hotelID, floor); err != nil {
+			return nil, err
+		}
+	}
+	draft.Snapshot = snapshot
+	draft.Note = req.Note
+
+	if draft.ID == 0 {
+		err = s.layoutRepo.Create(draft)
+	} else {
+		err = s.layoutRepo.Update(draft)
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError("save layout draft", err)
+	}
+
+	return s.buildDetail(draft, rooms, facilities, validation), nil
+}
+
+// StageDrafts 将各楼层的布局改动合并到楼层草稿，草稿中已有的其他改动保持不变，楼层没有草稿时基于当前布局新建
+// 供布局编辑器以外的入口（如设施批量调整）修改坐标，同样要发布后才生效；所有楼层校验通过后在一个事务中保存
+func (s *FloorLayoutService) StageDrafts(userID int64, hotelID uint, floors map[int]*SaveLayoutDraftRequest) ([]*FloorLayoutVersionDetail, error) {
+	order := make([]int, 0, len(floors))
+	for floor := range floors {
+		order = append(order, floor)
+	}
+	sort.Ints(order)
+
+	details := make([]*FloorLayoutVersionDetail, 0, len(floors))
+	drafts := make([]*models.FloorLayoutVersion, 0, len(floors))
+	for _, floor := range order {
+		req := floors[floor]
+		draft, err := s.findDraft(hotelID, floor)
+		if err != nil {
+			return nil, err
+		}
+		rooms, facilities, changes, err := s.mergeWithDraft(hotelID, floor, draft)
+		if err != nil {
+			return nil, err
+		}
+		if err := applyLayoutItems(floor, rooms, facilities, req.Rooms, req.Facilities, true, changes); err != nil {
+			return nil, err
+		}
+		validation := validateFloorLayout(rooms, facilities, changes)
+		if err := layoutValidationError(validation); err != nil {
+			return nil, err
+		}
+
+		if draft == nil {
+			if draft, err = s.newDraft(userID, hotelID, floor); err != nil {
+				return nil, err
+			}
+		}
+		if draft.Snapshot, err = encodeLayoutSnapshot(rooms, facilities); err != nil {
+			return nil, err
+		}
+		if req.Note != "" {
+			draft.Note = req.Note
+		}
+		drafts = append(drafts, draft)
+		details = append(details, s.buildDetail(draft, rooms, facilities, validation))
+	}
+
+	if err := s.layoutRepo.SaveDrafts(drafts); err != nil {
+		return nil, errors.NewDatabaseError("save layout drafts", err)
+	}
+	return details, nil
+}
+
+// CreateRooms 创建房间，房间坐标保存到所在楼层的布局草稿
+// 房间先以未摆放（宽高为 0）的状态写入，草稿发布后才出现在平面图上；房间和草稿在一个事务中写入
+func (s *FloorLayoutService) CreateRooms(userID int64, hotelID uint, rooms []*models.Room, note string) error {
+	// 待保存的楼层草稿：rooms、facilities 为草稿发布后的楼层布局，placements 为带坐标的新房间（ID 在创建后补上）
+	type floorDraft struct {
+		draft      *models.FloorLayoutVersion
+		rooms      []models.Room
+		facilities []models.Facility
+		created    []*models.Room
+		placements []models.Room
+	}
+
+	floors := make(map[int]*floorDraft)
+	for _, room := range rooms {
+		fd, ok := floors[room.Floor]
+		if !ok {
+			draft, err := s.findDraft(hotelID, room.Floor)
+			if err != nil {
+				return err
+			}
+			current, facilities, _, err := s.mergeWithDraft(hotelID, room.Floor, draft)
+			if err != nil {
+				return err
+			}
+			if draft == nil {
+				if draft, err = s.newDraft(userID, hotelID, room.Floor); err != nil {
+					return err
+				}
+				draft.Note = note
+			}
+			fd = &floorDraft{draft: draft, rooms: current, facilities: facilities}
+			floors[room.Floor] = fd
+		}
+		fd.created = append(fd.created, room)
+		fd.placements = append(fd.placements, *room)
+	}
+
+	// 新房间的 ID 为 0，校验时全部视为改动的元素
+	for _, fd := range floors {
+		changes := newLayoutChanges()
+		changes.Rooms[0] = true
+		if err := layoutValidationError(validateFloorLayout(append(fd.rooms, fd.placements...), fd.facilities, changes)); err != nil {
+			return err
+		}
+	}
+
+	for _, room := range rooms {
+		room.Left, room.Top, room.Width, room.Height = 0, 0, 0, 0
+	}
+	err := s.layoutRepo.CreateRoomsWithDrafts(rooms, func() ([]*models.FloorLayoutVersion, error) {
+		drafts := make([]*models.FloorLayoutVersion, 0, len(floors))
+		for _, fd := range floors {
+			for i, room := range fd.created {
+				fd.placements[i].ID = room.ID
+			}
+			snapshot, err := encodeLayoutSnapshot(append(fd.rooms, fd.placements...), fd.facilities)
+			if err != nil {
+				return nil, err
+			}
+			fd.draft.Snapshot = snapshot
+			drafts = append(drafts, fd.draft)
+		}
+		return drafts, nil
+	})
+	if err != nil {
+		return errors.NewDatabaseError("create rooms with layout drafts", err)
+	}
+	return nil
+}
+
+// ListVersions 获取楼层的布局版本列表（新版本在前）
+func (s *FloorLayoutService) ListVersions(hotelID uint, floor int) ([]models.FloorLayoutVersion, error) {
+	versions, err := s.layoutRepo.FindByFloor(hotelID, floor)
+	if err != nil {
+		return nil, errors.NewDatabaseError("list layout versions", err)
+	}
+	return versions, nil
+}
+
+// GetVersion 获取布局版本详情
+// 校验结果基于当前楼层状态计算，只校验与当前布局不同的元素（快照中已删除的元素被忽略，新增元素保持当前坐标）
+func (s *FloorLayoutService) GetVersion(hotelID uint, floor int, id uint) (*FloorLayoutVersionDetail, error) {
+	version, err := s.findVersion(hotelID, floor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := decodeLayoutSnapshot(version.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+	rooms, facilities, changes, err := s.mergeWithCurrent(hotelID, floor, snapshot.Rooms, snapshot.Facilities, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildDetail(version, rooms, facilities, validateFloorLayout(rooms, facilities, changes)), nil
+}
+
+// PublishDraft 发布楼层草稿，将草稿坐标写入房间和设施
+func (s *FloorLayoutService) PublishDraft(userID int64, hotelID uint, floor int, id uint) (*FloorLayoutVersionDetail, error) {
+	version, err := s.findVersion(hotelID, floor, id)
+	if err != nil {
+		return nil, err
+	}
+	if !version.IsDraft() {
+		return nil, errors.NewBadRequestError("只能发布草稿版本")
+	}
+
+	return s.publish(userID, version)
+}
+
+// Rollback 回滚到历史版本
+// 以历史版本快照创建一个新版本并立即发布，原有历史记录保持不变
+func (s *FloorLayoutService) Rollback(userID int64, hotelID uint, floor int, id uint, req *RollbackLayoutRequest) (*FloorLayoutVersionDetail, error) {
+	source, err := s.findVersion(hotelID, floor, id)
+	if err != nil {
+		return nil, err
+	}
+	if source.IsDraft() {
+		return nil, errors.NewBadRequestError("草稿版本请直接发布")
+	}
+	if source.IsPublished() {
+		return nil, errors.NewBadRequestError("该版本已是当前发布版本")
+	}
+
+	next, err := s.layoutRepo.NextVersion(hotelID, floor)
+	if err != nil {
+		return nil, errors.NewDatabaseError("next layout version", err)
+	}
+	note := req.Note
+	if note == "" {
+		note = fmt.Sprintf("回滚到版本 %d", source.Version)
+	}
+	version := &models.FloorLayoutVersion{
+		HotelID:   hotelID,
+		Floor:     floor,
+		Version:   next,
+		Status:    "draft",
+		Snapshot:  source.Snapshot,
+		Note:      note,
+		BasedOnID: &source.ID,
+		CreatedBy: utils.JSONInt64(userID),
+	}
+
+	return s.publish(userID, version)
+}
+
+// publish 校验并发布版本
+// 楼层第一次发布时，先把当前线上布局保存为基线版本，保证可以回滚到启用版本管理之前的布局
+func (s *FloorLayoutService) publish(userID int64, version *models.FloorLayoutVersion) (*FloorLayoutVersionDetail, error) {
+	snapshot, err := decodeLayoutSnapshot(version.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+	rooms, facilities, changes, err := s.mergeWithCurrent(version.HotelID, version.Floor, snapshot.Rooms, snapshot.Facilities, false)
+	if err != nil {
+		return nil, err
+	}
+	validation := validateFloorLayout(rooms, facilities, changes)
+	if err := layoutValidationError(validation); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureBaseline(userID, version); err != nil {
+		return nil, err
+	}
+
+	// 快照与当前布局合并后重新保存，去掉已删除的元素、补上新增的元素
+	merged, err := encodeLayoutSnapshot(rooms, facilities)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	publisher := utils.JSONInt64(userID)
+	version.Snapshot = merged
+	version.Status = "published"
+	version.PublishedBy = &publisher
+	version.PublishedAt = &now
+
+	if err := s.layoutRepo.Publish(version, rooms, facilities); err != nil {
+		return nil, errors.NewDatabaseError("publish layout", err)
+	}
+
+	return s.buildDetail(version, rooms, facilities, validation), nil
+}
+
+// ensureBaseline 楼层尚无发布版本时，将当前线上布局保存为已归档的基线版本（版本号 0）
+// 基线已存在（例如之前发布失败）时不再重复创建
+func (s *FloorLayoutService) ensureBaseline(userID int64, version *models.FloorLayoutVersion) error {
+	exists, err := s.layoutRepo.ExistsPublishedOrBaseline(version.HotelID, version.Floor)
+	if err != nil {
+		return errors.NewDatabaseError("check published layout", err)
+	}
+	if exists {
+		return nil
+	}
+
+	rooms, facilities, err := s.loadFloor(version.HotelID, version.Floor)
+	if err != nil {
+		return err
+	}
+	snapshot, err := encodeLayoutSnapshot(rooms, facilities)
+	if err != nil {
+		return err
+	}
+
+	// 基线固定使用版本号 0，正常版本从 1 开始
+	baseline := &models.FloorLayoutVersion{
+		HotelID:   version.HotelID,
+		Floor:     version.Floor,
+		Version:   0,
+		Status:    "archived",
+		Snapshot:  snapshot,
+		Note:      "启用版本管理前的布局",
+		CreatedBy: utils.JSONInt64(userID),
+	}
+	if err := s.layoutRepo.Create(baseline); err != nil {
+		return errors.NewDatabaseError("create layout baseline", err)
+	}
+	return nil
+}
+
+// mergeWithCurrent 将布局改动合并到楼层当前布局，同时返回坐标与当前布局不同的元素
+// strict 为 true 时改动中出现不属于该楼层的元素会报错，否则忽略（用于历史快照中已删除的元素）
+func (s *FloorLayoutService) mergeWithCurrent(hotelID uint, floor int, roomItems []LayoutRoomItem, facilityItems []LayoutFacilityItem, strict bool) ([]models.Room, []models.Facility, layoutChanges, error) {
+	changes := newLayoutChanges()
+	rooms, facilities, err := s.loadFloor(hotelID, floor)
+	if err != nil {
+		return nil, nil, changes, err
+	}
+	if err := applyLayoutItems(floor, rooms, facilities, roomItems, facilityItems, strict, changes); err != nil {
+		return nil, nil, changes, err
+	}
+	return rooms, facilities, changes, nil
+}
+
+// mergeWithDraft 返回楼层草稿发布后的布局（草稿快照合并到当前布局），没有草稿时返回当前布局
+// 楼层还没有房间和设施时返回空布局
+func (s *FloorLayoutService) mergeWithDraft(hotelID uint, floor int, draft *models.FloorLayoutVersion) ([]models.Room, []models.Facility, layoutChanges, error) {
+	changes := newLayoutChanges()
+	rooms, facilities, err := s.findFloorItems(hotelID, floor)
+	if err != nil {
+		return nil, nil, changes, err
+	}
+	if draft == nil {
+		return rooms, facilities, changes, nil
+	}
+
+	snapshot, err := decodeLayoutSnapshot(draft.Snapshot)
+	if err != nil {
+		return nil, nil, changes, err
+	}
+	if err := applyLayoutItems(floor, rooms, facilities, snapshot.Rooms, snapshot.Facilities, false, changes); err != nil {
+		return nil, nil, changes, err
+	}
+	return rooms, facilities, changes, nil
+}
+
+// draftLayout 返回楼层草稿发布后的房间和设施，没有草稿时返回当前布局
+func (s *FloorLayoutService) draftLayout(hotelID uint, floor int) ([]models.Room, []models.Facility, error) {
+	draft, err := s.findDraft(hotelID, floor)
+	if err != nil {
+		return nil, nil, err
+	}
+	rooms, facilities, _, err := s.mergeWithDraft(hotelID, floor, draft)
+	return rooms, facilities, err
+}
+
+// applyLayoutItems 将布局改动写入楼层的房间和设施，并记录坐标有变化的元素
+// strict 为 true 时改动中出现不属于该楼层的元素会报错，否则忽略
+func applyLayoutItems(floor int, rooms []models.Room, facilities []models.Facility, roomItems []LayoutRoomItem, facilityItems []LayoutFacilityItem, strict bool, changes layoutChanges) error {
+	roomIndex := make(map[uint]int, len(rooms))
+	for i, r := range rooms {
+		roomIndex[r.ID] = i
+	}
+	for _, item := range roomItems {
+		i, ok := roomIndex[item.ID]
+		if !ok {
+			if strict {
+				return errors.NewBadRequestError(fmt.Sprintf("房间 #%d 不在 %d 楼", item.ID, floor))
+			}
+			continue
+		}
+		r := &rooms[i]
+		if r.Left != item.Left || r.Top != item.Top || r.Width != item.Width || r.Height != item.Height {
+			changes.Rooms[r.ID] = true
+		}
+		r.Left, r.Top = item.Left, item.Top
+		r.Width, r.Height = item.Width, item.Height
+	}
+
+	facilityIndex := make(map[uint]int, len(facilities))
+	for i, f := range facilities {
+		facilityIndex[f.ID] = i
+	}
+	for _, item := range facilityItems {
+		i, ok := facilityIndex[item.ID]
+		if !ok {
+			if strict {
+				return errors.NewBadRequestError(fmt.Sprintf("设施 #%d 不在 %d 楼", item.ID, floor))
+			}
+			continue
+		}
+		f := &facilities[i]
+		if f.Left != item.Left || f.Top != item.Top || f.Width != item.Width || f.Height != item.Height || f.Rotation != item.Rotation {
+			changes.Facilities[f.ID] = true
+		}
+		f.Left, f.Top = item.Left, item.Top
+		f.Width, f.Height = item.Width, item.Height
+		f.Rotation = item.Rotation
+	}
+	return nil
+}
+
+// loadFloor 读取酒店楼层当前的房间和设施，楼层没有房间和设施时返回 404
+func (s *FloorLayoutService) loadFloor(hotelID uint, floor int) ([]models.Room, []models.Facility, error) {
+	rooms, facilities, err := s.findFloorItems(hotelID, floor)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rooms) == 0 && len(facilities) == 0 {
+		return nil, nil, errors.NewNotFoundError("该楼层没有房间和设施")
+	}
+	return rooms, facilities, nil
+}
+
+// findFloorItems 查询酒店楼层当前的房间和设施
+func (s *FloorLayoutService) findFloorItems(hotelID uint, floor int) ([]models.Room, []models.Facility, error) {
+	rooms, err := s.roomRepo.FindAllByFilter(hotelID, floor, "")
+	if err != nil {
+		return nil, nil, errors.NewDatabaseError("find rooms", err)
+	}
+	facilities, err := s.facilityRepo.FindByFloor(hotelID, floor)
+	if err != nil {
+		return nil, nil, errors.NewDatabaseError("find facilities", err)
+	}
+	return rooms, facilities, nil
+}
+
+// findDraft 查找楼层的草稿版本，没有草稿时返回 nil
+func (s *FloorLayoutService) findDraft(hotelID uint, floor int) (*models.FloorLayoutVersion, error) {
+	draft, err := s.layoutRepo.FindDraft(hotelID, floor)
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError("find layout draft", err)
+	}
+	return draft, nil
+}
+
+// newDraft 为楼层创建新的草稿版本（尚未保存）
+func (s *FloorLayoutService) newDraft(userID int64, hotelID uint, floor int) (*models.FloorLayoutVersion, error) {
+	version, err := s.layoutRepo.NextVersion(hotelID, floor)
+	if err != nil {
+		return nil, errors.NewDatabaseError("next layout version", err)
+	}
+	return &models.FloorLayoutVersion{
+		HotelID:   hotelID,
+		Floor:     floor,
+		Version:   version,
+		Status:    "draft",
+		CreatedBy: utils.JSONInt64(userID),
+	}, nil
+}
+
+// findVersion 查找属于酒店指定楼层的布局版本
+func (s *FloorLayoutService) findVersion(hotelID uint, floor int, id uint) (*models.FloorLayoutVersion, error) {
+	version, err := s.layoutRepo.FindByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("布局版本不存在")
+		}
+		return nil, errors.NewDatabaseError("find layout version", err)
+	}
+	if version.HotelID != hotelID || version.Floor != floor {
+		return nil, errors.NewNotFoundError("布局版本不存在")
+	}
+	return version, nil
+}
+
+// buildDetail 组装布局版本详情
+func (s *FloorLayoutService) buildDetail(version *models.FloorLayoutVersion, rooms []models.Room, facilities []models.Facility, validation *LayoutValidationResult) *FloorLayoutVersionDetail {
+	return &FloorLayoutVersionDetail{
+		FloorLayoutVersion: *version,
+		Layout:             newLayoutSnapshot(rooms, facilities),
+		Validation:         validation,
+	}
+}
+
+// newLayoutSnapshot 根据房间和设施生成布局快照
+func newLayoutSnapshot(rooms []models.Room, facilities []models.Facility) *LayoutSnapshot {
+	snapshot := &LayoutSnapshot{
+		Rooms:      make([]LayoutRoomItem, len(rooms)),
+		Facilities: make([]LayoutFacilityItem, len(facilities)),
+	}
+	for i, r := range rooms {
+		snapshot.Rooms[i] = LayoutRoomItem{
+			ID:         r.ID,
+			RoomNumber: r.RoomNumber,
+			Left:       r.Left,
+			Top:        r.Top,
+			Width:      r.Width,
+			Height:     r.Height,
+		}
+	}
+	for i, f := range facilities {
+		snapshot.Facilities[i] = LayoutFacilityItem{
+			ID:       f.ID,
+			Type:     f.Type,
+			Label:    f.Label,
+			Left:     f.Left,
+			Top:      f.Top,
+			Width:    f.Width,
+			Height:   f.Height,
+			Rotation: f.Rotation,
+		}
+	}
+	return snapshot
+}
+
+// encodeLayoutSnapshot 将布局编码为 JSON 快照
+func encodeLayoutSnapshot(rooms []models.Room, facilities []models.Facility) (string, error) {
+	data, err := json.Marshal(newLayoutSnapshot(rooms, facilities))
+	if err != nil {
+		return "", errors.NewInternalServerError("布局快照编码失败")
+	}
+	return string(data), nil
+}
+
+// decodeLayoutSnapshot 解析 JSON 布局快照
+func decodeLayoutSnapshot(data string) (*LayoutSnapshot, error) {
+	var snapshot LayoutSnapshot
+	if err := json.Unmarshal([]byte(data), &snapshot); err != nil {
+		return nil, errors.NewInternalServerError("布局快照格式错误")
+	}
+	return &snapshot, nil
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/pkg/errors"
+	"math"
+	"time"
+)
+
+// 平面图中房间的实时状态
+const (
+	PlanRoomAvailable  = "available"    // 可售
+	PlanRoomOccupied   = "occupied"     // 在住
+	PlanRoomDirty      = "dirty"        // 待清洁/待查房
+	PlanRoomOutOfOrder = "out_of_order" // 停用（维修等）
+)
+
+// planPadding 平面图画布四周留白
+const planPadding = 20
+
+// planRoomColors 房间实时状态对应的填充颜色
+var planRoomColors = map[string]string{
+	PlanRoomAvailable:  "#52c41a",
+	PlanRoomOccupied:   "#f5222d",
+	PlanRoomDirty:      "#faad14",
+	PlanRoomOutOfOrder: "#8c8c8c",
+}
+
+// planRoomLabels 房间实时状态的图例文字
+var planRoomLabels = map[string]string{
+	PlanRoomAvailable:  "空闲",
+	PlanRoomOccupied:   "在住",
+	PlanRoomDirty:      "待清洁",
+	PlanRoomOutOfOrder: "停用",
+}
+
+// planFacilityColors 设施类型对应的填充颜色，未列出的类型使用默认颜色
+var planFacilityColors = map[string]string{
+	"elevator": "#1890ff",
+	"stairs":   "#722ed1",
+	"corridor": "#f0f0f0",
+	"storage":  "#d4b106",
+	"exit":     "#237804",
+}
+
+// planFacilityDefaultColor 设施默认填充颜色
+const planFacilityDefaultColor = "#d9d9d9"
+
+// FloorPlanService 楼层平面图业务逻辑层
+// 将房间和设施的坐标组合成一张平面图，房间按实时状态着色
+type FloorPlanService struct {
+	roomRepo      *repository.RoomRepository
+	facilityRepo  *repository.FacilityRepository
+	workOrderRepo *repository.WorkOrderRepository
+}
+
+// NewFloorPlanService 创建楼层平面图服务实例
+func NewFloorPlanService(roomRepo *repository.RoomRepository, facilityRepo *repository.FacilityRepository, workOrderRepo *repository.WorkOrderRepository) *FloorPlanService {
+	return &FloorPlanService{
+		roomRepo:      roomRepo,
+		facilityRepo:  facilityRepo,
+		workOrderRepo: workOrderRepo,
+	}
+}
+
+// FloorPlanRoom 平面图中的房间
+type FloorPlanRoom struct {
+	ID                 uint   `json:"id"`
+	RoomNumber         string `json:"room_number"`
+	RoomType           string `json:"room_type"`
+	Left               int    `json:"left"`
+	Top                int    `json:"top"`
+	Width              int    `json:"width"`
+	Height             int    `json:"height"`
+	Status             string `json:"status"`              // 房间状态：available, occupied, maintenance
+	HousekeepingStatus string `json:"housekeeping_status"` // 清洁状态
+	LiveStatus         string `json:"live_status"`         // 实时状态：available, occupied, dirty, out_of_order
+	Color              string `json:"color"`               // 按实时状态计算的填充颜色
+}
+
+// FloorPlanFacility 平面图中的设施
+type FloorPlanFacility struct {
+	ID       uint   `json:"id"`
+	Type     string `json:"type"`
+	Label    string `json:"label"`
+	Left     int    `json:"left"`
+	Top      int    `json:"top"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Rotation int    `json:"rotation"`
+	Color    string `json:"color"`
+}
+
+// FloorPlanLegend 平面图图例
+type FloorPlanLegend struct {
+	Status string `json:"status"`
+	Label  string `json:"label"`
+	Color  string `json:"color"`
+}
+
+// FloorPlan 楼层平面图文档
+type FloorPlan struct {
+	Floor       int                 `json:"floor"`
+	Width       int                 `json:"width"`  // 画布宽度（包含留白）
+	Height      int                 `json:"height"` // 画布高度（包含留白）
+	GeneratedAt time.Time           `json:"generated_at"`
+	Rooms       []FloorPlanRoom     `json:"rooms"`
+	Facilities  []FloorPlanFacility `json:"facilities"`
+	Legend      []FloorPlanLegend   `json:"legend"`
+}
+
+// GetFloorPlan 获取楼层平面图
+func (s *FloorPlanService) GetFloorPlan(hotelID uint, floor int) (*FloorPlan, error) {
+	rooms, err := s.roomRepo.FindAllByFilter(hotelID, floor, "")
+	if err != nil {
+		return nil, errors.NewDatabaseError("find rooms", err)
+	}
+	facilities, err := s.facilityRepo.FindByFloor(hotelID, floor)
+	if err != nil {
+		return nil, errors.NewDatabaseError("find facilities", err)
+	}
+	if len(rooms) == 0 && len(facilities) == 0 {
+		return nil, errors.NewNotFoundError("该楼层没有房间和设施")
+	}
+
+	// 查询今晚处于停用时段的工单
+	today, _ := time.Parse("2006-01-02", time.Now().Format("2006-01-02"))
+	roomIDs := make([]uint, len(rooms))
+	for i, room := range rooms {
+		roomIDs[i] = room.ID
+	}
+	outOfOrder := make(map[uint]bool)
+	if len(roomIDs) > 0 {
+		workOrders, err := s.workOrderRepo.FindOutOfOrderByRoomsAndDateRange(roomIDs, today, today.AddDate(0, 0, 1))
+		if err != nil {
+			return nil, errors.NewDatabaseError("find work orders", err)
+		}
+		for _, wo := range workOrders {
+			if wo.BlocksDate(today) {
+				outOfOrder[wo.RoomID] = true
+			}
+		}
+	}
+
+	plan := &FloorPlan{
+		Floor:       floor,
+		GeneratedAt: time.Now(),
+		Rooms:       make([]FloorPlanRoom, 0, len(rooms)),
+		Facilities:  make([]FloorPlanFacility, 0, len(facilities)),
+	}
+
+	maxX, maxY := 0.0, 0.0
+	for _, room := range rooms {
+		liveStatus := roomLiveStatus(&room, outOfOrder[room.ID])
+		plan.Rooms = append(plan.Rooms, FloorPlanRoom{
+			ID:                 room.ID,
+			RoomNumber:         room.RoomNumber,
+			RoomType:           room.RoomType,
+			Left:               room.Left,
+			Top:                room.Top,
+			Width:              room.Width,
+			Height:             room.Height,
+			Status:             room.Status,
+			HousekeepingStatus: room.HousekeepingStatus,
+			LiveStatus:         liveStatus,
+			Color:              planRoomColors[liveStatus],
+		})
+		maxX = math.Max(maxX, float64(room.Left+room.Width))
+		maxY = math.Max(maxY, float64(room.Top+room.Height))
+	}
+
+	for _, f := range facilities {
+		color, ok := planFacilityColors[f.Type]
+		if !ok {
+			color = planFacilityDefaultColor
+		}
+		plan.Facilities = append(plan.Facilities, FloorPlanFacility{
+			ID:       f.ID,
+			Type:     f.Type,
+			Label:    f.Label,
+			Left:     f.Left,
+			Top:      f.Top,
+			Width:    f.Width,
+			Height:   f.Height,
+			Rotation: f.Rotation,
+			Color:    color,
+		})
+		_, _, right, bottom := rotatedBounds(f.Left, f.Top, f.Width, f.Height, f.Rotation)
+		maxX = math.Max(maxX, right)
+		maxY = math.Max(maxY, bottom)
+	}
+
+	plan.Width = int(math.Ceil(maxX)) + planPadding
+	plan.Height = int(math.Ceil(maxY)) + planPadding
+
+	for _, status := range []string{PlanRoomAvailable, PlanRoomOccupied, PlanRoomDirty, PlanRoomOutOfOrder} {
+		plan.Legend = append(plan.Legend, FloorPlanLegend{
+			Status: status,
+			Label:  planRoomLabels[status],
+			Color:  planRoomColors[status],
+		})
+	}
+
+	return plan, nil
+}
+
+// roomLiveStatus 计算房间实时状态
+// 优先级：停用 > 在住 > 待清洁 > 空闲
+func roomLiveStatus(room *models.Room, blockedByWorkOrder bool) string {
+	switch {
+	case blockedByWorkOrder || room.Status == "maintenance":
+		return PlanRoomOutOfOrder
+	case room.Status == "occupied":
+		return PlanRoomOccupied
+	case !room.IsInspected():
+		return PlanRoomDirty
+	default:
+		return PlanRoomAvailable
+	}
+}
+
+// rotatedBounds 计算矩形绕中心旋转后的外接矩形
+// 返回左、上、右、下边界
+func rotatedBounds(left, top, width, height, rotation int) (float64, float64, float64, float64) {
+	cx := float64(left) + float64(width)/2
+	cy := float64(top) + float64(height)/2
+	rad := float64(rotation) * math.Pi / 180
+	absCos, absSin := math.Abs(math.Cos(rad)), math.Abs(math.Sin(rad))
+	halfW := (float64(width)*absCos + float64(height)*absSin) / 2
+	halfH := (float64(width)*absSin + float64(height)*absCos) / 2
+	return cx - halfW, cy - halfH, cx + halfW, cy + halfH
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// RenderFloorPlanSVG 将楼层平面图渲染为 SVG 文档
+// 房间按实时状态着色，设施按类型着色并按 Rotation 绕中心旋转，底部附带图例
+func RenderFloorPlanSVG(plan *FloorPlan) []byte {
+	const legendHeight = 30
+
+	var b strings.Builder
+	width, height := plan.Width, plan.Height+legendHeight
+
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(&b, `<title>%d F</title>`+"\n", plan.Floor)
+	fmt.Fprintf(&b, `<rect x="0" y="0" width="%d" height="%d" fill="#ffffff"/>`+"\n", width, height)
+
+	// 设施在下层，房间在上层
+	b.WriteString(`<g id="facilities">` + "\n")
+	for _, f := range plan.Facilities {
+		cx := float64(f.Left) + float64(f.Width)/2
+		cy := float64(f.Top) + float64(f.Height)/2
+		transform := ""
+		if f.Rotation != 0 {
+			transform = fmt.Sprintf(` transform="rotate(%d %.1f %.1f)"`, f.Rotation, cx, cy)
+		}
+		fmt.Fprintf(&b, `<g data-facility-id="%d" data-type="%s"%s>`, f.ID, html.EscapeString(f.Type), transform)
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s" stroke="#595959" stroke-width="1"/>`,
+			f.Left, f.Top, f.Width, f.Height, f.Color)
+		label := f.Label
+		if label == "" {
+			label = f.Type
+		}
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" font-size="10" text-anchor="middle" dominant-baseline="middle" fill="#262626">%s</text>`,
+			cx, cy, html.EscapeString(label))
+		b.WriteString("</g>\n")
+	}
+	b.WriteString("</g>\n")
+
+	b.WriteString(`<g id="rooms">` + "\n")
+	for _, r := range plan.Rooms {
+		cx := float64(r.Left) + float64(r.Width)/2
+		cy := float64(r.Top) + float64(r.Height)/2
+		fmt.Fprintf(&b, `<g data-room-id="%d" data-status="%s">`, r.ID, r.LiveStatus)
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s" fill-opacity="0.85" stroke="#262626" stroke-width="1"/>`,
+			r.Left, r.Top, r.Width, r.Height, r.Color)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" font-size="12" text-anchor="middle" dominant-baseline="middle" fill="#ffffff">%s</text>`,
+			cx, cy, html.EscapeString(r.RoomNumber))
+		b.WriteString("</g>\n")
+	}
+	b.WriteString("</g>\n")
+
+	// 图例
+	b.WriteString(`<g id="legend">` + "\n")
+	y := plan.Height + 8
+	for i, item := range plan.Legend {
+		x := planPadding + i*90
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="14" height="14" fill="%s"/>`, x, y, item.Color)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="12" fill="#262626">%s</text>`+"\n", x+20, y+12, html.EscapeString(item.Label))
+	}
+	b.WriteString("</g>\n")
+
+	b.WriteString("</svg>\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/pkg/errors"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// HotelService 酒店业务逻辑层
+// 负责多门店管理、员工分配，以及请求中酒店范围的解析
+type HotelService struct {
+	hotelRepo *repository.HotelRepository
+	userRepo  *repository.UserRepository
+}
+
+// NewHotelService 创建酒店服务实例
+func NewHotelService(hotelRepo *repository.HotelRepository, userRepo *repository.UserRepository) *HotelService {
+	return &HotelService{
+		hotelRepo: hotelRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// CreateHotelRequest 创建酒店请求
+type CreateHotelRequest struct {
+	Code    string `json:"code" binding:"required,max=50"`
+	Name    string `json:"name" binding:"required,max=100"`
+	City    string `json:"city" binding:"max=50"`
+	Address string `json:"address" binding:"max=255"`
+	Phone   string `json:"phone" binding:"max=20"`
+	Sort    int    `json:"sort"`
+}
+
+// UpdateHotelRequest 更新酒店请求
+type UpdateHotelRequest struct {
+	Name    string `json:"name" binding:"max=100"`
+	City    string `json:"city" binding:"max=50"`
+	Address string `json:"address" binding:"max=255"`
+	Phone   string `json:"phone" binding:"max=20"`
+	Status  string `json:"status" binding:"omitempty,oneof=active inactive"`
+	Sort    *int   `json:"sort"`
+}
+
+// AssignHotelStaffRequest 分配酒店员工请求
+type AssignHotelStaffRequest struct {
+	UserIDs []string `json:"user_ids" binding:"required,min=1,max=100"`
+}
+
+// ResolvePublicHotel 解析公开接口的酒店范围
+// hotelID 为 0 时使用默认酒店，指定的酒店必须存在且营业中
+func (s *HotelService) ResolvePublicHotel(hotelID uint) (uint, error) {
+	if hotelID == 0 {
+		hotel, err := s.hotelRepo.FindDefault()
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return 0, errors.NewNotFoundError("暂无营业中的酒店")
+			}
+			return 0, errors.NewDatabaseError("find default hotel", err)
+		}
+		return hotel.ID, nil
+	}
+
+	hotel, err := s.findHotel(hotelID)
+	if err != nil {
+		return 0, err
+	}
+	if !hotel.IsActive() {
+		return 0, errors.NewNotFoundError("酒店不存在")
+	}
+	return hotel.ID, nil
+}
+
+// ResolveStaffHotel 解析员工接口的酒店范围
+// 员工只能访问被分配的酒店；只分配了一家酒店时可以不指定
+func (s *HotelService) ResolveStaffHotel(userID int64, hotelID uint) (uint, error) {
+	ids, err := s.hotelRepo.FindStaffHotelIDs(userID)
+	if err != nil {
+		return 0, errors.NewDatabaseError("find staff hotels", err)
+	}
+	if len(ids) == 0 {
+		return 0, errors.NewForbiddenError("尚未分配酒店，请联系管理员")
+	}
+
+	if hotelID == 0 {
+		if len(ids) > 1 {
+			return 0, errors.NewBadRequestError("请指定要管理的酒店")
+		}
+		return ids[0], nil
+	}
+	for _, id := range ids {
+		if id == hotelID {
+			return hotelID, nil
+		}
+	}
+	return 0, errors.NewForbiddenError("无权访问该酒店")
+}
+
+// ListActiveHotels 获取营业中的酒店列表（公开，用于选择酒店）
+func (s *HotelService) ListActiveHotels(city string) ([]models.Hotel, error) {
+	hotels, err := s.hotelRepo.FindActive(city)
+	if err != nil {
+		return nil, errors.NewDatabaseError("list hotels", err)
+	}
+	return hotels, nil
+}
+
+// ListMyHotels 获取员工被分配的酒店列表
+func (s *HotelService) ListMyHotels(userID int64) ([]models.Hotel, error) {
+	hotels, err := s.hotelRepo.FindByStaff(userID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("list staff hotels", err)
+	}
+	return hotels, nil
+}
+
+// GetHotel 获取酒店详情（员工只能查看被分配的酒店）
+func (s *HotelService) GetHotel(userID int64, id uint) (*models.Hotel, error) {
+	if _, err := s.ResolveStaffHotel(userID, id); err != nil {
+		return nil, err
+	}
+	return s.findHotel(id)
+}
+
+// CreateHotel 创建酒店，创建人自动成为该酒店员工
+func (s *HotelService) CreateHotel(userID int64, req *CreateHotelRequest) (*models.Hotel, error) {
+	exists, err := s.hotelRepo.ExistsByCode(req.Code)
+	if err != nil {
+		return nil, errors.NewDatabaseError("check hotel code", err)
+	}
+	if exists {
+		return nil, errors.NewConflictError("酒店编码已存在")
+	}
+
+	hotel := &models.Hotel{
+		Code:    req.Code,
+		Name:    req.Name,
+		City:    req.City,
+		Address: req.Address,
+		Phone:   req.Phone,
+		Status:  "active",
+		Sort:    req.Sort,
+	}
+	if err := s.hotelRepo.CreateWithStaff(hotel, userID); err != nil {
+		return nil, errors.NewDatabaseError("create hotel", err)
+	}
+	return hotel, nil
+}
+
+// UpdateHotel 更新酒店信息
+func (s *HotelService) UpdateHotel(userID int64, id uint, req *UpdateHotelRequest) (*models.Hotel, error) {
+	hotel, err := s.GetHotel(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		hotel.Name = req.Name
+	}
+	if req.City != "" {
+		hotel.City = req.City
+	}
+	if req.Address != "" {
+		hotel.Address = req.Address
+	}
+	if req.Phone != "" {
+		hotel.Phone = req.Phone
+	}
+	if req.Status != "" {
+		hotel.Status = req.Status
+	}
+	if req.Sort != nil {
+		hotel.Sort = *req.Sort
+	}
+
+	if err := s.hotelRepo.Update(hotel); err != nil {
+		return nil, errors.NewDatabaseError("update hotel", err)
+	}
+	return hotel, nil
+}
+
+// ListStaff 获取酒店员工列表
+func (s *HotelService) ListStaff(userID int64, hotelID uint) ([]models.HotelStaff, error) {
+	if _, err := s.ResolveStaffHotel(userID, hotelID); err != nil {
+		return nil, err
+	}
+	staff, err := s.hotelRepo.FindStaff(hotelID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("list hotel staff", err)
+	}
+	return staff, nil
+}
+
+// AssignStaff 将员工分配到酒店（操作人必须是该酒店员工）
+func (s *HotelService) AssignStaff(userID int64, hotelID uint, req *AssignHotelStaffRequest) ([]models.HotelStaff, error) {
+	if _, err := s.ResolveStaffHotel(userID, hotelID); err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]int64, 0, len(req.UserIDs))
+	for _, idStr := range req.UserIDs {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return nil, errors.NewBadRequestError("无效的员工ID: " + idStr)
+		}
+		if _, err := s.userRepo.FindByID(id); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, errors.NewNotFoundError("员工不存在: " + idStr)
+			}
+			return nil, errors.NewDatabaseError("find user", err)
+		}
+		userIDs = append(userIDs, id)
+	}
+
+	if err := s.hotelRepo.AddStaff(hotelID, userIDs); err != nil {
+		return nil, errors.NewDatabaseError("assign hotel staff", err)
+	}
+	return s.ListStaff(userID, hotelID)
+}
+
+// RemoveStaff 取消员工的酒店分配，酒店至少保留一名员工
+func (s *HotelService) RemoveStaff(userID int64, hotelID uint, staffUserID int64) error {
+	if _, err := s.ResolveStaffHotel(userID, hotelID); err != nil {
+		return err
+	}
+
+	count, err := s.hotelRepo.CountStaff(hotelID)
+	if err != nil {
+		return errors.NewDatabaseError("count hotel staff", err)
+	}
+	if count <= 1 {
+		return errors.NewBadRequestError("酒店至少需要保留一名员工")
+	}
+
+	removed, err := s.hotelRepo.RemoveStaff(hotelID, staffUserID)
+	if err != nil {
+		return errors.NewDatabaseError("remove hotel staff", err)
+	}
+	if !removed {
+		return errors.NewNotFoundError("该员工未分配到此酒店")
+	}
+	return nil
+}
+
+// findHotel 根据 ID 查找酒店
+func (s *HotelService) findHotel(id uint) (*models.Hotel, error) {
+	hotel, err := s.hotelRepo.FindByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("酒店不存在")
+		}
+		return nil, errors.NewDatabaseError("find hotel", err)
+	}
+	return hotel, nil
+}
+
+// isHotelStaff 判断用户是否被分配到酒店
+func isHotelStaff(hotelRepo *repository.HotelRepository, hotelID uint, userID int64) (bool, error) {
+	ids, err := hotelRepo.FindStaffHotelIDs(userID)
+	if err != nil {
+		return false, err
+	}
+	for _, id := range ids {
+		if id == hotelID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,343 @@
+package service
+
+import (
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// HousekeepingService 客房清洁业务逻辑层
+// 清洁状态流转：dirty -> cleaning -> clean -> inspected
+// 只有查房通过（inspected）的空闲房间才可售卖
+type HousekeepingService struct {
+	housekeepingRepo *repository.HousekeepingRepository
+	roomRepo         *repository.RoomRepository
+	userRepo         *repository.UserRepository
+	hotelRepo        *repository.HotelRepository
+}
+
+// NewHousekeepingService 创建客房清洁服务实例
+func NewHousekeepingService(
+	housekeepingRepo *repository.HousekeepingRepository,
+	roomRepo *repository.RoomRepository,
+	userRepo *repository.UserRepository,
+	hotelRepo *repository.HotelRepository,
+) *HousekeepingService {
+	return &HousekeepingService{
+		housekeepingRepo: housekeepingRepo,
+		roomRepo:         roomRepo,
+		userRepo:         userRepo,
+		hotelRepo:        hotelRepo,
+	}
+}
+
+// CreateHousekeepingTaskRequest 手动创建清洁任务请求
+type CreateHousekeepingTaskRequest struct {
+	RoomID     uint   `json:"room_id" binding:"required"`
+	TaskType   string `json:"task_type" binding:"required,oneof=checkout stayover deep"`
+	AssigneeID string `json:"assignee_id"` // 保洁员 ID（可选）
+	Note       string `json:"note"`
+}
+
+// AssignHousekeepingTaskRequest 分配清洁任务请求
+type AssignHousekeepingTaskRequest struct {
+	AssigneeID string `json:"assignee_id" binding:"required"`
+}
+
+// InspectHousekeepingTaskRequest 查房请求
+type InspectHousekeepingTaskRequest struct {
+	Passed bool   `json:"passed"`
+	Note   string `json:"note"` // 不合格原因
+}
+
+// CreateCheckoutTask 退房后自动生成清洁任务，并将房间标记为脏房
+// 若该房间已有未完成的任务则复用，避免重复派单；复用的任务改为关联本次退房的预订
+func (s *HousekeepingService) CreateCheckoutTask(roomID uint, bookingID int64) error {
+	if err := s.roomRepo.UpdateHousekeepingStatus(roomID, "dirty"); err != nil {
+		return errors.NewDatabaseError("update room housekeeping status", err)
+	}
+
+	task, err := s.housekeepingRepo.FindOpenByRoomID(roomID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return errors.NewDatabaseError("find open housekeeping task", err)
+	}
+	id := utils.JSONInt64(bookingID)
+	if task != nil {
+		// 已有任务重新回到待清扫状态
+		task.BookingID = &id
+		task.TaskType = "checkout"
+		task.Status = "pending"
+		task.StartedAt = nil
+		task.CleanedAt = nil
+		if err := s.housekeepingRepo.Update(task); err != nil {
+			return errors.NewDatabaseError("update housekeeping task", err)
+		}
+		return nil
+	}
+
+	task = &models.HousekeepingTask{
+		RoomID:    roomID,
+		BookingID: &id,
+		TaskType:  "checkout",
+		Status:    "pending",
+	}
+	if err := s.housekeepingRepo.Create(task); err != nil {
+		return errors.NewDatabaseError("create housekeeping task", err)
+	}
+	return nil
+}
+
+// CreateTask 手动创建清洁任务（管理员）
+// 每个房间同时只能有一个未完成的任务
+func (s *HousekeepingService) CreateTask(hotelID uint, req *CreateHousekeepingTaskRequest) (*models.HousekeepingTask, error) {
+	if _, err := findHotelRoom(s.roomRepo, hotelID, req.RoomID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.housekeepingRepo.FindOpenByRoomID(req.RoomID); err == nil {
+		return nil, errors.NewConflictError("该房间已有未完成的清洁任务")
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, errors.NewDatabaseError("find open housekeeping task", err)
+	}
+
+	task := &models.HousekeepingTask{
+		RoomID:   req.RoomID,
+		TaskType: req.TaskType,
+		Status:   "pending",
+		Note:     req.Note,
+	}
+	if req.AssigneeID != "" {
+		assigneeID, err := s.parseStaffID(hotelID, req.AssigneeID)
+		if err != nil {
+			return nil, err
+		}
+		task.AssigneeID = &assigneeID
+	}
+
+	if err := s.housekeepingRepo.Create(task); err != nil {
+		return nil, errors.NewDatabaseError("create housekeeping task", err)
+	}
+	if err := s.roomRepo.UpdateHousekeepingStatus(req.RoomID, "dirty"); err != nil {
+		return nil, errors.NewDatabaseError("update room housekeeping status", err)
+	}
+
+	return s.findTask(task.ID)
+}
+
+// AssignTask 分配清洁任务给保洁员（管理员）
+func (s *HousekeepingService) AssignTask(hotelID, id uint, req *AssignHousekeepingTaskRequest) (*models.HousekeepingTask, error) {
+	task, err := s.findHotelTask(hotelID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !task.IsOpen() {
+		return nil, errors.NewBadRequestError("该任务已完成查房，无法重新分配")
+	}
+
+	assigneeID, err := s.parseStaffID(hotelID, req.AssigneeID)
+	if err != nil {
+		return nil, err
+	}
+	task.AssigneeID = &assigneeID
+
+	if err := s.housekeepingRepo.Update(task); err != nil {
+		return nil, errors.NewDatabaseError("assign housekeeping task", err)
+	}
+	return s.findTask(id)
+}
+
+// ListTasks 查询清洁任务列表（管理员）
+func (s *HousekeepingService) ListTasks(hotelID uint, page, pageSize int, status string, assigneeID int64, floor int) ([]models.HousekeepingTask, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	tasks, total, err := s.housekeepingRepo.FindAll(hotelID, page, pageSize, status, assigneeID, floor)
+	if err != nil {
+		return nil, 0, errors.NewDatabaseError("list housekeeping tasks", err)
+	}
+	return tasks, total, nil
+}
+
+// GetMyTasks 获取分配给当前员工的待办清洁任务（移动端）
+func (s *HousekeepingService) GetMyTasks(userID int64) ([]models.HousekeepingTask, error) {
+	tasks, err := s.housekeepingRepo.FindOpenByAssignee(userID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("find my housekeeping tasks", err)
+	}
+	return tasks, nil
+}
+
+// StartTask 保洁员开始清扫（移动端）
+func (s *HousekeepingService) StartTask(id uint, userID int64) (*models.HousekeepingTask, error) {
+	task, err := s.findAssignedTask(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != "pending" {
+		return nil, errors.NewBadRequestError("只能开始待清扫的任务")
+	}
+
+	now := time.Now()
+	task.Status = "in_progress"
+	task.StartedAt = &now
+	if err := s.housekeepingRepo.Update(task); err != nil {
+		return nil, errors.NewDatabaseError("start housekeeping task", err)
+	}
+	if err := s.roomRepo.UpdateHousekeepingStatus(task.RoomID, "cleaning"); err != nil {
+		return nil, errors.NewDatabaseError("update room housekeeping status", err)
+	}
+
+	return task, nil
+}
+
+// CompleteTask 保洁员完成清扫，等待查房（移动端）
+func (s *HousekeepingService) CompleteTask(id uint, userID int64, note string) (*models.HousekeepingTask, error) {
+	task, err := s.findAssignedTask(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != "in_progress" {
+		return nil, errors.NewBadRequestError("只能完成清扫中的任务")
+	}
+
+	now := time.Now()
+	task.Status = "cleaned"
+	task.CleanedAt = &now
+	if note != "" {
+		task.Note = note
+	}
+	if err := s.housekeepingRepo.Update(task); err != nil {
+		return nil, errors.NewDatabaseError("complete housekeeping task", err)
+	}
+	if err := s.roomRepo.UpdateHousekeepingStatus(task.RoomID, "clean"); err != nil {
+		return nil, errors.NewDatabaseError("update room housekeeping status", err)
+	}
+
+	return task, nil
+}
+
+// InspectTask 查房（管理员）
+// 通过后房间变为 inspected 可重新售卖，不通过则退回待清扫
+func (s *HousekeepingService) InspectTask(hotelID, id uint, inspectorID int64, req *InspectHousekeepingTaskRequest) (*models.HousekeepingTask, error) {
+	task, err := s.findHotelTask(hotelID, id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != "cleaned" {
+		return nil, errors.NewBadRequestError("只能对已清扫的任务进行查房")
+	}
+
+	inspector := utils.JSONInt64(inspectorID)
+	task.InspectorID = &inspector
+	task.Note = req.Note
+
+	roomStatus := "inspected"
+	if req.Passed {
+		now := time.Now()
+		task.Status = "inspected"
+		task.InspectedAt = &now
+	} else {
+		task.Status = "pending"
+		task.StartedAt = nil
+		task.CleanedAt = nil
+		roomStatus = "dirty"
+	}
+
+	if err := s.housekeepingRepo.Update(task); err != nil {
+		return nil, errors.NewDatabaseError("inspect housekeeping task", err)
+	}
+	if err := s.roomRepo.UpdateHousekeepingStatus(task.RoomID, roomStatus); err != nil {
+		return nil, errors.NewDatabaseError("update room housekeeping status", err)
+	}
+
+	return task, nil
+}
+
+// UpdateRoomHousekeepingStatus 手动设置房间清洁状态（管理员）
+func (s *HousekeepingService) UpdateRoomHousekeepingStatus(hotelID, roomID uint, status string) error {
+	validStatuses := map[string]bool{
+		"dirty":     true,
+		"cleaning":  true,
+		"clean":     true,
+		"inspected": true,
+	}
+	if !validStatuses[status] {
+		return errors.NewBadRequestError("无效的清洁状态")
+	}
+
+	if _, err := findHotelRoom(s.roomRepo, hotelID, roomID); err != nil {
+		return err
+	}
+
+	if err := s.roomRepo.UpdateHousekeepingStatus(roomID, status); err != nil {
+		return errors.NewDatabaseError("update room housekeeping status", err)
+	}
+	return nil
+}
+
+// findTask 查找清洁任务
+func (s *HousekeepingService) findTask(id uint) (*models.HousekeepingTask, error) {
+	task, err := s.housekeepingRepo.FindByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("清洁任务不存在")
+		}
+		return nil, errors.NewDatabaseError("find housekeeping task", err)
+	}
+	return task, nil
+}
+
+// findHotelTask 查找酒店内的清洁任务，任务房间属于其他酒店时视为不存在
+func (s *HousekeepingService) findHotelTask(hotelID, id uint) (*models.HousekeepingTask, error) {
+	task, err := s.findTask(id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Room.HotelID != hotelID {
+		return nil, errors.NewNotFoundError("清洁任务不存在")
+	}
+	return task, nil
+}
+
+// findAssignedTask 查找分配给指定员工的清洁任务
+func (s *HousekeepingService) findAssignedTask(id uint, userID int64) (*models.HousekeepingTask, error) {
+	task, err := s.findTask(id)
+	if err != nil {
+		return nil, err
+	}
+	if !task.IsAssignedTo(userID) {
+		return nil, errors.NewForbiddenError("该任务未分配给你")
+	}
+	return task, nil
+}
+
+// parseStaffID 解析并校验员工 ID，员工必须已分配到该酒店
+func (s *HousekeepingService) parseStaffID(hotelID uint, idStr string) (utils.JSONInt64, error) {
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return 0, errors.NewBadRequestError("无效的员工ID")
+	}
+	if _, err := s.userRepo.FindByID(id); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, errors.NewNotFoundError("员工不存在")
+		}
+		return 0, errors.NewDatabaseError("find user", err)
+	}
+	ok, err := isHotelStaff(s.hotelRepo, hotelID, id)
+	if err != nil {
+		return 0, errors.NewDatabaseError("find staff hotels", err)
+	}
+	if !ok {
+		return 0, errors.NewNotFoundError("该员工未分配到此酒店")
+	}
+	return utils.JSONInt64(id), nil
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"fmt"
+	"gohotel/internal/config"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/logger"
+	"gohotel/pkg/utils"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// LoginThrottleService 登录防暴力破解业务逻辑层
+// 按账号记录连续失败次数，达到阈值后临时锁定账号，锁定时长按连续锁定次数指数增长并通知管理员；
+// 按 IP 记录失败次数（进程内），达到阈值后暂停该 IP 的登录
+type LoginThrottleService struct {
+	lockoutRepo *repository.LoginLockoutRepository
+	userRepo    *repository.UserRepository
+	roleRepo    *repository.RoleRepository
+	emailSender EmailSender
+	cfg         *config.LoginConfig
+	ipFailures  map[string]ipLoginFailure
+	lastPrune   time.Time
+	ipMutex     sync.Mutex
+}
+
+// ipLoginFailure IP 的登录失败记录
+type ipLoginFailure struct {
+	count        int
+	lastFail     time.Time
+	blockedUntil time.Time
+}
+
+// NewLoginThrottleService 创建登录防暴力破解服务实例
+func NewLoginThrottleService(lockoutRepo *repository.LoginLockoutRepository, userRepo *repository.UserRepository, roleRepo *repository.RoleRepository, emailSender EmailSender, cfg *config.LoginConfig) *LoginThrottleService {
+	return &LoginThrottleService{
+		lockoutRepo: lockoutRepo,
+		userRepo:    userRepo,
+		roleRepo:    roleRepo,
+		emailSender: emailSender,
+		cfg:         cfg,
+		ipFailures:  make(map[string]ipLoginFailure),
+	}
+}
+
+// LockedAccount 被临时锁定的账号
+type LockedAccount struct {
+	UserID      utils.JSONInt64 `json:"user_id"`      // 用户 ID
+	Username    string          `json:"username"`     // 用户名
+	Email       string          `json:"email"`        // 邮箱
+	LockCount   int             `json:"lock_count"`   // 连续锁定次数
+	LockedUntil time.Time       `json:"locked_until"` // 锁定截止时间
+}
+
+// CheckIP 检查 IP 是否因失败次数过多被暂停登录
+func (s *LoginThrottleService) CheckIP(ip string) error {
+	if ip == "" {
+		return nil
+	}
+	now := time.Now()
+	s.ipMutex.Lock()
+	failure, ok := s.ipFailures[ip]
+	s.ipMutex.Unlock()
+
+	if ok && now.Before(failure.blockedUntil) {
+		return errors.NewTooManyRequestsError(fmt.Sprintf("登录失败次数过多，请在 %s后重试", remainingText(failure.blockedUntil.Sub(now))))
+	}
+	return nil
+}
+
+// CheckAccount 检查账号是否处于锁定状态
+func (s *LoginThrottleService) CheckAccount(userID int64) error {
+	lockout, err := s.lockoutRepo.FindByUserID(userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return errors.NewDatabaseError("find login lockout", err)
+	}
+	now := time.Now()
+	if lockout.IsLocked(now) {
+		return errors.NewAccountLockedError(fmt.Sprintf("登录失败次数过多，账号已临时锁定，请在 %s后重试", remainingText(lockout.LockedUntil.Sub(now))))
+	}
+	return nil
+}
+
+// RecordFailure 记录一次登录失败，user 为空表示账号不存在
+// 本次失败导致账号锁定时返回锁定错误，否则返回 nil
+func (s *LoginThrottleService) RecordFailure(user *models.User, ip string) error {
+	now := time.Now()
+	s.recordIPFailure(ip, now)
+	if user == nil {
+		return nil
+	}
+
+	lockout, err := s.lockoutRepo.IncrementFailure(user.ID.Int64(), now, now.Add(-s.cfg.FailWindow))
+	if err != nil {
+		return errors.NewDatabaseError("record login failure", err)
+	}
+	if lockout.FailedCount < s.cfg.MaxFailures {
+		return nil
+	}
+
+	// 并发的失败请求同时达到阈值时只锁定一次，其余请求按已锁定处理
+	until := now.Add(s.lockDuration(lockout.LockCount))
+	locked, err := s.lockoutRepo.Lock(user.ID.Int64(), s.cfg.MaxFailures, until)
+	if err != nil {
+		return errors.NewDatabaseError("lock account", err)
+	}
+	if !locked {
+		return s.CheckAccount(user.ID.Int64())
+	}
+	lockout.LockedUntil = &until
+	lockout.LockCount++
+	s.notifyAdmins(user, lockout, ip)
+	return errors.NewAccountLockedError(fmt.Sprintf("登录失败次数过多，账号已临时锁定，请在 %s后重试", remainingText(until.Sub(now))))
+}
+
+// FailureCount 返回账号在统计窗口内的连续失败次数
+// 以数据库中的记录为准，多实例部署时各实例看到的次数一致
+func (s *LoginThrottleService) FailureCount(userID int64) (int, error) {
+	lockout, err := s.lockoutRepo.FindByUserID(userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, nil
+		}
+		return 0, errors.NewDatabaseError("find login lockout", err)
+	}
+	if lockout.LastFailedAt == nil || time.Since(*lockout.LastFailedAt) > s.cfg.FailWindow {
+		return 0, nil
+	}
+	return lockout.FailedCount, nil
+}
+
+// RecordSuccess 登录成功后清除账号的失败和锁定记录
+func (s *LoginThrottleService) RecordSuccess(userID int64) error {
+	if _, err := s.lockoutRepo.Delete(userID); err != nil {
+		return errors.NewDatabaseError("delete login lockout", err)
+	}
+	return nil
+}
+
+// Unlock 管理员解除账号锁定，同时清零连续锁定次数
+func (s *LoginThrottleService) Unlock(userID int64) error {
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewNotFoundError("用户不存在")
+		}
+		return errors.NewDatabaseError("find user", err)
+	}
+	if _, err := s.lockoutRepo.Delete(userID); err != nil {
+		return errors.NewDatabaseError("delete login lockout", err)
+	}
+	return nil
+}
+
+// ListLocked 查询当前被锁定的账号
+func (s *LoginThrottleService) ListLocked() ([]LockedAccount, error) {
+	lockouts, err := s.lockoutRepo.FindLocked(time.Now())
+	if err != nil {
+		return nil, errors.NewDatabaseError("list login lockouts", err)
+	}
+
+	ids := make([]int64, len(lockouts))
+	for i, lockout := range lockouts {
+		ids[i] = lockout.UserID.Int64()
+	}
+	users, err := s.userRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, errors.NewDatabaseError("find users", err)
+	}
+	byID := make(map[int64]models.User, len(users))
+	for _, user := range users {
+		byID[user.ID.Int64()] = user
+	}
+
+	accounts := make([]LockedAccount, 0, len(lockouts))
+	for _, lockout := range lockouts {
+		user, ok := byID[lockout.UserID.Int64()]
+		if !ok {
+			continue
+		}
+		accounts = append(accounts, LockedAccount{
+			UserID:      user.ID,
+			Username:    user.Username,
+			Email:       user.Email,
+			LockCount:   lockout.LockCount,
+			LockedUntil: *lockout.LockedUntil,
+		})
+	}
+	return accounts, nil
+}
+
+// recordIPFailure 记录 IP 的一次失败，达到阈值后暂停该 IP 的登录
+func (s *LoginThrottleService) recordIPFailure(ip string, now time.Time) {
+	if ip == "" {
+		return
+	}
+	s.ipMutex.Lock()
+	defer s.ipMutex.Unlock()
+
+	// 每分钟最多顺带清理一次过期记录
+	if now.Sub(s.lastPrune) > time.Minute {
+		for key, f := range s.ipFailures {
+			if now.Sub(f.lastFail) > s.cfg.FailWindow && !now.Before(f.blockedUntil) {
+				delete(s.ipFailures, key)
+			}
+		}
+		s.lastPrune = now
+	}
+
+	failure := s.ipFailures[ip]
+	if now.Sub(failure.lastFail) > s.cfg.FailWindow {
+		failure.count = 0
+	}
+	failure.count++
+	failure.lastFail = now
+	if failure.count >= s.cfg.IPMaxFailures {
+		failure.blockedUntil = now.Add(s.cfg.IPLockDuration)
+		failure.count = 0
+		logger.Warn("IP 登录失败次数过多，已暂停登录", zap.String("ip", ip), zap.Time("until", failure.blockedUntil))
+	}
+	s.ipFailures[ip] = failure
+}
+
+// lockDuration 计算第 lockCount+1 次锁定的时长：首次为 LockBase，之后每次翻倍，不超过 LockMax
+func (s *LoginThrottleService) lockDuration(lockCount int) time.Duration {
+	d := s.cfg.LockBase
+	for i := 0; i < lockCount && d < s.cfg.LockMax; i++ {
+		d *= 2
+	}
+	if d > s.cfg.LockMax {
+		d = s.cfg.LockMax
+	}
+	return d
+}
+
+// notifyAdmins 账号被锁定时记录服务端日志，并邮件通知有权解锁的管理员
+// 锁定记录包含用户名和 IP，不写入 logs 表；通知失败只记录日志，不影响登录流程
+func (s *LoginThrottleService) notifyAdmins(user *models.User, lockout *models.LoginLockout, ip string) {
+	message := fmt.Sprintf("账号 %s（ID %d）连续登录失败，已被锁定至 %s（第 %d 次锁定），最近一次失败来自 IP %s",
+		user.Username, user.ID.Int64(), lockout.LockedUntil.Format("2006-01-02 15:04:05"), lockout.LockCount, ip)
+	logger.Warn("账号登录失败次数过多，已临时锁定", zap.Int64("user_id", user.ID.Int64()), zap.String("ip", ip), zap.Int("lock_count", lockout.LockCount))
+
+	adminIDs, err := s.roleRepo.FindUserIDsWithPermission(models.PermUserUpdate)
+	if err != nil {
+		logger.Error("查询管理员失败", zap.Error(err))
+		return
+	}
+	admins, err := s.userRepo.FindByIDs(adminIDs)
+	if err != nil {
+		logger.Error("查询管理员失败", zap.Error(err))
+		return
+	}
+	for _, admin := range admins {
+		if !admin.IsActive() || admin.Email == "" {
+			continue
+		}
+		if err := s.emailSender.SendEmail(admin.Email, "【GoHotel】账号锁定提醒", message+"。如需提前解锁，请在用户管理中操作。"); err != nil {
+			logger.Error("发送账号锁定通知失败", zap.String("to", admin.Email), zap.Error(err))
+		}
+	}
+}
+
+// remainingText 将剩余时间向上取整到分钟后转换为中文描述
+func remainingText(d time.Duration) string {
+	if rem := d % time.Minute; rem > 0 {
+		d += time.Minute - rem
+	}
+	return durationText(d)
+}
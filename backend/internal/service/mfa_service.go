@@ -0,0 +1,524 @@
+package service
+
+import (
+	"crypto/rand"
+	"gohotel/internal/config"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/logger"
+	"gohotel/pkg/utils"
+	"math/big"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// mfaTOTPSkew 允许的时钟偏差（前后各一个时间步）
+	mfaTOTPSkew = 1
+	// mfaQRCodeSize 绑定二维码图片边长（像素）
+	mfaQRCodeSize = 256
+	// recoveryCodeLength 恢复码长度，展示时每 5 位用 - 分隔
+	recoveryCodeLength = 10
+	// recoveryCodeChars 恢复码字符集，去掉了容易混淆的 0/o、1/l/i
+	recoveryCodeChars = "abcdefghjkmnpqrstuvwxyz23456789"
+	// mfaCleanupInterval 清理过期第二步验证的间隔
+	mfaCleanupInterval = time.Hour
+)
+
+// MFAService 两步验证业务逻辑层
+// 用户使用身份验证器 App 绑定 TOTP，角色可以要求其成员必须启用；
+// 启用后登录分为两步：密码等第一步验证通过后只返回第二步验证令牌，提交动态码或恢复码后才签发访问令牌
+type MFAService struct {
+	mfaRepo      *repository.MFARepository
+	userRepo     *repository.UserRepository
+	roleRepo     *repository.RoleRepository
+	tokenService *TokenService
+	timeWheel    *utils.MultiTimeWheel
+	cfg          *config.MFAConfig
+}
+
+// NewMFAService 创建两步验证服务实例
+func NewMFAService(mfaRepo *repository.MFARepository, userRepo *repository.UserRepository, roleRepo *repository.RoleRepository, tokenService *TokenService, timeWheel *utils.MultiTimeWheel, cfg *config.MFAConfig) *MFAService {
+	return &MFAService{
+		mfaRepo:      mfaRepo,
+		userRepo:     userRepo,
+		roleRepo:     roleRepo,
+		tokenService: tokenService,
+		timeWheel:    timeWheel,
+		cfg:          cfg,
+	}
+}
+
+// MFAChallengeResponse 需要第二步验证时的登录响应
+type MFAChallengeResponse struct {
+	MFAToken      string `json:"mfa_token"`      // 第二步验证令牌，提交动态码时回传
+	SetupRequired bool   `json:"setup_required"` // 角色要求两步验证但尚未绑定，需先获取二维码完成绑定
+	ExpiresIn     int64  `json:"expires_in"`     // 有效期（秒）
+}
+
+// MFAStatusResponse 两步验证状态
+type MFAStatusResponse struct {
+	Enabled           bool       `json:"enabled"`              // 是否已启用
+	Required          bool       `json:"required"`             // 角色是否要求启用，要求时不能关闭
+	EnabledAt         *time.Time `json:"enabled_at,omitempty"` // 启用时间
+	RecoveryCodesLeft int64      `json:"recovery_codes_left"`  // 剩余可用的恢复码数量
+}
+
+// MFASetupResponse 绑定身份验证器所需的信息
+type MFASetupResponse struct {
+	Secret string `json:"secret"`  // Base32 密钥，无法扫码时手动输入
+	URI    string `json:"uri"`     // otpauth:// 链接
+	QRCode string `json:"qr_code"` // 二维码 PNG 图片（data URL）
+}
+
+// MFACodeRequest 动态码请求
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"` // 身份验证器显示的 6 位动态码
+}
+
+// MFADisableRequest 关闭两步验证请求
+type MFADisableRequest struct {
+	Password string `json:"password" binding:"required"` // 当前密码
+	Code     string `json:"code" binding:"required"`     // 动态码或恢复码
+}
+
+// MFARecoveryCodesResponse 恢复码，只在生成时返回一次
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFATokenRequest 第二步验证令牌请求
+type MFATokenRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+// MFAVerifyRequest 登录第二步验证请求
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"` // 动态码或恢复码；登录时完成绑定的只能使用动态码
+}
+
+// MFAVerifyResponse 登录第二步验证响应
+type MFAVerifyResponse struct {
+	LoginResponse
+	RecoveryCodes []string `json:"recovery_codes,omitempty"` // 登录时完成绑定生成的恢复码，只返回一次
+}
+
+// BeginLogin 第一步验证通过后调用
+// 未启用两步验证且角色不要求时直接签发令牌，否则只返回第二步验证令牌
+func (s *MFAService) BeginLogin(user *models.User, client ClientInfo) (*LoginResponse, error) {
+	enabled, required, err := s.state(user.ID.Int64())
+	if err != nil {
+		return nil, err
+	}
+	if !enabled && !required {
+		tokens, err := s.tokenService.IssueTokens(user, client)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResponse{User: user, TokenPair: tokens}, nil
+	}
+
+	raw, err := newRandomToken()
+	if err != nil {
+		return nil, errors.NewInternalServerError("生成令牌失败")
+	}
+	challenge := &models.MFAChallenge{
+		UserID:        user.ID,
+		TokenHash:     hashToken(raw),
+		SetupRequired: !enabled,
+		ExpiresAt:     time.Now().Add(s.cfg.ChallengeTTL),
+	}
+	if err := s.mfaRepo.CreateChallenge(challenge); err != nil {
+		return nil, errors.NewDatabaseError("create mfa challenge", err)
+	}
+	return &LoginResponse{
+		MFA: &MFAChallengeResponse{
+			MFAToken:      raw,
+			SetupRequired: challenge.SetupRequired,
+			ExpiresIn:     int64(s.cfg.ChallengeTTL / time.Second),
+		},
+	}, nil
+}
+
+// SetupForLogin 角色要求两步验证但尚未绑定的用户在登录过程中获取绑定二维码
+func (s *MFAService) SetupForLogin(req *MFATokenRequest) (*MFASetupResponse, error) {
+	challenge, err := s.findChallenge(req.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+	if !challenge.SetupRequired {
+		return nil, errors.NewBadRequestError("已启用两步验证，请输入动态码")
+	}
+	user, err := s.findUser(challenge.UserID.Int64())
+	if err != nil {
+		return nil, err
+	}
+	return s.setup(user)
+}
+
+// VerifyLogin 完成登录的第二步验证并签发令牌
+// 登录时完成绑定的，同时启用两步验证并返回恢复码
+func (s *MFAService) VerifyLogin(req *MFAVerifyRequest, client ClientInfo) (*MFAVerifyResponse, error) {
+	challenge, err := s.findChallenge(req.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+	userID := challenge.UserID.Int64()
+
+	var (
+		recoveryCodes []string
+		ok            bool
+	)
+	if challenge.SetupRequired {
+		recoveryCodes, ok, err = s.enable(userID, req.Code)
+	} else {
+		ok, err = s.verifyCode(userID, req.Code, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := s.mfaRepo.IncrementChallengeAttempts(challenge.ID); err != nil {
+			return nil, errors.NewDatabaseError("update mfa challenge", err)
+		}
+		if challenge.Attempts+1 >= s.cfg.MaxAttempts {
+			return nil, errors.NewUnauthorizedError("动态码错误次数过多，请重新登录")
+		}
+		return nil, errors.NewUnauthorizedError("动态码错误")
+	}
+
+	completed, err := s.mfaRepo.CompleteChallenge(challenge.ID, time.Now())
+	if err != nil {
+		return nil, errors.NewDatabaseError("complete mfa challenge", err)
+	}
+	if !completed {
+		return nil, errors.NewUnauthorizedError("两步验证已失效，请重新登录")
+	}
+
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive() {
+		return nil, errors.NewForbiddenError("账号已被封禁")
+	}
+	tokens, err := s.tokenService.IssueTokens(user, client)
+	if err != nil {
+		return nil, err
+	}
+	return &MFAVerifyResponse{
+		LoginResponse: LoginResponse{User: user, TokenPair: tokens},
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+// GetStatus 查询用户的两步验证状态
+func (s *MFAService) GetStatus(userID int64) (*MFAStatusResponse, error) {
+	totp, err := s.findTOTP(userID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := s.roleRepo.UserRequiresMFA(userID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("check mfa requirement", err)
+	}
+
+	status := &MFAStatusResponse{Required: required}
+	if totp != nil && totp.IsEnabled() {
+		status.Enabled = true
+		status.EnabledAt = totp.EnabledAt
+		status.RecoveryCodesLeft, err = s.mfaRepo.CountRecoveryCodes(userID)
+		if err != nil {
+			return nil, errors.NewDatabaseError("count recovery codes", err)
+		}
+	}
+	return status, nil
+}
+
+// Setup 生成新的 TOTP 密钥和绑定二维码，输入一次正确的动态码后才启用
+func (s *MFAService) Setup(userID int64) (*MFASetupResponse, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.setup(user)
+}
+
+// Enable 校验动态码并启用两步验证，返回恢复码
+func (s *MFAService) Enable(userID int64, req *MFACodeRequest) (*MFARecoveryCodesResponse, error) {
+	codes, ok, err := s.enable(userID, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.NewBadRequestError("动态码错误")
+	}
+	return &MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable 关闭两步验证，需要当前密码和动态码（或恢复码）；角色要求两步验证时不能关闭
+func (s *MFAService) Disable(userID int64, req *MFADisableRequest) error {
+	required, err := s.roleRepo.UserRequiresMFA(userID)
+	if err != nil {
+		return errors.NewDatabaseError("check mfa requirement", err)
+	}
+	if required {
+		return errors.NewForbiddenError("你的角色要求启用两步验证，不能关闭")
+	}
+
+	user, err := s.findUser(userID)
+	if err != nil {
+		return err
+	}
+	if !utils.CheckPassword(req.Password, user.Password) {
+		return errors.NewBadRequestError("密码错误")
+	}
+	ok, err := s.verifyCode(userID, req.Code, true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.NewBadRequestError("动态码错误")
+	}
+
+	if err := s.mfaRepo.Disable(userID); err != nil {
+		return errors.NewDatabaseError("disable mfa", err)
+	}
+	return nil
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，旧恢复码全部作废；只能使用动态码确认
+func (s *MFAService) RegenerateRecoveryCodes(userID int64, req *MFACodeRequest) (*MFARecoveryCodesResponse, error) {
+	ok, err := s.verifyCode(userID, req.Code, false)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.NewBadRequestError("动态码错误")
+	}
+
+	codes, records, err := s.newRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(userID, records); err != nil {
+		return nil, errors.NewDatabaseError("replace recovery codes", err)
+	}
+	return &MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Reset 管理员重置用户的两步验证，用于用户丢失身份验证器且没有恢复码的情况
+// 角色要求两步验证的用户下次登录时需要重新绑定
+func (s *MFAService) Reset(userID int64) error {
+	if _, err := s.findUser(userID); err != nil {
+		return err
+	}
+	if err := s.mfaRepo.Disable(userID); err != nil {
+		return errors.NewDatabaseError("reset mfa", err)
+	}
+	logger.Info("管理员重置了用户的两步验证", zap.Int64("user_id", userID))
+	return nil
+}
+
+// CleanupExpired 删除已过期的第二步验证
+func (s *MFAService) CleanupExpired() (int64, error) {
+	deleted, err := s.mfaRepo.DeleteExpiredChallenges(time.Now())
+	if err != nil {
+		return 0, errors.NewDatabaseError("delete mfa challenges", err)
+	}
+	return deleted, nil
+}
+
+// StartCleanup 启动过期第二步验证的定时清理任务
+func (s *MFAService) StartCleanup() {
+	var task func()
+	task = func() {
+		count, err := s.CleanupExpired()
+		if err != nil {
+			logger.Error("清理两步验证记录失败", zap.Error(err))
+		} else if count > 0 {
+			logger.Info("已清理两步验证记录", zap.Int64("count", count))
+		}
+		s.timeWheel.AddTask(time.Now().Add(mfaCleanupInterval), task, nil, true) // 不持久化任务
+	}
+	go task()
+}
+
+// state 返回用户是否已启用两步验证、角色是否要求两步验证
+func (s *MFAService) state(userID int64) (bool, bool, error) {
+	totp, err := s.findTOTP(userID)
+	if err != nil {
+		return false, false, err
+	}
+	required, err := s.roleRepo.UserRequiresMFA(userID)
+	if err != nil {
+		return false, false, errors.NewDatabaseError("check mfa requirement", err)
+	}
+	return totp != nil && totp.IsEnabled(), required, nil
+}
+
+// setup 生成并保存新的 TOTP 密钥，返回绑定二维码
+func (s *MFAService) setup(user *models.User) (*MFASetupResponse, error) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, errors.NewInternalServerError("生成密钥失败")
+	}
+	if err := s.mfaRepo.SaveTOTP(&models.UserTOTP{UserID: user.ID, Secret: secret}); err != nil {
+		if err == gorm.ErrDuplicatedKey {
+			return nil, errors.NewConflictError("已启用两步验证")
+		}
+		return nil, errors.NewDatabaseError("save totp", err)
+	}
+
+	uri := utils.TOTPURI(s.cfg.Issuer, user.Username, secret)
+	qrCode, err := utils.QRCodeDataURL(uri, mfaQRCodeSize)
+	if err != nil {
+		return nil, errors.NewInternalServerError("生成二维码失败")
+	}
+	return &MFASetupResponse{Secret: secret, URI: uri, QRCode: qrCode}, nil
+}
+
+// enable 校验绑定中的动态码，正确时启用两步验证并生成恢复码；动态码错误时返回 false
+func (s *MFAService) enable(userID int64, code string) ([]string, bool, error) {
+	totp, err := s.findTOTP(userID)
+	if err != nil {
+		return nil, false, err
+	}
+	if totp == nil {
+		return nil, false, errors.NewBadRequestError("请先获取绑定二维码")
+	}
+	if totp.IsEnabled() {
+		return nil, false, errors.NewConflictError("已启用两步验证")
+	}
+
+	now := time.Now()
+	step, ok := utils.VerifyTOTP(totp.Secret, code, now, mfaTOTPSkew)
+	if !ok {
+		return nil, false, nil
+	}
+
+	codes, records, err := s.newRecoveryCodes(userID)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := s.mfaRepo.Enable(userID, step, now, records); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, false, errors.NewConflictError("已启用两步验证")
+		}
+		return nil, false, errors.NewDatabaseError("enable mfa", err)
+	}
+	return codes, true, nil
+}
+
+// verifyCode 校验已启用用户的动态码，allowRecovery 为 true 时也接受恢复码
+// 动态码只能使用一次，恢复码使用后作废；校验不通过时返回 false
+func (s *MFAService) verifyCode(userID int64, code string, allowRecovery bool) (bool, error) {
+	totp, err := s.findTOTP(userID)
+	if err != nil {
+		return false, err
+	}
+	if totp == nil || !totp.IsEnabled() {
+		return false, errors.NewBadRequestError("未启用两步验证")
+	}
+
+	now := time.Now()
+	if step, ok := utils.VerifyTOTP(totp.Secret, code, now, mfaTOTPSkew); ok {
+		used, err := s.mfaRepo.UseStep(userID, step)
+		if err != nil {
+			return false, errors.NewDatabaseError("update totp", err)
+		}
+		return used, nil
+	}
+	if !allowRecovery {
+		return false, nil
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if len(normalized) != recoveryCodeLength {
+		return false, nil
+	}
+	used, err := s.mfaRepo.UseRecoveryCode(userID, hashToken(normalized), now)
+	if err != nil {
+		return false, errors.NewDatabaseError("use recovery code", err)
+	}
+	if used {
+		logger.Info("用户使用恢复码完成两步验证", zap.Int64("user_id", userID))
+	}
+	return used, nil
+}
+
+// newRecoveryCodes 生成一组恢复码，返回展示给用户的恢复码和待保存的摘要记录
+func (s *MFAService) newRecoveryCodes(userID int64) ([]string, []models.MFARecoveryCode, error) {
+	codes := make([]string, s.cfg.RecoveryCodes)
+	records := make([]models.MFARecoveryCode, s.cfg.RecoveryCodes)
+	max := big.NewInt(int64(len(recoveryCodeChars)))
+	for i := range codes {
+		b := make([]byte, recoveryCodeLength)
+		for j := range b {
+			idx, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return nil, nil, errors.NewInternalServerError("生成恢复码失败")
+			}
+			b[j] = recoveryCodeChars[idx.Int64()]
+		}
+		raw := string(b)
+		codes[i] = raw[:recoveryCodeLength/2] + "-" + raw[recoveryCodeLength/2:]
+		records[i] = models.MFARecoveryCode{UserID: utils.JSONInt64(userID), CodeHash: hashToken(raw)}
+	}
+	return codes, records, nil
+}
+
+// findChallenge 根据令牌查找有效的第二步验证
+func (s *MFAService) findChallenge(raw string) (*models.MFAChallenge, error) {
+	challenge, err := s.mfaRepo.FindChallengeByHash(hashToken(raw))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewUnauthorizedError("两步验证已失效，请重新登录")
+		}
+		return nil, errors.NewDatabaseError("find mfa challenge", err)
+	}
+	if challenge.UsedAt != nil || !time.Now().Before(challenge.ExpiresAt) || challenge.Attempts >= s.cfg.MaxAttempts {
+		return nil, errors.NewUnauthorizedError("两步验证已失效，请重新登录")
+	}
+	return challenge, nil
+}
+
+// findTOTP 查找用户的 TOTP 设置，未绑定时返回 nil
+func (s *MFAService) findTOTP(userID int64) (*models.UserTOTP, error) {
+	totp, err := s.mfaRepo.FindTOTP(userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.NewDatabaseError("find totp", err)
+	}
+	return totp, nil
+}
+
+// findUser 查找用户
+func (s *MFAService) findUser(userID int64) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("用户不存在")
+		}
+		return nil, errors.NewDatabaseError("find user", err)
+	}
+	return user, nil
+}
+
+// normalizeRecoveryCode 去掉恢复码中的分隔符和空白并转为小写
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, code)
+}
@@ -6,6 +6,8 @@ import (
 	"gohotel/pkg/utils"
 	"sync"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // NoticeService 公告业务逻辑层
@@ -123,7 +125,7 @@ func parseNoticeTimeString(timeStr *string) *time.Time {
 }
 
 // CreateNotice 创建公告
-func (s *NoticeService) CreateNotice(req *CreateNoticeRequest) (*models.Notice, error) {
+func (s *NoticeService) CreateNotice(hotelID uint, req *CreateNoticeRequest) (*models.Notice, error) {
 	// 解析时间
 	startTime := parseNoticeTimeString(req.StartTime)
 	endTime := parseNoticeTimeString(req.EndTime)
@@ -144,6 +146,7 @@ func (s *NoticeService) CreateNotice(req *CreateNoticeRequest) (*models.Notice,
 
 	// 创建公告对象
 	notice := &models.Notice{
+		HotelID:   hotelID,
 		Title:     req.Title,
 		LinkURL:   req.LinkURL,
 		Sort:      sort,
@@ -164,24 +167,32 @@ func (s *NoticeService) CreateNotice(req *CreateNoticeRequest) (*models.Notice,
 }
 
 // GetNoticeByID 根据ID获取公告
-func (s *NoticeService) GetNoticeByID(id int64) (*models.Notice, error) {
-	return s.noticeRepo.FindByID(id)
+// 属于其他酒店时视为不存在
+func (s *NoticeService) GetNoticeByID(hotelID uint, id int64) (*models.Notice, error) {
+	notice, err := s.GetNoticeByID(hotelID, id)
+	if err != nil {
+		return nil, err
+	}
+	if notice.HotelID != hotelID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return notice, nil
 }
 
 // GetAllNotices 获取所有公告（带分页）
-func (s *NoticeService) GetAllNotices(page, pageSize int) ([]models.Notice, int64, error) {
-	return s.noticeRepo.FindAll(page, pageSize)
+func (s *NoticeService) GetAllNotices(hotelID uint, page, pageSize int) ([]models.Notice, int64, error) {
+	return s.noticeRepo.FindAll(hotelID, page, pageSize)
 }
 
 // GetActiveNotices 获取激活的公告（前端展示用）
-func (s *NoticeService) GetActiveNotices() ([]models.Notice, error) {
-	return s.noticeRepo.FindActive()
+func (s *NoticeService) GetActiveNotices(hotelID uint) ([]models.Notice, error) {
+	return s.noticeRepo.FindActive(hotelID)
 }
 
 // UpdateNotice 更新公告信息
-func (s *NoticeService) UpdateNotice(id int64, req *UpdateNoticeRequest) (*models.Notice, error) {
+func (s *NoticeService) UpdateNotice(hotelID uint, id int64, req *UpdateNoticeRequest) (*models.Notice, error) {
 	// 获取现有公告
-	notice, err := s.noticeRepo.FindByID(id)
+	notice, err := s.GetNoticeByID(hotelID, id)
 	if err != nil {
 		return nil, err
 	}
@@ -328,8 +339,8 @@ func (s *NoticeService) addNoticeTasks(notice *models.Notice) {
 }
 
 // DeleteNotice 删除公告
-func (s *NoticeService) DeleteNotice(id int64) error {
-	notice, err := s.noticeRepo.FindByID(id)
+func (s *NoticeService) DeleteNotice(hotelID uint, id int64) error {
+	notice, err := s.GetNoticeByID(hotelID, id)
 	if err != nil {
 		return err
 	}
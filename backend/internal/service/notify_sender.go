@@ -0,0 +1,107 @@
+package service
+
+import (
+	"encoding/json"
+	"gohotel/internal/config"
+	"gohotel/pkg/logger"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SMSSender 短信发送通道，接入短信服务商时实现该接口
+type SMSSender interface {
+	SendSMS(phone, message string) error
+}
+
+// EmailSender 邮件发送通道，接入 SMTP 或邮件服务商时实现该接口
+type EmailSender interface {
+	SendEmail(to, subject, body string) error
+}
+
+// MessageSender 同时支持短信和邮件的发送通道
+type MessageSender interface {
+	SMSSender
+	EmailSender
+}
+
+// NewStubSender 根据配置创建开发和测试用的发送通道
+// VERIFY_SENDER 为 file 时写入发件箱文件，否则输出到日志
+func NewStubSender(cfg *config.VerificationConfig) MessageSender {
+	if cfg.Sender == "file" {
+		return NewFileSender(cfg.OutboxFile)
+	}
+	return ConsoleSender{}
+}
+
+// ConsoleSender 将消息输出到日志的发送通道，不会真正发送
+type ConsoleSender struct{}
+
+// SendSMS 输出短信内容
+func (ConsoleSender) SendSMS(phone, message string) error {
+	logger.Info("[短信] 模拟发送", zap.String("to", phone), zap.String("message", message))
+	return nil
+}
+
+// SendEmail 输出邮件内容
+func (ConsoleSender) SendEmail(to, subject, body string) error {
+	logger.Info("[邮件] 模拟发送", zap.String("to", to), zap.String("subject", subject), zap.String("body", body))
+	return nil
+}
+
+// FileSender 将消息逐行追加到发件箱文件（JSON Lines）的发送通道，便于联调时查看
+type FileSender struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// outboxMessage 发件箱中的一条消息
+type outboxMessage struct {
+	Time    time.Time `json:"time"`
+	Channel string    `json:"channel"`
+	To      string    `json:"to"`
+	Subject string    `json:"subject,omitempty"`
+	Body    string    `json:"body"`
+}
+
+// NewFileSender 创建写入发件箱文件的发送通道
+func NewFileSender(path string) *FileSender {
+	return &FileSender{path: path}
+}
+
+// SendSMS 写入短信
+func (s *FileSender) SendSMS(phone, message string) error {
+	return s.write(outboxMessage{Time: time.Now(), Channel: "sms", To: phone, Body: message})
+}
+
+// SendEmail 写入邮件
+func (s *FileSender) SendEmail(to, subject, body string) error {
+	return s.write(outboxMessage{Time: time.Now(), Channel: "email", To: to, Subject: subject, Body: body})
+}
+
+// write 追加一行消息
+func (s *FileSender) write(msg outboxMessage) error {
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if dir := filepath.Dir(s.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"crypto/rand"
+	"fmt"
+	"gohotel/internal/config"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/logger"
+	"gohotel/pkg/utils"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// initialPasswordLength 管理员创建用户时生成的初始密码长度
+	initialPasswordLength = 12
+	// initialPasswordChars 初始密码字符集，去掉了容易混淆的 0/O、1/l/I
+	initialPasswordChars = "ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnpqrstuvwxyz23456789"
+	// passwordTokenCleanupInterval 清理过期设置密码令牌的间隔
+	passwordTokenCleanupInterval = time.Hour
+)
+
+// PasswordResetService 找回密码与邀请业务逻辑层
+// 通过邮件或短信发送一次性、限时的设置密码链接，用于用户找回密码和管理员邀请新用户
+type PasswordResetService struct {
+	resetRepo    *repository.PasswordResetRepository
+	userRepo     *repository.UserRepository
+	tokenService *TokenService
+	smsSender    SMSSender
+	emailSender  EmailSender
+	timeWheel    *utils.MultiTimeWheel
+	cfg          *config.PasswordConfig
+}
+
+// NewPasswordResetService 创建找回密码服务实例
+func NewPasswordResetService(resetRepo *repository.PasswordResetRepository, userRepo *repository.UserRepository, tokenService *TokenService, smsSender SMSSender, emailSender EmailSender, timeWheel *utils.MultiTimeWheel, cfg *config.PasswordConfig) *PasswordResetService {
+	return &PasswordResetService{
+		resetRepo:    resetRepo,
+		userRepo:     userRepo,
+		tokenService: tokenService,
+		smsSender:    smsSender,
+		emailSender:  emailSender,
+		timeWheel:    timeWheel,
+		cfg:          cfg,
+	}
+}
+
+// ForgotPasswordRequest 找回密码请求
+type ForgotPasswordRequest struct {
+	Account string `json:"account" binding:"required"` // 用户名、邮箱或手机号；填写手机号时通过短信发送链接，否则发送到邮箱
+}
+
+// ResetPasswordRequest 设置新密码请求
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`              // 链接中的令牌
+	NewPassword string `json:"new_password" binding:"required,min=6"` // 新密码
+}
+
+// RequestReset 发送找回密码链接
+// 账号不存在、已封禁或申请过于频繁时不发送，但返回相同的结果，避免暴露账号是否存在
+func (s *PasswordResetService) RequestReset(req *ForgotPasswordRequest) error {
+	account := strings.TrimSpace(req.Account)
+	bySMS := phonePattern.MatchString(account)
+
+	var (
+		user *models.User
+		err  error
+	)
+	if strings.Contains(account, "@") {
+		user, err = s.userRepo.FindByEmail(account)
+	} else if bySMS {
+		user, err = s.userRepo.FindByPhone(account)
+	} else {
+		user, err = s.userRepo.FindByUsername(account)
+	}
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return errors.NewDatabaseError("find user", err)
+	}
+	if !user.IsActive() {
+		return nil
+	}
+
+	latest, err := s.resetRepo.FindLatest(user.ID.Int64(), models.PasswordTokenReset)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return errors.NewDatabaseError("find password token", err)
+	}
+	if err == nil && time.Since(latest.CreatedAt) < s.cfg.ResendInterval {
+		return nil
+	}
+
+	link, _, err := s.issue(user, models.PasswordTokenReset, s.cfg.ResetTTL)
+	if err != nil {
+		return err
+	}
+	text := fmt.Sprintf("您正在找回 GoHotel 账号密码，请在 %s内打开以下链接设置新密码：%s 如非本人操作请忽略。", durationText(s.cfg.ResetTTL), link)
+	if bySMS && user.Phone != nil {
+		err = s.smsSender.SendSMS(*user.Phone, "【GoHotel】"+text)
+	} else {
+		err = s.emailSender.SendEmail(user.Email, "【GoHotel】找回密码", text)
+	}
+	if err != nil {
+		logger.Error("发送找回密码链接失败", zap.Int64("user_id", user.ID.Int64()), zap.Error(err))
+		return errors.NewInternalServerError("发送失败，请稍后重试")
+	}
+	return nil
+}
+
+// SendInvite 向管理员创建的用户发送邀请链接，由用户自行设置密码，返回链接的过期时间
+func (s *PasswordResetService) SendInvite(user *models.User) (*time.Time, error) {
+	link, expiresAt, err := s.issue(user, models.PasswordTokenInvite, s.cfg.InviteTTL)
+	if err != nil {
+		return nil, err
+	}
+	text := fmt.Sprintf("管理员为您创建了 GoHotel 账号（用户名：%s），请在 %s内打开以下链接设置登录密码：%s", user.Username, durationText(s.cfg.InviteTTL), link)
+	if err := s.emailSender.SendEmail(user.Email, "【GoHotel】账号开通邀请", text); err != nil {
+		logger.Error("发送邀请链接失败", zap.Int64("user_id", user.ID.Int64()), zap.Error(err))
+		return nil, errors.NewInternalServerError("用户已创建，但邀请邮件发送失败，请让用户通过找回密码设置密码")
+	}
+	return &expiresAt, nil
+}
+
+// ResetPassword 使用链接中的令牌设置新密码，成功后令牌作废、首次登录标记清除、全部登录会话失效
+func (s *PasswordResetService) ResetPassword(req *ResetPasswordRequest) error {
+	token, err := s.resetRepo.FindByHash(hashToken(strings.TrimSpace(req.Token)))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewBadRequestError("链接无效或已过期，请重新申请")
+		}
+		return errors.NewDatabaseError("find password token", err)
+	}
+	now := time.Now()
+	if token.UsedAt != nil || !now.Before(token.ExpiresAt) {
+		return errors.NewBadRequestError("链接无效或已过期，请重新申请")
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return errors.NewInternalServerError("密码加密失败")
+	}
+	if err := s.resetRepo.UseAndSetPassword(token, hashedPassword, now); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			// 同一个令牌被并发使用
+			return errors.NewBadRequestError("链接无效或已过期，请重新申请")
+		}
+		return errors.NewDatabaseError("reset password", err)
+	}
+
+	return s.tokenService.RevokeUserSessions(token.UserID.Int64())
+}
+
+// CleanupExpired 删除已过期的设置密码令牌
+func (s *PasswordResetService) CleanupExpired() (int64, error) {
+	deleted, err := s.resetRepo.DeleteExpired(time.Now())
+	if err != nil {
+		return 0, errors.NewDatabaseError("delete password tokens", err)
+	}
+	return deleted, nil
+}
+
+// StartCleanup 启动过期令牌的定时清理任务
+func (s *PasswordResetService) StartCleanup() {
+	var task func()
+	task = func() {
+		count, err := s.CleanupExpired()
+		if err != nil {
+			logger.Error("清理设置密码令牌失败", zap.Error(err))
+		} else if count > 0 {
+			logger.Info("已清理设置密码令牌", zap.Int64("count", count))
+		}
+		s.timeWheel.AddTask(time.Now().Add(passwordTokenCleanupInterval), task, nil, true) // 不持久化任务
+	}
+	go task()
+}
+
+// issue 签发设置密码令牌，同一用户未使用的旧令牌作废，返回带令牌的链接
+func (s *PasswordResetService) issue(user *models.User, purpose string, ttl time.Duration) (string, time.Time, error) {
+	raw, err := newRandomToken()
+	if err != nil {
+		return "", time.Time{}, errors.NewInternalServerError("生成令牌失败")
+	}
+	now := time.Now()
+	token := &models.PasswordResetToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hashToken(raw),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	if err := s.resetRepo.Replace(token); err != nil {
+		return "", time.Time{}, errors.NewDatabaseError("create password token", err)
+	}
+
+	separator := "?"
+	if strings.Contains(s.cfg.ResetURL, "?") {
+		separator = "&"
+	}
+	return s.cfg.ResetURL + separator + "token=" + url.QueryEscape(raw), token.ExpiresAt, nil
+}
+
+// randomPassword 使用加密随机数生成初始密码
+func randomPassword(n int) (string, error) {
+	b := make([]byte, n)
+	max := big.NewInt(int64(len(initialPasswordChars)))
+	for i := range b {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = initialPasswordChars[idx.Int64()]
+	}
+	return string(b), nil
+}
+
+// durationText 将有效期转换为消息中的中文描述
+func durationText(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		return fmt.Sprintf("%d 小时", int64(d/time.Hour))
+	}
+	minutes := int64(d / time.Minute)
+	if minutes < 1 {
+		minutes = 1
+	}
+	return fmt.Sprintf("%d 分钟", minutes)
+}
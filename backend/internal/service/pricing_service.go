@@ -0,0 +1,606 @@
+package service
+
+import (
+	"gohotel/internal/config"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/logger"
+	"gohotel/pkg/utils"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// PricingService 动态定价业务逻辑层
+// 按房型每日入住率匹配定价规则，定时重算未来日期并写入房价日历，预订按日历逐晚计价
+type PricingService struct {
+	pricingRepo *repository.PricingRepository
+	hotelRepo   *repository.HotelRepository
+	timeWheel   *utils.MultiTimeWheel
+	interval    time.Duration
+	horizonDays int
+}
+
+// NewPricingService 创建动态定价服务实例
+func NewPricingService(pricingRepo *repository.PricingRepository, hotelRepo *repository.HotelRepository, timeWheel *utils.MultiTimeWheel, cfg *config.PricingConfig) *PricingService {
+	return &PricingService{
+		pricingRepo: pricingRepo,
+		hotelRepo:   hotelRepo,
+		timeWheel:   timeWheel,
+		interval:    cfg.Interval,
+		horizonDays: cfg.HorizonDays,
+	}
+}
+
+// PricingRuleRequest 创建/更新定价规则请求
+type PricingRuleRequest struct {
+	Name         string  `json:"name" binding:"required,max=100"`
+	RoomType     string  `json:"room_type" binding:"max=50"`                          // 为空时适用所有房型
+	MinOccupancy float64 `json:"min_occupancy" binding:"gte=0,lt=100"`                // 入住率阈值（百分比）
+	AdjustType   string  `json:"adjust_type" binding:"required,oneof=percent amount"` // 调整方式
+	AdjustValue  float64 `json:"adjust_value" binding:"required,gt=0"`                // 上浮比例（百分比）或金额
+	MaxPrice     float64 `json:"max_price" binding:"gte=0"`                           // 价格上限，0 表示不限
+	Enabled      *bool   `json:"enabled"`                                             // 是否启用，默认启用
+}
+
+// StayDiscountRequest 创建/更新连住折扣档位请求
+type StayDiscountRequest struct {
+	RoomType        string  `json:"room_type" binding:"max=50"`                      // 为空时适用所有房型
+	MinNights       int     `json:"min_nights" binding:"required,gte=2"`             // 最少连住晚数
+	DiscountPercent float64 `json:"discount_percent" binding:"required,gt=0,lt=100"` // 折扣比例（百分比）
+	Enabled         *bool   `json:"enabled"`                                         // 是否启用，默认启用
+}
+
+// LongStayPolicyRequest 设置长住政策请求
+type LongStayPolicyRequest struct {
+	RoomType string  `json:"room_type" binding:"max=50"` // 为空时适用所有房型
+	Deposit  float64 `json:"deposit" binding:"gte=0"`    // 按月付款时收取的押金，0 表示不收
+}
+
+// StayQuote 按晚预订的报价明细
+type StayQuote struct {
+	NightlyPrices  []float64            // 每晚按房价日历计算的价格
+	Subtotal       float64              // 折扣前总价
+	Discount       *models.StayDiscount // 生效的连住折扣档位，为空表示无折扣
+	DiscountAmount float64              // 减免金额
+	Total          float64              // 折扣后总价
+	Deposit        float64              // 按月付款时收取的押金（来自长住政策），0 表示不收
+}
+
+// RatePreview 房型某日的定价预览
+type RatePreview struct {
+	RoomType      string  `json:"room_type"`
+	Date          string  `json:"date"`
+	TotalRooms    int     `json:"total_rooms"`
+	BookedRooms   int     `json:"booked_rooms"`
+	Occupancy     float64 `json:"occupancy"` // 入住率（百分比）
+	BasePrice     float64 `json:"base_price"`
+	CurrentPrice  float64 `json:"current_price"`  // 房价日历中的当前价格
+	ProposedPrice float64 `json:"proposed_price"` // 按当前规则重算后的价格
+	RuleID        *uint   `json:"rule_id"`
+	RuleName      string  `json:"rule_name"`
+	Changed       bool    `json:"changed"` // 重算后价格是否变化
+}
+
+// PricingEvaluation 一次重算的结果
+type PricingEvaluation struct {
+	StartDate    string `json:"start_date"`
+	EndDate      string `json:"end_date"`
+	UpdatedRates int    `json:"updated_rates"` // 写入房价日历的条数
+	PriceChanges int    `json:"price_changes"` // 价格发生变化的条数（即新增的调价记录数）
+}
+
+// ratePlan 房型某日的重算结果
+type ratePlan struct {
+	preview RatePreview
+	current *models.RoomRate
+	rule    *models.PricingRule
+}
+
+// ListRules 获取酒店的定价规则
+func (s *PricingService) ListRules(hotelID uint) ([]models.PricingRule, error) {
+	rules, err := s.pricingRepo.FindRules(hotelID, false)
+	if err != nil {
+		return nil, errors.NewDatabaseError("list pricing rules", err)
+	}
+	return rules, nil
+}
+
+// CreateRule 创建定价规则，下次重算时生效
+func (s *PricingService) CreateRule(hotelID uint, req *PricingRuleRequest) (*models.PricingRule, error) {
+	rule := &models.PricingRule{HotelID: hotelID, Enabled: true}
+	applyPricingRuleRequest(rule, req)
+	if err := s.pricingRepo.CreateRule(rule); err != nil {
+		return nil, errors.NewDatabaseError("create pricing rule", err)
+	}
+	return rule, nil
+}
+
+// UpdateRule 更新定价规则，下次重算时生效
+func (s *PricingService) UpdateRule(hotelID, id uint, req *PricingRuleRequest) (*models.PricingRule, error) {
+	rule, err := s.findRule(hotelID, id)
+	if err != nil {
+		return nil, err
+	}
+	applyPricingRuleRequest(rule, req)
+	if err := s.pricingRepo.UpdateRule(rule); err != nil {
+		return nil, errors.NewDatabaseError("update pricing rule", err)
+	}
+	return rule, nil
+}
+
+// DeleteRule 删除定价规则，下次重算时相关日期恢复原价
+func (s *PricingService) DeleteRule(hotelID, id uint) error {
+	if _, err := s.findRule(hotelID, id); err != nil {
+		return err
+	}
+	if err := s.pricingRepo.DeleteRule(id); err != nil {
+		return errors.NewDatabaseError("delete pricing rule", err)
+	}
+	return nil
+}
+
+// GetCalendar 获取房价日历，结束日期不包含在内
+func (s *PricingService) GetCalendar(hotelID uint, startDate, endDate, roomType string) ([]models.RoomRate, error) {
+	start, end, err := parseCalendarRange(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := s.pricingRepo.FindRates(hotelID, roomType, start, end)
+	if err != nil {
+		return nil, errors.NewDatabaseError("find room rates", err)
+	}
+	return rates, nil
+}
+
+// Preview 按当前规则预览 [startDate, endDate) 的定价，不写入房价日历
+func (s *PricingService) Preview(hotelID uint, startDate, endDate, roomType string) ([]RatePreview, error) {
+	start, end, err := parseCalendarRange(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	plans, err := s.plan(hotelID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	previews := make([]RatePreview, 0, len(plans))
+	for _, p := range plans {
+		if roomType == "" || p.preview.RoomType == roomType {
+			previews = append(previews, p.preview)
+		}
+	}
+	return previews, nil
+}
+
+// ListChangeLogs 获取调价记录（分页）
+func (s *PricingService) ListChangeLogs(hotelID uint, roomType string, page, pageSize int) ([]models.PriceChangeLog, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	logs, total, err := s.pricingRepo.FindChangeLogs(hotelID, roomType, page, pageSize)
+	if err != nil {
+		return nil, 0, errors.NewDatabaseError("list price change logs", err)
+	}
+	return logs, total, nil
+}
+
+// EvaluateHotel 重算酒店从今天起未来若干天的定价，写入房价日历并记录每一次价格变化
+func (s *PricingService) EvaluateHotel(hotelID uint) (*PricingEvaluation, error) {
+	start := pricingToday()
+	end := start.AddDate(0, 0, s.horizonDays)
+	plans, err := s.plan(hotelID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PricingEvaluation{
+		StartDate: start.Format("2006-01-02"),
+		EndDate:   end.Format("2006-01-02"),
+	}
+	var rates []*models.RoomRate
+	var logs []models.PriceChangeLog
+	for _, p := range plans {
+		rate := p.current
+		if rate == nil {
+			// 没有规则生效且日历中没有记录时，无需写入
+			if p.rule == nil {
+				continue
+			}
+			date, _ := time.Parse("2006-01-02", p.preview.Date)
+			rate = &models.RoomRate{HotelID: hotelID, RoomType: p.preview.RoomType, Date: date}
+		} else if rateUpToDate(rate, &p) {
+			continue
+		}
+
+		rate.Occupancy = p.preview.Occupancy
+		rate.BasePrice = p.preview.BasePrice
+		rate.Price = p.preview.ProposedPrice
+		rate.RuleID, rate.RuleName = nil, ""
+		rate.AdjustType, rate.AdjustValue, rate.MaxPrice = "", 0, 0
+		if p.rule != nil {
+			ruleID := p.rule.ID
+			rate.RuleID, rate.RuleName = &ruleID, p.rule.Name
+			rate.AdjustType, rate.AdjustValue, rate.MaxPrice = p.rule.AdjustType, p.rule.AdjustValue, p.rule.MaxPrice
+		}
+		rates = append(rates, rate)
+
+		if p.preview.Changed {
+			logs = append(logs, models.PriceChangeLog{
+				HotelID:   hotelID,
+				RoomType:  rate.RoomType,
+				Date:      rate.Date,
+				OldPrice:  p.preview.CurrentPrice,
+				NewPrice:  p.preview.ProposedPrice,
+				Occupancy: p.preview.Occupancy,
+				RuleID:    rate.RuleID,
+				RuleName:  rate.RuleName,
+			})
+		}
+	}
+
+	if len(rates) > 0 {
+		if err := s.pricingRepo.SaveRates(rates, logs); err != nil {
+			return nil, errors.NewDatabaseError("save room rates", err)
+		}
+	}
+	result.UpdatedRates = len(rates)
+	result.PriceChanges = len(logs)
+	return result, nil
+}
+
+// StartScheduler 启动定时重算任务，立即执行一次，之后按配置的间隔重复执行
+func (s *PricingService) StartScheduler() {
+	var task func()
+	task = func() {
+		s.evaluateAll()
+		s.timeWheel.AddTask(time.Now().Add(s.interval), task, nil, true) // 不持久化任务
+	}
+	go task()
+}
+
+// evaluateAll 重算所有营业中酒店的定价
+func (s *PricingService) evaluateAll() {
+	hotels, err := s.hotelRepo.FindActive("")
+	if err != nil {
+		logger.Error("动态定价加载酒店失败", zap.Error(err))
+		return
+	}
+	for _, hotel := range hotels {
+		result, err := s.EvaluateHotel(hotel.ID)
+		if err != nil {
+			logger.Error("动态定价重算失败", zap.Uint("hotel_id", hotel.ID), zap.Error(err))
+			continue
+		}
+		if result.PriceChanges > 0 {
+			logger.Info("动态定价已更新",
+				zap.Uint("hotel_id", hotel.ID),
+				zap.Int("updated_rates", result.UpdatedRates),
+				zap.Int("price_changes", result.PriceChanges))
+		}
+	}
+}
+
+// QuoteStay 按房价日历计算房间 [checkIn, checkOut) 的总价（已扣除连住折扣）
+func (s *PricingService) QuoteStay(room *models.Room, checkIn, checkOut time.Time) (float64, error) {
+	quote, err := s.QuoteStayDetail(room, checkIn, checkOut)
+	if err != nil {
+		return 0, err
+	}
+	return quote.Total, nil
+}
+
+// QuoteStayDetail 按房价日历逐晚计价，连住晚数达到折扣档位时整单打折
+// 同时匹配多个档位时取晚数要求最高的，晚数相同时指定房型的档位优先
+func (s *PricingService) QuoteStayDetail(room *models.Room, checkIn, checkOut time.Time) (*StayQuote, error) {
+	rates, err := s.pricingRepo.FindRates(room.HotelID, room.RoomType, checkIn, checkOut)
+	if err != nil {
+		return nil, errors.NewDatabaseError("find room rates", err)
+	}
+	rateByDate := make(map[string]*models.RoomRate, len(rates))
+	for i := range rates {
+		rateByDate[rates[i].Date.Format("2006-01-02")] = &rates[i]
+	}
+
+	dates := calendarDates(checkIn, checkOut)
+	quote := &StayQuote{NightlyPrices: make([]float64, len(dates))}
+	for i, date := range dates {
+		price := room.Price
+		if rate, ok := rateByDate[date.Format("2006-01-02")]; ok {
+			price = rate.Apply(room.Price)
+		}
+		quote.NightlyPrices[i] = price
+		quote.Subtotal += price
+	}
+	quote.Subtotal = math.Round(quote.Subtotal*100) / 100
+
+	discounts, err := s.pricingRepo.FindStayDiscounts(room.HotelID, true)
+	if err != nil {
+		return nil, errors.NewDatabaseError("find stay discounts", err)
+	}
+	for i := range discounts {
+		d := &discounts[i]
+		if !d.Matches(room.RoomType, len(dates)) {
+			continue
+		}
+		if quote.Discount == nil || d.MinNights > quote.Discount.MinNights ||
+			(d.MinNights == quote.Discount.MinNights && quote.Discount.RoomType == "" && d.RoomType != "") {
+			quote.Discount = d
+		}
+	}
+	if quote.Discount != nil {
+		quote.DiscountAmount = math.Round(quote.Subtotal*quote.Discount.DiscountPercent) / 100
+	}
+	quote.Total = math.Round((quote.Subtotal-quote.DiscountAmount)*100) / 100
+
+	policies, err := s.pricingRepo.FindLongStayPolicies(room.HotelID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("find long stay policies", err)
+	}
+	quote.Deposit = longStayDeposit(policies, room.RoomType)
+	return quote, nil
+}
+
+// longStayDeposit 返回房型按月付款时的押金，指定房型的政策优先于适用所有房型的政策
+func longStayDeposit(policies []models.LongStayPolicy, roomType string) float64 {
+	deposit := 0.0
+	for _, p := range policies {
+		if p.RoomType == roomType {
+			return p.Deposit
+		}
+		if p.RoomType == "" {
+			deposit = p.Deposit
+		}
+	}
+	return deposit
+}
+
+// ListStayDiscounts 获取酒店的连住折扣档位
+func (s *PricingService) ListStayDiscounts(hotelID uint) ([]models.StayDiscount, error) {
+	discounts, err := s.pricingRepo.FindStayDiscounts(hotelID, false)
+	if err != nil {
+		return nil, errors.NewDatabaseError("list stay discounts", err)
+	}
+	return discounts, nil
+}
+
+// CreateStayDiscount 创建连住折扣档位，对之后创建的预订生效
+func (s *PricingService) CreateStayDiscount(hotelID uint, req *StayDiscountRequest) (*models.StayDiscount, error) {
+	discount := &models.StayDiscount{HotelID: hotelID, Enabled: true}
+	applyStayDiscountRequest(discount, req)
+	if err := s.pricingRepo.CreateStayDiscount(discount); err != nil {
+		return nil, errors.NewDatabaseError("create stay discount", err)
+	}
+	return discount, nil
+}
+
+// UpdateStayDiscount 更新连住折扣档位，已创建的预订不受影响
+func (s *PricingService) UpdateStayDiscount(hotelID, id uint, req *StayDiscountRequest) (*models.StayDiscount, error) {
+	discount, err := s.findStayDiscount(hotelID, id)
+	if err != nil {
+		return nil, err
+	}
+	applyStayDiscountRequest(discount, req)
+	if err := s.pricingRepo.UpdateStayDiscount(discount); err != nil {
+		return nil, errors.NewDatabaseError("update stay discount", err)
+	}
+	return discount, nil
+}
+
+// DeleteStayDiscount 删除连住折扣档位
+func (s *PricingService) DeleteStayDiscount(hotelID, id uint) error {
+	if _, err := s.findStayDiscount(hotelID, id); err != nil {
+		return err
+	}
+	if err := s.pricingRepo.DeleteStayDiscount(id); err != nil {
+		return errors.NewDatabaseError("delete stay discount", err)
+	}
+	return nil
+}
+
+// ListLongStayPolicies 获取酒店的长住政策
+func (s *PricingService) ListLongStayPolicies(hotelID uint) ([]models.LongStayPolicy, error) {
+	policies, err := s.pricingRepo.FindLongStayPolicies(hotelID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("list long stay policies", err)
+	}
+	return policies, nil
+}
+
+// SaveLongStayPolicy 设置房型的长住政策，房型已有政策时更新，已创建的预订不受影响
+func (s *PricingService) SaveLongStayPolicy(hotelID uint, req *LongStayPolicyRequest) (*models.LongStayPolicy, error) {
+	policy, err := s.pricingRepo.FindLongStayPolicy(hotelID, req.RoomType)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, errors.NewDatabaseError("find long stay policy", err)
+		}
+		policy = &models.LongStayPolicy{HotelID: hotelID, RoomType: req.RoomType}
+	}
+	policy.Deposit = req.Deposit
+	if err := s.pricingRepo.SaveLongStayPolicy(policy); err != nil {
+		return nil, errors.NewDatabaseError("save long stay policy", err)
+	}
+	return policy, nil
+}
+
+// DeleteLongStayPolicy 删除长住政策
+func (s *PricingService) DeleteLongStayPolicy(hotelID, id uint) error {
+	policy, err := s.pricingRepo.FindLongStayPolicyByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewNotFoundError("长住政策不存在")
+		}
+		return errors.NewDatabaseError("find long stay policy", err)
+	}
+	if policy.HotelID != hotelID {
+		return errors.NewNotFoundError("长住政策不存在")
+	}
+	if err := s.pricingRepo.DeleteLongStayPolicy(id); err != nil {
+		return errors.NewDatabaseError("delete long stay policy", err)
+	}
+	return nil
+}
+
+// plan 计算 [start, end) 内每个房型每天的入住率和应生效的规则
+func (s *PricingService) plan(hotelID uint, start, end time.Time) ([]ratePlan, error) {
+	stats, err := s.pricingRepo.FindRoomTypeStats(hotelID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("find room type stats", err)
+	}
+	rules, err := s.pricingRepo.FindRules(hotelID, true)
+	if err != nil {
+		return nil, errors.NewDatabaseError("find pricing rules", err)
+	}
+	stays, err := s.pricingRepo.FindActiveStays(hotelID, start, end)
+	if err != nil {
+		return nil, errors.NewDatabaseError("find active stays", err)
+	}
+	rates, err := s.pricingRepo.FindRates(hotelID, "", start, end)
+	if err != nil {
+		return nil, errors.NewDatabaseError("find room rates", err)
+	}
+
+	current := make(map[string]*models.RoomRate, len(rates))
+	for i := range rates {
+		current[rates[i].RoomType+"|"+rates[i].Date.Format("2006-01-02")] = &rates[i]
+	}
+
+	dates := calendarDates(start, end)
+	plans := make([]ratePlan, 0, len(stats)*len(dates))
+	for _, stat := range stats {
+		for _, date := range dates {
+			day := date.Format("2006-01-02")
+			booked := 0
+			for _, stay := range stays {
+				if stay.RoomType == stat.RoomType && !stay.CheckIn.After(date) && stay.CheckOut.After(date) {
+					booked++
+				}
+			}
+			occupancy := 0.0
+			if stat.TotalRooms > 0 {
+				occupancy = math.Round(float64(booked)/float64(stat.TotalRooms)*10000) / 100
+			}
+
+			p := ratePlan{
+				preview: RatePreview{
+					RoomType:     stat.RoomType,
+					Date:         day,
+					TotalRooms:   stat.TotalRooms,
+					BookedRooms:  booked,
+					Occupancy:    occupancy,
+					BasePrice:    stat.BasePrice,
+					CurrentPrice: stat.BasePrice,
+				},
+				current: current[stat.RoomType+"|"+day],
+				rule:    matchPricingRule(rules, stat.RoomType, occupancy),
+			}
+			if p.current != nil {
+				p.preview.CurrentPrice = p.current.Price
+			}
+			p.preview.ProposedPrice = stat.BasePrice
+			if p.rule != nil {
+				ruleID := p.rule.ID
+				p.preview.RuleID, p.preview.RuleName = &ruleID, p.rule.Name
+				p.preview.ProposedPrice = models.AdjustPrice(stat.BasePrice, p.rule.AdjustType, p.rule.AdjustValue, p.rule.MaxPrice)
+			}
+			p.preview.Changed = p.preview.ProposedPrice != p.preview.CurrentPrice
+			plans = append(plans, p)
+		}
+	}
+	return plans, nil
+}
+
+// matchPricingRule 选择房型在该入住率下生效的规则
+// 入住率超过阈值的规则中取阈值最高的；阈值相同时指定房型的规则优先，其次是先创建的规则
+func matchPricingRule(rules []models.PricingRule, roomType string, occupancy float64) *models.PricingRule {
+	var matched *models.PricingRule
+	for i := range rules {
+		r := &rules[i]
+		if (r.RoomType != "" && r.RoomType != roomType) || occupancy <= r.MinOccupancy {
+			continue
+		}
+		if matched == nil || r.MinOccupancy > matched.MinOccupancy ||
+			(r.MinOccupancy == matched.MinOccupancy && matched.RoomType == "" && r.RoomType != "") {
+			matched = r
+		}
+	}
+	return matched
+}
+
+// rateUpToDate 判断日历记录与重算结果是否一致，一致时无需写入
+func rateUpToDate(rate *models.RoomRate, p *ratePlan) bool {
+	if rate.Occupancy != p.preview.Occupancy || rate.BasePrice != p.preview.BasePrice || p.preview.Changed {
+		return false
+	}
+	if rate.RuleID == nil || p.rule == nil {
+		return rate.RuleID == nil && p.rule == nil
+	}
+	return *rate.RuleID == p.rule.ID && rate.RuleName == p.rule.Name && rate.AdjustType == p.rule.AdjustType &&
+		rate.AdjustValue == p.rule.AdjustValue && rate.MaxPrice == p.rule.MaxPrice
+}
+
+// pricingToday 返回本地日期的今天（UTC 零点，与预订日期的存储方式一致）
+func pricingToday() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// applyPricingRuleRequest 将请求中的字段写入规则
+func applyPricingRuleRequest(rule *models.PricingRule, req *PricingRuleRequest) {
+	rule.Name = req.Name
+	rule.RoomType = req.RoomType
+	rule.MinOccupancy = req.MinOccupancy
+	rule.AdjustType = req.AdjustType
+	rule.AdjustValue = req.AdjustValue
+	rule.MaxPrice = req.MaxPrice
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+}
+
+// findRule 查找酒店的定价规则，不属于该酒店时视为不存在
+func (s *PricingService) findRule(hotelID, id uint) (*models.PricingRule, error) {
+	rule, err := s.pricingRepo.FindRuleByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("定价规则不存在")
+		}
+		return nil, errors.NewDatabaseError("find pricing rule", err)
+	}
+	if rule.HotelID != hotelID {
+		return nil, errors.NewNotFoundError("定价规则不存在")
+	}
+	return rule, nil
+}
+
+// applyStayDiscountRequest 将请求中的字段写入连住折扣档位
+func applyStayDiscountRequest(discount *models.StayDiscount, req *StayDiscountRequest) {
+	discount.RoomType = req.RoomType
+	discount.MinNights = req.MinNights
+	discount.DiscountPercent = req.DiscountPercent
+	if req.Enabled != nil {
+		discount.Enabled = *req.Enabled
+	}
+}
+
+// findStayDiscount 查找酒店的连住折扣档位，不属于该酒店时视为不存在
+func (s *PricingService) findStayDiscount(hotelID, id uint) (*models.StayDiscount, error) {
+	discount, err := s.pricingRepo.FindStayDiscountByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("连住折扣档位不存在")
+		}
+		return nil, errors.NewDatabaseError("find stay discount", err)
+	}
+	if discount.HotelID != hotelID {
+		return nil, errors.NewNotFoundError("连住折扣档位不存在")
+	}
+	return discount, nil
+}
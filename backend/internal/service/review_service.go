@@ -0,0 +1,258 @@
+package service
+
+import (
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReviewService 住客评价业务逻辑层
+type ReviewService struct {
+	reviewRepo  *repository.ReviewRepository
+	bookingRepo *repository.BookingRepository
+	userRepo    *repository.UserRepository
+	cosService  *CosService
+}
+
+// NewReviewService 创建评价服务实例
+func NewReviewService(
+	reviewRepo *repository.ReviewRepository,
+	bookingRepo *repository.BookingRepository,
+	userRepo *repository.UserRepository,
+	cosService *CosService,
+) *ReviewService {
+	return &ReviewService{
+		reviewRepo:  reviewRepo,
+		bookingRepo: bookingRepo,
+		userRepo:    userRepo,
+		cosService:  cosService,
+	}
+}
+
+// CreateReviewRequest 创建评价请求
+type CreateReviewRequest struct {
+	BookingID     utils.JSONInt64 `json:"booking_id" binding:"required"`
+	Cleanliness   int             `json:"cleanliness" binding:"required,min=1,max=5"`
+	Service       int             `json:"service" binding:"required,min=1,max=5"`
+	Location      int             `json:"location" binding:"required,min=1,max=5"`
+	Content       string          `json:"content" binding:"max=2000"`
+	PhotoTempURLs []string        `json:"photo_temp_urls" binding:"max=9"` // 通过 /api/upload/image（type=review）上传的临时图片
+}
+
+// HideReviewRequest 隐藏评价请求
+type HideReviewRequest struct {
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+// ReplyReviewRequest 回复评价请求
+type ReplyReviewRequest struct {
+	Reply string `json:"reply" binding:"required,max=1000"`
+}
+
+// CreateReview 评价已退房的预订，每个预订只能评价一次
+func (s *ReviewService) CreateReview(userID int64, req *CreateReviewRequest) (*models.Review, error) {
+	// 1. 检查预订归属和状态
+	booking, err := s.bookingRepo.FindByID(int64(req.BookingID))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("预订不存在")
+		}
+		return nil, errors.NewDatabaseError("find booking", err)
+	}
+	if int64(booking.UserID) != userID {
+		return nil, errors.NewForbiddenError("无权评价此预订")
+	}
+	if booking.Status != "checkout" {
+		return nil, errors.NewBadRequestError("只能评价已退房的预订")
+	}
+
+	// 2. 检查是否已评价
+	exists, err := s.reviewRepo.ExistsByBookingID(int64(booking.ID))
+	if err != nil {
+		return nil, errors.NewDatabaseError("check review", err)
+	}
+	if exists {
+		return nil, errors.NewConflictError("该预订已评价")
+	}
+
+	// 3. 确认评价图片
+	photos, err := confirmTempUploads(s.cosService, req.PhotoTempURLs)
+	if err != nil {
+		return nil, err
+	}
+	photosJSON, err := encodePhotos(photos)
+	if err != nil {
+		deleteCosFiles(s.cosService, photos)
+		return nil, err
+	}
+
+	// 4. 创建评价
+	review := &models.Review{
+		HotelID:        booking.HotelID,
+		BookingID:      booking.ID,
+		UserID:         booking.UserID,
+		RoomID:         booking.RoomID,
+		RoomType:       booking.Room.RoomType,
+		ReviewerName:   maskReviewerName(booking.User.Username),
+		ReviewerAvatar: booking.User.Avatar,
+		Cleanliness:    req.Cleanliness,
+		Service:        req.Service,
+		Location:       req.Location,
+		Overall:        roundRating(float64(req.Cleanliness+req.Service+req.Location) / 3),
+		Content:        req.Content,
+		Photos:         photosJSON,
+		Status:         "visible",
+	}
+
+	if err := s.reviewRepo.Create(review); err != nil {
+		deleteCosFiles(s.cosService, photos)
+		return nil, errors.NewDatabaseError("create review", err)
+	}
+
+	return review, nil
+}
+
+// ListPublicReviews 获取公开评价列表（分页）
+func (s *ReviewService) ListPublicReviews(hotelID uint, page, pageSize int, roomType string, roomID int64) ([]models.Review, int64, error) {
+	page, pageSize = normalizeReviewPage(page, pageSize)
+
+	reviews, total, err := s.reviewRepo.FindAll(hotelID, page, pageSize, "visible", roomType, roomID)
+	if err != nil {
+		return nil, 0, errors.NewDatabaseError("list reviews", err)
+	}
+	return reviews, total, nil
+}
+
+// GetMyReviews 获取当前用户的评价（分页）
+func (s *ReviewService) GetMyReviews(userID int64, page, pageSize int) ([]models.Review, int64, error) {
+	page, pageSize = normalizeReviewPage(page, pageSize)
+
+	reviews, total, err := s.reviewRepo.FindByUserID(userID, page, pageSize)
+	if err != nil {
+		return nil, 0, errors.NewDatabaseError("list user reviews", err)
+	}
+	return reviews, total, nil
+}
+
+// GetRatingSummary 按房型汇总评分，roomType 为空时返回所有房型
+// 只统计公开展示的评价
+func (s *ReviewService) GetRatingSummary(hotelID uint, roomType string) ([]models.RoomTypeRating, error) {
+	ratings, err := s.reviewRepo.AggregateByRoomType(hotelID, roomType)
+	if err != nil {
+		return nil, errors.NewDatabaseError("aggregate ratings", err)
+	}
+
+	for i := range ratings {
+		ratings[i].AvgCleanliness = roundRating(ratings[i].AvgCleanliness)
+		ratings[i].AvgService = roundRating(ratings[i].AvgService)
+		ratings[i].AvgLocation = roundRating(ratings[i].AvgLocation)
+		ratings[i].AvgOverall = roundRating(ratings[i].AvgOverall)
+	}
+	return ratings, nil
+}
+
+// ListAllReviews 获取评价列表（管理员，包含已隐藏的评价）
+func (s *ReviewService) ListAllReviews(hotelID uint, page, pageSize int, status, roomType string, roomID int64) ([]models.Review, int64, error) {
+	page, pageSize = normalizeReviewPage(page, pageSize)
+
+	reviews, total, err := s.reviewRepo.FindAll(hotelID, page, pageSize, status, roomType, roomID)
+	if err != nil {
+		return nil, 0, errors.NewDatabaseError("list reviews", err)
+	}
+	return reviews, total, nil
+}
+
+// HideReview 隐藏评价（管理员）
+func (s *ReviewService) HideReview(hotelID, id uint, req *HideReviewRequest) (*models.Review, error) {
+	review, err := s.findReview(hotelID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	review.Status = "hidden"
+	review.HiddenReason = req.Reason
+	if err := s.reviewRepo.Update(review); err != nil {
+		return nil, errors.NewDatabaseError("hide review", err)
+	}
+	return review, nil
+}
+
+// ShowReview 恢复展示评价（管理员）
+func (s *ReviewService) ShowReview(hotelID, id uint) (*models.Review, error) {
+	review, err := s.findReview(hotelID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	review.Status = "visible"
+	review.HiddenReason = ""
+	if err := s.reviewRepo.Update(review); err != nil {
+		return nil, errors.NewDatabaseError("show review", err)
+	}
+	return review, nil
+}
+
+// ReplyReview 回复评价（管理员），再次回复会覆盖之前的回复
+func (s *ReviewService) ReplyReview(hotelID, id uint, req *ReplyReviewRequest) (*models.Review, error) {
+	review, err := s.findReview(hotelID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	review.Reply = req.Reply
+	review.RepliedAt = &now
+	if err := s.reviewRepo.Update(review); err != nil {
+		return nil, errors.NewDatabaseError("reply review", err)
+	}
+	return review, nil
+}
+
+// findReview 查找酒店内的评价，不存在或属于其他酒店时返回 404
+func (s *ReviewService) findReview(hotelID, id uint) (*models.Review, error) {
+	review, err := s.reviewRepo.FindByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("评价不存在")
+		}
+		return nil, errors.NewDatabaseError("find review", err)
+	}
+	if review.HotelID != hotelID {
+		return nil, errors.NewNotFoundError("评价不存在")
+	}
+	return review, nil
+}
+
+// normalizeReviewPage 校正分页参数
+func normalizeReviewPage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+	return page, pageSize
+}
+
+// roundRating 评分保留一位小数
+func roundRating(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// maskReviewerName 评价人昵称脱敏，只保留首尾字符
+func maskReviewerName(name string) string {
+	runes := []rune(name)
+	switch len(runes) {
+	case 0:
+		return "匿名用户"
+	case 1, 2:
+		return string(runes[0]) + "***"
+	default:
+		return string(runes[0]) + "***" + string(runes[len(runes)-1])
+	}
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/pkg/errors"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// permissionCacheTTL 用户权限缓存的有效期
+// 本实例修改角色或分配时会立即清空缓存，有效期用于多实例部署时兜底
+const permissionCacheTTL = time.Minute
+
+// RoleService 角色与权限业务逻辑层
+// 员工通过角色获得权限，每个请求都会检查权限，因此按用户缓存权限集合
+type RoleService struct {
+	roleRepo   *repository.RoleRepository
+	userRepo   *repository.UserRepository
+	cache      map[int64]cachedPermissions
+	cacheMutex sync.RWMutex
+}
+
+// cachedPermissions 缓存的用户权限集合
+type cachedPermissions struct {
+	permissions map[string]bool
+	expiresAt   time.Time
+}
+
+// NewRoleService 创建角色服务实例
+func NewRoleService(roleRepo *repository.RoleRepository, userRepo *repository.UserRepository) *RoleService {
+	return &RoleService{
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+		cache:    make(map[int64]cachedPermissions),
+	}
+}
+
+// CreateRoleRequest 创建角色请求
+type CreateRoleRequest struct {
+	Code        string   `json:"code" binding:"required,max=50"` // 角色编码（唯一），小写字母开头，只能包含小写字母、数字和下划线
+	Name        string   `json:"name" binding:"required,max=50"`
+	Description string   `json:"description" binding:"max=255"`
+	Permissions []string `json:"permissions"` // 权限码列表
+	RequireMFA  bool     `json:"require_mfa"` // 是否要求拥有该角色的用户启用两步验证
+}
+
+// UpdateRoleRequest 更新角色请求
+type UpdateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=50"`
+	Description string   `json:"description" binding:"max=255"`
+	Permissions []string `json:"permissions"` // 权限码列表，整体替换
+	RequireMFA  bool     `json:"require_mfa"` // 是否要求两步验证，已登录的用户下次登录时生效
+}
+
+// SetUserRolesRequest 设置用户角色请求
+type SetUserRolesRequest struct {
+	RoleIDs []uint `json:"role_ids"` // 为空时取消用户的全部角色
+}
+
+// ListPermissions 获取系统支持的全部权限
+func (s *RoleService) ListPermissions() []models.PermissionInfo {
+	return models.Permissions
+}
+
+// ListRoles 获取全部角色
+func (s *RoleService) ListRoles() ([]models.Role, error) {
+	roles, err := s.roleRepo.FindAll()
+	if err != nil {
+		return nil, errors.NewDatabaseError("list roles", err)
+	}
+	return roles, nil
+}
+
+// roleCodePattern 角色编码格式
+var roleCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// CreateRole 创建自定义角色
+func (s *RoleService) CreateRole(req *CreateRoleRequest) (*models.Role, error) {
+	if !roleCodePattern.MatchString(req.Code) {
+		return nil, errors.NewBadRequestError("角色编码只能包含小写字母、数字和下划线，且以字母开头")
+	}
+	permissions, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := s.roleRepo.ExistsByCode(req.Code)
+	if err != nil {
+		return nil, errors.NewDatabaseError("check role code", err)
+	}
+	if exists {
+		return nil, errors.NewConflictError("角色编码已存在")
+	}
+
+	role := &models.Role{
+		Code:        req.Code,
+		Name:        req.Name,
+		Description: req.Description,
+		RequireMFA:  req.RequireMFA,
+		Permissions: make([]models.RolePermission, len(permissions)),
+	}
+	for i, p := range permissions {
+		role.Permissions[i] = models.RolePermission{Permission: p}
+	}
+	if err := s.roleRepo.Create(role); err != nil {
+		return nil, errors.NewDatabaseError("create role", err)
+	}
+	return role, nil
+}
+
+// UpdateRole 更新角色名称和权限，超级管理员的权限不可修改
+func (s *RoleService) UpdateRole(id uint, req *UpdateRoleRequest) (*models.Role, error) {
+	role, err := s.findRole(id)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := []string{models.PermissionAll}
+	if role.Code != models.RoleSuperAdmin {
+		permissions, err = normalizePermissions(req.Permissions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	role.Name = req.Name
+	role.Description = req.Description
+	role.RequireMFA = req.RequireMFA
+	if err := s.roleRepo.UpdateWithPermissions(role, permissions); err != nil {
+		return nil, errors.NewDatabaseError("update role", err)
+	}
+	s.clearCache()
+	return role, nil
+}
+
+// DeleteRole 删除自定义角色，内置角色不可删除
+func (s *RoleService) DeleteRole(id uint) error {
+	role, err := s.findRole(id)
+	if err != nil {
+		return err
+	}
+	if role.BuiltIn {
+		return errors.NewBadRequestError("内置角色不可删除")
+	}
+	if err := s.roleRepo.Delete(id); err != nil {
+		return errors.NewDatabaseError("delete role", err)
+	}
+	s.clearCache()
+	return nil
+}
+
+// GetUserRoles 获取用户的角色
+func (s *RoleService) GetUserRoles(userID int64) ([]models.Role, error) {
+	if err := s.checkUserExists(userID); err != nil {
+		return nil, err
+	}
+	roles, err := s.roleRepo.FindUserRoles(userID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("find user roles", err)
+	}
+	return roles, nil
+}
+
+// SetUserRoles 替换用户的角色，系统至少保留一名超级管理员
+func (s *RoleService) SetUserRoles(userID int64, req *SetUserRolesRequest) ([]models.Role, error) {
+	if err := s.checkUserExists(userID); err != nil {
+		return nil, err
+	}
+
+	roleIDs := make([]uint, 0, len(req.RoleIDs))
+	seen := make(map[uint]bool, len(req.RoleIDs))
+	for _, id := range req.RoleIDs {
+		if !seen[id] {
+			seen[id] = true
+			roleIDs = append(roleIDs, id)
+		}
+	}
+	roles, err := s.roleRepo.FindByIDs(roleIDs)
+	if err != nil {
+		return nil, errors.NewDatabaseError("find roles", err)
+	}
+	if len(roles) != len(roleIDs) {
+		return nil, errors.NewNotFoundError("角色不存在")
+	}
+
+	superAdmin, err := s.roleRepo.FindByCode(models.RoleSuperAdmin)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewDatabaseError("find super admin role", err)
+	}
+	if err == nil && !seen[superAdmin.ID] {
+		// 取消的是最后一名超级管理员时拒绝
+		total, err := s.roleRepo.CountUsersWithRole(superAdmin.ID, nil)
+		if err != nil {
+			return nil, errors.NewDatabaseError("count super admins", err)
+		}
+		others, err := s.roleRepo.CountUsersWithRole(superAdmin.ID, []int64{userID})
+		if err != nil {
+			return nil, errors.NewDatabaseError("count super admins", err)
+		}
+		if total > others && others == 0 {
+			return nil, errors.NewBadRequestError("系统至少需要保留一名超级管理员")
+		}
+	}
+
+	if err := s.roleRepo.SetUserRoles(userID, roleIDs); err != nil {
+		return nil, errors.NewDatabaseError("set user roles", err)
+	}
+	s.invalidate(userID)
+	return s.GetUserRoles(userID)
+}
+
+// GetUserPermissions 获取用户的全部权限码
+func (s *RoleService) GetUserPermissions(userID int64) ([]string, error) {
+	permissions, err := s.loadPermissions(userID)
+	if err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, len(permissions))
+	for code := range permissions {
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// HasPermissions 判断用户是否拥有全部指定权限，拥有通配权限时始终为 true
+func (s *RoleService) HasPermissions(userID int64, required ...string) (bool, error) {
+	permissions, err := s.loadPermissions(userID)
+	if err != nil {
+		return false, err
+	}
+	if permissions[models.PermissionAll] {
+		return true, nil
+	}
+	for _, p := range required {
+		if !permissions[p] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// loadPermissions 读取用户权限集合，优先使用缓存
+func (s *RoleService) loadPermissions(userID int64) (map[string]bool, error) {
+	s.cacheMutex.RLock()
+	cached, ok := s.cache[userID]
+	s.cacheMutex.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.permissions, nil
+	}
+
+	codes, err := s.roleRepo.FindUserPermissions(userID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("find user permissions", err)
+	}
+	permissions := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		permissions[code] = true
+	}
+
+	s.cacheMutex.Lock()
+	s.cache[userID] = cachedPermissions{permissions: permissions, expiresAt: time.Now().Add(permissionCacheTTL)}
+	s.cacheMutex.Unlock()
+	return permissions, nil
+}
+
+// invalidate 清除单个用户的权限缓存
+func (s *RoleService) invalidate(userID int64) {
+	s.cacheMutex.Lock()
+	delete(s.cache, userID)
+	s.cacheMutex.Unlock()
+}
+
+// clearCache 角色权限变化时清空全部缓存
+func (s *RoleService) clearCache() {
+	s.cacheMutex.Lock()
+	s.cache = make(map[int64]cachedPermissions)
+	s.cacheMutex.Unlock()
+}
+
+// findRole 根据 ID 查找角色
+func (s *RoleService) findRole(id uint) (*models.Role, error) {
+	role, err := s.roleRepo.FindByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("角色不存在")
+		}
+		return nil, errors.NewDatabaseError("find role", err)
+	}
+	return role, nil
+}
+
+// checkUserExists 检查用户是否存在
+func (s *RoleService) checkUserExists(userID int64) error {
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewNotFoundError("用户不存在")
+		}
+		return errors.NewDatabaseError("find user", err)
+	}
+	return nil
+}
+
+// normalizePermissions 校验并去重权限码，自定义角色不能拥有通配权限
+func normalizePermissions(codes []string) ([]string, error) {
+	seen := make(map[string]bool, len(codes))
+	result := make([]string, 0, len(codes))
+	for _, code := range codes {
+		if code == models.PermissionAll || !models.IsValidPermission(code) {
+			return nil, errors.NewBadRequestError("无效的权限: " + strconv.Quote(code))
+		}
+		if !seen[code] {
+			seen[code] = true
+			result = append(result, code)
+		}
+	}
+	return result, nil
+}
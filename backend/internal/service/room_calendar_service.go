@@ -0,0 +1,246 @@
+package service
+
+import (
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"time"
+)
+
+// 房态日历单元格状态
+const (
+	CalendarCellFree       = "free"         // 空闲
+	CalendarCellOutOfOrder = "out_of_order" // 停用（维修等）
+)
+
+// maxCalendarDays 单次查询房态日历的最大天数
+const maxCalendarDays = 92
+
+// RoomCalendarService 房态日历业务逻辑层
+type RoomCalendarService struct {
+	roomRepo      *repository.RoomRepository
+	bookingRepo   *repository.BookingRepository
+	workOrderRepo *repository.WorkOrderRepository
+}
+
+// NewRoomCalendarService 创建房态日历服务实例
+func NewRoomCalendarService(roomRepo *repository.RoomRepository, bookingRepo *repository.BookingRepository, workOrderRepo *repository.WorkOrderRepository) *RoomCalendarService {
+	return &RoomCalendarService{
+		roomRepo:      roomRepo,
+		bookingRepo:   bookingRepo,
+		workOrderRepo: workOrderRepo,
+	}
+}
+
+// CalendarCell 房态图中的一个格子（某房间某一晚）
+type CalendarCell struct {
+	Date        string           `json:"date"`                    // 日期，格式: 2024-01-01
+	Status      string           `json:"status"`                  // free, out_of_order 或预订状态 pending, confirmed, checkin
+	BookingID   *utils.JSONInt64 `json:"booking_id,omitempty"`    // 预订 ID（空闲时为空）
+	GuestName   string           `json:"guest_name,omitempty"`    // 入住人姓名
+	IsArrival   bool             `json:"is_arrival,omitempty"`    // 是否为入住当晚（便于前端绘制色块起点）
+	WorkOrderID *uint            `json:"work_order_id,omitempty"` // 停用对应的维修工单 ID
+}
+
+// CalendarRoomRow 房态图中的一行（一个房间）
+type CalendarRoomRow struct {
+	RoomID             uint           `json:"room_id"`
+	RoomNumber         string         `json:"room_number"`
+	RoomType           string         `json:"room_type"`
+	Floor              int            `json:"floor"`
+	HousekeepingStatus string         `json:"housekeeping_status"` // 当前清洁状态，便于前台区分脏房
+	Cells              []CalendarCell `json:"cells"`
+}
+
+// RoomCalendar 前台房态图（房间 × 日期）
+type RoomCalendar struct {
+	StartDate string            `json:"start_date"`
+	EndDate   string            `json:"end_date"`
+	Dates     []string          `json:"dates"`
+	Rooms     []CalendarRoomRow `json:"rooms"`
+}
+
+// RoomDayAvailability 单个房间某一晚的空闲/占用情况（对客展示）
+type RoomDayAvailability struct {
+	Date      string `json:"date"`
+	Available bool   `json:"available"`
+}
+
+// parseCalendarRange 解析并校验日历查询的日期范围
+// 结束日期不包含在内，即查询 [startDate, endDate) 之间的每一晚
+func parseCalendarRange(startDate, endDate string) (time.Time, time.Time, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.NewBadRequestError("开始日期格式错误，应为: YYYY-MM-DD")
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.NewBadRequestError("结束日期格式错误，应为: YYYY-MM-DD")
+	}
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, errors.NewBadRequestError("结束日期必须晚于开始日期")
+	}
+	if end.Sub(start) > maxCalendarDays*24*time.Hour {
+		return time.Time{}, time.Time{}, errors.NewBadRequestError("查询范围不能超过92天")
+	}
+	return start, end, nil
+}
+
+// calendarDates 生成 [start, end) 之间的所有日期
+func calendarDates(start, end time.Time) []time.Time {
+	var dates []time.Time
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d)
+	}
+	return dates
+}
+
+// GetRoomCalendar 获取前台房态图（管理员）
+// floor 为 0、roomType 为空时返回所有房间
+func (s *RoomCalendarService) GetRoomCalendar(hotelID uint, startDate, endDate string, floor int, roomType string) (*RoomCalendar, error) {
+	start, end, err := parseCalendarRange(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 查询房间
+	rooms, err := s.roomRepo.FindAllByFilter(hotelID, floor, roomType)
+	if err != nil {
+		return nil, errors.NewDatabaseError("find rooms", err)
+	}
+
+	// 2. 查询这些房间在范围内的有效预订和停用工单
+	roomIDs := make([]int64, len(rooms))
+	workOrderRoomIDs := make([]uint, len(rooms))
+	for i, room := range rooms {
+		roomIDs[i] = int64(room.ID)
+		workOrderRoomIDs[i] = room.ID
+	}
+	var bookings []models.Booking
+	var workOrders []models.WorkOrder
+	if len(roomIDs) > 0 {
+		bookings, err = s.bookingRepo.FindActiveByRoomsAndDateRange(roomIDs, start, end)
+		if err != nil {
+			return nil, errors.NewDatabaseError("find bookings", err)
+		}
+		workOrders, err = s.workOrderRepo.FindOutOfOrderByRoomsAndDateRange(workOrderRoomIDs, start, end)
+		if err != nil {
+			return nil, errors.NewDatabaseError("find work orders", err)
+		}
+	}
+
+	// 3. 按房间分组
+	bookingsByRoom := make(map[int64][]models.Booking)
+	for _, b := range bookings {
+		bookingsByRoom[b.RoomID] = append(bookingsByRoom[b.RoomID], b)
+	}
+	workOrdersByRoom := make(map[uint][]models.WorkOrder)
+	for _, w := range workOrders {
+		workOrdersByRoom[w.RoomID] = append(workOrdersByRoom[w.RoomID], w)
+	}
+
+	// 4. 组装房间 × 日期网格
+	dates := calendarDates(start, end)
+	calendar := &RoomCalendar{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Dates:     make([]string, len(dates)),
+		Rooms:     make([]CalendarRoomRow, 0, len(rooms)),
+	}
+	for i, d := range dates {
+		calendar.Dates[i] = d.Format("2006-01-02")
+	}
+
+	for _, room := range rooms {
+		row := CalendarRoomRow{
+			RoomID:             room.ID,
+			RoomNumber:         room.RoomNumber,
+			RoomType:           room.RoomType,
+			Floor:              room.Floor,
+			HousekeepingStatus: room.HousekeepingStatus,
+			Cells:              make([]CalendarCell, len(dates)),
+		}
+		roomBookings := bookingsByRoom[int64(room.ID)]
+		roomWorkOrders := workOrdersByRoom[room.ID]
+		for i, d := range dates {
+			row.Cells[i] = buildCalendarCell(&room, roomBookings, roomWorkOrders, d)
+		}
+		calendar.Rooms = append(calendar.Rooms, row)
+	}
+
+	return calendar, nil
+}
+
+// buildCalendarCell 计算某房间某一晚的格子内容
+// 预订优先于停用状态展示，便于前台发现维修期间仍有在住客人的冲突
+func buildCalendarCell(room *models.Room, bookings []models.Booking, workOrders []models.WorkOrder, date time.Time) CalendarCell {
+	cell := CalendarCell{
+		Date:   date.Format("2006-01-02"),
+		Status: CalendarCellFree,
+	}
+
+	for i := range bookings {
+		b := &bookings[i]
+		// 入住日当晚占用，退房日当晚不占用；钟点房占用开始到结束时间所跨的日期
+		if b.OccupiesDate(date) {
+			id := b.ID
+			cell.Status = b.Status
+			cell.BookingID = &id
+			cell.GuestName = b.GuestName
+			cell.IsArrival = date.Equal(b.CheckIn)
+			return cell
+		}
+	}
+
+	for i := range workOrders {
+		if workOrders[i].BlocksDate(date) {
+			id := workOrders[i].ID
+			cell.Status = CalendarCellOutOfOrder
+			cell.WorkOrderID = &id
+			return cell
+		}
+	}
+
+	// 未设置停用时段的维修状态视为无限期停用
+	if room.Status == "maintenance" {
+		cell.Status = CalendarCellOutOfOrder
+	}
+
+	return cell
+}
+
+// GetRoomAvailability 获取单个房间的空闲/占用日历（对客展示，不暴露预订信息）
+func (s *RoomCalendarService) GetRoomAvailability(hotelID, roomID uint, startDate, endDate string) ([]RoomDayAvailability, error) {
+	start, end, err := parseCalendarRange(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	room, err := findHotelRoom(s.roomRepo, hotelID, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	bookings, err := s.bookingRepo.FindActiveByRoomsAndDateRange([]int64{int64(room.ID)}, start, end)
+	if err != nil {
+		return nil, errors.NewDatabaseError("find bookings", err)
+	}
+
+	workOrders, err := s.workOrderRepo.FindOutOfOrderByRoomsAndDateRange([]uint{room.ID}, start, end)
+	if err != nil {
+		return nil, errors.NewDatabaseError("find work orders", err)
+	}
+
+	dates := calendarDates(start, end)
+	result := make([]RoomDayAvailability, len(dates))
+	for i, d := range dates {
+		cell := buildCalendarCell(room, bookings, workOrders, d)
+		result[i] = RoomDayAvailability{
+			Date:      cell.Date,
+			Available: cell.Status == CalendarCellFree,
+		}
+	}
+
+	return result, nil
+}
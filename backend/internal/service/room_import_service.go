@@ -0,0 +1,368 @@
+package service
+
+import (
+	stderrors "errors"
+	"fmt"
+	"gohotel/internal/models"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// 房间导入限制
+const (
+	RoomImportMaxFileSize = 5 << 20 // 导入文件最大 5MB
+	roomImportMaxRows     = 1000    // 单次最多导入 1000 个房间
+)
+
+// roomImportColumn 导入表格的列定义
+type roomImportColumn struct {
+	Key      string // 表头（英文）
+	Label    string // 表头（中文），与英文表头等价
+	Required bool
+	Example  string
+}
+
+// roomImportColumns 导入模板的列，顺序即模板中的列顺序
+var roomImportColumns = []roomImportColumn{
+	{Key: "room_number", Label: "房间号", Required: true, Example: "101"},
+	{Key: "room_type", Label: "房型", Required: true, Example: "标准间"},
+	{Key: "floor", Label: "楼层", Required: true, Example: "1"},
+	{Key: "price", Label: "价格", Required: true, Example: "299"},
+	{Key: "original_price", Label: "原价", Example: "399"},
+	{Key: "capacity", Label: "可住人数", Required: true, Example: "2"},
+	{Key: "area", Label: "面积", Example: "25"},
+	{Key: "bed_type", Label: "床型", Example: "双人床"},
+	{Key: "description", Label: "描述", Example: "朝南，带窗"},
+	{Key: "left", Label: "左边界", Required: true, Example: "0"},
+	{Key: "top", Label: "上边界", Required: true, Example: "0"},
+	{Key: "width", Label: "宽度", Required: true, Example: "100"},
+	{Key: "height", Label: "高度", Required: true, Example: "80"},
+}
+
+// RoomImportError 导入表格中的行级错误
+type RoomImportError struct {
+	Row        int    `json:"row"`                   // 表格行号（从 1 开始，含表头）
+	Column     string `json:"column,omitempty"`      // 出错的列，整行错误时为空
+	RoomNumber string `json:"room_number,omitempty"` // 房间号
+	Message    string `json:"message"`
+}
+
+// RoomImportResult 房间导入结果
+type RoomImportResult struct {
+	DryRun        bool              `json:"dry_run"`        // 是否为试运行（只校验不写入）
+	TotalRows     int               `json:"total_rows"`     // 数据行数（不含表头和空行）
+	ValidRows     int               `json:"valid_rows"`     // 校验通过的行数
+	ImportedCount int               `json:"imported_count"` // 实际导入的房间数
+	Errors        []RoomImportError `json:"errors"`
+	Rooms         []*models.Room    `json:"rooms"` // 试运行时为将要创建的房间，正式导入时为已创建的房间
+}
+
+// RoomImportTemplate 生成房间导入模板，format 为 csv 或 xlsx
+// 返回文件名和文件内容
+func RoomImportTemplate(format string) (string, []byte, error) {
+	header := make([]string, len(roomImportColumns))
+	example := make([]string, len(roomImportColumns))
+	for i, col := range roomImportColumns {
+		header[i] = col.Key
+		example[i] = col.Example
+	}
+	rows := [][]string{header, example}
+
+	switch format {
+	case "", "xlsx":
+		data, err := utils.WriteXLSX("rooms", rows)
+		if err != nil {
+			return "", nil, errors.NewInternalServerError("生成导入模板失败")
+		}
+		return "room_import_template.xlsx", data, nil
+	case "csv":
+		data, err := utils.WriteCSV(rows)
+		if err != nil {
+			return "", nil, errors.NewInternalServerError("生成导入模板失败")
+		}
+		return "room_import_template.csv", data, nil
+	default:
+		return "", nil, errors.NewBadRequestError("不支持的模板格式，仅支持 csv 和 xlsx")
+	}
+}
+
+// ImportRooms 从 CSV/XLSX 文件批量导入酒店房间
+// 所有行都校验通过才会在一个事务中写入，任何一行出错都不会导入；dryRun 为 true 时只校验不写入
+// 房间坐标保存到所在楼层的布局草稿，发布后才出现在平面图上
+func (s *RoomService) ImportRooms(userID int64, hotelID uint, filename string, file io.Reader, dryRun bool) (*RoomImportResult, error) {
+	// 表头占一行，读到上限后停止解析
+	rows, err := utils.ReadSpreadsheet(filename, file, roomImportMaxRows+1)
+	if stderrors.Is(err, utils.ErrTooManyRows) {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("单次最多导入%d个房间", roomImportMaxRows))
+	}
+	if err != nil {
+		return nil, errors.NewBadRequestError("无法读取导入文件: " + err.Error())
+	}
+
+	headerIndex := -1
+	for i, row := range rows {
+		if !isBlankRow(row) {
+			headerIndex = i
+			break
+		}
+	}
+	if headerIndex < 0 {
+		return nil, errors.NewBadRequestError("导入文件为空")
+	}
+	columns, err := mapRoomImportHeader(rows[headerIndex])
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RoomImportResult{
+		DryRun: dryRun,
+		Errors: make([]RoomImportError, 0),
+		Rooms:  make([]*models.Room, 0),
+	}
+
+	// 1. 逐行解析并校验字段
+	type parsedRow struct {
+		line int
+		room *models.Room
+	}
+	var parsed []parsedRow
+	for i := headerIndex + 1; i < len(rows); i++ {
+		if isBlankRow(rows[i]) {
+			continue
+		}
+		result.TotalRows++
+		if result.TotalRows > roomImportMaxRows {
+			return nil, errors.NewBadRequestError(fmt.Sprintf("单次最多导入%d个房间", roomImportMaxRows))
+		}
+
+		room, rowErrors := parseRoomImportRow(hotelID, i+1, rows[i], columns)
+		if len(rowErrors) > 0 {
+			result.Errors = append(result.Errors, rowErrors...)
+			continue
+		}
+		parsed = append(parsed, parsedRow{line: i + 1, room: room})
+	}
+	if result.TotalRows == 0 {
+		return nil, errors.NewBadRequestError("导入文件中没有房间数据")
+	}
+
+	// 2. 检查房间号是否重复（文件内、已有房间）
+	numbers := make([]string, len(parsed))
+	for i, p := range parsed {
+		numbers[i] = p.room.RoomNumber
+	}
+	existingSet := make(map[string]bool)
+	if len(numbers) > 0 {
+		existing, err := s.roomRepo.ExistsByRoomNumbers(hotelID, numbers)
+		if err != nil {
+			return nil, errors.NewDatabaseError("check existing room numbers", err)
+		}
+		for _, num := range existing {
+			existingSet[num] = true
+		}
+	}
+
+	// 3. 校验与同楼层房间、设施（按楼层草稿发布后的布局）以及先前的导入行是否冲突
+	type floorLayout struct {
+		rooms      []models.Room
+		facilities []models.Facility
+	}
+	layouts := make(map[int]*floorLayout)
+	firstLine := make(map[string]int)
+	var valid []*models.Room
+	for _, p := range parsed {
+		room := p.room
+		if line, ok := firstLine[room.RoomNumber]; ok {
+			result.Errors = append(result.Errors, RoomImportError{
+				Row: p.line, Column: "room_number", RoomNumber: room.RoomNumber,
+				Message: fmt.Sprintf("房间号与第 %d 行重复", line),
+			})
+			continue
+		}
+		firstLine[room.RoomNumber] = p.line
+		if existingSet[room.RoomNumber] {
+			result.Errors = append(result.Errors, RoomImportError{
+				Row: p.line, Column: "room_number", RoomNumber: room.RoomNumber, Message: "房间号已存在",
+			})
+			continue
+		}
+
+		layout, ok := layouts[room.Floor]
+		if !ok {
+			rooms, facilities, err := s.layoutService.draftLayout(hotelID, room.Floor)
+			if err != nil {
+				return nil, err
+			}
+			layout = &floorLayout{rooms: rooms, facilities: facilities}
+			layouts[room.Floor] = layout
+		}
+		// 导入的房间 ID 都为 0，先前通过校验的导入行彼此不冲突，只会报告与当前行有关的问题
+		changes := newLayoutChanges()
+		changes.Rooms[0] = true
+		validation := validateFloorLayout(append(layout.rooms, *room), layout.facilities, changes)
+		if !validation.Valid {
+			for _, issue := range validation.Issues {
+				result.Errors = append(result.Errors, RoomImportError{
+					Row: p.line, RoomNumber: room.RoomNumber, Message: issue.Message,
+				})
+			}
+			continue
+		}
+		layout.rooms = append(layout.rooms, *room)
+		valid = append(valid, room)
+	}
+
+	result.ValidRows = len(valid)
+	if valid != nil {
+		result.Rooms = valid
+	}
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	// 4. 全部通过后在事务中写入房间和布局草稿
+	if err := s.layoutService.CreateRooms(userID, hotelID, valid, "导入房间"); err != nil {
+		return nil, err
+	}
+	result.ImportedCount = len(valid)
+	return result, nil
+}
+
+// mapRoomImportHeader 解析表头，返回列名到列号的映射
+func mapRoomImportHeader(header []string) (map[string]int, error) {
+	aliases := make(map[string]string, len(roomImportColumns)*2)
+	for _, col := range roomImportColumns {
+		aliases[col.Key] = col.Key
+		aliases[col.Label] = col.Key
+	}
+
+	columns := make(map[string]int)
+	for i, cell := range header {
+		name := strings.TrimSpace(cell)
+		if name == "" {
+			continue
+		}
+		key, ok := aliases[strings.ToLower(name)]
+		if !ok {
+			key, ok = aliases[name]
+		}
+		if !ok {
+			return nil, errors.NewBadRequestError("无法识别的列: " + name)
+		}
+		if _, dup := columns[key]; dup {
+			return nil, errors.NewBadRequestError("重复的列: " + name)
+		}
+		columns[key] = i
+	}
+
+	var missing []string
+	for _, col := range roomImportColumns {
+		if _, ok := columns[col.Key]; col.Required && !ok {
+			missing = append(missing, col.Key)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, errors.NewBadRequestError("缺少必填列: " + strings.Join(missing, ", "))
+	}
+	return columns, nil
+}
+
+// parseRoomImportRow 解析一行房间数据，返回房间和该行的所有字段错误
+func parseRoomImportRow(hotelID uint, line int, row []string, columns map[string]int) (*models.Room, []RoomImportError) {
+	value := func(key string) string {
+		i, ok := columns[key]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	room := &models.Room{
+		HotelID:     hotelID,
+		RoomNumber:  value("room_number"),
+		RoomType:    value("room_type"),
+		BedType:     value("bed_type"),
+		Description: value("description"),
+		Status:      "available",
+	}
+
+	var rowErrors []RoomImportError
+	fail := func(column, message string) {
+		rowErrors = append(rowErrors, RoomImportError{Row: line, Column: column, RoomNumber: room.RoomNumber, Message: message})
+	}
+	parseInt := func(key string, target *int, min int) {
+		raw := value(key)
+		if raw == "" {
+			fail(key, "不能为空")
+			return
+		}
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			fail(key, "必须是整数: "+raw)
+			return
+		}
+		if v < min {
+			fail(key, fmt.Sprintf("不能小于 %d", min))
+			return
+		}
+		*target = v
+	}
+	parseFloat := func(key string, target *float64, required bool) {
+		raw := value(key)
+		if raw == "" {
+			if required {
+				fail(key, "不能为空")
+			}
+			return
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			fail(key, "必须是数字: "+raw)
+			return
+		}
+		if v < 0 || (required && v == 0) {
+			fail(key, "必须大于 0")
+			return
+		}
+		*target = v
+	}
+
+	if room.RoomNumber == "" {
+		fail("room_number", "不能为空")
+	} else if len(room.RoomNumber) > 20 {
+		fail("room_number", "长度不能超过 20")
+	}
+	if room.RoomType == "" {
+		fail("room_type", "不能为空")
+	}
+	if raw := value("floor"); raw == "" {
+		fail("floor", "不能为空")
+	} else if v, err := strconv.Atoi(raw); err != nil || v == 0 {
+		fail("floor", "必须是非 0 整数: "+raw)
+	} else {
+		room.Floor = v
+	}
+	parseFloat("price", &room.Price, true)
+	parseFloat("original_price", &room.OriginalPrice, false)
+	parseInt("capacity", &room.Capacity, 1)
+	parseFloat("area", &room.Area, false)
+	parseInt("left", &room.Left, 0)
+	parseInt("top", &room.Top, 0)
+	parseInt("width", &room.Width, 1)
+	parseInt("height", &room.Height, 1)
+
+	return room, rowErrors
+}
+
+// isBlankRow 判断表格行是否为空行
+func isBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+// RoomMediaService 房间图库业务逻辑层
+// 图片先通过 /api/upload/image 上传到临时目录，添加到图库时再确认为正式文件，
+// 未确认的临时文件会被定时清理
+type RoomMediaService struct {
+	roomRepo   *repository.RoomRepository
+	photoRepo  *repository.RoomPhotoRepository
+	cosService *CosService
+}
+
+// NewRoomMediaService 创建房间图库服务实例
+func NewRoomMediaService(
+	roomRepo *repository.RoomRepository,
+	photoRepo *repository.RoomPhotoRepository,
+	cosService *CosService,
+) *RoomMediaService {
+	return &RoomMediaService{
+		roomRepo:   roomRepo,
+		photoRepo:  photoRepo,
+		cosService: cosService,
+	}
+}
+
+// RoomPhotoInput 待添加的图片
+type RoomPhotoInput struct {
+	TempURL string `json:"temp_url" binding:"required"` // 临时上传返回的 URL
+	Caption string `json:"caption" binding:"max=255"`
+}
+
+// AddRoomPhotosRequest 添加房间图片请求
+type AddRoomPhotosRequest struct {
+	Photos []RoomPhotoInput `json:"photos" binding:"required,min=1,max=20,dive"`
+}
+
+// UpdateRoomPhotoRequest 更新图片说明请求
+type UpdateRoomPhotoRequest struct {
+	Caption string `json:"caption" binding:"max=255"`
+}
+
+// ReorderRoomPhotosRequest 图片排序请求
+type ReorderRoomPhotosRequest struct {
+	PhotoIDs []uint `json:"photo_ids" binding:"required,min=1"` // 房间全部图片 ID，按新顺序排列
+}
+
+// ListPhotos 获取房间图库
+func (s *RoomMediaService) ListPhotos(hotelID, roomID uint) ([]models.RoomPhoto, error) {
+	if err := s.checkRoom(hotelID, roomID); err != nil {
+		return nil, err
+	}
+
+	photos, err := s.photoRepo.FindByRoomID(roomID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("list room photos", err)
+	}
+	return photos, nil
+}
+
+// AddPhotos 确认临时上传的图片并追加到房间图库
+// 房间还没有封面时，第一张新图片自动设为封面
+func (s *RoomMediaService) AddPhotos(hotelID, roomID uint, req *AddRoomPhotosRequest) ([]models.RoomPhoto, error) {
+	if err := s.checkRoom(hotelID, roomID); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.photoRepo.FindByRoomID(roomID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("list room photos", err)
+	}
+	maxSort, err := s.photoRepo.MaxSort(roomID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("find max photo sort", err)
+	}
+
+	tempURLs := make([]string, len(req.Photos))
+	for i, p := range req.Photos {
+		tempURLs[i] = p.TempURL
+	}
+	urls, err := confirmTempUploads(s.cosService, tempURLs)
+	if err != nil {
+		return nil, err
+	}
+
+	hasCover := false
+	for _, p := range existing {
+		if p.IsCover {
+			hasCover = true
+			break
+		}
+	}
+
+	photos := make([]*models.RoomPhoto, len(urls))
+	for i, u := range urls {
+		photos[i] = &models.RoomPhoto{
+			RoomID:  roomID,
+			URL:     u,
+			Caption: req.Photos[i].Caption,
+			Sort:    maxSort + 1 + i,
+			IsCover: !hasCover && i == 0,
+		}
+	}
+
+	if err := s.photoRepo.CreateBatch(photos); err != nil {
+		// 记录写入失败，已确认的文件不再被引用，一并删除
+		deleteCosFiles(s.cosService, urls)
+		return nil, errors.NewDatabaseError("create room photos", err)
+	}
+
+	return s.refresh(roomID)
+}
+
+// UpdatePhoto 更新图片说明
+func (s *RoomMediaService) UpdatePhoto(hotelID, roomID, photoID uint, req *UpdateRoomPhotoRequest) (*models.RoomPhoto, error) {
+	photo, err := s.findPhoto(hotelID, roomID, photoID)
+	if err != nil {
+		return nil, err
+	}
+
+	photo.Caption = req.Caption
+	if err := s.photoRepo.Update(photo); err != nil {
+		return nil, errors.NewDatabaseError("update room photo", err)
+	}
+	return photo, nil
+}
+
+// ReorderPhotos 按给定顺序重排房间图片
+// photo_ids 必须恰好包含房间的全部图片
+func (s *RoomMediaService) ReorderPhotos(hotelID, roomID uint, req *ReorderRoomPhotosRequest) ([]models.RoomPhoto, error) {
+	if err := s.checkRoom(hotelID, roomID); err != nil {
+		return nil, err
+	}
+
+	photos, err := s.photoRepo.FindByRoomID(roomID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("list room photos", err)
+	}
+
+	owned := make(map[uint]bool, len(photos))
+	for _, p := range photos {
+		owned[p.ID] = true
+	}
+	seen := make(map[uint]bool, len(req.PhotoIDs))
+	for _, id := range req.PhotoIDs {
+		if !owned[id] || seen[id] {
+			return nil, errors.NewBadRequestError("图片列表与房间图库不一致")
+		}
+		seen[id] = true
+	}
+	if len(seen) != len(photos) {
+		return nil, errors.NewBadRequestError("图片列表与房间图库不一致")
+	}
+
+	if err := s.photoRepo.UpdateSorts(roomID, req.PhotoIDs); err != nil {
+		return nil, errors.NewDatabaseError("reorder room photos", err)
+	}
+
+	return s.refresh(roomID)
+}
+
+// SetCover 设置房间封面
+func (s *RoomMediaService) SetCover(hotelID, roomID, photoID uint) ([]models.RoomPhoto, error) {
+	if _, err := s.findPhoto(hotelID, roomID, photoID); err != nil {
+		return nil, err
+	}
+
+	if err := s.photoRepo.SetCover(roomID, photoID); err != nil {
+		return nil, errors.NewDatabaseError("set room cover", err)
+	}
+
+	return s.refresh(roomID)
+}
+
+// DeletePhoto 删除图片，同时删除 COS 中的文件
+// 删除的是封面时，排在最前的图片自动成为新封面；
+// 先删除记录再删除文件，文件删除失败只记录日志，不会留下指向已删除文件的记录
+func (s *RoomMediaService) DeletePhoto(hotelID, roomID, photoID uint) ([]models.RoomPhoto, error) {
+	photo, err := s.findPhoto(hotelID, roomID, photoID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.photoRepo.DeleteWithCover(photo); err != nil {
+		return nil, errors.NewDatabaseError("delete room photo", err)
+	}
+	deleteCosFiles(s.cosService, []string{photo.URL})
+
+	return s.refresh(roomID)
+}
+
+// refresh 重新读取房间图库，返回改动后的图片列表
+// 房间的 images 字段已在图库改动的事务中同步
+func (s *RoomMediaService) refresh(roomID uint) ([]models.RoomPhoto, error) {
+	photos, err := s.photoRepo.FindByRoomID(roomID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("list room photos", err)
+	}
+	return photos, nil
+}
+
+// checkRoom 检查房间是否存在于酒店内
+func (s *RoomMediaService) checkRoom(hotelID, roomID uint) error {
+	_, err := findHotelRoom(s.roomRepo, hotelID, roomID)
+	return err
+}
+
+// findPhoto 查找属于指定房间的图片
+func (s *RoomMediaService) findPhoto(hotelID, roomID, photoID uint) (*models.RoomPhoto, error) {
+	if err := s.checkRoom(hotelID, roomID); err != nil {
+		return nil, err
+	}
+	photo, err := s.photoRepo.FindByID(photoID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("图片不存在")
+		}
+		return nil, errors.NewDatabaseError("find room photo", err)
+	}
+	if photo.RoomID != roomID {
+		return nil, errors.NewNotFoundError("图片不存在")
+	}
+	return photo, nil
+}
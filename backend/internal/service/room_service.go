@@ -10,12 +10,18 @@ import (
 
 // RoomService 房间业务逻辑层
 type RoomService struct {
-	roomRepo *repository.RoomRepository
+	roomRepo      *repository.RoomRepository
+	layoutService *FloorLayoutService
+	cosService    *CosService
 }
 
 // NewRoomService 创建房间服务实例
-func NewRoomService(roomRepo *repository.RoomRepository) *RoomService {
-	return &RoomService{roomRepo: roomRepo}
+func NewRoomService(roomRepo *repository.RoomRepository, layoutService *FloorLayoutService, cosService *CosService) *RoomService {
+	return &RoomService{
+		roomRepo:      roomRepo,
+		layoutService: layoutService,
+		cosService:    cosService,
+	}
 }
 
 // CreateRoomRequest 创建房间请求
@@ -29,15 +35,18 @@ type CreateRoomRequest struct {
 	Area          float64 `json:"area"`
 	BedType       string  `json:"bed_type"`
 	Description   string  `json:"description"`
-	Facilities    string  `json:"facilities"`
-	Images        string  `json:"images"`
 	Left          int     `json:"left"`
 	Top           int     `json:"top"`
 	Width         int     `json:"width"`
 	Height        int     `json:"height"`
+	// 钟点房设置
+	HourlyEnabled  bool    `json:"hourly_enabled"`
+	HourlyPrice    float64 `json:"hourly_price" binding:"gte=0"`
+	HourlyMinHours int     `json:"hourly_min_hours" binding:"gte=0"`
 }
 
 // UpdateRoomRequest 更新房间请求
+// 坐标通过楼层布局草稿修改，发布后生效
 type UpdateRoomRequest struct {
 	RoomType      string  `json:"room_type"`
 	Floor         int     `json:"floor"`
@@ -47,44 +56,47 @@ type UpdateRoomRequest struct {
 	Area          float64 `json:"area"`
 	BedType       string  `json:"bed_type"`
 	Description   string  `json:"description"`
-	Facilities    string  `json:"facilities"`
-	Images        string  `json:"images"`
 	Status        string  `json:"status"`
-	Left          int     `json:"left"`
-	Top           int     `json:"top"`
-	Width         int     `json:"width"`
-	Height        int     `json:"height"`
+	// 钟点房设置
+	HourlyEnabled  *bool   `json:"hourly_enabled"`
+	HourlyPrice    float64 `json:"hourly_price"`
+	HourlyMinHours int     `json:"hourly_min_hours"`
 }
 
-// CreateRoom 创建房间
-func (s *RoomService) CreateRoom(req *CreateRoomRequest) (*models.Room, error) {
+// CreateRoom 在酒店内创建房间
+func (s *RoomService) CreateRoom(hotelID uint, req *CreateRoomRequest) (*models.Room, error) {
 	// 1. 检查房间号是否已存在
-	exists, err := s.roomRepo.ExistsByRoomNumber(req.RoomNumber)
+	exists, err := s.roomRepo.ExistsByRoomNumber(hotelID, req.RoomNumber)
 	if err != nil {
 		return nil, errors.NewDatabaseError("check room number", err)
 	}
 	if exists {
 		return nil, errors.NewConflictError("房间号已存在")
 	}
+	if req.HourlyEnabled && req.HourlyPrice <= 0 {
+		return nil, errors.NewBadRequestError("开放钟点房时必须设置钟点房价格")
+	}
 
 	// 2. 创建房间对象
 	room := &models.Room{
-		RoomNumber:    req.RoomNumber,
-		RoomType:      req.RoomType,
-		Floor:         req.Floor,
-		Price:         req.Price,
-		OriginalPrice: req.OriginalPrice,
-		Capacity:      req.Capacity,
-		Area:          req.Area,
-		BedType:       req.BedType,
-		Description:   req.Description,
-		Facilities:    req.Facilities,
-		Images:        req.Images,
-		Status:        "available",
-		Left:          req.Left,
-		Top:           req.Top,
-		Width:         req.Width,
-		Height:        req.Height,
+		HotelID:        hotelID,
+		RoomNumber:     req.RoomNumber,
+		RoomType:       req.RoomType,
+		Floor:          req.Floor,
+		Price:          req.Price,
+		OriginalPrice:  req.OriginalPrice,
+		Capacity:       req.Capacity,
+		Area:           req.Area,
+		BedType:        req.BedType,
+		Description:    req.Description,
+		Status:         "available",
+		Left:           req.Left,
+		Top:            req.Top,
+		Width:          req.Width,
+		Height:         req.Height,
+		HourlyEnabled:  req.HourlyEnabled,
+		HourlyPrice:    req.HourlyPrice,
+		HourlyMinHours: req.HourlyMinHours,
 	}
 
 	// 3. 保存到数据库
@@ -95,35 +107,43 @@ func (s *RoomService) CreateRoom(req *CreateRoomRequest) (*models.Room, error) {
 	return room, nil
 }
 
-// GetRoomByID 根据 ID 获取房间
-func (s *RoomService) GetRoomByID(id uint) (*models.Room, error) {
-	room, err := s.roomRepo.FindByID(id)
+// GetRoomByID 根据 ID 获取酒店内的房间
+func (s *RoomService) GetRoomByID(hotelID, id uint) (*models.Room, error) {
+	return findHotelRoom(s.roomRepo, hotelID, id)
+}
+
+// findHotelRoom 查找酒店内的房间，房间属于其他酒店时视为不存在
+func findHotelRoom(roomRepo *repository.RoomRepository, hotelID, id uint) (*models.Room, error) {
+	room, err := roomRepo.FindByID(id)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, errors.NewNotFoundError("房间不存在")
 		}
 		return nil, errors.NewDatabaseError("find room", err)
 	}
+	if room.HotelID != hotelID {
+		return nil, errors.NewNotFoundError("房间不存在")
+	}
 	return room, nil
 }
 
 // UpdateRoom 更新房间信息
-func (s *RoomService) UpdateRoom(id uint, req *UpdateRoomRequest) (*models.Room, error) {
+func (s *RoomService) UpdateRoom(hotelID, id uint, req *UpdateRoomRequest) (*models.Room, error) {
 	// 1. 查找房间
-	room, err := s.roomRepo.FindByID(id)
+	room, err := findHotelRoom(s.roomRepo, hotelID, id)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, errors.NewNotFoundError("房间不存在")
-		}
-		return nil, errors.NewDatabaseError("find room", err)
+		return nil, err
 	}
 
 	// 2. 更新字段（只更新非空字段）
 	if req.RoomType != "" {
 		room.RoomType = req.RoomType
 	}
-	if req.Floor > 0 {
+	if req.Floor > 0 && req.Floor != room.Floor {
+		// 更换楼层后房间变为未摆放状态，需要在新楼层的布局草稿中重新摆放
 		room.Floor = req.Floor
+		room.Left, room.Top = 0, 0
+		room.Width, room.Height = 0, 0
 	}
 	if req.Price > 0 {
 		room.Price = req.Price
@@ -143,26 +163,20 @@ func (s *RoomService) UpdateRoom(id uint, req *UpdateRoomRequest) (*models.Room,
 	if req.Description != "" {
 		room.Description = req.Description
 	}
-	if req.Facilities != "" {
-		room.Facilities = req.Facilities
-	}
-	if req.Images != "" {
-		room.Images = req.Images
-	}
 	if req.Status != "" {
 		room.Status = req.Status
 	}
-	if req.Left > 0 {
-		room.Left = req.Left
+	if req.HourlyEnabled != nil {
+		room.HourlyEnabled = *req.HourlyEnabled
 	}
-	if req.Top > 0 {
-		room.Top = req.Top
+	if req.HourlyPrice > 0 {
+		room.HourlyPrice = req.HourlyPrice
 	}
-	if req.Width > 0 {
-		room.Width = req.Width
+	if req.HourlyMinHours > 0 {
+		room.HourlyMinHours = req.HourlyMinHours
 	}
-	if req.Height > 0 {
-		room.Height = req.Height
+	if room.HourlyEnabled && room.HourlyPrice <= 0 {
+		return nil, errors.NewBadRequestError("开放钟点房时必须设置钟点房价格")
 	}
 
 	// 3. 保存更新
@@ -174,14 +188,11 @@ func (s *RoomService) UpdateRoom(id uint, req *UpdateRoomRequest) (*models.Room,
 }
 
 // DeleteRoom 删除房间
-func (s *RoomService) DeleteRoom(id uint) error {
+func (s *RoomService) DeleteRoom(hotelID, id uint) error {
 	// 1. 检查房间是否存在
-	_, err := s.roomRepo.FindByID(id)
+	room, err := findHotelRoom(s.roomRepo, hotelID, id)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.NewNotFoundError("房间不存在")
-		}
-		return errors.NewDatabaseError("find room", err)
+		return err
 	}
 
 	// 2. 删除房间
@@ -189,11 +200,19 @@ func (s *RoomService) DeleteRoom(id uint) error {
 		return errors.NewDatabaseError("delete room", err)
 	}
 
+	// 3. 删除图库文件（失败只记录日志，不影响房间删除）
+	urls := make([]string, len(room.Photos))
+	for i, photo := range room.Photos {
+		urls[i] = photo.URL
+	}
+	deleteCosFiles(s.cosService, urls)
+
 	return nil
 }
 
 // ListRooms 获取所有房间列表（分页）
-func (s *RoomService) ListRooms(page, pageSize int) ([]models.Room, int64, error) {
+// amenityIDs 不为空时只返回同时具备这些设施的房间
+func (s *RoomService) ListRooms(hotelID uint, page, pageSize int, amenityIDs []uint) ([]models.Room, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -201,7 +220,7 @@ func (s *RoomService) ListRooms(page, pageSize int) ([]models.Room, int64, error
 		pageSize = 10
 	}
 
-	rooms, total, err := s.roomRepo.FindAll(page, pageSize)
+	rooms, total, err := s.roomRepo.FindAll(hotelID, page, pageSize, uniqueAmenityIDs(amenityIDs))
 	if err != nil {
 		return nil, 0, errors.NewDatabaseError("list rooms", err)
 	}
@@ -210,7 +229,8 @@ func (s *RoomService) ListRooms(page, pageSize int) ([]models.Room, int64, error
 }
 
 // ListAvailableRooms 获取可用房间列表（分页）
-func (s *RoomService) ListAvailableRooms(page, pageSize int) ([]models.Room, int64, error) {
+// amenityIDs 不为空时只返回同时具备这些设施的房间
+func (s *RoomService) ListAvailableRooms(hotelID uint, page, pageSize int, amenityIDs []uint) ([]models.Room, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -218,7 +238,7 @@ func (s *RoomService) ListAvailableRooms(page, pageSize int) ([]models.Room, int
 		pageSize = 10
 	}
 
-	rooms, total, err := s.roomRepo.FindAvailable(page, pageSize)
+	rooms, total, err := s.roomRepo.FindAvailable(hotelID, page, pageSize, uniqueAmenityIDs(amenityIDs))
 	if err != nil {
 		return nil, 0, errors.NewDatabaseError("list available rooms", err)
 	}
@@ -226,8 +246,32 @@ func (s *RoomService) ListAvailableRooms(page, pageSize int) ([]models.Room, int
 	return rooms, total, nil
 }
 
+// GetAmenityFacets 获取当前设施筛选条件下的分面统计
+// onlyAvailable 为 true 时只统计可售房间
+func (s *RoomService) GetAmenityFacets(hotelID uint, onlyAvailable bool, amenityIDs []uint) ([]models.AmenityFacet, error) {
+	facets, err := s.roomRepo.CountAmenityFacets(hotelID, onlyAvailable, uniqueAmenityIDs(amenityIDs))
+	if err != nil {
+		return nil, errors.NewDatabaseError("count amenity facets", err)
+	}
+	return facets, nil
+}
+
+// uniqueAmenityIDs 去除重复和无效的设施 ID
+func uniqueAmenityIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
+}
+
 // SearchRoomsByType 根据房型搜索房间
-func (s *RoomService) SearchRoomsByType(roomType string, page, pageSize int) ([]models.Room, int64, error) {
+func (s *RoomService) SearchRoomsByType(hotelID uint, roomType string, page, pageSize int) ([]models.Room, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -235,7 +279,7 @@ func (s *RoomService) SearchRoomsByType(roomType string, page, pageSize int) ([]
 		pageSize = 10
 	}
 
-	rooms, total, err := s.roomRepo.FindByRoomType(roomType, page, pageSize)
+	rooms, total, err := s.roomRepo.FindByRoomType(hotelID, roomType, page, pageSize)
 	if err != nil {
 		return nil, 0, errors.NewDatabaseError("search rooms by type", err)
 	}
@@ -244,7 +288,7 @@ func (s *RoomService) SearchRoomsByType(roomType string, page, pageSize int) ([]
 }
 
 // ListRoomsByFloor 根据楼层获取房间
-func (s *RoomService) ListRoomsByFloor(floor, page, pageSize int) ([]models.Room, int64, error) {
+func (s *RoomService) ListRoomsByFloor(hotelID uint, floor, page, pageSize int) ([]models.Room, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -252,7 +296,7 @@ func (s *RoomService) ListRoomsByFloor(floor, page, pageSize int) ([]models.Room
 		pageSize = 10
 	}
 	// 直接查询房间表，楼层号相同的一层，无需再查其他表
-	rooms, total, err := s.roomRepo.FindRoomByFloor(floor, page, pageSize)
+	rooms, total, err := s.roomRepo.FindRoomByFloor(hotelID, floor, page, pageSize)
 	if err != nil {
 		return nil, 0, errors.NewDatabaseError("list rooms by floor", err)
 	}
@@ -260,7 +304,7 @@ func (s *RoomService) ListRoomsByFloor(floor, page, pageSize int) ([]models.Room
 }
 
 // SearchRoomsByPrice 根据价格范围搜索房间
-func (s *RoomService) SearchRoomsByPrice(minPrice, maxPrice float64, page, pageSize int) ([]models.Room, int64, error) {
+func (s *RoomService) SearchRoomsByPrice(hotelID uint, minPrice, maxPrice float64, page, pageSize int) ([]models.Room, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -268,7 +312,7 @@ func (s *RoomService) SearchRoomsByPrice(minPrice, maxPrice float64, page, pageS
 		pageSize = 10
 	}
 
-	rooms, total, err := s.roomRepo.FindByPriceRange(minPrice, maxPrice, page, pageSize)
+	rooms, total, err := s.roomRepo.FindByPriceRange(hotelID, minPrice, maxPrice, page, pageSize)
 	if err != nil {
 		return nil, 0, errors.NewDatabaseError("search rooms by price", err)
 	}
@@ -277,7 +321,7 @@ func (s *RoomService) SearchRoomsByPrice(minPrice, maxPrice float64, page, pageS
 }
 
 // UpdateRoomStatus 更新房间状态
-func (s *RoomService) UpdateRoomStatus(id uint, status string) error {
+func (s *RoomService) UpdateRoomStatus(hotelID, id uint, status string) error {
 	// 验证状态值
 	validStatuses := map[string]bool{
 		"available":   true,
@@ -287,6 +331,9 @@ func (s *RoomService) UpdateRoomStatus(id uint, status string) error {
 	if !validStatuses[status] {
 		return errors.NewBadRequestError("无效的房间状态")
 	}
+	if _, err := findHotelRoom(s.roomRepo, hotelID, id); err != nil {
+		return err
+	}
 
 	if err := s.roomRepo.UpdateStatus(id, status); err != nil {
 		return errors.NewDatabaseError("update room status", err)
@@ -314,8 +361,8 @@ type FailedRoom struct {
 	Reason     string `json:"reason"`
 }
 
-// BatchCreateRooms 批量创建房间
-func (s *RoomService) BatchCreateRooms(req *BatchCreateRoomRequest) (*BatchCreateRoomsResult, error) {
+// BatchCreateRooms 在酒店内批量创建房间
+func (s *RoomService) BatchCreateRooms(hotelID uint, req *BatchCreateRoomRequest) (*BatchCreateRoomsResult, error) {
 	if len(req.Rooms) == 0 {
 		return nil, errors.NewBadRequestError("房间列表不能为空")
 	}
@@ -336,7 +383,7 @@ func (s *RoomService) BatchCreateRooms(req *BatchCreateRoomRequest) (*BatchCreat
 	}
 
 	// 2. 批量检查哪些房间号已存在
-	existingNumbers, err := s.roomRepo.ExistsByRoomNumbers(roomNumbers)
+	existingNumbers, err := s.roomRepo.ExistsByRoomNumbers(hotelID, roomNumbers)
 	if err != nil {
 		return nil, errors.NewDatabaseError("check existing room numbers", err)
 	}
@@ -371,20 +418,32 @@ func (s *RoomService) BatchCreateRooms(req *BatchCreateRoomRequest) (*BatchCreat
 			continue
 		}
 
+		// 检查钟点房设置
+		if r.HourlyEnabled && r.HourlyPrice <= 0 {
+			result.FailedRooms = append(result.FailedRooms, FailedRoom{
+				RoomNumber: r.RoomNumber,
+				Reason:     "开放钟点房时必须设置钟点房价格",
+			})
+			result.FailedCount++
+			continue
+		}
+
 		// 创建房间对象
 		room := &models.Room{
-			RoomNumber:    r.RoomNumber,
-			RoomType:      r.RoomType,
-			Floor:         r.Floor,
-			Price:         r.Price,
-			OriginalPrice: r.OriginalPrice,
-			Capacity:      r.Capacity,
-			Area:          r.Area,
-			BedType:       r.BedType,
-			Description:   r.Description,
-			Facilities:    r.Facilities,
-			Images:        r.Images,
-			Status:        "available",
+			HotelID:        hotelID,
+			RoomNumber:     r.RoomNumber,
+			RoomType:       r.RoomType,
+			Floor:          r.Floor,
+			Price:          r.Price,
+			OriginalPrice:  r.OriginalPrice,
+			Capacity:       r.Capacity,
+			Area:           r.Area,
+			BedType:        r.BedType,
+			Description:    r.Description,
+			Status:         "available",
+			HourlyEnabled:  r.HourlyEnabled,
+			HourlyPrice:    r.HourlyPrice,
+			HourlyMinHours: r.HourlyMinHours,
 		}
 		roomsToCreate = append(roomsToCreate, room)
 	}
@@ -0,0 +1,424 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"gohotel/internal/config"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/logger"
+	"gohotel/pkg/utils"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// sessionCacheTTL 会话状态缓存的有效期
+	// 本实例撤销会话时会立即更新缓存，有效期用于多实例部署时兜底
+	sessionCacheTTL = 30 * time.Second
+	// tokenCleanupInterval 清理过期会话的间隔
+	tokenCleanupInterval = time.Hour
+)
+
+// TokenService 登录令牌业务逻辑层
+// 登录时创建会话，签发短期访问令牌和可轮换的刷新令牌；认证中间件通过会话状态判断访问令牌是否已撤销
+type TokenService struct {
+	sessionRepo *repository.AuthSessionRepository
+	userRepo    *repository.UserRepository
+	timeWheel   *utils.MultiTimeWheel
+	accessTTL   time.Duration
+	refreshTTL  time.Duration
+	cache       map[int64]cachedSession
+	cacheMutex  sync.RWMutex
+}
+
+// cachedSession 缓存的会话状态
+type cachedSession struct {
+	userID    int64
+	active    bool
+	blocked   bool // 用户已被封禁
+	expiresAt time.Time
+}
+
+// NewTokenService 创建登录令牌服务实例
+func NewTokenService(sessionRepo *repository.AuthSessionRepository, userRepo *repository.UserRepository, timeWheel *utils.MultiTimeWheel, cfg *config.JWTConfig) *TokenService {
+	return &TokenService{
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+		timeWheel:   timeWheel,
+		accessTTL:   cfg.ExpireTime,
+		refreshTTL:  cfg.RefreshExpireTime,
+		cache:       make(map[int64]cachedSession),
+	}
+}
+
+// TokenPair 访问令牌与刷新令牌
+type TokenPair struct {
+	Token        string `json:"token"`         // 访问令牌（JWT）
+	RefreshToken string `json:"refresh_token"` // 刷新令牌，只能使用一次
+	ExpiresIn    int64  `json:"expires_in"`    // 访问令牌有效期（秒）
+}
+
+// RefreshRequest 刷新令牌请求
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// ClientInfo 登录客户端信息，记录到会话中用于设备管理
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// SessionInfo 登录会话信息
+type SessionInfo struct {
+	models.AuthSession
+	Current bool `json:"current"` // 是否为当前请求所用的会话
+}
+
+// IssueTokens 为登录成功的用户创建会话并签发令牌
+func (s *TokenService) IssueTokens(user *models.User, client ClientInfo) (*TokenPair, error) {
+	raw, err := newRandomToken()
+	if err != nil {
+		return nil, errors.NewInternalServerError("生成令牌失败")
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.refreshTTL)
+	userAgent := truncateRunes(client.UserAgent, 255)
+	session := &models.AuthSession{
+		ID:         utils.JSONInt64(utils.GenID()),
+		UserID:     user.ID,
+		Device:     utils.ParseDevice(userAgent),
+		IP:         client.IP,
+		UserAgent:  userAgent,
+		LastSeenAt: now,
+		ExpiresAt:  expiresAt,
+	}
+	token := &models.RefreshToken{
+		SessionID: session.ID,
+		UserID:    user.ID,
+		TokenHash: hashToken(raw),
+		ExpiresAt: expiresAt,
+	}
+	if err := s.sessionRepo.CreateWithToken(session, token); err != nil {
+		return nil, errors.NewDatabaseError("create session", err)
+	}
+
+	return s.tokenPair(user, session.ID.Int64(), raw)
+}
+
+// Refresh 使用刷新令牌换取新的令牌，旧刷新令牌随即作废
+// 已使用过的刷新令牌再次出现说明令牌可能被盗用，此时撤销整个会话
+func (s *TokenService) Refresh(req *RefreshRequest, client ClientInfo) (*TokenPair, error) {
+	token, err := s.sessionRepo.FindTokenByHash(hashToken(req.RefreshToken))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewUnauthorizedError("刷新令牌无效")
+		}
+		return nil, errors.NewDatabaseError("find refresh token", err)
+	}
+
+	sessionID := token.SessionID.Int64()
+	if token.UsedAt != nil {
+		s.revokeReusedSession(sessionID)
+		return nil, errors.NewUnauthorizedError("刷新令牌已失效，请重新登录")
+	}
+
+	now := time.Now()
+	session, err := s.sessionRepo.FindByID(sessionID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewUnauthorizedError("刷新令牌无效")
+		}
+		return nil, errors.NewDatabaseError("find session", err)
+	}
+	if !session.IsActive(now) || !now.Before(token.ExpiresAt) {
+		return nil, errors.NewUnauthorizedError("登录已过期，请重新登录")
+	}
+
+	user, err := s.userRepo.FindByID(token.UserID.Int64())
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewUnauthorizedError("用户不存在")
+		}
+		return nil, errors.NewDatabaseError("find user", err)
+	}
+	if !user.IsActive() {
+		if err := s.RevokeUserSessions(user.ID.Int64()); err != nil {
+			return nil, err
+		}
+		return nil, errors.NewForbiddenError("账号已被封禁")
+	}
+
+	raw, err := newRandomToken()
+	if err != nil {
+		return nil, errors.NewInternalServerError("生成令牌失败")
+	}
+	next := &models.RefreshToken{
+		SessionID: token.SessionID,
+		UserID:    token.UserID,
+		TokenHash: hashToken(raw),
+		ExpiresAt: now.Add(s.refreshTTL),
+	}
+	if err := s.sessionRepo.RotateToken(token, next, now); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			// 同一个刷新令牌被并发使用
+			s.revokeReusedSession(sessionID)
+			return nil, errors.NewUnauthorizedError("刷新令牌已失效，请重新登录")
+		}
+		return nil, errors.NewDatabaseError("rotate refresh token", err)
+	}
+
+	userAgent := truncateRunes(client.UserAgent, 255)
+	if err := s.sessionRepo.UpdateClient(sessionID, client.IP, userAgent, utils.ParseDevice(userAgent), now); err != nil {
+		logger.Warn("更新会话客户端信息失败", zap.Int64("session_id", sessionID), zap.Error(err))
+	}
+
+	return s.tokenPair(user, sessionID, raw)
+}
+
+// Logout 注销会话，该会话的访问令牌和刷新令牌立即失效
+func (s *TokenService) Logout(userID, sessionID int64) error {
+	if err := s.sessionRepo.Revoke(sessionID, time.Now()); err != nil {
+		return errors.NewDatabaseError("revoke session", err)
+	}
+	s.setCache(sessionID, cachedSession{userID: userID, active: false})
+	return nil
+}
+
+// ListSessions 查询用户当前有效的登录会话，currentSessionID 对应的会话标记为当前会话
+func (s *TokenService) ListSessions(userID, currentSessionID int64) ([]SessionInfo, error) {
+	sessions, err := s.sessionRepo.FindActiveByUserID(userID, time.Now())
+	if err != nil {
+		return nil, errors.NewDatabaseError("find sessions", err)
+	}
+
+	list := make([]SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		list = append(list, SessionInfo{
+			AuthSession: session,
+			Current:     session.ID.Int64() == currentSessionID,
+		})
+	}
+	return list, nil
+}
+
+// RevokeSession 撤销用户自己的某个会话，会话不属于该用户时按不存在处理
+func (s *TokenService) RevokeSession(userID, sessionID int64) error {
+	session, err := s.sessionRepo.FindByID(sessionID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewNotFoundError("会话不存在")
+		}
+		return errors.NewDatabaseError("find session", err)
+	}
+	if session.UserID.Int64() != userID || !session.IsActive(time.Now()) {
+		return errors.NewNotFoundError("会话不存在")
+	}
+	return s.Logout(userID, sessionID)
+}
+
+// RevokeOtherSessions 撤销用户除 keepSessionID 以外的全部会话，用于"退出其他设备"
+func (s *TokenService) RevokeOtherSessions(userID, keepSessionID int64) error {
+	if err := s.sessionRepo.RevokeOthers(userID, keepSessionID, time.Now()); err != nil {
+		return errors.NewDatabaseError("revoke user sessions", err)
+	}
+
+	s.cacheMutex.Lock()
+	for id, entry := range s.cache {
+		if entry.userID == userID && id != keepSessionID {
+			entry.active = false
+			s.cache[id] = entry
+		}
+	}
+	s.cacheMutex.Unlock()
+	return nil
+}
+
+// ForceLogout 强制用户在所有设备上下线（管理员）
+func (s *TokenService) ForceLogout(userID int64) error {
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewNotFoundError("用户不存在")
+		}
+		return errors.NewDatabaseError("find user", err)
+	}
+	if err := s.RevokeUserSessions(userID); err != nil {
+		return err
+	}
+	logger.Info("管理员强制用户下线", zap.Int64("user_id", userID))
+	return nil
+}
+
+// RevokeUserSessions 撤销用户的全部会话，用于修改密码、封禁或删除用户
+func (s *TokenService) RevokeUserSessions(userID int64) error {
+	if err := s.sessionRepo.RevokeByUserID(userID, time.Now()); err != nil {
+		return errors.NewDatabaseError("revoke user sessions", err)
+	}
+
+	s.cacheMutex.Lock()
+	for id, entry := range s.cache {
+		if entry.userID == userID {
+			entry.active = false
+			s.cache[id] = entry
+		}
+	}
+	s.cacheMutex.Unlock()
+	return nil
+}
+
+// ClearUserCache 清除用户全部会话的状态缓存，用于解封用户后立即恢复访问
+func (s *TokenService) ClearUserCache(userID int64) {
+	s.cacheMutex.Lock()
+	for id, entry := range s.cache {
+		if entry.userID == userID {
+			delete(s.cache, id)
+		}
+	}
+	s.cacheMutex.Unlock()
+}
+
+// ValidateSession 检查访问令牌所属的会话是否仍然有效、用户是否已被封禁，优先使用缓存，每个认证请求都会调用
+func (s *TokenService) ValidateSession(userID, sessionID int64) error {
+	if sessionID == 0 {
+		return errors.NewUnauthorizedError("令牌已失效，请重新登录")
+	}
+
+	s.cacheMutex.RLock()
+	cached, ok := s.cache[sessionID]
+	s.cacheMutex.RUnlock()
+
+	now := time.Now()
+	if !ok || !now.Before(cached.expiresAt) {
+		session, err := s.sessionRepo.FindByID(sessionID)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return errors.NewDatabaseError("find session", err)
+		}
+		cached = cachedSession{
+			userID: userID,
+			active: err == nil && session.UserID.Int64() == userID && session.IsActive(now),
+		}
+		if cached.active {
+			user, err := s.userRepo.FindByID(userID)
+			if err != nil && err != gorm.ErrRecordNotFound {
+				return errors.NewDatabaseError("find user", err)
+			}
+			cached.active = err == nil
+			cached.blocked = err == nil && !user.IsActive()
+		}
+		if cached.active {
+			// 最近活跃时间随缓存刷新更新，精度为缓存有效期
+			if err := s.sessionRepo.Touch(sessionID, now); err != nil {
+				logger.Warn("更新会话活跃时间失败", zap.Int64("session_id", sessionID), zap.Error(err))
+			}
+		}
+		s.setCache(sessionID, cached)
+	}
+
+	if cached.blocked {
+		return errors.NewForbiddenError("账号已被封禁")
+	}
+	if !cached.active || cached.userID != userID {
+		return errors.NewUnauthorizedError("令牌已失效，请重新登录")
+	}
+	return nil
+}
+
+// CleanupExpired 删除过期或已撤销超过一个访问令牌有效期的会话，并清理过期的缓存
+func (s *TokenService) CleanupExpired() (int64, error) {
+	deleted, err := s.sessionRepo.DeleteExpired(time.Now().Add(-s.accessTTL))
+	if err != nil {
+		return 0, errors.NewDatabaseError("delete expired sessions", err)
+	}
+
+	now := time.Now()
+	s.cacheMutex.Lock()
+	for id, entry := range s.cache {
+		if !now.Before(entry.expiresAt) {
+			delete(s.cache, id)
+		}
+	}
+	s.cacheMutex.Unlock()
+	return deleted, nil
+}
+
+// StartCleanup 启动过期会话的定时清理任务
+func (s *TokenService) StartCleanup() {
+	var task func()
+	task = func() {
+		count, err := s.CleanupExpired()
+		if err != nil {
+			logger.Error("清理过期会话失败", zap.Error(err))
+		} else if count > 0 {
+			logger.Info("已清理过期会话", zap.Int64("count", count))
+		}
+		s.timeWheel.AddTask(time.Now().Add(tokenCleanupInterval), task, nil, true) // 不持久化任务
+	}
+	go task()
+}
+
+// tokenPair 为会话签发访问令牌并与刷新令牌组合
+func (s *TokenService) tokenPair(user *models.User, sessionID int64, refreshToken string) (*TokenPair, error) {
+	accessToken, err := utils.GenerateToken(user.ID.Int64(), sessionID, user.Username, user.Role, user.FirstLogin)
+	if err != nil {
+		return nil, errors.NewInternalServerError("生成令牌失败")
+	}
+	return &TokenPair{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.accessTTL / time.Second),
+	}, nil
+}
+
+// revokeReusedSession 刷新令牌被重复使用时撤销会话
+func (s *TokenService) revokeReusedSession(sessionID int64) {
+	if err := s.sessionRepo.Revoke(sessionID, time.Now()); err != nil {
+		logger.Error("撤销会话失败", zap.Int64("session_id", sessionID), zap.Error(err))
+		return
+	}
+	s.cacheMutex.Lock()
+	if entry, ok := s.cache[sessionID]; ok {
+		entry.active = false
+		s.cache[sessionID] = entry
+	}
+	s.cacheMutex.Unlock()
+	logger.Warn("刷新令牌被重复使用，已撤销会话", zap.Int64("session_id", sessionID))
+}
+
+// setCache 写入会话状态缓存
+func (s *TokenService) setCache(sessionID int64, entry cachedSession) {
+	entry.expiresAt = time.Now().Add(sessionCacheTTL)
+	s.cacheMutex.Lock()
+	s.cache[sessionID] = entry
+	s.cacheMutex.Unlock()
+}
+
+// truncateRunes 按字符截断字符串，避免超出数据库字段长度
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
+
+// newRandomToken 生成随机令牌，用于刷新令牌和设置密码链接
+func newRandomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashToken 计算令牌的摘要，数据库只保存摘要
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"gohotel/internal/config"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/logger"
+	"gohotel/pkg/utils"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// userAnonymizeInterval 检查回收站中到期用户的间隔
+	userAnonymizeInterval = time.Hour
+	// userAnonymizeBatch 每次最多匿名化的用户数量
+	userAnonymizeBatch = 100
+)
+
+// UserRecycleService 用户回收站业务逻辑层
+// 删除的用户在保留期内可以恢复，期满后清除个人信息（匿名化），预订等历史记录保留
+type UserRecycleService struct {
+	userRepo  *repository.UserRepository
+	timeWheel *utils.MultiTimeWheel
+	retention time.Duration
+}
+
+// NewUserRecycleService 创建用户回收站服务实例
+func NewUserRecycleService(userRepo *repository.UserRepository, timeWheel *utils.MultiTimeWheel, cfg *config.UserConfig) *UserRecycleService {
+	return &UserRecycleService{
+		userRepo:  userRepo,
+		timeWheel: timeWheel,
+		retention: cfg.DeletedRetention,
+	}
+}
+
+// DeletedUser 回收站中的用户
+type DeletedUser struct {
+	models.User
+	PurgeAt time.Time `json:"purge_at"` // 到期匿名化的时间，此后不可恢复
+}
+
+// ListDeleted 分页查询回收站中的用户
+func (s *UserRecycleService) ListDeleted(page, pageSize int) ([]DeletedUser, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	users, total, err := s.userRepo.FindDeleted(page, pageSize)
+	if err != nil {
+		return nil, 0, errors.NewDatabaseError("find deleted users", err)
+	}
+	result := make([]DeletedUser, len(users))
+	for i, user := range users {
+		result[i] = DeletedUser{User: user, PurgeAt: user.DeletedAt.Time.Add(s.retention)}
+	}
+	return result, total, nil
+}
+
+// Restore 从回收站恢复用户，已匿名化的用户不能恢复
+// 删除时已撤销的登录会话不会恢复，用户需要重新登录
+func (s *UserRecycleService) Restore(id int64) (*models.User, error) {
+	user, err := s.userRepo.FindDeletedByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("回收站中没有该用户")
+		}
+		return nil, errors.NewDatabaseError("find deleted user", err)
+	}
+	if user.AnonymizedAt != nil {
+		return nil, errors.NewBadRequestError("用户已超过保留期被匿名化，不能恢复")
+	}
+
+	if err := s.userRepo.Restore(id); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewBadRequestError("用户已超过保留期被匿名化，不能恢复")
+		}
+		return nil, errors.NewDatabaseError("restore user", err)
+	}
+	user.DeletedAt = gorm.DeletedAt{}
+	return user, nil
+}
+
+// AnonymizeExpired 匿名化超过保留期的已删除用户，返回处理数量
+func (s *UserRecycleService) AnonymizeExpired() (int64, error) {
+	now := time.Now()
+	var count int64
+	for {
+		ids, err := s.userRepo.FindDeletedBefore(now.Add(-s.retention), userAnonymizeBatch)
+		if err != nil {
+			return count, errors.NewDatabaseError("find expired deleted users", err)
+		}
+		for _, id := range ids {
+			if err := s.userRepo.Anonymize(id, now); err != nil {
+				if err == gorm.ErrRecordNotFound {
+					continue // 已被恢复或已由其他实例处理
+				}
+				return count, errors.NewDatabaseError("anonymize user", err)
+			}
+			count++
+		}
+		if len(ids) < userAnonymizeBatch {
+			return count, nil
+		}
+	}
+}
+
+// StartAnonymizer 启动回收站到期用户的定时匿名化任务
+func (s *UserRecycleService) StartAnonymizer() {
+	var task func()
+	task = func() {
+		count, err := s.AnonymizeExpired()
+		if err != nil {
+			logger.Error("匿名化已删除用户失败", zap.Error(err))
+		} else if count > 0 {
+			logger.Info("已匿名化回收站中到期的用户", zap.Int64("count", count))
+		}
+		s.timeWheel.AddTask(time.Now().Add(userAnonymizeInterval), task, nil, true) // 不持久化任务
+	}
+	go task()
+}
@@ -6,19 +6,39 @@ import (
 	"gohotel/pkg/errors"
 	"gohotel/pkg/utils"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 // UserService 用户业务逻辑层
 type UserService struct {
-	userRepo *repository.UserRepository
+	userRepo            *repository.UserRepository
+	tokenService        *TokenService
+	captchaService      *CaptchaService
+	verificationService *VerificationService
+	passwordService     *PasswordResetService
+	loginThrottle       *LoginThrottleService
+	mfaService          *MFAService
+	bookingRepo         *repository.BookingRepository
+	roleRepo            *repository.RoleRepository
 }
 
 // NewUserService 创建用户服务实例
-func NewUserService(userRepo *repository.UserRepository) *UserService {
-	return &UserService{userRepo: userRepo}
+func NewUserService(userRepo *repository.UserRepository, tokenService *TokenService, captchaService *CaptchaService, verificationService *VerificationService, passwordService *PasswordResetService, loginThrottle *LoginThrottleService, mfaService *MFAService, bookingRepo *repository.BookingRepository, roleRepo *repository.RoleRepository) *UserService {
+	return &UserService{
+		userRepo:            userRepo,
+		tokenService:        tokenService,
+		captchaService:      captchaService,
+		verificationService: verificationService,
+		passwordService:     passwordService,
+		loginThrottle:       loginThrottle,
+		mfaService:          mfaService,
+		bookingRepo:         bookingRepo,
+		roleRepo:            roleRepo,
+	}
 }
 
 // RegisterRequest 注册请求结构
@@ -28,6 +48,12 @@ type RegisterRequest struct {
 	Password string `json:"password" binding:"required,min=6"`
 	Phone    string `json:"phone"`
 	RealName string `json:"real_name"`
+
+	CaptchaID     string `json:"captcha_id"`     // 图形验证码 ID，必填
+	CaptchaAnswer string `json:"captcha_answer"` // 图形验证码答案，必填
+
+	EmailCode string `json:"email_code"` // 邮箱验证码，必填
+	PhoneCode string `json:"phone_code"` // 手机验证码，填写手机号时必填
 }
 
 // AddUserRequest 添加用户请求结构
@@ -36,19 +62,50 @@ type AddUserRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Phone    string `json:"phone"`
 	RealName string `json:"real_name"`
-	Role     string `json:"role"`
+	Invite   bool   `json:"invite"` // 为 true 时向用户邮箱发送邀请链接由用户自行设置密码，否则生成随机初始密码
+}
+
+// AddUserResponse 添加用户响应结构
+type AddUserResponse struct {
+	User            *models.User `json:"user"`
+	InitialPassword string       `json:"initial_password,omitempty"`  // 随机初始密码，只在创建时返回一次，用户首次登录后必须修改
+	InviteExpiresAt *time.Time   `json:"invite_expires_at,omitempty"` // 邀请链接的过期时间
 }
 
 // LoginRequest 登录请求结构
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+
+	CaptchaID     string `json:"captcha_id"`     // 图形验证码 ID，登录失败次数过多后必填
+	CaptchaAnswer string `json:"captcha_answer"` // 图形验证码答案
+}
+
+// CodeLoginRequest 验证码登录请求结构
+type CodeLoginRequest struct {
+	Target string `json:"target" binding:"required"` // 已绑定的手机号或邮箱
+	Code   string `json:"code" binding:"required"`   // 短信/邮件验证码
+}
+
+// UpdateProfileRequest 更新个人信息请求结构
+type UpdateProfileRequest struct {
+	Phone     string `json:"phone"`
+	PhoneCode string `json:"phone_code"` // 新手机号的验证码，修改手机号时必填
+	RealName  string `json:"real_name"`
+	Avatar    string `json:"avatar"`
 }
 
 // LoginResponse 登录响应结构
+// 需要两步验证时只返回 mfa，不返回用户信息和令牌
 type LoginResponse struct {
-	User  *models.User `json:"user"`
-	Token string       `json:"token"`
+	User *models.User `json:"user,omitempty"`
+	*TokenPair
+	MFA *MFAChallengeResponse `json:"mfa,omitempty"`
+}
+
+// UpdateUserStatusRequest 修改用户状态请求结构
+type UpdateUserStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=active blocked"` // 状态：active, blocked
 }
 
 // DeleteUsersRequest 批量删除用户请求结构
@@ -58,6 +115,11 @@ type DeleteUsersRequest struct {
 
 // Register 用户注册
 func (s *UserService) Register(req *RegisterRequest) (*models.User, error) {
+	// 0. 校验图形验证码
+	if err := s.captchaService.Verify(req.CaptchaID, req.CaptchaAnswer); err != nil {
+		return nil, err
+	}
+
 	// 1. 检查用户名是否已存在
 	exists, err := s.userRepo.ExistsByUsername(req.Username)
 	if err != nil {
@@ -87,16 +149,26 @@ func (s *UserService) Register(req *RegisterRequest) (*models.User, error) {
 		}
 	}
 
-	// 4. 加密密码
+	// 4. 校验邮箱和手机号的验证码
+	if err := s.verificationService.Verify(req.Email, models.VerifyPurposeRegister, req.EmailCode); err != nil {
+		return nil, err
+	}
+	if req.Phone != "" {
+		if err := s.verificationService.Verify(req.Phone, models.VerifyPurposeRegister, req.PhoneCode); err != nil {
+			return nil, err
+		}
+	}
+
+	// 5. 加密密码
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
 		return nil, errors.NewInternalServerError("密码加密失败")
 	}
 
-	// 5. 生成雪花 ID
+	// 6. 生成雪花 ID
 	userID := utils.GenID()
 
-	// 6. 创建用户对象
+	// 7. 创建用户对象
 	var phonePtr *string
 	if req.Phone != "" {
 		phonePtr = &req.Phone
@@ -112,7 +184,7 @@ func (s *UserService) Register(req *RegisterRequest) (*models.User, error) {
 		Status:   "active",
 	}
 
-	// 7. 保存到数据库
+	// 8. 保存到数据库
 	if err := s.userRepo.Create(user); err != nil {
 		return nil, errors.NewDatabaseError("create user", err)
 	}
@@ -121,13 +193,19 @@ func (s *UserService) Register(req *RegisterRequest) (*models.User, error) {
 }
 
 // Login 用户登录
-func (s *UserService) Login(req *LoginRequest) (*LoginResponse, error) {
+// 同一 IP 或同一账号失败次数过多时暂停登录，账号锁定时长按连续锁定次数指数增长
+func (s *UserService) Login(req *LoginRequest, client ClientInfo) (*LoginResponse, error) {
 	// 1. 查找用户
 	account := strings.TrimSpace(req.Username)
 	if account == "" {
 		return nil, errors.NewUnauthorizedError("用户名或密码错误")
 	}
 
+	// 同一 IP 失败次数过多时暂停登录
+	if err := s.loginThrottle.CheckIP(client.IP); err != nil {
+		return nil, err
+	}
+
 	var (
 		user *models.User
 		err  error
@@ -144,35 +222,88 @@ func (s *UserService) Login(req *LoginRequest) (*LoginResponse, error) {
 	}
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
+			if err := s.loginThrottle.RecordFailure(nil, client.IP); err != nil {
+				return nil, err
+			}
 			return nil, errors.NewUnauthorizedError("用户名或密码错误")
 		}
 		return nil, errors.NewDatabaseError("find user", err)
 	}
 
-	// 2. 检查账号状态
-	if !user.IsActive() {
-		return nil, errors.NewForbiddenError("账号已被封禁")
+	// 账号失败次数过多时需要图形验证码，次数以数据库中的登录失败记录为准
+	failures, err := s.loginThrottle.FailureCount(user.ID.Int64())
+	if err != nil {
+		return nil, err
+	}
+	if s.captchaService.LoginCaptchaRequired(failures) {
+		if err := s.captchaService.Verify(req.CaptchaID, req.CaptchaAnswer); err != nil {
+			return nil, err
+		}
+	}
+
+	// 2. 锁定期间不校验密码
+	if err := s.loginThrottle.CheckAccount(user.ID.Int64()); err != nil {
+		return nil, err
 	}
 
-	// 3. 验证密码
+	// 3. 验证密码，连续失败达到阈值后锁定账号
 	if !utils.CheckPassword(req.Password, user.Password) {
+		if err := s.loginThrottle.RecordFailure(user, client.IP); err != nil {
+			return nil, err
+		}
 		return nil, errors.NewUnauthorizedError("用户名或密码错误")
 	}
+	if err := s.loginThrottle.RecordSuccess(user.ID.Int64()); err != nil {
+		return nil, err
+	}
 
-	// 4. 生成 JWT 令牌
-	token, err := utils.GenerateToken(user.ID.Int64(), user.Username, user.Role)
+	// 检查账号状态，密码正确后才提示封禁，避免暴露账号状态
+	if !user.IsActive() {
+		return nil, errors.NewForbiddenError("账号已被封禁")
+	}
+
+	// 4. 启用两步验证时返回第二步验证令牌，否则创建登录会话并签发令牌
+	return s.mfaService.BeginLogin(user, client)
+}
+
+// LoginWithCode 使用手机号或邮箱验证码登录，无需密码
+func (s *UserService) LoginWithCode(req *CodeLoginRequest, client ClientInfo) (*LoginResponse, error) {
+	// 1. 校验验证码，未注册的目标不会收到验证码
+	if err := s.verificationService.Verify(req.Target, models.VerifyPurposeLogin, req.Code); err != nil {
+		return nil, err
+	}
+
+	// 2. 查找用户
+	target := strings.TrimSpace(req.Target)
+	var (
+		user *models.User
+		err  error
+	)
+	if strings.Contains(target, "@") {
+		user, err = s.userRepo.FindByEmail(target)
+	} else {
+		user, err = s.userRepo.FindByPhone(target)
+	}
 	if err != nil {
-		return nil, errors.NewInternalServerError("生成令牌失败")
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewUnauthorizedError("账号不存在")
+		}
+		return nil, errors.NewDatabaseError("find user", err)
+	}
+
+	// 3. 检查账号状态
+	if !user.IsActive() {
+		return nil, errors.NewForbiddenError("账号已被封禁")
 	}
 
-	return &LoginResponse{
-		User:  user,
-		Token: token,
-	}, nil
+	// 4. 启用两步验证时返回第二步验证令牌，否则创建登录会话并签发令牌
+	return s.mfaService.BeginLogin(user, client)
 }
 
-// UpdateProfile 更新用户资料
-func (s *UserService) UpdateProfile(userID int64, phone, realName, avatar string) (*models.User, error) {
+// UpdateProfile 更新用户资料，更换手机号时需要新手机号的验证码
+func (s *UserService) UpdateProfile(userID int64, req *UpdateProfileRequest) (*models.User, error) {
+	phone, realName, avatar := req.Phone, req.RealName, req.Avatar
+
 	// 1. 查找用户
 	user, err := s.userRepo.FindByID(userID)
 	if err != nil {
@@ -196,6 +327,9 @@ func (s *UserService) UpdateProfile(userID int64, phone, realName, avatar string
 			if exists {
 				return nil, errors.NewConflictError("手机号已被使用")
 			}
+			if err := s.verificationService.Verify(phone, models.VerifyPurposeChangePhone, req.PhoneCode); err != nil {
+				return nil, err
+			}
 			phonePtr := &phone
 			user.Phone = phonePtr
 		}
@@ -218,7 +352,7 @@ func (s *UserService) UpdateProfile(userID int64, phone, realName, avatar string
 	return user, nil
 }
 
-// ChangePassword 修改密码
+// ChangePassword 修改密码，成功后撤销用户的全部会话，需要重新登录
 func (s *UserService) ChangePassword(userID int64, oldPassword, newPassword string) error {
 	// 1. 查找用户
 	user, err := s.userRepo.FindByID(userID)
@@ -240,13 +374,40 @@ func (s *UserService) ChangePassword(userID int64, oldPassword, newPassword stri
 		return errors.NewInternalServerError("密码加密失败")
 	}
 
-	// 4. 更新密码
+	// 4. 更新密码，首次登录的用户修改密码后解除限制
 	user.Password = hashedPassword
+	user.FirstLogin = false
 	if err := s.userRepo.Update(user); err != nil {
 		return errors.NewDatabaseError("update password", err)
 	}
 
-	return nil
+	// 5. 使旧令牌全部失效
+	return s.tokenService.RevokeUserSessions(userID)
+}
+
+// UpdateUserStatus 修改用户状态，封禁时撤销用户的全部会话
+func (s *UserService) UpdateUserStatus(userID int64, req *UpdateUserStatusRequest) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("用户不存在")
+		}
+		return nil, errors.NewDatabaseError("find user", err)
+	}
+
+	user.Status = req.Status
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, errors.NewDatabaseError("update user status", err)
+	}
+
+	if !user.IsActive() {
+		if err := s.tokenService.RevokeUserSessions(userID); err != nil {
+			return nil, err
+		}
+	} else {
+		s.tokenService.ClearUserCache(userID)
+	}
+	return user, nil
 }
 
 // GetUserByID 根据 ID 获取用户信息
@@ -272,7 +433,8 @@ func (s *UserService) GetUser(page, pageSize int, username, email, phone, realNa
 }
 
 // AddUser 添加用户
-func (s *UserService) AddUser(req *AddUserRequest) (*models.User, error) {
+// 用户的初始密码为随机生成，或通过邀请链接由用户自行设置；首次登录后必须修改密码才能使用其他接口
+func (s *UserService) AddUser(req *AddUserRequest) (*AddUserResponse, error) {
 	// 1. 检查用户名是否已存在
 	exists, err := s.userRepo.ExistsByUsername(req.Username)
 	if err != nil {
@@ -291,8 +453,12 @@ func (s *UserService) AddUser(req *AddUserRequest) (*models.User, error) {
 		return nil, errors.NewConflictError("邮箱已被使用")
 	}
 
-	// 3. 加密默认密码
-	hashedPassword, err := utils.HashPassword("yumi123456")
+	// 3. 生成随机初始密码；邀请模式下该密码不告知任何人，用户通过邀请链接设置密码
+	initialPassword, err := randomPassword(initialPasswordLength)
+	if err != nil {
+		return nil, errors.NewInternalServerError("生成初始密码失败")
+	}
+	hashedPassword, err := utils.HashPassword(initialPassword)
 	if err != nil {
 		return nil, errors.NewInternalServerError("密码加密失败")
 	}
@@ -307,11 +473,11 @@ func (s *UserService) AddUser(req *AddUserRequest) (*models.User, error) {
 		Email:      req.Email,
 		Password:   hashedPassword,
 		RealName:   req.RealName,
-		Role:       "user", // 默认角色为 user
+		Role:       "user", // 权限通过角色分配，不能在添加用户时指定
 		Status:     "active",
 		FirstLogin: true,
 	}
-	user.Role = req.Role // 处理 Phone 字段，将 string 转换为 *string
+	// 处理 Phone 字段，将 string 转换为 *string
 	if req.Phone != "" {
 		phone := req.Phone
 		user.Phone = &phone
@@ -321,21 +487,79 @@ func (s *UserService) AddUser(req *AddUserRequest) (*models.User, error) {
 		return nil, errors.NewDatabaseError("create admin user", err)
 	}
 
-	return user, nil
+	// 7. 返回初始密码或发送邀请链接
+	resp := &AddUserResponse{User: user}
+	if req.Invite {
+		resp.InviteExpiresAt, err = s.passwordService.SendInvite(user)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		resp.InitialPassword = initialPassword
+	}
+	return resp, nil
 }
 
-// DeleteUsers 批量删除用户
+// DeleteUsers 批量删除用户，删除后进入回收站，可在保留期内恢复；有未完成预订的用户和最后一名超级管理员不能删除
 func (s *UserService) DeleteUsers(req *DeleteUsersRequest) error {
 	// 检查用户ID列表是否为空
 	if len(req.UserIDs) == 0 {
 		return errors.NewBadRequestError("用户ID列表不能为空")
 	}
 
-	// 执行批量删除操作
-	err := s.userRepo.BatchDelete(req.UserIDs)
+	ids := make([]int64, len(req.UserIDs))
+	for i, raw := range req.UserIDs {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return errors.NewBadRequestError("无效的用户ID: " + raw)
+		}
+		ids[i] = id
+	}
+
+	// 有未完成预订的用户不能删除
+	activeIDs, err := s.bookingRepo.FindUserIDsWithActiveBookings(ids)
+	if err != nil {
+		return errors.NewDatabaseError("check active bookings", err)
+	}
+	if len(activeIDs) > 0 {
+		blocked := make([]string, len(activeIDs))
+		for i, id := range activeIDs {
+			blocked[i] = strconv.FormatInt(id, 10)
+		}
+		return errors.NewConflictError("以下用户有未完成的预订，不能删除: " + strings.Join(blocked, ", "))
+	}
+
+	// 至少保留一名超级管理员
+	superAdmin, err := s.roleRepo.FindByCode(models.RoleSuperAdmin)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return errors.NewDatabaseError("find super admin role", err)
+	}
+	if err == nil {
+		total, err := s.roleRepo.CountUsersWithRole(superAdmin.ID, nil)
+		if err != nil {
+			return errors.NewDatabaseError("count super admins", err)
+		}
+		others, err := s.roleRepo.CountUsersWithRole(superAdmin.ID, ids)
+		if err != nil {
+			return errors.NewDatabaseError("count super admins", err)
+		}
+		if total > others && others == 0 {
+			return errors.NewBadRequestError("系统至少需要保留一名超级管理员")
+		}
+	}
+
+	// 执行批量删除操作（软删除，用户进入回收站）
+	err = s.userRepo.BatchDelete(req.UserIDs)
 	if err != nil {
 		return errors.NewDatabaseError("batch delete users", err)
 	}
 
+	// 已删除用户的令牌立即失效
+	for _, id := range ids {
+		if err := s.tokenService.RevokeUserSessions(id); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"gohotel/internal/config"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/logger"
+	"gohotel/pkg/utils"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// verifyCodeLength 短信/邮件验证码位数
+	verifyCodeLength = 6
+	// verifyCodeRetention 发送记录的保留时间，需覆盖按目标每日限额的统计窗口
+	verifyCodeRetention = 24 * time.Hour
+	// verifyCleanupInterval 清理发送记录的间隔
+	verifyCleanupInterval = time.Hour
+)
+
+// phonePattern 手机号格式，与登录时区分手机号和用户名的规则一致
+var phonePattern = regexp.MustCompile(`^\d{6,20}$`)
+
+// verifyPurposeNames 验证码用途在消息中的名称
+var verifyPurposeNames = map[string]string{
+	models.VerifyPurposeRegister:    "注册账号",
+	models.VerifyPurposeLogin:       "登录",
+	models.VerifyPurposeChangePhone: "修改手机号",
+}
+
+// VerificationService 短信/邮件验证码业务逻辑层
+// 负责按目标和按 IP 限制发送频率、生成并发送验证码、校验验证码（限时、限次、一次性）
+type VerificationService struct {
+	codeRepo    *repository.VerificationCodeRepository
+	userRepo    *repository.UserRepository
+	smsSender   SMSSender
+	emailSender EmailSender
+	timeWheel   *utils.MultiTimeWheel
+	cfg         *config.VerificationConfig
+}
+
+// NewVerificationService 创建验证码服务实例
+func NewVerificationService(codeRepo *repository.VerificationCodeRepository, userRepo *repository.UserRepository, smsSender SMSSender, emailSender EmailSender, timeWheel *utils.MultiTimeWheel, cfg *config.VerificationConfig) *VerificationService {
+	return &VerificationService{
+		codeRepo:    codeRepo,
+		userRepo:    userRepo,
+		smsSender:   smsSender,
+		emailSender: emailSender,
+		timeWheel:   timeWheel,
+		cfg:         cfg,
+	}
+}
+
+// SendCodeRequest 发送验证码请求
+type SendCodeRequest struct {
+	Target  string `json:"target" binding:"required"`                                    // 手机号或邮箱
+	Purpose string `json:"purpose" binding:"required,oneof=register login change_phone"` // 用途：register, login, change_phone
+}
+
+// SendCodeResponse 发送验证码响应
+type SendCodeResponse struct {
+	Channel     string `json:"channel"`      // 发送渠道：sms, email
+	ExpiresIn   int64  `json:"expires_in"`   // 验证码有效期（秒）
+	ResendAfter int64  `json:"resend_after"` // 多少秒后可以重新发送
+}
+
+// SendCode 向手机号或邮箱发送验证码
+// 登录用途下目标未注册时不发送，但返回相同的结果，避免暴露账号是否存在
+func (s *VerificationService) SendCode(req *SendCodeRequest, ip string) (*SendCodeResponse, error) {
+	target, channel, err := normalizeVerifyTarget(req.Target)
+	if err != nil {
+		return nil, err
+	}
+	if req.Purpose == models.VerifyPurposeChangePhone && channel != models.VerifyChannelSMS {
+		return nil, errors.NewBadRequestError("请输入新的手机号")
+	}
+
+	resp := &SendCodeResponse{
+		Channel:     channel,
+		ExpiresIn:   int64(s.cfg.TTL / time.Second),
+		ResendAfter: int64(s.cfg.ResendInterval / time.Second),
+	}
+
+	now := time.Now()
+	if err := s.checkRateLimit(target, req.Purpose, ip, now); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.targetExists(target, channel)
+	if err != nil {
+		return nil, err
+	}
+	switch req.Purpose {
+	case models.VerifyPurposeRegister, models.VerifyPurposeChangePhone:
+		if exists {
+			if channel == models.VerifyChannelSMS {
+				return nil, errors.NewConflictError("手机号已被使用")
+			}
+			return nil, errors.NewConflictError("邮箱已被使用")
+		}
+	case models.VerifyPurposeLogin:
+		if !exists {
+			return resp, nil
+		}
+	}
+
+	code, err := randomDigits(verifyCodeLength)
+	if err != nil {
+		return nil, errors.NewInternalServerError("生成验证码失败")
+	}
+	record := &models.VerificationCode{
+		Channel:   channel,
+		Target:    target,
+		Purpose:   req.Purpose,
+		CodeHash:  hashVerifyCode(target, code),
+		IP:        ip,
+		ExpiresAt: now.Add(s.cfg.TTL),
+	}
+	if err := s.codeRepo.Create(record); err != nil {
+		return nil, errors.NewDatabaseError("create verification code", err)
+	}
+
+	if err := s.deliver(channel, target, req.Purpose, code); err != nil {
+		// 发送失败时撤回记录，不占用频率限额
+		if delErr := s.codeRepo.Delete(record.ID); delErr != nil {
+			logger.Error("撤回验证码记录失败", zap.Uint("id", record.ID), zap.Error(delErr))
+		}
+		logger.Error("发送验证码失败", zap.String("channel", channel), zap.String("target", target), zap.Error(err))
+		return nil, errors.NewInternalServerError("验证码发送失败，请稍后重试")
+	}
+
+	return resp, nil
+}
+
+// Verify 校验验证码，通过后验证码作废；失败次数达到上限后需要重新获取
+func (s *VerificationService) Verify(target, purpose, code string) error {
+	target, _, err := normalizeVerifyTarget(target)
+	if err != nil {
+		return err
+	}
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return errors.NewBadRequestError("请输入验证码")
+	}
+
+	record, err := s.codeRepo.FindLatest(target, purpose)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewBadRequestError("验证码无效或已过期，请重新获取")
+		}
+		return errors.NewDatabaseError("find verification code", err)
+	}
+
+	now := time.Now()
+	if record.UsedAt != nil || !now.Before(record.ExpiresAt) {
+		return errors.NewBadRequestError("验证码无效或已过期，请重新获取")
+	}
+	if record.Attempts >= s.cfg.MaxAttempts {
+		return errors.NewBadRequestError("验证码错误次数过多，请重新获取")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashVerifyCode(target, code)), []byte(record.CodeHash)) != 1 {
+		if err := s.codeRepo.IncrementAttempts(record.ID); err != nil {
+			return errors.NewDatabaseError("update verification code", err)
+		}
+		return errors.NewBadRequestError("验证码错误")
+	}
+
+	if err := s.codeRepo.MarkUsed(record.ID, now); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			// 同一个验证码被并发使用
+			return errors.NewBadRequestError("验证码无效或已过期，请重新获取")
+		}
+		return errors.NewDatabaseError("use verification code", err)
+	}
+	return nil
+}
+
+// CleanupExpired 删除超过保留时间的发送记录
+func (s *VerificationService) CleanupExpired() (int64, error) {
+	deleted, err := s.codeRepo.DeleteBefore(time.Now().Add(-verifyCodeRetention))
+	if err != nil {
+		return 0, errors.NewDatabaseError("delete verification codes", err)
+	}
+	return deleted, nil
+}
+
+// StartCleanup 启动发送记录的定时清理任务
+func (s *VerificationService) StartCleanup() {
+	var task func()
+	task = func() {
+		count, err := s.CleanupExpired()
+		if err != nil {
+			logger.Error("清理验证码记录失败", zap.Error(err))
+		} else if count > 0 {
+			logger.Info("已清理验证码记录", zap.Int64("count", count))
+		}
+		s.timeWheel.AddTask(time.Now().Add(verifyCleanupInterval), task, nil, true) // 不持久化任务
+	}
+	go task()
+}
+
+// checkRateLimit 检查重发间隔、目标每日限额和 IP 每小时限额
+func (s *VerificationService) checkRateLimit(target, purpose, ip string, now time.Time) error {
+	latest, err := s.codeRepo.FindLatest(target, purpose)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return errors.NewDatabaseError("find verification code", err)
+	}
+	if err == nil {
+		if wait := latest.CreatedAt.Add(s.cfg.ResendInterval).Sub(now); wait > 0 {
+			return errors.NewTooManyRequestsError(fmt.Sprintf("发送过于频繁，请 %d 秒后再试", int64(wait/time.Second)+1))
+		}
+	}
+
+	count, err := s.codeRepo.CountByTargetSince(target, now.Add(-24*time.Hour))
+	if err != nil {
+		return errors.NewDatabaseError("count verification codes", err)
+	}
+	if count >= int64(s.cfg.TargetDaily) {
+		return errors.NewTooManyRequestsError("今日发送次数已达上限，请明天再试")
+	}
+
+	if ip != "" {
+		count, err = s.codeRepo.CountByIPSince(ip, now.Add(-time.Hour))
+		if err != nil {
+			return errors.NewDatabaseError("count verification codes", err)
+		}
+		if count >= int64(s.cfg.IPHourly) {
+			return errors.NewTooManyRequestsError("请求过于频繁，请稍后再试")
+		}
+	}
+	return nil
+}
+
+// targetExists 判断手机号或邮箱是否已被注册
+func (s *VerificationService) targetExists(target, channel string) (bool, error) {
+	var (
+		exists bool
+		err    error
+	)
+	if channel == models.VerifyChannelEmail {
+		exists, err = s.userRepo.ExistsByEmail(target)
+	} else {
+		exists, err = s.userRepo.ExistsByPhone(target)
+	}
+	if err != nil {
+		return false, errors.NewDatabaseError("check verification target", err)
+	}
+	return exists, nil
+}
+
+// deliver 通过对应渠道发送验证码
+func (s *VerificationService) deliver(channel, target, purpose, code string) error {
+	minutes := int64(s.cfg.TTL / time.Minute)
+	if minutes < 1 {
+		minutes = 1
+	}
+	text := fmt.Sprintf("您正在%s，验证码为 %s，%d 分钟内有效。如非本人操作请忽略。", verifyPurposeNames[purpose], code, minutes)
+	if channel == models.VerifyChannelEmail {
+		return s.emailSender.SendEmail(target, "【GoHotel】验证码", text)
+	}
+	return s.smsSender.SendSMS(target, "【GoHotel】"+text)
+}
+
+// normalizeVerifyTarget 规范化手机号或邮箱并识别渠道，邮箱统一为小写
+func normalizeVerifyTarget(target string) (string, string, error) {
+	target = strings.TrimSpace(target)
+	if strings.Contains(target, "@") {
+		at := strings.LastIndex(target, "@")
+		if at == 0 || at == len(target)-1 {
+			return "", "", errors.NewBadRequestError("邮箱格式不正确")
+		}
+		return strings.ToLower(target), models.VerifyChannelEmail, nil
+	}
+	if phonePattern.MatchString(target) {
+		return target, models.VerifyChannelSMS, nil
+	}
+	return "", "", errors.NewBadRequestError("请输入正确的手机号或邮箱")
+}
+
+// hashVerifyCode 计算验证码摘要，混入目标避免相同验证码产生相同摘要
+func hashVerifyCode(target, code string) string {
+	sum := sha256.Sum256([]byte(target + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
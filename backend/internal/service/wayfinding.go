@@ -0,0 +1,370 @@
+package service
+
+import (
+	"container/heap"
+	"math"
+)
+
+// 寻路网格参数
+const (
+	wayfindingStep       = 10.0   // 网格边长
+	wayfindingMaxCells   = 250000 // 网格单元上限，画布过大时自动放大网格边长
+	wayfindingWallCost   = 1.5    // 贴墙单元的通行代价倍数，让路线尽量走在走廊中间
+	wayfindingMarginCost = 3.0    // 可通行区域外沿（门口附近）的通行代价倍数，让路线尽量走在走廊内
+)
+
+// center 返回矩形中心点
+func (s layoutShape) center() layoutPoint {
+	return layoutPoint{
+		X: float64(s.Left) + float64(s.Width)/2,
+		Y: float64(s.Top) + float64(s.Height)/2,
+	}
+}
+
+// contains 判断点是否在旋转矩形内，margin 为向外扩展的距离
+func (s layoutShape) contains(p layoutPoint, margin float64) bool {
+	c := s.center()
+	rad := float64(s.Rotation) * math.Pi / 180
+	cosA, sinA := math.Cos(rad), math.Sin(rad)
+	dx, dy := p.X-c.X, p.Y-c.Y
+	// 将点转换到矩形自身坐标系（反向旋转）
+	lx := dx*cosA + dy*sinA
+	ly := -dx*sinA + dy*cosA
+	return math.Abs(lx) <= float64(s.Width)/2+margin && math.Abs(ly) <= float64(s.Height)/2+margin
+}
+
+// wayfindingGrid 寻路网格
+// cost 为 0 的单元不可通行；start 记录单元所属的起点下标（-1 表示不属于任何起点）；
+// goals 记录覆盖该单元的终点下标（包括门口范围），goalOnly 表示单元只因终点而可通行
+type wayfindingGrid struct {
+	originX, originY float64
+	step             float64
+	cols, rows       int
+	cost             []float64
+	start            []int
+	goals            [][]int
+	goalOnly         []bool
+}
+
+// point 返回单元中心点
+func (g *wayfindingGrid) point(i int) layoutPoint {
+	return layoutPoint{
+		X: g.originX + (float64(i%g.cols)+0.5)*g.step,
+		Y: g.originY + (float64(i/g.cols)+0.5)*g.step,
+	}
+}
+
+// newWayfindingGrid 根据可通行区域、起点和终点建立寻路网格
+// 走廊、起点和终点内部的单元代价为 1，贴墙的单元代价略高；
+// 向外扩展一个网格边长的范围作为门口（代价更高），相邻但有细小缝隙的区域也能连通
+func newWayfindingGrid(walkable, starts, goals []layoutShape) *wayfindingGrid {
+	shapes := make([]layoutShape, 0, len(walkable)+len(starts))
+	shapes = append(shapes, walkable...)
+	shapes = append(shapes, starts...)
+
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, s := range append(shapes, goals...) {
+		l, t, r, b := rotatedBounds(s.Left, s.Top, s.Width, s.Height, s.Rotation)
+		minX, minY = math.Min(minX, l), math.Min(minY, t)
+		maxX, maxY = math.Max(maxX, r), math.Max(maxY, b)
+	}
+
+	step := wayfindingStep
+	if area := (maxX - minX + 2*step) * (maxY - minY + 2*step); area/(step*step) > wayfindingMaxCells {
+		step = math.Ceil(math.Sqrt(area / wayfindingMaxCells))
+	}
+
+	g := &wayfindingGrid{
+		originX: minX - step,
+		originY: minY - step,
+		step:    step,
+		cols:    int(math.Ceil((maxX-minX)/step)) + 2,
+		rows:    int(math.Ceil((maxY-minY)/step)) + 2,
+	}
+	n := g.cols * g.rows
+	g.cost = make([]float64, n)
+	g.start = make([]int, n)
+	g.goals = make([][]int, n)
+	g.goalOnly = make([]bool, n)
+
+	for i := 0; i < n; i++ {
+		p := g.point(i)
+		g.start[i] = -1
+		for _, s := range shapes {
+			g.cost[i] = minCellCost(g.cost[i], shapeCellCost(s, p, step))
+		}
+		baseCost := g.cost[i]
+		for j, s := range goals {
+			cost := shapeCellCost(s, p, step)
+			if cost > 0 {
+				g.goals[i] = append(g.goals[i], j)
+				g.cost[i] = minCellCost(g.cost[i], cost)
+			}
+		}
+		g.goalOnly[i] = baseCost == 0 && g.cost[i] > 0
+		// 起点向外扩展半个网格，保证尺寸很小的设施也至少占用一个单元
+		for j, s := range starts {
+			if s.contains(p, step/2) {
+				g.start[i] = j
+				break
+			}
+		}
+	}
+	return g
+}
+
+// shapeCellCost 计算矩形对网格单元的通行代价，0 表示单元不在矩形及其门口范围内
+func shapeCellCost(s layoutShape, p layoutPoint, step float64) float64 {
+	switch {
+	case s.contains(p, -step):
+		return 1
+	case s.contains(p, 0):
+		return wayfindingWallCost
+	case s.contains(p, step):
+		return wayfindingMarginCost
+	}
+	return 0
+}
+
+// minCellCost 取两个通行代价中较小的正值
+func minCellCost(a, b float64) float64 {
+	if a == 0 || b > 0 && b < a {
+		return b
+	}
+	return a
+}
+
+// isGoalCell 判断单元是否属于终点（向外扩展半个网格，保证尺寸很小的终点也至少占用一个单元）
+func (g *wayfindingGrid) isGoalCell(i int, goal layoutShape) bool {
+	return goal.contains(g.point(i), g.step/2)
+}
+
+// findRoute 计算从任一起点到终点的最短路线
+// 从终点出发做 Dijkstra 搜索，最先到达的起点即为最近的起点；
+// 返回起点下标和路线折线（起点中心 → 终点中心），无法到达时 ok 为 false
+func findRoute(walkable, starts []layoutShape, goal layoutShape) (startIndex int, points []layoutPoint, ok bool) {
+	if len(starts) == 0 {
+		return -1, nil, false
+	}
+	g := newWayfindingGrid(walkable, starts, []layoutShape{goal})
+	n := len(g.cost)
+
+	dist := make([]float64, n)
+	prev := make([]int, n)
+	for i := range dist {
+		dist[i] = math.Inf(1)
+		prev[i] = -1
+	}
+
+	goalCenter := goal.center()
+	queue := &wayfindingQueue{}
+	for i := 0; i < n; i++ {
+		if g.cost[i] > 0 && g.isGoalCell(i, goal) {
+			dist[i] = distance(goalCenter, g.point(i))
+			heap.Push(queue, wayfindingItem{index: i, dist: dist[i]})
+		}
+	}
+
+	reached := -1
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(wayfindingItem)
+		if item.dist > dist[item.index] {
+			continue
+		}
+		if g.start[item.index] >= 0 {
+			reached = item.index
+			break
+		}
+		for _, next := range g.neighbors(item.index) {
+			d := item.dist + distance(g.point(item.index), g.point(next))*math.Max(g.cost[item.index], g.cost[next])
+			if d < dist[next] {
+				dist[next] = d
+				prev[next] = item.index
+				heap.Push(queue, wayfindingItem{index: next, dist: d})
+			}
+		}
+	}
+	if reached < 0 {
+		return -1, nil, false
+	}
+
+	// 沿前驱回溯即为起点到终点的顺序
+	startIndex = g.start[reached]
+	points = append(points, starts[startIndex].center())
+	for i := reached; i >= 0; i = prev[i] {
+		points = append(points, g.point(i))
+	}
+	points = append(points, goalCenter)
+	return startIndex, simplifyPolyline(points), true
+}
+
+// layoutRoute 起点到终点的路线
+type layoutRoute struct {
+	StartIndex int           // 最近起点的下标
+	Points     []layoutPoint // 路线折线（起点中心 → 终点中心）
+	OK         bool          // 是否可以到达
+}
+
+// findRoutesToGoals 计算每个终点到最近起点的路线
+// 在同一张网格上从所有起点同时出发做一次 Dijkstra 搜索，再读取每个终点的最短距离；
+// 终点只能作为路线的末端，路线不会穿过其他终点（例如房间）
+func findRoutesToGoals(walkable, starts, goals []layoutShape) []layoutRoute {
+	routes := make([]layoutRoute, len(goals))
+	if len(starts) == 0 || len(goals) == 0 {
+		return routes
+	}
+	g := newWayfindingGrid(walkable, starts, goals)
+	n := len(g.cost)
+
+	dist := make([]float64, n)
+	prev := make([]int, n)
+	source := make([]int, n)
+	for i := range dist {
+		dist[i] = math.Inf(1)
+		prev[i] = -1
+		source[i] = g.start[i]
+	}
+
+	queue := &wayfindingQueue{}
+	for i := 0; i < n; i++ {
+		if g.start[i] >= 0 && g.cost[i] > 0 {
+			dist[i] = distance(starts[g.start[i]].center(), g.point(i))
+			heap.Push(queue, wayfindingItem{index: i, dist: dist[i]})
+		}
+	}
+
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(wayfindingItem)
+		if item.dist > dist[item.index] {
+			continue
+		}
+		// 只因终点而可通行的单元是路线末端，不再继续扩展
+		if g.goalOnly[item.index] {
+			continue
+		}
+		for _, next := range g.neighbors(item.index) {
+			d := item.dist + distance(g.point(item.index), g.point(next))*math.Max(g.cost[item.index], g.cost[next])
+			if d < dist[next] {
+				dist[next] = d
+				prev[next] = item.index
+				source[next] = source[item.index]
+				heap.Push(queue, wayfindingItem{index: next, dist: d})
+			}
+		}
+	}
+
+	// 每个终点取到达其单元（包括门口范围）后再直线走到中心的总距离最短的单元
+	best := make([]int, len(goals))
+	bestDist := make([]float64, len(goals))
+	for j := range goals {
+		best[j] = -1
+		bestDist[j] = math.Inf(1)
+	}
+	for i := 0; i < n; i++ {
+		if math.IsInf(dist[i], 1) {
+			continue
+		}
+		for _, j := range g.goals[i] {
+			if d := dist[i] + distance(g.point(i), goals[j].center()); d < bestDist[j] {
+				best[j], bestDist[j] = i, d
+			}
+		}
+	}
+
+	for j, cell := range best {
+		if cell < 0 {
+			continue
+		}
+		var cells []layoutPoint
+		for i := cell; i >= 0; i = prev[i] {
+			cells = append(cells, g.point(i))
+		}
+		startIndex := source[cell]
+		points := make([]layoutPoint, 0, len(cells)+2)
+		points = append(points, starts[startIndex].center())
+		for i := len(cells) - 1; i >= 0; i-- {
+			points = append(points, cells[i])
+		}
+		points = append(points, goals[j].center())
+		routes[j] = layoutRoute{StartIndex: startIndex, Points: simplifyPolyline(points), OK: true}
+	}
+	return routes
+}
+
+// neighbors 返回可通行的相邻单元（八方向，斜向移动不能穿过墙角）
+func (g *wayfindingGrid) neighbors(i int) []int {
+	col, row := i%g.cols, i/g.cols
+	walkable := func(c, r int) bool {
+		return c >= 0 && c < g.cols && r >= 0 && r < g.rows && g.cost[r*g.cols+c] > 0
+	}
+
+	result := make([]int, 0, 8)
+	for dr := -1; dr <= 1; dr++ {
+		for dc := -1; dc <= 1; dc++ {
+			if dr == 0 && dc == 0 || !walkable(col+dc, row+dr) {
+				continue
+			}
+			if dr != 0 && dc != 0 && (!walkable(col+dc, row) || !walkable(col, row+dr)) {
+				continue
+			}
+			result = append(result, (row+dr)*g.cols+col+dc)
+		}
+	}
+	return result
+}
+
+// simplifyPolyline 去掉重复点和共线的中间点
+func simplifyPolyline(points []layoutPoint) []layoutPoint {
+	result := make([]layoutPoint, 0, len(points))
+	for _, p := range points {
+		if len(result) > 0 && distance(result[len(result)-1], p) < 1e-6 {
+			continue
+		}
+		if len(result) >= 2 {
+			a, b := result[len(result)-2], result[len(result)-1]
+			cross := (b.X-a.X)*(p.Y-b.Y) - (b.Y-a.Y)*(p.X-b.X)
+			dot := (b.X-a.X)*(p.X-b.X) + (b.Y-a.Y)*(p.Y-b.Y)
+			if math.Abs(cross) < 1e-6 && dot > 0 {
+				result[len(result)-1] = p
+				continue
+			}
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+// polylineLength 计算折线长度
+func polylineLength(points []layoutPoint) float64 {
+	total := 0.0
+	for i := 1; i < len(points); i++ {
+		total += distance(points[i-1], points[i])
+	}
+	return total
+}
+
+// distance 两点间距离
+func distance(a, b layoutPoint) float64 {
+	return math.Hypot(a.X-b.X, a.Y-b.Y)
+}
+
+// wayfindingItem 优先队列元素
+type wayfindingItem struct {
+	index int
+	dist  float64
+}
+
+// wayfindingQueue 按距离排序的最小堆
+type wayfindingQueue []wayfindingItem
+
+func (q wayfindingQueue) Len() int            { return len(q) }
+func (q wayfindingQueue) Less(i, j int) bool  { return q[i].dist < q[j].dist }
+func (q wayfindingQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *wayfindingQueue) Push(x interface{}) { *q = append(*q, x.(wayfindingItem)) }
+func (q *wayfindingQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
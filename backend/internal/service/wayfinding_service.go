@@ -0,0 +1,133 @@
+package service
+
+import (
+	"fmt"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/pkg/errors"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+// 参与寻路的设施类型
+const (
+	wayfindingCorridorType = "corridor" // 可通行区域
+)
+
+// wayfindingStartTypes 未指定起点时可作为起点的设施类型
+var wayfindingStartTypes = map[string]bool{
+	"elevator": true,
+	"stairs":   true,
+}
+
+// WayfindingService 室内导航业务逻辑层
+// 以走廊为可通行区域，计算从电梯或楼梯到房间的步行路线
+type WayfindingService struct {
+	roomRepo     *repository.RoomRepository
+	facilityRepo *repository.FacilityRepository
+}
+
+// NewWayfindingService 创建室内导航服务实例
+func NewWayfindingService(roomRepo *repository.RoomRepository, facilityRepo *repository.FacilityRepository) *WayfindingService {
+	return &WayfindingService{
+		roomRepo:     roomRepo,
+		facilityRepo: facilityRepo,
+	}
+}
+
+// RoutePoint 路线折线上的点（平面图坐标）
+type RoutePoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// RouteFacility 路线起点设施
+type RouteFacility struct {
+	ID    uint   `json:"id"`
+	Type  string `json:"type"`
+	Label string `json:"label"`
+}
+
+// WayfindingRoute 室内导航路线
+type WayfindingRoute struct {
+	Floor      int           `json:"floor"`
+	RoomID     uint          `json:"room_id"`
+	RoomNumber string        `json:"room_number"`
+	Start      RouteFacility `json:"start"`
+	Points     []RoutePoint  `json:"points"`   // 折线顶点，从起点设施中心到房间中心
+	Distance   float64       `json:"distance"` // 路线长度（平面图单位）
+}
+
+// FindRoute 计算到房间的步行路线
+// startFacilityID 为 0 时从该楼层所有电梯和楼梯中选择步行距离最近的一个作为起点
+func (s *WayfindingService) FindRoute(hotelID uint, roomNumber string, startFacilityID uint) (*WayfindingRoute, error) {
+	room, err := s.roomRepo.FindByRoomNumber(hotelID, roomNumber)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("房间不存在")
+		}
+		return nil, errors.NewDatabaseError("find room", err)
+	}
+	if room.Width <= 0 || room.Height <= 0 {
+		return nil, errors.NewBadRequestError("房间尚未摆放到平面图上")
+	}
+
+	facilities, err := s.facilityRepo.FindByFloor(hotelID, room.Floor)
+	if err != nil {
+		return nil, errors.NewDatabaseError("find facilities", err)
+	}
+
+	var corridors []layoutShape
+	var starts []models.Facility
+	for _, f := range facilities {
+		if f.Width <= 0 || f.Height <= 0 {
+			continue
+		}
+		if f.Type == wayfindingCorridorType {
+			corridors = append(corridors, facilityShape(f))
+		}
+		if startFacilityID != 0 && f.ID == startFacilityID || startFacilityID == 0 && wayfindingStartTypes[f.Type] {
+			starts = append(starts, f)
+		}
+	}
+	if len(starts) == 0 {
+		if startFacilityID != 0 {
+			return nil, errors.NewBadRequestError(fmt.Sprintf("起点设施不存在或不在 %d 楼", room.Floor))
+		}
+		return nil, errors.NewNotFoundError(fmt.Sprintf("%d 楼没有可作为起点的电梯或楼梯", room.Floor))
+	}
+
+	startShapes := make([]layoutShape, len(starts))
+	for i, f := range starts {
+		startShapes[i] = facilityShape(f)
+	}
+	index, points, ok := findRoute(corridors, startShapes, roomShape(*room))
+	if !ok {
+		return nil, errors.NewNotFoundError(fmt.Sprintf("未找到到达房间 %s 的路线，请检查走廊是否连通", room.RoomNumber))
+	}
+
+	start := starts[index]
+	return &WayfindingRoute{
+		Floor:      room.Floor,
+		RoomID:     room.ID,
+		RoomNumber: room.RoomNumber,
+		Start:      RouteFacility{ID: start.ID, Type: start.Type, Label: start.Label},
+		Points:     newRoutePoints(points),
+		Distance:   roundRouteValue(polylineLength(points)),
+	}, nil
+}
+
+// newRoutePoints 将折线顶点转换为响应格式
+func newRoutePoints(points []layoutPoint) []RoutePoint {
+	result := make([]RoutePoint, len(points))
+	for i, p := range points {
+		result[i] = RoutePoint{X: roundRouteValue(p.X), Y: roundRouteValue(p.Y)}
+	}
+	return result
+}
+
+// roundRouteValue 坐标和距离保留一位小数
+func roundRouteValue(v float64) float64 {
+	return math.Round(v*10) / 10
+}
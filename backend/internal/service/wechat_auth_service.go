@@ -0,0 +1,271 @@
+package service
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"gohotel/internal/config"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/logger"
+	"gohotel/pkg/utils"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// wechatEmailDomain 微信新用户的占位邮箱域名，保证 users.email 唯一且不会被当作真实邮箱投递
+const wechatEmailDomain = "@wechat.invalid"
+
+// WechatAuthService 微信小程序登录业务逻辑层
+// 使用 js_code 换取 openid 后创建或关联用户并签发与密码登录相同的令牌，支持解密并绑定微信手机号
+type WechatAuthService struct {
+	wechatRepo *repository.WechatAccountRepository
+	userRepo   *repository.UserRepository
+	mfaService *MFAService
+	client     WechatClient
+	appID      string
+}
+
+// NewWechatAuthService 创建微信登录服务实例
+func NewWechatAuthService(wechatRepo *repository.WechatAccountRepository, userRepo *repository.UserRepository, mfaService *MFAService, client WechatClient, cfg *config.WechatConfig) *WechatAuthService {
+	return &WechatAuthService{
+		wechatRepo: wechatRepo,
+		userRepo:   userRepo,
+		mfaService: mfaService,
+		client:     client,
+		appID:      cfg.AppID,
+	}
+}
+
+// WechatCodeRequest 微信登录凭证请求
+type WechatCodeRequest struct {
+	Code string `json:"code" binding:"required"` // wx.login 获取的 js_code
+}
+
+// WechatLoginResponse 微信登录响应
+type WechatLoginResponse struct {
+	LoginResponse
+	IsNewUser bool `json:"is_new_user"` // 是否为本次登录新创建的用户
+}
+
+// WechatPhoneRequest 绑定微信手机号请求
+type WechatPhoneRequest struct {
+	EncryptedData string `json:"encrypted_data" binding:"required"` // getPhoneNumber 返回的 encryptedData
+	IV            string `json:"iv" binding:"required"`             // getPhoneNumber 返回的 iv
+}
+
+// wechatPhoneInfo 解密后的手机号数据
+type wechatPhoneInfo struct {
+	PhoneNumber     string `json:"phoneNumber"`     // 带区号的手机号（境外手机号）
+	PurePhoneNumber string `json:"purePhoneNumber"` // 不带区号的手机号
+	CountryCode     string `json:"countryCode"`     // 区号
+	Watermark       struct {
+		AppID string `json:"appid"`
+	} `json:"watermark"`
+}
+
+// Login 微信登录
+// openid 已绑定时登录对应用户；未绑定但 unionid 已关联其他小程序的用户时绑定到该用户；否则创建新用户
+func (s *WechatAuthService) Login(req *WechatCodeRequest, client ClientInfo) (*WechatLoginResponse, error) {
+	session, err := s.code2Session(req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	user, isNew, err := s.findOrCreateUser(session)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive() {
+		return nil, errors.NewForbiddenError("账号已被封禁")
+	}
+
+	resp, err := s.mfaService.BeginLogin(user, client)
+	if err != nil {
+		return nil, err
+	}
+	return &WechatLoginResponse{
+		LoginResponse: *resp,
+		IsNewUser:     isNew,
+	}, nil
+}
+
+// BindAccount 将微信绑定到当前登录的用户，用于已有账号的用户在小程序中关联微信
+func (s *WechatAuthService) BindAccount(userID int64, req *WechatCodeRequest) error {
+	session, err := s.code2Session(req.Code)
+	if err != nil {
+		return err
+	}
+
+	account, err := s.wechatRepo.FindByOpenID(session.OpenID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return errors.NewDatabaseError("find wechat account", err)
+	}
+	if err == nil {
+		if account.UserID.Int64() != userID {
+			return errors.NewConflictError("该微信已绑定其他账号")
+		}
+		if err := s.wechatRepo.UpdateSession(account.ID, session.SessionKey, session.UnionID); err != nil {
+			return errors.NewDatabaseError("update wechat session", err)
+		}
+		return nil
+	}
+
+	if _, err := s.wechatRepo.FindByUserAndApp(userID, s.appID); err == nil {
+		return errors.NewConflictError("当前账号已绑定其他微信")
+	} else if err != gorm.ErrRecordNotFound {
+		return errors.NewDatabaseError("find wechat account", err)
+	}
+
+	if err := s.wechatRepo.Create(s.newAccount(utils.JSONInt64(userID), session)); err != nil {
+		return errors.NewDatabaseError("create wechat account", err)
+	}
+	return nil
+}
+
+// BindPhone 使用最近一次微信登录的 session_key 解密手机号并绑定到当前用户
+func (s *WechatAuthService) BindPhone(userID int64, req *WechatPhoneRequest) (*models.User, error) {
+	account, err := s.wechatRepo.FindByUserAndApp(userID, s.appID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewBadRequestError("请先使用微信登录")
+		}
+		return nil, errors.NewDatabaseError("find wechat account", err)
+	}
+
+	plain, err := utils.DecryptWechatData(account.SessionKey, req.EncryptedData, req.IV)
+	if err != nil {
+		return nil, errors.NewBadRequestError("手机号解密失败，请重新登录后再试")
+	}
+	var info wechatPhoneInfo
+	if err := json.Unmarshal(plain, &info); err != nil {
+		return nil, errors.NewBadRequestError("手机号解密失败，请重新登录后再试")
+	}
+	if info.Watermark.AppID != s.appID {
+		return nil, errors.NewBadRequestError("手机号数据不属于当前小程序")
+	}
+	phone := info.PurePhoneNumber
+	if info.CountryCode != "" && info.CountryCode != "86" {
+		phone = strings.TrimPrefix(info.PhoneNumber, "+")
+	}
+	if phone == "" {
+		return nil, errors.NewBadRequestError("未获取到手机号")
+	}
+
+	exists, err := s.userRepo.ExistsByPhoneExcludingUser(phone, userID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("check phone", err)
+	}
+	if exists {
+		return nil, errors.NewConflictError("手机号已被其他账号使用")
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("用户不存在")
+		}
+		return nil, errors.NewDatabaseError("find user", err)
+	}
+	user.Phone = &phone
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, errors.NewDatabaseError("update user", err)
+	}
+	return user, nil
+}
+
+// findOrCreateUser 根据 openid/unionid 查找用户，不存在时创建，返回用户及是否为新用户
+func (s *WechatAuthService) findOrCreateUser(session *WechatSession) (*models.User, bool, error) {
+	// 1. openid 已绑定
+	account, err := s.wechatRepo.FindByOpenID(session.OpenID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, false, errors.NewDatabaseError("find wechat account", err)
+	}
+	if err == nil {
+		if err := s.wechatRepo.UpdateSession(account.ID, session.SessionKey, session.UnionID); err != nil {
+			return nil, false, errors.NewDatabaseError("update wechat session", err)
+		}
+		user, err := s.findUser(account.UserID.Int64())
+		return user, false, err
+	}
+
+	// 2. 同一开放平台下的其他小程序已关联用户
+	if session.UnionID != "" {
+		linked, err := s.wechatRepo.FindByUnionID(session.UnionID)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return nil, false, errors.NewDatabaseError("find wechat account", err)
+		}
+		if err == nil {
+			if err := s.wechatRepo.Create(s.newAccount(linked.UserID, session)); err != nil {
+				return nil, false, errors.NewDatabaseError("create wechat account", err)
+			}
+			user, err := s.findUser(linked.UserID.Int64())
+			return user, false, err
+		}
+	}
+
+	// 3. 创建新用户，密码随机且不告知用户，之后可通过找回密码设置
+	raw, err := newRandomToken()
+	if err != nil {
+		return nil, false, errors.NewInternalServerError("生成初始密码失败")
+	}
+	hashedPassword, err := utils.HashPassword(raw)
+	if err != nil {
+		return nil, false, errors.NewInternalServerError("密码加密失败")
+	}
+	// 用户名由雪花 ID 生成，保证唯一
+	id := utils.GenID()
+	username := "wx_" + strconv.FormatInt(id, 10)
+	user := &models.User{
+		ID:       utils.JSONInt64(id),
+		Username: username,
+		Email:    username + wechatEmailDomain,
+		Password: hashedPassword,
+		Role:     "user",
+		Status:   "active",
+	}
+	if err := s.wechatRepo.CreateWithUser(user, s.newAccount(0, session)); err != nil {
+		return nil, false, errors.NewDatabaseError("create wechat user", err)
+	}
+	return user, true, nil
+}
+
+// findUser 查找绑定的用户
+func (s *WechatAuthService) findUser(userID int64) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewUnauthorizedError("绑定的用户不存在")
+		}
+		return nil, errors.NewDatabaseError("find user", err)
+	}
+	return user, nil
+}
+
+// newAccount 根据登录凭证校验结果构造绑定记录
+func (s *WechatAuthService) newAccount(userID utils.JSONInt64, session *WechatSession) *models.WechatAccount {
+	return &models.WechatAccount{
+		UserID:     userID,
+		AppID:      s.appID,
+		OpenID:     session.OpenID,
+		UnionID:    session.UnionID,
+		SessionKey: session.SessionKey,
+	}
+}
+
+// code2Session 换取 openid，区分凭证无效和微信服务不可用
+func (s *WechatAuthService) code2Session(code string) (*WechatSession, error) {
+	session, err := s.client.Code2Session(strings.TrimSpace(code))
+	if err != nil {
+		var codeErr *WechatCodeError
+		if stderrors.As(err, &codeErr) {
+			return nil, errors.NewBadRequestError("微信登录凭证无效或已过期，请重试")
+		}
+		logger.Error("微信登录凭证校验失败", zap.Error(err))
+		return nil, errors.NewInternalServerError("微信服务暂时不可用，请稍后再试")
+	}
+	return session, nil
+}
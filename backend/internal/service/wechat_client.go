@@ -0,0 +1,112 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"gohotel/internal/config"
+	"net/http"
+	"net/url"
+)
+
+// code2SessionURL 微信登录凭证校验接口
+const code2SessionURL = "https://api.weixin.qq.com/sns/jscode2session"
+
+// WechatSession 登录凭证校验结果
+type WechatSession struct {
+	OpenID     string // 用户在小程序下的唯一标识
+	UnionID    string // 用户在开放平台下的唯一标识，小程序未绑定开放平台时为空
+	SessionKey string // 会话密钥（Base64），用于解密开放数据
+}
+
+// WechatClient 微信小程序服务端接口
+// 生产环境使用 APIWechatClient，本地开发和测试使用 StubWechatClient 或自定义实现
+type WechatClient interface {
+	// Code2Session 使用 wx.login 获取的 js_code 换取 openid、unionid 和 session_key
+	Code2Session(jsCode string) (*WechatSession, error)
+}
+
+// NewWechatClient 根据配置创建微信客户端，WECHAT_CLIENT 为 api 时调用微信接口，否则使用本地模拟
+func NewWechatClient(cfg *config.WechatConfig) WechatClient {
+	if cfg.Client == "api" {
+		return NewAPIWechatClient(cfg)
+	}
+	return StubWechatClient{}
+}
+
+// APIWechatClient 调用微信官方接口的客户端
+type APIWechatClient struct {
+	appID     string
+	appSecret string
+	http      *http.Client
+}
+
+// NewAPIWechatClient 创建调用微信官方接口的客户端
+func NewAPIWechatClient(cfg *config.WechatConfig) *APIWechatClient {
+	return &APIWechatClient{
+		appID:     cfg.AppID,
+		appSecret: cfg.AppSecret,
+		http:      &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// code2SessionResponse 微信接口返回结构
+type code2SessionResponse struct {
+	OpenID     string `json:"openid"`
+	UnionID    string `json:"unionid"`
+	SessionKey string `json:"session_key"`
+	ErrCode    int    `json:"errcode"`
+	ErrMsg     string `json:"errmsg"`
+}
+
+// Code2Session 调用 jscode2session 接口
+func (c *APIWechatClient) Code2Session(jsCode string) (*WechatSession, error) {
+	query := url.Values{}
+	query.Set("appid", c.appID)
+	query.Set("secret", c.appSecret)
+	query.Set("js_code", jsCode)
+	query.Set("grant_type", "authorization_code")
+
+	resp, err := c.http.Get(code2SessionURL + "?" + query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("code2session: unexpected status %d", resp.StatusCode)
+	}
+
+	var result code2SessionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if result.ErrCode != 0 || result.OpenID == "" {
+		return nil, &WechatCodeError{Code: result.ErrCode, Message: result.ErrMsg}
+	}
+	return &WechatSession{OpenID: result.OpenID, UnionID: result.UnionID, SessionKey: result.SessionKey}, nil
+}
+
+// WechatCodeError 微信接口返回的业务错误，通常是 js_code 无效或已使用
+type WechatCodeError struct {
+	Code    int
+	Message string
+}
+
+// Error 实现 error 接口
+func (e *WechatCodeError) Error() string {
+	return fmt.Sprintf("code2session: errcode=%d errmsg=%s", e.Code, e.Message)
+}
+
+// StubWechatClient 本地模拟的微信客户端，不访问网络
+// 以 js_code 作为 openid（加 stub_ 前缀），session_key 由 js_code 派生，同一个 js_code 总是对应同一个用户
+type StubWechatClient struct{}
+
+// Code2Session 模拟登录凭证校验
+func (StubWechatClient) Code2Session(jsCode string) (*WechatSession, error) {
+	sum := sha256.Sum256([]byte("stub-session:" + jsCode))
+	return &WechatSession{
+		OpenID:     "stub_" + jsCode,
+		SessionKey: base64.StdEncoding.EncodeToString(sum[:16]),
+	}, nil
+}
@@ -0,0 +1,336 @@
+package service
+
+import (
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/pkg/errors"
+	"gohotel/pkg/utils"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WorkOrderService 维修工单业务逻辑层
+type WorkOrderService struct {
+	workOrderRepo *repository.WorkOrderRepository
+	roomRepo      *repository.RoomRepository
+	bookingRepo   *repository.BookingRepository
+	userRepo      *repository.UserRepository
+	hotelRepo     *repository.HotelRepository
+	cosService    *CosService
+}
+
+// NewWorkOrderService 创建维修工单服务实例
+func NewWorkOrderService(
+	workOrderRepo *repository.WorkOrderRepository,
+	roomRepo *repository.RoomRepository,
+	bookingRepo *repository.BookingRepository,
+	userRepo *repository.UserRepository,
+	hotelRepo *repository.HotelRepository,
+	cosService *CosService,
+) *WorkOrderService {
+	return &WorkOrderService{
+		workOrderRepo: workOrderRepo,
+		roomRepo:      roomRepo,
+		bookingRepo:   bookingRepo,
+		userRepo:      userRepo,
+		hotelRepo:     hotelRepo,
+		cosService:    cosService,
+	}
+}
+
+// CreateWorkOrderRequest 创建工单请求
+type CreateWorkOrderRequest struct {
+	RoomID          uint     `json:"room_id" binding:"required"`
+	Title           string   `json:"title" binding:"required,max=100"`
+	Description     string   `json:"description"`
+	Category        string   `json:"category" binding:"required,oneof=plumbing electrical hvac furniture appliance other"`
+	Priority        string   `json:"priority" binding:"omitempty,oneof=low medium high urgent"`
+	AssigneeID      string   `json:"assignee_id"`        // 维修人员 ID（可选）
+	PhotoTempURLs   []string `json:"photo_temp_urls"`    // 临时图片URL（通过通用上传接口获取，type=workorder）
+	OutOfOrderStart string   `json:"out_of_order_start"` // 停用开始日期（包含），格式: 2024-01-01
+	OutOfOrderEnd   string   `json:"out_of_order_end"`   // 停用结束日期（不包含），格式: 2024-01-05
+}
+
+// UpdateWorkOrderRequest 更新工单请求
+type UpdateWorkOrderRequest struct {
+	Title            string   `json:"title" binding:"omitempty,max=100"`
+	Description      string   `json:"description"`
+	Category         string   `json:"category" binding:"omitempty,oneof=plumbing electrical hvac furniture appliance other"`
+	Priority         string   `json:"priority" binding:"omitempty,oneof=low medium high urgent"`
+	Status           string   `json:"status" binding:"omitempty,oneof=open in_progress resolved cancelled"`
+	AssigneeID       string   `json:"assignee_id"`
+	AddPhotoTempURLs []string `json:"add_photo_temp_urls"` // 新增的临时图片URL
+	RemovePhotoURLs  []string `json:"remove_photo_urls"`   // 要删除的图片URL
+	OutOfOrderStart  *string  `json:"out_of_order_start"`  // 传空字符串表示取消停用时段
+	OutOfOrderEnd    *string  `json:"out_of_order_end"`
+}
+
+// WorkOrderResult 工单创建/更新结果
+// 当停用时段与已有预订重叠时，ConflictingBookings 会列出这些预订供前台处理
+type WorkOrderResult struct {
+	WorkOrder           *models.WorkOrder `json:"work_order"`
+	ConflictingBookings []models.Booking  `json:"conflicting_bookings"`
+	Warning             string            `json:"warning,omitempty"`
+}
+
+// CreateWorkOrder 创建维修工单
+func (s *WorkOrderService) CreateWorkOrder(reporterID int64, hotelID uint, req *CreateWorkOrderRequest) (*WorkOrderResult, error) {
+	// 1. 检查房间是否存在
+	if _, err := findHotelRoom(s.roomRepo, hotelID, req.RoomID); err != nil {
+		return nil, err
+	}
+
+	// 2. 解析停用时段
+	start, end, err := parseOutOfOrderRange(req.OutOfOrderStart, req.OutOfOrderEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	workOrder := &models.WorkOrder{
+		RoomID:          req.RoomID,
+		Title:           req.Title,
+		Description:     req.Description,
+		Category:        req.Category,
+		Priority:        req.Priority,
+		Status:          "open",
+		ReporterID:      utils.JSONInt64(reporterID),
+		OutOfOrderStart: start,
+		OutOfOrderEnd:   end,
+	}
+	if workOrder.Priority == "" {
+		workOrder.Priority = "medium"
+	}
+
+	// 3. 校验维修人员
+	if req.AssigneeID != "" {
+		assigneeID, err := s.parseAssigneeID(hotelID, req.AssigneeID)
+		if err != nil {
+			return nil, err
+		}
+		workOrder.AssigneeID = &assigneeID
+	}
+
+	// 4. 确认上传的照片，后续步骤失败时删除已确认的文件
+	photos, err := confirmTempUploads(s.cosService, req.PhotoTempURLs)
+	if err != nil {
+		return nil, err
+	}
+	if workOrder.Photos, err = encodePhotos(photos); err != nil {
+		deleteCosFiles(s.cosService, photos)
+		return nil, err
+	}
+
+	// 5. 保存工单
+	if err := s.workOrderRepo.Create(workOrder); err != nil {
+		deleteCosFiles(s.cosService, photos)
+		return nil, errors.NewDatabaseError("create work order", err)
+	}
+
+	return s.buildResult(hotelID, workOrder.ID)
+}
+
+// UpdateWorkOrder 更新维修工单
+func (s *WorkOrderService) UpdateWorkOrder(hotelID, id uint, req *UpdateWorkOrderRequest) (*WorkOrderResult, error) {
+	workOrder, err := s.findWorkOrder(hotelID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Title != "" {
+		workOrder.Title = req.Title
+	}
+	if req.Description != "" {
+		workOrder.Description = req.Description
+	}
+	if req.Category != "" {
+		workOrder.Category = req.Category
+	}
+	if req.Priority != "" {
+		workOrder.Priority = req.Priority
+	}
+	if req.AssigneeID != "" {
+		assigneeID, err := s.parseAssigneeID(hotelID, req.AssigneeID)
+		if err != nil {
+			return nil, err
+		}
+		workOrder.AssigneeID = &assigneeID
+	}
+	if req.Status != "" && req.Status != workOrder.Status {
+		workOrder.Status = req.Status
+		if req.Status == "resolved" {
+			now := time.Now()
+			workOrder.ResolvedAt = &now
+		} else {
+			workOrder.ResolvedAt = nil
+		}
+	}
+
+	// 更新停用时段（两个字段需同时提供）
+	if req.OutOfOrderStart != nil || req.OutOfOrderEnd != nil {
+		var startStr, endStr string
+		if req.OutOfOrderStart != nil {
+			startStr = *req.OutOfOrderStart
+		}
+		if req.OutOfOrderEnd != nil {
+			endStr = *req.OutOfOrderEnd
+		}
+		start, end, err := parseOutOfOrderRange(startStr, endStr)
+		if err != nil {
+			return nil, err
+		}
+		workOrder.OutOfOrderStart = start
+		workOrder.OutOfOrderEnd = end
+	}
+
+	// 更新照片：先移除，再追加；保存失败时删除新确认的文件
+	photos, removed := splitPhotos(decodePhotos(workOrder.Photos), req.RemovePhotoURLs)
+	added, err := confirmTempUploads(s.cosService, req.AddPhotoTempURLs)
+	if err != nil {
+		return nil, err
+	}
+	photos = append(photos, added...)
+	if workOrder.Photos, err = encodePhotos(photos); err != nil {
+		deleteCosFiles(s.cosService, added)
+		return nil, err
+	}
+
+	if err := s.workOrderRepo.Update(workOrder); err != nil {
+		deleteCosFiles(s.cosService, added)
+		return nil, errors.NewDatabaseError("update work order", err)
+	}
+
+	// 工单保存成功后再删除移除的照片文件
+	deleteCosFiles(s.cosService, removed)
+
+	return s.buildResult(hotelID, id)
+}
+
+// GetWorkOrder 获取工单详情（包含与停用时段冲突的预订）
+func (s *WorkOrderService) GetWorkOrder(hotelID, id uint) (*WorkOrderResult, error) {
+	return s.buildResult(hotelID, id)
+}
+
+// ListWorkOrders 查询工单列表
+func (s *WorkOrderService) ListWorkOrders(hotelID uint, page, pageSize int, status, priority, category string, roomID uint) ([]models.WorkOrder, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	workOrders, total, err := s.workOrderRepo.FindAll(hotelID, page, pageSize, status, priority, category, roomID)
+	if err != nil {
+		return nil, 0, errors.NewDatabaseError("list work orders", err)
+	}
+	return workOrders, total, nil
+}
+
+// buildResult 查询工单并附带与停用时段重叠的预订
+func (s *WorkOrderService) buildResult(hotelID, id uint) (*WorkOrderResult, error) {
+	workOrder, err := s.findWorkOrder(hotelID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &WorkOrderResult{
+		WorkOrder:           workOrder,
+		ConflictingBookings: make([]models.Booking, 0),
+	}
+	if !workOrder.IsActive() || !workOrder.HasOutOfOrder() {
+		return result, nil
+	}
+
+	bookings, err := s.bookingRepo.FindActiveByRoomsAndDateRange(
+		[]int64{int64(workOrder.RoomID)}, *workOrder.OutOfOrderStart, *workOrder.OutOfOrderEnd)
+	if err != nil {
+		return nil, errors.NewDatabaseError("find conflicting bookings", err)
+	}
+	if len(bookings) > 0 {
+		result.ConflictingBookings = bookings
+		result.Warning = "停用时段与 " + strconv.Itoa(len(bookings)) + " 个已有预订重叠，请为客人调换房间"
+	}
+
+	return result, nil
+}
+
+// findWorkOrder 查找酒店内的工单，工单房间属于其他酒店时视为不存在
+func (s *WorkOrderService) findWorkOrder(hotelID, id uint) (*models.WorkOrder, error) {
+	workOrder, err := s.workOrderRepo.FindByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("工单不存在")
+		}
+		return nil, errors.NewDatabaseError("find work order", err)
+	}
+	if workOrder.Room.HotelID != hotelID {
+		return nil, errors.NewNotFoundError("工单不存在")
+	}
+	return workOrder, nil
+}
+
+// parseAssigneeID 解析并校验维修人员 ID，维修人员必须已分配到该酒店
+func (s *WorkOrderService) parseAssigneeID(hotelID uint, idStr string) (utils.JSONInt64, error) {
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return 0, errors.NewBadRequestError("无效的维修人员ID")
+	}
+	if _, err := s.userRepo.FindByID(id); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, errors.NewNotFoundError("维修人员不存在")
+		}
+		return 0, errors.NewDatabaseError("find user", err)
+	}
+	ok, err := isHotelStaff(s.hotelRepo, hotelID, id)
+	if err != nil {
+		return 0, errors.NewDatabaseError("find staff hotels", err)
+	}
+	if !ok {
+		return 0, errors.NewNotFoundError("该维修人员未分配到此酒店")
+	}
+	return utils.JSONInt64(id), nil
+}
+
+// splitPhotos 将照片列表按要移除的 URL 拆分为保留和移除两部分
+func splitPhotos(photos []string, removeURLs []string) ([]string, []string) {
+	toRemove := make(map[string]bool, len(removeURLs))
+	for _, u := range removeURLs {
+		toRemove[u] = true
+	}
+
+	kept := make([]string, 0, len(photos))
+	var removed []string
+	for _, photo := range photos {
+		if toRemove[photo] {
+			removed = append(removed, photo)
+		} else {
+			kept = append(kept, photo)
+		}
+	}
+	return kept, removed
+}
+
+// parseOutOfOrderRange 解析停用时段，两个日期都为空表示不设置停用时段
+func parseOutOfOrderRange(startStr, endStr string) (*time.Time, *time.Time, error) {
+	if startStr == "" && endStr == "" {
+		return nil, nil, nil
+	}
+	if startStr == "" || endStr == "" {
+		return nil, nil, errors.NewBadRequestError("停用开始日期和结束日期需同时提供")
+	}
+
+	start, err := time.Parse("2006-01-02", startStr)
+	if err != nil {
+		return nil, nil, errors.NewBadRequestError("停用开始日期格式错误，应为: YYYY-MM-DD")
+	}
+	end, err := time.Parse("2006-01-02", endStr)
+	if err != nil {
+		return nil, nil, errors.NewBadRequestError("停用结束日期格式错误，应为: YYYY-MM-DD")
+	}
+	if !end.After(start) {
+		return nil, nil, errors.NewBadRequestError("停用结束日期必须晚于开始日期")
+	}
+	return &start, &end, nil
+}
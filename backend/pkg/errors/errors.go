@@ -99,6 +99,15 @@ func NewInternalServerError(message string) AppError {
 	}
 }
 
+// NewTooManyRequestsError 创建 429 错误（请求过于频繁）
+func NewTooManyRequestsError(message string) AppError {
+	return &baseError{
+		statusCode:   http.StatusTooManyRequests, // 429
+		errorCode:    "TOO_MANY_REQUESTS",
+		errorMessage: message,
+	}
+}
+
 // ========== 特定业务错误 ==========
 
 // NewValidationError 创建验证错误
@@ -119,6 +128,42 @@ func NewDatabaseError(operation string, err error) AppError {
 	}
 }
 
+// NewCaptchaRequiredError 创建需要验证码的错误，前端据此显示图形验证码
+func NewCaptchaRequiredError(message string) AppError {
+	return &baseError{
+		statusCode:   http.StatusBadRequest,
+		errorCode:    "CAPTCHA_REQUIRED",
+		errorMessage: message,
+	}
+}
+
+// NewCaptchaInvalidError 创建验证码错误，前端据此刷新图形验证码
+func NewCaptchaInvalidError(message string) AppError {
+	return &baseError{
+		statusCode:   http.StatusBadRequest,
+		errorCode:    "CAPTCHA_INVALID",
+		errorMessage: message,
+	}
+}
+
+// NewAccountLockedError 创建账号临时锁定的错误（423）
+func NewAccountLockedError(message string) AppError {
+	return &baseError{
+		statusCode:   http.StatusLocked, // 423
+		errorCode:    "ACCOUNT_LOCKED",
+		errorMessage: message,
+	}
+}
+
+// NewPasswordChangeRequiredError 创建需要修改密码的错误，前端据此跳转到修改密码页面
+func NewPasswordChangeRequiredError(message string) AppError {
+	return &baseError{
+		statusCode:   http.StatusForbidden,
+		errorCode:    "PASSWORD_CHANGE_REQUIRED",
+		errorMessage: message,
+	}
+}
+
 // ErrorResponse Swagger 错误响应结构
 type ErrorResponse struct {
 	Success bool      `json:"success" example:"false"`
@@ -10,24 +10,30 @@ import (
 
 // Claims JWT 声明结构
 type Claims struct {
-	UserID   int64  `json:"user_id"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	UserID    int64  `json:"user_id"`
+	SessionID int64  `json:"sid"` // 登录会话 ID，会话撤销后令牌失效
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	// PasswordChangeRequired 首次登录需要修改密码，修改前只能访问个人资料、修改密码和注销接口
+	PasswordChangeRequired bool `json:"pcr,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken 生成 JWT 令牌
-// 参数：用户ID、用户名、角色
+// GenerateToken 生成 JWT 访问令牌
+// 参数：用户ID、登录会话ID、用户名、角色、是否需要修改密码
 // 返回：令牌字符串、错误
-func GenerateToken(userID int64, username string, role string) (string, error) {
+func GenerateToken(userID int64, sessionID int64, username string, role string, passwordChangeRequired bool) (string, error) {
 	// 设置过期时间
 	expirationTime := time.Now().Add(config.AppConfig.JWT.ExpireTime)
 
 	// 创建声明
 	claims := &Claims{
-		UserID:   userID,
-		Username: username,
-		Role:     role,
+		UserID:    userID,
+		SessionID: sessionID,
+		Username:  username,
+		Role:      role,
+
+		PasswordChangeRequired: passwordChangeRequired,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"encoding/base64"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// QRCodeDataURL 将文本编码为二维码 PNG 图片（data URL），size 为图片边长（像素）
+func QRCodeDataURL(content string, size int) (string, error) {
+	img, err := qrcode.Encode(content, qrcode.Medium, size)
+	if err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(img), nil
+}
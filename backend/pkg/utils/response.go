@@ -81,28 +81,45 @@ type PageResponse struct {
 	Page    PageInfo    `json:"page"`
 }
 
+// FacetPageResponse 带分面统计的分页响应
+type FacetPageResponse struct {
+	Success bool        `json:"success"`
+	Data    interface{} `json:"data"`
+	Page    PageInfo    `json:"page"`
+	Facets  interface{} `json:"facets"`
+}
+
 // SuccessWithPage 返回分页响应
 func SuccessWithPage(c *gin.Context, data interface{}, page int, pageSize int, total int64) {
-	totalPages := int(total) / pageSize
-	if int(total)%pageSize != 0 {
-		totalPages++
-	}
-
 	c.JSON(200, PageResponse{
 		Success: true,
 		Data:    data,
-		Page: PageInfo{
-			Page:       page,
-			PageSize:   pageSize,
-			Total:      total,
-			TotalPages: totalPages,
-		},
+		Page:    newPageInfo(page, pageSize, total),
 	})
 }
 
+// SuccessWithPageAndFacets 返回带分面统计的分页响应
+// 在分页响应的基础上增加 facets 字段，兼容原有分页结构
+func SuccessWithPageAndFacets(c *gin.Context, data interface{}, page int, pageSize int, total int64, facets interface{}) {
+	c.JSON(200, FacetPageResponse{
+		Success: true,
+		Data:    data,
+		Page:    newPageInfo(page, pageSize, total),
+		Facets:  facets,
+	})
+}
 
+// newPageInfo 计算分页信息
+func newPageInfo(page int, pageSize int, total int64) PageInfo {
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize != 0 {
+		totalPages++
+	}
 
-
-
-
-
+	return PageInfo{
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+}
@@ -0,0 +1,372 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// 表格读取限制
+const (
+	xlsxMaxPartSize = 20 << 20 // XLSX 内单个 XML 文件解压后最大 20MB
+	xlsxMaxRows     = 1048576  // Excel 工作表最大行数
+	xlsxMaxColumns  = 16384    // Excel 工作表最大列数（XFD）
+)
+
+// ErrTooManyRows 表格的非空行数超过读取上限
+var ErrTooManyRows = errors.New("表格行数超过上限")
+
+// ReadSpreadsheet 读取 CSV 或 XLSX 表格，按扩展名识别格式
+// 返回所有行的单元格文本；XLSX 只读取第一个工作表。
+// maxRows 大于 0 时最多读取 maxRows 个非空行，超出时停止解析并返回 ErrTooManyRows
+func ReadSpreadsheet(filename string, r io.Reader, maxRows int) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return ReadCSV(r, maxRows)
+	case ".xlsx":
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return ReadXLSX(data, maxRows)
+	default:
+		return nil, fmt.Errorf("不支持的文件格式，仅支持 .csv 和 .xlsx")
+	}
+}
+
+// ReadCSV 读取 CSV 表格，兼容 Excel 导出时带的 UTF-8 BOM
+// maxRows 的含义同 ReadSpreadsheet（CSV 中的空行会被跳过）
+func ReadCSV(r io.Reader, maxRows int) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var rows [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if maxRows > 0 && len(rows) >= maxRows {
+			return nil, ErrTooManyRows
+		}
+		rows = append(rows, record)
+	}
+}
+
+// WriteCSV 生成 CSV 表格，带 UTF-8 BOM 以便 Excel 正确显示中文
+func WriteCSV(rows [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\xef\xbb\xbf")
+	writer := csv.NewWriter(&buf)
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// xlsx 文件内部的 XML 结构（只包含读取单元格文本需要的部分）
+type (
+	xlsxWorkbook struct {
+		Sheets []struct {
+			RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	xlsxRelationships struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	xlsxSharedStrings struct {
+		Items []xlsxRichText `xml:"si"`
+	}
+	xlsxRichText struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	}
+	xlsxRow struct {
+		Index int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string       `xml:"r,attr"`
+			Type   string       `xml:"t,attr"`
+			Value  string       `xml:"v"`
+			Inline xlsxRichText `xml:"is"`
+		} `xml:"c"`
+	}
+)
+
+// String 返回富文本的纯文本内容
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.Text)
+	}
+	return b.String()
+}
+
+// ReadXLSX 读取 XLSX 表格第一个工作表的单元格文本
+// 数字单元格按最短形式格式化（如 101、99.9），空行和空单元格保留位置；
+// 工作表逐行解析，maxRows 的含义同 ReadSpreadsheet
+func ReadXLSX(data []byte, maxRows int) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("无法解析 XLSX 文件: %w", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeZipXML(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	sheetFile, ok := files[firstSheetPath(files)]
+	if !ok {
+		return nil, fmt.Errorf("XLSX 文件中没有工作表")
+	}
+	rc, limited, err := openZipXML(sheetFile)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	decoder := xml.NewDecoder(limited)
+
+	var rows [][]string
+	nonBlank := 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, zipXMLError(sheetFile, limited, err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+		var row xlsxRow
+		if err := decoder.DecodeElement(&row, &start); err != nil {
+			return nil, zipXMLError(sheetFile, limited, err)
+		}
+		if len(row.Cells) > 0 {
+			if maxRows > 0 && nonBlank >= maxRows {
+				return nil, ErrTooManyRows
+			}
+			nonBlank++
+		}
+
+		index := row.Index - 1
+		if index < len(rows) {
+			index = len(rows)
+		}
+		if index >= xlsxMaxRows {
+			return nil, fmt.Errorf("行号 %d 超出工作表范围", row.Index)
+		}
+		for len(rows) < index {
+			rows = append(rows, nil)
+		}
+
+		var cells []string
+		for _, c := range row.Cells {
+			col := len(cells)
+			if c.Ref != "" {
+				col = xlsxColumnIndex(c.Ref)
+			}
+			if col < 0 || col >= xlsxMaxColumns {
+				return nil, fmt.Errorf("单元格 %s 超出工作表范围", c.Ref)
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+
+			switch c.Type {
+			case "s":
+				i, err := strconv.Atoi(c.Value)
+				if err != nil || i < 0 || i >= len(shared.Items) {
+					return nil, fmt.Errorf("单元格 %s 引用了无效的共享字符串", c.Ref)
+				}
+				cells[col] = shared.Items[i].String()
+			case "inlineStr":
+				cells[col] = c.Inline.String()
+			case "", "n":
+				if f, err := strconv.ParseFloat(c.Value, 64); err == nil {
+					cells[col] = strconv.FormatFloat(f, 'f', -1, 64)
+				} else {
+					cells[col] = c.Value
+				}
+			default:
+				cells[col] = c.Value
+			}
+		}
+		rows = append(rows, cells)
+	}
+	return rows, nil
+}
+
+// firstSheetPath 根据 workbook.xml 找到第一个工作表的路径
+func firstSheetPath(files map[string]*zip.File) string {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	var workbook xlsxWorkbook
+	var rels xlsxRelationships
+	wf, ok1 := files["xl/workbook.xml"]
+	rf, ok2 := files["xl/_rels/workbook.xml.rels"]
+	if !ok1 || !ok2 || decodeZipXML(wf, &workbook) != nil || decodeZipXML(rf, &rels) != nil || len(workbook.Sheets) == 0 {
+		return fallback
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].RID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+		return path.Join("xl", rel.Target)
+	}
+	return fallback
+}
+
+// xlsxColumnIndex 将单元格引用（如 C12）转换为从 0 开始的列号
+// 列名超过工作表最大列数时返回 xlsxMaxColumns
+func xlsxColumnIndex(ref string) int {
+	col := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+		if col > xlsxMaxColumns {
+			return xlsxMaxColumns
+		}
+	}
+	return col - 1
+}
+
+// xlsxColumnName 将从 0 开始的列号转换为列名（如 0 -> A，27 -> AB）
+func xlsxColumnName(col int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name
+}
+
+// decodeZipXML 解析压缩包内的 XML 文件
+func decodeZipXML(f *zip.File, v interface{}) error {
+	rc, limited, err := openZipXML(f)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(limited).Decode(v); err != nil {
+		return zipXMLError(f, limited, err)
+	}
+	return nil
+}
+
+// openZipXML 打开压缩包内的 XML 文件，解压后的大小不能超过 xlsxMaxPartSize
+// 文件头中声明的大小可能被伪造，读取时再用 LimitedReader 限制实际读取的字节数
+func openZipXML(f *zip.File) (io.ReadCloser, *io.LimitedReader, error) {
+	if f.UncompressedSize64 > xlsxMaxPartSize {
+		return nil, nil, fmt.Errorf("%s 解压后超过 %dMB", f.Name, xlsxMaxPartSize>>20)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, nil, fmt.Errorf("无法读取 %s: %w", f.Name, err)
+	}
+	return rc, &io.LimitedReader{R: rc, N: xlsxMaxPartSize}, nil
+}
+
+// zipXMLError 生成解析压缩包内 XML 文件失败的错误，读满限制时提示文件过大
+func zipXMLError(f *zip.File, limited *io.LimitedReader, err error) error {
+	if limited.N <= 0 {
+		return fmt.Errorf("%s 解压后超过 %dMB", f.Name, xlsxMaxPartSize>>20)
+	}
+	return fmt.Errorf("无法解析 %s: %w", f.Name, err)
+}
+
+// WriteXLSX 生成只有一个工作表的 XLSX 表格，所有单元格按文本写入
+func WriteXLSX(sheetName string, rows [][]string) ([]byte, error) {
+	var sheet strings.Builder
+	sheet.WriteString(xml.Header)
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range rows {
+		fmt.Fprintf(&sheet, `<row r="%d">`, i+1)
+		for j, cell := range row {
+			fmt.Fprintf(&sheet, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">`, xlsxColumnName(j), i+1)
+			if err := xml.EscapeText(&sheet, []byte(cell)); err != nil {
+				return nil, err
+			}
+			sheet.WriteString(`</t></is></c>`)
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	var name strings.Builder
+	if err := xml.EscapeText(&name, []byte(sheetName)); err != nil {
+		return nil, err
+	}
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="` + name.String() + `" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`},
+		{"xl/worksheets/sheet1.xml", sheet.String()},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, p := range parts {
+		w, err := zw.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(w, p.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,64 @@
+package utils
+
+import "strings"
+
+// uaRule User-Agent 识别规则，按顺序匹配第一个包含关键字的规则
+type uaRule struct {
+	keyword string
+	name    string
+}
+
+// 浏览器识别规则，Edge、Opera 和微信的 User-Agent 中也包含 Chrome/Safari，需要排在前面
+var browserRules = []uaRule{
+	{"miniProgram", "微信小程序"},
+	{"MicroMessenger", "微信"},
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"Chrome/", "Chrome"},
+	{"CriOS/", "Chrome"},
+	{"Safari/", "Safari"},
+}
+
+// 操作系统识别规则，iPhone/iPad 的 User-Agent 中也包含 Mac OS X，需要排在前面
+var osRules = []uaRule{
+	{"iPhone", "iPhone"},
+	{"iPad", "iPad"},
+	{"Android", "Android"},
+	{"Windows", "Windows"},
+	{"Mac OS X", "macOS"},
+	{"Macintosh", "macOS"},
+	{"Linux", "Linux"},
+}
+
+// ParseDevice 根据 User-Agent 生成简短的设备描述，例如 "Chrome (Windows)"、"微信小程序 (iPhone)"
+// 无法识别浏览器时使用 User-Agent 的产品名（如 curl、PostmanRuntime）
+func ParseDevice(userAgent string) string {
+	userAgent = strings.TrimSpace(userAgent)
+	if userAgent == "" {
+		return "未知设备"
+	}
+
+	browser := matchUARule(userAgent, browserRules)
+	os := matchUARule(userAgent, osRules)
+	if browser == "" {
+		browser = strings.SplitN(strings.Fields(userAgent)[0], "/", 2)[0]
+		if len(browser) > 50 {
+			browser = browser[:50]
+		}
+	}
+	if os == "" {
+		return browser
+	}
+	return browser + " (" + os + ")"
+}
+
+// matchUARule 返回第一个匹配规则的名称，没有匹配时返回空字符串
+func matchUARule(userAgent string, rules []uaRule) string {
+	for _, rule := range rules {
+		if strings.Contains(userAgent, rule.keyword) {
+			return rule.name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,183 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"gohotel/internal/database"
+	"gohotel/internal/handler"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/internal/service"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupAmenityRouter 初始化内存数据库并配置设施目录和房间路由
+//
+// 房间 1、2 属于酒店 1（房间 2 已入住），房间 3 属于酒店 2
+func setupAmenityRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法连接到内存数据库: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Room{}, &models.RoomPhoto{}, &models.Amenity{}, &models.DataMigration{}); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+
+	rooms := []models.Room{
+		{ID: 1, HotelID: 1, RoomNumber: "101", Status: "available"},
+		{ID: 2, HotelID: 1, RoomNumber: "102", Status: "occupied"},
+		{ID: 3, HotelID: 2, RoomNumber: "201", Status: "available"},
+	}
+	for i := range rooms {
+		rooms[i].RoomType = "标准间"
+		rooms[i].Floor = 1
+		rooms[i].Price = 200
+		rooms[i].Capacity = 2
+	}
+	assert.NoError(t, db.Create(&rooms).Error)
+
+	roomRepo := repository.NewRoomRepository(db)
+	amenityHandler := handler.NewAmenityHandler(service.NewAmenityService(repository.NewAmenityRepository(db), roomRepo))
+	roomHandler := handler.NewRoomHandler(service.NewRoomService(roomRepo, nil, nil))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("hotel_id", uint(1))
+		c.Next()
+	})
+	router.GET("/api/amenities", amenityHandler.ListAmenities)
+	router.GET("/api/rooms", roomHandler.ListRooms)
+	router.GET("/api/rooms/available", roomHandler.ListAvailableRooms)
+	router.POST("/api/admin/amenities", amenityHandler.CreateAmenity)
+	router.POST("/api/admin/amenities/:id", amenityHandler.UpdateAmenity)
+	router.POST("/api/admin/amenities/:id/delete", amenityHandler.DeleteAmenity)
+	router.POST("/api/admin/rooms/:id/amenities", amenityHandler.SetRoomAmenities)
+	router.POST("/api/admin/rooms/:id", roomHandler.UpdateRoom)
+	return router, db
+}
+
+// createAmenity 创建设施并返回其 ID
+func createAmenity(t *testing.T, router *gin.Engine, name, category string) uint {
+	w := housekeepingRequest(router, "POST", "/api/admin/amenities", 1, map[string]string{"name": name, "category": category})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data models.Amenity `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp.Data.ID
+}
+
+// listRoomsWithFacets 查询房间列表，返回房间号和设施分面
+func listRoomsWithFacets(t *testing.T, router *gin.Engine, url string) ([]string, []models.AmenityFacet) {
+	w := housekeepingRequest(router, "GET", url, 1, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data   []models.Room         `json:"data"`
+		Facets []models.AmenityFacet `json:"facets"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	numbers := make([]string, len(resp.Data))
+	for i, room := range resp.Data {
+		numbers[i] = room.RoomNumber
+	}
+	return numbers, resp.Facets
+}
+
+// roomAmenityNames 读取房间关联的设施名称
+func roomAmenityNames(t *testing.T, db *gorm.DB, roomID uint) []string {
+	var room models.Room
+	assert.NoError(t, db.Preload("Amenities").First(&room, roomID).Error)
+	names := make([]string, len(room.Amenities))
+	for i, amenity := range room.Amenities {
+		names[i] = amenity.Name
+	}
+	return names
+}
+
+func TestAmenity_CatalogCRUD(t *testing.T) {
+	router, _ := setupAmenityRouter(t)
+
+	wifi := createAmenity(t, router, "WiFi", "网络")
+	createAmenity(t, router, "浴缸", "卫浴")
+	w := housekeepingRequest(router, "POST", "/api/admin/amenities", 1, map[string]string{"name": "WiFi"})
+	assert.Equal(t, http.StatusConflict, w.Code, "设施名称不能重复")
+
+	w = housekeepingRequest(router, "GET", "/api/amenities?category=网络", 1, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "WiFi")
+	assert.NotContains(t, w.Body.String(), "浴缸")
+
+	w = housekeepingRequest(router, "POST", "/api/admin/amenities/"+fmt.Sprint(wifi), 1, map[string]string{"name": "无线网络"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "无线网络")
+
+	assert.Equal(t, http.StatusOK, housekeepingRequest(router, "POST", "/api/admin/amenities/"+fmt.Sprint(wifi)+"/delete", 1, nil).Code)
+	assert.Equal(t, http.StatusNotFound, housekeepingRequest(router, "POST", "/api/admin/amenities/"+fmt.Sprint(wifi)+"/delete", 1, nil).Code)
+}
+
+func TestAmenity_RoomAmenitiesAndFacets(t *testing.T) {
+	router, db := setupAmenityRouter(t)
+	wifi := createAmenity(t, router, "WiFi", "网络")
+	bathtub := createAmenity(t, router, "浴缸", "卫浴")
+
+	w := housekeepingRequest(router, "POST", "/api/admin/rooms/1/amenities", 1, map[string][]uint{"amenity_ids": {wifi, bathtub}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = housekeepingRequest(router, "POST", "/api/admin/rooms/2/amenities", 1, map[string][]uint{"amenity_ids": {wifi}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.ElementsMatch(t, []string{"WiFi", "浴缸"}, roomAmenityNames(t, db, 1))
+
+	w = housekeepingRequest(router, "POST", "/api/admin/rooms/1/amenities", 1, map[string][]uint{"amenity_ids": {999}})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "无效的设施ID")
+	w = housekeepingRequest(router, "POST", "/api/admin/rooms/3/amenities", 1, map[string][]uint{"amenity_ids": {wifi}})
+	assert.Equal(t, http.StatusNotFound, w.Code, "不能设置其他酒店的房间")
+
+	// 需同时具备所有筛选设施，分面统计在筛选结果内计数
+	numbers, facets := listRoomsWithFacets(t, router, "/api/rooms?amenity_ids="+fmt.Sprint(wifi))
+	assert.ElementsMatch(t, []string{"101", "102"}, numbers)
+	counts := map[uint]int64{}
+	for _, facet := range facets {
+		counts[facet.AmenityID] = facet.Count
+	}
+	assert.Equal(t, map[uint]int64{wifi: 2, bathtub: 1}, counts)
+
+	numbers, _ = listRoomsWithFacets(t, router, "/api/rooms?amenity_ids="+fmt.Sprint(wifi)+","+fmt.Sprint(bathtub))
+	assert.Equal(t, []string{"101"}, numbers)
+	numbers, _ = listRoomsWithFacets(t, router, "/api/rooms/available?amenity_ids="+fmt.Sprint(wifi))
+	assert.Equal(t, []string{"101"}, numbers, "已入住的房间不在可用列表中")
+	assert.Equal(t, http.StatusBadRequest, housekeepingRequest(router, "GET", "/api/rooms?amenity_ids=abc", 1, nil).Code)
+
+	// 更新房间的其他字段不影响设施
+	w = housekeepingRequest(router, "POST", "/api/admin/rooms/1", 1, map[string]string{"description": "朝南", "facilities": `["电视"]`})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.ElementsMatch(t, []string{"WiFi", "浴缸"}, roomAmenityNames(t, db, 1))
+
+	// 空列表清空设施
+	w = housekeepingRequest(router, "POST", "/api/admin/rooms/1/amenities", 1, map[string][]uint{"amenity_ids": {}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, roomAmenityNames(t, db, 1))
+}
+
+func TestMigrateRoomAmenities_RunsOnce(t *testing.T) {
+	_, db := setupAmenityRouter(t)
+	database.DB = db
+	assert.NoError(t, db.Model(&models.Room{}).Where("id = ?", 1).Update("facilities", `["WiFi", "空调"]`).Error)
+	assert.NoError(t, db.Model(&models.Room{}).Where("id = ?", 2).Update("facilities", "WiFi，电视").Error)
+
+	assert.NoError(t, database.MigrateRoomAmenities())
+	assert.ElementsMatch(t, []string{"WiFi", "空调"}, roomAmenityNames(t, db, 1))
+	assert.ElementsMatch(t, []string{"WiFi", "电视"}, roomAmenityNames(t, db, 2))
+
+	// 管理员清空设施后重启，旧字段不会把设施恢复回来
+	var room models.Room
+	assert.NoError(t, db.First(&room, 1).Error)
+	assert.NoError(t, db.Model(&room).Association("Amenities").Clear())
+	assert.NoError(t, database.MigrateRoomAmenities())
+	assert.Empty(t, roomAmenityNames(t, db, 1))
+}
@@ -0,0 +1,385 @@
+package test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"gohotel/internal/config"
+	"gohotel/internal/handler"
+	"gohotel/internal/middleware"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/internal/service"
+	"gohotel/pkg/logger"
+	"gohotel/pkg/utils"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// authTestPassword 测试用户的密码
+const authTestPassword = "secret123"
+
+// recordingCaptchaStore 记录生成的验证码答案，便于测试填写
+type recordingCaptchaStore struct {
+	*service.MemoryCaptchaStore
+	answers map[string]string
+}
+
+// Set 保存答案并记录
+func (s *recordingCaptchaStore) Set(id, answer string, ttl time.Duration) bool {
+	s.answers[id] = answer
+	return s.MemoryCaptchaStore.Set(id, answer, ttl)
+}
+
+// recordingSender 记录发出的短信和邮件，便于测试读取验证码
+type recordingSender struct {
+	messages map[string][]string
+}
+
+// SendSMS 记录短信
+func (s *recordingSender) SendSMS(phone, message string) error {
+	s.messages[phone] = append(s.messages[phone], message)
+	return nil
+}
+
+// SendEmail 记录邮件
+func (s *recordingSender) SendEmail(to, subject, body string) error {
+	s.messages[to] = append(s.messages[to], body)
+	return nil
+}
+
+// lastCode 读取最近一条发给目标的消息中的 6 位验证码
+func (s *recordingSender) lastCode(target string) string {
+	messages := s.messages[target]
+	if len(messages) == 0 {
+		return ""
+	}
+	return regexp.MustCompile(`\d{6}`).FindString(messages[len(messages)-1])
+}
+
+// lastLinkToken 读取最近一条发给目标的消息中设置密码链接的令牌
+func (s *recordingSender) lastLinkToken(target string) string {
+	messages := s.messages[target]
+	if len(messages) == 0 {
+		return ""
+	}
+	match := regexp.MustCompile(`token=([0-9a-f]{64})`).FindStringSubmatch(messages[len(messages)-1])
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// authCaptchaThreshold 测试中登录失败多少次后需要验证码
+const authCaptchaThreshold = 2
+
+// authTestEnv 认证测试环境
+type authTestEnv struct {
+	router   *gin.Engine
+	db       *gorm.DB
+	captchas *recordingCaptchaStore
+	outbox   *recordingSender
+	tokens   *service.TokenService
+	mfa      *service.MFAService
+}
+
+// setupAuthRouter 初始化内存数据库和认证相关路由，创建一个测试用户
+func setupAuthRouter(t *testing.T) *authTestEnv {
+	assert.NoError(t, utils.InitSnowflake(1))
+	logger.Log = zap.NewNop()
+	config.AppConfig = &config.Config{JWT: config.JWTConfig{
+		Secret:            "test-secret",
+		ExpireTime:        15 * time.Minute,
+		RefreshExpireTime: time.Hour,
+	}}
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法连接到内存数据库: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.AuthSession{}, &models.RefreshToken{}, &models.VerificationCode{}, &models.PasswordResetToken{},
+		&models.LoginLockout{}, &models.Log{}, &models.Role{}, &models.RolePermission{}, &models.UserRole{},
+		&models.UserTOTP{}, &models.MFARecoveryCode{}, &models.MFAChallenge{}); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+
+	hashed, err := utils.HashPassword(authTestPassword)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Create(&models.User{
+		ID: 1, Username: "alice", Email: "alice@example.com", Password: hashed, Status: "active",
+	}).Error)
+
+	userRepo := repository.NewUserRepository(db)
+	tokenService := service.NewTokenService(repository.NewAuthSessionRepository(db), userRepo,
+		utils.NewMultiTimeWheel(), &config.AppConfig.JWT)
+	captchaStore := &recordingCaptchaStore{MemoryCaptchaStore: service.NewMemoryCaptchaStore(0), answers: map[string]string{}}
+	captchaService := service.NewCaptchaService(captchaStore, &config.CaptchaConfig{
+		Length: 4, TTL: time.Minute, LoginThreshold: authCaptchaThreshold,
+	})
+	outbox := &recordingSender{messages: map[string][]string{}}
+	verificationService := service.NewVerificationService(repository.NewVerificationCodeRepository(db), userRepo,
+		outbox, outbox, utils.NewMultiTimeWheel(), &config.VerificationConfig{
+			TTL: 5 * time.Minute, ResendInterval: time.Minute, TargetDaily: 3, IPHourly: 5, MaxAttempts: 3,
+		})
+	passwordService := service.NewPasswordResetService(repository.NewPasswordResetRepository(db), userRepo, tokenService,
+		outbox, outbox, utils.NewMultiTimeWheel(), &config.PasswordConfig{
+			ResetTTL: 30 * time.Minute, InviteTTL: 72 * time.Hour, ResendInterval: time.Minute, ResetURL: "http://hotel.test/reset",
+		})
+	loginThrottle := service.NewLoginThrottleService(repository.NewLoginLockoutRepository(db), userRepo,
+		repository.NewRoleRepository(db), outbox, &config.LoginConfig{
+			MaxFailures: 3, FailWindow: time.Minute, LockBase: time.Minute, LockMax: 4 * time.Minute,
+			IPMaxFailures: 10, IPLockDuration: time.Minute,
+		})
+	mfaService := service.NewMFAService(repository.NewMFARepository(db), userRepo, repository.NewRoleRepository(db), tokenService,
+		utils.NewMultiTimeWheel(), &config.MFAConfig{
+			Issuer: "GoHotel", ChallengeTTL: 5 * time.Minute, MaxAttempts: 3, RecoveryCodes: 4,
+		})
+	userHandler := handler.NewUserHandler(service.NewUserService(userRepo, tokenService, captchaService, verificationService, passwordService, loginThrottle, mfaService,
+		repository.NewBookingRepository(db), repository.NewRoleRepository(db)), tokenService)
+	loginThrottleHandler := handler.NewLoginThrottleHandler(loginThrottle)
+	passwordHandler := handler.NewPasswordHandler(passwordService)
+	captchaHandler := handler.NewCaptchaHandler(captchaService)
+	verificationHandler := handler.NewVerificationHandler(verificationService)
+	authRequired := middleware.AuthMiddleware(tokenService)
+	passwordChangeAuth := middleware.PasswordChangeAuthMiddleware(tokenService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/auth/captcha", captchaHandler.GetCaptcha)
+	router.POST("/api/auth/register", userHandler.Register)
+	router.POST("/api/auth/verification-codes", verificationHandler.SendCode)
+	router.POST("/api/auth/login", userHandler.Login)
+	router.POST("/api/auth/login/code", userHandler.LoginWithCode)
+	router.POST("/api/auth/refresh", userHandler.Refresh)
+	router.POST("/api/auth/logout", passwordChangeAuth, userHandler.Logout)
+	router.POST("/api/auth/password/forgot", passwordHandler.ForgotPassword)
+	router.POST("/api/auth/password/reset", passwordHandler.ResetPassword)
+	router.GET("/api/users/profile", passwordChangeAuth, userHandler.GetProfile)
+	router.POST("/api/users/profile", authRequired, userHandler.UpdateProfile)
+	router.POST("/api/users/password", passwordChangeAuth, userHandler.ChangePassword)
+	router.POST("/api/admin/users/user", userHandler.AddUser)
+	router.GET("/api/admin/users/locked", loginThrottleHandler.ListLockedUsers)
+	router.POST("/api/admin/users/:id/unlock", loginThrottleHandler.UnlockUser)
+	router.POST("/api/admin/users/:id/status", userHandler.UpdateUserStatus)
+	router.POST("/api/admin/users/batch", userHandler.DeleteUsers)
+	return &authTestEnv{router: router, db: db, captchas: captchaStore, outbox: outbox, tokens: tokenService, mfa: mfaService}
+}
+
+// authRequest 发送 JSON 请求，token 不为空时携带访问令牌
+func authRequest(router *gin.Engine, method, url, token string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req, _ := http.NewRequest(method, url, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "192.0.2.1:1234"
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// login 以测试用户登录并返回令牌
+func login(t *testing.T, router *gin.Engine, password string) (int, service.TokenPair) {
+	return loginAs(t, router, "alice", password)
+}
+
+// loginAs 使用指定账号登录并返回令牌
+func loginAs(t *testing.T, router *gin.Engine, username, password string) (int, service.TokenPair) {
+	w := authRequest(router, "POST", "/api/auth/login", "", map[string]string{
+		"username": username, "password": password,
+	})
+	var resp struct {
+		Data service.LoginResponse `json:"data"`
+	}
+	if w.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	if resp.Data.TokenPair == nil {
+		return w.Code, service.TokenPair{}
+	}
+	return w.Code, *resp.Data.TokenPair
+}
+
+// refresh 使用刷新令牌换取新令牌
+func refresh(t *testing.T, router *gin.Engine, refreshToken string) (int, service.TokenPair) {
+	w := authRequest(router, "POST", "/api/auth/refresh", "", map[string]string{"refresh_token": refreshToken})
+	var resp struct {
+		Data service.TokenPair `json:"data"`
+	}
+	if w.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w.Code, resp.Data
+}
+
+func TestAuth_RefreshRotatesAndDetectsReuse(t *testing.T) {
+	router := setupAuthRouter(t).router
+
+	code, first := login(t, router, authTestPassword)
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotEmpty(t, first.Token)
+	assert.NotEmpty(t, first.RefreshToken)
+	assert.Equal(t, int64(15*60), first.ExpiresIn)
+
+	code, second := refresh(t, router, first.RefreshToken)
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+	assert.Equal(t, http.StatusOK, authRequest(router, "GET", "/api/users/profile", second.Token, nil).Code)
+
+	// 重复使用已轮换的刷新令牌，整个会话被撤销
+	code, _ = refresh(t, router, first.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = refresh(t, router, second.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Equal(t, http.StatusUnauthorized, authRequest(router, "GET", "/api/users/profile", second.Token, nil).Code)
+
+	code, _ = refresh(t, router, "not-a-token")
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
+
+func TestAuth_LogoutRevokesOnlyCurrentSession(t *testing.T) {
+	router := setupAuthRouter(t).router
+
+	_, phone := login(t, router, authTestPassword)
+	_, laptop := login(t, router, authTestPassword)
+
+	assert.Equal(t, http.StatusOK, authRequest(router, "POST", "/api/auth/logout", phone.Token, nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, authRequest(router, "GET", "/api/users/profile", phone.Token, nil).Code)
+	code, _ := refresh(t, router, phone.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	assert.Equal(t, http.StatusOK, authRequest(router, "GET", "/api/users/profile", laptop.Token, nil).Code)
+}
+
+func TestAuth_PasswordChangeAndBlockRevokeSessions(t *testing.T) {
+	router := setupAuthRouter(t).router
+
+	_, tokens := login(t, router, authTestPassword)
+	w := authRequest(router, "POST", "/api/users/password", tokens.Token, map[string]string{
+		"old_password": authTestPassword, "new_password": "newsecret456",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusUnauthorized, authRequest(router, "GET", "/api/users/profile", tokens.Token, nil).Code)
+	code, _ := refresh(t, router, tokens.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	code, tokens = login(t, router, "newsecret456")
+	assert.Equal(t, http.StatusOK, code)
+
+	w = authRequest(router, "POST", "/api/admin/users/1/status", "", map[string]string{"status": "blocked"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusUnauthorized, authRequest(router, "GET", "/api/users/profile", tokens.Token, nil).Code)
+	code, _ = refresh(t, router, tokens.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = login(t, router, "newsecret456")
+	assert.Equal(t, http.StatusForbidden, code)
+}
+
+// getCaptcha 获取图形验证码，返回 ID 和答案
+func getCaptcha(t *testing.T, router *gin.Engine, store *recordingCaptchaStore) (string, string) {
+	w := authRequest(router, "GET", "/api/auth/captcha", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data service.CaptchaResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, strings.HasPrefix(resp.Data.Image, "data:image/png;base64,"))
+
+	img, err := png.Decode(base64.NewDecoder(base64.StdEncoding,
+		strings.NewReader(strings.TrimPrefix(resp.Data.Image, "data:image/png;base64,"))))
+	assert.NoError(t, err)
+	assert.Equal(t, 120, img.Bounds().Dx())
+	return resp.Data.CaptchaID, store.answers[resp.Data.CaptchaID]
+}
+
+// errorCode 读取错误响应的错误代码
+func errorCode(w *httptest.ResponseRecorder) string {
+	var resp struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp.Error.Code
+}
+
+func TestCaptcha_RequiredOnRegister(t *testing.T) {
+	env := setupAuthRouter(t)
+	router, store := env.router, env.captchas
+	body := map[string]string{"username": "bob", "email": "bob@example.com", "password": "secret123"}
+
+	w := authRequest(router, "POST", "/api/auth/register", "", body)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "CAPTCHA_REQUIRED", errorCode(w))
+
+	// 答错后验证码作废，即使再提交正确答案也需要重新获取
+	id, answer := getCaptcha(t, router, store)
+	assert.Len(t, answer, 4)
+	body["captcha_id"], body["captcha_answer"] = id, "wrong"
+	w = authRequest(router, "POST", "/api/auth/register", "", body)
+	assert.Equal(t, "CAPTCHA_INVALID", errorCode(w))
+	body["captcha_answer"] = answer
+	w = authRequest(router, "POST", "/api/auth/register", "", body)
+	assert.Equal(t, "CAPTCHA_INVALID", errorCode(w))
+
+	sendCode(t, router, "bob@example.com", "register")
+	id, answer = getCaptcha(t, router, store)
+	body["captcha_id"], body["captcha_answer"] = id, answer
+	body["email_code"] = env.outbox.lastCode("bob@example.com")
+	w = authRequest(router, "POST", "/api/auth/register", "", body)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCaptcha_RequiredAfterLoginFailures(t *testing.T) {
+	env := setupAuthRouter(t)
+	router, store := env.router, env.captchas
+
+	for i := 0; i < authCaptchaThreshold; i++ {
+		code, _ := login(t, router, "wrong-password")
+		assert.Equal(t, http.StatusUnauthorized, code)
+	}
+
+	// 达到阈值后，即使密码正确也需要验证码
+	w := authRequest(router, "POST", "/api/auth/login", "", map[string]string{
+		"username": "alice", "password": authTestPassword,
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "CAPTCHA_REQUIRED", errorCode(w))
+
+	id, answer := getCaptcha(t, router, store)
+	w = authRequest(router, "POST", "/api/auth/login", "", map[string]string{
+		"username": "alice", "password": authTestPassword, "captcha_id": id, "captcha_answer": answer,
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 登录成功后失败次数清零
+	code, _ := login(t, router, authTestPassword)
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestCaptcha_StoreLimit(t *testing.T) {
+	captchaService := service.NewCaptchaService(service.NewMemoryCaptchaStore(2), &config.CaptchaConfig{Length: 4, TTL: time.Minute})
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/auth/captcha", handler.NewCaptchaHandler(captchaService).GetCaptcha)
+
+	assert.Equal(t, http.StatusOK, authRequest(router, "GET", "/api/auth/captcha", "", nil).Code)
+	assert.Equal(t, http.StatusOK, authRequest(router, "GET", "/api/auth/captcha", "", nil).Code)
+	w := authRequest(router, "GET", "/api/auth/captcha", "", nil)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "未过期的验证码达到上限后拒绝生成")
+}
@@ -0,0 +1,356 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gohotel/internal/config"
+	"gohotel/internal/handler"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/internal/service"
+	"gohotel/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupBookingRouter 初始化内存数据库并配置预订路由
+//
+// 房间 1 开放钟点房（每小时 50，最少 2 小时），3 天后入住一晚；房间 2 不开放钟点房
+func setupBookingRouter(t *testing.T) (*gin.Engine, *gorm.DB, time.Time) {
+	if err := utils.InitSnowflake(1); err != nil {
+		t.Fatalf("初始化雪花算法失败: %v", err)
+	}
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法连接到内存数据库: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Room{}, &models.RoomPhoto{}, &models.Booking{}, &models.WorkOrder{},
+		&models.HousekeepingTask{}, &models.PricingRule{}, &models.RoomRate{}, &models.PriceChangeLog{},
+		&models.StayDiscount{}, &models.LongStayPolicy{}, &models.BookingInstallment{}); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+
+	rooms := []models.Room{
+		{ID: 1, RoomNumber: "101", RoomType: "标准间", Price: 200, HourlyEnabled: true, HourlyPrice: 50, HourlyMinHours: 2},
+		{ID: 2, RoomNumber: "102", RoomType: "标准间", Price: 200},
+	}
+	for i := range rooms {
+		rooms[i].HotelID = 1
+		rooms[i].Floor = 1
+		rooms[i].Capacity = 2
+	}
+	assert.NoError(t, db.Create(&rooms).Error)
+
+	now := time.Now()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 3)
+	assert.NoError(t, db.Create(&models.Booking{
+		ID: 1, BookingNumber: 1, HotelID: 1, UserID: 1, RoomID: 1,
+		BookingType: models.BookingTypeNightly, CheckIn: day, CheckOut: day.AddDate(0, 0, 1), TotalDays: 1,
+		GuestName: "张三", GuestPhone: "13800000000", Status: "confirmed",
+	}).Error)
+
+	roomRepo := repository.NewRoomRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	housekeepingService := service.NewHousekeepingService(repository.NewHousekeepingRepository(db), roomRepo, userRepo,
+		repository.NewHotelRepository(db))
+	pricingService := service.NewPricingService(repository.NewPricingRepository(db), repository.NewHotelRepository(db),
+		utils.NewMultiTimeWheel(), &config.PricingConfig{Interval: time.Hour, HorizonDays: 10})
+	bookingRepo := repository.NewBookingRepository(db)
+	bookingConfig := &config.BookingConfig{CheckInHour: 14, CheckOutHour: 12, CleaningBuffer: 30 * time.Minute, BillingInterval: time.Hour}
+	billingService := service.NewBillingService(repository.NewInstallmentRepository(db), bookingRepo, utils.NewMultiTimeWheel(), bookingConfig)
+	bookingService := service.NewBookingService(bookingRepo, roomRepo, userRepo, repository.NewWorkOrderRepository(db),
+		housekeepingService, pricingService, billingService, bookingConfig)
+	bookingHandler := handler.NewBookingHandler(bookingService)
+	roomHandler := handler.NewRoomHandler(service.NewRoomService(roomRepo, nil, nil))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", int64(1))
+		c.Set("hotel_id", uint(1))
+		c.Next()
+	})
+	router.POST("/api/bookings", bookingHandler.CreateBooking)
+	router.GET("/api/bookings/:id", bookingHandler.GetBookingByID)
+	router.POST("/api/bookings/:id/cancel", bookingHandler.CancelBooking)
+	router.POST("/api/admin/bookings/:id/checkin", bookingHandler.CheckIn)
+	router.POST("/api/admin/bookings/:id/installments/:installment_id/pay", bookingHandler.PayInstallment)
+	router.POST("/api/admin/rooms/batch", roomHandler.BatchCreateRooms)
+	return router, db, day
+}
+
+// postBooking 通过接口创建预订，返回状态码和预订
+func postBooking(t *testing.T, router *gin.Engine, body map[string]interface{}) (int, models.Booking) {
+	body["guest_name"] = "李四"
+	body["guest_phone"] = "13900000000"
+	data, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/bookings", bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	var response struct {
+		Data models.Booking `json:"data"`
+	}
+	if w.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), "JSON 反序列化失败")
+	}
+	return w.Code, response.Data
+}
+
+func TestBooking_HourlyPricingAndMinimumBlock(t *testing.T) {
+	router, _, day := setupBookingRouter(t)
+	next := day.AddDate(0, 0, 1).Format("2006-01-02")
+
+	code, _ := postBooking(t, router, map[string]interface{}{
+		"room_id": 2, "booking_type": "hourly", "start_time": next + " 13:00", "end_time": next + " 16:00",
+	})
+	assert.Equal(t, http.StatusBadRequest, code, "未开放钟点房的房间不能按小时预订")
+
+	code, _ = postBooking(t, router, map[string]interface{}{
+		"room_id": 1, "booking_type": "hourly", "start_time": next + " 15:00", "end_time": next + " 16:00",
+	})
+	assert.Equal(t, http.StatusBadRequest, code, "不足最少预订小时数")
+
+	code, _ = postBooking(t, router, map[string]interface{}{
+		"room_id": 1, "booking_type": "hourly", "start_time": next + " 15:00", "end_time": next + " 16:30",
+	})
+	assert.Equal(t, http.StatusBadRequest, code, "钟点房按整小时预订")
+
+	code, booking := postBooking(t, router, map[string]interface{}{
+		"room_id": 1, "booking_type": "hourly", "start_time": next + " 15:00", "end_time": next + " 18:00",
+	})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, models.BookingTypeHourly, booking.BookingType)
+	assert.Equal(t, 3, booking.TotalHours)
+	assert.Equal(t, 0, booking.TotalDays)
+	assert.Equal(t, 150.0, booking.TotalPrice)
+	assert.Equal(t, next, booking.CheckIn.Format("2006-01-02"))
+}
+
+func TestBooking_BatchCreatedHourlyRooms(t *testing.T) {
+	router, db, day := setupBookingRouter(t)
+	next := day.AddDate(0, 0, 1).Format("2006-01-02")
+
+	w := housekeepingRequest(router, "POST", "/api/admin/rooms/batch", 1, map[string]interface{}{
+		"rooms": []map[string]interface{}{
+			{"room_number": "201", "room_type": "标准间", "floor": 2, "price": 200, "capacity": 2,
+				"hourly_enabled": true, "hourly_price": 60, "hourly_min_hours": 3},
+			{"room_number": "202", "room_type": "标准间", "floor": 2, "price": 200, "capacity": 2, "hourly_enabled": true},
+		},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data service.BatchCreateRoomsResult `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Data.SuccessCount)
+	if assert.Len(t, resp.Data.FailedRooms, 1) {
+		assert.Equal(t, "202", resp.Data.FailedRooms[0].RoomNumber, "开放钟点房但未设置价格")
+	}
+
+	var room models.Room
+	assert.NoError(t, db.Where("room_number = ?", "201").First(&room).Error)
+	assert.True(t, room.HourlyEnabled)
+	assert.Equal(t, 60.0, room.HourlyPrice)
+	assert.Equal(t, 3, room.HourlyMinHours)
+
+	// 批量创建的钟点房同样按最少小时数和钟点价格预订
+	code, _ := postBooking(t, router, map[string]interface{}{
+		"room_id": room.ID, "booking_type": "hourly", "start_time": next + " 13:00", "end_time": next + " 15:00",
+	})
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, booking := postBooking(t, router, map[string]interface{}{
+		"room_id": room.ID, "booking_type": "hourly", "start_time": next + " 13:00", "end_time": next + " 16:00",
+	})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 180.0, booking.TotalPrice)
+}
+
+func TestBooking_HousekeepingOnlyGatesSameDay(t *testing.T) {
+	router, db, day := setupBookingRouter(t)
+	assert.NoError(t, db.Model(&models.Room{}).Where("id = ?", 2).Update("housekeeping_status", "dirty").Error)
+
+	today := day.AddDate(0, 0, -3)
+	code, _ := postBooking(t, router, map[string]interface{}{
+		"room_id": 2, "check_in": today.Format("2006-01-02"), "check_out": today.AddDate(0, 0, 1).Format("2006-01-02"),
+	})
+	assert.Equal(t, http.StatusBadRequest, code, "脏房不能预订今日入住")
+
+	code, _ = postBooking(t, router, map[string]interface{}{
+		"room_id": 2, "check_in": day.Format("2006-01-02"), "check_out": day.AddDate(0, 0, 1).Format("2006-01-02"),
+	})
+	assert.Equal(t, http.StatusOK, code, "之后日期的预订不受当前清洁状态影响")
+
+	// 办理入住时房间必须已通过查房
+	assert.NoError(t, db.Create(&models.Booking{
+		ID: 2, BookingNumber: 2, HotelID: 1, UserID: 1, RoomID: 2,
+		BookingType: models.BookingTypeNightly, CheckIn: today, CheckOut: today.AddDate(0, 0, 1), TotalDays: 1,
+		GuestName: "张三", GuestPhone: "13800000000", Status: "confirmed", PaymentStatus: "paid",
+	}).Error)
+	w := housekeepingRequest(router, "POST", "/api/admin/bookings/2/checkin", 1, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "查房")
+	assert.NoError(t, db.Model(&models.Room{}).Where("id = ?", 2).Update("housekeeping_status", "inspected").Error)
+	w = housekeepingRequest(router, "POST", "/api/admin/bookings/2/checkin", 1, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestBooking_MixedHourlyAndNightlyConflicts(t *testing.T) {
+	router, _, day := setupBookingRouter(t)
+	next := day.AddDate(0, 0, 1).Format("2006-01-02")
+
+	// 前一晚住客 12:00 退房，需预留 30 分钟清洁时间
+	code, _ := postBooking(t, router, map[string]interface{}{
+		"room_id": 1, "booking_type": "hourly", "start_time": next + " 12:00", "end_time": next + " 14:00",
+	})
+	assert.Equal(t, http.StatusConflict, code, "钟点房不能紧接前一晚退房")
+
+	code, _ = postBooking(t, router, map[string]interface{}{
+		"room_id": 1, "booking_type": "hourly", "start_time": next + " 12:30", "end_time": next + " 14:30",
+	})
+	assert.Equal(t, http.StatusOK, code)
+
+	// 当晚 14:00 入住与钟点房重叠
+	code, _ = postBooking(t, router, map[string]interface{}{
+		"room_id": 1, "check_in": next, "check_out": day.AddDate(0, 0, 2).Format("2006-01-02"),
+	})
+	assert.Equal(t, http.StatusConflict, code, "按晚预订与钟点房时段重叠")
+
+	// 按晚预订之间不需要清洁缓冲，退房日当天即可入住
+	code, booking := postBooking(t, router, map[string]interface{}{
+		"room_id": 2, "check_in": next, "check_out": day.AddDate(0, 0, 2).Format("2006-01-02"),
+	})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, models.BookingTypeNightly, booking.BookingType)
+	assert.Equal(t, 1, booking.TotalDays)
+}
+
+func TestBooking_LongStayDiscountTiers(t *testing.T) {
+	router, db, day := setupBookingRouter(t)
+	assert.NoError(t, db.Create(&[]models.StayDiscount{
+		{HotelID: 1, MinNights: 7, DiscountPercent: 5, Enabled: true},
+		{HotelID: 1, RoomType: "标准间", MinNights: 7, DiscountPercent: 10, Enabled: true},
+		{HotelID: 1, MinNights: 14, DiscountPercent: 50, Enabled: false},
+	}).Error)
+
+	// 6 晚不打折
+	code, booking := postBooking(t, router, map[string]interface{}{
+		"room_id": 2, "check_in": day.Format("2006-01-02"), "check_out": day.AddDate(0, 0, 6).Format("2006-01-02"),
+	})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 1200.0, booking.TotalPrice)
+	assert.Equal(t, 0.0, booking.DiscountAmount)
+
+	// 14 晚匹配指定房型的 7 晚档位，停用的档位不生效
+	code, booking = postBooking(t, router, map[string]interface{}{
+		"room_id": 2, "check_in": day.AddDate(0, 0, 6).Format("2006-01-02"), "check_out": day.AddDate(0, 0, 20).Format("2006-01-02"),
+	})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 280.0, booking.DiscountAmount)
+	assert.Equal(t, 2520.0, booking.TotalPrice)
+	assert.Equal(t, models.BillingCycleFull, booking.BillingCycle)
+	assert.Empty(t, booking.Installments)
+
+	// 不足 30 晚不能按月付款
+	code, _ = postBooking(t, router, map[string]interface{}{
+		"room_id": 2, "check_in": day.AddDate(0, 0, 20).Format("2006-01-02"), "check_out": day.AddDate(0, 0, 40).Format("2006-01-02"),
+		"billing_cycle": "monthly",
+	})
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestBooking_MonthlyInstallmentPlan(t *testing.T) {
+	router, db, day := setupBookingRouter(t)
+	assert.NoError(t, db.Create(&models.StayDiscount{HotelID: 1, MinNights: 30, DiscountPercent: 20, Enabled: true}).Error)
+	assert.NoError(t, db.Create(&models.LongStayPolicy{HotelID: 1, Deposit: 1000}).Error)
+
+	// 65 晚按月付款：押金 + 30 + 30 + 5 晚三期房费
+	code, booking := postBooking(t, router, map[string]interface{}{
+		"room_id": 2, "check_in": day.Format("2006-01-02"), "check_out": day.AddDate(0, 0, 65).Format("2006-01-02"),
+		"billing_cycle": "monthly",
+	})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 10400.0, booking.TotalPrice)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/bookings/"+booking.ID.String(), nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var detail struct {
+		Data models.Booking `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &detail), "JSON 反序列化失败")
+	plan := detail.Data.Installments
+	if assert.Len(t, plan, 4) {
+		assert.Equal(t, models.InstallmentTypeDeposit, plan[0].Type)
+		assert.Equal(t, 1000.0, plan[0].Amount)
+		assert.Equal(t, models.InstallmentBilled, plan[0].Status, "押金下单即出账")
+		assert.Equal(t, []int{30, 30, 5}, []int{plan[1].Nights, plan[2].Nights, plan[3].Nights})
+		assert.Equal(t, []float64{4800, 4800, 800}, []float64{plan[1].Amount, plan[2].Amount, plan[3].Amount})
+		assert.Equal(t, day.Format("2006-01-02"), plan[1].DueDate.Format("2006-01-02"))
+		assert.Equal(t, day.AddDate(0, 0, 30).Format("2006-01-02"), plan[2].DueDate.Format("2006-01-02"))
+		assert.Equal(t, models.InstallmentScheduled, plan[1].Status)
+	}
+
+	// 未出账的房费不能登记支付，押金可以
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", fmt.Sprintf("/api/admin/bookings/%s/installments/%d/pay", booking.ID.String(), plan[1].ID), nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", fmt.Sprintf("/api/admin/bookings/%s/installments/%d/pay", booking.ID.String(), plan[0].ID), nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// 取消预订后未支付的账单作废
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/bookings/"+booking.ID.String()+"/cancel", bytes.NewReader([]byte(`{"reason":"行程变更"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var statuses []string
+	assert.NoError(t, db.Model(&models.BookingInstallment{}).Order("seq").Pluck("status", &statuses).Error)
+	assert.Equal(t, []string{models.InstallmentPaid, models.InstallmentCancelled, models.InstallmentCancelled, models.InstallmentCancelled}, statuses)
+}
+
+func TestBooking_MonthlyDepositWithoutDiscountTier(t *testing.T) {
+	router, db, day := setupBookingRouter(t)
+	assert.NoError(t, db.Create(&[]models.LongStayPolicy{
+		{HotelID: 1, Deposit: 1000},
+		{HotelID: 1, RoomType: "大床房", Deposit: 3000},
+	}).Error)
+
+	// 没有连住折扣档位时按原价分期，押金仍按长住政策收取
+	code, booking := postBooking(t, router, map[string]interface{}{
+		"room_id": 2, "check_in": day.Format("2006-01-02"), "check_out": day.AddDate(0, 0, 40).Format("2006-01-02"),
+		"billing_cycle": "monthly",
+	})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 0.0, booking.DiscountAmount)
+	assert.Equal(t, 8000.0, booking.TotalPrice)
+
+	var plan []models.BookingInstallment
+	assert.NoError(t, db.Where("booking_id = ?", booking.ID).Order("seq").Find(&plan).Error)
+	if assert.Len(t, plan, 3) {
+		assert.Equal(t, models.InstallmentTypeDeposit, plan[0].Type)
+		assert.Equal(t, 1000.0, plan[0].Amount, "标准间没有单独的政策，使用适用所有房型的押金")
+		assert.Equal(t, []float64{6000, 2000}, []float64{plan[1].Amount, plan[2].Amount})
+	}
+
+	// 一次付清不收押金
+	code, booking = postBooking(t, router, map[string]interface{}{
+		"room_id": 2, "check_in": day.AddDate(0, 0, 40).Format("2006-01-02"), "check_out": day.AddDate(0, 0, 75).Format("2006-01-02"),
+	})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, booking.Installments)
+}
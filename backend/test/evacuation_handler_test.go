@@ -0,0 +1,220 @@
+package test
+
+import (
+	"encoding/json"
+	"gohotel/internal/config"
+	"gohotel/internal/handler"
+	"gohotel/internal/middleware"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupEvacuationRouter 初始化合成楼层并配置疏散图路由
+//
+// 1 楼：走廊左端有安全出口，右端的房间离出口较远
+//
+//	      101    102    103    104
+//	[出口][========== 走廊 ==========]
+//	                               [105]   106（不与走廊相连）
+//
+// 2 楼：没有安全出口
+//
+// 3 楼：走廊两端各有一个安全出口，305 只与 304 相接
+//
+//	      301                302
+//	[出口A][====== 走廊 ======][出口B]
+//	                         [304]
+//	                         [305]
+//
+// 另一家酒店的 1 楼只有一个房间 101，用于验证按酒店隔离
+func setupEvacuationRouter(t *testing.T, maxDistance int) *gin.Engine {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法连接到内存数据库: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Hotel{}, &models.Room{}, &models.Facility{}); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+	hotels := []models.Hotel{
+		{ID: 1, Code: "default", Name: "测试酒店", Status: "active"},
+		{ID: 2, Code: "branch", Name: "分店", Status: "active"},
+	}
+	assert.NoError(t, db.Create(&hotels).Error)
+
+	facilities := []models.Facility{
+		{ID: 1, HotelID: 1, Type: "exit", Floor: 1, Left: 0, Top: 100, Width: 40, Height: 40},
+		{ID: 2, HotelID: 1, Type: "corridor", Floor: 1, Left: 40, Top: 100, Width: 400, Height: 40},
+		{ID: 3, HotelID: 1, Type: "corridor", Floor: 2, Left: 0, Top: 0, Width: 400, Height: 40},
+		{ID: 4, HotelID: 2, Type: "exit", Floor: 1, Left: 0, Top: 100, Width: 40, Height: 40},
+		{ID: 5, HotelID: 2, Type: "corridor", Floor: 1, Left: 40, Top: 100, Width: 400, Height: 40},
+		{ID: 6, HotelID: 1, Type: "exit", Floor: 3, Left: 0, Top: 100, Width: 40, Height: 40, Label: "A"},
+		{ID: 7, HotelID: 1, Type: "corridor", Floor: 3, Left: 40, Top: 100, Width: 400, Height: 40},
+		{ID: 8, HotelID: 1, Type: "exit", Floor: 3, Left: 440, Top: 100, Width: 40, Height: 40, Label: "B"},
+	}
+	assert.NoError(t, db.Create(&facilities).Error)
+
+	rooms := []models.Room{
+		{RoomNumber: "101", Floor: 1, Left: 40, Top: 0, Width: 100, Height: 100},
+		{RoomNumber: "102", Floor: 1, Left: 140, Top: 0, Width: 100, Height: 100},
+		{RoomNumber: "103", Floor: 1, Left: 240, Top: 0, Width: 100, Height: 100},
+		{RoomNumber: "104", Floor: 1, Left: 340, Top: 0, Width: 100, Height: 100},
+		{RoomNumber: "105", Floor: 1, Left: 340, Top: 140, Width: 100, Height: 100},
+		{RoomNumber: "106", Floor: 1, Left: 600, Top: 140, Width: 100, Height: 100},
+		{RoomNumber: "107", Floor: 1},
+		{RoomNumber: "201", Floor: 2, Left: 0, Top: 40, Width: 100, Height: 100},
+		{HotelID: 2, RoomNumber: "101", Floor: 1, Left: 40, Top: 0, Width: 100, Height: 100},
+		{RoomNumber: "301", Floor: 3, Left: 40, Top: 0, Width: 100, Height: 100},
+		{RoomNumber: "302", Floor: 3, Left: 340, Top: 0, Width: 100, Height: 100},
+		{RoomNumber: "304", Floor: 3, Left: 340, Top: 140, Width: 100, Height: 100},
+		{RoomNumber: "305", Floor: 3, Left: 340, Top: 240, Width: 100, Height: 100},
+	}
+	for i := range rooms {
+		if rooms[i].HotelID == 0 {
+			rooms[i].HotelID = 1
+		}
+		rooms[i].RoomType = "标准间"
+		rooms[i].Price = 100
+		rooms[i].Capacity = 2
+	}
+	assert.NoError(t, db.Create(&rooms).Error)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	evacuationService := service.NewEvacuationService(repository.NewRoomRepository(db), repository.NewFacilityRepository(db),
+		&config.EvacuationConfig{MaxDistance: maxDistance})
+	evacuationHandler := handler.NewEvacuationHandler(evacuationService)
+	hotelService := service.NewHotelService(repository.NewHotelRepository(db), repository.NewUserRepository(db))
+	router.GET("/api/floors/:floor/evacuation", middleware.HotelSelectorMiddleware(hotelService), evacuationHandler.GetEvacuationPlan)
+	return router
+}
+
+func TestGetEvacuationPlan_FlagsDistantRooms(t *testing.T) {
+	router := setupEvacuationRouter(t, 300)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/floors/1/evacuation", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Success bool                   `json:"success"`
+		Data    service.EvacuationPlan `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), "JSON 反序列化失败")
+
+	plan := response.Data
+	assert.Len(t, plan.Exits, 1)
+	assert.Len(t, plan.Rooms, 6, "尚未摆放的房间不参与计算")
+
+	rooms := make(map[string]service.EvacuationRoom)
+	for _, r := range plan.Rooms {
+		rooms[r.RoomNumber] = r
+	}
+
+	// 靠近出口的房间：路线从房间中心出发，到出口中心结束
+	near := rooms["101"]
+	assert.NotNil(t, near.Exit)
+	assert.False(t, near.ExceedsLimit)
+	assert.Equal(t, service.RoutePoint{X: 90, Y: 50}, near.Points[0])
+	assert.Equal(t, service.RoutePoint{X: 20, Y: 120}, near.Points[len(near.Points)-1])
+
+	// 走廊尽头的房间超出最大疏散距离
+	assert.True(t, rooms["104"].ExceedsLimit)
+	assert.True(t, rooms["105"].ExceedsLimit)
+	assert.Greater(t, rooms["104"].Distance, 300.0)
+
+	// 不与走廊相连的房间无法到达出口
+	assert.True(t, rooms["106"].Unreachable)
+	assert.Nil(t, rooms["106"].Exit)
+
+	assert.Equal(t, 2, plan.ExceedingCount)
+	assert.Equal(t, 1, plan.UnreachableCount)
+}
+
+func TestGetEvacuationPlan_NearestExitPerRoom(t *testing.T) {
+	router := setupEvacuationRouter(t, 300)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/floors/3/evacuation", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data service.EvacuationPlan `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), "JSON 反序列化失败")
+	rooms := make(map[string]service.EvacuationRoom)
+	for _, r := range response.Data.Rooms {
+		rooms[r.RoomNumber] = r
+	}
+
+	// 每个房间各自走向最近的出口
+	assert.Equal(t, uint(6), rooms["301"].Exit.ID)
+	assert.Equal(t, uint(8), rooms["302"].Exit.ID)
+	assert.Equal(t, uint(8), rooms["304"].Exit.ID)
+	assert.InDelta(t, rooms["301"].Distance, rooms["302"].Distance, 1, "对称位置的房间距离相同")
+	assert.Equal(t, service.RoutePoint{X: 460, Y: 120}, rooms["302"].Points[len(rooms["302"].Points)-1])
+
+	// 路线不能穿过其他房间
+	assert.True(t, rooms["305"].Unreachable)
+	assert.Equal(t, 1, response.Data.UnreachableCount)
+}
+
+func TestGetEvacuationPlan_SVG(t *testing.T) {
+	router := setupEvacuationRouter(t, 300)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/floors/1/evacuation?format=svg&room_number=102", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "image/svg+xml")
+
+	body := w.Body.String()
+	assert.True(t, strings.HasPrefix(body, "<?xml"))
+	assert.Contains(t, body, "您在此处")
+	assert.Contains(t, body, "需关注房间：104、105、106")
+	assert.Equal(t, 5, strings.Count(body, "<polyline"), "可到达出口的房间都应绘制路线")
+}
+
+func TestGetEvacuationPlan_NoExit(t *testing.T) {
+	router := setupEvacuationRouter(t, 300)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/floors/2/evacuation", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetEvacuationPlan_ScopedByHotel(t *testing.T) {
+	router := setupEvacuationRouter(t, 300)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/floors/1/evacuation", nil)
+	req.Header.Set(middleware.HotelHeader, "2")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data service.EvacuationPlan `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), "JSON 反序列化失败")
+	assert.Len(t, response.Data.Rooms, 1, "只包含指定酒店的房间")
+	assert.Equal(t, uint(4), response.Data.Exits[0].ID)
+
+	// 不存在的酒店
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/floors/1/evacuation?hotel_id=3", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,267 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"gohotel/internal/handler"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/internal/service"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupFloorLayoutRouter 初始化内存数据库并配置布局版本和设施路由
+//
+// 1 楼：房间 101 位于 (100,100)-(200,200)，房间 102 位于 (400,100)-(500,200)；
+// 设施 A、B 在启用校验前就已重叠，设施 3 用于探测碰撞，初始放在远处
+func setupFloorLayoutRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法连接到内存数据库: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Room{}, &models.RoomPhoto{}, &models.Amenity{}, &models.Facility{}, &models.FloorLayoutVersion{}); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+
+	rooms := []models.Room{
+		{ID: 1, HotelID: 1, RoomNumber: "101", Left: 100, Top: 100},
+		{ID: 2, HotelID: 1, RoomNumber: "102", Left: 400, Top: 100},
+	}
+	for i := range rooms {
+		rooms[i].RoomType = "标准间"
+		rooms[i].Floor = 1
+		rooms[i].Price = 200
+		rooms[i].Capacity = 2
+		rooms[i].Width = 100
+		rooms[i].Height = 100
+	}
+	assert.NoError(t, db.Create(&rooms).Error)
+	assert.NoError(t, db.Create(&[]models.Facility{
+		{ID: 1, HotelID: 1, Type: "storage", Floor: 1, Left: 400, Top: 300, Width: 50, Height: 50, Label: "A"},
+		{ID: 2, HotelID: 1, Type: "storage", Floor: 1, Left: 420, Top: 320, Width: 50, Height: 50, Label: "B"},
+		{ID: 3, HotelID: 1, Type: "elevator", Floor: 1, Left: 600, Top: 600, Width: 10, Height: 10, Label: "探测"},
+	}).Error)
+
+	roomRepo := repository.NewRoomRepository(db)
+	facilityRepo := repository.NewFacilityRepository(db)
+	layoutService := service.NewFloorLayoutService(repository.NewFloorLayoutRepository(db), roomRepo, facilityRepo)
+	layoutHandler := handler.NewFloorLayoutHandler(layoutService)
+	facilityHandler := handler.NewFacilityHandler(service.NewFacilityService(facilityRepo, roomRepo, layoutService))
+	roomHandler := handler.NewRoomHandler(service.NewRoomService(roomRepo, layoutService, nil))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", int64(1))
+		c.Set("hotel_id", uint(1))
+		c.Next()
+	})
+	router.POST("/api/admin/floors/:floor/layout/validate", layoutHandler.ValidateLayout)
+	router.POST("/api/admin/floors/:floor/layout/draft", layoutHandler.SaveDraft)
+	router.GET("/api/admin/floors/:floor/layout/versions", layoutHandler.ListVersions)
+	router.POST("/api/admin/floors/:floor/layout/versions/:id/publish", layoutHandler.PublishDraft)
+	router.POST("/api/admin/floors/:floor/layout/versions/:id/rollback", layoutHandler.Rollback)
+	router.POST("/api/admin/facilities", facilityHandler.CreateFacility)
+	router.POST("/api/admin/facilities/batch", facilityHandler.BatchUpdateFacilities)
+	router.POST("/api/admin/facilities/:id", facilityHandler.UpdateFacility)
+	router.POST("/api/admin/rooms/:id", roomHandler.UpdateRoom)
+	return router, db
+}
+
+// validateLayout 校验布局改动，返回问题类型列表
+func validateLayout(t *testing.T, router *gin.Engine, body interface{}) []string {
+	w := housekeepingRequest(router, "POST", "/api/admin/floors/1/layout/validate", 1, body)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data service.LayoutValidationResult `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	types := make([]string, 0, len(resp.Data.Issues))
+	for _, issue := range resp.Data.Issues {
+		types = append(types, issue.Type)
+	}
+	assert.Equal(t, len(types) == 0, resp.Data.Valid)
+	return types
+}
+
+// probeAt 把探测设施移动到指定位置
+func probeAt(left, top, width, height, rotation int) map[string]interface{} {
+	return map[string]interface{}{"facilities": []map[string]int{
+		{"id": 3, "left": left, "top": top, "width": width, "height": height, "rotation": rotation},
+	}}
+}
+
+func TestFloorLayout_CollisionDetection(t *testing.T) {
+	router, _ := setupFloorLayoutRouter(t)
+
+	// 与房间 101 (100,100)-(200,200) 比较
+	cases := []struct {
+		name                            string
+		left, top, width, height, angle int
+		issues                          []string
+	}{
+		{"分离", 300, 100, 50, 50, 0, nil},
+		{"边相接", 200, 100, 50, 100, 0, nil},
+		{"角相接", 200, 200, 50, 50, 0, nil},
+		{"部分重叠", 150, 150, 100, 100, 0, []string{service.LayoutIssueCollision}},
+		{"被房间包含", 120, 120, 20, 20, 0, []string{service.LayoutIssueCollision}},
+		{"包含房间", 50, 50, 300, 300, 0, []string{service.LayoutIssueCollision}},
+		{"旋转45度外接矩形相交但不重叠", 220, 220, 100, 100, 45, nil},
+		{"旋转45度重叠", 180, 100, 100, 100, 45, []string{service.LayoutIssueCollision}},
+		{"旋转90度后避开房间", 150, 140, 200, 20, 90, nil},
+		{"旋转90度后边相接", 160, 140, 100, 20, 90, nil},
+		{"旋转后超出画布", 0, 0, 100, 20, 90, []string{service.LayoutIssueOutOfBounds}},
+		{"宽高不合法", 300, 300, 0, 10, 0, []string{service.LayoutIssueInvalidSize}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			issues := validateLayout(t, router, probeAt(tc.left, tc.top, tc.width, tc.height, tc.angle))
+			if tc.issues == nil {
+				assert.Empty(t, issues)
+			} else {
+				assert.Equal(t, tc.issues, issues)
+			}
+		})
+	}
+}
+
+func TestFloorLayout_OnlyChangedItemsValidated(t *testing.T) {
+	router, _ := setupFloorLayoutRouter(t)
+
+	// A、B 原本就重叠，不影响其他元素的改动
+	assert.Empty(t, validateLayout(t, router, map[string]interface{}{"rooms": []map[string]int{
+		{"id": 2, "left": 400, "top": 0, "width": 100, "height": 100},
+	}}))
+	assert.Empty(t, validateLayout(t, router, map[string]interface{}{"facilities": []map[string]int{
+		{"id": 1, "left": 400, "top": 300, "width": 50, "height": 50},
+	}}), "坐标未变的元素不算改动")
+	assert.Equal(t, []string{service.LayoutIssueCollision}, validateLayout(t, router, map[string]interface{}{"facilities": []map[string]int{
+		{"id": 1, "left": 410, "top": 300, "width": 50, "height": 50},
+	}}), "改动的元素仍与 B 重叠")
+
+	w := housekeepingRequest(router, "POST", "/api/admin/facilities", 1, map[string]interface{}{
+		"type": "stairs", "floor": 1, "left": 700, "top": 100, "width": 50, "height": 50,
+	})
+	assert.Equal(t, http.StatusOK, w.Code, "新建设施不受已有重叠影响")
+	w = housekeepingRequest(router, "POST", "/api/admin/facilities", 1, map[string]interface{}{
+		"type": "stairs", "floor": 1, "left": 150, "top": 150, "width": 50, "height": 50,
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestFloorLayout_DraftPublishRollback(t *testing.T) {
+	router, db := setupFloorLayoutRouter(t)
+
+	w := housekeepingRequest(router, "POST", "/api/admin/floors/1/layout/draft", 1, map[string]interface{}{
+		"rooms": []map[string]int{{"id": 1, "left": 350, "top": 100, "width": 100, "height": 100}},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "校验未通过的布局不能保存")
+
+	w = housekeepingRequest(router, "POST", "/api/admin/floors/1/layout/draft", 1, map[string]interface{}{
+		"rooms": []map[string]int{{"id": 1, "left": 100, "top": 0, "width": 100, "height": 100}},
+		"note":  "101 上移",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var draft struct {
+		Data service.FloorLayoutVersionDetail `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &draft))
+	assert.Equal(t, "draft", draft.Data.Status)
+
+	var room models.Room
+	assert.NoError(t, db.First(&room, 1).Error)
+	assert.Equal(t, 100, room.Top, "发布前不影响线上布局")
+
+	w = housekeepingRequest(router, "POST", fmt.Sprintf("/api/admin/floors/1/layout/versions/%d/publish", draft.Data.ID), 1, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, db.First(&room, 1).Error)
+	assert.Equal(t, 0, room.Top)
+
+	// 首次发布时保存了基线版本，回滚到基线恢复原布局
+	w = housekeepingRequest(router, "GET", "/api/admin/floors/1/layout/versions", 1, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var versions struct {
+		Data []models.FloorLayoutVersion `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &versions))
+	assert.Len(t, versions.Data, 2)
+	var baseline models.FloorLayoutVersion
+	for _, v := range versions.Data {
+		if v.Version == 0 {
+			baseline = v
+		}
+	}
+	w = housekeepingRequest(router, "POST", fmt.Sprintf("/api/admin/floors/1/layout/versions/%d/rollback", baseline.ID), 1, map[string]string{})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, db.First(&room, 1).Error)
+	assert.Equal(t, 100, room.Top)
+}
+
+func TestFloorLayout_CoordinateEditsStagedAsDraft(t *testing.T) {
+	router, db := setupFloorLayoutRouter(t)
+
+	w := housekeepingRequest(router, "POST", "/api/admin/floors/1/layout/draft", 1, map[string]interface{}{
+		"rooms": []map[string]int{{"id": 1, "left": 100, "top": 0, "width": 100, "height": 100}},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 设施批量调整同样校验布局
+	w = housekeepingRequest(router, "POST", "/api/admin/facilities/batch", 1, map[string]interface{}{
+		"items": []map[string]int{{"id": 3, "left": 450, "top": 150, "width": 10, "height": 10}},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "移动到房间 102 内")
+
+	w = housekeepingRequest(router, "POST", "/api/admin/facilities/batch", 1, map[string]interface{}{
+		"items": []map[string]int{{"id": 3, "left": 700, "top": 700, "width": 10, "height": 10}},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var drafts struct {
+		Data []service.FloorLayoutVersionDetail `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &drafts))
+	if !assert.Len(t, drafts.Data, 1) {
+		return
+	}
+	draft := drafts.Data[0]
+	assert.Equal(t, "draft", draft.Status)
+
+	var facility models.Facility
+	assert.NoError(t, db.First(&facility, 3).Error)
+	assert.Equal(t, 600, facility.Left, "发布前不影响线上布局")
+
+	// 房间和设施接口不再直接修改坐标
+	w = housekeepingRequest(router, "POST", "/api/admin/facilities/3", 1, map[string]interface{}{
+		"left": 450, "top": 150, "width": 10, "height": 10, "label": "电梯",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = housekeepingRequest(router, "POST", "/api/admin/rooms/2", 1, map[string]interface{}{"left": 100, "top": 100})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, db.First(&facility, 3).Error)
+	assert.Equal(t, 600, facility.Left)
+	assert.Equal(t, "电梯", facility.Label)
+	var room models.Room
+	assert.NoError(t, db.First(&room, 2).Error)
+	assert.Equal(t, 400, room.Left)
+
+	// 发布后草稿中先前保存的房间改动和设施调整一起生效
+	w = housekeepingRequest(router, "POST", fmt.Sprintf("/api/admin/floors/1/layout/versions/%d/publish", draft.ID), 1, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, db.First(&facility, 3).Error)
+	assert.Equal(t, 700, facility.Left)
+	var moved models.Room
+	assert.NoError(t, db.First(&moved, 1).Error)
+	assert.Equal(t, 0, moved.Top)
+
+	// 更换楼层后需要在新楼层重新摆放
+	w = housekeepingRequest(router, "POST", "/api/admin/facilities/3", 1, map[string]interface{}{"floor": 2})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, db.First(&facility, 3).Error)
+	assert.Equal(t, 2, facility.Floor)
+	assert.Equal(t, 0, facility.Width)
+}
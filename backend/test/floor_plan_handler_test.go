@@ -0,0 +1,132 @@
+package test
+
+import (
+	"encoding/json"
+	"gohotel/internal/handler"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupFloorPlanRouter 初始化内存数据库并配置楼层平面图路由
+//
+// 1 楼四个房间分别为空闲、在住、待清洁、今天有停用工单，另有一部旋转 90 度的电梯；
+// 酒店 2 的 1 楼有一个房间
+func setupFloorPlanRouter(t *testing.T) *gin.Engine {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法连接到内存数据库: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Room{}, &models.Facility{}, &models.WorkOrder{}); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+
+	rooms := []models.Room{
+		{ID: 1, HotelID: 1, RoomNumber: "101", Status: "available", HousekeepingStatus: "inspected", Left: 0, Top: 0},
+		{ID: 2, HotelID: 1, RoomNumber: "102", Status: "occupied", HousekeepingStatus: "inspected", Left: 100, Top: 0},
+		{ID: 3, HotelID: 1, RoomNumber: "103", Status: "available", HousekeepingStatus: "dirty", Left: 0, Top: 100},
+		{ID: 4, HotelID: 1, RoomNumber: "104", Status: "available", HousekeepingStatus: "inspected", Left: 100, Top: 100},
+		{ID: 5, HotelID: 2, RoomNumber: "101", Status: "available", HousekeepingStatus: "inspected", Left: 0, Top: 0},
+	}
+	for i := range rooms {
+		rooms[i].RoomType = "标准间"
+		rooms[i].Floor = 1
+		rooms[i].Price = 200
+		rooms[i].Capacity = 2
+		rooms[i].Width = 100
+		rooms[i].Height = 80
+	}
+	assert.NoError(t, db.Create(&rooms).Error)
+	assert.NoError(t, db.Create(&models.Facility{
+		ID: 1, HotelID: 1, Type: "elevator", Floor: 1, Left: 250, Top: 150, Width: 100, Height: 20, Rotation: 90, Label: "<电梯>",
+	}).Error)
+
+	today, _ := time.Parse("2006-01-02", time.Now().Format("2006-01-02"))
+	start, end := today, today.AddDate(0, 0, 1)
+	assert.NoError(t, db.Create(&models.WorkOrder{
+		ID: 1, RoomID: 4, Title: "漏水", Category: "plumbing", Status: "open", ReporterID: 1,
+		OutOfOrderStart: &start, OutOfOrderEnd: &end,
+	}).Error)
+
+	floorPlanHandler := handler.NewFloorPlanHandler(service.NewFloorPlanService(repository.NewRoomRepository(db),
+		repository.NewFacilityRepository(db), repository.NewWorkOrderRepository(db)))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("hotel_id", uint(1))
+		c.Next()
+	})
+	router.GET("/api/floors/:floor/plan", floorPlanHandler.GetFloorPlan)
+	return router
+}
+
+// getFloorPlan 请求楼层平面图
+func getFloorPlan(router *gin.Engine, url string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", url, nil)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestFloorPlan_JSON(t *testing.T) {
+	router := setupFloorPlanRouter(t)
+
+	w := getFloorPlan(router, "/api/floors/1/plan")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data service.FloorPlan `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	plan := resp.Data
+
+	assert.Len(t, plan.Rooms, 4, "只包含当前酒店的房间")
+	live := map[string]string{}
+	for _, room := range plan.Rooms {
+		live[room.RoomNumber] = room.LiveStatus
+	}
+	assert.Equal(t, map[string]string{
+		"101": service.PlanRoomAvailable,
+		"102": service.PlanRoomOccupied,
+		"103": service.PlanRoomDirty,
+		"104": service.PlanRoomOutOfOrder,
+	}, live)
+
+	// 旋转 90 度的电梯外接矩形为 (290, 110) - (310, 210)，再加 20 的留白
+	assert.Len(t, plan.Facilities, 1)
+	assert.Equal(t, 330, plan.Width)
+	assert.Equal(t, 230, plan.Height)
+	assert.Len(t, plan.Legend, 4)
+}
+
+func TestFloorPlan_SVG(t *testing.T) {
+	router := setupFloorPlanRouter(t)
+
+	w := getFloorPlan(router, "/api/floors/1/plan?format=svg")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "image/svg+xml"))
+	svg := w.Body.String()
+	assert.Contains(t, svg, `<svg xmlns="http://www.w3.org/2000/svg" width="330" height="260"`)
+	assert.Contains(t, svg, `data-room-id="4" data-status="out_of_order"`)
+	assert.Contains(t, svg, `transform="rotate(90 300.0 160.0)"`)
+	assert.Contains(t, svg, "&lt;电梯&gt;", "标签需转义")
+	assert.NotContains(t, svg, "<电梯>")
+}
+
+func TestFloorPlan_Errors(t *testing.T) {
+	router := setupFloorPlanRouter(t)
+
+	assert.Equal(t, http.StatusBadRequest, getFloorPlan(router, "/api/floors/1/plan?format=png").Code)
+	assert.Equal(t, http.StatusBadRequest, getFloorPlan(router, "/api/floors/abc/plan").Code)
+	assert.Equal(t, http.StatusNotFound, getFloorPlan(router, "/api/floors/9/plan").Code)
+}
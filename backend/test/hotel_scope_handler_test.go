@@ -0,0 +1,176 @@
+package test
+
+import (
+	"encoding/json"
+	"gohotel/internal/config"
+	"gohotel/internal/handler"
+	"gohotel/internal/middleware"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/internal/service"
+	"gohotel/pkg/utils"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupHotelScopeRouter 初始化内存数据库并配置经过酒店范围中间件的管理接口
+//
+// 管理员 1 只分配到酒店 1；房间 1、预订 1、工单 1 属于酒店 1，房间 2、预订 2、工单 2 属于酒店 2
+func setupHotelScopeRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
+	if err := utils.InitSnowflake(1); err != nil {
+		t.Fatalf("初始化雪花算法失败: %v", err)
+	}
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法连接到内存数据库: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Hotel{}, &models.HotelStaff{}, &models.Room{}, &models.RoomPhoto{},
+		&models.Amenity{}, &models.Booking{}, &models.BookingInstallment{}, &models.WorkOrder{}, &models.HousekeepingTask{},
+		&models.PricingRule{}, &models.RoomRate{}, &models.PriceChangeLog{}, &models.StayDiscount{}); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+
+	assert.NoError(t, db.Create(&models.User{ID: 1, Username: "admin", Email: "admin@example.com", Password: "x", Status: "active"}).Error)
+	assert.NoError(t, db.Create(&[]models.Hotel{
+		{ID: 1, Code: "A", Name: "酒店 A", Status: "active"},
+		{ID: 2, Code: "B", Name: "酒店 B", Status: "active"},
+	}).Error)
+	assert.NoError(t, db.Create(&models.HotelStaff{HotelID: 1, UserID: 1}).Error)
+	assert.NoError(t, db.Create(&[]models.Room{
+		{ID: 1, HotelID: 1, RoomNumber: "101", RoomType: "标准间", Floor: 1, Price: 200, Capacity: 2, Status: "available"},
+		{ID: 2, HotelID: 2, RoomNumber: "101", RoomType: "标准间", Floor: 1, Price: 200, Capacity: 2, Status: "available"},
+	}).Error)
+	day := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 10)
+	bookings := []models.Booking{
+		{ID: 1, BookingNumber: 1, HotelID: 1, RoomID: 1},
+		{ID: 2, BookingNumber: 2, HotelID: 2, RoomID: 2},
+	}
+	for i := range bookings {
+		bookings[i].UserID = 1
+		bookings[i].BookingType = models.BookingTypeNightly
+		bookings[i].CheckIn = day
+		bookings[i].CheckOut = day.AddDate(0, 0, 1)
+		bookings[i].TotalDays = 1
+		bookings[i].GuestName = "张三"
+		bookings[i].GuestPhone = "13800000000"
+		bookings[i].Status = "pending"
+	}
+	assert.NoError(t, db.Create(&bookings).Error)
+	assert.NoError(t, db.Create(&[]models.WorkOrder{
+		{ID: 1, RoomID: 1, Title: "漏水", Category: "plumbing", Status: "open", ReporterID: 1},
+		{ID: 2, RoomID: 2, Title: "空调异响", Category: "hvac", Status: "open", ReporterID: 1},
+	}).Error)
+
+	roomRepo := repository.NewRoomRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	bookingRepo := repository.NewBookingRepository(db)
+	workOrderRepo := repository.NewWorkOrderRepository(db)
+	housekeepingService := service.NewHousekeepingService(repository.NewHousekeepingRepository(db), roomRepo, userRepo,
+		repository.NewHotelRepository(db))
+	pricingService := service.NewPricingService(repository.NewPricingRepository(db), repository.NewHotelRepository(db),
+		utils.NewMultiTimeWheel(), &config.PricingConfig{Interval: time.Hour, HorizonDays: 10})
+	bookingConfig := &config.BookingConfig{CheckInHour: 14, CheckOutHour: 12, CleaningBuffer: 30 * time.Minute, BillingInterval: time.Hour}
+	billingService := service.NewBillingService(repository.NewInstallmentRepository(db), bookingRepo, utils.NewMultiTimeWheel(), bookingConfig)
+	bookingHandler := handler.NewBookingHandler(service.NewBookingService(bookingRepo, roomRepo, userRepo, workOrderRepo,
+		housekeepingService, pricingService, billingService, bookingConfig))
+	roomHandler := handler.NewRoomHandler(service.NewRoomService(roomRepo, nil, nil))
+	workOrderHandler := handler.NewWorkOrderHandler(service.NewWorkOrderService(workOrderRepo, roomRepo, bookingRepo, userRepo,
+		repository.NewHotelRepository(db), nil))
+	hotelService := service.NewHotelService(repository.NewHotelRepository(db), userRepo)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", int64(1))
+		c.Next()
+	})
+	admin := router.Group("/api", middleware.HotelScopeMiddleware(hotelService))
+	admin.POST("/rooms/:id", roomHandler.UpdateRoom)
+	admin.POST("/rooms/:id/delete", roomHandler.DeleteRoom)
+	admin.GET("/admin/bookings", bookingHandler.ListAllBookings)
+	admin.GET("/admin/bookings/room", bookingHandler.GetBookingsByRoomNumberAndStatus)
+	admin.POST("/admin/bookings/:id/confirm", bookingHandler.ConfirmBooking)
+	admin.POST("/admin/bookings/:id/checkin", bookingHandler.CheckIn)
+	admin.GET("/admin/work-orders", workOrderHandler.ListWorkOrders)
+	admin.GET("/admin/work-orders/:id", workOrderHandler.GetWorkOrder)
+	admin.POST("/admin/work-orders/:id", workOrderHandler.UpdateWorkOrder)
+	return router, db
+}
+
+// responseIDs 读取列表响应中的 ID
+func responseIDs(t *testing.T, body []byte) []int64 {
+	var resp struct {
+		Data []struct {
+			ID utils.JSONInt64 `json:"id"`
+		} `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &resp))
+	ids := make([]int64, len(resp.Data))
+	for i, item := range resp.Data {
+		ids[i] = int64(item.ID)
+	}
+	return ids
+}
+
+func TestHotelScope_CannotSelectOtherHotel(t *testing.T) {
+	router, _ := setupHotelScopeRouter(t)
+
+	for _, url := range []string{"/api/admin/bookings?hotel_id=2", "/api/admin/work-orders?hotel_id=2"} {
+		assert.Equal(t, http.StatusForbidden, housekeepingRequest(router, "GET", url, 1, nil).Code, url)
+	}
+	w := housekeepingRequest(router, "POST", "/api/rooms/2?hotel_id=2", 1, map[string]string{"description": "朝南"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestHotelScope_Rooms(t *testing.T) {
+	router, db := setupHotelScopeRouter(t)
+
+	w := housekeepingRequest(router, "POST", "/api/rooms/2", 1, map[string]string{"description": "朝南"})
+	assert.Equal(t, http.StatusNotFound, w.Code, "不能修改其他酒店的房间")
+	w = housekeepingRequest(router, "POST", "/api/rooms/2/delete", 1, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code, "不能删除其他酒店的房间")
+
+	var room models.Room
+	assert.NoError(t, db.First(&room, 2).Error)
+	assert.Empty(t, room.Description)
+}
+
+func TestHotelScope_Bookings(t *testing.T) {
+	router, db := setupHotelScopeRouter(t)
+
+	w := housekeepingRequest(router, "GET", "/api/admin/bookings", 1, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []int64{1}, responseIDs(t, w.Body.Bytes()))
+	w = housekeepingRequest(router, "GET", "/api/admin/bookings/room?room_number=101&status=pending", 1, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []int64{1}, responseIDs(t, w.Body.Bytes()), "房间号相同也只返回当前酒店的预订")
+
+	assert.Equal(t, http.StatusNotFound, housekeepingRequest(router, "POST", "/api/admin/bookings/2/confirm", 1, nil).Code)
+	assert.Equal(t, http.StatusNotFound, housekeepingRequest(router, "POST", "/api/admin/bookings/2/checkin", 1, nil).Code)
+	var booking models.Booking
+	assert.NoError(t, db.First(&booking, 2).Error)
+	assert.Equal(t, "pending", booking.Status)
+
+	assert.Equal(t, http.StatusOK, housekeepingRequest(router, "POST", "/api/admin/bookings/1/confirm", 1, nil).Code)
+}
+
+func TestHotelScope_WorkOrders(t *testing.T) {
+	router, db := setupHotelScopeRouter(t)
+
+	w := housekeepingRequest(router, "GET", "/api/admin/work-orders", 1, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []int64{1}, responseIDs(t, w.Body.Bytes()))
+
+	assert.Equal(t, http.StatusNotFound, housekeepingRequest(router, "GET", "/api/admin/work-orders/2", 1, nil).Code)
+	w = housekeepingRequest(router, "POST", "/api/admin/work-orders/2", 1, map[string]string{"status": "resolved"})
+	assert.Equal(t, http.StatusNotFound, w.Code, "不能修改其他酒店的工单")
+	var order models.WorkOrder
+	assert.NoError(t, db.First(&order, 2).Error)
+	assert.Equal(t, "open", order.Status)
+}
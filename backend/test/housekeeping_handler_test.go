@@ -0,0 +1,199 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gohotel/internal/handler"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/internal/service"
+	"gohotel/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// housekeepingUserHeader 测试中指定当前用户 ID 的请求头，未设置时为管理员（用户 1）
+const housekeepingUserHeader = "X-Test-User"
+
+// setupHousekeepingRouter 初始化内存数据库并配置清洁任务路由
+//
+// 用户 1 为管理员，用户 2、3 为保洁员，用户 3 属于酒店 2；房间 1、2 属于酒店 1，房间 3 属于酒店 2
+func setupHousekeepingRouter(t *testing.T) (*gin.Engine, *gorm.DB, *service.HousekeepingService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法连接到内存数据库: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Room{}, &models.RoomPhoto{}, &models.Amenity{}, &models.HotelStaff{}, &models.HousekeepingTask{}); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+
+	for i, name := range []string{"admin", "cleaner1", "cleaner2"} {
+		assert.NoError(t, db.Create(&models.User{
+			ID: utils.JSONInt64(i + 1), Username: name, Email: name + "@example.com", Password: "x", Status: "active",
+		}).Error)
+	}
+	assert.NoError(t, db.Create(&[]models.HotelStaff{
+		{HotelID: 1, UserID: 1}, {HotelID: 1, UserID: 2}, {HotelID: 2, UserID: 3},
+	}).Error)
+	rooms := []models.Room{
+		{ID: 1, HotelID: 1, RoomNumber: "101", HousekeepingStatus: "inspected"},
+		{ID: 2, HotelID: 1, RoomNumber: "102", HousekeepingStatus: "inspected"},
+		{ID: 3, HotelID: 2, RoomNumber: "201", HousekeepingStatus: "inspected"},
+	}
+	for i := range rooms {
+		rooms[i].RoomType = "标准间"
+		rooms[i].Floor = 1
+		rooms[i].Price = 200
+		rooms[i].Capacity = 2
+	}
+	assert.NoError(t, db.Create(&rooms).Error)
+
+	housekeepingService := service.NewHousekeepingService(repository.NewHousekeepingRepository(db),
+		repository.NewRoomRepository(db), repository.NewUserRepository(db), repository.NewHotelRepository(db))
+	housekeepingHandler := handler.NewHousekeepingHandler(housekeepingService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		userID := int64(1)
+		if header := c.GetHeader(housekeepingUserHeader); header != "" {
+			userID, _ = strconv.ParseInt(header, 10, 64)
+		}
+		c.Set("user_id", userID)
+		c.Set("hotel_id", uint(1))
+		c.Next()
+	})
+	router.GET("/api/housekeeping/tasks/my", housekeepingHandler.GetMyTasks)
+	router.POST("/api/housekeeping/tasks/:id/start", housekeepingHandler.StartTask)
+	router.POST("/api/housekeeping/tasks/:id/complete", housekeepingHandler.CompleteTask)
+	router.POST("/api/admin/rooms/:id/housekeeping", housekeepingHandler.UpdateRoomHousekeepingStatus)
+	router.GET("/api/admin/housekeeping/tasks", housekeepingHandler.ListTasks)
+	router.POST("/api/admin/housekeeping/tasks", housekeepingHandler.CreateTask)
+	router.POST("/api/admin/housekeeping/tasks/:id/assign", housekeepingHandler.AssignTask)
+	router.POST("/api/admin/housekeeping/tasks/:id/inspect", housekeepingHandler.InspectTask)
+	return router, db, housekeepingService
+}
+
+// housekeepingRequest 以指定用户发送请求，body 为字符串时原样发送
+func housekeepingRequest(router *gin.Engine, method, url string, userID int64, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	switch b := body.(type) {
+	case nil:
+	case string:
+		payload = []byte(b)
+	default:
+		payload, _ = json.Marshal(b)
+	}
+	req, _ := http.NewRequest(method, url, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(housekeepingUserHeader, strconv.FormatInt(userID, 10))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// roomHousekeepingStatus 读取房间当前的清洁状态
+func roomHousekeepingStatus(t *testing.T, db *gorm.DB, roomID uint) string {
+	var room models.Room
+	assert.NoError(t, db.First(&room, roomID).Error)
+	return room.HousekeepingStatus
+}
+
+func TestHousekeeping_TaskLifecycle(t *testing.T) {
+	router, db, _ := setupHousekeepingRouter(t)
+
+	w := housekeepingRequest(router, "POST", "/api/admin/housekeeping/tasks", 1, map[string]interface{}{
+		"room_id": 1, "task_type": "deep",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var created struct {
+		Data models.HousekeepingTask `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	taskURL := fmt.Sprintf("/api/admin/housekeeping/tasks/%d", created.Data.ID)
+	cleanerURL := fmt.Sprintf("/api/housekeeping/tasks/%d", created.Data.ID)
+	assert.Equal(t, "dirty", roomHousekeepingStatus(t, db, 1))
+
+	// 同一房间不能同时有两个未完成的任务
+	w = housekeepingRequest(router, "POST", "/api/admin/housekeeping/tasks", 1, map[string]interface{}{
+		"room_id": 1, "task_type": "stayover",
+	})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	assert.Equal(t, http.StatusOK, housekeepingRequest(router, "POST", taskURL+"/assign", 1, map[string]string{"assignee_id": "2"}).Code)
+	assert.Equal(t, http.StatusForbidden, housekeepingRequest(router, "POST", cleanerURL+"/start", 3, nil).Code, "任务未分配给该保洁员")
+	assert.Equal(t, http.StatusBadRequest, housekeepingRequest(router, "POST", cleanerURL+"/complete", 2, nil).Code, "尚未开始清扫")
+
+	assert.Equal(t, http.StatusOK, housekeepingRequest(router, "POST", cleanerURL+"/start", 2, nil).Code)
+	assert.Equal(t, "cleaning", roomHousekeepingStatus(t, db, 1))
+
+	assert.Equal(t, http.StatusBadRequest, housekeepingRequest(router, "POST", cleanerURL+"/complete", 2, "{bad json").Code)
+	assert.Equal(t, http.StatusOK, housekeepingRequest(router, "POST", cleanerURL+"/complete", 2, nil).Code, "备注可选")
+	assert.Equal(t, "clean", roomHousekeepingStatus(t, db, 1))
+
+	// 查房不合格退回待清扫
+	w = housekeepingRequest(router, "POST", taskURL+"/inspect", 1, map[string]interface{}{"passed": false, "note": "地面未清洁"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "dirty", roomHousekeepingStatus(t, db, 1))
+
+	assert.Equal(t, http.StatusOK, housekeepingRequest(router, "POST", cleanerURL+"/start", 2, nil).Code)
+	assert.Equal(t, http.StatusOK, housekeepingRequest(router, "POST", cleanerURL+"/complete", 2, map[string]string{"note": "已返工"}).Code)
+	w = housekeepingRequest(router, "POST", taskURL+"/inspect", 1, map[string]interface{}{"passed": true})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "inspected", roomHousekeepingStatus(t, db, 1))
+
+	// 查房通过后可以为该房间创建新任务
+	w = housekeepingRequest(router, "POST", "/api/admin/housekeeping/tasks", 1, map[string]interface{}{
+		"room_id": 1, "task_type": "stayover",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHousekeeping_CheckoutTaskFollowsLatestBooking(t *testing.T) {
+	router, db, housekeepingService := setupHousekeepingRouter(t)
+
+	assert.NoError(t, housekeepingService.CreateCheckoutTask(2, 1001))
+	var task models.HousekeepingTask
+	assert.NoError(t, db.Where("room_id = ?", 2).First(&task).Error)
+	assert.Equal(t, int64(1001), task.BookingID.Int64())
+
+	taskURL := fmt.Sprintf("/api/housekeeping/tasks/%d", task.ID)
+	assert.Equal(t, http.StatusOK, housekeepingRequest(router, "POST", fmt.Sprintf("/api/admin/housekeeping/tasks/%d/assign", task.ID), 1,
+		map[string]string{"assignee_id": "2"}).Code)
+	assert.Equal(t, http.StatusOK, housekeepingRequest(router, "POST", taskURL+"/start", 2, nil).Code)
+
+	// 清扫未完成时下一位住客又退房，复用任务并关联新的预订
+	assert.NoError(t, housekeepingService.CreateCheckoutTask(2, 1002))
+	var tasks []models.HousekeepingTask
+	assert.NoError(t, db.Where("room_id = ?", 2).Find(&tasks).Error)
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, int64(1002), tasks[0].BookingID.Int64())
+	assert.Equal(t, "pending", tasks[0].Status)
+	assert.Nil(t, tasks[0].StartedAt)
+	assert.Equal(t, "dirty", roomHousekeepingStatus(t, db, 2))
+}
+
+func TestHousekeeping_HotelScope(t *testing.T) {
+	router, _, _ := setupHousekeepingRouter(t)
+
+	w := housekeepingRequest(router, "POST", "/api/admin/housekeeping/tasks", 1, map[string]interface{}{
+		"room_id": 3, "task_type": "deep",
+	})
+	assert.Equal(t, http.StatusNotFound, w.Code, "不能为其他酒店的房间创建任务")
+	w = housekeepingRequest(router, "POST", "/api/admin/housekeeping/tasks", 1, map[string]interface{}{
+		"room_id": 1, "task_type": "deep", "assignee_id": "3",
+	})
+	assert.Equal(t, http.StatusNotFound, w.Code, "不能把任务分配给其他酒店的员工")
+	w = housekeepingRequest(router, "POST", "/api/admin/rooms/3/housekeeping", 1, map[string]string{"status": "dirty"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = housekeepingRequest(router, "POST", "/api/admin/rooms/1/housekeeping", 1, map[string]string{"status": "unknown"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,122 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"gohotel/internal/models"
+	"gohotel/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// loginWithCaptcha 附带图形验证码登录测试用户，避免失败次数过多后被验证码拦截
+func loginWithCaptcha(t *testing.T, env *authTestEnv, password string) *httptest.ResponseRecorder {
+	id, answer := getCaptcha(t, env.router, env.captchas)
+	return authRequest(env.router, "POST", "/api/auth/login", "", map[string]string{
+		"username": "alice", "password": password, "captcha_id": id, "captcha_answer": answer,
+	})
+}
+
+func TestLoginThrottle_AccountLockoutWithBackoff(t *testing.T) {
+	env := setupAuthRouter(t)
+
+	// 拥有 user:update 权限的管理员和超级管理员都会收到锁定通知
+	role := models.Role{Code: "ops", Name: "运维"}
+	assert.NoError(t, env.db.Create(&role).Error)
+	assert.NoError(t, env.db.Create(&models.RolePermission{RoleID: role.ID, Permission: models.PermUserUpdate}).Error)
+	assert.NoError(t, env.db.Create(&models.User{ID: 2, Username: "admin", Email: "admin@example.com", Password: "x", Status: "active"}).Error)
+	assert.NoError(t, env.db.Create(&models.UserRole{UserID: 2, RoleID: role.ID}).Error)
+	superRole := models.Role{Code: "super_admin", Name: "超级管理员"}
+	assert.NoError(t, env.db.Create(&superRole).Error)
+	assert.NoError(t, env.db.Create(&models.RolePermission{RoleID: superRole.ID, Permission: models.PermissionAll}).Error)
+	assert.NoError(t, env.db.Create(&models.User{ID: 3, Username: "root", Email: "root@example.com", Password: "x", Status: "active"}).Error)
+	assert.NoError(t, env.db.Create(&models.UserRole{UserID: 3, RoleID: superRole.ID}).Error)
+
+	assert.Equal(t, http.StatusUnauthorized, loginWithCaptcha(t, env, "wrong").Code)
+	assert.Equal(t, http.StatusUnauthorized, loginWithCaptcha(t, env, "wrong").Code)
+	w := loginWithCaptcha(t, env, "wrong")
+	assert.Equal(t, http.StatusLocked, w.Code)
+	assert.Equal(t, "ACCOUNT_LOCKED", errorCode(w))
+
+	// 锁定期间正确的密码也无法登录
+	assert.Equal(t, http.StatusLocked, loginWithCaptcha(t, env, authTestPassword).Code)
+	assert.Len(t, env.outbox.messages["admin@example.com"], 1)
+	assert.Len(t, env.outbox.messages["root@example.com"], 1)
+	var logCount int64
+	env.db.Model(&models.Log{}).Count(&logCount)
+	assert.Zero(t, logCount, "锁定记录不写入日志表")
+
+	var lockout models.LoginLockout
+	assert.NoError(t, env.db.First(&lockout, "user_id = ?", 1).Error)
+	assert.InDelta(t, time.Minute.Seconds(), time.Until(*lockout.LockedUntil).Seconds(), 5)
+
+	// 锁定到期后再次连续失败，锁定时长翻倍
+	assert.NoError(t, env.db.Model(&lockout).Update("locked_until", time.Now().Add(-time.Second)).Error)
+	for i := 0; i < 3; i++ {
+		loginWithCaptcha(t, env, "wrong")
+	}
+	assert.NoError(t, env.db.First(&lockout, "user_id = ?", 1).Error)
+	assert.Equal(t, 2, lockout.LockCount)
+	assert.InDelta(t, (2 * time.Minute).Seconds(), time.Until(*lockout.LockedUntil).Seconds(), 5)
+
+	w = authRequest(env.router, "GET", "/api/admin/users/locked", "", nil)
+	var locked struct {
+		Data []service.LockedAccount `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &locked))
+	assert.Len(t, locked.Data, 1)
+	assert.Equal(t, "alice", locked.Data[0].Username)
+
+	// 管理员解锁后可以立即登录
+	assert.Equal(t, http.StatusOK, authRequest(env.router, "POST", "/api/admin/users/1/unlock", "", nil).Code)
+	assert.Equal(t, http.StatusOK, loginWithCaptcha(t, env, authTestPassword).Code)
+}
+
+func TestLoginThrottle_CaptchaUsesStoredFailures(t *testing.T) {
+	env := setupAuthRouter(t)
+
+	// 失败记录由其他实例写入数据库，本实例同样要求验证码
+	now := time.Now()
+	assert.NoError(t, env.db.Create(&models.LoginLockout{UserID: 1, FailedCount: authCaptchaThreshold, LastFailedAt: &now}).Error)
+	w := authRequest(env.router, "POST", "/api/auth/login", "", map[string]string{"username": "alice", "password": authTestPassword})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "CAPTCHA_REQUIRED", errorCode(w))
+
+	// 超过统计窗口的失败记录不再要求验证码
+	expired := now.Add(-2 * time.Minute)
+	assert.NoError(t, env.db.Model(&models.LoginLockout{}).Where("user_id = ?", 1).Update("last_failed_at", expired).Error)
+	code, _ := login(t, env.router, authTestPassword)
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestLoginThrottle_IPLimit(t *testing.T) {
+	env := setupAuthRouter(t)
+
+	for i := 0; i < 10; i++ {
+		code, _ := loginAs(t, env.router, fmt.Sprintf("ghost%d", i), "wrong")
+		assert.Equal(t, http.StatusUnauthorized, code)
+	}
+
+	// 同一 IP 暂停登录，正确的账号密码也会被拒绝
+	code, _ := login(t, env.router, authTestPassword)
+	assert.Equal(t, http.StatusTooManyRequests, code)
+}
+
+func TestLoginThrottle_BlockedEnforcedOnEveryRequest(t *testing.T) {
+	env := setupAuthRouter(t)
+	_, tokens := login(t, env.router, authTestPassword)
+
+	// 直接在数据库中封禁，未经过撤销会话的流程
+	assert.NoError(t, env.db.Model(&models.User{}).Where("id = ?", 1).Update("status", "blocked").Error)
+	w := authRequest(env.router, "GET", "/api/users/profile", tokens.Token, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// 解封后立即恢复
+	w = authRequest(env.router, "POST", "/api/admin/users/1/status", "", map[string]string{"status": "active"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusOK, authRequest(env.router, "GET", "/api/users/profile", tokens.Token, nil).Code)
+}
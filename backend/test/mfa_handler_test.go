@@ -0,0 +1,274 @@
+package test
+
+import (
+	"encoding/json"
+	"gohotel/internal/handler"
+	"gohotel/internal/middleware"
+	"gohotel/internal/models"
+	"gohotel/internal/service"
+	"gohotel/pkg/utils"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setupMFARouter 在认证测试环境上注册两步验证路由
+func setupMFARouter(t *testing.T) *authTestEnv {
+	env := setupAuthRouter(t)
+	mfaHandler := handler.NewMFAHandler(env.mfa)
+
+	authRequired := middleware.AuthMiddleware(env.tokens)
+	env.router.POST("/api/auth/mfa/verify", mfaHandler.VerifyLogin)
+	env.router.POST("/api/auth/mfa/setup", mfaHandler.SetupForLogin)
+	env.router.GET("/api/users/mfa", authRequired, mfaHandler.GetStatus)
+	env.router.POST("/api/users/mfa/setup", authRequired, mfaHandler.Setup)
+	env.router.POST("/api/users/mfa/enable", authRequired, mfaHandler.Enable)
+	env.router.POST("/api/users/mfa/disable", authRequired, mfaHandler.Disable)
+	env.router.POST("/api/users/mfa/recovery-codes", authRequired, mfaHandler.RegenerateRecoveryCodes)
+	env.router.POST("/api/admin/users/:id/mfa/reset", mfaHandler.ResetUserMFA)
+	return env
+}
+
+// totpCode 计算当前时间偏移 offset 个时间步的动态码
+func totpCode(t *testing.T, secret string, offset int64) string {
+	code, err := utils.TOTPCode(secret, utils.TOTPStep(time.Now())+offset)
+	assert.NoError(t, err)
+	return code
+}
+
+// mfaLogin 使用密码登录，返回登录响应
+func mfaLogin(t *testing.T, env *authTestEnv) service.LoginResponse {
+	w := authRequest(env.router, "POST", "/api/auth/login", "", map[string]string{
+		"username": "alice", "password": authTestPassword,
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data service.LoginResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp.Data
+}
+
+// mfaVerify 提交登录第二步验证
+func mfaVerify(t *testing.T, env *authTestEnv, mfaToken, code string) (int, service.MFAVerifyResponse) {
+	w := authRequest(env.router, "POST", "/api/auth/mfa/verify", "", map[string]string{
+		"mfa_token": mfaToken, "code": code,
+	})
+	var resp struct {
+		Data service.MFAVerifyResponse `json:"data"`
+	}
+	if w.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w.Code, resp.Data
+}
+
+// enrolMFA 以已登录用户绑定并启用身份验证器，返回密钥和恢复码
+func enrolMFA(t *testing.T, env *authTestEnv, token string) (string, []string) {
+	w := authRequest(env.router, "POST", "/api/users/mfa/setup", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var setup struct {
+		Data service.MFASetupResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &setup))
+	assert.True(t, strings.HasPrefix(setup.Data.URI, "otpauth://totp/GoHotel:alice?"))
+	assert.Contains(t, setup.Data.URI, "secret="+setup.Data.Secret)
+	assert.True(t, strings.HasPrefix(setup.Data.QRCode, "data:image/png;base64,"))
+
+	w = authRequest(env.router, "POST", "/api/users/mfa/enable", token, map[string]string{"code": "000000"})
+	if totpCode(t, setup.Data.Secret, 0) != "000000" {
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	}
+
+	w = authRequest(env.router, "POST", "/api/users/mfa/enable", token, map[string]string{"code": totpCode(t, setup.Data.Secret, 0)})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var enabled struct {
+		Data service.MFARecoveryCodesResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &enabled))
+	assert.Len(t, enabled.Data.RecoveryCodes, 4)
+	return setup.Data.Secret, enabled.Data.RecoveryCodes
+}
+
+func TestTOTP_RFC6238Vectors(t *testing.T) {
+	// RFC 6238 附录 B 的 SHA1 测试向量（取后 6 位）
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" // "12345678901234567890" 的 Base32
+	cases := map[int64]string{59: "287082", 1111111109: "081804", 1234567890: "005924", 2000000000: "279037"}
+	for unix, expected := range cases {
+		code, err := utils.TOTPCode(secret, utils.TOTPStep(time.Unix(unix, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, code)
+	}
+
+	step, ok := utils.VerifyTOTP(secret, "287082", time.Unix(89, 0), 1)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), step)
+	_, ok = utils.VerifyTOTP(secret, "287082", time.Unix(150, 0), 1)
+	assert.False(t, ok)
+}
+
+func TestMFA_OptionalEnrolmentMakesLoginTwoStep(t *testing.T) {
+	env := setupMFARouter(t)
+
+	code, tokens := login(t, env.router, authTestPassword)
+	assert.Equal(t, http.StatusOK, code)
+	secret, recoveryCodes := enrolMFA(t, env, tokens.Token)
+
+	w := authRequest(env.router, "GET", "/api/users/mfa", tokens.Token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"enabled":true`)
+	assert.Contains(t, w.Body.String(), `"recovery_codes_left":4`)
+
+	// 启用后密码登录只返回第二步验证令牌
+	resp := mfaLogin(t, env)
+	assert.Nil(t, resp.TokenPair)
+	assert.Nil(t, resp.User)
+	if assert.NotNil(t, resp.MFA) {
+		assert.False(t, resp.MFA.SetupRequired)
+		assert.NotEmpty(t, resp.MFA.MFAToken)
+	}
+
+	// 第二步验证令牌不能当作访问令牌使用
+	w = authRequest(env.router, "GET", "/api/users/profile", resp.MFA.MFAToken, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// 启用时使用过的动态码不能重复使用
+	status, _ := mfaVerify(t, env, resp.MFA.MFAToken, totpCode(t, secret, 0))
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	status, verified := mfaVerify(t, env, resp.MFA.MFAToken, totpCode(t, secret, 1))
+	assert.Equal(t, http.StatusOK, status)
+	if assert.NotNil(t, verified.TokenPair) {
+		assert.NotEmpty(t, verified.Token)
+		w = authRequest(env.router, "GET", "/api/users/profile", verified.Token, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	assert.Empty(t, verified.RecoveryCodes)
+
+	// 完成后的第二步验证令牌不能再次使用
+	status, _ = mfaVerify(t, env, resp.MFA.MFAToken, recoveryCodes[0])
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	// 恢复码大小写和分隔符不敏感，且只能使用一次
+	resp = mfaLogin(t, env)
+	status, _ = mfaVerify(t, env, resp.MFA.MFAToken, strings.ToUpper(strings.ReplaceAll(recoveryCodes[0], "-", "")))
+	assert.Equal(t, http.StatusOK, status)
+	resp = mfaLogin(t, env)
+	status, _ = mfaVerify(t, env, resp.MFA.MFAToken, recoveryCodes[0])
+	assert.Equal(t, http.StatusUnauthorized, status)
+	status, _ = mfaVerify(t, env, resp.MFA.MFAToken, recoveryCodes[1])
+	assert.Equal(t, http.StatusOK, status)
+}
+
+func TestMFA_ChallengeExpiresAfterTooManyFailures(t *testing.T) {
+	env := setupMFARouter(t)
+	_, tokens := login(t, env.router, authTestPassword)
+	_, recoveryCodes := enrolMFA(t, env, tokens.Token)
+
+	resp := mfaLogin(t, env)
+	for i := 0; i < 3; i++ {
+		status, _ := mfaVerify(t, env, resp.MFA.MFAToken, "abcde-fghij")
+		assert.Equal(t, http.StatusUnauthorized, status)
+	}
+	status, _ := mfaVerify(t, env, resp.MFA.MFAToken, recoveryCodes[0])
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	// 重新登录后可以继续验证
+	resp = mfaLogin(t, env)
+	status, _ = mfaVerify(t, env, resp.MFA.MFAToken, recoveryCodes[0])
+	assert.Equal(t, http.StatusOK, status)
+}
+
+func TestMFA_RoleRequirementForcesEnrolmentAtLogin(t *testing.T) {
+	env := setupMFARouter(t)
+	role := &models.Role{Code: "auditor", Name: "审计", RequireMFA: true}
+	assert.NoError(t, env.db.Create(role).Error)
+	assert.NoError(t, env.db.Create(&models.UserRole{UserID: 1, RoleID: role.ID}).Error)
+
+	resp := mfaLogin(t, env)
+	assert.Nil(t, resp.TokenPair)
+	if !assert.NotNil(t, resp.MFA) {
+		return
+	}
+	assert.True(t, resp.MFA.SetupRequired)
+
+	w := authRequest(env.router, "POST", "/api/auth/mfa/setup", "", map[string]string{"mfa_token": resp.MFA.MFAToken})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var setup struct {
+		Data service.MFASetupResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &setup))
+
+	// 绑定时只接受动态码，完成后签发令牌并返回恢复码
+	status, verified := mfaVerify(t, env, resp.MFA.MFAToken, totpCode(t, setup.Data.Secret, 0))
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, verified.RecoveryCodes, 4)
+	if !assert.NotNil(t, verified.TokenPair) {
+		return
+	}
+
+	w = authRequest(env.router, "GET", "/api/users/mfa", verified.Token, nil)
+	assert.Contains(t, w.Body.String(), `"required":true`)
+
+	// 角色要求两步验证时不能关闭
+	w = authRequest(env.router, "POST", "/api/users/mfa/disable", verified.Token, map[string]string{
+		"password": authTestPassword, "code": verified.RecoveryCodes[0],
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// 下次登录直接要求动态码
+	resp = mfaLogin(t, env)
+	if assert.NotNil(t, resp.MFA) {
+		assert.False(t, resp.MFA.SetupRequired)
+	}
+	w = authRequest(env.router, "POST", "/api/auth/mfa/setup", "", map[string]string{"mfa_token": resp.MFA.MFAToken})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestMFA_DisableRegenerateAndAdminReset(t *testing.T) {
+	env := setupMFARouter(t)
+	_, tokens := login(t, env.router, authTestPassword)
+	secret, recoveryCodes := enrolMFA(t, env, tokens.Token)
+
+	// 重新生成恢复码后旧恢复码作废
+	w := authRequest(env.router, "POST", "/api/users/mfa/recovery-codes", tokens.Token, map[string]string{"code": recoveryCodes[0]})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = authRequest(env.router, "POST", "/api/users/mfa/recovery-codes", tokens.Token, map[string]string{"code": totpCode(t, secret, 1)})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var regenerated struct {
+		Data service.MFARecoveryCodesResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &regenerated))
+	assert.Len(t, regenerated.Data.RecoveryCodes, 4)
+	assert.NotContains(t, regenerated.Data.RecoveryCodes, recoveryCodes[0])
+
+	// 关闭需要正确的密码
+	w = authRequest(env.router, "POST", "/api/users/mfa/disable", tokens.Token, map[string]string{
+		"password": "wrong-password", "code": regenerated.Data.RecoveryCodes[0],
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = authRequest(env.router, "POST", "/api/users/mfa/disable", tokens.Token, map[string]string{
+		"password": authTestPassword, "code": recoveryCodes[1],
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = authRequest(env.router, "POST", "/api/users/mfa/disable", tokens.Token, map[string]string{
+		"password": authTestPassword, "code": regenerated.Data.RecoveryCodes[0],
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	code, _ := login(t, env.router, authTestPassword)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Nil(t, mfaLogin(t, env).MFA)
+
+	// 管理员重置后不再需要第二步验证
+	enrolMFA(t, env, tokens.Token)
+	assert.NotNil(t, mfaLogin(t, env).MFA)
+	w = authRequest(env.router, "POST", "/api/admin/users/1/mfa/reset", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	resp := mfaLogin(t, env)
+	assert.Nil(t, resp.MFA)
+	assert.NotNil(t, resp.TokenPair)
+}
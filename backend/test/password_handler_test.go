@@ -0,0 +1,111 @@
+package test
+
+import (
+	"encoding/json"
+	"gohotel/internal/models"
+	"gohotel/internal/service"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// addUser 通过管理接口创建用户
+func addUser(t *testing.T, router *gin.Engine, body map[string]interface{}) service.AddUserResponse {
+	w := authRequest(router, "POST", "/api/admin/users/user", "", body)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data service.AddUserResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp.Data
+}
+
+func TestPassword_ForgotAndReset(t *testing.T) {
+	env := setupAuthRouter(t)
+	_, old := login(t, env.router, authTestPassword)
+
+	// 账号不存在时同样返回成功，但不发送
+	w := authRequest(env.router, "POST", "/api/auth/password/forgot", "", map[string]string{"account": "nobody"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, env.outbox.messages)
+
+	w = authRequest(env.router, "POST", "/api/auth/password/forgot", "", map[string]string{"account": "alice"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	token := env.outbox.lastLinkToken("alice@example.com")
+	assert.NotEmpty(t, token)
+
+	// 间隔内重复申请不会再次发送
+	authRequest(env.router, "POST", "/api/auth/password/forgot", "", map[string]string{"account": "alice@example.com"})
+	assert.Len(t, env.outbox.messages["alice@example.com"], 1)
+
+	w = authRequest(env.router, "POST", "/api/auth/password/reset", "", map[string]string{
+		"token": token, "new_password": "resetpass1",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 令牌只能使用一次，旧会话失效，新密码生效
+	w = authRequest(env.router, "POST", "/api/auth/password/reset", "", map[string]string{
+		"token": token, "new_password": "resetpass2",
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, http.StatusUnauthorized, authRequest(env.router, "GET", "/api/users/profile", old.Token, nil).Code)
+	code, _ := login(t, env.router, "resetpass1")
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestPassword_FirstLoginMustChangePassword(t *testing.T) {
+	env := setupAuthRouter(t)
+
+	created := addUser(t, env.router, map[string]interface{}{"username": "staff01", "email": "staff01@example.com", "role": "admin"})
+	assert.Equal(t, "user", created.User.Role, "添加用户时不能指定角色")
+	assert.Len(t, created.InitialPassword, 12)
+	assert.True(t, created.User.FirstLogin)
+
+	code, tokens := loginAs(t, env.router, "staff01", created.InitialPassword)
+	assert.Equal(t, http.StatusOK, code)
+
+	// 修改密码前只能查看资料、修改密码
+	assert.Equal(t, http.StatusOK, authRequest(env.router, "GET", "/api/users/profile", tokens.Token, nil).Code)
+	w := authRequest(env.router, "POST", "/api/users/profile", tokens.Token, map[string]string{"real_name": "Staff"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "PASSWORD_CHANGE_REQUIRED", errorCode(w))
+
+	w = authRequest(env.router, "POST", "/api/users/password", tokens.Token, map[string]string{
+		"old_password": created.InitialPassword, "new_password": "mypassword1",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	code, tokens = loginAs(t, env.router, "staff01", "mypassword1")
+	assert.Equal(t, http.StatusOK, code)
+	w = authRequest(env.router, "POST", "/api/users/profile", tokens.Token, map[string]string{"real_name": "Staff"})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestPassword_InviteLink(t *testing.T) {
+	env := setupAuthRouter(t)
+
+	created := addUser(t, env.router, map[string]interface{}{
+		"username": "staff02", "email": "staff02@example.com", "invite": true,
+	})
+	assert.Empty(t, created.InitialPassword)
+	assert.NotNil(t, created.InviteExpiresAt)
+
+	token := env.outbox.lastLinkToken("staff02@example.com")
+	assert.NotEmpty(t, token)
+	w := authRequest(env.router, "POST", "/api/auth/password/reset", "", map[string]string{
+		"token": token, "new_password": "invited123",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 通过邀请链接设置密码后不再要求修改密码
+	code, tokens := loginAs(t, env.router, "staff02", "invited123")
+	assert.Equal(t, http.StatusOK, code)
+	w = authRequest(env.router, "POST", "/api/users/profile", tokens.Token, map[string]string{"real_name": "Staff"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var user models.User
+	assert.NoError(t, env.db.Where("username = ?", "staff02").First(&user).Error)
+	assert.False(t, user.FirstLogin)
+}
@@ -0,0 +1,217 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gohotel/internal/config"
+	"gohotel/internal/handler"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/internal/service"
+	"gohotel/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupPricingRouter 初始化内存数据库并配置动态定价路由
+//
+// 酒店 1 有 4 间标准间（最低价 100）和 1 间套房；
+// 5 天后的那一晚有 3 间标准间被预订（入住率 75%），6 天后有 1 间（25%）
+func setupPricingRouter(t *testing.T) (*gin.Engine, *service.PricingService, *gorm.DB, time.Time) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法连接到内存数据库: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Room{}, &models.Booking{},
+		&models.PricingRule{}, &models.RoomRate{}, &models.PriceChangeLog{}, &models.StayDiscount{},
+		&models.LongStayPolicy{}); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+
+	rooms := []models.Room{
+		{ID: 1, RoomNumber: "101", RoomType: "标准间", Price: 100},
+		{ID: 2, RoomNumber: "102", RoomType: "标准间", Price: 100},
+		{ID: 3, RoomNumber: "103", RoomType: "标准间", Price: 120},
+		{ID: 4, RoomNumber: "104", RoomType: "标准间", Price: 120},
+		{ID: 5, RoomNumber: "501", RoomType: "套房", Price: 500},
+	}
+	for i := range rooms {
+		rooms[i].HotelID = 1
+		rooms[i].Floor = 1
+		rooms[i].Capacity = 2
+	}
+	assert.NoError(t, db.Create(&rooms).Error)
+
+	now := time.Now()
+	busy := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 5)
+	bookings := []models.Booking{
+		{RoomID: 1, CheckIn: busy, CheckOut: busy.AddDate(0, 0, 2), Status: "confirmed"},
+		{RoomID: 2, CheckIn: busy, CheckOut: busy.AddDate(0, 0, 1), Status: "pending"},
+		{RoomID: 3, CheckIn: busy.AddDate(0, 0, -1), CheckOut: busy.AddDate(0, 0, 1), Status: "checkin"},
+		{RoomID: 4, CheckIn: busy, CheckOut: busy.AddDate(0, 0, 1), Status: "cancelled"},
+	}
+	for i := range bookings {
+		bookings[i].ID = utils.JSONInt64(i + 1)
+		bookings[i].BookingNumber = utils.JSONInt64(i + 1)
+		bookings[i].HotelID = 1
+		bookings[i].UserID = 1
+		bookings[i].GuestName = "张三"
+		bookings[i].GuestPhone = "13800000000"
+	}
+	assert.NoError(t, db.Create(&bookings).Error)
+
+	pricingService := service.NewPricingService(repository.NewPricingRepository(db), repository.NewHotelRepository(db),
+		utils.NewMultiTimeWheel(), &config.PricingConfig{Interval: time.Hour, HorizonDays: 10})
+	pricingHandler := handler.NewPricingHandler(pricingService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("hotel_id", uint(1))
+		c.Next()
+	})
+	router.POST("/api/admin/pricing/rules", pricingHandler.CreateRule)
+	router.GET("/api/admin/pricing/preview", pricingHandler.Preview)
+	router.POST("/api/admin/pricing/evaluate", pricingHandler.Evaluate)
+	router.GET("/api/admin/pricing/calendar", pricingHandler.GetCalendar)
+	router.GET("/api/admin/pricing/logs", pricingHandler.ListChangeLogs)
+	router.GET("/api/admin/pricing/long-stay-policies", pricingHandler.ListLongStayPolicies)
+	router.POST("/api/admin/pricing/long-stay-policies", pricingHandler.SaveLongStayPolicy)
+	router.POST("/api/admin/pricing/long-stay-policies/:id/delete", pricingHandler.DeleteLongStayPolicy)
+	return router, pricingService, db, busy
+}
+
+// createPricingRule 通过接口创建定价规则
+func createPricingRule(t *testing.T, router *gin.Engine, rule map[string]interface{}) {
+	body, _ := json.Marshal(rule)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/admin/pricing/rules", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestPricing_PreviewMatchesHighestThreshold(t *testing.T) {
+	router, _, _, busy := setupPricingRouter(t)
+	createPricingRule(t, router, map[string]interface{}{
+		"name": "旺日上浮", "min_occupancy": 50, "adjust_type": "percent", "adjust_value": 10,
+	})
+	createPricingRule(t, router, map[string]interface{}{
+		"name": "满房上浮", "room_type": "标准间", "min_occupancy": 70, "adjust_type": "percent", "adjust_value": 15, "max_price": 112,
+	})
+
+	day := busy.Format("2006-01-02")
+	next := busy.AddDate(0, 0, 2).Format("2006-01-02")
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/admin/pricing/preview?start_date="+day+"&end_date="+next+"&room_type=标准间", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Data []service.RatePreview `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), "JSON 反序列化失败")
+	assert.Len(t, response.Data, 2)
+
+	busyDay := response.Data[0]
+	assert.Equal(t, 3, busyDay.BookedRooms, "已取消的预订不占用房间")
+	assert.Equal(t, 75.0, busyDay.Occupancy)
+	assert.Equal(t, "满房上浮", busyDay.RuleName)
+	assert.Equal(t, 112.0, busyDay.ProposedPrice, "上浮 15% 后受价格上限限制")
+	assert.True(t, busyDay.Changed)
+
+	quietDay := response.Data[1]
+	assert.Equal(t, 25.0, quietDay.Occupancy)
+	assert.Nil(t, quietDay.RuleID)
+	assert.Equal(t, 100.0, quietDay.ProposedPrice)
+	assert.False(t, quietDay.Changed)
+}
+
+func TestPricing_EvaluateWritesCalendarAndLogs(t *testing.T) {
+	router, pricingService, db, busy := setupPricingRouter(t)
+	createPricingRule(t, router, map[string]interface{}{
+		"name": "旺日上浮", "min_occupancy": 70, "adjust_type": "amount", "adjust_value": 30,
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/admin/pricing/evaluate", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Data service.PricingEvaluation `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), "JSON 反序列化失败")
+	assert.Equal(t, 1, response.Data.PriceChanges, "只有标准间的旺日触发规则")
+
+	var rate models.RoomRate
+	assert.NoError(t, db.Where("room_type = ?", "标准间").First(&rate).Error)
+	assert.Equal(t, busy.Format("2006-01-02"), rate.Date.Format("2006-01-02"))
+	assert.Equal(t, 130.0, rate.Price)
+
+	// 再次重算没有变化时不重复记录
+	result, err := pricingService.EvaluateHotel(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.PriceChanges)
+
+	// 预订按日历逐晚计价：旺日 120+30，次日原价 120
+	total, err := pricingService.QuoteStay(&models.Room{HotelID: 1, RoomType: "标准间", Price: 120}, busy, busy.AddDate(0, 0, 2))
+	assert.NoError(t, err)
+	assert.Equal(t, 270.0, total)
+
+	// 取消预订后入住率下降，价格恢复并记录
+	assert.NoError(t, db.Model(&models.Booking{}).Where("id = ?", 1).Update("status", "cancelled").Error)
+	result, err = pricingService.EvaluateHotel(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.PriceChanges)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/admin/pricing/logs", nil)
+	router.ServeHTTP(w, req)
+	var logs struct {
+		Data []models.PriceChangeLog `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &logs), "JSON 反序列化失败")
+	assert.Len(t, logs.Data, 2)
+	assert.Equal(t, 100.0, logs.Data[0].NewPrice)
+	assert.Nil(t, logs.Data[0].RuleID)
+	assert.Equal(t, 130.0, logs.Data[1].NewPrice)
+}
+
+func TestPricing_LongStayPolicies(t *testing.T) {
+	router, _, db, _ := setupPricingRouter(t)
+	assert.NoError(t, db.Create(&models.LongStayPolicy{ID: 9, HotelID: 2, Deposit: 500}).Error)
+
+	save := func(body map[string]interface{}) int {
+		return housekeepingRequest(router, "POST", "/api/admin/pricing/long-stay-policies", 1, body).Code
+	}
+	assert.Equal(t, http.StatusOK, save(map[string]interface{}{"deposit": 1000}))
+	assert.Equal(t, http.StatusOK, save(map[string]interface{}{"room_type": "套房", "deposit": 3000}))
+	assert.Equal(t, http.StatusOK, save(map[string]interface{}{"room_type": "套房", "deposit": 2000}), "同一房型重复设置时更新")
+	assert.Equal(t, http.StatusBadRequest, save(map[string]interface{}{"deposit": -1}))
+
+	w := housekeepingRequest(router, "GET", "/api/admin/pricing/long-stay-policies", 1, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data []models.LongStayPolicy `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	if assert.Len(t, resp.Data, 2, "只返回当前酒店的政策") {
+		assert.Equal(t, 1000.0, resp.Data[0].Deposit)
+		assert.Equal(t, "套房", resp.Data[1].RoomType)
+		assert.Equal(t, 2000.0, resp.Data[1].Deposit)
+	}
+
+	w = housekeepingRequest(router, "POST", "/api/admin/pricing/long-stay-policies/9/delete", 1, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code, "不能删除其他酒店的政策")
+	w = housekeepingRequest(router, "POST", fmt.Sprintf("/api/admin/pricing/long-stay-policies/%d/delete", resp.Data[1].ID), 1, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,203 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"gohotel/internal/handler"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/internal/service"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupReviewRouter 初始化内存数据库并配置评价路由
+//
+// 用户 1 为 alice，用户 2 为 bob；预订 1（alice，标准间）、3（bob，大床房）已退房，
+// 预订 2（alice）尚未入住，预订 4（alice）属于酒店 2
+func setupReviewRouter(t *testing.T) (*gin.Engine, *fakeCOS) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法连接到内存数据库: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Room{}, &models.Booking{}, &models.BookingInstallment{}, &models.Review{}); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+
+	assert.NoError(t, db.Create(&[]models.User{
+		{ID: 1, Username: "alice", Email: "alice@example.com", Password: "x", Status: "active"},
+		{ID: 2, Username: "bob", Email: "bob@example.com", Password: "x", Status: "active"},
+	}).Error)
+	assert.NoError(t, db.Create(&[]models.Room{
+		{ID: 1, HotelID: 1, RoomNumber: "101", RoomType: "标准间", Floor: 1, Price: 200, Capacity: 2},
+		{ID: 2, HotelID: 1, RoomNumber: "102", RoomType: "大床房", Floor: 1, Price: 300, Capacity: 2},
+		{ID: 3, HotelID: 2, RoomNumber: "201", RoomType: "标准间", Floor: 2, Price: 200, Capacity: 2},
+	}).Error)
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+	bookings := []models.Booking{
+		{ID: 1, HotelID: 1, UserID: 1, RoomID: 1, Status: "checkout"},
+		{ID: 2, HotelID: 1, UserID: 1, RoomID: 1, Status: "confirmed"},
+		{ID: 3, HotelID: 1, UserID: 2, RoomID: 2, Status: "checkout"},
+		{ID: 4, HotelID: 2, UserID: 1, RoomID: 3, Status: "checkout"},
+	}
+	for i := range bookings {
+		bookings[i].BookingNumber = bookings[i].ID
+		bookings[i].BookingType = models.BookingTypeNightly
+		bookings[i].CheckIn = day.AddDate(0, 0, -2)
+		bookings[i].CheckOut = day
+		bookings[i].TotalDays = 2
+		bookings[i].GuestName = "张三"
+		bookings[i].GuestPhone = "13800000000"
+	}
+	assert.NoError(t, db.Create(&bookings).Error)
+
+	fake, cosService := newFakeCOS(t)
+	reviewHandler := handler.NewReviewHandler(service.NewReviewService(repository.NewReviewRepository(db),
+		repository.NewBookingRepository(db), repository.NewUserRepository(db), cosService))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		userID := int64(1)
+		if header := c.GetHeader(housekeepingUserHeader); header != "" {
+			userID, _ = strconv.ParseInt(header, 10, 64)
+		}
+		c.Set("user_id", userID)
+		c.Set("hotel_id", uint(1))
+		c.Next()
+	})
+	router.GET("/api/reviews", reviewHandler.ListReviews)
+	router.GET("/api/reviews/summary", reviewHandler.GetRatingSummary)
+	router.POST("/api/reviews", reviewHandler.CreateReview)
+	router.GET("/api/reviews/my", reviewHandler.GetMyReviews)
+	router.GET("/api/admin/reviews", reviewHandler.ListAllReviews)
+	router.POST("/api/admin/reviews/:id/hide", reviewHandler.HideReview)
+	router.POST("/api/admin/reviews/:id/show", reviewHandler.ShowReview)
+	router.POST("/api/admin/reviews/:id/reply", reviewHandler.ReplyReview)
+	return router, fake
+}
+
+// createReview 以指定用户评价预订，返回状态码和评价
+func createReview(t *testing.T, router *gin.Engine, userID, bookingID int64, scores [3]int, photos ...string) (int, models.Review) {
+	w := housekeepingRequest(router, "POST", "/api/reviews", userID, map[string]interface{}{
+		"booking_id": strconv.FormatInt(bookingID, 10), "cleanliness": scores[0], "service": scores[1], "location": scores[2],
+		"content": "干净整洁", "photo_temp_urls": photos,
+	})
+	var resp struct {
+		Data models.Review `json:"data"`
+	}
+	if w.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w.Code, resp.Data
+}
+
+// listReviews 查询评价列表，返回评价和总数
+func listReviews(t *testing.T, router *gin.Engine, url string) ([]models.Review, int64) {
+	w := housekeepingRequest(router, "GET", url, 1, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data []models.Review `json:"data"`
+		Page struct {
+			Total int64 `json:"total"`
+		} `json:"page"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp.Data, resp.Page.Total
+}
+
+func TestReview_CreateOnlyForCompletedStay(t *testing.T) {
+	router, fake := setupReviewRouter(t)
+
+	code, review := createReview(t, router, 1, 1, [3]int{5, 4, 4}, fake.putTemp("review", "a.jpg"))
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 4.3, review.Overall)
+	assert.Equal(t, "标准间", review.RoomType)
+	assert.Equal(t, "a***e", review.ReviewerName, "评价人昵称脱敏")
+	assert.JSONEq(t, fmt.Sprintf(`[%q]`, fake.url("reviews/a.jpg")), review.Photos)
+
+	code, _ = createReview(t, router, 1, 1, [3]int{5, 5, 5})
+	assert.Equal(t, http.StatusConflict, code, "每个预订只能评价一次")
+	code, _ = createReview(t, router, 1, 2, [3]int{5, 5, 5})
+	assert.Equal(t, http.StatusBadRequest, code, "未退房的预订不能评价")
+	code, _ = createReview(t, router, 1, 3, [3]int{5, 5, 5})
+	assert.Equal(t, http.StatusForbidden, code, "不能评价他人的预订")
+	code, _ = createReview(t, router, 2, 3, [3]int{6, 5, 5})
+	assert.Equal(t, http.StatusBadRequest, code, "评分范围 1-5")
+
+	// 图片确认失败时不创建评价，已确认的图片被删除
+	missing := strings.Replace(fake.putTemp("review", "x.jpg"), "x.jpg", "missing.jpg", 1)
+	code, _ = createReview(t, router, 2, 3, [3]int{3, 3, 3}, fake.putTemp("review", "b.jpg"), missing)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.False(t, fake.has("reviews/b.jpg"))
+	code, _ = createReview(t, router, 2, 3, [3]int{3, 3, 3})
+	assert.Equal(t, http.StatusOK, code)
+
+	w := housekeepingRequest(router, "GET", "/api/reviews/my", 1, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "a***e")
+	assert.NotContains(t, w.Body.String(), "b***b")
+}
+
+func TestReview_SummaryAndModeration(t *testing.T) {
+	router, _ := setupReviewRouter(t)
+	_, alice := createReview(t, router, 1, 1, [3]int{5, 4, 3})
+	_, _ = createReview(t, router, 2, 3, [3]int{2, 2, 2})
+	_, _ = createReview(t, router, 1, 4, [3]int{1, 1, 1})
+
+	w := housekeepingRequest(router, "GET", "/api/reviews/summary", 1, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var summary struct {
+		Data []models.RoomTypeRating `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &summary))
+	ratings := map[string]models.RoomTypeRating{}
+	for _, r := range summary.Data {
+		ratings[r.RoomType] = r
+	}
+	assert.Len(t, ratings, 2, "只统计当前酒店")
+	assert.Equal(t, int64(1), ratings["标准间"].ReviewCount)
+	assert.Equal(t, 4.0, ratings["标准间"].AvgOverall)
+	assert.Equal(t, 2.0, ratings["大床房"].AvgCleanliness)
+
+	reviews, total := listReviews(t, router, "/api/reviews?page_size=1")
+	assert.Len(t, reviews, 1)
+	assert.Equal(t, int64(2), total)
+
+	// 隐藏后公开列表和汇总都不再包含，管理员仍可查看
+	hideURL := fmt.Sprintf("/api/admin/reviews/%d/hide", alice.ID)
+	assert.Equal(t, http.StatusOK, housekeepingRequest(router, "POST", hideURL, 1, map[string]string{"reason": "广告"}).Code)
+	reviews, _ = listReviews(t, router, "/api/reviews?room_type=标准间")
+	assert.Empty(t, reviews)
+	w = housekeepingRequest(router, "GET", "/api/reviews/summary?room_type=标准间", 1, nil)
+	assert.NotContains(t, w.Body.String(), "标准间")
+	reviews, _ = listReviews(t, router, "/api/admin/reviews?status=hidden")
+	assert.Len(t, reviews, 1)
+	assert.Equal(t, "广告", reviews[0].HiddenReason)
+
+	assert.Equal(t, http.StatusOK, housekeepingRequest(router, "POST", fmt.Sprintf("/api/admin/reviews/%d/show", alice.ID), 1, nil).Code)
+	w = housekeepingRequest(router, "POST", fmt.Sprintf("/api/admin/reviews/%d/reply", alice.ID), 1, map[string]string{"reply": "感谢入住"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	reviews, _ = listReviews(t, router, "/api/reviews?room_type=标准间")
+	assert.Len(t, reviews, 1)
+	assert.Equal(t, "感谢入住", reviews[0].Reply)
+	assert.NotNil(t, reviews[0].RepliedAt)
+}
+
+func TestReview_ModerationHotelScope(t *testing.T) {
+	router, _ := setupReviewRouter(t)
+	_, other := createReview(t, router, 1, 4, [3]int{1, 1, 1})
+
+	w := housekeepingRequest(router, "POST", fmt.Sprintf("/api/admin/reviews/%d/hide", other.ID), 1, map[string]string{})
+	assert.Equal(t, http.StatusNotFound, w.Code, "不能管理其他酒店的评价")
+	w = housekeepingRequest(router, "POST", fmt.Sprintf("/api/admin/reviews/%d/reply", other.ID), 1, map[string]string{"reply": "感谢"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
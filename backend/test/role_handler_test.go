@@ -0,0 +1,194 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"gohotel/internal/database"
+	"gohotel/internal/handler"
+	"gohotel/internal/middleware"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/internal/service"
+	"gohotel/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// 测试用户
+const (
+	roleTestAdminID     = 1 // 旧版本的管理员，迁移后拥有超级管理员角色
+	roleTestFrontDeskID = 2 // 前台
+	roleTestGuestID     = 3 // 普通客人，没有角色
+)
+
+// setupRoleRouter 初始化内存数据库、写入内置角色，并配置权限保护的路由
+// 请求头 X-User-ID 模拟登录用户
+func setupRoleRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法连接到内存数据库: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Role{}, &models.RolePermission{}, &models.UserRole{}, &models.DataMigration{}); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+
+	users := []models.User{
+		{ID: roleTestAdminID, Username: "admin", Email: "admin@example.com", Password: "x", Role: "admin"},
+		{ID: roleTestFrontDeskID, Username: "desk", Email: "desk@example.com", Password: "x", Role: "user"},
+		{ID: roleTestGuestID, Username: "guest", Email: "guest@example.com", Password: "x", Role: "user"},
+	}
+	assert.NoError(t, db.Create(&users).Error)
+
+	database.DB = db
+	assert.NoError(t, database.MigrateRoles())
+	// 重复执行不会重复写入
+	assert.NoError(t, database.MigrateRoles())
+
+	roleRepo := repository.NewRoleRepository(db)
+	frontDesk, err := roleRepo.FindByCode(models.RoleFrontDesk)
+	assert.NoError(t, err)
+	assert.NoError(t, roleRepo.SetUserRoles(roleTestFrontDeskID, []uint{frontDesk.ID}))
+
+	roleService := service.NewRoleService(roleRepo, repository.NewUserRepository(db))
+	roleHandler := handler.NewRoleHandler(roleService)
+	ok := func(c *gin.Context) { utils.SuccessResponse(c, nil) }
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		id, _ := strconv.ParseInt(c.GetHeader("X-User-ID"), 10, 64)
+		c.Set("user_id", id)
+		c.Next()
+	})
+	router.GET("/api/users/permissions", roleHandler.GetMyPermissions)
+	router.POST("/api/admin/bookings/:id/checkin", middleware.RequirePermission(roleService, models.PermBookingCheckIn), ok)
+	router.GET("/api/admin/logs", middleware.RequirePermission(roleService, models.PermLogRead), ok)
+	router.POST("/api/admin/roles", middleware.RequirePermission(roleService, models.PermRoleManage), roleHandler.CreateRole)
+	router.POST("/api/admin/roles/:id/delete", middleware.RequirePermission(roleService, models.PermRoleManage), roleHandler.DeleteRole)
+	router.POST("/api/admin/users/:id/roles", middleware.RequirePermission(roleService, models.PermRoleManage), roleHandler.SetUserRoles)
+	return router, db
+}
+
+// roleRequest 以指定用户身份发送请求
+func roleRequest(router *gin.Engine, userID int64, method, url string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req, _ := http.NewRequest(method, url, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", strconv.FormatInt(userID, 10))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRequirePermission_ByRole(t *testing.T) {
+	router, _ := setupRoleRouter(t)
+
+	// 前台可以办理入住，但不能查看日志
+	assert.Equal(t, http.StatusOK, roleRequest(router, roleTestFrontDeskID, "POST", "/api/admin/bookings/1/checkin", nil).Code)
+	assert.Equal(t, http.StatusForbidden, roleRequest(router, roleTestFrontDeskID, "GET", "/api/admin/logs", nil).Code)
+
+	// 超级管理员拥有全部权限
+	assert.Equal(t, http.StatusOK, roleRequest(router, roleTestAdminID, "GET", "/api/admin/logs", nil).Code)
+
+	// 没有角色的用户不能访问管理接口
+	assert.Equal(t, http.StatusForbidden, roleRequest(router, roleTestGuestID, "POST", "/api/admin/bookings/1/checkin", nil).Code)
+
+	w := roleRequest(router, roleTestFrontDeskID, "GET", "/api/users/permissions", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data []string `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Contains(t, resp.Data, models.PermBookingCheckIn)
+	assert.NotContains(t, resp.Data, models.PermLogRead)
+}
+
+func TestRoles_CustomRoleAssignment(t *testing.T) {
+	router, db := setupRoleRouter(t)
+
+	// 不能授予通配权限或未知权限
+	w := roleRequest(router, roleTestAdminID, "POST", "/api/admin/roles", map[string]interface{}{
+		"code": "auditor", "name": "审计", "permissions": []string{"*"},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = roleRequest(router, roleTestAdminID, "POST", "/api/admin/roles", map[string]interface{}{
+		"code": "auditor", "name": "审计", "permissions": []string{"log:delete"},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// 前台不能管理角色
+	w = roleRequest(router, roleTestFrontDeskID, "POST", "/api/admin/roles", map[string]interface{}{
+		"code": "auditor", "name": "审计", "permissions": []string{models.PermLogRead},
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = roleRequest(router, roleTestAdminID, "POST", "/api/admin/roles", map[string]interface{}{
+		"code": "auditor", "name": "审计", "permissions": []string{models.PermLogRead},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var created struct {
+		Data models.Role `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	// 分配角色后立即生效（缓存已清除）
+	assert.Equal(t, http.StatusForbidden, roleRequest(router, roleTestGuestID, "GET", "/api/admin/logs", nil).Code)
+	w = roleRequest(router, roleTestAdminID, "POST", "/api/admin/users/3/roles", map[string]interface{}{
+		"role_ids": []uint{created.Data.ID},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusOK, roleRequest(router, roleTestGuestID, "GET", "/api/admin/logs", nil).Code)
+
+	// 删除角色后失去权限
+	w = roleRequest(router, roleTestAdminID, "POST", "/api/admin/roles/"+strconv.Itoa(int(created.Data.ID))+"/delete", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusForbidden, roleRequest(router, roleTestGuestID, "GET", "/api/admin/logs", nil).Code)
+
+	// 内置角色不可删除
+	var manager models.Role
+	assert.NoError(t, db.Where("code = ?", models.RoleManager).First(&manager).Error)
+	assert.True(t, manager.BuiltIn)
+	w = roleRequest(router, roleTestAdminID, "POST", "/api/admin/roles/"+strconv.Itoa(int(manager.ID))+"/delete", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRoles_KeepsLastSuperAdmin(t *testing.T) {
+	router, _ := setupRoleRouter(t)
+
+	// 唯一的超级管理员不能取消自己的超级管理员角色
+	w := roleRequest(router, roleTestAdminID, "POST", "/api/admin/users/1/roles", map[string]interface{}{
+		"role_ids": []uint{},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// 不存在的角色
+	w = roleRequest(router, roleTestAdminID, "POST", "/api/admin/users/2/roles", map[string]interface{}{
+		"role_ids": []uint{999},
+	})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestMigrateRoles_AssignsSuperAdminOnce(t *testing.T) {
+	router, db := setupRoleRouter(t)
+	w := roleRequest(router, roleTestAdminID, "GET", "/api/admin/logs", nil)
+	assert.Equal(t, http.StatusOK, w.Code, "旧版本的管理员迁移后拥有超级管理员角色")
+
+	// 首次迁移之后 role 字段被改为 admin 的用户，重启时不会再被授予超级管理员
+	assert.NoError(t, db.Model(&models.User{}).Where("id = ?", roleTestGuestID).Update("role", "admin").Error)
+	assert.NoError(t, database.MigrateRoles())
+	var count int64
+	assert.NoError(t, db.Model(&models.UserRole{}).Where("user_id = ?", roleTestGuestID).Count(&count).Error)
+	assert.Zero(t, count)
+	w = roleRequest(router, roleTestGuestID, "GET", "/api/admin/logs", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,225 @@
+package test
+
+import (
+	"encoding/json"
+	"gohotel/internal/handler"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupCalendarRouter 初始化内存数据库并配置房态日历路由
+//
+// 101 在第 1~3 天有已确认预订（第 0 天有一条已取消的预订），102 在第 2~4 天有停用工单，201 处于维修状态
+func setupCalendarRouter(t *testing.T) (*gin.Engine, *gorm.DB, time.Time) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法连接到内存数据库: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Room{}, &models.RoomPhoto{}, &models.Amenity{}, &models.Booking{}, &models.BookingInstallment{}, &models.WorkOrder{}); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+
+	rooms := []models.Room{
+		{ID: 1, RoomNumber: "101", RoomType: "标准间", Floor: 1, Status: "available", HousekeepingStatus: "dirty"},
+		{ID: 2, RoomNumber: "102", RoomType: "大床房", Floor: 1, Status: "available"},
+		{ID: 3, RoomNumber: "201", RoomType: "标准间", Floor: 2, Status: "maintenance"},
+		{ID: 4, RoomNumber: "301", RoomType: "标准间", Floor: 3, Status: "available", HotelID: 2},
+	}
+	for i := range rooms {
+		if rooms[i].HotelID == 0 {
+			rooms[i].HotelID = 1
+		}
+		rooms[i].Price = 200
+		rooms[i].Capacity = 2
+	}
+	assert.NoError(t, db.Create(&rooms).Error)
+
+	day := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 10)
+	bookings := []models.Booking{
+		{ID: 1, BookingNumber: 1, RoomID: 1, CheckIn: day.AddDate(0, 0, 1), CheckOut: day.AddDate(0, 0, 3), TotalDays: 2, GuestName: "张三", Status: "confirmed"},
+		{ID: 2, BookingNumber: 2, RoomID: 1, CheckIn: day, CheckOut: day.AddDate(0, 0, 1), TotalDays: 1, GuestName: "李四", Status: "cancelled"},
+	}
+	for i := range bookings {
+		bookings[i].HotelID = 1
+		bookings[i].UserID = 1
+		bookings[i].BookingType = models.BookingTypeNightly
+		bookings[i].GuestPhone = "13800000000"
+	}
+	assert.NoError(t, db.Create(&bookings).Error)
+
+	start, end := day.AddDate(0, 0, 2), day.AddDate(0, 0, 4)
+	assert.NoError(t, db.Create(&models.WorkOrder{
+		ID: 1, RoomID: 2, Title: "空调漏水", Category: "hvac", Status: "open", ReporterID: 1,
+		OutOfOrderStart: &start, OutOfOrderEnd: &end,
+	}).Error)
+
+	calendarService := service.NewRoomCalendarService(repository.NewRoomRepository(db), repository.NewBookingRepository(db),
+		repository.NewWorkOrderRepository(db))
+	calendarHandler := handler.NewRoomCalendarHandler(calendarService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("hotel_id", uint(1))
+		c.Next()
+	})
+	router.GET("/api/admin/rooms/calendar", calendarHandler.GetRoomCalendar)
+	router.GET("/api/rooms/:id/calendar", calendarHandler.GetRoomAvailability)
+	return router, db, day
+}
+
+// getCalendar 查询前台房态图
+func getCalendar(t *testing.T, router *gin.Engine, query string) (int, service.RoomCalendar) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/admin/rooms/calendar?"+query, nil)
+	router.ServeHTTP(w, req)
+
+	var resp struct {
+		Data service.RoomCalendar `json:"data"`
+	}
+	if w.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w.Code, resp.Data
+}
+
+// calendarRange 生成从 day 开始 days 天的查询参数
+func calendarRange(day time.Time, days int) string {
+	return "start_date=" + day.Format("2006-01-02") + "&end_date=" + day.AddDate(0, 0, days).Format("2006-01-02")
+}
+
+func TestRoomCalendar_TapeChart(t *testing.T) {
+	router, _, day := setupCalendarRouter(t)
+
+	code, calendar := getCalendar(t, router, calendarRange(day, 5))
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, calendar.Dates, 5)
+	assert.Len(t, calendar.Rooms, 3, "只返回当前酒店的房间")
+
+	room101 := calendar.Rooms[0]
+	assert.Equal(t, "101", room101.RoomNumber)
+	assert.Equal(t, "dirty", room101.HousekeepingStatus)
+	assert.Equal(t, service.CalendarCellFree, room101.Cells[0].Status, "已取消的预订不占用房间")
+	assert.Equal(t, "confirmed", room101.Cells[1].Status)
+	assert.Equal(t, "张三", room101.Cells[1].GuestName)
+	assert.True(t, room101.Cells[1].IsArrival)
+	assert.Equal(t, "confirmed", room101.Cells[2].Status)
+	assert.False(t, room101.Cells[2].IsArrival)
+	assert.Equal(t, service.CalendarCellFree, room101.Cells[3].Status, "退房当晚不占用")
+
+	room102 := calendar.Rooms[1]
+	assert.Equal(t, service.CalendarCellFree, room102.Cells[1].Status)
+	assert.Equal(t, service.CalendarCellOutOfOrder, room102.Cells[2].Status)
+	assert.NotNil(t, room102.Cells[2].WorkOrderID)
+	assert.Equal(t, service.CalendarCellOutOfOrder, room102.Cells[3].Status)
+	assert.Equal(t, service.CalendarCellFree, room102.Cells[4].Status)
+
+	for _, cell := range calendar.Rooms[2].Cells {
+		assert.Equal(t, service.CalendarCellOutOfOrder, cell.Status, "维修状态的房间视为停用")
+	}
+
+	code, calendar = getCalendar(t, router, calendarRange(day, 5)+"&floor=1&room_type=大床房")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, calendar.Rooms, 1)
+	assert.Equal(t, "102", calendar.Rooms[0].RoomNumber)
+}
+
+func TestRoomCalendar_InvalidRange(t *testing.T) {
+	router, _, day := setupCalendarRouter(t)
+
+	code, _ := getCalendar(t, router, "start_date="+day.Format("2006-01-02"))
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = getCalendar(t, router, "start_date="+day.Format("2006-01-02")+"&end_date="+day.Format("2006-01-02"))
+	assert.Equal(t, http.StatusBadRequest, code, "结束日期必须晚于开始日期")
+	code, _ = getCalendar(t, router, calendarRange(day, 93))
+	assert.Equal(t, http.StatusBadRequest, code, "查询范围不能超过92天")
+	code, _ = getCalendar(t, router, calendarRange(day, 5)+"&floor=abc")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestRoomCalendar_GuestAvailability(t *testing.T) {
+	router, _, day := setupCalendarRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/rooms/1/calendar?"+calendarRange(day, 4), nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "张三", "对客日历不暴露预订信息")
+
+	var resp struct {
+		Data []service.RoomDayAvailability `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	available := make([]bool, len(resp.Data))
+	for i, d := range resp.Data {
+		available[i] = d.Available
+	}
+	assert.Equal(t, []bool{true, false, false, true}, available)
+
+	// 其他酒店的房间按不存在处理
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/rooms/4/calendar?"+calendarRange(day, 4), nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRoomCalendar_HourlyBookings(t *testing.T) {
+	router, db, day := setupCalendarRouter(t)
+
+	// 钟点房的入住、退房日期相同：102 第 0 天 13:00-17:00，101 第 4 天 22:00 到第 5 天 02:00
+	hourly := []models.Booking{
+		{ID: 3, BookingNumber: 3, RoomID: 2, CheckIn: day, CheckOut: day, GuestName: "王五"},
+		{ID: 4, BookingNumber: 4, RoomID: 1, CheckIn: day.AddDate(0, 0, 4), CheckOut: day.AddDate(0, 0, 4), GuestName: "赵六"},
+	}
+	windows := [][2]time.Time{
+		{day.Add(13 * time.Hour), day.Add(17 * time.Hour)},
+		{day.AddDate(0, 0, 4).Add(22 * time.Hour), day.AddDate(0, 0, 5).Add(2 * time.Hour)},
+	}
+	for i := range hourly {
+		hourly[i].HotelID = 1
+		hourly[i].UserID = 1
+		hourly[i].BookingType = models.BookingTypeHourly
+		hourly[i].StartTime = &windows[i][0]
+		hourly[i].EndTime = &windows[i][1]
+		hourly[i].TotalHours = 4
+		hourly[i].GuestPhone = "13800000000"
+		hourly[i].Status = "confirmed"
+	}
+	assert.NoError(t, db.Create(&hourly).Error)
+
+	code, calendar := getCalendar(t, router, calendarRange(day, 6))
+	assert.Equal(t, http.StatusOK, code)
+	room101, room102 := calendar.Rooms[0], calendar.Rooms[1]
+	assert.Equal(t, "confirmed", room102.Cells[0].Status)
+	assert.Equal(t, "王五", room102.Cells[0].GuestName)
+	assert.True(t, room102.Cells[0].IsArrival)
+	assert.Equal(t, service.CalendarCellFree, room102.Cells[1].Status)
+	assert.Equal(t, "赵六", room101.Cells[4].GuestName)
+	assert.Equal(t, "赵六", room101.Cells[5].GuestName, "跨过零点的钟点房占用两天")
+
+	// 只查询钟点房所在的一天也能查到
+	code, calendar = getCalendar(t, router, calendarRange(day, 1))
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "王五", calendar.Rooms[1].Cells[0].GuestName)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/rooms/2/calendar?"+calendarRange(day, 2), nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data []service.RoomDayAvailability `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.False(t, resp.Data[0].Available)
+	assert.True(t, resp.Data[1].Available)
+}
@@ -0,0 +1,219 @@
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gohotel/internal/handler"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/internal/service"
+	"gohotel/pkg/utils"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// importResponse 导入接口响应
+type importResponse struct {
+	Success bool                     `json:"success"`
+	Data    service.RoomImportResult `json:"data"`
+}
+
+// setupRoomImportRouter 初始化内存数据库并配置房间导入路由
+// 酒店 1 已有房间 101 位于 (0,0)-(100,80)，2 楼电梯位于 (400,0)-(450,50)
+func setupRoomImportRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法连接到内存数据库: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Room{}, &models.Facility{}, &models.FloorLayoutVersion{}); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+	assert.NoError(t, db.Create(&models.Room{
+		HotelID: 1, RoomNumber: "101", RoomType: "标准间", Floor: 1, Price: 100, Capacity: 2,
+		Left: 0, Top: 0, Width: 100, Height: 80,
+	}).Error)
+	assert.NoError(t, db.Create(&models.Facility{
+		HotelID: 1, Type: "elevator", Floor: 2, Label: "电梯", Left: 400, Top: 0, Width: 50, Height: 50,
+	}).Error)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", int64(1))
+		c.Set("hotel_id", uint(1))
+		c.Next()
+	})
+
+	roomRepo := repository.NewRoomRepository(db)
+	layoutService := service.NewFloorLayoutService(repository.NewFloorLayoutRepository(db), roomRepo, repository.NewFacilityRepository(db))
+	roomHandler := handler.NewRoomHandler(service.NewRoomService(roomRepo, layoutService, nil))
+	layoutHandler := handler.NewFloorLayoutHandler(layoutService)
+	router.POST("/api/rooms/import", roomHandler.ImportRooms)
+	router.GET("/api/rooms/import/template", roomHandler.GetImportTemplate)
+	router.POST("/api/admin/floors/:floor/layout/versions/:id/publish", layoutHandler.PublishDraft)
+	return router, db
+}
+
+// uploadRoomFile 以 multipart 表单上传导入文件
+func uploadRoomFile(router *gin.Engine, url, filename string, data []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("file", filename)
+	part.Write(data)
+	writer.Close()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", url, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestImportRooms_TemplateRoundTrip(t *testing.T) {
+	router, db := setupRoomImportRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/rooms/import/template", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "room_import_template.xlsx")
+
+	// 模板示例行的房间号与已有房间重复，改成新房间号后导入
+	rows, err := utils.ReadXLSX(w.Body.Bytes(), 0)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "room_number", rows[0][0])
+	rows[1][0] = "102"
+	rows[1][9] = "100" // left，放在 101 右侧
+	data, err := utils.WriteXLSX("rooms", rows)
+	assert.NoError(t, err)
+
+	w = uploadRoomFile(router, "/api/rooms/import", "rooms.xlsx", data)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response importResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), "JSON 反序列化失败")
+	assert.Equal(t, 1, response.Data.ImportedCount)
+
+	var room models.Room
+	assert.NoError(t, db.Where("room_number = ?", "102").First(&room).Error)
+	assert.Equal(t, uint(1), room.HotelID)
+	assert.Equal(t, 0, room.Width, "坐标保存在布局草稿中，发布前未摆放")
+
+	var draft models.FloorLayoutVersion
+	assert.NoError(t, db.Where("floor = ? AND status = ?", 1, "draft").First(&draft).Error)
+	w = housekeepingRequest(router, "POST", fmt.Sprintf("/api/admin/floors/1/layout/versions/%d/publish", draft.ID), 1, nil)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NoError(t, db.First(&room, room.ID).Error)
+	assert.Equal(t, 100, room.Left)
+	assert.Equal(t, 80, room.Height)
+}
+
+func TestImportRooms_DryRunReportsRowErrors(t *testing.T) {
+	router, db := setupRoomImportRouter(t)
+
+	csv := "房间号,房型,楼层,价格,可住人数,left,top,width,height\n" +
+		"201,大床房,2,399,2,0,0,100,80\n" +
+		"201,大床房,2,399,2,100,0,100,80\n" + // 文件内重复
+		"101,标准间,1,299,2,200,0,100,80\n" + // 与已有房间重复
+		"202,大床房,2,abc,2,,0,100,80\n" + // 价格类型错误、缺少坐标
+		"203,大床房,2,399,2,50,0,100,80\n" + // 与 201 重叠
+		"\n" +
+		"204,大床房,2,399,2,300,0,100,80\n" +
+		"205,大床房,2,399,2,420,100,100,80\n" + // 在电梯下方，不重叠
+		"206,大床房,2,399,2,420,20,60,60\n" // 与电梯重叠
+
+	w := uploadRoomFile(router, "/api/rooms/import?dry_run=true", "rooms.csv", []byte(csv))
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response importResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), "JSON 反序列化失败")
+	result := response.Data
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 8, result.TotalRows, "空行不计入")
+	assert.Equal(t, 3, result.ValidRows)
+	assert.Equal(t, 0, result.ImportedCount)
+
+	errorRows := make(map[int][]string)
+	for _, e := range result.Errors {
+		errorRows[e.Row] = append(errorRows[e.Row], e.Column)
+	}
+	assert.Equal(t, []string{"room_number"}, errorRows[3])
+	assert.Equal(t, []string{"room_number"}, errorRows[4])
+	assert.ElementsMatch(t, []string{"price", "left"}, errorRows[5])
+	assert.Len(t, errorRows[6], 1)
+	assert.Len(t, errorRows[9], 1)
+	assert.Equal(t, "204", result.Rooms[1].RoomNumber)
+	for _, e := range result.Errors {
+		if e.Row == 9 {
+			assert.Contains(t, e.Message, "电梯")
+		}
+	}
+
+	var count int64
+	db.Model(&models.Room{}).Count(&count)
+	assert.Equal(t, int64(1), count, "试运行不写入")
+
+	// 正式导入时存在错误则整体不导入
+	w = uploadRoomFile(router, "/api/rooms/import", "rooms.csv", []byte(csv))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	db.Model(&models.Room{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestImportRooms_RejectsUnknownFormat(t *testing.T) {
+	router, _ := setupRoomImportRouter(t)
+
+	w := uploadRoomFile(router, "/api/rooms/import", "rooms.txt", []byte("room_number\n101\n"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = uploadRoomFile(router, "/api/rooms/import", "rooms.csv", []byte("room_number,price\n101,100\n"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "缺少必填列")
+}
+
+func TestImportRooms_RejectsTooManyRows(t *testing.T) {
+	router, _ := setupRoomImportRouter(t)
+
+	rows := [][]string{{"房间号", "房型", "楼层", "价格", "可住人数", "left", "top", "width", "height"}}
+	for i := 0; i < 1001; i++ {
+		rows = append(rows, []string{fmt.Sprint(1000 + i), "标准间", "1", "299", "2", "0", "0", "10", "10"})
+	}
+	csvData, err := utils.WriteCSV(rows)
+	assert.NoError(t, err)
+	xlsxData, err := utils.WriteXLSX("rooms", rows)
+	assert.NoError(t, err)
+
+	for name, data := range map[string][]byte{"rooms.csv": csvData, "rooms.xlsx": xlsxData} {
+		w := uploadRoomFile(router, "/api/rooms/import?dry_run=true", name, data)
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+		assert.Contains(t, w.Body.String(), "单次最多导入1000个房间", name)
+	}
+}
+
+func TestImportRooms_RejectsOversizedXLSXPart(t *testing.T) {
+	router, _ := setupRoomImportRouter(t)
+
+	// 压缩后很小，解压后超过单个文件的大小限制
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("xl/worksheets/sheet1.xml")
+	assert.NoError(t, err)
+	_, err = w.Write([]byte("<worksheet><sheetData>" + strings.Repeat(" ", 21<<20) + "</sheetData></worksheet>"))
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+
+	resp := uploadRoomFile(router, "/api/rooms/import", "rooms.xlsx", buf.Bytes())
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), "解压后超过")
+}
@@ -0,0 +1,194 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"gohotel/internal/database"
+	"gohotel/internal/handler"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/internal/service"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupRoomMediaRouter 初始化内存数据库并配置房间图库路由
+//
+// 房间 1 属于酒店 1，房间 2 属于酒店 2
+func setupRoomMediaRouter(t *testing.T) (*gin.Engine, *gorm.DB, *fakeCOS) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法连接到内存数据库: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Room{}, &models.RoomPhoto{}, &models.Amenity{}, &models.DataMigration{}); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+	assert.NoError(t, db.Create(&[]models.Room{
+		{ID: 1, HotelID: 1, RoomNumber: "101", RoomType: "标准间", Floor: 1, Price: 200, Capacity: 2},
+		{ID: 2, HotelID: 2, RoomNumber: "201", RoomType: "标准间", Floor: 2, Price: 200, Capacity: 2},
+	}).Error)
+
+	fake, cosService := newFakeCOS(t)
+	roomRepo := repository.NewRoomRepository(db)
+	roomMediaHandler := handler.NewRoomMediaHandler(service.NewRoomMediaService(roomRepo, repository.NewRoomPhotoRepository(db), cosService))
+	roomHandler := handler.NewRoomHandler(service.NewRoomService(roomRepo, nil, cosService))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("hotel_id", uint(1))
+		c.Next()
+	})
+	router.GET("/api/rooms/:id/photos", roomMediaHandler.ListPhotos)
+	router.POST("/api/admin/rooms", roomHandler.CreateRoom)
+	router.POST("/api/admin/rooms/:id", roomHandler.UpdateRoom)
+	router.POST("/api/admin/rooms/:id/photos", roomMediaHandler.AddPhotos)
+	router.POST("/api/admin/rooms/:id/photos/reorder", roomMediaHandler.ReorderPhotos)
+	router.POST("/api/admin/rooms/:id/photos/:photo_id", roomMediaHandler.UpdatePhoto)
+	router.POST("/api/admin/rooms/:id/photos/:photo_id/cover", roomMediaHandler.SetCover)
+	router.POST("/api/admin/rooms/:id/photos/:photo_id/delete", roomMediaHandler.DeletePhoto)
+	return router, db, fake
+}
+
+// photoRequest 发送图库请求，返回状态码和图片列表
+func photoRequest(t *testing.T, router *gin.Engine, url string, body interface{}) (int, []models.RoomPhoto) {
+	w := housekeepingRequest(router, "POST", url, 1, body)
+	var resp struct {
+		Data []models.RoomPhoto `json:"data"`
+	}
+	if w.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w.Code, resp.Data
+}
+
+// photoSummary 按顺序返回图片说明，封面带 * 前缀
+func photoSummary(photos []models.RoomPhoto) []string {
+	summary := make([]string, len(photos))
+	for i, p := range photos {
+		summary[i] = p.Caption
+		if p.IsCover {
+			summary[i] = "*" + p.Caption
+		}
+	}
+	return summary
+}
+
+// roomImages 读取房间同步后的 images 字段
+func roomImages(t *testing.T, db *gorm.DB, roomID uint) string {
+	var room models.Room
+	assert.NoError(t, db.First(&room, roomID).Error)
+	return room.Images
+}
+
+func TestRoomMedia_Gallery(t *testing.T) {
+	router, db, fake := setupRoomMediaRouter(t)
+
+	code, photos := photoRequest(t, router, "/api/admin/rooms/1/photos", map[string]interface{}{
+		"photos": []map[string]string{
+			{"temp_url": fake.putTemp("room", "a.jpg"), "caption": "a"},
+			{"temp_url": fake.putTemp("room", "b.jpg"), "caption": "b"},
+			{"temp_url": fake.putTemp("room", "c.jpg"), "caption": "c"},
+		},
+	})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"*a", "b", "c"}, photoSummary(photos), "第一张图片自动成为封面")
+	assert.True(t, fake.has("rooms/a.jpg"))
+	ids := map[string]uint{}
+	for _, p := range photos {
+		ids[p.Caption] = p.ID
+	}
+
+	code, photos = photoRequest(t, router, "/api/admin/rooms/1/photos/reorder", map[string][]uint{"photo_ids": {ids["c"], ids["a"], ids["b"]}})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"c", "*a", "b"}, photoSummary(photos))
+	code, _ = photoRequest(t, router, "/api/admin/rooms/1/photos/reorder", map[string][]uint{"photo_ids": {ids["c"], ids["a"]}})
+	assert.Equal(t, http.StatusBadRequest, code, "排序必须包含全部图片")
+
+	code, photos = photoRequest(t, router, fmt.Sprintf("/api/admin/rooms/1/photos/%d/cover", ids["b"]), nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"c", "a", "*b"}, photoSummary(photos))
+	assert.JSONEq(t, fmt.Sprintf(`[%q, %q, %q]`, fake.url("rooms/b.jpg"), fake.url("rooms/c.jpg"), fake.url("rooms/a.jpg")),
+		roomImages(t, db, 1), "images 字段封面在前")
+
+	// 删除封面后排在最前的图片成为封面，文件一并删除
+	code, photos = photoRequest(t, router, fmt.Sprintf("/api/admin/rooms/1/photos/%d/delete", ids["b"]), nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"*c", "a"}, photoSummary(photos))
+	assert.False(t, fake.has("rooms/b.jpg"))
+	assert.JSONEq(t, fmt.Sprintf(`[%q, %q]`, fake.url("rooms/c.jpg"), fake.url("rooms/a.jpg")), roomImages(t, db, 1))
+	code, _ = photoRequest(t, router, fmt.Sprintf("/api/admin/rooms/1/photos/%d/delete", ids["b"]), nil)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestMigrateRoomPhotos_RunsOnce(t *testing.T) {
+	_, db, _ := setupRoomMediaRouter(t)
+	database.DB = db
+	assert.NoError(t, db.Model(&models.Room{}).Where("id = ?", 1).Update("images", `["http://example.com/a.jpg", "http://example.com/b.jpg"]`).Error)
+
+	assert.NoError(t, database.MigrateRoomPhotos())
+	var photos []models.RoomPhoto
+	assert.NoError(t, db.Where("room_id = ?", 1).Order("sort").Find(&photos).Error)
+	if assert.Len(t, photos, 2) {
+		assert.True(t, photos[0].IsCover)
+	}
+
+	// 图库清空后重启，旧字段不会把图片恢复回来
+	assert.NoError(t, db.Where("room_id = ?", 1).Delete(&models.RoomPhoto{}).Error)
+	assert.NoError(t, database.MigrateRoomPhotos())
+	var count int64
+	assert.NoError(t, db.Model(&models.RoomPhoto{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestRoomMedia_AddPhotosRollsBack(t *testing.T) {
+	router, db, fake := setupRoomMediaRouter(t)
+
+	missing := strings.Replace(fake.putTemp("room", "x.jpg"), "x.jpg", "missing.jpg", 1)
+	code, _ := photoRequest(t, router, "/api/admin/rooms/1/photos", map[string]interface{}{
+		"photos": []map[string]string{{"temp_url": fake.putTemp("room", "a.jpg")}, {"temp_url": missing}},
+	})
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.False(t, fake.has("rooms/a.jpg"), "部分确认失败时删除已确认的文件")
+	var count int64
+	assert.NoError(t, db.Model(&models.RoomPhoto{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestRoomMedia_RoomRequestsIgnoreImages(t *testing.T) {
+	router, db, fake := setupRoomMediaRouter(t)
+
+	code, _ := photoRequest(t, router, "/api/admin/rooms/1/photos", map[string]interface{}{
+		"photos": []map[string]string{{"temp_url": fake.putTemp("room", "a.jpg")}},
+	})
+	assert.Equal(t, http.StatusOK, code)
+	images := roomImages(t, db, 1)
+
+	// 图片只能通过图库接口管理，房间接口中的 images 不会写入未确认的 URL
+	w := housekeepingRequest(router, "POST", "/api/admin/rooms/1", 1, map[string]string{"images": `["http://example.com/x.jpg"]`, "description": "朝南"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, images, roomImages(t, db, 1))
+
+	w = housekeepingRequest(router, "POST", "/api/admin/rooms", 1, map[string]interface{}{
+		"room_number": "102", "room_type": "标准间", "floor": 1, "price": 200, "capacity": 2, "images": `["http://example.com/x.jpg"]`,
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "example.com")
+}
+
+func TestRoomMedia_HotelScope(t *testing.T) {
+	router, _, fake := setupRoomMediaRouter(t)
+
+	assert.Equal(t, http.StatusNotFound, housekeepingRequest(router, "GET", "/api/rooms/2/photos", 1, nil).Code)
+	code, _ := photoRequest(t, router, "/api/admin/rooms/2/photos", map[string]interface{}{
+		"photos": []map[string]string{{"temp_url": fake.putTemp("room", "a.jpg")}},
+	})
+	assert.Equal(t, http.StatusNotFound, code, "不能为其他酒店的房间添加图片")
+	assert.False(t, fake.has("rooms/a.jpg"))
+}
@@ -0,0 +1,157 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"gohotel/internal/handler"
+	"gohotel/internal/middleware"
+	"gohotel/internal/models"
+	"gohotel/internal/service"
+	"gohotel/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	// iPhone 上的 Safari
+	iPhoneUserAgent = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1"
+	// Windows 上的 Chrome
+	windowsUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36"
+)
+
+// setupSessionRouter 在认证测试环境上注册登录会话路由
+func setupSessionRouter(t *testing.T) *authTestEnv {
+	env := setupAuthRouter(t)
+	sessionHandler := handler.NewSessionHandler(env.tokens)
+	authRequired := middleware.AuthMiddleware(env.tokens)
+	env.router.GET("/api/users/sessions", authRequired, sessionHandler.ListSessions)
+	env.router.POST("/api/users/sessions/:id/revoke", authRequired, sessionHandler.RevokeSession)
+	env.router.POST("/api/users/sessions/revoke-all", authRequired, sessionHandler.RevokeAllSessions)
+	env.router.POST("/api/admin/users/:id/logout", sessionHandler.ForceLogout)
+	return env
+}
+
+// loginFrom 使用指定的 User-Agent 和 IP 以测试用户登录
+func loginFrom(t *testing.T, env *authTestEnv, userAgent, remoteAddr string) service.TokenPair {
+	payload, _ := json.Marshal(map[string]string{"username": "alice", "password": authTestPassword})
+	req, _ := http.NewRequest("POST", "/api/auth/login", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.RemoteAddr = remoteAddr
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data service.LoginResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	if resp.Data.TokenPair == nil {
+		t.Fatalf("登录未返回令牌: %s", w.Body.String())
+	}
+	return *resp.Data.TokenPair
+}
+
+// listSessions 查询登录设备
+func listSessions(t *testing.T, env *authTestEnv, token string) []service.SessionInfo {
+	w := authRequest(env.router, "GET", "/api/users/sessions", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data []service.SessionInfo `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp.Data
+}
+
+func TestParseDevice(t *testing.T) {
+	assert.Equal(t, "Safari (iPhone)", utils.ParseDevice(iPhoneUserAgent))
+	assert.Equal(t, "Chrome (Windows)", utils.ParseDevice(windowsUserAgent))
+	assert.Equal(t, "Edge (Windows)", utils.ParseDevice(windowsUserAgent+" Edg/126.0.0.0"))
+	assert.Equal(t, "微信小程序 (Android)", utils.ParseDevice("Mozilla/5.0 (Linux; Android 14) MicroMessenger/8.0.47 miniProgram/wx123"))
+	assert.Equal(t, "curl", utils.ParseDevice("curl/8.5.0"))
+	assert.Equal(t, "未知设备", utils.ParseDevice(""))
+}
+
+func TestSessions_ListAndRevoke(t *testing.T) {
+	env := setupSessionRouter(t)
+	phone := loginFrom(t, env, iPhoneUserAgent, "198.51.100.7:5000")
+	laptop := loginFrom(t, env, windowsUserAgent, "203.0.113.9:6000")
+
+	sessions := listSessions(t, env, laptop.Token)
+	assert.Len(t, sessions, 2)
+	var phoneSession service.SessionInfo
+	for _, session := range sessions {
+		if session.Current {
+			assert.Equal(t, "Chrome (Windows)", session.Device)
+			assert.Equal(t, "203.0.113.9", session.IP)
+			assert.Equal(t, windowsUserAgent, session.UserAgent)
+			assert.False(t, session.LastSeenAt.IsZero())
+		} else {
+			phoneSession = session
+		}
+	}
+	assert.Equal(t, "Safari (iPhone)", phoneSession.Device)
+	assert.Equal(t, "198.51.100.7", phoneSession.IP)
+
+	// 从电脑上撤销手机的会话
+	w := authRequest(env.router, "POST", "/api/users/sessions/"+phoneSession.ID.String()+"/revoke", laptop.Token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusUnauthorized, authRequest(env.router, "GET", "/api/users/profile", phone.Token, nil).Code)
+	code, _ := refresh(t, env.router, phone.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Len(t, listSessions(t, env, laptop.Token), 1)
+
+	// 已撤销或不存在的会话
+	w = authRequest(env.router, "POST", "/api/users/sessions/"+phoneSession.ID.String()+"/revoke", laptop.Token, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = authRequest(env.router, "POST", "/api/users/sessions/abc/revoke", laptop.Token, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSessions_CannotRevokeOtherUsersSession(t *testing.T) {
+	env := setupSessionRouter(t)
+	hashed, err := utils.HashPassword(authTestPassword)
+	assert.NoError(t, err)
+	assert.NoError(t, env.db.Create(&models.User{
+		ID: 2, Username: "bob", Email: "bob@example.com", Password: hashed, Status: "active",
+	}).Error)
+
+	alice := loginFrom(t, env, windowsUserAgent, "192.0.2.1:1234")
+	_, bob := loginAs(t, env.router, "bob", authTestPassword)
+	aliceSession := listSessions(t, env, alice.Token)[0]
+
+	w := authRequest(env.router, "POST", "/api/users/sessions/"+aliceSession.ID.String()+"/revoke", bob.Token, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, http.StatusOK, authRequest(env.router, "GET", "/api/users/profile", alice.Token, nil).Code)
+}
+
+func TestSessions_RevokeAllAndForceLogout(t *testing.T) {
+	env := setupSessionRouter(t)
+	phone := loginFrom(t, env, iPhoneUserAgent, "198.51.100.7:5000")
+	laptop := loginFrom(t, env, windowsUserAgent, "203.0.113.9:6000")
+
+	// 退出其他设备，保留当前会话
+	w := authRequest(env.router, "POST", "/api/users/sessions/revoke-all?keep_current=true", laptop.Token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusUnauthorized, authRequest(env.router, "GET", "/api/users/profile", phone.Token, nil).Code)
+	assert.Equal(t, http.StatusOK, authRequest(env.router, "GET", "/api/users/profile", laptop.Token, nil).Code)
+
+	// 退出所有设备
+	w = authRequest(env.router, "POST", "/api/users/sessions/revoke-all", laptop.Token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusUnauthorized, authRequest(env.router, "GET", "/api/users/profile", laptop.Token, nil).Code)
+
+	// 管理员强制下线
+	tablet := loginFrom(t, env, iPhoneUserAgent, "198.51.100.8:5000")
+	w = authRequest(env.router, "POST", "/api/admin/users/1/logout", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusUnauthorized, authRequest(env.router, "GET", "/api/users/profile", tablet.Token, nil).Code)
+	code, _ := refresh(t, env.router, tablet.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	w = authRequest(env.router, "POST", "/api/admin/users/999/logout", "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
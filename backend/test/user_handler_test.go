@@ -2,6 +2,7 @@ package test
 
 import (
 	"encoding/json"
+	"gohotel/internal/config"
 	"gohotel/internal/handler"
 	"gohotel/internal/models"
 	"gohotel/internal/repository"
@@ -41,8 +42,18 @@ func setupRouter(db *gorm.DB) *gin.Engine {
 
 	// 依赖注入
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
-	userHandler := handler.NewUserHandler(userService)
+	tokenService := service.NewTokenService(repository.NewAuthSessionRepository(db), userRepo, utils.NewMultiTimeWheel(), &config.JWTConfig{})
+	captchaService := service.NewCaptchaService(service.NewMemoryCaptchaStore(0), &config.CaptchaConfig{})
+	verificationService := service.NewVerificationService(repository.NewVerificationCodeRepository(db), userRepo,
+		service.ConsoleSender{}, service.ConsoleSender{}, utils.NewMultiTimeWheel(), &config.VerificationConfig{})
+	passwordService := service.NewPasswordResetService(repository.NewPasswordResetRepository(db), userRepo, tokenService,
+		service.ConsoleSender{}, service.ConsoleSender{}, utils.NewMultiTimeWheel(), &config.PasswordConfig{})
+	loginThrottle := service.NewLoginThrottleService(repository.NewLoginLockoutRepository(db), userRepo,
+		repository.NewRoleRepository(db), service.ConsoleSender{}, &config.LoginConfig{})
+	mfaService := service.NewMFAService(repository.NewMFARepository(db), userRepo, repository.NewRoleRepository(db), tokenService,
+		utils.NewMultiTimeWheel(), &config.MFAConfig{})
+	userService := service.NewUserService(userRepo, tokenService, captchaService, verificationService, passwordService, loginThrottle, mfaService, repository.NewBookingRepository(db), repository.NewRoleRepository(db))
+	userHandler := handler.NewUserHandler(userService, tokenService)
 
 	// 设置路由
 	api := router.Group("/api/admin")
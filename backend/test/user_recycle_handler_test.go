@@ -0,0 +1,178 @@
+package test
+
+import (
+	"encoding/json"
+	"gohotel/internal/config"
+	"gohotel/internal/handler"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/internal/service"
+	"gohotel/pkg/utils"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recycleTestRetention 测试中回收站的保留时间
+const recycleTestRetention = 7 * 24 * time.Hour
+
+// setupRecycleRouter 在认证测试环境上注册回收站路由，并为测试用户创建一条已确认的预订
+func setupRecycleRouter(t *testing.T) (*authTestEnv, *service.UserRecycleService) {
+	env := setupAuthRouter(t)
+	assert.NoError(t, env.db.AutoMigrate(&models.Room{}, &models.Booking{}, &models.BookingInstallment{}, &models.WechatAccount{}))
+
+	day := time.Now().Truncate(24 * time.Hour)
+	assert.NoError(t, env.db.Create(&models.Booking{
+		ID: 1, BookingNumber: 1, HotelID: 1, UserID: 1, RoomID: 1,
+		BookingType: models.BookingTypeNightly, CheckIn: day, CheckOut: day.AddDate(0, 0, 1), TotalDays: 1,
+		GuestName: "Alice", GuestPhone: "13800000000", Status: "confirmed",
+	}).Error)
+
+	recycleService := service.NewUserRecycleService(repository.NewUserRepository(env.db), utils.NewMultiTimeWheel(),
+		&config.UserConfig{DeletedRetention: recycleTestRetention})
+	recycleHandler := handler.NewUserRecycleHandler(recycleService)
+	env.router.GET("/api/admin/users/deleted", recycleHandler.ListDeletedUsers)
+	env.router.POST("/api/admin/users/:id/restore", recycleHandler.RestoreUser)
+	return env, recycleService
+}
+
+// deleteAlice 删除测试用户
+func deleteAlice(env *authTestEnv) int {
+	w := authRequest(env.router, "POST", "/api/admin/users/batch", "", map[string]interface{}{"user_ids": []string{"1"}})
+	return w.Code
+}
+
+// listDeleted 查询回收站
+func listDeleted(t *testing.T, env *authTestEnv) []service.DeletedUser {
+	w := authRequest(env.router, "GET", "/api/admin/users/deleted", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data []service.DeletedUser `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp.Data
+}
+
+func TestUserRecycle_SoftDeleteAndRestore(t *testing.T) {
+	env, _ := setupRecycleRouter(t)
+	_, tokens := login(t, env.router, authTestPassword)
+
+	// 有未完成预订时不能删除
+	assert.Equal(t, http.StatusConflict, deleteAlice(env))
+	assert.NoError(t, env.db.Model(&models.Booking{}).Where("id = ?", 1).Update("status", "checkout").Error)
+	assert.Equal(t, http.StatusOK, deleteAlice(env))
+
+	// 删除后不能登录，已有令牌失效
+	code, _ := login(t, env.router, authTestPassword)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	w := authRequest(env.router, "GET", "/api/users/profile", tokens.Token, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// 用户名和邮箱仍保留给原用户
+	w = authRequest(env.router, "POST", "/api/admin/users/user", "", map[string]interface{}{
+		"username": "alice2", "email": "alice@example.com",
+	})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// 历史预订仍能显示下单人
+	booking, err := repository.NewBookingRepository(env.db).FindByID(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", booking.User.Username)
+
+	deleted := listDeleted(t, env)
+	if assert.Len(t, deleted, 1) {
+		assert.Equal(t, "alice", deleted[0].Username)
+		assert.WithinDuration(t, deleted[0].DeletedAt.Time.Add(recycleTestRetention), deleted[0].PurgeAt, time.Second)
+	}
+
+	w = authRequest(env.router, "POST", "/api/admin/users/1/restore", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, listDeleted(t, env))
+	code, _ = login(t, env.router, authTestPassword)
+	assert.Equal(t, http.StatusOK, code)
+
+	// 不在回收站中的用户不能恢复
+	w = authRequest(env.router, "POST", "/api/admin/users/1/restore", "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUserRecycle_AnonymizeAfterRetention(t *testing.T) {
+	env, recycleService := setupRecycleRouter(t)
+	assert.NoError(t, env.db.Model(&models.Booking{}).Where("id = ?", 1).Update("status", "cancelled").Error)
+	assert.NoError(t, env.db.Model(&models.User{}).Where("id = ?", 1).Update("phone", "13800000000").Error)
+	assert.NoError(t, env.db.Create(&models.WechatAccount{UserID: 1, AppID: "wx-app", OpenID: "openid-alice"}).Error)
+	assert.NoError(t, env.db.Model(&models.Booking{}).Where("id = ?", 1).Update("guest_id_card", "110101199001011234").Error)
+	assert.NoError(t, env.db.Create(&[]models.VerificationCode{
+		{Channel: "sms", Target: "13800000000", Purpose: models.VerifyPurposeLogin, CodeHash: "x", ExpiresAt: time.Now()},
+		{Channel: "email", Target: "alice@example.com", Purpose: models.VerifyPurposeLogin, CodeHash: "x", ExpiresAt: time.Now()},
+		{Channel: "email", Target: "bob@example.com", Purpose: models.VerifyPurposeLogin, CodeHash: "x", ExpiresAt: time.Now()},
+	}).Error)
+	_, _ = login(t, env.router, authTestPassword)
+	assert.Equal(t, http.StatusOK, deleteAlice(env))
+
+	// 未到保留期不处理
+	count, err := recycleService.AnonymizeExpired()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	assert.NoError(t, env.db.Unscoped().Model(&models.User{}).Where("id = ?", 1).
+		Update("deleted_at", time.Now().Add(-recycleTestRetention-time.Hour)).Error)
+	count, err = recycleService.AnonymizeExpired()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	var user models.User
+	assert.NoError(t, env.db.Unscoped().First(&user, 1).Error)
+	assert.Equal(t, "deleted_1", user.Username)
+	assert.Nil(t, user.Phone)
+	assert.Empty(t, user.Password)
+	assert.NotNil(t, user.AnonymizedAt)
+	var accounts int64
+	env.db.Model(&models.WechatAccount{}).Where("user_id = ?", 1).Count(&accounts)
+	assert.Zero(t, accounts)
+
+	// 登录会话的设备信息和发给该用户的验证码一并清除
+	var session models.AuthSession
+	assert.NoError(t, env.db.Where("user_id = ?", 1).First(&session).Error)
+	assert.Empty(t, session.IP)
+	assert.Empty(t, session.UserAgent)
+	var targets []string
+	env.db.Model(&models.VerificationCode{}).Pluck("target", &targets)
+	assert.Equal(t, []string{"bob@example.com"}, targets)
+
+	// 匿名化后不可恢复，原用户名和邮箱可以重新使用，历史预订仍关联该用户
+	assert.Empty(t, listDeleted(t, env))
+	w := authRequest(env.router, "POST", "/api/admin/users/1/restore", "", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	addUser(t, env.router, map[string]interface{}{"username": "alice", "email": "alice@example.com"})
+
+	booking, err := repository.NewBookingRepository(env.db).FindByID(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "deleted_1", booking.User.Username)
+	assert.Equal(t, "已注销用户", booking.GuestName)
+	assert.Empty(t, booking.GuestPhone)
+	assert.Empty(t, booking.GuestIDCard)
+}
+
+func TestUserRecycle_KeepsLastSuperAdmin(t *testing.T) {
+	env, _ := setupRecycleRouter(t)
+	assert.NoError(t, env.db.Model(&models.Booking{}).Where("id = ?", 1).Update("status", "cancelled").Error)
+	superAdmin := models.Role{Code: models.RoleSuperAdmin, Name: "超级管理员"}
+	assert.NoError(t, env.db.Create(&superAdmin).Error)
+	assert.NoError(t, env.db.Create(&models.User{ID: 2, Username: "root", Email: "root@example.com", Password: "x", Status: "active"}).Error)
+	assert.NoError(t, env.db.Create(&[]models.UserRole{{UserID: 1, RoleID: superAdmin.ID}, {UserID: 2, RoleID: superAdmin.ID}}).Error)
+
+	// 一次删除全部超级管理员时拒绝
+	w := authRequest(env.router, "POST", "/api/admin/users/batch", "", map[string]interface{}{"user_ids": []string{"1", "2"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// 还有其他超级管理员时可以删除，剩下的最后一名不能删除
+	assert.Equal(t, http.StatusOK, deleteAlice(env))
+	w = authRequest(env.router, "POST", "/api/admin/users/batch", "", map[string]interface{}{"user_ids": []string{"2"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var count int64
+	env.db.Model(&models.User{}).Where("id = ?", 2).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
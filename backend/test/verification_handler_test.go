@@ -0,0 +1,120 @@
+package test
+
+import (
+	"gohotel/internal/models"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// sendCode 请求发送验证码并返回状态码
+func sendCode(t *testing.T, router *gin.Engine, target, purpose string) int {
+	w := authRequest(router, "POST", "/api/auth/verification-codes", "", map[string]string{
+		"target": target, "purpose": purpose,
+	})
+	return w.Code
+}
+
+func TestVerification_RateLimits(t *testing.T) {
+	env := setupAuthRouter(t)
+
+	assert.Equal(t, http.StatusOK, sendCode(t, env.router, "13800000001", "register"))
+	assert.Len(t, env.outbox.messages["13800000001"], 1)
+
+	// 重发间隔内再次请求
+	assert.Equal(t, http.StatusTooManyRequests, sendCode(t, env.router, "13800000001", "register"))
+
+	// 超过间隔后可以重发，但同一目标每天最多 3 次
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, env.db.Model(&models.VerificationCode{}).Where("target = ?", "13800000001").
+			Update("created_at", time.Now().Add(-2*time.Minute)).Error)
+		assert.Equal(t, http.StatusOK, sendCode(t, env.router, "13800000001", "register"))
+	}
+	assert.NoError(t, env.db.Model(&models.VerificationCode{}).Where("target = ?", "13800000001").
+		Update("created_at", time.Now().Add(-2*time.Minute)).Error)
+	assert.Equal(t, http.StatusTooManyRequests, sendCode(t, env.router, "13800000001", "register"))
+	assert.Len(t, env.outbox.messages["13800000001"], 3)
+
+	// 同一 IP 每小时最多 5 次，已用 3 次
+	assert.Equal(t, http.StatusOK, sendCode(t, env.router, "13800000002", "register"))
+	assert.Equal(t, http.StatusOK, sendCode(t, env.router, "carol@example.com", "register"))
+	assert.Equal(t, http.StatusTooManyRequests, sendCode(t, env.router, "13800000003", "register"))
+
+	// 已注册的邮箱不能再获取注册验证码，格式不正确的目标直接拒绝
+	env.db.Where("1 = 1").Delete(&models.VerificationCode{})
+	assert.Equal(t, http.StatusConflict, sendCode(t, env.router, "alice@example.com", "register"))
+	assert.Equal(t, http.StatusBadRequest, sendCode(t, env.router, "not-a-target", "register"))
+}
+
+func TestVerification_AttemptsAndSingleUse(t *testing.T) {
+	env := setupAuthRouter(t)
+	register := func(emailCode string) int {
+		id, answer := getCaptcha(t, env.router, env.captchas)
+		return authRequest(env.router, "POST", "/api/auth/register", "", map[string]string{
+			"username": "bob", "email": "bob@example.com", "password": "secret123",
+			"captcha_id": id, "captcha_answer": answer, "email_code": emailCode,
+		}).Code
+	}
+
+	assert.Equal(t, http.StatusOK, sendCode(t, env.router, "Bob@Example.com", "register"))
+	code := env.outbox.lastCode("bob@example.com")
+	assert.Len(t, code, 6)
+
+	// 失败 3 次后验证码作废，正确的验证码也不再有效
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusBadRequest, register("000000x"))
+	}
+	assert.Equal(t, http.StatusBadRequest, register(code))
+
+	assert.NoError(t, env.db.Model(&models.VerificationCode{}).Where("1 = 1").
+		Update("created_at", time.Now().Add(-2*time.Minute)).Error)
+	assert.Equal(t, http.StatusOK, sendCode(t, env.router, "bob@example.com", "register"))
+	code = env.outbox.lastCode("bob@example.com")
+	assert.Equal(t, http.StatusOK, register(code))
+}
+
+func TestVerification_PasswordlessLogin(t *testing.T) {
+	env := setupAuthRouter(t)
+
+	// 未注册的目标返回相同结果，但不会发送验证码
+	assert.Equal(t, http.StatusOK, sendCode(t, env.router, "nobody@example.com", "login"))
+	assert.Empty(t, env.outbox.messages["nobody@example.com"])
+
+	assert.Equal(t, http.StatusOK, sendCode(t, env.router, "alice@example.com", "login"))
+	code := env.outbox.lastCode("alice@example.com")
+
+	w := authRequest(env.router, "POST", "/api/auth/login/code", "", map[string]string{
+		"target": "alice@example.com", "code": code,
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 验证码只能使用一次
+	w = authRequest(env.router, "POST", "/api/auth/login/code", "", map[string]string{
+		"target": "alice@example.com", "code": code,
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestVerification_ChangePhoneRequiresCode(t *testing.T) {
+	env := setupAuthRouter(t)
+	_, tokens := login(t, env.router, authTestPassword)
+
+	w := authRequest(env.router, "POST", "/api/users/profile", tokens.Token, map[string]string{"phone": "13900000001"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	assert.Equal(t, http.StatusBadRequest, sendCode(t, env.router, "alice@example.com", "change_phone"))
+	assert.Equal(t, http.StatusOK, sendCode(t, env.router, "13900000001", "change_phone"))
+	w = authRequest(env.router, "POST", "/api/users/profile", tokens.Token, map[string]string{
+		"phone": "13900000001", "phone_code": env.outbox.lastCode("13900000001"),
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 未修改手机号时不需要验证码
+	w = authRequest(env.router, "POST", "/api/users/profile", tokens.Token, map[string]string{
+		"phone": "13900000001", "real_name": "Alice",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
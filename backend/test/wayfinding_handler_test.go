@@ -0,0 +1,230 @@
+package test
+
+import (
+	"encoding/json"
+	"gohotel/internal/handler"
+	"gohotel/internal/middleware"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// routeResponse 导航接口响应
+type routeResponse struct {
+	Success bool                    `json:"success"`
+	Data    service.WayfindingRoute `json:"data"`
+}
+
+// rect 测试用的轴对齐矩形
+type rect struct {
+	left, top, width, height float64
+}
+
+// contains 判断点是否在矩形内（margin 为向外扩展的距离，负数表示向内收缩）
+func (r rect) contains(p service.RoutePoint, margin float64) bool {
+	return p.X >= r.left-margin && p.X <= r.left+r.width+margin &&
+		p.Y >= r.top-margin && p.Y <= r.top+r.height+margin
+}
+
+// setupWayfindingDB 初始化内存数据库并写入合成楼层布局
+//
+// 1 楼：一条横向走廊，左端电梯、右端楼梯，房间排列在走廊两侧
+//
+//	      101    102    103    104    105
+//	[电梯][============ 走廊 ============][楼梯]
+//	      106
+//
+// 2 楼：电梯和房间之间没有走廊，房间 202 尚未摆放
+//
+// 3 楼：L 形走廊，房间 302 占据 L 形内侧，路线必须沿走廊拐弯
+//
+//	[======== 走廊 A ========]
+//	[电梯][   302    ][走廊 B]
+//	      [          ][      ][301]
+func setupWayfindingDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法连接到内存数据库: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Hotel{}, &models.Room{}, &models.Facility{}); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+	assert.NoError(t, db.Create(&models.Hotel{ID: 1, Code: "default", Name: "测试酒店", Status: "active"}).Error)
+
+	facilities := []models.Facility{
+		{ID: 1, Type: "elevator", Floor: 1, Left: 0, Top: 100, Width: 60, Height: 40},
+		{ID: 2, Type: "corridor", Floor: 1, Left: 60, Top: 100, Width: 540, Height: 40},
+		{ID: 3, Type: "stairs", Floor: 1, Left: 600, Top: 100, Width: 60, Height: 40},
+		{ID: 4, Type: "elevator", Floor: 2, Left: 0, Top: 0, Width: 60, Height: 40},
+		{ID: 5, Type: "corridor", Floor: 3, Left: 0, Top: 0, Width: 400, Height: 40},
+		{ID: 6, Type: "corridor", Floor: 3, Left: 360, Top: 40, Width: 40, Height: 300},
+		{ID: 7, Type: "elevator", Floor: 3, Left: 0, Top: 40, Width: 40, Height: 40},
+	}
+	for i := range facilities {
+		facilities[i].HotelID = 1
+	}
+	assert.NoError(t, db.Create(&facilities).Error)
+
+	rooms := []models.Room{
+		{RoomNumber: "101", Floor: 1, Left: 60, Top: 0, Width: 100, Height: 100},
+		{RoomNumber: "102", Floor: 1, Left: 160, Top: 0, Width: 100, Height: 100},
+		{RoomNumber: "103", Floor: 1, Left: 260, Top: 0, Width: 100, Height: 100},
+		{RoomNumber: "104", Floor: 1, Left: 360, Top: 0, Width: 100, Height: 100},
+		{RoomNumber: "105", Floor: 1, Left: 460, Top: 0, Width: 140, Height: 100},
+		{RoomNumber: "106", Floor: 1, Left: 60, Top: 140, Width: 100, Height: 100},
+		{RoomNumber: "201", Floor: 2, Left: 300, Top: 300, Width: 100, Height: 100},
+		{RoomNumber: "202", Floor: 2},
+		{RoomNumber: "301", Floor: 3, Left: 400, Top: 260, Width: 100, Height: 80},
+		{RoomNumber: "302", Floor: 3, Left: 40, Top: 40, Width: 320, Height: 300},
+	}
+	for i := range rooms {
+		rooms[i].HotelID = 1
+		rooms[i].RoomType = "标准间"
+		rooms[i].Price = 100
+		rooms[i].Capacity = 2
+	}
+	assert.NoError(t, db.Create(&rooms).Error)
+
+	return db
+}
+
+// setupWayfindingRouter 配置用于测试的导航路由
+func setupWayfindingRouter(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	wayfindingService := service.NewWayfindingService(repository.NewRoomRepository(db), repository.NewFacilityRepository(db))
+	wayfindingHandler := handler.NewWayfindingHandler(wayfindingService)
+	hotelService := service.NewHotelService(repository.NewHotelRepository(db), repository.NewUserRepository(db))
+
+	router.GET("/api/floors/route", middleware.HotelSelectorMiddleware(hotelService), wayfindingHandler.FindRoute)
+	return router
+}
+
+// requestRoute 请求导航接口并解析响应
+func requestRoute(t *testing.T, router *gin.Engine, query string) (int, routeResponse) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/floors/route?"+query, nil)
+	router.ServeHTTP(w, req)
+
+	var response routeResponse
+	if w.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), "JSON 反序列化失败")
+	}
+	return w.Code, response
+}
+
+// assertRouteInside 沿折线采样，断言路线只经过允许的区域
+func assertRouteInside(t *testing.T, points []service.RoutePoint, areas []rect, margin float64) {
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		for s := 0; s <= 20; s++ {
+			f := float64(s) / 20
+			p := service.RoutePoint{X: a.X + (b.X-a.X)*f, Y: a.Y + (b.Y-a.Y)*f}
+			inside := false
+			for _, area := range areas {
+				if area.contains(p, margin) {
+					inside = true
+					break
+				}
+			}
+			assert.True(t, inside, "路线上的点 (%.1f, %.1f) 不在可通行区域内", p.X, p.Y)
+		}
+	}
+}
+
+func TestFindRoute_NearestStart(t *testing.T) {
+	router := setupWayfindingRouter(setupWayfindingDB(t))
+
+	// 房间 101 靠近电梯
+	code, response := requestRoute(t, router, "room_number=101")
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, response.Success)
+	assert.Equal(t, uint(1), response.Data.Start.ID, "应从最近的电梯出发")
+	assert.Equal(t, 1, response.Data.Floor)
+
+	points := response.Data.Points
+	assert.GreaterOrEqual(t, len(points), 2)
+	assert.Equal(t, service.RoutePoint{X: 30, Y: 120}, points[0], "起点应为电梯中心")
+	assert.Equal(t, service.RoutePoint{X: 110, Y: 50}, points[len(points)-1], "终点应为房间中心")
+
+	// 房间 105 靠近楼梯
+	code, response = requestRoute(t, router, "room_number=105")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, uint(3), response.Data.Start.ID, "应从最近的楼梯出发")
+	assert.Equal(t, "stairs", response.Data.Start.Type)
+}
+
+func TestFindRoute_StaysInCorridor(t *testing.T) {
+	router := setupWayfindingRouter(setupWayfindingDB(t))
+
+	// 指定从电梯出发前往走廊另一端的房间 105
+	code, response := requestRoute(t, router, "room_number=105&start_facility_id=1")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, uint(1), response.Data.Start.ID)
+
+	elevator := rect{0, 100, 60, 40}
+	corridor := rect{60, 100, 540, 40}
+	room := rect{460, 0, 140, 100}
+	assertRouteInside(t, response.Data.Points, []rect{elevator, corridor, room}, 10)
+
+	// 路线至少与两点直线距离一样长，且明显短于绕行
+	assert.GreaterOrEqual(t, response.Data.Distance, 490.0)
+	assert.Less(t, response.Data.Distance, 650.0)
+
+	// 从楼梯出发的路线更短
+	_, nearest := requestRoute(t, router, "room_number=105")
+	assert.Less(t, nearest.Data.Distance, response.Data.Distance)
+}
+
+func TestFindRoute_TurnsAlongLShapedCorridor(t *testing.T) {
+	router := setupWayfindingRouter(setupWayfindingDB(t))
+
+	code, response := requestRoute(t, router, "room_number=301")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, uint(7), response.Data.Start.ID)
+
+	points := response.Data.Points
+	assert.GreaterOrEqual(t, len(points), 4, "路线应沿 L 形走廊拐弯")
+	assert.Equal(t, service.RoutePoint{X: 20, Y: 60}, points[0])
+	assert.Equal(t, service.RoutePoint{X: 450, Y: 300}, points[len(points)-1])
+
+	// 路线不能穿过 L 形内侧的房间 302（允许贴着墙边的一个网格）
+	blocked := rect{40, 40, 320, 300}
+	for _, p := range points {
+		assert.False(t, blocked.contains(p, -10), "路线顶点 (%.1f, %.1f) 穿过了房间 302", p.X, p.Y)
+	}
+	assertRouteInside(t, points, []rect{{0, 0, 400, 40}, {360, 40, 40, 300}, {0, 40, 40, 40}, {400, 260, 100, 80}}, 10)
+}
+
+func TestFindRoute_Errors(t *testing.T) {
+	router := setupWayfindingRouter(setupWayfindingDB(t))
+
+	tests := []struct {
+		name  string
+		query string
+		code  int
+	}{
+		{"缺少房间号", "", http.StatusBadRequest},
+		{"无效的起点设施ID", "room_number=101&start_facility_id=abc", http.StatusBadRequest},
+		{"房间不存在", "room_number=999", http.StatusNotFound},
+		{"房间尚未摆放", "room_number=202", http.StatusBadRequest},
+		{"起点设施不在该楼层", "room_number=101&start_facility_id=4", http.StatusBadRequest},
+		{"走廊不连通", "room_number=201", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _ := requestRoute(t, router, tt.query)
+			assert.Equal(t, tt.code, code)
+		})
+	}
+}
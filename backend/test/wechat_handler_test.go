@@ -0,0 +1,156 @@
+package test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"gohotel/internal/config"
+	"gohotel/internal/handler"
+	"gohotel/internal/middleware"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/internal/service"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// wechatTestAppID 测试小程序 AppID
+const wechatTestAppID = "wx-test-app"
+
+// fakeWechatClient 按 js_code 返回预设结果的微信客户端
+type fakeWechatClient struct {
+	sessions map[string]*service.WechatSession
+}
+
+// Code2Session 返回预设的会话，未知的 js_code 视为无效
+func (c *fakeWechatClient) Code2Session(jsCode string) (*service.WechatSession, error) {
+	if session, ok := c.sessions[jsCode]; ok {
+		return session, nil
+	}
+	return nil, &service.WechatCodeError{Code: 40029, Message: "invalid code"}
+}
+
+// setupWechatRouter 在认证测试环境上注册微信登录路由
+func setupWechatRouter(t *testing.T) (*authTestEnv, *fakeWechatClient) {
+	env := setupAuthRouter(t)
+	assert.NoError(t, env.db.AutoMigrate(&models.WechatAccount{}))
+
+	key := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef"))
+	client := &fakeWechatClient{sessions: map[string]*service.WechatSession{
+		"code-new":    {OpenID: "openid-new", UnionID: "union-new", SessionKey: key},
+		"code-alice":  {OpenID: "openid-alice", SessionKey: key},
+		"code-linked": {OpenID: "openid-linked", UnionID: "union-alice", SessionKey: key},
+	}}
+	wechatService := service.NewWechatAuthService(repository.NewWechatAccountRepository(env.db), repository.NewUserRepository(env.db),
+		env.mfa, client, &config.WechatConfig{AppID: wechatTestAppID})
+	wechatHandler := handler.NewWechatHandler(wechatService)
+
+	authRequired := middleware.AuthMiddleware(env.tokens)
+	env.router.POST("/api/auth/wechat/login", wechatHandler.Login)
+	env.router.POST("/api/users/wechat/bind", authRequired, wechatHandler.BindAccount)
+	env.router.POST("/api/users/wechat/phone", authRequired, wechatHandler.BindPhone)
+	return env, client
+}
+
+// wechatLogin 使用 js_code 登录
+func wechatLogin(t *testing.T, env *authTestEnv, code string) (int, service.WechatLoginResponse) {
+	w := authRequest(env.router, "POST", "/api/auth/wechat/login", "", map[string]string{"code": code})
+	var resp struct {
+		Data service.WechatLoginResponse `json:"data"`
+	}
+	if w.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w.Code, resp.Data
+}
+
+// encryptWechatData 按微信开放数据的格式加密（AES-128-CBC、PKCS#7）
+func encryptWechatData(t *testing.T, sessionKey string, payload interface{}) (string, string) {
+	key, _ := base64.StdEncoding.DecodeString(sessionKey)
+	plain, err := json.Marshal(payload)
+	assert.NoError(t, err)
+	padding := aes.BlockSize - len(plain)%aes.BlockSize
+	plain = append(plain, bytes.Repeat([]byte{byte(padding)}, padding)...)
+
+	iv := []byte("fedcba9876543210")
+	block, err := aes.NewCipher(key)
+	assert.NoError(t, err)
+	encrypted := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, plain)
+	return base64.StdEncoding.EncodeToString(encrypted), base64.StdEncoding.EncodeToString(iv)
+}
+
+func TestWechat_LoginCreatesAndLinksUser(t *testing.T) {
+	env, _ := setupWechatRouter(t)
+
+	code, first := wechatLogin(t, env, "code-new")
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, first.IsNewUser)
+	assert.Equal(t, fmt.Sprintf("wx_%d", first.User.ID), first.User.Username, "用户名由用户 ID 生成")
+	assert.NotEmpty(t, first.Token)
+	assert.Equal(t, http.StatusOK, authRequest(env.router, "GET", "/api/users/profile", first.Token, nil).Code)
+
+	// 再次登录是同一个用户
+	code, again := wechatLogin(t, env, "code-new")
+	assert.Equal(t, http.StatusOK, code)
+	assert.False(t, again.IsNewUser)
+	assert.Equal(t, first.User.ID, again.User.ID)
+
+	// 同一开放平台下其他小程序已关联的用户，通过 unionid 关联到同一用户
+	assert.NoError(t, env.db.Create(&models.WechatAccount{
+		UserID: 1, AppID: "wx-other-app", OpenID: "openid-legacy", UnionID: "union-alice",
+	}).Error)
+	code, linked := wechatLogin(t, env, "code-linked")
+	assert.Equal(t, http.StatusOK, code)
+	assert.False(t, linked.IsNewUser)
+	assert.Equal(t, "alice", linked.User.Username)
+	var count int64
+	env.db.Model(&models.WechatAccount{}).Where("user_id = ?", 1).Count(&count)
+	assert.Equal(t, int64(2), count)
+
+	code, _ = wechatLogin(t, env, "bad-code")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestWechat_BindAccountAndPhone(t *testing.T) {
+	env, client := setupWechatRouter(t)
+	_, tokens := login(t, env.router, authTestPassword)
+
+	// 已有账号绑定微信后，微信登录进入该账号
+	w := authRequest(env.router, "POST", "/api/users/wechat/bind", tokens.Token, map[string]string{"code": "code-alice"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	code, resp := wechatLogin(t, env, "code-alice")
+	assert.Equal(t, http.StatusOK, code)
+	assert.False(t, resp.IsNewUser)
+	assert.Equal(t, "alice", resp.User.Username)
+
+	// 已绑定其他账号的微信不能再绑定
+	_, newcomer := wechatLogin(t, env, "code-new")
+	w = authRequest(env.router, "POST", "/api/users/wechat/bind", newcomer.Token, map[string]string{"code": "code-alice"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	sessionKey := client.sessions["code-alice"].SessionKey
+	data, iv := encryptWechatData(t, sessionKey, map[string]interface{}{
+		"phoneNumber": "13700000001", "purePhoneNumber": "13700000001", "countryCode": "86",
+		"watermark": map[string]interface{}{"appid": wechatTestAppID, "timestamp": 1700000000},
+	})
+	w = authRequest(env.router, "POST", "/api/users/wechat/phone", resp.Token, map[string]string{"encrypted_data": data, "iv": iv})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var user models.User
+	assert.NoError(t, env.db.First(&user, 1).Error)
+	assert.Equal(t, "13700000001", *user.Phone)
+
+	// 其他账号不能绑定同一手机号，其他小程序的数据被拒绝
+	w = authRequest(env.router, "POST", "/api/users/wechat/phone", newcomer.Token, map[string]string{"encrypted_data": data, "iv": iv})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	data, iv = encryptWechatData(t, sessionKey, map[string]interface{}{
+		"purePhoneNumber": "13700000002", "countryCode": "86", "watermark": map[string]interface{}{"appid": "wx-other"},
+	})
+	w = authRequest(env.router, "POST", "/api/users/wechat/phone", newcomer.Token, map[string]string{"encrypted_data": data, "iv": iv})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
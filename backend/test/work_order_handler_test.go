@@ -0,0 +1,251 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"gohotel/internal/config"
+	"gohotel/internal/handler"
+	"gohotel/internal/models"
+	"gohotel/internal/repository"
+	"gohotel/internal/service"
+	"gohotel/pkg/utils"
+	"hash/crc64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeCOS 内存中的 COS 存储桶，支持 HEAD/GET/PUT/DELETE，用于测试临时上传确认流程
+type fakeCOS struct {
+	server  *httptest.Server
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+// newFakeCOS 启动内存 COS 服务并创建连接它的 CosService
+func newFakeCOS(t *testing.T) (*fakeCOS, *service.CosService) {
+	f := &fakeCOS{objects: map[string][]byte{}}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+
+	cosService, err := service.NewCosService(&config.COSConfig{SecretID: "id", SecretKey: "key", BaseURL: f.server.URL})
+	if err != nil {
+		t.Fatalf("创建 COS 服务失败: %v", err)
+	}
+	return f, cosService
+}
+
+// serve 处理对象存储请求
+func (f *fakeCOS) serve(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.objects[key] = data
+		// SDK 会校验上传内容的 CRC64
+		w.Header().Set("x-cos-hash-crc64ecma", strconv.FormatUint(crc64.Checksum(data, crc64.MakeTable(crc64.ECMA)), 10))
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	}
+}
+
+// putTemp 放入一个临时上传文件，返回临时 URL
+func (f *fakeCOS) putTemp(resourceType, filename string) string {
+	key := fmt.Sprintf("tmp/%s/%s/%s", resourceType, time.Now().Format("20060102"), filename)
+	f.mu.Lock()
+	f.objects[key] = []byte(filename)
+	f.mu.Unlock()
+	return fmt.Sprintf("%s/%s?temp=true&expire=%d", f.server.URL, key, time.Now().Add(time.Hour).Unix())
+}
+
+// has 判断对象是否存在
+func (f *fakeCOS) has(key string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.objects[key]
+	return ok
+}
+
+// url 返回正式文件的 URL
+func (f *fakeCOS) url(key string) string {
+	return f.server.URL + "/" + key
+}
+
+// setupWorkOrderRouter 初始化内存数据库并配置维修工单路由
+//
+// 房间 1 在第 1~3 天有已确认预订；房间 2 属于酒店 2；维修人员 2 属于酒店 1，维修人员 3 属于酒店 2
+func setupWorkOrderRouter(t *testing.T) (*gin.Engine, *fakeCOS, time.Time) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法连接到内存数据库: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Room{}, &models.RoomPhoto{}, &models.Amenity{}, &models.Booking{},
+		&models.BookingInstallment{}, &models.HotelStaff{}, &models.WorkOrder{}); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+
+	for i, name := range []string{"admin", "tech1", "tech2"} {
+		assert.NoError(t, db.Create(&models.User{
+			ID: utils.JSONInt64(i + 1), Username: name, Email: name + "@example.com", Password: "x", Status: "active",
+		}).Error)
+	}
+	assert.NoError(t, db.Create(&[]models.HotelStaff{
+		{HotelID: 1, UserID: 1}, {HotelID: 1, UserID: 2}, {HotelID: 2, UserID: 3},
+	}).Error)
+	assert.NoError(t, db.Create(&[]models.Room{
+		{ID: 1, HotelID: 1, RoomNumber: "101", RoomType: "标准间", Floor: 1, Price: 200, Capacity: 2},
+		{ID: 2, HotelID: 2, RoomNumber: "201", RoomType: "标准间", Floor: 2, Price: 200, Capacity: 2},
+	}).Error)
+	day := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 10)
+	assert.NoError(t, db.Create(&models.Booking{
+		ID: 1, BookingNumber: 1, HotelID: 1, UserID: 1, RoomID: 1, BookingType: models.BookingTypeNightly,
+		CheckIn: day.AddDate(0, 0, 1), CheckOut: day.AddDate(0, 0, 3), TotalDays: 2,
+		GuestName: "张三", GuestPhone: "13800000000", Status: "confirmed",
+	}).Error)
+
+	fake, cosService := newFakeCOS(t)
+	workOrderService := service.NewWorkOrderService(repository.NewWorkOrderRepository(db), repository.NewRoomRepository(db),
+		repository.NewBookingRepository(db), repository.NewUserRepository(db), repository.NewHotelRepository(db), cosService)
+	workOrderHandler := handler.NewWorkOrderHandler(workOrderService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", int64(1))
+		c.Set("hotel_id", uint(1))
+		c.Next()
+	})
+	router.GET("/api/admin/work-orders", workOrderHandler.ListWorkOrders)
+	router.POST("/api/admin/work-orders", workOrderHandler.CreateWorkOrder)
+	router.GET("/api/admin/work-orders/:id", workOrderHandler.GetWorkOrder)
+	router.POST("/api/admin/work-orders/:id", workOrderHandler.UpdateWorkOrder)
+	return router, fake, day
+}
+
+// workOrderRequest 发送工单请求，返回状态码和结果
+func workOrderRequest(t *testing.T, router *gin.Engine, method, url string, body interface{}) (int, service.WorkOrderResult) {
+	w := housekeepingRequest(router, method, url, 1, body)
+	var resp struct {
+		Data service.WorkOrderResult `json:"data"`
+	}
+	if w.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w.Code, resp.Data
+}
+
+func TestWorkOrder_OutOfOrderConflictsWithBookings(t *testing.T) {
+	router, _, day := setupWorkOrderRouter(t)
+
+	code, result := workOrderRequest(t, router, "POST", "/api/admin/work-orders", map[string]interface{}{
+		"room_id": 1, "title": "卫生间漏水", "category": "plumbing",
+		"out_of_order_start": day.AddDate(0, 0, 2).Format("2006-01-02"), "out_of_order_end": day.AddDate(0, 0, 4).Format("2006-01-02"),
+	})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "open", result.WorkOrder.Status)
+	assert.Equal(t, "medium", result.WorkOrder.Priority)
+	assert.Len(t, result.ConflictingBookings, 1)
+	assert.NotEmpty(t, result.Warning)
+
+	// 停用时段改到预订之后，不再冲突
+	url := fmt.Sprintf("/api/admin/work-orders/%d", result.WorkOrder.ID)
+	code, result = workOrderRequest(t, router, "POST", url, map[string]interface{}{
+		"out_of_order_start": day.AddDate(0, 0, 3).Format("2006-01-02"), "out_of_order_end": day.AddDate(0, 0, 4).Format("2006-01-02"),
+	})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, result.ConflictingBookings)
+
+	// 已完成的工单不再占用停用时段
+	code, result = workOrderRequest(t, router, "POST", url, map[string]interface{}{
+		"status": "resolved", "out_of_order_start": day.Format("2006-01-02"), "out_of_order_end": day.AddDate(0, 0, 5).Format("2006-01-02"),
+	})
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotNil(t, result.WorkOrder.ResolvedAt)
+	assert.Empty(t, result.ConflictingBookings)
+
+	code, _ = workOrderRequest(t, router, "POST", url, map[string]interface{}{"out_of_order_start": day.Format("2006-01-02")})
+	assert.Equal(t, http.StatusBadRequest, code, "停用开始和结束日期需同时提供")
+}
+
+func TestWorkOrder_Photos(t *testing.T) {
+	router, fake, _ := setupWorkOrderRouter(t)
+
+	code, result := workOrderRequest(t, router, "POST", "/api/admin/work-orders", map[string]interface{}{
+		"room_id": 1, "title": "灯不亮", "category": "electrical",
+		"photo_temp_urls": []string{fake.putTemp("workorder", "a.jpg"), fake.putTemp("workorder", "b.jpg")},
+	})
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, fmt.Sprintf(`[%q, %q]`, fake.url("workorders/a.jpg"), fake.url("workorders/b.jpg")), result.WorkOrder.Photos)
+	assert.True(t, fake.has("workorders/a.jpg"))
+
+	// 删除一张、追加一张
+	url := fmt.Sprintf("/api/admin/work-orders/%d", result.WorkOrder.ID)
+	code, result = workOrderRequest(t, router, "POST", url, map[string]interface{}{
+		"remove_photo_urls":   []string{fake.url("workorders/a.jpg")},
+		"add_photo_temp_urls": []string{fake.putTemp("workorder", "c.jpg")},
+	})
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, fmt.Sprintf(`[%q, %q]`, fake.url("workorders/b.jpg"), fake.url("workorders/c.jpg")), result.WorkOrder.Photos)
+	assert.False(t, fake.has("workorders/a.jpg"))
+
+	// 部分照片确认失败时，已确认的文件被删除，工单不变
+	missing := strings.Replace(fake.putTemp("workorder", "e.jpg"), "e.jpg", "missing.jpg", 1)
+	code, _ = workOrderRequest(t, router, "POST", url, map[string]interface{}{
+		"add_photo_temp_urls": []string{fake.putTemp("workorder", "d.jpg"), missing},
+	})
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.False(t, fake.has("workorders/d.jpg"))
+	_, result = workOrderRequest(t, router, "GET", url, nil)
+	assert.JSONEq(t, fmt.Sprintf(`[%q, %q]`, fake.url("workorders/b.jpg"), fake.url("workorders/c.jpg")), result.WorkOrder.Photos)
+
+	code, _ = workOrderRequest(t, router, "POST", "/api/admin/work-orders", map[string]interface{}{
+		"room_id": 1, "title": "灯不亮", "category": "electrical", "photo_temp_urls": []string{fake.putTemp("workorder", "f.jpg"), missing},
+	})
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.False(t, fake.has("workorders/f.jpg"))
+}
+
+func TestWorkOrder_HotelScope(t *testing.T) {
+	router, _, _ := setupWorkOrderRouter(t)
+
+	code, _ := workOrderRequest(t, router, "POST", "/api/admin/work-orders", map[string]interface{}{
+		"room_id": 2, "title": "空调异响", "category": "hvac",
+	})
+	assert.Equal(t, http.StatusNotFound, code, "不能为其他酒店的房间创建工单")
+
+	code, _ = workOrderRequest(t, router, "POST", "/api/admin/work-orders", map[string]interface{}{
+		"room_id": 1, "title": "空调异响", "category": "hvac", "assignee_id": "3",
+	})
+	assert.Equal(t, http.StatusNotFound, code, "不能把工单分配给其他酒店的维修人员")
+	code, created := workOrderRequest(t, router, "POST", "/api/admin/work-orders", map[string]interface{}{
+		"room_id": 1, "title": "空调异响", "category": "hvac", "assignee_id": "2",
+	})
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = workOrderRequest(t, router, "POST", fmt.Sprintf("/api/admin/work-orders/%d", created.WorkOrder.ID), map[string]interface{}{
+		"assignee_id": "3",
+	})
+	assert.Equal(t, http.StatusNotFound, code)
+}